@@ -46,8 +46,10 @@ func run() error {
 	// Commands
 	clickhouseMigrateFlag := flag.Bool("clickhouse-migrate", false, "Run ClickHouse/indexer database migrations (up)")
 	clickhouseMigrateUpToFlag := flag.Int64("clickhouse-migrate-up-to", 0, "Run ClickHouse migrations up to a specific version")
-	clickhouseMigrateDownFlag := flag.Bool("clickhouse-migrate-down", false, "Roll back the most recent ClickHouse migration")
+	clickhouseMigrateDownFlag := flag.Bool("clickhouse-migrate-down", false, "Roll back the most recent ClickHouse migrations (see --steps)")
 	clickhouseMigrateDownToFlag := flag.Int64("clickhouse-migrate-down-to", 0, "Roll back ClickHouse migrations to a specific version")
+	clickhouseMigrateToFlag := flag.Int64("clickhouse-migrate-to", 0, "Migrate ClickHouse up or down to a specific version")
+	stepsFlag := flag.Int("steps", 1, "Number of migrations to roll back with --clickhouse-migrate-down")
 	clickhouseMigrateRedoFlag := flag.Bool("clickhouse-migrate-redo", false, "Roll back and re-apply the most recent ClickHouse migration")
 	clickhouseMigrateStatusFlag := flag.Bool("clickhouse-migrate-status", false, "Show ClickHouse/indexer database migration status")
 	clickhouseMigrateVersionFlag := flag.Bool("clickhouse-migrate-version", false, "Show current ClickHouse migration version")
@@ -62,6 +64,7 @@ func run() error {
 	backfillDeviceLinkLatencyFlag := flag.Bool("backfill-device-link-latency", false, "Backfill device link latency fact table from on-chain data")
 	backfillInternetMetroLatencyFlag := flag.Bool("backfill-internet-metro-latency", false, "Backfill internet metro latency fact table from on-chain data")
 	backfillDeviceInterfaceCountersFlag := flag.Bool("backfill-device-interface-counters", false, "Backfill device interface counters fact table from InfluxDB")
+	backfillGeoIPFlag := flag.Bool("backfill-geoip", false, "Resolve all current device public IPs and write them to the geoip records table")
 
 	// Backfill options (latency - epoch-based)
 	dzEnvFlag := flag.String("dz-env", config.EnvMainnetBeta, "DZ ledger environment (devnet, testnet, mainnet-beta)")
@@ -75,6 +78,10 @@ func run() error {
 	chunkIntervalFlag := flag.Duration("chunk-interval", 1*time.Hour, "Chunk interval for usage backfill")
 	queryDelayFlag := flag.Duration("query-delay", 5*time.Second, "Delay between InfluxDB queries to avoid rate limits")
 
+	// Backfill options (geoip)
+	geoipCityDBPathFlag := flag.String("geoip-city-db-path", "/usr/share/GeoIP/GeoLite2-City.mmdb", "Path to MaxMind GeoIP2 City database file (or set GEOIP_CITY_DB_PATH env var)")
+	geoipASNDBPathFlag := flag.String("geoip-asn-db-path", "/usr/share/GeoIP/GeoLite2-ASN.mmdb", "Path to MaxMind GeoIP2 ASN database file (or set GEOIP_ASN_DB_PATH env var)")
+
 	// PostgreSQL configuration
 	pgHostFlag := flag.String("pg-host", "localhost", "PostgreSQL host (or set POSTGRES_HOST env var)")
 	pgPortFlag := flag.String("pg-port", "5432", "PostgreSQL port (or set POSTGRES_PORT env var)")
@@ -137,6 +144,14 @@ func run() error {
 		*dzEnvFlag = envDZEnv
 	}
 
+	// Override GeoIP flags with environment variables if set
+	if envPath := os.Getenv("GEOIP_CITY_DB_PATH"); envPath != "" {
+		*geoipCityDBPathFlag = envPath
+	}
+	if envPath := os.Getenv("GEOIP_ASN_DB_PATH"); envPath != "" {
+		*geoipASNDBPathFlag = envPath
+	}
+
 	// ClickHouse migration config helper
 	chMigrationCfg := clickhouse.MigrationConfig{
 		Addr:     *clickhouseAddrFlag,
@@ -165,14 +180,21 @@ func run() error {
 		if *clickhouseAddrFlag == "" {
 			return fmt.Errorf("--clickhouse-addr is required for --clickhouse-migrate-down")
 		}
-		return clickhouse.Down(context.Background(), log, chMigrationCfg)
+		return admin.ClickHouseRollback(log, chMigrationCfg, *stepsFlag, *dryRunFlag, *yesFlag)
 	}
 
 	if *clickhouseMigrateDownToFlag != 0 {
 		if *clickhouseAddrFlag == "" {
 			return fmt.Errorf("--clickhouse-addr is required for --clickhouse-migrate-down-to")
 		}
-		return clickhouse.DownTo(context.Background(), log, chMigrationCfg, *clickhouseMigrateDownToFlag)
+		return admin.ClickHouseRollbackTo(log, chMigrationCfg, *clickhouseMigrateDownToFlag, *dryRunFlag, *yesFlag)
+	}
+
+	if *clickhouseMigrateToFlag != 0 {
+		if *clickhouseAddrFlag == "" {
+			return fmt.Errorf("--clickhouse-addr is required for --clickhouse-migrate-to")
+		}
+		return admin.ClickHouseMigrateTo(log, chMigrationCfg, *clickhouseMigrateToFlag, *dryRunFlag, *yesFlag)
 	}
 
 	if *clickhouseMigrateRedoFlag {
@@ -339,6 +361,22 @@ func run() error {
 		)
 	}
 
+	if *backfillGeoIPFlag {
+		if *clickhouseAddrFlag == "" {
+			return fmt.Errorf("--clickhouse-addr is required for --backfill-geoip")
+		}
+		return admin.BackfillGeoIP(
+			log,
+			*clickhouseAddrFlag, *clickhouseDatabaseFlag, *clickhouseUsernameFlag, *clickhousePasswordFlag,
+			*clickhouseSecureFlag,
+			admin.BackfillGeoIPConfig{
+				CityDBPath: *geoipCityDBPathFlag,
+				ASNDBPath:  *geoipASNDBPathFlag,
+				DryRun:     *dryRunFlag,
+			},
+		)
+	}
+
 	// PostgreSQL migration commands
 	pgCfg := admin.PgMigrateConfig{
 		Host:     *pgHostFlag,
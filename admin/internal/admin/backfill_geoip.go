@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+
+	"github.com/malbeclabs/doublezero/tools/maxmind/pkg/geoip"
+	"github.com/malbeclabs/lake/indexer/pkg/clickhouse"
+	dzsvc "github.com/malbeclabs/lake/indexer/pkg/dz/serviceability"
+	mcpgeoip "github.com/malbeclabs/lake/indexer/pkg/geoip"
+)
+
+// BackfillGeoIPConfig holds the configuration for the geoip backfill command
+type BackfillGeoIPConfig struct {
+	CityDBPath string
+	ASNDBPath  string
+	DryRun     bool
+}
+
+// BackfillGeoIP resolves the public IPs of all current devices against the
+// MaxMind databases and writes the results to the geoip_records dimension.
+// The indexer's geoip view does this on every refresh; the backfill is for
+// populating the table after a MaxMind database update without running the indexer.
+func BackfillGeoIP(
+	log *slog.Logger,
+	clickhouseAddr, clickhouseDatabase, clickhouseUsername, clickhousePassword string,
+	clickhouseSecure bool,
+	cfg BackfillGeoIPConfig,
+) error {
+	ctx := context.Background()
+
+	chDB, err := clickhouse.NewClient(ctx, log, clickhouseAddr, clickhouseDatabase, clickhouseUsername, clickhousePassword, clickhouseSecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect to ClickHouse: %w", err)
+	}
+	defer chDB.Close()
+
+	resolver, closeResolver, err := mcpgeoip.OpenResolver(log, cfg.CityDBPath, cfg.ASNDBPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := closeResolver(); err != nil {
+			log.Error("failed to close GeoIP resolver", "error", err)
+		}
+	}()
+
+	store, err := mcpgeoip.NewStore(mcpgeoip.StoreConfig{
+		Logger:     log,
+		ClickHouse: chDB,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	fmt.Printf("Backfill GeoIP\n")
+	fmt.Printf("  City database:   %s\n", cfg.CityDBPath)
+	fmt.Printf("  ASN database:    %s\n", cfg.ASNDBPath)
+	fmt.Printf("  Dry run:         %v\n", cfg.DryRun)
+	fmt.Println()
+
+	devices, err := dzsvc.QueryCurrentDevices(ctx, log, chDB)
+	if err != nil {
+		return fmt.Errorf("failed to query devices: %w", err)
+	}
+
+	ips := make(map[string]net.IP)
+	for _, device := range devices {
+		if ip := net.ParseIP(device.PublicIP); ip != nil {
+			ips[ip.String()] = ip
+		}
+	}
+	fmt.Printf("Found %d devices with %d distinct public IPs\n", len(devices), len(ips))
+
+	keys := make([]string, 0, len(ips))
+	for k := range ips {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	records := make([]*geoip.Record, 0, len(ips))
+	for _, k := range keys {
+		record := resolver.Resolve(ips[k])
+		if record == nil {
+			fmt.Printf("  %-40s (not found)\n", k)
+			continue
+		}
+		fmt.Printf("  %-40s AS%-10d %-3s %s\n", k, record.ASN, record.CountryCode, record.City)
+		records = append(records, record)
+	}
+	fmt.Printf("Resolved %d of %d IPs\n", len(records), len(ips))
+
+	if cfg.DryRun {
+		fmt.Println("[DRY RUN] Would write the above records to geoip_records")
+		return nil
+	}
+
+	if err := store.UpsertRecords(ctx, records); err != nil {
+		return fmt.Errorf("failed to write geoip records: %w", err)
+	}
+
+	fmt.Printf("\nBackfill completed: %d records written\n", len(records))
+	return nil
+}
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/malbeclabs/lake/indexer/pkg/clickhouse"
+)
+
+// ClickHouseRollback rolls back the given number of most recent ClickHouse migrations,
+// after printing the migrations that will be reversed and prompting for confirmation.
+func ClickHouseRollback(log *slog.Logger, cfg clickhouse.MigrationConfig, steps int, dryRun, skipConfirm bool) error {
+	ctx := context.Background()
+
+	if steps < 1 {
+		return fmt.Errorf("--steps must be at least 1, got %d", steps)
+	}
+
+	plan, err := clickhouse.PlanRollback(ctx, cfg, steps)
+	if err != nil {
+		return err
+	}
+
+	proceed, err := confirmRollback(plan, cfg.Database, dryRun, skipConfirm)
+	if err != nil || !proceed {
+		return err
+	}
+
+	return clickhouse.RollbackMigrations(ctx, log, cfg, steps)
+}
+
+// ClickHouseMigrateTo migrates ClickHouse to the given version, rolling back or
+// applying migrations as needed. Rollbacks are confirmed like ClickHouseRollback.
+func ClickHouseMigrateTo(log *slog.Logger, cfg clickhouse.MigrationConfig, version int64, dryRun, skipConfirm bool) error {
+	ctx := context.Background()
+
+	plan, err := clickhouse.PlanRollbackTo(ctx, cfg, version)
+	if err != nil {
+		return err
+	}
+
+	if len(plan) == 0 {
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would apply pending migrations up to version %d\n", version)
+			return nil
+		}
+		return clickhouse.UpTo(ctx, log, cfg, version)
+	}
+
+	proceed, err := confirmRollback(plan, cfg.Database, dryRun, skipConfirm)
+	if err != nil || !proceed {
+		return err
+	}
+
+	return clickhouse.DownTo(ctx, log, cfg, version)
+}
+
+// ClickHouseRollbackTo rolls back ClickHouse migrations to the given version,
+// after printing the migrations that will be reversed and prompting for confirmation.
+func ClickHouseRollbackTo(log *slog.Logger, cfg clickhouse.MigrationConfig, version int64, dryRun, skipConfirm bool) error {
+	ctx := context.Background()
+
+	plan, err := clickhouse.PlanRollbackTo(ctx, cfg, version)
+	if err != nil {
+		return err
+	}
+
+	proceed, err := confirmRollback(plan, cfg.Database, dryRun, skipConfirm)
+	if err != nil || !proceed {
+		return err
+	}
+
+	return clickhouse.DownTo(ctx, log, cfg, version)
+}
+
+// confirmRollback prints the migrations that will be reversed and asks for
+// confirmation. It returns false if nothing should be executed.
+func confirmRollback(plan []clickhouse.AppliedMigration, database string, dryRun, skipConfirm bool) (bool, error) {
+	if len(plan) == 0 {
+		fmt.Println("No applied migrations to roll back")
+		return false, nil
+	}
+
+	fmt.Printf("⚠️  WARNING: This will roll back %d migration(s) in database '%s':\n\n", len(plan), database)
+	for _, m := range plan {
+		fmt.Printf("  - %d  %s\n", m.Version, path.Base(m.Path))
+	}
+
+	if dryRun {
+		fmt.Println("\n[DRY RUN] Would roll back the above migrations")
+		return false, nil
+	}
+
+	// Prompt for confirmation unless --yes flag is set
+	if !skipConfirm {
+		fmt.Printf("\n⚠️  Rolling back may drop tables or views and cannot be undone!\n")
+		fmt.Printf("Type 'yes' to confirm: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("failed to read confirmation: %w", err)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "yes" {
+			fmt.Printf("\nConfirmation failed. Operation cancelled.\n")
+			return false, nil
+		}
+		fmt.Println()
+	}
+
+	return true, nil
+}
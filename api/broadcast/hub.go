@@ -0,0 +1,100 @@
+// Package broadcast provides an in-process fan-out hub for pushing live
+// events to many subscribers (e.g. SSE connections).
+package broadcast
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Subscriber receives events from a Hub.
+// Done is closed when the hub is closed or the subscriber is removed.
+type Subscriber[T any] struct {
+	Events chan T
+	Done   chan struct{}
+}
+
+// Hub fans out events to all registered subscribers. Sends are non-blocking:
+// a subscriber whose buffer is full misses the event rather than stalling
+// the broadcaster.
+type Hub[T any] struct {
+	mu          sync.RWMutex
+	name        string
+	bufferSize  int
+	subscribers map[*Subscriber[T]]struct{}
+	closed      bool
+}
+
+// NewHub creates a hub whose subscribers each buffer up to bufferSize events.
+func NewHub[T any](name string, bufferSize int) *Hub[T] {
+	return &Hub[T]{
+		name:        name,
+		bufferSize:  bufferSize,
+		subscribers: make(map[*Subscriber[T]]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber.
+// Returns nil if the hub has been closed.
+func (h *Hub[T]) Subscribe() *Subscriber[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	sub := &Subscriber[T]{
+		Events: make(chan T, h.bufferSize),
+		Done:   make(chan struct{}),
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its Done channel.
+// It is safe to call after the hub has been closed.
+func (h *Hub[T]) Unsubscribe(sub *Subscriber[T]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.Done)
+	}
+}
+
+// Broadcast delivers an event to every subscriber.
+func (h *Hub[T]) Broadcast(event T) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return
+	}
+	for sub := range h.subscribers {
+		select {
+		case sub.Events <- event:
+		default:
+			slog.Warn("Subscriber buffer full, skipping event", "hub", h.name)
+		}
+	}
+}
+
+// Len returns the number of active subscribers.
+func (h *Hub[T]) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Close signals all subscribers to stop and rejects new subscriptions.
+// Subsequent broadcasts are dropped.
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for sub := range h.subscribers {
+		close(sub.Done)
+	}
+	h.subscribers = make(map[*Subscriber[T]]struct{})
+}
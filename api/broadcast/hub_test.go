@@ -0,0 +1,115 @@
+package broadcast_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/broadcast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_BroadcastToAllSubscribers(t *testing.T) {
+	hub := broadcast.NewHub[int]("test", 10)
+	a := hub.Subscribe()
+	b := hub.Subscribe()
+	require.NotNil(t, a)
+	require.NotNil(t, b)
+
+	hub.Broadcast(42)
+
+	assert.Equal(t, 42, <-a.Events)
+	assert.Equal(t, 42, <-b.Events)
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	hub := broadcast.NewHub[int]("test", 10)
+	sub := hub.Subscribe()
+	hub.Unsubscribe(sub)
+
+	assert.Equal(t, 0, hub.Len())
+	select {
+	case <-sub.Done:
+	default:
+		t.Fatal("Done should be closed after unsubscribe")
+	}
+
+	hub.Broadcast(1)
+	assert.Empty(t, sub.Events)
+
+	// Unsubscribing twice is a no-op
+	hub.Unsubscribe(sub)
+}
+
+func TestHub_FullBufferDoesNotBlock(t *testing.T) {
+	hub := broadcast.NewHub[int]("test", 1)
+	sub := hub.Subscribe()
+
+	done := make(chan struct{})
+	go func() {
+		hub.Broadcast(1)
+		hub.Broadcast(2)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Broadcast blocked on a full subscriber")
+	}
+	assert.Equal(t, 1, <-sub.Events)
+}
+
+func TestHub_CloseReleasesSubscribers(t *testing.T) {
+	hub := broadcast.NewHub[int]("test", 10)
+
+	const n = 150
+	var wg sync.WaitGroup
+	received := make(chan int, n)
+	for i := 0; i < n; i++ {
+		sub := hub.Subscribe()
+		require.NotNil(t, sub)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case v := <-sub.Events:
+					received <- v
+				case <-sub.Done:
+					return
+				}
+			}
+		}()
+	}
+	assert.Equal(t, n, hub.Len())
+
+	hub.Broadcast(7)
+	for i := 0; i < n; i++ {
+		select {
+		case v := <-received:
+			assert.Equal(t, 7, v)
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of %d subscribers received the event", i, n)
+		}
+	}
+
+	hub.Close()
+
+	waitDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(waitDone)
+	}()
+	select {
+	case <-waitDone:
+	case <-time.After(time.Second):
+		t.Fatal("subscriber goroutines did not exit after Close")
+	}
+
+	assert.Equal(t, 0, hub.Len())
+	assert.Nil(t, hub.Subscribe(), "Subscribe should fail after Close")
+	hub.Broadcast(8) // must not panic
+	hub.Close()      // idempotent
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/malbeclabs/lake/api/tracing"
 )
 
 // DB is the global ClickHouse connection pool (mainnet-beta)
@@ -134,7 +135,7 @@ func Load() error {
 		return fmt.Errorf("failed to ping clickhouse: %w", err)
 	}
 
-	DB = conn
+	DB = tracing.ClickHouse(conn)
 	log.Printf("Connected to ClickHouse successfully")
 
 	// Create connections for each env database
@@ -170,7 +171,7 @@ func Load() error {
 			return fmt.Errorf("failed to connect to ClickHouse for %s (database=%s): %w", env, dbName, err)
 		}
 		pingCancel()
-		EnvDBs[env] = envConn
+		EnvDBs[env] = tracing.ClickHouse(envConn)
 		log.Printf("Connected to ClickHouse for %s (database=%s)", env, dbName)
 	}
 
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    key_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA256 hash of key
+    tier VARCHAR(16) NOT NULL DEFAULT 'standard' CHECK (tier IN ('standard', 'premium', 'unlimited')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
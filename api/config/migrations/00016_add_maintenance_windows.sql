@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_pks TEXT[] NOT NULL DEFAULT '{}',
+    link_pks TEXT[] NOT NULL DEFAULT '{}',
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    impact JSONB NOT NULL, -- MaintenanceImpactResponse computed when the window was scheduled
+    created_by UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    notified_at TIMESTAMPTZ, -- when the pre-maintenance Slack notification was sent
+    CHECK (end_at > start_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_start_at ON maintenance_windows(start_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS maintenance_windows;
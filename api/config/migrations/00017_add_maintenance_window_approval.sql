@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE maintenance_windows
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending', -- pending, approved or cancelled
+    ADD COLUMN IF NOT EXISTS approved_by TEXT, -- Slack user ID of the on-call engineer who approved the window
+    ADD COLUMN IF NOT EXISTS approved_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE maintenance_windows
+    DROP COLUMN IF EXISTS approved_at,
+    DROP COLUMN IF EXISTS approved_by,
+    DROP COLUMN IF EXISTS status;
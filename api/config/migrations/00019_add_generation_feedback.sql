@@ -0,0 +1,29 @@
+-- +goose Up
+-- SQL returned by /api/generate, kept so users can rate it
+CREATE TABLE IF NOT EXISTS generated_queries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    prompt TEXT NOT NULL,
+    sql TEXT NOT NULL,
+    query_type VARCHAR(32) NOT NULL, -- coarse topic of the prompt, see generationQueryType
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_generated_queries_query_type ON generated_queries(query_type);
+
+CREATE TABLE IF NOT EXISTS generation_feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID, -- query editor session, if any; not a foreign key since sessions are saved lazily
+    query_id UUID NOT NULL REFERENCES generated_queries(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating IN (-1, 1)),
+    corrected_query TEXT,
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One rating per session and query; rating again replaces it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_generation_feedback_query_session ON generation_feedback(query_id, session_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS generation_feedback;
+DROP TABLE IF EXISTS generated_queries;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Per-day counts of SQL queries run and SQL generations, alongside chat questions
+ALTER TABLE usage_daily ADD COLUMN IF NOT EXISTS query_count INT NOT NULL DEFAULT 0;
+ALTER TABLE usage_daily ADD COLUMN IF NOT EXISTS generate_count INT NOT NULL DEFAULT 0;
+
+-- Daily usage per account, rolled up from usage_daily after each UTC day ends.
+-- Unlike usage_daily it isn't cleaned up, so it holds all-time usage.
+CREATE TABLE IF NOT EXISTS usage_history (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    query_count INT NOT NULL DEFAULT 0,
+    generate_count INT NOT NULL DEFAULT 0,
+    chat_count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (account_id, date)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS usage_history;
+ALTER TABLE usage_daily DROP COLUMN IF EXISTS generate_count;
+ALTER TABLE usage_daily DROP COLUMN IF EXISTS query_count;
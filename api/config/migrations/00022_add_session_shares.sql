@@ -0,0 +1,17 @@
+-- +goose Up
+-- Read-only share links for sessions. Only the hash of the share token is stored.
+CREATE TABLE IF NOT EXISTS session_shares (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    share_token_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA256 hash of token
+    expires_at TIMESTAMPTZ NOT NULL,
+    view_count INT NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_shares_session_id ON session_shares(session_id);
+CREATE INDEX IF NOT EXISTS idx_session_shares_expires_at ON session_shares(expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS session_shares;
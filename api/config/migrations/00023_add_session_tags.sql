@@ -0,0 +1,9 @@
+-- +goose Up
+-- Key-value labels on sessions, e.g. {"project": "capacity-planning"}
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_tags ON sessions USING GIN (tags);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_sessions_tags;
+ALTER TABLE sessions DROP COLUMN IF EXISTS tags;
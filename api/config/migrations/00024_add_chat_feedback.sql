@@ -0,0 +1,17 @@
+-- +goose Up
+-- Ratings of assistant messages in chat sessions
+CREATE TABLE IF NOT EXISTS chat_feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    message_index INTEGER NOT NULL, -- index of the assistant message in the session content
+    rating SMALLINT NOT NULL CHECK (rating IN (-1, 1)),
+    correction TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One rating per message; rating again replaces it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chat_feedback_session_message ON chat_feedback(session_id, message_index);
+
+-- +goose Down
+DROP TABLE IF EXISTS chat_feedback;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- The last result of a diffed query in each session, compared against the next run
+CREATE TABLE IF NOT EXISTS session_query_results (
+    session_id UUID PRIMARY KEY REFERENCES sessions(id) ON DELETE CASCADE,
+    diff_key TEXT NOT NULL,
+    columns JSONB NOT NULL,
+    rows JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS session_query_results;
@@ -5,8 +5,10 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/malbeclabs/lake/api/tracing"
 	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
 )
 
@@ -16,6 +18,9 @@ var Neo4jClient neo4j.Client
 // Neo4jDatabase is the configured database name
 var Neo4jDatabase string
 
+// Neo4jPool manages the sessions handed out by Neo4jSession
+var Neo4jPool = NewNeo4jSessionPool(defaultNeo4jMaxOpenSessions, defaultNeo4jAcquireTimeout)
+
 // LoadNeo4j initializes the Neo4j client from environment variables.
 // The client is read-only to prevent accidental writes from the API layer.
 func LoadNeo4j() error {
@@ -36,6 +41,8 @@ func LoadNeo4j() error {
 
 	password := os.Getenv("NEO4J_PASSWORD")
 
+	Neo4jPool = NewNeo4jSessionPool(neo4jMaxOpenSessions(), neo4jAcquireTimeout())
+
 	log.Printf("Connecting to Neo4j (read-only): uri=%s, database=%s, username=%s", uri, Neo4jDatabase, username)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -47,7 +54,7 @@ func LoadNeo4j() error {
 	}
 
 	Neo4jClient = client
-	log.Printf("Connected to Neo4j successfully (read-only)")
+	log.Printf("Connected to Neo4j successfully (read-only): max_open_sessions=%d, acquire_timeout=%s", Neo4jPool.MaxOpenSessions, Neo4jPool.AcquireTimeout)
 
 	return nil
 }
@@ -62,8 +69,36 @@ func CloseNeo4j() error {
 	return nil
 }
 
-// Neo4jSession creates a new Neo4j session
+// Neo4jSession checks out a Neo4j session from Neo4jPool. Callers must Close it
+// to return it to the pool.
 func Neo4jSession(ctx context.Context) neo4j.Session {
-	session, _ := Neo4jClient.Session(ctx)
-	return session
+	return tracing.Neo4jSession(Neo4jPool.Acquire(ctx, Neo4jClient))
+}
+
+// neo4jMaxOpenSessions returns NEO4J_MAX_OPEN_SESSIONS or the default.
+func neo4jMaxOpenSessions() int {
+	val := os.Getenv("NEO4J_MAX_OPEN_SESSIONS")
+	if val == "" {
+		return defaultNeo4jMaxOpenSessions
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n <= 0 {
+		slog.Warn("Invalid NEO4J_MAX_OPEN_SESSIONS, using default", "value", val, "default", defaultNeo4jMaxOpenSessions)
+		return defaultNeo4jMaxOpenSessions
+	}
+	return n
+}
+
+// neo4jAcquireTimeout returns NEO4J_SESSION_ACQUIRE_TIMEOUT or the default.
+func neo4jAcquireTimeout() time.Duration {
+	val := os.Getenv("NEO4J_SESSION_ACQUIRE_TIMEOUT")
+	if val == "" {
+		return defaultNeo4jAcquireTimeout
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d < 0 {
+		slog.Warn("Invalid NEO4J_SESSION_ACQUIRE_TIMEOUT, using default", "value", val, "default", defaultNeo4jAcquireTimeout)
+		return defaultNeo4jAcquireTimeout
+	}
+	return d
 }
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+)
+
+const (
+	defaultNeo4jMaxOpenSessions = 50
+	defaultNeo4jAcquireTimeout  = 5 * time.Second
+)
+
+// ErrNeo4jSessionPoolTimeout is returned when no session slot frees up within AcquireTimeout.
+var ErrNeo4jSessionPoolTimeout = errors.New("timed out waiting for a Neo4j session")
+
+// ErrNeo4jNotConfigured is returned by sessions acquired before a Neo4j client is loaded.
+var ErrNeo4jNotConfigured = errors.New("neo4j client is not configured")
+
+// Neo4jSessionPool bounds the number of concurrently checked-out Neo4j sessions
+// and reuses idle sessions between requests. Each session holds at most one
+// driver connection, so MaxOpenSessions also caps connections to Neo4j. At
+// most MaxOpenSessions idle sessions are kept; any beyond that are closed.
+type Neo4jSessionPool struct {
+	// MaxOpenSessions is the maximum number of sessions checked out at once.
+	MaxOpenSessions int
+	// AcquireTimeout is how long Acquire waits for a free slot (0 waits until ctx is done).
+	AcquireTimeout time.Duration
+
+	slots chan struct{}
+	idle  chan *idleNeo4jSession
+}
+
+// idleNeo4jSession is a returned session along with the client that created it,
+// so sessions are never reused after the client is swapped or closed.
+type idleNeo4jSession struct {
+	client  neo4j.Client
+	session neo4j.Session
+}
+
+// NewNeo4jSessionPool creates a session pool.
+func NewNeo4jSessionPool(maxOpenSessions int, acquireTimeout time.Duration) *Neo4jSessionPool {
+	if maxOpenSessions <= 0 {
+		maxOpenSessions = defaultNeo4jMaxOpenSessions
+	}
+	return &Neo4jSessionPool{
+		MaxOpenSessions: maxOpenSessions,
+		AcquireTimeout:  acquireTimeout,
+		slots:           make(chan struct{}, maxOpenSessions),
+		idle:            make(chan *idleNeo4jSession, maxOpenSessions),
+	}
+}
+
+// Acquire checks out a session from client, waiting for a free slot if the pool
+// is at capacity. Closing the returned session returns it to the pool. If no
+// slot is available in time, the returned session fails every call with the
+// acquire error.
+func (p *Neo4jSessionPool) Acquire(ctx context.Context, client neo4j.Client) neo4j.Session {
+	if client == nil {
+		return failedNeo4jSession{err: ErrNeo4jNotConfigured}
+	}
+
+	start := time.Now()
+
+	var timeout <-chan time.Time
+	if p.AcquireTimeout > 0 {
+		timer := time.NewTimer(p.AcquireTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-timeout:
+		metrics.Neo4jSessionAcquireTimeoutsTotal.Inc()
+		return failedNeo4jSession{err: ErrNeo4jSessionPoolTimeout}
+	case <-ctx.Done():
+		return failedNeo4jSession{err: ctx.Err()}
+	}
+	metrics.Neo4jSessionPoolWaitDuration.Observe(time.Since(start).Seconds())
+
+	var session neo4j.Session
+	select {
+	case idle := <-p.idle:
+		if idle.client == client {
+			session = idle.session
+		} else {
+			_ = idle.session.Close(ctx)
+		}
+	default:
+	}
+	if session == nil {
+		s, err := client.Session(ctx)
+		if err != nil {
+			<-p.slots
+			return failedNeo4jSession{err: err}
+		}
+		session = s
+	}
+
+	metrics.Neo4jSessionsActive.Inc()
+	return &pooledNeo4jSession{Session: session, pool: p, client: client}
+}
+
+// Active returns the number of sessions currently checked out.
+func (p *Neo4jSessionPool) Active() int {
+	return len(p.slots)
+}
+
+// pooledNeo4jSession returns its underlying session to the pool on Close.
+// A session that returned an error is closed instead, since it may be broken.
+type pooledNeo4jSession struct {
+	neo4j.Session
+	pool   *Neo4jSessionPool
+	client neo4j.Client
+	failed atomic.Bool
+	closed atomic.Bool
+}
+
+func (s *pooledNeo4jSession) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.Result, error) {
+	result, err := s.Session.Run(ctx, cypher, params)
+	if err != nil {
+		s.failed.Store(true)
+	}
+	return result, err
+}
+
+func (s *pooledNeo4jSession) ExecuteRead(ctx context.Context, work neo4j.TransactionWork) (any, error) {
+	result, err := s.Session.ExecuteRead(ctx, work)
+	if err != nil {
+		s.failed.Store(true)
+	}
+	return result, err
+}
+
+func (s *pooledNeo4jSession) ExecuteWrite(ctx context.Context, work neo4j.TransactionWork) (any, error) {
+	result, err := s.Session.ExecuteWrite(ctx, work)
+	if err != nil {
+		s.failed.Store(true)
+	}
+	return result, err
+}
+
+func (s *pooledNeo4jSession) Close(ctx context.Context) error {
+	if !s.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	var err error
+	if s.failed.Load() {
+		err = s.Session.Close(ctx)
+	} else {
+		select {
+		case s.pool.idle <- &idleNeo4jSession{client: s.client, session: s.Session}:
+		default:
+			err = s.Session.Close(ctx)
+		}
+	}
+	<-s.pool.slots
+	metrics.Neo4jSessionsActive.Dec()
+	return err
+}
+
+// failedNeo4jSession is returned when a session could not be acquired.
+type failedNeo4jSession struct {
+	err error
+}
+
+func (s failedNeo4jSession) Run(context.Context, string, map[string]any) (neo4j.Result, error) {
+	return nil, s.err
+}
+
+func (s failedNeo4jSession) ExecuteRead(context.Context, neo4j.TransactionWork) (any, error) {
+	return nil, s.err
+}
+
+func (s failedNeo4jSession) ExecuteWrite(context.Context, neo4j.TransactionWork) (any, error) {
+	return nil, s.err
+}
+
+func (s failedNeo4jSession) Close(context.Context) error {
+	return nil
+}
@@ -0,0 +1,166 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNeo4jClient counts sessions that are open against it. Each session
+// stands in for one driver connection. With fail set, every Run errors.
+type fakeNeo4jClient struct {
+	open    atomic.Int64
+	maxOpen atomic.Int64
+	created atomic.Int64
+	closed  atomic.Int64
+	fail    atomic.Bool
+}
+
+func (c *fakeNeo4jClient) Session(context.Context) (neo4j.Session, error) {
+	c.created.Add(1)
+	return &fakeNeo4jSession{client: c}, nil
+}
+
+func (c *fakeNeo4jClient) Close(context.Context) error { return nil }
+
+type fakeNeo4jSession struct {
+	client *fakeNeo4jClient
+}
+
+func (s *fakeNeo4jSession) Run(context.Context, string, map[string]any) (neo4j.Result, error) {
+	n := s.client.open.Add(1)
+	defer s.client.open.Add(-1)
+	for {
+		m := s.client.maxOpen.Load()
+		if n <= m || s.client.maxOpen.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	if s.client.fail.Load() {
+		return nil, errors.New("connection reset")
+	}
+	return nil, nil
+}
+
+func (s *fakeNeo4jSession) ExecuteRead(context.Context, neo4j.TransactionWork) (any, error) {
+	return nil, nil
+}
+
+func (s *fakeNeo4jSession) ExecuteWrite(context.Context, neo4j.TransactionWork) (any, error) {
+	return nil, nil
+}
+
+func (s *fakeNeo4jSession) Close(context.Context) error {
+	s.client.closed.Add(1)
+	return nil
+}
+
+func TestNeo4jSessionPool_BoundsConcurrentSessions(t *testing.T) {
+	client := &fakeNeo4jClient{}
+	pool := config.NewNeo4jSessionPool(10, 10*time.Second)
+
+	var wg sync.WaitGroup
+	for range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			session := pool.Acquire(ctx, client)
+			defer session.Close(ctx)
+			_, err := session.Run(ctx, "RETURN 1", nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, client.maxOpen.Load(), int64(10))
+	// Sessions are reused, so no more are created than can be open at once
+	assert.LessOrEqual(t, client.created.Load(), int64(10))
+	assert.Zero(t, client.closed.Load())
+	assert.Equal(t, 0, pool.Active())
+}
+
+func TestNeo4jSessionPool_ReusesIdleSession(t *testing.T) {
+	client := &fakeNeo4jClient{}
+	pool := config.NewNeo4jSessionPool(1, time.Second)
+	ctx := context.Background()
+
+	for range 5 {
+		session := pool.Acquire(ctx, client)
+		_, err := session.Run(ctx, "RETURN 1", nil)
+		require.NoError(t, err)
+		require.NoError(t, session.Close(ctx))
+	}
+
+	assert.Equal(t, int64(1), client.created.Load())
+	assert.Zero(t, client.closed.Load())
+}
+
+func TestNeo4jSessionPool_DiscardsFailedSession(t *testing.T) {
+	client := &fakeNeo4jClient{}
+	pool := config.NewNeo4jSessionPool(1, time.Second)
+	ctx := context.Background()
+
+	client.fail.Store(true)
+	session := pool.Acquire(ctx, client)
+	_, err := session.Run(ctx, "RETURN 1", nil)
+	require.Error(t, err)
+	require.NoError(t, session.Close(ctx))
+	assert.Equal(t, int64(1), client.closed.Load())
+
+	client.fail.Store(false)
+	session = pool.Acquire(ctx, client)
+	_, err = session.Run(ctx, "RETURN 1", nil)
+	require.NoError(t, err)
+	require.NoError(t, session.Close(ctx))
+	assert.Equal(t, int64(2), client.created.Load())
+}
+
+func TestNeo4jSessionPool_AcquireTimeout(t *testing.T) {
+	client := &fakeNeo4jClient{}
+	pool := config.NewNeo4jSessionPool(1, 20*time.Millisecond)
+	ctx := context.Background()
+
+	held := pool.Acquire(ctx, client)
+	_, err := held.Run(ctx, "RETURN 1", nil)
+	require.NoError(t, err)
+
+	blocked := pool.Acquire(ctx, client)
+	_, err = blocked.Run(ctx, "RETURN 1", nil)
+	assert.ErrorIs(t, err, config.ErrNeo4jSessionPoolTimeout)
+	assert.NoError(t, blocked.Close(ctx))
+
+	require.NoError(t, held.Close(ctx))
+	// Double close must not release the slot twice
+	require.NoError(t, held.Close(ctx))
+	assert.Equal(t, 0, pool.Active())
+
+	session := pool.Acquire(ctx, client)
+	_, err = session.Run(ctx, "RETURN 1", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, session.Close(ctx))
+}
+
+func TestNeo4jSessionPool_DoesNotReuseAcrossClients(t *testing.T) {
+	first := &fakeNeo4jClient{}
+	second := &fakeNeo4jClient{}
+	pool := config.NewNeo4jSessionPool(1, time.Second)
+	ctx := context.Background()
+
+	require.NoError(t, pool.Acquire(ctx, first).Close(ctx))
+	require.NoError(t, pool.Acquire(ctx, second).Close(ctx))
+
+	assert.Equal(t, int64(1), first.created.Load())
+	assert.Equal(t, int64(1), second.created.Load())
+	// The first client's idle session is closed rather than left open
+	assert.Equal(t, int64(1), first.closed.Load())
+}
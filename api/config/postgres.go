@@ -6,6 +6,7 @@ import (
 	"embed"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"os"
 	"time"
 
@@ -32,8 +33,33 @@ type PgConfig struct {
 // pgCfg holds the parsed configuration
 var pgCfg PgConfig
 
-// LoadPostgres initializes the PostgreSQL connection pool
-func LoadPostgres() error {
+// Initial connection retry settings for LoadPostgres
+const (
+	pgConnectMaxAttempts    = 10
+	pgConnectInitialBackoff = time.Second
+	pgConnectMaxBackoff     = 30 * time.Second
+)
+
+// DefaultPgConnectTimeout bounds each PostgreSQL connection attempt at startup
+const DefaultPgConnectTimeout = 5 * time.Second
+
+// pgConnectBackoff returns the delay before retry attempt n (1-based): an
+// exponential backoff from 1s, capped at 30s, with up to half of it jittered
+// away so restarting replicas don't retry in lockstep.
+func pgConnectBackoff(n int) time.Duration {
+	backoff := pgConnectMaxBackoff
+	if n < 6 { // 1s << 5 already exceeds the cap
+		backoff = min(pgConnectInitialBackoff<<(n-1), pgConnectMaxBackoff)
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+// LoadPostgres initializes the PostgreSQL connection pool. PostgreSQL being
+// briefly unavailable is common during rolling deploys, so the initial ping is
+// retried with backoff; each attempt is bounded by connectTimeout. It gives up
+// with the last error when ctx is cancelled or all attempts fail.
+func LoadPostgres(ctx context.Context, connectTimeout time.Duration) error {
 	pgCfg.Host = os.Getenv("POSTGRES_HOST")
 	if pgCfg.Host == "" {
 		pgCfg.Host = "localhost"
@@ -82,16 +108,14 @@ func LoadPostgres() error {
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create postgres pool: %w", err)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		return fmt.Errorf("failed to ping postgres: %w", err)
+	if err := pingPostgresWithRetry(ctx, pool, connectTimeout); err != nil {
+		pool.Close()
+		return err
 	}
 
 	PgPool = pool
@@ -107,6 +131,33 @@ func LoadPostgres() error {
 	return nil
 }
 
+// pingPostgresWithRetry pings the pool until it succeeds, ctx is done, or
+// pgConnectMaxAttempts attempts have failed.
+func pingPostgresWithRetry(ctx context.Context, pool *pgxpool.Pool, connectTimeout time.Duration) error {
+	var err error
+	for attempt := 1; attempt <= pgConnectMaxAttempts; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+		err = pool.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == pgConnectMaxAttempts {
+			break
+		}
+
+		backoff := pgConnectBackoff(attempt)
+		log.Printf("PostgreSQL ping failed (attempt %d/%d), retrying in %s: %v",
+			attempt, pgConnectMaxAttempts, backoff.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to ping postgres: %w (gave up: %w)", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+	return fmt.Errorf("failed to ping postgres after %d attempts: %w", pgConnectMaxAttempts, err)
+}
+
 // runMigrations runs database migrations using goose
 func runMigrations(connStr string) error {
 	log.Printf("Running PostgreSQL migrations...")
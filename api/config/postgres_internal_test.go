@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPgConnectBackoff(t *testing.T) {
+	t.Parallel()
+
+	for attempt, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		5:  16 * time.Second,
+		6:  30 * time.Second,
+		9:  30 * time.Second,
+		63: 30 * time.Second,
+	} {
+		for range 20 {
+			got := pgConnectBackoff(attempt)
+			assert.GreaterOrEqual(t, got, want/2, "attempt %d", attempt)
+			assert.LessOrEqual(t, got, want, "attempt %d", attempt)
+		}
+	}
+}
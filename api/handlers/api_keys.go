@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+	"golang.org/x/time/rate"
+)
+
+// API key tiers control the MCP rate limit applied to a key.
+const (
+	APIKeyTierStandard  = "standard"
+	APIKeyTierPremium   = "premium"
+	APIKeyTierUnlimited = "unlimited"
+)
+
+// apiKeyPrefix marks bearer tokens as API keys rather than session tokens.
+const apiKeyPrefix = "lake_"
+
+// Default per-key MCP rates, overridable with RATE_LIMIT_MCP_STANDARD_RPS and RATE_LIMIT_MCP_PREMIUM_RPS.
+const (
+	defaultMCPStandardRPS = 600.0 / 60  // 600 calls per minute
+	defaultMCPPremiumRPS  = 3000.0 / 60 // 3000 calls per minute
+	mcpRateLimitBurst     = 100
+)
+
+// MCPStandardRateLimiter is the shared rate limiter for standard tier API keys, keyed by key ID.
+var MCPStandardRateLimiter = NewRateLimiter(rate.Limit(defaultMCPStandardRPS), mcpRateLimitBurst)
+
+// MCPPremiumRateLimiter is the shared rate limiter for premium tier API keys, keyed by key ID.
+var MCPPremiumRateLimiter = NewRateLimiter(rate.Limit(defaultMCPPremiumRPS), mcpRateLimitBurst)
+
+// apiKeyLastUsedInterval limits how often last_used_at is written for a busy key.
+const apiKeyLastUsedInterval = time.Minute
+
+// apiKeyCacheTTL is how long a looked-up API key is reused before it is checked
+// against the database again, so a deactivated owner loses access within it.
+const apiKeyCacheTTL = 30 * time.Second
+
+// apiKeyCacheEntry is a valid API key along with when it was looked up and
+// when its last_used_at was last written.
+type apiKeyCacheEntry struct {
+	id        uuid.UUID
+	tier      string
+	fetchedAt time.Time
+	touchedAt time.Time
+}
+
+var (
+	apiKeyCache   = make(map[string]apiKeyCacheEntry) // keyed by key hash
+	apiKeyCacheMu sync.Mutex
+)
+
+// APIKey is an API key as returned on creation. Key is only ever returned once.
+type APIKey struct {
+	ID        uuid.UUID `json:"id"`
+	Key       string    `json:"key"`
+	Tier      string    `json:"tier"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateAPIKeyRequest is the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Tier string `json:"tier,omitempty"`
+}
+
+func isValidAPIKeyTier(tier string) bool {
+	switch tier {
+	case APIKeyTierStandard, APIKeyTierPremium, APIKeyTierUnlimited:
+		return true
+	}
+	return false
+}
+
+// generateAPIKey generates a new API key and its hash
+func generateAPIKey() (string, string, error) {
+	keyBytes := make([]byte, 32)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return "", "", err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(keyBytes)
+	return key, hashToken(key), nil
+}
+
+// PostAuthAPIKey handles POST /api/auth/api-keys - creates an API key for the
+// authenticated account. Only admins may request a tier other than standard.
+func PostAuthAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account := GetAccountFromContext(ctx)
+	if account == nil {
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if req.Tier == "" {
+		req.Tier = APIKeyTierStandard
+	}
+	if !isValidAPIKeyTier(req.Tier) {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "tier must be one of standard, premium, unlimited")
+		return
+	}
+	if req.Tier != APIKeyTierStandard && !IsAdmin(account) {
+		writeError(w, r, http.StatusForbidden, ErrCodeForbidden, "Admin access required for this tier")
+		return
+	}
+
+	key, keyHash, err := generateAPIKey()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to generate API key", err))
+		return
+	}
+
+	apiKey := APIKey{Key: key, Tier: req.Tier}
+	err = config.PgPool.QueryRow(ctx, `
+		INSERT INTO api_keys (user_id, key_hash, tier)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, account.ID, keyHash, req.Tier).Scan(&apiKey.ID, &apiKey.CreatedAt)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to create API key", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(apiKey)
+}
+
+// lookupAPIKey returns the ID and tier of the API key, or pgx.ErrNoRows if it doesn't exist
+// or its owner is inactive.
+func lookupAPIKey(ctx context.Context, key string) (uuid.UUID, string, error) {
+	var id uuid.UUID
+	var tier string
+	err := config.PgPool.QueryRow(ctx, `
+		SELECT k.id, k.tier
+		FROM api_keys k
+		INNER JOIN accounts a ON a.id = k.user_id
+		WHERE k.key_hash = $1 AND a.is_active = true
+	`, hashToken(key)).Scan(&id, &tier)
+	return id, tier, err
+}
+
+// cachedAPIKey is lookupAPIKey with results reused for apiKeyCacheTTL. touch
+// reports whether the caller should record the use, which is at most once per
+// apiKeyLastUsedInterval for each key.
+func cachedAPIKey(ctx context.Context, key string) (id uuid.UUID, tier string, touch bool, err error) {
+	keyHash := hashToken(key)
+	now := time.Now()
+
+	apiKeyCacheMu.Lock()
+	entry, ok := apiKeyCache[keyHash]
+	apiKeyCacheMu.Unlock()
+
+	if !ok || now.Sub(entry.fetchedAt) >= apiKeyCacheTTL {
+		id, tier, err := lookupAPIKey(ctx, key)
+		if err != nil {
+			apiKeyCacheMu.Lock()
+			delete(apiKeyCache, keyHash)
+			apiKeyCacheMu.Unlock()
+			return uuid.Nil, "", false, err
+		}
+		entry = apiKeyCacheEntry{id: id, tier: tier, fetchedAt: now, touchedAt: entry.touchedAt}
+	}
+
+	touch = now.Sub(entry.touchedAt) >= apiKeyLastUsedInterval
+	if touch {
+		entry.touchedAt = now
+	}
+	apiKeyCacheMu.Lock()
+	apiKeyCache[keyHash] = entry
+	apiKeyCacheMu.Unlock()
+
+	return entry.id, entry.tier, touch, nil
+}
+
+// touchAPIKey records that the key was used, at most once per apiKeyLastUsedInterval.
+func touchAPIKey(id uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := config.PgPool.Exec(ctx, `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - make_interval(secs => $2))
+	`, id, apiKeyLastUsedInterval.Seconds())
+	if err != nil {
+		slog.Warn("failed to update api key last_used_at", "id", id, "error", err)
+	}
+}
+
+// MCPRateLimitMiddleware rate limits MCP calls per API key according to the key's tier.
+// Requests without a valid API key fall through to QueryRateLimitMiddleware.
+// Must run after OptionalAuth so anonymous callers are limited by IP or account.
+func MCPRateLimitMiddleware(next http.Handler) http.Handler {
+	fallback := QueryRateLimitMiddleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := extractBearerToken(r)
+		if !strings.HasPrefix(key, apiKeyPrefix) || config.PgPool == nil {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		id, tier, touch, err := cachedAPIKey(r.Context(), key)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				slog.Warn("failed to look up api key", "error", err)
+			}
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		if touch {
+			go touchAPIKey(id)
+		}
+
+		var limiter *RateLimiter
+		switch tier {
+		case APIKeyTierUnlimited:
+			next.ServeHTTP(w, r)
+			return
+		case APIKeyTierPremium:
+			limiter = MCPPremiumRateLimiter
+		default:
+			limiter = MCPStandardRateLimiter
+		}
+
+		res := limiter.Check(id.String())
+		setRateLimitHeaders(w, res)
+		if !res.Allowed {
+			writeRateLimitExceeded(w, r, res.RetryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,146 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAPIKey(t *testing.T, account *handlers.Account, body string) (*httptest.ResponseRecorder, handlers.APIKey) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/api-keys", bytes.NewBufferString(body))
+	req = withAccount(req, account)
+	rr := httptest.NewRecorder()
+	handlers.PostAuthAPIKey(rr, req)
+
+	var key handlers.APIKey
+	if rr.Code == http.StatusCreated {
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&key))
+	}
+	return rr, key
+}
+
+func TestPostAuthAPIKey(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+	account := createTestAccount(t, ctx)
+
+	rr, key := createTestAPIKey(t, account, "")
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.True(t, strings.HasPrefix(key.Key, "lake_"))
+	assert.Equal(t, handlers.APIKeyTierStandard, key.Tier)
+
+	// Only the hash is stored
+	var userID, keyHash string
+	require.NoError(t, config.PgPool.QueryRow(ctx,
+		`SELECT user_id::text, key_hash FROM api_keys WHERE id = $1`, key.ID).Scan(&userID, &keyHash))
+	assert.Equal(t, account.ID.String(), userID)
+	assert.NotEqual(t, key.Key, keyHash)
+	assert.Len(t, keyHash, 64)
+}
+
+func TestPostAuthAPIKey_Tiers(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+	account := createTestAccount(t, ctx)
+
+	rr, _ := createTestAPIKey(t, account, `{"tier":"gold"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, _ = createTestAPIKey(t, account, `{"tier":"premium"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "non-admins can only create standard keys")
+
+	email := "ops@example.com"
+	account.Email = &email
+	t.Setenv("AUTH_ADMIN_EMAILS", email)
+	rr, key := createTestAPIKey(t, account, `{"tier":"premium"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.Equal(t, handlers.APIKeyTierPremium, key.Tier)
+}
+
+func TestPostAuthAPIKey_Unauthenticated(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/api-keys", nil)
+	rr := httptest.NewRecorder()
+	handlers.PostAuthAPIKey(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func mcpCall(handler http.Handler, token, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/mcp", nil)
+	req.RemoteAddr = ip + ":1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMCPRateLimitMiddleware(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+	account := createTestAccount(t, ctx)
+
+	handler := handlers.MCPRateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("standard key uses tier limit", func(t *testing.T) {
+		_, key := createTestAPIKey(t, account, "")
+		rr := mcpCall(handler, key.Key, "10.0.0.1")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "100", rr.Header().Get("X-RateLimit-Limit"))
+
+		// last_used_at is updated in the background
+		require.Eventually(t, func() bool {
+			var lastUsed *time.Time
+			err := config.PgPool.QueryRow(ctx, `SELECT last_used_at FROM api_keys WHERE id = $1`, key.ID).Scan(&lastUsed)
+			return err == nil && lastUsed != nil
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("unlimited key skips rate limiting", func(t *testing.T) {
+		_, key := createTestAPIKey(t, account, "")
+		_, err := config.PgPool.Exec(ctx, `UPDATE api_keys SET tier = 'unlimited' WHERE id = $1`, key.ID)
+		require.NoError(t, err)
+
+		rr := mcpCall(handler, key.Key, "10.0.0.2")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("repeated calls reuse the looked-up key", func(t *testing.T) {
+		_, key := createTestAPIKey(t, account, "")
+		rr := mcpCall(handler, key.Key, "10.0.0.5")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		// The tier change isn't seen until the cached lookup expires
+		_, err := config.PgPool.Exec(ctx, `UPDATE api_keys SET tier = 'unlimited' WHERE id = $1`, key.ID)
+		require.NoError(t, err)
+		rr = mcpCall(handler, key.Key, "10.0.0.5")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "100", rr.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("unknown key falls through to IP limiter", func(t *testing.T) {
+		rr := mcpCall(handler, "lake_not-a-real-key", "10.0.0.3")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "20", rr.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("anonymous falls through to IP limiter", func(t *testing.T) {
+		rr := mcpCall(handler, "", "10.0.0.4")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "20", rr.Header().Get("X-RateLimit-Limit"))
+	})
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// ASNPathHop is one autonomous system on an AS-level path
+type ASNPathHop struct {
+	ASN         int64  `json:"asn"`
+	Org         string `json:"org"`
+	DeviceCount int    `json:"deviceCount"`
+}
+
+// ASNPathResponse is the response for the ASN path endpoint
+type ASNPathResponse struct {
+	From string       `json:"from"`
+	To   string       `json:"to"`
+	Path []ASNPathHop `json:"path"`
+}
+
+// GetASNPaths returns the AS-level path between two devices. Each device's
+// public IP is resolved to an ASN via GeoIP, and the shortest path is found
+// over the as_adjacency view maintained by the indexer.
+func GetASNPaths(w http.ResponseWriter, r *http.Request) {
+	fromPK := r.URL.Query().Get("from")
+	toPK := r.URL.Query().Get("to")
+	if fromPK == "" || toPK == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "from and to parameters are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	// Resolve every device to its ASN so we can count devices per AS
+	deviceQuery := `
+		SELECT d.pk, g.asn, g.asn_org
+		FROM dz_devices_current d
+		JOIN geoip_records_current g ON g.ip = d.public_ip
+		WHERE d.public_ip != ''
+		  AND g.asn != 0
+	`
+	rows, err := envDB(ctx).Query(ctx, deviceQuery)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("ASN paths device query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	deviceASN := make(map[string]int64)
+	hops := make(map[int64]*ASNPathHop)
+	for rows.Next() {
+		var pk, org string
+		var asn int64
+		if err := rows.Scan(&pk, &asn, &org); err != nil {
+			rows.Close()
+			LoggerFromContext(ctx).Error("ASN paths device scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		deviceASN[pk] = asn
+		hop, ok := hops[asn]
+		if !ok {
+			hop = &ASNPathHop{ASN: asn, Org: org}
+			hops[asn] = hop
+		}
+		hop.DeviceCount++
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		LoggerFromContext(ctx).Error("ASN paths device rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	fromASN, ok := deviceASN[fromPK]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "no ASN found for from device")
+		return
+	}
+	toASN, ok := deviceASN[toPK]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "no ASN found for to device")
+		return
+	}
+
+	adjRows, err := envDB(ctx).Query(ctx, `
+		SELECT DISTINCT side_a_asn, side_z_asn
+		FROM as_adjacency
+		WHERE side_a_asn != side_z_asn
+	`)
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("ASN paths adjacency query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer adjRows.Close()
+
+	adjacency := make(map[int64][]int64)
+	for adjRows.Next() {
+		var a, z int64
+		if err := adjRows.Scan(&a, &z); err != nil {
+			LoggerFromContext(ctx).Error("ASN paths adjacency scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		adjacency[a] = append(adjacency[a], z)
+		adjacency[z] = append(adjacency[z], a)
+	}
+	if err := adjRows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("ASN paths adjacency rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	asns := shortestASNPath(adjacency, fromASN, toASN)
+	if asns == nil {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "no AS path found between devices")
+		return
+	}
+
+	response := ASNPathResponse{
+		From: fromPK,
+		To:   toPK,
+		Path: make([]ASNPathHop, 0, len(asns)),
+	}
+	for _, asn := range asns {
+		if hop, ok := hops[asn]; ok {
+			response.Path = append(response.Path, *hop)
+		} else {
+			response.Path = append(response.Path, ASNPathHop{ASN: asn})
+		}
+	}
+
+	LoggerFromContext(ctx).Info("ASN path query completed", "hops", len(response.Path), "duration_ms", duration.Milliseconds())
+	writeJSON(w, response)
+}
+
+// shortestASNPath runs a BFS over the AS adjacency graph and returns the ASNs
+// from src to dst inclusive, or nil if dst is unreachable.
+func shortestASNPath(adjacency map[int64][]int64, src, dst int64) []int64 {
+	if src == dst {
+		return []int64{src}
+	}
+
+	prev := map[int64]int64{src: src}
+	queue := []int64{src}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[cur] {
+			if _, seen := prev[next]; seen {
+				continue
+			}
+			prev[next] = cur
+			if next == dst {
+				var path []int64
+				for asn := dst; asn != src; asn = prev[asn] {
+					path = append(path, asn)
+				}
+				path = append(path, src)
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
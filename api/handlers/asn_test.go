@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedASNData inserts four devices across three ASNs linked in a chain:
+// dev-1 (AS100) - dev-2 (AS200) - dev-3 (AS300), plus dev-4 in AS100.
+func seedASNData(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-1', now(), now(), generateUUIDv4(), 0, 1, 'dev-1', 'activated', 'hybrid', 'DEV-1', '1.0.0.1', '', '', 0),
+		('dev-2', now(), now(), generateUUIDv4(), 0, 2, 'dev-2', 'activated', 'hybrid', 'DEV-2', '2.0.0.1', '', '', 0),
+		('dev-3', now(), now(), generateUUIDv4(), 0, 3, 'dev-3', 'activated', 'hybrid', 'DEV-3', '3.0.0.1', '', '', 0),
+		('dev-4', now(), now(), generateUUIDv4(), 0, 4, 'dev-4', 'activated', 'hybrid', 'DEV-4', '1.0.0.2', '', '', 0),
+		('dev-5', now(), now(), generateUUIDv4(), 0, 5, 'dev-5', 'activated', 'hybrid', 'DEV-5', '', '', '', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_geoip_records_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, ip, asn, asn_org)
+		VALUES
+		('1.0.0.1', now(), now(), generateUUIDv4(), 0, 1, '1.0.0.1', 100, 'Org A'),
+		('1.0.0.2', now(), now(), generateUUIDv4(), 0, 2, '1.0.0.2', 100, 'Org A'),
+		('2.0.0.1', now(), now(), generateUUIDv4(), 0, 3, '2.0.0.1', 200, 'Org B'),
+		('3.0.0.1', now(), now(), generateUUIDv4(), 0, 4, '3.0.0.1', 300, 'Org C')`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now(), now(), generateUUIDv4(), 0, 1, 'link-1', 'activated', 'LINK-1', '', '', 'dev-1', 'dev-2', '', '', 'WAN', 0, 0, 0, 0),
+		('link-2', now(), now(), generateUUIDv4(), 0, 2, 'link-2', 'activated', 'LINK-2', '', '', 'dev-2', 'dev-3', '', '', 'WAN', 0, 0, 0, 0)`))
+}
+
+func getASNPaths(t *testing.T, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/asn-paths"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetASNPaths(rr, req)
+	return rr
+}
+
+func TestGetASNPaths(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedASNData(t)
+
+	rr := getASNPaths(t, "?from=dev-1&to=dev-3")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.ASNPathResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, []handlers.ASNPathHop{
+		{ASN: 100, Org: "Org A", DeviceCount: 2},
+		{ASN: 200, Org: "Org B", DeviceCount: 1},
+		{ASN: 300, Org: "Org C", DeviceCount: 1},
+	}, resp.Path)
+}
+
+func TestGetASNPaths_SameASN(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedASNData(t)
+
+	rr := getASNPaths(t, "?from=dev-1&to=dev-4")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.ASNPathResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.Path, 1)
+	assert.Equal(t, int64(100), resp.Path[0].ASN)
+}
+
+func TestGetASNPaths_Errors(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedASNData(t)
+
+	assert.Equal(t, http.StatusBadRequest, getASNPaths(t, "?from=dev-1").Code)
+	assert.Equal(t, http.StatusNotFound, getASNPaths(t, "?from=dev-1&to=dev-5").Code)
+	assert.Equal(t, http.StatusNotFound, getASNPaths(t, "?from=dev-4&to=missing").Code)
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// AuditLogEntry is a single row of the audit log
+type AuditLogEntry struct {
+	ID              int64      `json:"id"`
+	UserID          *uuid.UUID `json:"user_id,omitempty"`
+	Action          string     `json:"action"`
+	ResourceType    string     `json:"resource_type"`
+	ResourceID      *string    `json:"resource_id,omitempty"`
+	RequestBodyHash *string    `json:"request_body_hash,omitempty"`
+	IPAddress       *string    `json:"ip_address,omitempty"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// auditWriteTimeout bounds how long a request waits on the audit insert.
+const auditWriteTimeout = 2 * time.Second
+
+// isMutatingMethod reports whether requests with this method are audited
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// AuditMiddleware records mutating requests (POST, PUT, PATCH, DELETE) in the
+// audit_log table once the handler has returned a non-5xx response.
+// Must run after OptionalAuth so the account and client IP are in the context.
+// Failures to write the audit log are logged and never affect the response.
+func AuditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingMethod(r.Method) || config.PgPool == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Hash the body as the handler reads it
+		hasher := sha256.New()
+		body := r.Body
+		if body != nil && body != http.NoBody {
+			r.Body = &hashingReadCloser{ReadCloser: body, hash: hasher}
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		if ww.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		var bodyHash *string
+		if body != nil && body != http.NoBody {
+			// Include any bytes the handler didn't consume
+			_, _ = io.Copy(hasher, io.LimitReader(body, 10<<20))
+			sum := hex.EncodeToString(hasher.Sum(nil))
+			bodyHash = &sum
+		}
+
+		pattern := ""
+		var resourceID *string
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			pattern = rctx.RoutePattern()
+			for i, key := range rctx.URLParams.Keys {
+				if key != "*" && rctx.URLParams.Values[i] != "" {
+					id := rctx.URLParams.Values[i]
+					resourceID = &id
+					break
+				}
+			}
+		}
+		if pattern == "" {
+			pattern = r.URL.Path
+		}
+
+		var userID *uuid.UUID
+		if account := GetAccountFromContext(r.Context()); account != nil {
+			userID = &account.ID
+		}
+		var ip *string
+		if addr := GetIPFromContext(r.Context()); addr != "" {
+			ip = &addr
+		}
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), auditWriteTimeout)
+		defer cancel()
+
+		_, err := config.PgPool.Exec(ctx, `
+			INSERT INTO audit_log (user_id, action, resource_type, resource_id, request_body_hash, ip_address)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, userID, r.Method+" "+pattern, auditResourceType(pattern), resourceID, bodyHash, ip)
+		if err != nil {
+			LoggerFromContext(r.Context()).Warn("failed to write audit log", "route", pattern, "error", err)
+		}
+	})
+}
+
+// auditResourceType derives the resource type from a route pattern,
+// e.g. "/api/sessions/{id}" -> "sessions", "/api/slack/installations/{team_id}" -> "slack".
+func auditResourceType(pattern string) string {
+	parts := strings.Split(strings.Trim(pattern, "/"), "/")
+	if len(parts) > 1 && parts[0] == "api" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return "unknown"
+	}
+	return parts[0]
+}
+
+// hashingReadCloser feeds everything read from the body into a hash
+type hashingReadCloser struct {
+	io.ReadCloser
+	hash hash.Hash
+}
+
+func (h *hashingReadCloser) Read(p []byte) (int, error) {
+	n, err := h.ReadCloser.Read(p)
+	if n > 0 {
+		h.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+// getAdminEmails returns the list of email addresses with admin access
+func getAdminEmails() []string {
+	emails := os.Getenv("AUTH_ADMIN_EMAILS")
+	if emails == "" {
+		return nil
+	}
+	return strings.Split(emails, ",")
+}
+
+// IsAdmin reports whether the account is allowed to use admin endpoints
+func IsAdmin(account *Account) bool {
+	if account == nil || account.Email == nil {
+		return false
+	}
+	for _, e := range getAdminEmails() {
+		if strings.EqualFold(strings.TrimSpace(e), *account.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAdmin middleware returns 403 unless the authenticated account is an admin.
+// Must run after RequireAuth.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsAdmin(GetAccountFromContext(r.Context())) {
+			writeError(w, r, http.StatusForbidden, ErrCodeForbidden, "Admin access required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetAuditLog returns audit log entries, newest first, with pagination and
+// optional start/end (RFC3339) and user_id filters.
+func GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pagination := ParsePagination(r, DefaultLimit)
+
+	conditions := []string{"1=1"}
+	var args []any
+	addArg := func(cond string, v any) {
+		args = append(args, v)
+		conditions = append(conditions, strings.ReplaceAll(cond, "?", "$"+itoa(len(args))))
+	}
+
+	if s := r.URL.Query().Get("start"); s != "" {
+		start, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "start must be an RFC3339 timestamp")
+			return
+		}
+		addArg("timestamp >= ?", start)
+	}
+	if s := r.URL.Query().Get("end"); s != "" {
+		end, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "end must be an RFC3339 timestamp")
+			return
+		}
+		addArg("timestamp < ?", end)
+	}
+	if s := r.URL.Query().Get("user_id"); s != "" {
+		userID, err := uuid.Parse(s)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "user_id must be a UUID")
+			return
+		}
+		addArg("user_id = ?", userID)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := config.PgPool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_log WHERE `+where, args...).Scan(&total); err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to count audit log", err))
+		return
+	}
+
+	args = append(args, pagination.Limit, pagination.Offset)
+	rows, err := config.PgPool.Query(ctx, `
+		SELECT id, user_id, action, resource_type, resource_id, request_body_hash, ip_address, timestamp
+		FROM audit_log
+		WHERE `+where+`
+		ORDER BY timestamp DESC, id DESC
+		LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to query audit log", err))
+		return
+	}
+	defer rows.Close()
+
+	entries := []AuditLogEntry{}
+	for rows.Next() {
+		var e AuditLogEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Action, &e.ResourceType, &e.ResourceID, &e.RequestBodyHash, &e.IPAddress, &e.Timestamp); err != nil {
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to scan audit log", err))
+			return
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to iterate audit log", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(PaginatedResponse[AuditLogEntry]{
+		Items:  entries,
+		Total:  total,
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+	})
+}
@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuditRouter returns a router with the audit middleware applied and the
+// given account injected into every request.
+func newAuditRouter(account *handlers.Account) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if account != nil {
+				req = withAccount(req, account)
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Use(handlers.AuditMiddleware)
+	r.Get("/api/admin/audit-log", handlers.GetAuditLog)
+	r.Put("/api/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Delete("/api/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	return r
+}
+
+func TestAuditMiddleware_RecordsMutatingRequests(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+	account := createTestAccount(t, ctx)
+	router := newAuditRouter(account)
+
+	sessionID := uuid.New().String()
+	body := []byte(`{"name":"renamed"}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/sessions/"+sessionID, bytes.NewReader(body))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// 5xx responses and reads are not audited
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/api/sessions/"+sessionID, nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/admin/audit-log", nil))
+
+	var count int
+	require.NoError(t, config.PgPool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_log`).Scan(&count))
+	require.Equal(t, 1, count)
+
+	var entry handlers.AuditLogEntry
+	require.NoError(t, config.PgPool.QueryRow(ctx, `
+		SELECT user_id, action, resource_type, resource_id, request_body_hash FROM audit_log
+	`).Scan(&entry.UserID, &entry.Action, &entry.ResourceType, &entry.ResourceID, &entry.RequestBodyHash))
+
+	sum := sha256.Sum256(body)
+	require.NotNil(t, entry.UserID)
+	assert.Equal(t, account.ID, *entry.UserID)
+	assert.Equal(t, "PUT /api/sessions/{id}", entry.Action)
+	assert.Equal(t, "sessions", entry.ResourceType)
+	require.NotNil(t, entry.ResourceID)
+	assert.Equal(t, sessionID, *entry.ResourceID)
+	require.NotNil(t, entry.RequestBodyHash)
+	assert.Equal(t, hex.EncodeToString(sum[:]), *entry.RequestBodyHash)
+}
+
+func TestGetAuditLog(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO audit_log (action, resource_type, timestamp) VALUES
+		('POST /api/sessions', 'sessions', '2025-01-01T00:00:00Z'),
+		('PUT /api/sessions/{id}', 'sessions', '2025-01-02T00:00:00Z'),
+		('DELETE /api/sessions/{id}', 'sessions', '2025-01-03T00:00:00Z')
+	`)
+	require.NoError(t, err)
+
+	get := func(query string) handlers.PaginatedResponse[handlers.AuditLogEntry] {
+		rr := httptest.NewRecorder()
+		handlers.GetAuditLog(rr, httptest.NewRequest(http.MethodGet, "/api/admin/audit-log"+query, nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var resp handlers.PaginatedResponse[handlers.AuditLogEntry]
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		return resp
+	}
+
+	resp := get("?limit=2")
+	assert.Equal(t, 3, resp.Total)
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, "DELETE /api/sessions/{id}", resp.Items[0].Action)
+
+	resp = get("?start=2025-01-02T00:00:00Z&end=2025-01-03T00:00:00Z")
+	assert.Equal(t, 1, resp.Total)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "PUT /api/sessions/{id}", resp.Items[0].Action)
+
+	rr := httptest.NewRecorder()
+	handlers.GetAuditLog(rr, httptest.NewRequest(http.MethodGet, "/api/admin/audit-log?start=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRequireAdmin(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_EMAILS", "admin@example.com")
+
+	handler := handlers.RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	admin := "Admin@example.com"
+	other := "user@example.com"
+	for _, tc := range []struct {
+		name    string
+		account *handlers.Account
+		want    int
+	}{
+		{"admin", &handlers.Account{Email: &admin}, http.StatusOK},
+		{"non-admin", &handlers.Account{Email: &other}, http.StatusForbidden},
+		{"wallet", &handlers.Account{}, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, withAccount(httptest.NewRequest(http.MethodGet, "/", nil), tc.account))
+			assert.Equal(t, tc.want, rr.Code)
+		})
+	}
+}
@@ -72,8 +72,9 @@ type GoogleAuthRequest struct {
 
 // GoogleAuthResponse is the response for Google OAuth
 type GoogleAuthResponse struct {
-	Token   string   `json:"token"`
-	Account *Account `json:"account"`
+	Token        string   `json:"token"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	Account      *Account `json:"account"`
 }
 
 // WalletNonceResponse is the response for nonce request
@@ -91,8 +92,9 @@ type WalletAuthRequest struct {
 
 // WalletAuthResponse is the response for wallet authentication
 type WalletAuthResponse struct {
-	Token   string   `json:"token"`
-	Account *Account `json:"account"`
+	Token        string   `json:"token"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	Account      *Account `json:"account"`
 }
 
 // Session token lifetime
@@ -169,16 +171,19 @@ func extractEmailDomain(email string) string {
 	return strings.ToLower(parts[1])
 }
 
-// createSession creates a new auth session for an account
-func createSession(ctx context.Context, accountID uuid.UUID) (string, error) {
+// createSession creates a new auth session for an account, valid until expiresAt
+func createSession(ctx context.Context, accountID uuid.UUID, expiresAt time.Time) (string, error) {
+	return insertSession(ctx, config.PgPool, accountID, expiresAt)
+}
+
+// insertSession creates a session with db, which may be a transaction
+func insertSession(ctx context.Context, db pgExecutor, accountID uuid.UUID, expiresAt time.Time) (string, error) {
 	token, tokenHash, err := generateSessionToken()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	expiresAt := time.Now().Add(sessionTokenLifetime)
-
-	_, err = config.PgPool.Exec(ctx, `
+	_, err = db.Exec(ctx, `
 		INSERT INTO auth_sessions (account_id, token_hash, expires_at)
 		VALUES ($1, $2, $3)
 	`, accountID, tokenHash, expiresAt)
@@ -345,11 +350,24 @@ func GetAuthMe(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// PostAuthLogout handles POST /api/auth/logout
+// PostAuthLogout handles POST /api/auth/logout. A refresh token in the body
+// is revoked along with the session.
 func PostAuthLogout(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	var req RefreshRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+	if req.RefreshToken != "" {
+		if _, err := config.PgPool.Exec(ctx, `
+			DELETE FROM refresh_tokens WHERE token_hash = $1
+		`, hashToken(req.RefreshToken)); err != nil {
+			slog.Error("Failed to delete refresh token", "error", err)
+		}
+	}
+
 	// Get token from header
 	token := extractBearerToken(r)
 	if token == "" {
@@ -378,7 +396,7 @@ func GetAuthNonce(w http.ResponseWriter, r *http.Request) {
 	nonce, err := generateNonce()
 	if err != nil {
 		slog.Error("Failed to generate nonce", "error", err)
-		http.Error(w, "Failed to generate nonce", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to generate nonce")
 		return
 	}
 
@@ -392,7 +410,7 @@ func GetAuthNonce(w http.ResponseWriter, r *http.Request) {
 	`, nonce)
 	if err != nil {
 		slog.Error("Failed to store nonce", "error", err)
-		http.Error(w, "Failed to store nonce", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to store nonce")
 		return
 	}
 
@@ -409,31 +427,31 @@ func PostAuthWallet(w http.ResponseWriter, r *http.Request) {
 
 	var req WalletAuthRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if req.PublicKey == "" || req.Signature == "" || req.Message == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Missing required fields")
 		return
 	}
 
 	// Validate public key format (Solana base58, 32-44 chars)
 	if len(req.PublicKey) < 32 || len(req.PublicKey) > 44 {
-		http.Error(w, "Invalid public key format", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid public key format")
 		return
 	}
 
 	// Extract nonce from message and verify it exists and is not expired
 	// Message format: "Sign this message to authenticate with Lake.\n\nNonce: <nonce>"
 	if !strings.Contains(req.Message, "Nonce: ") {
-		http.Error(w, "Invalid message format", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid message format")
 		return
 	}
 
 	nonceParts := strings.Split(req.Message, "Nonce: ")
 	if len(nonceParts) != 2 {
-		http.Error(w, "Invalid message format", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid message format")
 		return
 	}
 	nonce := strings.TrimSpace(nonceParts[1])
@@ -446,7 +464,7 @@ func PostAuthWallet(w http.ResponseWriter, r *http.Request) {
 	`, nonce).Scan(&expiresAt)
 	if err != nil {
 		slog.Warn("Invalid or expired nonce", "nonce", nonce, "error", err)
-		http.Error(w, "Invalid or expired nonce", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid or expired nonce")
 		return
 	}
 
@@ -455,7 +473,7 @@ func PostAuthWallet(w http.ResponseWriter, r *http.Request) {
 	valid, err := verifyEd25519Signature(req.PublicKey, req.Message, req.Signature)
 	if err != nil || !valid {
 		slog.Warn("Invalid signature", "publicKey", req.PublicKey, "error", err)
-		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid signature")
 		return
 	}
 
@@ -474,7 +492,7 @@ func PostAuthWallet(w http.ResponseWriter, r *http.Request) {
 	)
 	if err != nil {
 		slog.Error("Failed to create/update account", "error", err)
-		http.Error(w, "Failed to create account", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to create account")
 		return
 	}
 	account.DisplayName = displayName
@@ -508,17 +526,24 @@ func PostAuthWallet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create session
-	token, err := createSession(ctx, account.ID)
+	token, err := createSession(ctx, account.ID, time.Now().Add(sessionTokenLifetime))
 	if err != nil {
 		slog.Error("Failed to create session", "error", err)
-		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to create session")
 		return
 	}
 
+	// Issue a refresh token; the session token works without one, so don't fail auth
+	refreshToken, _, err := createRefreshToken(ctx, config.PgPool, account.ID)
+	if err != nil {
+		slog.Error("Failed to create refresh token", "error", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(WalletAuthResponse{
-		Token:   token,
-		Account: &account,
+		Token:        token,
+		RefreshToken: refreshToken,
+		Account:      &account,
 	}); err != nil {
 		slog.Error("Failed to encode response", "error", err)
 	}
@@ -531,12 +556,12 @@ func PostAuthGoogle(w http.ResponseWriter, r *http.Request) {
 
 	var req GoogleAuthRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if req.IDToken == "" {
-		http.Error(w, "Missing id_token", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Missing id_token")
 		return
 	}
 
@@ -544,7 +569,7 @@ func PostAuthGoogle(w http.ResponseWriter, r *http.Request) {
 	claims, err := verifyGoogleIDToken(ctx, req.IDToken)
 	if err != nil {
 		slog.Warn("Invalid Google ID token", "error", err)
-		http.Error(w, "Invalid ID token", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid ID token")
 		return
 	}
 
@@ -552,7 +577,7 @@ func PostAuthGoogle(w http.ResponseWriter, r *http.Request) {
 	emailDomain := extractEmailDomain(claims.Email)
 	if !isEmailAllowed(claims.Email, emailDomain) {
 		slog.Warn("Email not allowed", "email", claims.Email, "domain", emailDomain)
-		http.Error(w, "Email not authorized", http.StatusForbidden)
+		writeError(w, r, http.StatusForbidden, ErrCodeForbidden, "Email not authorized")
 		return
 	}
 
@@ -573,7 +598,7 @@ func PostAuthGoogle(w http.ResponseWriter, r *http.Request) {
 	)
 	if err != nil {
 		slog.Error("Failed to create/update account", "error", err)
-		http.Error(w, "Failed to create account", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to create account")
 		return
 	}
 	account.WalletAddress = walletAddress
@@ -588,17 +613,24 @@ func PostAuthGoogle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create session
-	token, err := createSession(ctx, account.ID)
+	token, err := createSession(ctx, account.ID, time.Now().Add(sessionTokenLifetime))
 	if err != nil {
 		slog.Error("Failed to create session", "error", err)
-		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to create session")
 		return
 	}
 
+	// Issue a refresh token; the session token works without one, so don't fail auth
+	refreshToken, _, err := createRefreshToken(ctx, config.PgPool, account.ID)
+	if err != nil {
+		slog.Error("Failed to create refresh token", "error", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(GoogleAuthResponse{
-		Token:   token,
-		Account: &account,
+		Token:        token,
+		RefreshToken: refreshToken,
+		Account:      &account,
 	})
 }
 
@@ -613,7 +645,7 @@ func GetUsageQuota(w http.ResponseWriter, r *http.Request) {
 	quota, err := GetQuotaForAccount(ctx, account, ip)
 	if err != nil {
 		slog.Error("Failed to get quota", "error", err)
-		http.Error(w, "Failed to get quota", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to get quota")
 		return
 	}
 
@@ -621,6 +653,28 @@ func GetUsageQuota(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(quota)
 }
 
+// GetUsageHistory handles GET /api/usage/history
+func GetUsageHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account := GetAccountFromContext(ctx)
+	if account == nil {
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
+		return
+	}
+
+	history, err := GetUsageHistoryForAccount(ctx, account.ID)
+	if err != nil {
+		slog.Error("Failed to get usage history", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to get usage history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(history)
+}
+
 // extractBearerToken extracts the token from Authorization header
 func extractBearerToken(r *http.Request) string {
 	auth := r.Header.Get("Authorization")
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // Context keys for auth
@@ -53,13 +54,13 @@ func RequireAuth(next http.Handler) http.Handler {
 		// Try to authenticate
 		token := extractBearerToken(r)
 		if token == "" {
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
 			return
 		}
 
 		account, err := GetAccountByToken(ctx, token)
 		if err != nil || account == nil {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid or expired token")
 			return
 		}
 
@@ -131,7 +132,9 @@ func SetQuotaHeaders(w http.ResponseWriter, quota *QuotaInfo) {
 
 	// Parse ResetsAt and convert to Unix timestamp
 	if quota.ResetsAt != "" {
-		w.Header().Set("X-RateLimit-Reset", quota.ResetsAt)
+		if resetsAt, err := time.Parse(time.RFC3339, quota.ResetsAt); err == nil {
+			w.Header().Set("X-RateLimit-Reset", itoa(int(resetsAt.Unix())))
+		}
 	}
 }
 
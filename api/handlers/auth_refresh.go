@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// Access tokens issued by a refresh are short-lived; the refresh token keeps
+// the login alive for as long as a session token issued at login would.
+const (
+	accessTokenLifetime  = 15 * time.Minute
+	refreshTokenLifetime = sessionTokenLifetime
+)
+
+// RefreshRequest is the request body for POST /api/auth/refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshResponse is the response for POST /api/auth/refresh. RefreshToken
+// replaces the one sent in the request, which can't be used again.
+type RefreshResponse struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
+// pgExecutor is satisfied by both config.PgPool and a pgx.Tx, so token
+// helpers can run on their own or as part of a rotation.
+type pgExecutor interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// createRefreshToken issues a new refresh token for an account and returns it
+// along with its ID.
+func createRefreshToken(ctx context.Context, db pgExecutor, accountID uuid.UUID) (string, uuid.UUID, error) {
+	token, tokenHash, err := generateSessionToken()
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	var id uuid.UUID
+	err = db.QueryRow(ctx, `
+		INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, accountID, tokenHash, time.Now().Add(refreshTokenLifetime)).Scan(&id)
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return token, id, nil
+}
+
+// consumeRefreshToken marks a refresh token as used and returns its ID and
+// owner. It returns pgx.ErrNoRows if the token is unknown, expired, already
+// used, revoked or belongs to an inactive account. Marking it used in the same
+// statement means two concurrent refreshes can't both succeed.
+func consumeRefreshToken(ctx context.Context, db pgExecutor, token string) (uuid.UUID, uuid.UUID, error) {
+	var id, accountID uuid.UUID
+	err := db.QueryRow(ctx, `
+		UPDATE refresh_tokens t SET used_at = NOW()
+		FROM accounts a
+		WHERE t.token_hash = $1 AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > NOW()
+		  AND a.id = t.user_id AND a.is_active = true
+		RETURNING t.id, t.user_id
+	`, hashToken(token)).Scan(&id, &accountID)
+	return id, accountID, err
+}
+
+// revokeIfReused checks whether a refresh token that couldn't be consumed had
+// already been rotated. A rotated token being presented again means it was
+// copied, so every session and refresh token of its owner is revoked and both
+// the thief and the user have to log in again. Revoked tokens don't count as
+// reused, so replaying the same stale token can't keep logging the user out.
+func revokeIfReused(ctx context.Context, token string) (bool, error) {
+	var accountID uuid.UUID
+	err := config.PgPool.QueryRow(ctx, `
+		SELECT user_id FROM refresh_tokens
+		WHERE token_hash = $1 AND rotated_to IS NOT NULL AND revoked_at IS NULL
+	`, hashToken(token)).Scan(&accountID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	slog.Warn("Refresh token reuse detected, revoking all tokens", "accountID", accountID)
+	err = pgx.BeginFunc(ctx, config.PgPool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL
+		`, accountID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM auth_sessions WHERE account_id = $1
+		`, accountID); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		return nil
+	})
+	return true, err
+}
+
+// PostAuthRefresh handles POST /api/auth/refresh - exchanges a refresh token
+// for a new short-lived session token and a new refresh token. Refresh tokens
+// are single-use; reusing one revokes all of the account's tokens.
+func PostAuthRefresh(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+	if req.RefreshToken == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Missing refresh_token")
+		return
+	}
+
+	// The old token is only spent if the replacement and session are issued too
+	tx, err := config.PgPool.Begin(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to begin transaction", err))
+		return
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	oldID, accountID, err := consumeRefreshToken(ctx, tx, req.RefreshToken)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_ = tx.Rollback(ctx)
+		if _, err := revokeIfReused(ctx, req.RefreshToken); err != nil {
+			slog.Error("Failed to revoke tokens after refresh token reuse", "error", err)
+		}
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid or expired refresh token")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to verify refresh token", err))
+		return
+	}
+
+	refreshToken, newID, err := createRefreshToken(ctx, tx, accountID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to create refresh token", err))
+		return
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE refresh_tokens SET rotated_to = $2 WHERE id = $1
+	`, oldID, newID); err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to record refresh token rotation", err))
+		return
+	}
+
+	expiresAt := time.Now().Add(accessTokenLifetime)
+	token, err := insertSession(ctx, tx, accountID, expiresAt)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to create session", err))
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to commit refresh", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(RefreshResponse{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	})
+}
@@ -0,0 +1,122 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// insertRefreshToken stores a refresh token for the account, expiring after interval
+func insertRefreshToken(t *testing.T, ctx context.Context, accountID uuid.UUID, interval string) string {
+	t.Helper()
+	token := "test_refresh_" + uuid.New().String()
+	sum := sha256.Sum256([]byte(token))
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, NOW() + $3::interval)
+	`, accountID, hex.EncodeToString(sum[:]), interval)
+	require.NoError(t, err)
+	return token
+}
+
+func postAuthRefresh(refreshToken string) *httptest.ResponseRecorder {
+	body := `{"refresh_token":"` + refreshToken + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handlers.PostAuthRefresh(rr, req)
+	return rr
+}
+
+func TestPostAuthRefresh_Rotates(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	refreshToken := insertRefreshToken(t, ctx, account.ID, "1 day")
+
+	rr := postAuthRefresh(refreshToken)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var response handlers.RefreshResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.NotEmpty(t, response.Token)
+	assert.NotEmpty(t, response.RefreshToken)
+	assert.NotEqual(t, refreshToken, response.RefreshToken)
+
+	// The new access token authenticates the account
+	got, err := handlers.GetAccountByToken(ctx, response.Token)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, got.ID)
+
+	// The old refresh token is marked used and points at its replacement
+	var rotated int
+	err = config.PgPool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM refresh_tokens
+		WHERE user_id = $1 AND used_at IS NOT NULL AND rotated_to IS NOT NULL
+	`, account.ID).Scan(&rotated)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rotated)
+
+	// The new refresh token works in turn
+	rr = postAuthRefresh(response.RefreshToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestPostAuthRefresh_ReuseRevokesAllTokens(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	refreshToken := insertRefreshToken(t, ctx, account.ID, "1 day")
+
+	rr := postAuthRefresh(refreshToken)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response handlers.RefreshResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+
+	// Replaying the used token is rejected and revokes everything
+	rr = postAuthRefresh(refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	_, err := handlers.GetAccountByToken(ctx, response.Token)
+	assert.Error(t, err)
+
+	rr = postAuthRefresh(response.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// After logging in again, replaying the stale tokens doesn't revoke the new login
+	fresh := insertRefreshToken(t, ctx, account.ID, "1 day")
+	assert.Equal(t, http.StatusUnauthorized, postAuthRefresh(refreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, postAuthRefresh(response.RefreshToken).Code)
+	rr = postAuthRefresh(fresh)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
+
+func TestPostAuthRefresh_Invalid(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	expired := insertRefreshToken(t, ctx, account.ID, "-1 hour")
+
+	rr := postAuthRefresh(expired)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = postAuthRefresh("unknown_refresh_token")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = postAuthRefresh("")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
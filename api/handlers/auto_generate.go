@@ -25,12 +25,12 @@ type AutoGenerateRequest struct {
 func AutoGenerateStream(w http.ResponseWriter, r *http.Request) {
 	var req AutoGenerateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Prompt) == "" {
-		http.Error(w, "Prompt is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Prompt is required")
 		return
 	}
 
@@ -42,7 +42,7 @@ func AutoGenerateStream(w http.ResponseWriter, r *http.Request) {
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Streaming not supported")
 		return
 	}
 
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// BFDSession is the BFD session protecting an IS-IS adjacency
+type BFDSession struct {
+	SourcePK   string `json:"sourcePK"`
+	SourceCode string `json:"sourceCode"`
+	TargetPK   string `json:"targetPK"`
+	TargetCode string `json:"targetCode"`
+	BFDState   string `json:"bfdState"`
+	MinTxUs    int64  `json:"minTxUs"`
+	MinRxUs    int64  `json:"minRxUs"`
+	Multiplier int64  `json:"multiplier"`
+	Severity   string `json:"severity"` // "critical" or "normal"
+}
+
+// BFDSessionsResponse is the response for the BFD sessions endpoint
+type BFDSessionsResponse struct {
+	Sessions []BFDSession `json:"sessions"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// bfdSessionSeverity returns "critical" for sessions that are down, since the
+// adjacency has lost fast failure detection, and "normal" otherwise
+func bfdSessionSeverity(state string) string {
+	if state == "DOWN" || state == "ADMIN_DOWN" {
+		return "critical"
+	}
+	return "normal"
+}
+
+// GetBFDSessions returns the BFD session state of IS-IS adjacencies, as synced
+// onto ISIS_ADJACENT edges by the indexer. Critical sessions are listed first.
+func GetBFDSessions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	response := BFDSessionsResponse{
+		Sessions: []BFDSession{},
+	}
+
+	cypher := `
+		MATCH (a:Device)-[r:ISIS_ADJACENT]->(b:Device)
+		WHERE r.bfd_state IS NOT NULL
+		RETURN a.pk AS sourcePK,
+		       a.code AS sourceCode,
+		       b.pk AS targetPK,
+		       b.code AS targetCode,
+		       r.bfd_state AS bfdState,
+		       r.bfd_min_tx_us AS minTxUs,
+		       r.bfd_min_rx_us AS minRxUs,
+		       r.bfd_multiplier AS multiplier
+		ORDER BY CASE WHEN r.bfd_state IN ['DOWN', 'ADMIN_DOWN'] THEN 0 ELSE 1 END,
+		         sourceCode, targetCode
+	`
+
+	result, err := session.Run(ctx, cypher, nil)
+	if err != nil {
+		LoggerFromContext(ctx).Error("BFD sessions query error", "error", err)
+		metrics.RecordNeo4jQuery("bfd_sessions", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+
+	records, err := result.Collect(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("BFD sessions collect error", "error", err)
+		metrics.RecordNeo4jQuery("bfd_sessions", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	metrics.RecordNeo4jQuery("bfd_sessions", time.Since(start), nil)
+
+	for _, record := range records {
+		sourcePK, _ := record.Get("sourcePK")
+		sourceCode, _ := record.Get("sourceCode")
+		targetPK, _ := record.Get("targetPK")
+		targetCode, _ := record.Get("targetCode")
+		bfdState, _ := record.Get("bfdState")
+		minTxUs, _ := record.Get("minTxUs")
+		minRxUs, _ := record.Get("minRxUs")
+		multiplier, _ := record.Get("multiplier")
+
+		state := asString(bfdState)
+		response.Sessions = append(response.Sessions, BFDSession{
+			SourcePK:   asString(sourcePK),
+			SourceCode: asString(sourceCode),
+			TargetPK:   asString(targetPK),
+			TargetCode: asString(targetCode),
+			BFDState:   state,
+			MinTxUs:    asInt64(minTxUs),
+			MinRxUs:    asInt64(minRxUs),
+			Multiplier: asInt64(multiplier),
+			Severity:   bfdSessionSeverity(state),
+		})
+	}
+
+	writeJSON(w, response)
+}
@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBFDSessions(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (a:Device {pk: 'dev-a', code: 'AMS-1', isis_system_id: '0000.0000.0001'})
+			CREATE (b:Device {pk: 'dev-b', code: 'FRA-1', isis_system_id: '0000.0000.0002'})
+			CREATE (c:Device {pk: 'dev-c', code: 'LON-1', isis_system_id: '0000.0000.0003'})
+			CREATE (a)-[:ISIS_ADJACENT {metric: 10, bfd_state: 'UP', bfd_min_tx_us: 300000, bfd_min_rx_us: 300000, bfd_multiplier: 3}]->(b)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 10, bfd_state: 'DOWN', bfd_min_tx_us: 300000, bfd_min_rx_us: 250000, bfd_multiplier: 5}]->(a)
+			CREATE (a)-[:ISIS_ADJACENT {metric: 20}]->(c)
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+
+	rr := httptest.NewRecorder()
+	handlers.GetBFDSessions(rr, httptest.NewRequest(http.MethodGet, "/api/topology/bfd-sessions", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp handlers.BFDSessionsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Error)
+	require.Len(t, resp.Sessions, 2, "adjacencies without BFD are excluded")
+
+	// Down sessions sort first
+	down := resp.Sessions[0]
+	assert.Equal(t, "FRA-1", down.SourceCode)
+	assert.Equal(t, "AMS-1", down.TargetCode)
+	assert.Equal(t, "DOWN", down.BFDState)
+	assert.Equal(t, int64(300000), down.MinTxUs)
+	assert.Equal(t, int64(250000), down.MinRxUs)
+	assert.Equal(t, int64(5), down.Multiplier)
+	assert.Equal(t, "critical", down.Severity)
+
+	assert.Equal(t, "UP", resp.Sessions[1].BFDState)
+	assert.Equal(t, "normal", resp.Sessions[1].Severity)
+}
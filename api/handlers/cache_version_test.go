@@ -0,0 +1,34 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCache_HasChanged(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (:CacheVersion {type: 'isis_topology', hash: 'abc123', updatedAt: datetime()})
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+
+	cache := handlers.NewStatusCache(time.Minute, time.Minute, time.Minute, time.Minute, time.Minute)
+	defer cache.Stop()
+
+	assert.True(t, cache.HasChanged("isis_topology", ""), "no known version")
+	assert.Equal(t, "abc123", cache.CacheVersion("isis_topology"))
+	assert.True(t, cache.HasChanged("isis_topology", "stale"))
+	assert.False(t, cache.HasChanged("isis_topology", "abc123"))
+
+	// Types the indexer hasn't written always count as changed
+	assert.True(t, cache.HasChanged("unknown", ""))
+	assert.Empty(t, cache.CacheVersion("unknown"))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/malbeclabs/lake/api/metrics"
@@ -21,10 +22,79 @@ type CatalogResponse struct {
 	Tables []TableInfo `json:"tables"`
 }
 
+// catalogLastModifiedTTL is how long the catalog's last-modified time is reused
+// before the preflight query runs again.
+const catalogLastModifiedTTL = 10 * time.Second
+
+// catalogCacheControl lets browsers and CDNs reuse the catalog briefly.
+const catalogCacheControl = "public, max-age=30"
+
+type catalogLastModifiedEntry struct {
+	lastModified time.Time
+	fetchedAt    time.Time
+}
+
+var (
+	catalogLastModifiedCache   = make(map[string]catalogLastModifiedEntry)
+	catalogLastModifiedCacheMu sync.RWMutex
+)
+
+// catalogLastModified returns when the tables in database were last created or
+// altered, truncated to the second as HTTP dates are. It's cached per database
+// for catalogLastModifiedTTL.
+func catalogLastModified(ctx context.Context, database string) (time.Time, error) {
+	catalogLastModifiedCacheMu.RLock()
+	entry, ok := catalogLastModifiedCache[database]
+	catalogLastModifiedCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < catalogLastModifiedTTL {
+		return entry.lastModified, nil
+	}
+
+	start := time.Now()
+	var lastModified time.Time
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT max(metadata_modification_time)
+		FROM system.tables
+		WHERE database = $1
+	`, database).Scan(&lastModified)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if lastModified.Unix() <= 0 {
+		lastModified = time.Time{} // max() over no tables
+	} else {
+		lastModified = lastModified.UTC().Truncate(time.Second)
+	}
+
+	catalogLastModifiedCacheMu.Lock()
+	catalogLastModifiedCache[database] = catalogLastModifiedEntry{lastModified: lastModified, fetchedAt: time.Now()}
+	catalogLastModifiedCacheMu.Unlock()
+	return lastModified, nil
+}
+
+// GetCatalog lists the tables and views in the environment's database with
+// their columns. The catalog only changes when the schema does, so responses
+// carry Last-Modified (the latest table metadata change) and Cache-Control, and
+// a request whose If-Modified-Since is at or after Last-Modified gets a 304.
 func GetCatalog(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
+	// Conditional caching is best-effort: if the preflight fails, serve the
+	// catalog without validators.
+	lastModified, err := catalogLastModified(ctx, DatabaseForEnvFromContext(ctx))
+	if err != nil {
+		LoggerFromContext(ctx).Warn("Catalog last-modified query error", "error", err)
+	} else if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		w.Header().Set("Cache-Control", catalogCacheControl)
+		if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !ims.Before(lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
 	start := time.Now()
 
 	rows, err := envDB(ctx).Query(ctx, `
@@ -46,7 +116,7 @@ func GetCatalog(w http.ResponseWriter, r *http.Request) {
 	duration := time.Since(start)
 	if err != nil {
 		metrics.RecordClickHouseQuery(duration, err)
-		http.Error(w, internalError("Failed to query database", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to query database", err))
 		return
 	}
 	defer rows.Close()
@@ -56,7 +126,7 @@ func GetCatalog(w http.ResponseWriter, r *http.Request) {
 		var t TableInfo
 		if err := rows.Scan(&t.Name, &t.Database, &t.Engine, &t.Type); err != nil {
 			metrics.RecordClickHouseQuery(duration, err)
-			http.Error(w, internalError("Failed to scan row", err), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to scan row", err))
 			return
 		}
 		tables = append(tables, t)
@@ -64,7 +134,7 @@ func GetCatalog(w http.ResponseWriter, r *http.Request) {
 
 	if err := rows.Err(); err != nil {
 		metrics.RecordClickHouseQuery(duration, err)
-		http.Error(w, internalError("Failed to read rows", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to read rows", err))
 		return
 	}
 
@@ -95,14 +165,14 @@ func GetCatalog(w http.ResponseWriter, r *http.Request) {
 		var tableName, colName string
 		if err := colRows.Scan(&tableName, &colName); err != nil {
 			metrics.RecordClickHouseQuery(colDuration, err)
-			http.Error(w, internalError("Failed to scan column row", err), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to scan column row", err))
 			return
 		}
 		tableColumns[tableName] = append(tableColumns[tableName], colName)
 	}
 	if err := colRows.Err(); err != nil {
 		metrics.RecordClickHouseQuery(colDuration, err)
-		http.Error(w, internalError("Failed to iterate column rows", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to iterate column rows", err))
 		return
 	}
 	metrics.RecordClickHouseQuery(colDuration, nil)
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/malbeclabs/lake/api/config"
 	"github.com/malbeclabs/lake/api/handlers"
@@ -234,3 +235,52 @@ func TestTableInfo_Structure(t *testing.T) {
 	assert.Equal(t, info.Type, decoded.Type)
 	assert.Equal(t, info.Columns, decoded.Columns)
 }
+
+func TestGetCatalog_ConditionalRequest(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS catalog_cached_table (
+			id UInt64
+		) ENGINE = Memory
+	`))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/catalog", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetCatalog(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "public, max-age=30", rr.Header().Get("Cache-Control"))
+	lastModifiedHeader := rr.Header().Get("Last-Modified")
+	require.NotEmpty(t, lastModifiedHeader)
+	lastModified, err := http.ParseTime(lastModifiedHeader)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), lastModified, time.Hour)
+
+	// Unchanged since the client's copy: 304 with no body
+	req = httptest.NewRequest(http.MethodGet, "/api/catalog", nil)
+	req.Header.Set("If-Modified-Since", lastModifiedHeader)
+	rr = httptest.NewRecorder()
+	handlers.GetCatalog(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	// The client's copy predates the schema: full response
+	req = httptest.NewRequest(http.MethodGet, "/api/catalog", nil)
+	req.Header.Set("If-Modified-Since", lastModified.Add(-time.Second).Format(http.TimeFormat))
+	rr = httptest.NewRecorder()
+	handlers.GetCatalog(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.CatalogResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.NotEmpty(t, response.Tables)
+
+	// An unparseable If-Modified-Since is ignored
+	req = httptest.NewRequest(http.MethodGet, "/api/catalog", nil)
+	req.Header.Set("If-Modified-Since", "yesterday")
+	rr = httptest.NewRecorder()
+	handlers.GetCatalog(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
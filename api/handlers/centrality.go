@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"container/heap"
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// CentralityDevice is a device ranked by betweenness centrality
+type CentralityDevice struct {
+	PK        string  `json:"pk"`
+	Code      string  `json:"code"`
+	MetroPK   string  `json:"metroPK,omitempty"`
+	Status    string  `json:"status"`
+	Score     float64 `json:"score"`     // normalized betweenness centrality (0-1)
+	CutVertex bool    `json:"cutVertex"` // removing the device splits the topology
+}
+
+// BetweennessCentralityResponse is the response for the betweenness centrality endpoint
+type BetweennessCentralityResponse struct {
+	Devices []CentralityDevice `json:"devices"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// betweennessCacheTTL is how long centrality scores are reused; the
+// computation runs a shortest-path search from every device.
+const betweennessCacheTTL = 5 * time.Minute
+
+// highCentralityThreshold is the minimum score for a device to be reported
+// as a hub in the redundancy report.
+const highCentralityThreshold = 0.25
+
+var (
+	betweennessCache          []CentralityDevice
+	betweennessCacheFetchedAt time.Time
+	betweennessCacheMu        sync.RWMutex
+)
+
+// GetBetweennessCentrality returns ISIS devices ranked by betweenness centrality
+func GetBetweennessCentrality(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	devices, cached, err := getBetweennessCentrality(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Betweenness centrality error", "error", err)
+		writeJSON(w, BetweennessCentralityResponse{Devices: []CentralityDevice{}, Error: err.Error()})
+		return
+	}
+
+	if cached {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	writeJSON(w, BetweennessCentralityResponse{Devices: devices})
+}
+
+// getBetweennessCentrality returns cached centrality scores, recomputing them
+// when the cache is older than betweennessCacheTTL.
+func getBetweennessCentrality(ctx context.Context) ([]CentralityDevice, bool, error) {
+	betweennessCacheMu.RLock()
+	devices, fetchedAt := betweennessCache, betweennessCacheFetchedAt
+	betweennessCacheMu.RUnlock()
+	if devices != nil && time.Since(fetchedAt) < betweennessCacheTTL {
+		return devices, true, nil
+	}
+
+	devices, err := computeBetweennessCentrality(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	betweennessCacheMu.Lock()
+	betweennessCache = devices
+	betweennessCacheFetchedAt = time.Now()
+	betweennessCacheMu.Unlock()
+
+	return devices, false, nil
+}
+
+// computeBetweennessCentrality loads the ISIS graph from Neo4j and scores every
+// device using Brandes' algorithm over metric-weighted shortest paths.
+// The GDS plugin isn't available in every deployment, so the scoring is done here.
+func computeBetweennessCentrality(ctx context.Context) ([]CentralityDevice, error) {
+	start := time.Now()
+
+	g, err := loadISISGraph(ctx)
+	if err != nil {
+		metrics.RecordNeo4jQuery("betweenness_centrality", time.Since(start), err)
+		return nil, err
+	}
+
+	devices := make([]CentralityDevice, len(g.nodes))
+	for i, node := range g.nodes {
+		devices[i] = CentralityDevice{
+			PK:      node.PK,
+			Code:    node.Code,
+			MetroPK: node.MetroPK,
+			Status:  node.Status,
+		}
+	}
+
+	scores := brandesBetweenness(g.adj)
+	cutVertices := articulationPoints(g.adj)
+	for i := range devices {
+		devices[i].Score = scores[i]
+		devices[i].CutVertex = cutVertices[i]
+	}
+
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].Score > devices[j].Score
+	})
+
+	duration := time.Since(start)
+	metrics.RecordNeo4jQuery("betweenness_centrality", duration, nil)
+	LoggerFromContext(ctx).Info("Betweenness centrality computed", "devices", len(devices), "duration_ms", duration.Milliseconds())
+
+	return devices, nil
+}
+
+// brandesBetweenness returns the normalized betweenness centrality of each
+// node in an undirected weighted graph. Scores are in the range 0-1.
+func brandesBetweenness(adj [][]isisGraphEdge) []float64 {
+	n := len(adj)
+	scores := make([]float64, n)
+	if n < 3 {
+		return scores
+	}
+
+	dist := make([]int64, n)
+	sigma := make([]float64, n)
+	delta := make([]float64, n)
+	preds := make([][]int, n)
+	order := make([]int, 0, n)
+
+	for s := range n {
+		for i := range n {
+			dist[i] = -1
+			sigma[i] = 0
+			delta[i] = 0
+			preds[i] = preds[i][:0]
+		}
+		order = order[:0]
+
+		dist[s] = 0
+		sigma[s] = 1
+		pq := &centralityQueue{{node: s}}
+		for pq.Len() > 0 {
+			item := heap.Pop(pq).(centralityItem)
+			v := item.node
+			if item.dist > dist[v] {
+				continue // stale entry
+			}
+			order = append(order, v)
+			for _, e := range adj[v] {
+				alt := dist[v] + e.weight
+				switch {
+				case dist[e.to] < 0 || alt < dist[e.to]:
+					dist[e.to] = alt
+					sigma[e.to] = sigma[v]
+					preds[e.to] = append(preds[e.to][:0], v)
+					heap.Push(pq, centralityItem{node: e.to, dist: alt})
+				case alt == dist[e.to]:
+					sigma[e.to] += sigma[v]
+					preds[e.to] = append(preds[e.to], v)
+				}
+			}
+		}
+
+		// Accumulate dependencies in order of non-increasing distance
+		for i := len(order) - 1; i >= 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				scores[w] += delta[w]
+			}
+		}
+	}
+
+	// Every pair is counted in both directions, matching (n-1)(n-2) ordered pairs
+	norm := float64((n - 1) * (n - 2))
+	for i := range scores {
+		scores[i] /= norm
+	}
+	return scores
+}
+
+// articulationPoints reports which nodes disconnect the graph when removed
+func articulationPoints(adj [][]isisGraphEdge) []bool {
+	n := len(adj)
+	cut := make([]bool, n)
+	disc := make([]int, n)
+	low := make([]int, n)
+	for i := range disc {
+		disc[i] = -1
+	}
+
+	timer := 0
+	var visit func(v, parent int)
+	visit = func(v, parent int) {
+		disc[v] = timer
+		low[v] = timer
+		timer++
+		children := 0
+		for _, e := range adj[v] {
+			u := e.to
+			if u == parent {
+				continue
+			}
+			if disc[u] >= 0 {
+				low[v] = min(low[v], disc[u])
+				continue
+			}
+			children++
+			visit(u, v)
+			low[v] = min(low[v], low[u])
+			if parent >= 0 && low[u] >= disc[v] {
+				cut[v] = true
+			}
+		}
+		if parent < 0 && children > 1 {
+			cut[v] = true
+		}
+	}
+
+	for v := range n {
+		if disc[v] < 0 {
+			visit(v, -1)
+		}
+	}
+	return cut
+}
+
+type centralityItem struct {
+	node int
+	dist int64
+}
+
+// centralityQueue is a min-heap of nodes ordered by tentative distance
+type centralityQueue []centralityItem
+
+func (q centralityQueue) Len() int           { return len(q) }
+func (q centralityQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q centralityQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *centralityQueue) Push(x any)        { *q = append(*q, x.(centralityItem)) }
+func (q *centralityQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
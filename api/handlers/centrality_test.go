@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+// undirectedGraph builds an adjacency list from unit-weight edges
+func undirectedGraph(n int, edges [][2]int) [][]isisGraphEdge {
+	adj := make([][]isisGraphEdge, n)
+	for _, e := range edges {
+		adj[e[0]] = append(adj[e[0]], isisGraphEdge{to: e[1], weight: 1})
+		adj[e[1]] = append(adj[e[1]], isisGraphEdge{to: e[0], weight: 1})
+	}
+	return adj
+}
+
+func TestBrandesBetweenness_Line(t *testing.T) {
+	// 0 - 1 - 2 - 3
+	scores := brandesBetweenness(undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}}))
+
+	want := []float64{0, 2.0 / 3, 2.0 / 3, 0}
+	for i := range want {
+		if math.Abs(scores[i]-want[i]) > 1e-9 {
+			t.Errorf("node %d: expected %v, got %v", i, want[i], scores[i])
+		}
+	}
+}
+
+func TestBrandesBetweenness_Star(t *testing.T) {
+	// Every path between leaves goes through the center
+	scores := brandesBetweenness(undirectedGraph(5, [][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}}))
+
+	if math.Abs(scores[0]-1) > 1e-9 {
+		t.Errorf("expected center score 1, got %v", scores[0])
+	}
+	for i := 1; i < 5; i++ {
+		if scores[i] != 0 {
+			t.Errorf("expected leaf %d score 0, got %v", i, scores[i])
+		}
+	}
+}
+
+func TestBrandesBetweenness_SplitsEqualCostPaths(t *testing.T) {
+	// Square: 0-1-2 and 0-3-2 are equal cost, so 1 and 3 share the 0<->2 paths
+	scores := brandesBetweenness(undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}))
+
+	for i, score := range scores {
+		if math.Abs(score-1.0/6) > 1e-9 {
+			t.Errorf("node %d: expected 1/6, got %v", i, score)
+		}
+	}
+}
+
+func TestBrandesBetweenness_UsesMetric(t *testing.T) {
+	// 0 -> 2 directly costs 100, via 1 costs 20
+	adj := make([][]isisGraphEdge, 3)
+	link := func(a, b int, w int64) {
+		adj[a] = append(adj[a], isisGraphEdge{to: b, weight: w})
+		adj[b] = append(adj[b], isisGraphEdge{to: a, weight: w})
+	}
+	link(0, 1, 10)
+	link(1, 2, 10)
+	link(0, 2, 100)
+
+	scores := brandesBetweenness(adj)
+	if math.Abs(scores[1]-1) > 1e-9 {
+		t.Errorf("expected node 1 score 1, got %v", scores[1])
+	}
+}
+
+func TestArticulationPoints(t *testing.T) {
+	// Triangle 0-1-2 with a tail 2-3
+	cut := articulationPoints(undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 3}}))
+
+	want := []bool{false, false, true, false}
+	for i := range want {
+		if cut[i] != want[i] {
+			t.Errorf("node %d: expected cut=%v, got %v", i, want[i], cut[i])
+		}
+	}
+}
@@ -117,12 +117,12 @@ type ChatResponse struct {
 func Chat(w http.ResponseWriter, r *http.Request) {
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Message) == "" {
-		http.Error(w, "Message is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Message is required")
 		return
 	}
 
@@ -164,8 +164,15 @@ func Chat(w http.ResponseWriter, r *http.Request) {
 		cfg.GraphSchemaFetcher = NewNeo4jSchemaFetcher()
 	}
 
-	// Add env context to agent
-	cfg.EnvContext = BuildEnvContext(env)
+	// Add env context to agent, with answers the caller rated as examples
+	var accountID *uuid.UUID
+	var anonymousID *string
+	if account := GetAccountFromContext(r.Context()); account != nil {
+		accountID = &account.ID
+	} else if req.AnonymousID != "" {
+		anonymousID = &req.AnonymousID
+	}
+	cfg.EnvContext = BuildEnvContext(env) + chatFeedbackContext(r.Context(), accountID, anonymousID)
 
 	// Create and run workflow
 	wf, err := v3.New(cfg)
@@ -259,21 +266,23 @@ func sanitizeValue(v any) any {
 
 // QuotaExceededError represents a quota exceeded error response
 type QuotaExceededError struct {
+	Code      string `json:"code"`
 	Error     string `json:"error"`
 	Remaining int    `json:"remaining"`
 	ResetsAt  string `json:"resets_at"`
+	RequestID string `json:"requestId,omitempty"`
 }
 
 // ChatStream handles streaming chat requests with SSE progress updates.
 func ChatStream(w http.ResponseWriter, r *http.Request) {
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Message) == "" {
-		http.Error(w, "Message is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Message is required")
 		return
 	}
 
@@ -290,9 +299,11 @@ func ChatStream(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusServiceUnavailable)
 			_ = json.NewEncoder(w).Encode(QuotaExceededError{
+				Code:      ErrCodeUnavailable,
 				Error:     "Service temporarily unavailable. Please try again later.",
 				Remaining: 0,
 				ResetsAt:  nextMidnightUTC().Format(time.RFC3339),
+				RequestID: GetRequestIDFromContext(r.Context()),
 			})
 			return
 		}
@@ -300,9 +311,11 @@ func ChatStream(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusTooManyRequests)
 			_ = json.NewEncoder(w).Encode(QuotaExceededError{
+				Code:      ErrCodeQuotaExceeded,
 				Error:     "Service is currently at capacity. Please try again tomorrow.",
 				Remaining: 0,
 				ResetsAt:  nextMidnightUTC().Format(time.RFC3339),
+				RequestID: GetRequestIDFromContext(r.Context()),
 			})
 			return
 		}
@@ -313,9 +326,11 @@ func ChatStream(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusTooManyRequests)
 		_ = json.NewEncoder(w).Encode(QuotaExceededError{
+			Code:      ErrCodeQuotaExceeded,
 			Error:     "Daily question limit exceeded. Please sign in or try again tomorrow.",
 			Remaining: 0,
 			ResetsAt:  nextMidnightUTC().Format(time.RFC3339),
+			RequestID: GetRequestIDFromContext(r.Context()),
 		})
 		return
 	}
@@ -324,7 +339,7 @@ func ChatStream(w http.ResponseWriter, r *http.Request) {
 	if req.SessionID != "" {
 		sessionUUID, err := uuid.Parse(req.SessionID)
 		if err != nil {
-			http.Error(w, "Invalid session_id", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid session_id")
 			return
 		}
 
@@ -337,7 +352,7 @@ func ChatStream(w http.ResponseWriter, r *http.Request) {
 
 		if err != nil && err.Error() != "no rows in result set" {
 			slog.Error("Failed to check session ownership", "session_id", req.SessionID, "error", err)
-			http.Error(w, "Failed to verify session ownership", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to verify session ownership")
 			return
 		}
 
@@ -358,7 +373,7 @@ func ChatStream(w http.ResponseWriter, r *http.Request) {
 			}
 
 			if !owned {
-				http.Error(w, "Session not found", http.StatusNotFound)
+				writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 				return
 			}
 		}
@@ -379,7 +394,7 @@ func ChatStream(w http.ResponseWriter, r *http.Request) {
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Streaming not supported")
 		return
 	}
 
@@ -515,12 +530,12 @@ type CompleteResponse struct {
 func Complete(w http.ResponseWriter, r *http.Request) {
 	var req CompleteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Message) == "" {
-		http.Error(w, "Message is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Message is required")
 		return
 	}
 
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// maxChatExampleLength bounds each answer added to the prompt as an example
+const maxChatExampleLength = 2000
+
+// ChatFeedbackRequest is the request body for POST /api/chat/feedback
+type ChatFeedbackRequest struct {
+	SessionID    string  `json:"sessionId"`
+	MessageIndex int     `json:"messageIndex"` // index of the assistant message in the session
+	Rating       int     `json:"rating"`       // 1 or -1
+	Correction   *string `json:"correction,omitempty"`
+	AnonymousID  string  `json:"anonymousId,omitempty"`
+}
+
+// chatExample is a rated question and answer used as a few-shot example
+type chatExample struct {
+	Question string
+	Answer   string
+}
+
+// loadChatFeedbackExamples returns the caller's most recently rated answers
+// across their chat sessions. Answers rated down are only used when the user
+// supplied a correction, which then replaces the answer.
+func loadChatFeedbackExamples(ctx context.Context, accountID *uuid.UUID, anonymousID *string) ([]chatExample, error) {
+	if accountID == nil && (anonymousID == nil || *anonymousID == "") {
+		return nil, nil
+	}
+	rows, err := config.PgPool.Query(ctx, `
+		SELECT s.content -> (f.message_index - 1) ->> 'content' AS question,
+		       CASE WHEN COALESCE(f.correction, '') != '' THEN f.correction
+		            ELSE s.content -> f.message_index ->> 'content'
+		       END AS answer
+		FROM chat_feedback f
+		JOIN sessions s ON s.id = f.session_id
+		WHERE (s.account_id = $1 OR s.anonymous_id = $2)
+		  AND (f.rating > 0 OR COALESCE(f.correction, '') != '')
+		  AND f.message_index > 0
+		  AND s.content -> (f.message_index - 1) ->> 'role' = 'user'
+		ORDER BY f.updated_at DESC
+		LIMIT $3
+	`, accountID, anonymousID, maxFeedbackExamples)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var examples []chatExample
+	for rows.Next() {
+		var question, answer *string
+		if err := rows.Scan(&question, &answer); err != nil {
+			return nil, err
+		}
+		if question == nil || answer == nil || strings.TrimSpace(*answer) == "" {
+			continue
+		}
+		examples = append(examples, chatExample{Question: *question, Answer: *answer})
+	}
+	return examples, rows.Err()
+}
+
+// chatFeedbackContext loads the caller's rated answers and renders them for
+// the system prompt. Failures are logged and give no examples, so feedback
+// never blocks a chat.
+func chatFeedbackContext(ctx context.Context, accountID *uuid.UUID, anonymousID *string) string {
+	examples, err := loadChatFeedbackExamples(ctx, accountID, anonymousID)
+	if err != nil {
+		slog.Warn("Failed to load chat feedback examples", "error", err)
+		return ""
+	}
+	return formatChatFeedbackExamples(examples)
+}
+
+// sessionChatFeedbackContext is chatFeedbackContext for the owner of a session
+func sessionChatFeedbackContext(ctx context.Context, sessionID uuid.UUID) string {
+	var accountID *uuid.UUID
+	var anonymousID *string
+	err := config.PgPool.QueryRow(ctx, `
+		SELECT account_id, anonymous_id FROM sessions WHERE id = $1
+	`, sessionID).Scan(&accountID, &anonymousID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			slog.Warn("Failed to load session owner for chat feedback", "session_id", sessionID, "error", err)
+		}
+		return ""
+	}
+	return chatFeedbackContext(ctx, accountID, anonymousID)
+}
+
+// formatChatFeedbackExamples renders examples as a system prompt section
+func formatChatFeedbackExamples(examples []chatExample) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n## Examples\n\nThis user rated these answers as helpful. Match their level of detail and format:\n")
+	for _, e := range examples {
+		answer := strings.TrimSpace(e.Answer)
+		if runes := []rune(answer); len(runes) > maxChatExampleLength {
+			answer = string(runes[:maxChatExampleLength]) + "..."
+		}
+		fmt.Fprintf(&b, "\nQuestion: %s\nAnswer:\n%s\n", strings.TrimSpace(e.Question), answer)
+	}
+	return b.String()
+}
+
+// PostChatFeedback handles POST /api/chat/feedback - records a rating of an
+// assistant message in a chat session owned by the caller. Rating the same
+// message again replaces the earlier rating.
+func PostChatFeedback(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var req ChatFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+
+	sessionID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid sessionId")
+		return
+	}
+	if req.MessageIndex < 0 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "messageIndex must not be negative")
+		return
+	}
+	if req.Rating != 1 && req.Rating != -1 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "rating must be 1 or -1")
+		return
+	}
+	if req.Correction != nil && len(*req.Correction) > maxFeedbackTextLength {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("correction must be at most %d characters", maxFeedbackTextLength))
+		return
+	}
+
+	var accountID *uuid.UUID
+	var anonymousID *string
+	if account := GetAccountFromContext(ctx); account != nil {
+		accountID = &account.ID
+	} else if req.AnonymousID != "" {
+		anonymousID = &req.AnonymousID
+	}
+
+	// Selecting from sessions makes a session the caller doesn't own, or an
+	// index that isn't an assistant message, insert nothing
+	var id uuid.UUID
+	err = config.PgPool.QueryRow(ctx, `
+		INSERT INTO chat_feedback (session_id, message_index, rating, correction)
+		SELECT s.id, $2, $3, $4 FROM sessions s
+		WHERE s.id = $1
+		  AND s.type = 'chat'
+		  AND (s.account_id = $5 OR s.anonymous_id = $6)
+		  AND s.content -> $2::int ->> 'role' = 'assistant'
+		ON CONFLICT (session_id, message_index) DO UPDATE SET
+			rating = EXCLUDED.rating,
+			correction = EXCLUDED.correction,
+			updated_at = NOW()
+		RETURNING id
+	`, sessionID, req.MessageIndex, req.Rating, req.Correction, accountID, anonymousID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "Chat message not found")
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to record chat feedback", "session_id", sessionID, "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to record feedback", err))
+		return
+	}
+	metrics.RecordChatFeedback(req.Rating)
+
+	writeJSON(w, map[string]string{"id": id.String()})
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatChatFeedbackExamples(t *testing.T) {
+	assert.Empty(t, formatChatFeedbackExamples(nil))
+
+	got := formatChatFeedbackExamples([]chatExample{
+		{Question: " how many devices? ", Answer: "There are 42 devices.\n"},
+		{Question: "long", Answer: strings.Repeat("é", maxChatExampleLength+1)},
+	})
+	assert.Contains(t, got, "## Examples")
+	assert.Contains(t, got, "Question: how many devices?\nAnswer:\nThere are 42 devices.\n")
+	assert.Contains(t, got, strings.Repeat("é", maxChatExampleLength)+"...")
+	assert.NotContains(t, got, strings.Repeat("é", maxChatExampleLength+1))
+}
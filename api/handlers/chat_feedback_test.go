@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertChatSession(t *testing.T, account *handlers.Account) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	_, err := config.PgPool.Exec(t.Context(), `
+		INSERT INTO sessions (id, type, content, account_id)
+		VALUES ($1, 'chat', '[{"role":"user","content":"how many devices?"},{"role":"assistant","content":"There are 42 devices."}]', $2)
+	`, id, account.ID)
+	require.NoError(t, err)
+	return id
+}
+
+func postChatFeedback(account *handlers.Account, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/chat/feedback", strings.NewReader(body))
+	if account != nil {
+		req = withAccount(req, account)
+	}
+	rr := httptest.NewRecorder()
+	handlers.PostChatFeedback(rr, req)
+	return rr
+}
+
+func TestPostChatFeedback(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	account := createTestAccount(t, t.Context())
+	sessionID := insertChatSession(t, account)
+
+	rr := postChatFeedback(account, `{"sessionId":"`+sessionID.String()+`","messageIndex":1,"rating":-1,"correction":"There are 40 activated devices."}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var rating int
+	var correction string
+	err := config.PgPool.QueryRow(t.Context(), `
+		SELECT rating, correction FROM chat_feedback WHERE session_id = $1 AND message_index = 1
+	`, sessionID).Scan(&rating, &correction)
+	require.NoError(t, err)
+	assert.Equal(t, -1, rating)
+	assert.Equal(t, "There are 40 activated devices.", correction)
+
+	// Rating the same message again replaces the earlier rating
+	rr = postChatFeedback(account, `{"sessionId":"`+sessionID.String()+`","messageIndex":1,"rating":1}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var count int
+	err = config.PgPool.QueryRow(t.Context(), `
+		SELECT COUNT(*), MAX(rating) FROM chat_feedback WHERE session_id = $1
+	`, sessionID).Scan(&count, &rating)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, rating)
+}
+
+func TestPostChatFeedback_Invalid(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	owner := createTestAccount(t, t.Context())
+	other := createTestAccount(t, t.Context())
+	sessionID := insertChatSession(t, owner).String()
+
+	tests := []struct {
+		name    string
+		account *handlers.Account
+		body    string
+		want    int
+	}{
+		{"bad json", owner, `{`, http.StatusBadRequest},
+		{"bad session id", owner, `{"sessionId":"nope","messageIndex":1,"rating":1}`, http.StatusBadRequest},
+		{"negative index", owner, `{"sessionId":"` + sessionID + `","messageIndex":-1,"rating":1}`, http.StatusBadRequest},
+		{"bad rating", owner, `{"sessionId":"` + sessionID + `","messageIndex":1,"rating":0}`, http.StatusBadRequest},
+		{"user message", owner, `{"sessionId":"` + sessionID + `","messageIndex":0,"rating":1}`, http.StatusNotFound},
+		{"index out of range", owner, `{"sessionId":"` + sessionID + `","messageIndex":5,"rating":1}`, http.StatusNotFound},
+		{"unknown session", owner, `{"sessionId":"` + uuid.New().String() + `","messageIndex":1,"rating":1}`, http.StatusNotFound},
+		{"not the owner", other, `{"sessionId":"` + sessionID + `","messageIndex":1,"rating":1}`, http.StatusNotFound},
+		{"anonymous", nil, `{"sessionId":"` + sessionID + `","messageIndex":1,"rating":1}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, postChatFeedback(tt.account, tt.body).Code)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// compressor is implemented by both gzip.Writer and brotli.Writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var (
+	gzipPool = sync.Pool{New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}}
+	brotliPool = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	}}
+)
+
+// CompressionMiddleware compresses JSON responses with brotli or gzip based on
+// the request's Accept-Encoding header. Other content types, including SSE
+// streams (text/event-stream), are passed through unchanged.
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks brotli or gzip from an Accept-Encoding header,
+// preferring brotli. Encodings with q=0 are refused.
+func negotiateEncoding(header string) string {
+	var br, gz bool
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "br":
+			br = true
+		case "gzip":
+			gz = true
+		}
+	}
+	switch {
+	case br:
+		return "br"
+	case gz:
+		return "gzip"
+	default:
+		return ""
+	}
+}
+
+// compressResponseWriter decides whether to compress when the header is
+// written, so handlers can set Content-Type first.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	wroteHeader bool
+	writer      compressor
+	counter     *countingWriter
+	rawBytes    int64
+}
+
+func (cw *compressResponseWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	if shouldCompress(code, h) {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+
+		cw.counter = &countingWriter{w: cw.ResponseWriter}
+		if cw.encoding == "br" {
+			cw.writer = brotliPool.Get().(*brotli.Writer)
+		} else {
+			cw.writer = gzipPool.Get().(*gzip.Writer)
+		}
+		cw.writer.Reset(cw.counter)
+	}
+
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.writer == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+	cw.rawBytes += int64(len(b))
+	return cw.writer.Write(b)
+}
+
+func (cw *compressResponseWriter) Flush() {
+	if cw.writer != nil {
+		_ = cw.writer.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// close finishes the compressed stream and returns the compressor to its pool.
+func (cw *compressResponseWriter) close() {
+	if cw.writer == nil {
+		return
+	}
+	_ = cw.writer.Close()
+
+	if saved := cw.rawBytes - cw.counter.n; saved > 0 {
+		metrics.HTTPCompressionBytesSavedTotal.WithLabelValues(cw.encoding).Add(float64(saved))
+	}
+
+	cw.writer.Reset(io.Discard)
+	if cw.encoding == "br" {
+		brotliPool.Put(cw.writer)
+	} else {
+		gzipPool.Put(cw.writer)
+	}
+	cw.writer = nil
+}
+
+// shouldCompress reports whether a response with this status and headers is
+// a JSON body that has not already been encoded.
+func shouldCompress(code int, h http.Header) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		return false
+	}
+	return strings.HasPrefix(contentType, "application/json")
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveCompressed(h http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	handlers.CompressionMiddleware(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestCompressionMiddleware_Gzip(t *testing.T) {
+	body := `{"items":[` + strings.Repeat(`{"name":"value"},`, 100) + `{}]}`
+	rec := serveCompressed(jsonHandler(body), "gzip, deflate")
+
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+
+	zr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decoded))
+}
+
+func TestCompressionMiddleware_PrefersBrotli(t *testing.T) {
+	body := `{"ok":true}`
+	rec := serveCompressed(jsonHandler(body), "gzip, br")
+
+	assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	decoded, err := io.ReadAll(brotli.NewReader(rec.Body))
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decoded))
+}
+
+func TestCompressionMiddleware_Passthrough(t *testing.T) {
+	t.Run("no accept-encoding", func(t *testing.T) {
+		rec := serveCompressed(jsonHandler(`{"ok":true}`), "")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"ok":true}`, rec.Body.String())
+	})
+
+	t.Run("refused with q=0", func(t *testing.T) {
+		rec := serveCompressed(jsonHandler(`{"ok":true}`), "gzip;q=0")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("event stream", func(t *testing.T) {
+		rec := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: hello\n\n"))
+			w.(http.Flusher).Flush()
+		}, "gzip")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "data: hello\n\n", rec.Body.String())
+		assert.True(t, rec.Flushed)
+	})
+
+	t.Run("non-json", func(t *testing.T) {
+		rec := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}, "gzip")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "bad request\n", rec.Body.String())
+	})
+}
+
+func TestCompressionMiddleware_TopologyGzipRatio(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_metros_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name, latitude, longitude)
+		SELECT concat('metro-', toString(number)), now(), now(), generateUUIDv4(), 0, number,
+		       concat('metro-', toString(number)), concat('M', toString(number)), concat('Metro ', toString(number)), 40.0, -74.0
+		FROM numbers(20)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		SELECT concat('dev-', toString(number)), now(), now(), generateUUIDv4(), 0, number,
+		       concat('dev-', toString(number)), 'activated', 'hybrid', concat('DEV-', toString(number)), '',
+		       'contrib-1', concat('metro-', toString(number % 20)), 0
+		FROM numbers(200)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		SELECT concat('link-', toString(number)), now(), now(), generateUUIDv4(), 0, number,
+		       concat('link-', toString(number)), 'activated', concat('LINK-', toString(number)), '', 'contrib-1',
+		       concat('dev-', toString(number)), concat('dev-', toString((number + 1) % 200)),
+		       'Ethernet1', 'Ethernet1', 'WAN', 1000000, 0, 10000000000, 0
+		FROM numbers(200)`))
+
+	plain := serveCompressed(handlers.GetTopology, "")
+	require.Equal(t, http.StatusOK, plain.Code, plain.Body.String())
+	require.Empty(t, plain.Header().Get("Content-Encoding"))
+
+	compressed := serveCompressed(handlers.GetTopology, "gzip")
+	require.Equal(t, http.StatusOK, compressed.Code)
+	require.Equal(t, "gzip", compressed.Header().Get("Content-Encoding"))
+
+	plainSize := plain.Body.Len()
+	compressedSize := compressed.Body.Len()
+	t.Logf("topology response: %d bytes plain, %d bytes gzip", plainSize, compressedSize)
+	assert.LessOrEqual(t, float64(compressedSize), float64(plainSize)*0.4, "gzip should save at least 60%%")
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+	"golang.org/x/sync/errgroup"
+)
+
+// contributorSummaryCacheTTL is how long a contributor network summary is
+// reused; it aggregates a day of latency samples, so it's expensive to build.
+const contributorSummaryCacheTTL = 5 * time.Minute
+
+// ContributorMetroCoverage is a metro where a contributor operates devices
+type ContributorMetroCoverage struct {
+	PK          string `json:"pk"`
+	Code        string `json:"code"`
+	DeviceCount uint64 `json:"deviceCount"`
+}
+
+// ContributorNetworkSummaryResponse is the response for the contributor network summary endpoint
+type ContributorNetworkSummaryResponse struct {
+	ContributorPK      string                     `json:"contributorPK"`
+	ContributorCode    string                     `json:"contributorCode"`
+	DeviceCount        uint64                     `json:"deviceCount"`
+	LinkCount          uint64                     `json:"linkCount"`
+	ActiveLinkCount    uint64                     `json:"activeLinkCount"`
+	TotalBandwidthBps  int64                      `json:"totalBandwidthBps"`
+	MetrosCovered      []ContributorMetroCoverage `json:"metrosCovered"`
+	AvgLinkRttNs       float64                    `json:"avgLinkRttNs"`
+	P95LossPct         float64                    `json:"p95LossPct"`
+	ISISAdjacencyCount int64                      `json:"isisAdjacencyCount"`
+	ISISNeighborCount  int64                      `json:"isisNeighborCount"`
+}
+
+type contributorSummaryCacheEntry struct {
+	summary   ContributorNetworkSummaryResponse
+	fetchedAt time.Time
+}
+
+var (
+	contributorSummaryCache   = make(map[string]contributorSummaryCacheEntry)
+	contributorSummaryCacheMu sync.RWMutex
+)
+
+// GetContributorNetworkSummary aggregates everything a contributor has deployed:
+// devices, links, bandwidth, metro coverage, 24h link latency/loss and ISIS
+// adjacencies. Summaries are cached per environment for 5 minutes.
+func GetContributorNetworkSummary(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing contributor pk")
+		return
+	}
+
+	cacheKey := string(EnvFromContext(ctx)) + ":" + pk
+	contributorSummaryCacheMu.RLock()
+	entry, ok := contributorSummaryCache[cacheKey]
+	contributorSummaryCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < contributorSummaryCacheTTL {
+		w.Header().Set("X-Cache", "HIT")
+		writeJSON(w, entry.summary)
+		return
+	}
+
+	start := time.Now()
+	response := ContributorNetworkSummaryResponse{ContributorPK: pk}
+
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT code FROM dz_contributors_current WHERE pk = ?
+	`, pk).Scan(&response.ContributorCode)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeContributorNotFound, "contributor not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Contributor network summary contributor query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return envDB(gCtx).QueryRow(gCtx, `
+			SELECT count(*), countIf(status = 'activated'), sum(bandwidth_bps)
+			FROM dz_links_current
+			WHERE contributor_pk = ?
+		`, pk).Scan(&response.LinkCount, &response.ActiveLinkCount, &response.TotalBandwidthBps)
+	})
+
+	g.Go(func() error {
+		if err := envDB(gCtx).QueryRow(gCtx, `
+			SELECT count(*) FROM dz_devices_current WHERE contributor_pk = ?
+		`, pk).Scan(&response.DeviceCount); err != nil {
+			return err
+		}
+
+		rows, err := envDB(gCtx).Query(gCtx, `
+			SELECT m.pk, m.code, count(*) AS device_count
+			FROM dz_devices_current d
+			JOIN dz_metros_current m ON d.metro_pk = m.pk
+			WHERE d.contributor_pk = ?
+			GROUP BY m.pk, m.code
+			ORDER BY m.code
+		`, pk)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		metros := []ContributorMetroCoverage{}
+		for rows.Next() {
+			var m ContributorMetroCoverage
+			if err := rows.Scan(&m.PK, &m.Code, &m.DeviceCount); err != nil {
+				return err
+			}
+			metros = append(metros, m)
+		}
+		response.MetrosCovered = metros
+		return rows.Err()
+	})
+
+	// Average RTT is the mean of per-link averages so busy links don't dominate,
+	// and p95 loss is taken across links' 24h loss percentages.
+	g.Go(func() error {
+		var avgRtt, p95Loss *float64
+		err := envDB(gCtx).QueryRow(gCtx, `
+			WITH per_link AS (
+				SELECT
+					link_pk,
+					avgIf(rtt_us, NOT loss) AS avg_rtt_us,
+					countIf(loss) * 100.0 / count(*) AS loss_pct
+				FROM fact_dz_device_link_latency
+				WHERE link_pk IN (SELECT pk FROM dz_links_current WHERE contributor_pk = ?)
+				  AND event_ts > now() - INTERVAL 24 HOUR
+				GROUP BY link_pk
+			)
+			SELECT
+				avgIf(avg_rtt_us, NOT isNaN(avg_rtt_us)) * 1000 AS avg_rtt_ns,
+				quantile(0.95)(loss_pct) AS p95_loss_pct
+			FROM per_link
+		`, pk).Scan(&avgRtt, &p95Loss)
+		response.AvgLinkRttNs = finiteOrZero(avgRtt)
+		response.P95LossPct = finiteOrZero(p95Loss)
+		return err
+	})
+
+	// ISIS adjacencies only exist in the mainnet graph. A Neo4j failure leaves
+	// the counts at zero rather than failing the whole summary.
+	if config.Neo4jClient != nil && EnvFromContext(ctx) == EnvMainnet {
+		g.Go(func() error {
+			neo4jStart := time.Now()
+			session := config.Neo4jSession(gCtx)
+			defer session.Close(gCtx)
+
+			result, err := session.Run(gCtx, `
+				MATCH (d:Device)-[:OPERATES]->(:Contributor {pk: $pk})
+				MATCH (d)-[r:ISIS_ADJACENT]-(n:Device)
+				RETURN count(r) AS adjacencies, count(DISTINCT n) AS neighbors
+			`, map[string]any{"pk": pk})
+			if err != nil {
+				metrics.RecordNeo4jQuery("contributor_network_summary", time.Since(neo4jStart), err)
+				LoggerFromContext(ctx).Error("Contributor network summary ISIS query error", "error", err)
+				return nil // adjacency counts are best-effort
+			}
+			record, err := result.Single(gCtx)
+			metrics.RecordNeo4jQuery("contributor_network_summary", time.Since(neo4jStart), err)
+			if err != nil {
+				LoggerFromContext(ctx).Error("Contributor network summary ISIS query error", "error", err)
+				return nil
+			}
+			adjacencies, _ := record.Get("adjacencies")
+			neighbors, _ := record.Get("neighbors")
+			response.ISISAdjacencyCount = asInt64(adjacencies)
+			response.ISISNeighborCount = asInt64(neighbors)
+			return nil
+		})
+	}
+
+	err = g.Wait()
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Contributor network summary query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	contributorSummaryCacheMu.Lock()
+	for key, e := range contributorSummaryCache {
+		if time.Since(e.fetchedAt) >= contributorSummaryCacheTTL {
+			delete(contributorSummaryCache, key)
+		}
+	}
+	contributorSummaryCache[cacheKey] = contributorSummaryCacheEntry{summary: response, fetchedAt: time.Now()}
+	contributorSummaryCacheMu.Unlock()
+
+	LoggerFromContext(ctx).Info("Contributor network summary completed", "pk", pk, "devices", response.DeviceCount, "links", response.LinkCount, "duration_ms", duration.Milliseconds())
+
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, response)
+}
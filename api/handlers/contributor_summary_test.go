@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedContributorSummary inserts contributor csum with three devices across two
+// metros and two links: an activated 10G link with 10% loss and a soft-drained
+// 1G link with no loss.
+func seedContributorSummary(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_contributors_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name)
+		VALUES
+		('csum', now(), now(), generateUUIDv4(), 0, 1, 'csum', 'CSUM', 'Summary Co'),
+		('other', now(), now(), generateUUIDv4(), 0, 2, 'other', 'OTHER', 'Other Co')`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_metros_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name, longitude, latitude)
+		VALUES
+		('metro-ams', now(), now(), generateUUIDv4(), 0, 1, 'metro-ams', 'AMS', 'Amsterdam', 4.9, 52.4),
+		('metro-fra', now(), now(), generateUUIDv4(), 0, 2, 'metro-fra', 'FRA', 'Frankfurt', 8.7, 50.1)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('csum-1', now(), now(), generateUUIDv4(), 0, 1, 'csum-1', 'activated', 'hybrid', 'AMS-1', '', 'csum', 'metro-ams', 0),
+		('csum-2', now(), now(), generateUUIDv4(), 0, 2, 'csum-2', 'activated', 'hybrid', 'AMS-2', '', 'csum', 'metro-ams', 0),
+		('csum-3', now(), now(), generateUUIDv4(), 0, 3, 'csum-3', 'activated', 'hybrid', 'FRA-1', '', 'csum', 'metro-fra', 0),
+		('other-1', now(), now(), generateUUIDv4(), 0, 4, 'other-1', 'activated', 'hybrid', 'FRA-2', '', 'other', 'metro-fra', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('csum-l1', now(), now(), generateUUIDv4(), 0, 1, 'csum-l1', 'activated', 'AMS-FRA-1', '', 'csum', 'csum-1', 'csum-3', '', '', 'WAN', 0, 0, 10000000000, 0),
+		('csum-l2', now(), now(), generateUUIDv4(), 0, 2, 'csum-l2', 'soft-drained', 'AMS-AMS-1', '', 'csum', 'csum-1', 'csum-2', '', '', 'WAN', 0, 0, 1000000000, 0),
+		('other-l1', now(), now(), generateUUIDv4(), 0, 3, 'other-l1', 'activated', 'FRA-FRA-1', '', 'other', 'csum-3', 'other-1', '', '', 'WAN', 0, 0, 100000000000, 0)`))
+
+	// csum-l1: 10 samples at 4ms with one lost; csum-l2: 10 samples at 2ms
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT now() - INTERVAL 1 HOUR + INTERVAL number SECOND, now(), 1, number, 'csum-1',
+		       if(number < 10, 'csum-3', 'csum-2'), if(number < 10, 'csum-l1', 'csum-l2'),
+		       if(number = 9, 0, if(number < 10, 4000, 2000)), number = 9, 0
+		FROM numbers(20)`))
+}
+
+func getContributorNetworkSummary(pk string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/contributors/"+pk+"/network-summary", nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetContributorNetworkSummary(rr, req)
+	return rr
+}
+
+func TestGetContributorNetworkSummary(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedContributorSummary(t)
+
+	rr := getContributorNetworkSummary("csum")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+
+	var resp handlers.ContributorNetworkSummaryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "CSUM", resp.ContributorCode)
+	assert.Equal(t, uint64(3), resp.DeviceCount)
+	assert.Equal(t, uint64(2), resp.LinkCount)
+	assert.Equal(t, uint64(1), resp.ActiveLinkCount)
+	assert.Equal(t, int64(11000000000), resp.TotalBandwidthBps)
+	require.Len(t, resp.MetrosCovered, 2)
+	assert.Equal(t, "AMS", resp.MetrosCovered[0].Code)
+	assert.Equal(t, uint64(2), resp.MetrosCovered[0].DeviceCount)
+	assert.Equal(t, "FRA", resp.MetrosCovered[1].Code)
+	assert.Equal(t, uint64(1), resp.MetrosCovered[1].DeviceCount)
+	// Mean of per-link averages: (4ms + 2ms) / 2
+	assert.InDelta(t, 3000000.0, resp.AvgLinkRttNs, 1.0)
+	// Loss is 10% on one link and 0% on the other
+	assert.Greater(t, resp.P95LossPct, 0.0)
+	assert.LessOrEqual(t, resp.P95LossPct, 10.0)
+
+	rr = getContributorNetworkSummary("csum")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "HIT", rr.Header().Get("X-Cache"))
+}
+
+func TestGetContributorNetworkSummary_NotFound(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedContributorSummary(t)
+
+	rr := getContributorNetworkSummary("missing")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	var resp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, handlers.ErrCodeContributorNotFound, resp.Code)
+}
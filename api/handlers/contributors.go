@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -21,73 +23,208 @@ type ContributorListItem struct {
 	LinkCount    uint64 `json:"link_count"`
 }
 
-func GetContributors(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
-	defer cancel()
+// ContributorListSummary totals devices and link bandwidth across all
+// contributors, regardless of filters
+type ContributorListSummary struct {
+	TotalContributors uint64 `json:"total_contributors"`
+	TotalDevices      uint64 `json:"total_devices"`
+	TotalBandwidthBps int64  `json:"total_bandwidth_bps"`
+}
 
-	pagination := ParsePagination(r, 100)
-	start := time.Now()
+// ContributorListResponse is a page of contributors with a summary
+type ContributorListResponse struct {
+	PaginatedResponse[ContributorListItem]
+	Summary ContributorListSummary `json:"summary"`
+}
 
-	// Get total count
-	countQuery := `SELECT count(*) FROM dz_contributors_current`
-	var total uint64
-	if err := envDB(ctx).QueryRow(ctx, countQuery).Scan(&total); err != nil {
-		log.Printf("Contributors count error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+// Columns of the contributor stats query that sort_by accepts
+var contributorSortColumns = map[string]string{
+	"device_count": "device_count",
+	"bandwidth":    "bandwidth_bps",
+	"link_count":   "link_count",
+}
+
+// contributorListFilter holds the optional GetContributors filters and sort
+type contributorListFilter struct {
+	SortColumn     string
+	SortDesc       bool
+	MinDevices     uint64
+	HasActiveLinks bool
+}
+
+// parseContributorListFilter parses the sort_by, sort_dir, min_devices and
+// has_active_links query parameters. Sorting by a count defaults to descending.
+func parseContributorListFilter(r *http.Request) (contributorListFilter, error) {
+	q := r.URL.Query()
+	var f contributorListFilter
+	if s := q.Get("sort_by"); s != "" {
+		col, ok := contributorSortColumns[s]
+		if !ok {
+			return f, fmt.Errorf("invalid sort_by %q: must be one of device_count, bandwidth, link_count", s)
+		}
+		f.SortColumn = col
+		f.SortDesc = true
+	}
+	switch q.Get("sort_dir") {
+	case "":
+	case "asc":
+		f.SortDesc = false
+	case "desc":
+		f.SortDesc = true
+	default:
+		return f, fmt.Errorf("sort_dir must be asc or desc")
 	}
+	if s := q.Get("min_devices"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("min_devices must be a non-negative integer")
+		}
+		f.MinDevices = v
+	}
+	switch q.Get("has_active_links") {
+	case "", "false":
+	case "true":
+		f.HasActiveLinks = true
+	default:
+		return f, fmt.Errorf("has_active_links must be true or false")
+	}
+	return f, nil
+}
 
-	query := `
-		WITH device_counts AS (
-			SELECT contributor_pk, count(*) as cnt
-			FROM dz_devices_current
-			WHERE contributor_pk IS NOT NULL
-			GROUP BY contributor_pk
-		),
-		side_a_counts AS (
-			SELECT d.contributor_pk as cpk, count(DISTINCT l.pk) as cnt
-			FROM dz_links_current l
-			JOIN dz_devices_current d ON l.side_a_pk = d.pk
-			WHERE d.contributor_pk IS NOT NULL
-			GROUP BY d.contributor_pk
-		),
-		side_z_counts AS (
-			SELECT d.contributor_pk as cpk, count(DISTINCT l.pk) as cnt
-			FROM dz_links_current l
-			JOIN dz_devices_current d ON l.side_z_pk = d.pk
-			WHERE d.contributor_pk IS NOT NULL
-			GROUP BY d.contributor_pk
-		),
-		link_counts AS (
-			SELECT contributor_pk, count(*) as cnt
-			FROM dz_links_current
-			WHERE contributor_pk IS NOT NULL
-			GROUP BY contributor_pk
-		)
+// condition returns the filter as a boolean expression over the contributor
+// stats columns, and its arguments
+func (f contributorListFilter) condition() (string, []any) {
+	conditions := []string{"1"}
+	var args []any
+	if f.MinDevices > 0 {
+		conditions = append(conditions, "device_count >= ?")
+		args = append(args, f.MinDevices)
+	}
+	if f.HasActiveLinks {
+		conditions = append(conditions, "active_link_count > 0")
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// orderBy returns the ORDER BY expression, with the code as a tie-breaker
+func (f contributorListFilter) orderBy() string {
+	if f.SortColumn == "" {
+		return "code"
+	}
+	dir := "ASC"
+	if f.SortDesc {
+		dir = "DESC"
+	}
+	return f.SortColumn + " " + dir + ", code"
+}
+
+// contributorStatsQuery selects every contributor with its device and link
+// counts, link bandwidth and active link count
+const contributorStatsQuery = `
+	WITH device_counts AS (
+		SELECT contributor_pk, count(*) as cnt
+		FROM dz_devices_current
+		WHERE contributor_pk IS NOT NULL
+		GROUP BY contributor_pk
+	),
+	side_a_counts AS (
+		SELECT d.contributor_pk as cpk, count(DISTINCT l.pk) as cnt
+		FROM dz_links_current l
+		JOIN dz_devices_current d ON l.side_a_pk = d.pk
+		WHERE d.contributor_pk IS NOT NULL
+		GROUP BY d.contributor_pk
+	),
+	side_z_counts AS (
+		SELECT d.contributor_pk as cpk, count(DISTINCT l.pk) as cnt
+		FROM dz_links_current l
+		JOIN dz_devices_current d ON l.side_z_pk = d.pk
+		WHERE d.contributor_pk IS NOT NULL
+		GROUP BY d.contributor_pk
+	),
+	link_counts AS (
 		SELECT
-			c.pk,
-			c.code,
+			contributor_pk,
+			count(*) as cnt,
+			countIf(status = 'activated') as active_cnt,
+			sum(bandwidth_bps) as bandwidth
+		FROM dz_links_current
+		WHERE contributor_pk IS NOT NULL
+		GROUP BY contributor_pk
+	),
+	contributor_stats AS (
+		SELECT
+			c.pk as pk,
+			c.code as code,
 			COALESCE(c.name, '') as name,
 			COALESCE(dc.cnt, 0) as device_count,
 			COALESCE(sa.cnt, 0) as side_a_devices,
 			COALESCE(sz.cnt, 0) as side_z_devices,
-			COALESCE(lc.cnt, 0) as link_count
+			COALESCE(lc.cnt, 0) as link_count,
+			COALESCE(lc.active_cnt, 0) as active_link_count,
+			COALESCE(lc.bandwidth, 0) as bandwidth_bps
 		FROM dz_contributors_current c
 		LEFT JOIN device_counts dc ON c.pk = dc.contributor_pk
 		LEFT JOIN side_a_counts sa ON c.pk = sa.cpk
 		LEFT JOIN side_z_counts sz ON c.pk = sz.cpk
 		LEFT JOIN link_counts lc ON c.pk = lc.contributor_pk
-		ORDER BY c.code
+	)
+`
+
+// GetContributors returns a page of contributors with device and link counts.
+// Optional query parameters:
+//   - sort_by: device_count, bandwidth (total link bandwidth) or link_count;
+//     defaults to ordering by code
+//   - sort_dir: asc or desc (default desc when sort_by is set)
+//   - min_devices: only contributors with at least N devices
+//   - has_active_links: only contributors with activated links
+//
+// The total counts filtered contributors; the summary covers all of them.
+func GetContributors(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	filter, err := parseContributorListFilter(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	cond, condArgs := filter.condition()
+
+	pagination := ParsePagination(r, 100)
+	start := time.Now()
+
+	var total uint64
+	var summary ContributorListSummary
+	err = envDB(ctx).QueryRow(ctx, contributorStatsQuery+`
+		SELECT
+			countIf(`+cond+`),
+			count(*),
+			sum(device_count),
+			sum(bandwidth_bps)
+		FROM contributor_stats
+	`, condArgs...).Scan(&total, &summary.TotalContributors, &summary.TotalDevices, &summary.TotalBandwidthBps)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("Contributors count error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	query := contributorStatsQuery + `
+		SELECT pk, code, name, device_count, side_a_devices, side_z_devices, link_count
+		FROM contributor_stats
+		WHERE ` + cond + `
+		ORDER BY ` + filter.orderBy() + `
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := envDB(ctx).Query(ctx, query, pagination.Limit, pagination.Offset)
+	rows, err := envDB(ctx).Query(ctx, query, append(condArgs, pagination.Limit, pagination.Offset)...)
 	duration := time.Since(start)
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Contributors query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Contributors query error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -104,16 +241,16 @@ func GetContributors(w http.ResponseWriter, r *http.Request) {
 			&c.SideZDevices,
 			&c.LinkCount,
 		); err != nil {
-			log.Printf("Contributors scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Contributors scan error", "error", err)
+			writeDBError(w, r, err)
 			return
 		}
 		contributors = append(contributors, c)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Contributors rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Contributors rows error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 
@@ -122,16 +259,19 @@ func GetContributors(w http.ResponseWriter, r *http.Request) {
 		contributors = []ContributorListItem{}
 	}
 
-	response := PaginatedResponse[ContributorListItem]{
-		Items:  contributors,
-		Total:  int(total),
-		Limit:  pagination.Limit,
-		Offset: pagination.Offset,
+	response := ContributorListResponse{
+		PaginatedResponse: PaginatedResponse[ContributorListItem]{
+			Items:  contributors,
+			Total:  int(total),
+			Limit:  pagination.Limit,
+			Offset: pagination.Offset,
+		},
+		Summary: summary,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -154,7 +294,7 @@ func GetContributor(w http.ResponseWriter, r *http.Request) {
 
 	pk := chi.URLParam(r, "pk")
 	if pk == "" {
-		http.Error(w, "missing contributor pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing contributor pk")
 		return
 	}
 
@@ -247,13 +387,13 @@ func GetContributor(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Contributor query error: %v", err)
-		http.Error(w, "contributor not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("Contributor query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeContributorNotFound, "contributor not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(contributor); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
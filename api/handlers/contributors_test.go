@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getContributors(t *testing.T, query string) handlers.ContributorListResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/contributors"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetContributors(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.ContributorListResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return resp
+}
+
+func contributorCodes(resp handlers.ContributorListResponse) []string {
+	codes := make([]string, 0, len(resp.Items))
+	for _, c := range resp.Items {
+		codes = append(codes, c.Code)
+	}
+	return codes
+}
+
+func TestGetContributors_SortAndFilter(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedContributorSummary(t)
+
+	resp := getContributors(t, "")
+	assert.Equal(t, []string{"CSUM", "OTHER"}, contributorCodes(resp))
+	assert.Equal(t, 2, resp.Total)
+	assert.EqualValues(t, 2, resp.Summary.TotalContributors)
+	assert.EqualValues(t, 4, resp.Summary.TotalDevices)
+	assert.EqualValues(t, 111000000000, resp.Summary.TotalBandwidthBps)
+
+	// OTHER has a single 100G link, CSUM 11G over two links
+	assert.Equal(t, []string{"OTHER", "CSUM"}, contributorCodes(getContributors(t, "?sort_by=bandwidth")))
+	assert.Equal(t, []string{"CSUM", "OTHER"}, contributorCodes(getContributors(t, "?sort_by=link_count")))
+	assert.Equal(t, []string{"OTHER", "CSUM"}, contributorCodes(getContributors(t, "?sort_by=device_count&sort_dir=asc")))
+
+	// Filters narrow the page and total but not the summary
+	resp = getContributors(t, "?min_devices=2&has_active_links=true")
+	assert.Equal(t, []string{"CSUM"}, contributorCodes(resp))
+	assert.Equal(t, 1, resp.Total)
+	assert.EqualValues(t, 2, resp.Summary.TotalContributors)
+}
+
+func TestGetContributors_InvalidParams(t *testing.T) {
+	for _, query := range []string{
+		"?sort_by=name",
+		"?sort_dir=up",
+		"?min_devices=-1",
+		"?has_active_links=yes",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/dz/contributors"+query, nil)
+		rr := httptest.NewRecorder()
+		handlers.GetContributors(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
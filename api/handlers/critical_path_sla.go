@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"container/heap"
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// CriticalPathViolation is a metro pair whose best ISIS path is over the SLA
+type CriticalPathViolation struct {
+	FromMetroCode   string    `json:"fromMetroCode"`
+	ToMetroCode     string    `json:"toMetroCode"`
+	CurrentMetricMs float64   `json:"currentMetricMs"`
+	SlaMs           float64   `json:"slaMs"`
+	ViolationMs     float64   `json:"violationMs"` // currentMetricMs - slaMs
+	BestPath        []PathHop `json:"bestPath"`
+}
+
+// CriticalPathSLAResponse is the response for the critical path endpoint
+type CriticalPathSLAResponse struct {
+	SlaMs            float64                 `json:"slaMs"`
+	Violations       []CriticalPathViolation `json:"violations"`
+	TotalPairs       int                     `json:"totalPairs"` // connected metro pairs
+	TotalViolations  int                     `json:"totalViolations"`
+	CompliancePct    float64                 `json:"compliancePct"`
+	UnreachablePairs int                     `json:"unreachablePairs"` // not counted in totalPairs
+}
+
+// GetCriticalPathSLA finds metro pairs whose minimum-metric ISIS path exceeds
+// ?sla_ms=. ISIS metrics are in microseconds, so a path's metric is compared
+// to sla_ms * 1000. Violations are ordered by how far over the SLA they are.
+func GetCriticalPathSLA(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	slaMs, err := strconv.ParseFloat(r.URL.Query().Get("sla_ms"), 64)
+	if err != nil || slaMs <= 0 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "sla_ms must be a positive number of milliseconds")
+		return
+	}
+
+	metroCodes, err := loadMetroCodes(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Critical path metro query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	start := time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("critical_path_sla", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Critical path graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	writeJSON(w, criticalPathSLA(g, metroCodes, slaMs))
+}
+
+// criticalPathSLA checks the best path between every pair of metros with ISIS
+// devices against the SLA
+func criticalPathSLA(g *isisGraph, metroCodes map[string]string, slaMs float64) CriticalPathSLAResponse {
+	response := CriticalPathSLAResponse{SlaMs: slaMs, Violations: []CriticalPathViolation{}}
+	slaUs := int64(slaMs * 1000)
+
+	devicesByMetro := make(map[string][]int)
+	for i, node := range g.nodes {
+		if node.MetroPK != "" {
+			devicesByMetro[node.MetroPK] = append(devicesByMetro[node.MetroPK], i)
+		}
+	}
+	metros := make([]string, 0, len(devicesByMetro))
+	for pk := range devicesByMetro {
+		metros = append(metros, pk)
+	}
+	sort.Slice(metros, func(i, j int) bool { return metroCodes[metros[i]] < metroCodes[metros[j]] })
+
+	for i, from := range metros {
+		dist, prev := multiSourceShortestPaths(g.adj, devicesByMetro[from])
+		for _, to := range metros[i+1:] {
+			best := -1
+			for _, d := range devicesByMetro[to] {
+				if dist[d] >= 0 && (best < 0 || dist[d] < dist[best]) {
+					best = d
+				}
+			}
+			if best < 0 {
+				response.UnreachablePairs++
+				continue
+			}
+			response.TotalPairs++
+			if dist[best] <= slaUs {
+				continue
+			}
+
+			var path []int
+			for v := best; v != -1; v = prev[v] {
+				path = append(path, v)
+			}
+			hops := make([]PathHop, 0, len(path))
+			for j := len(path) - 1; j >= 0; j-- {
+				v := path[j]
+				hop := PathHop{
+					DevicePK:   g.nodes[v].PK,
+					DeviceCode: g.nodes[v].Code,
+					Status:     g.nodes[v].Status,
+					MetroCode:  metroCodes[g.nodes[v].MetroPK],
+				}
+				if j < len(path)-1 {
+					hop.EdgeMetric = uint32(edgeWeight(g.adj, path[j+1], v))
+				}
+				hops = append(hops, hop)
+			}
+
+			metricMs := float64(dist[best]) / 1000
+			response.Violations = append(response.Violations, CriticalPathViolation{
+				FromMetroCode:   metroCodes[from],
+				ToMetroCode:     metroCodes[to],
+				CurrentMetricMs: metricMs,
+				SlaMs:           slaMs,
+				ViolationMs:     metricMs - slaMs,
+				BestPath:        hops,
+			})
+		}
+	}
+
+	sort.SliceStable(response.Violations, func(i, j int) bool {
+		return response.Violations[i].ViolationMs > response.Violations[j].ViolationMs
+	})
+	response.TotalViolations = len(response.Violations)
+	if response.TotalPairs > 0 {
+		response.CompliancePct = float64(response.TotalPairs-response.TotalViolations) * 100 / float64(response.TotalPairs)
+	} else {
+		response.CompliancePct = 100
+	}
+	return response
+}
+
+// multiSourceShortestPaths runs Dijkstra from all sources at once, giving each
+// node's metric to the nearest source (-1 if unreachable) and its predecessor
+// on that path (-1 for sources).
+func multiSourceShortestPaths(adj [][]isisGraphEdge, sources []int) ([]int64, []int) {
+	n := len(adj)
+	dist := make([]int64, n)
+	prev := make([]int, n)
+	for i := range dist {
+		dist[i] = -1
+		prev[i] = -1
+	}
+
+	pq := &centralityQueue{}
+	for _, s := range sources {
+		dist[s] = 0
+		heap.Push(pq, centralityItem{node: s})
+	}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(centralityItem)
+		v := item.node
+		if item.dist > dist[v] {
+			continue // stale entry
+		}
+		for _, e := range adj[v] {
+			alt := dist[v] + e.weight
+			if dist[e.to] < 0 || alt < dist[e.to] {
+				dist[e.to] = alt
+				prev[e.to] = v
+				heap.Push(pq, centralityItem{node: e.to, dist: alt})
+			}
+		}
+	}
+	return dist, prev
+}
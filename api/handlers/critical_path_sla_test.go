@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCriticalPathSLA(t *testing.T) {
+	// ams1 -4ms- fra1 -6ms- lon1, with ams1 -12ms- lon1 as a slower direct link
+	g := &isisGraph{
+		nodes: []isisGraphNode{
+			{PK: "ams1", Code: "ams1", MetroPK: "ams"},
+			{PK: "fra1", Code: "fra1", MetroPK: "fra"},
+			{PK: "lon1", Code: "lon1", MetroPK: "lon"},
+			{PK: "nyc1", Code: "nyc1", MetroPK: "nyc"},
+		},
+		adj: [][]isisGraphEdge{
+			{{to: 1, weight: 4000}, {to: 2, weight: 12000}},
+			{{to: 0, weight: 4000}, {to: 2, weight: 6000}},
+			{{to: 1, weight: 6000}, {to: 0, weight: 12000}},
+			nil,
+		},
+	}
+	codes := map[string]string{"ams": "AMS", "fra": "FRA", "lon": "LON", "nyc": "NYC"}
+
+	resp := criticalPathSLA(g, codes, 5)
+
+	// ams-fra is 4ms and within SLA; ams-lon is 10ms via fra and fra-lon is 6ms
+	if resp.TotalPairs != 3 || resp.UnreachablePairs != 3 {
+		t.Fatalf("expected 3 connected and 3 unreachable pairs, got %d and %d", resp.TotalPairs, resp.UnreachablePairs)
+	}
+	if resp.TotalViolations != 2 {
+		t.Fatalf("expected 2 violations, got %d", resp.TotalViolations)
+	}
+	worst := resp.Violations[0]
+	if worst.FromMetroCode != "AMS" || worst.ToMetroCode != "LON" {
+		t.Errorf("expected AMS-LON first, got %s-%s", worst.FromMetroCode, worst.ToMetroCode)
+	}
+	if math.Abs(worst.CurrentMetricMs-10) > 1e-9 || math.Abs(worst.ViolationMs-5) > 1e-9 {
+		t.Errorf("expected 10ms metric and 5ms violation, got %v and %v", worst.CurrentMetricMs, worst.ViolationMs)
+	}
+	if len(worst.BestPath) != 3 || worst.BestPath[1].DeviceCode != "fra1" || worst.BestPath[2].EdgeMetric != 6000 {
+		t.Errorf("expected path through fra1, got %+v", worst.BestPath)
+	}
+	if math.Abs(resp.CompliancePct-100.0/3) > 1e-9 {
+		t.Errorf("expected 33.3%% compliance, got %v", resp.CompliancePct)
+	}
+}
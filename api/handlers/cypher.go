@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
 	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	neo4jdriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 type CypherQueryRequest struct {
@@ -27,12 +29,12 @@ type CypherQueryResponse struct {
 func ExecuteCypher(w http.ResponseWriter, r *http.Request) {
 	var req CypherQueryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Query) == "" {
-		http.Error(w, "Query is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Query is required")
 		return
 	}
 
@@ -89,6 +91,7 @@ func ExecuteCypher(w http.ResponseWriter, r *http.Request) {
 	})
 
 	duration := time.Since(start)
+	metrics.RecordNeo4jQuery("cypher", duration, err)
 
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -105,3 +108,196 @@ func ExecuteCypher(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+type CypherExplainRequest struct {
+	Query  string         `json:"query"`
+	Params map[string]any `json:"params,omitempty"`
+	Mode   string         `json:"mode,omitempty"` // "explain" (default) or "profile"
+}
+
+// CypherPlanOperator is one operator in a Cypher execution plan. DbHits and
+// Rows are only set for profiled plans.
+type CypherPlanOperator struct {
+	Operator      string               `json:"operator"`
+	EstimatedRows float64              `json:"estimatedRows"`
+	DbHits        *int64               `json:"dbHits,omitempty"`
+	Rows          *int64               `json:"rows,omitempty"`
+	Identifiers   []string             `json:"identifiers"`
+	Arguments     map[string]any       `json:"arguments,omitempty"`
+	Children      []CypherPlanOperator `json:"children"`
+}
+
+type CypherExplainResponse struct {
+	Mode          string              `json:"mode"`
+	Plan          *CypherPlanOperator `json:"plan,omitempty"`
+	EstimatedRows float64             `json:"estimatedRows"`
+	DbHits        *int64              `json:"dbHits,omitempty"`
+	ElapsedMs     int64               `json:"elapsed_ms"`
+	Warning       string              `json:"warning,omitempty"`
+	Error         string              `json:"error,omitempty"`
+}
+
+// ExplainCypher returns the execution plan for a Cypher query. In explain mode
+// the query is only planned. In profile mode it is executed in a read
+// transaction and the plan includes actual rows and db hits.
+func ExplainCypher(w http.ResponseWriter, r *http.Request) {
+	var req CypherExplainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+
+	query := stripCypherPlanPrefix(req.Query)
+	if query == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Query is required")
+		return
+	}
+
+	mode := strings.ToLower(req.Mode)
+	if mode == "" {
+		mode = "explain"
+	}
+	if mode != "explain" && mode != "profile" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "mode must be 'explain' or 'profile'")
+		return
+	}
+
+	response := CypherExplainResponse{Mode: mode}
+	if mode == "profile" {
+		response.Warning = "PROFILE executes the query; it may be slow on large graphs"
+	}
+
+	// Check if Neo4j is available
+	if config.Neo4jClient == nil {
+		response.Error = "Neo4j is not available"
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.Transaction) (any, error) {
+		res, err := tx.Run(ctx, strings.ToUpper(mode)+" "+query, req.Params)
+		if err != nil {
+			return nil, err
+		}
+		return res.Consume(ctx)
+	})
+
+	response.ElapsedMs = time.Since(start).Milliseconds()
+	metrics.RecordNeo4jQuery("cypher_explain", time.Since(start), err)
+
+	if err != nil {
+		response.Error = err.Error()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	summary := result.(neo4jdriver.ResultSummary)
+	if profile := summary.Profile(); profile != nil {
+		plan := convertProfiledPlan(profile)
+		response.Plan = &plan
+		var total int64
+		sumDbHits(plan, &total)
+		response.DbHits = &total
+	} else if p := summary.Plan(); p != nil {
+		plan := convertPlan(p)
+		response.Plan = &plan
+	}
+	if response.Plan != nil {
+		response.EstimatedRows = response.Plan.EstimatedRows
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// stripCypherPlanPrefix trims the query and removes a leading EXPLAIN or
+// PROFILE keyword, since the handler adds its own.
+func stripCypherPlanPrefix(query string) string {
+	query = strings.TrimSpace(query)
+	for _, keyword := range []string{"EXPLAIN", "PROFILE"} {
+		if strings.EqualFold(query, keyword) {
+			return ""
+		}
+		if len(query) > len(keyword) && strings.EqualFold(query[:len(keyword)], keyword) &&
+			strings.ContainsAny(query[len(keyword):len(keyword)+1], " \t\r\n") {
+			return strings.TrimSpace(query[len(keyword):])
+		}
+	}
+	return query
+}
+
+func convertPlan(p neo4jdriver.Plan) CypherPlanOperator {
+	op := CypherPlanOperator{
+		Operator:    p.Operator(),
+		Identifiers: p.Identifiers(),
+		Arguments:   convertPlanArguments(p.Arguments()),
+		Children:    []CypherPlanOperator{},
+	}
+	op.EstimatedRows = planEstimatedRows(p.Arguments())
+	for _, child := range p.Children() {
+		op.Children = append(op.Children, convertPlan(child))
+	}
+	return op
+}
+
+func convertProfiledPlan(p neo4jdriver.ProfiledPlan) CypherPlanOperator {
+	dbHits := p.DbHits()
+	rows := p.Records()
+	op := CypherPlanOperator{
+		Operator:    p.Operator(),
+		DbHits:      &dbHits,
+		Rows:        &rows,
+		Identifiers: p.Identifiers(),
+		Arguments:   convertPlanArguments(p.Arguments()),
+		Children:    []CypherPlanOperator{},
+	}
+	op.EstimatedRows = planEstimatedRows(p.Arguments())
+	for _, child := range p.Children() {
+		op.Children = append(op.Children, convertProfiledPlan(child))
+	}
+	return op
+}
+
+// planEstimatedRows reads the planner's row estimate from plan arguments.
+func planEstimatedRows(args map[string]any) float64 {
+	switch v := args["EstimatedRows"].(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
+
+// convertPlanArguments drops the fields promoted to CypherPlanOperator and
+// converts the rest to JSON-friendly values.
+func convertPlanArguments(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		switch k {
+		case "EstimatedRows", "DbHits", "Rows":
+			continue
+		}
+		out[k] = convertNeo4jValue(v)
+	}
+	return out
+}
+
+func sumDbHits(op CypherPlanOperator, total *int64) {
+	if op.DbHits != nil {
+		*total += *op.DbHits
+	}
+	for _, child := range op.Children {
+		sumDbHits(child, total)
+	}
+}
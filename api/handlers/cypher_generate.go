@@ -59,12 +59,12 @@ func getCypherGeneratePrompt() (string, error) {
 func GenerateCypher(w http.ResponseWriter, r *http.Request) {
 	var req GenerateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Prompt) == "" {
-		http.Error(w, "Prompt is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Prompt is required")
 		return
 	}
 
@@ -147,12 +147,12 @@ func GenerateCypher(w http.ResponseWriter, r *http.Request) {
 func GenerateCypherStream(w http.ResponseWriter, r *http.Request) {
 	var req GenerateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Prompt) == "" {
-		http.Error(w, "Prompt is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Prompt is required")
 		return
 	}
 
@@ -164,7 +164,7 @@ func GenerateCypherStream(w http.ResponseWriter, r *http.Request) {
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Streaming not supported")
 		return
 	}
 
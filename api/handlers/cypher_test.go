@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/malbeclabs/lake/api/config"
@@ -281,3 +282,82 @@ func TestCypherQueryResponse_Structure(t *testing.T) {
 	assert.Equal(t, response.RowCount, decoded.RowCount)
 	assert.Equal(t, response.ElapsedMs, decoded.ElapsedMs)
 }
+
+func explainCypher(t *testing.T, reqBody handlers.CypherExplainRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/cypher/explain", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handlers.ExplainCypher(rr, req)
+	return rr
+}
+
+func seedExplainData(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (:TestNode {name: 'Node1', value: 100})
+			CREATE (:TestNode {name: 'Node2', value: 200})
+			CREATE (:TestNode {name: 'Node3', value: 300})
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+}
+
+func TestExplainCypher_Explain(t *testing.T) {
+	seedExplainData(t)
+
+	rr := explainCypher(t, handlers.CypherExplainRequest{
+		Query:  "MATCH (n:TestNode) WHERE n.value > $min RETURN n.name",
+		Params: map[string]any{"min": 150},
+	})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.CypherExplainResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Empty(t, response.Error)
+	assert.Equal(t, "explain", response.Mode)
+	assert.Empty(t, response.Warning)
+	require.NotNil(t, response.Plan)
+	assert.True(t, strings.HasPrefix(response.Plan.Operator, "ProduceResults"), response.Plan.Operator)
+	assert.NotEmpty(t, response.Plan.Children)
+	assert.Nil(t, response.Plan.DbHits)
+	assert.Nil(t, response.DbHits)
+}
+
+func TestExplainCypher_Profile(t *testing.T) {
+	seedExplainData(t)
+
+	rr := explainCypher(t, handlers.CypherExplainRequest{
+		// A leading PROFILE keyword is stripped rather than doubled
+		Query: "PROFILE MATCH (n:TestNode) RETURN n.name",
+		Mode:  "profile",
+	})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.CypherExplainResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Empty(t, response.Error)
+	assert.Equal(t, "profile", response.Mode)
+	assert.NotEmpty(t, response.Warning)
+	require.NotNil(t, response.Plan)
+	require.NotNil(t, response.Plan.Rows)
+	assert.Equal(t, int64(3), *response.Plan.Rows)
+	require.NotNil(t, response.DbHits)
+	assert.Positive(t, *response.DbHits)
+}
+
+func TestExplainCypher_InvalidRequests(t *testing.T) {
+	apitesting.SetupTestNeo4j(t, testNeo4jDB)
+
+	assert.Equal(t, http.StatusBadRequest, explainCypher(t, handlers.CypherExplainRequest{Query: "  "}).Code)
+	assert.Equal(t, http.StatusBadRequest, explainCypher(t, handlers.CypherExplainRequest{Query: "EXPLAIN "}).Code)
+	assert.Equal(t, http.StatusBadRequest, explainCypher(t, handlers.CypherExplainRequest{Query: "MATCH (n) RETURN n", Mode: "analyze"}).Code)
+
+	rr := explainCypher(t, handlers.CypherExplainRequest{Query: "THIS IS NOT VALID CYPHER"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response handlers.CypherExplainResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.NotEmpty(t, response.Error)
+}
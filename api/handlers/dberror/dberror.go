@@ -138,24 +138,67 @@ func Classify(err error) ErrorType {
 	return ErrorTypeUnknown
 }
 
-// UserMessage returns a user-friendly error message based on the error type.
-func UserMessage(err error) string {
+// Machine-readable codes for classified database errors.
+const (
+	CodeUnavailable = "ERR_DB_UNAVAILABLE"
+	CodeTimeout     = "ERR_DB_TIMEOUT"
+	CodeAuth        = "ERR_DB_AUTH"
+	CodeQuery       = "ERR_DB_QUERY"
+	CodeUnknown     = "ERR_DB_UNKNOWN"
+)
+
+// Error is a classified database error with a machine-readable code and a
+// user-friendly message. The underlying error is available via Unwrap but is
+// never included in the message.
+type Error struct {
+	Type    ErrorType
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// UserError classifies err and returns it as an *Error, or nil if err is nil.
+func UserError(err error) *Error {
 	if err == nil {
-		return ""
+		return nil
 	}
 
-	switch Classify(err) {
+	errType := Classify(err)
+	e := &Error{Type: errType, Err: err}
+	switch errType {
 	case ErrorTypeConnectivity:
-		return "Database temporarily unavailable. Please try again in a moment."
+		e.Code = CodeUnavailable
+		e.Message = "Database temporarily unavailable. Please try again in a moment."
 	case ErrorTypeTimeout:
-		return "Request timed out. Please try again."
+		e.Code = CodeTimeout
+		e.Message = "Request timed out. Please try again."
 	case ErrorTypeAuth:
-		return "Database authentication error. Please contact support."
+		e.Code = CodeAuth
+		e.Message = "Database authentication error. Please contact support."
 	case ErrorTypeQuery:
-		return "Invalid query. Please check your input."
+		e.Code = CodeQuery
+		e.Message = "Invalid query. Please check your input."
 	default:
-		return "An unexpected error occurred. Please try again."
+		e.Code = CodeUnknown
+		e.Message = "An unexpected error occurred. Please try again."
+	}
+	return e
+}
+
+// UserMessage returns a user-friendly error message based on the error type.
+func UserMessage(err error) string {
+	if err == nil {
+		return ""
 	}
+	return UserError(err).Message
 }
 
 // RetryConfig holds configuration for retry behavior.
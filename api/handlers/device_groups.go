@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+	neo4jdriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Device groups by number of distinct ISIS neighbors
+const (
+	DeviceGroupLeaf     = "leaf"     // 1 neighbor
+	DeviceGroupEdge     = "edge"     // 2-3 neighbors
+	DeviceGroupTransit  = "transit"  // 4-6 neighbors
+	DeviceGroupCore     = "core"     // more than 6 neighbors
+	DeviceGroupIsolated = "isolated" // no neighbors; only listed with include_offline=true
+)
+
+// deviceGroupsCacheTTL is how long device groups are reused
+const deviceGroupsCacheTTL = 5 * time.Minute
+
+type deviceGroupsCacheEntry struct {
+	response  DeviceGroupsResponse
+	fetchedAt time.Time
+}
+
+var (
+	deviceGroupsCache   = make(map[bool]deviceGroupsCacheEntry) // keyed by include_offline
+	deviceGroupsCacheMu sync.RWMutex
+)
+
+// DeviceInfo is a device with its ISIS degree
+type DeviceInfo struct {
+	PK         string `json:"pk"`
+	Code       string `json:"code"`
+	Status     string `json:"status"`
+	DeviceType string `json:"deviceType"`
+	MetroPK    string `json:"metroPK,omitempty"`
+	MetroCode  string `json:"metroCode,omitempty"`
+	Degree     int    `json:"degree"`
+}
+
+// DeviceGroups holds devices by network role
+type DeviceGroups struct {
+	Leaf     []DeviceInfo `json:"leaf"`
+	Edge     []DeviceInfo `json:"edge"`
+	Transit  []DeviceInfo `json:"transit"`
+	Core     []DeviceInfo `json:"core"`
+	Isolated []DeviceInfo `json:"isolated,omitempty"`
+}
+
+// DeviceGroupsSummary counts the devices in each group
+type DeviceGroupsSummary struct {
+	LeafCount     int `json:"leafCount"`
+	EdgeCount     int `json:"edgeCount"`
+	TransitCount  int `json:"transitCount"`
+	CoreCount     int `json:"coreCount"`
+	IsolatedCount int `json:"isolatedCount,omitempty"`
+}
+
+// DeviceGroupsResponse is the response for the device groups endpoint
+type DeviceGroupsResponse struct {
+	Groups  DeviceGroups        `json:"groups"`
+	Summary DeviceGroupsSummary `json:"summary"`
+}
+
+// deviceGroup classifies a device by its number of distinct ISIS neighbors
+func deviceGroup(degree int) string {
+	switch {
+	case degree <= 0:
+		return DeviceGroupIsolated
+	case degree == 1:
+		return DeviceGroupLeaf
+	case degree <= 3:
+		return DeviceGroupEdge
+	case degree <= 6:
+		return DeviceGroupTransit
+	default:
+		return DeviceGroupCore
+	}
+}
+
+// groupDevices sorts devices into groups by degree
+func groupDevices(devices []DeviceInfo) DeviceGroupsResponse {
+	resp := DeviceGroupsResponse{Groups: DeviceGroups{
+		Leaf:    []DeviceInfo{},
+		Edge:    []DeviceInfo{},
+		Transit: []DeviceInfo{},
+		Core:    []DeviceInfo{},
+	}}
+	for _, d := range devices {
+		switch deviceGroup(d.Degree) {
+		case DeviceGroupLeaf:
+			resp.Groups.Leaf = append(resp.Groups.Leaf, d)
+		case DeviceGroupEdge:
+			resp.Groups.Edge = append(resp.Groups.Edge, d)
+		case DeviceGroupTransit:
+			resp.Groups.Transit = append(resp.Groups.Transit, d)
+		case DeviceGroupCore:
+			resp.Groups.Core = append(resp.Groups.Core, d)
+		default:
+			resp.Groups.Isolated = append(resp.Groups.Isolated, d)
+		}
+	}
+	resp.Summary = DeviceGroupsSummary{
+		LeafCount:     len(resp.Groups.Leaf),
+		EdgeCount:     len(resp.Groups.Edge),
+		TransitCount:  len(resp.Groups.Transit),
+		CoreCount:     len(resp.Groups.Core),
+		IsolatedCount: len(resp.Groups.Isolated),
+	}
+	return resp
+}
+
+// GetDeviceGroups classifies ISIS devices as leaf, edge, transit or core by
+// their number of ISIS neighbors. Only activated devices with adjacencies are
+// included unless include_offline=true, which adds other statuses and devices
+// without adjacencies.
+func GetDeviceGroups(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	includeOffline := r.URL.Query().Get("include_offline") == "true"
+
+	deviceGroupsCacheMu.RLock()
+	entry, ok := deviceGroupsCache[includeOffline]
+	deviceGroupsCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < deviceGroupsCacheTTL {
+		w.Header().Set("X-Cache", "HIT")
+		writeJSON(w, entry.response)
+		return
+	}
+
+	start := time.Now()
+	devices, err := loadDeviceDegrees(ctx, includeOffline)
+	metrics.RecordNeo4jQuery("device_groups", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Device groups query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	response := groupDevices(devices)
+
+	deviceGroupsCacheMu.Lock()
+	deviceGroupsCache[includeOffline] = deviceGroupsCacheEntry{response: response, fetchedAt: time.Now()}
+	deviceGroupsCacheMu.Unlock()
+
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, response)
+}
+
+// loadDeviceDegrees returns every device with its number of distinct ISIS neighbors
+func loadDeviceDegrees(ctx context.Context, includeOffline bool) ([]DeviceInfo, error) {
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	cypher := `
+		MATCH (d:Device)
+		WHERE $include_offline OR d.status = 'activated'
+		OPTIONAL MATCH (d)-[:ISIS_ADJACENT]-(n:Device)
+		WHERE $include_offline OR n.status = 'activated'
+		WITH d, count(DISTINCT n) AS degree
+		WHERE $include_offline OR degree > 0
+		OPTIONAL MATCH (d)-[:LOCATED_IN]->(m:Metro)
+		RETURN d.pk AS pk,
+		       d.code AS code,
+		       d.status AS status,
+		       d.device_type AS device_type,
+		       m.pk AS metro_pk,
+		       m.code AS metro_code,
+		       degree
+		ORDER BY degree DESC, code
+	`
+
+	result, err := session.Run(ctx, cypher, map[string]any{"include_offline": includeOffline})
+	var records []*neo4jdriver.Record
+	if err == nil {
+		records, err = result.Collect(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]DeviceInfo, 0, len(records))
+	for _, record := range records {
+		pk, _ := record.Get("pk")
+		code, _ := record.Get("code")
+		status, _ := record.Get("status")
+		deviceType, _ := record.Get("device_type")
+		metroPK, _ := record.Get("metro_pk")
+		metroCode, _ := record.Get("metro_code")
+		degree, _ := record.Get("degree")
+		devices = append(devices, DeviceInfo{
+			PK:         asString(pk),
+			Code:       asString(code),
+			Status:     asString(status),
+			DeviceType: asString(deviceType),
+			MetroPK:    asString(metroPK),
+			MetroCode:  asString(metroCode),
+			Degree:     int(asInt64(degree)),
+		})
+	}
+	return devices, nil
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupDevices(t *testing.T) {
+	devices := []DeviceInfo{
+		{PK: "core", Degree: 7},
+		{PK: "transit-hi", Degree: 6},
+		{PK: "transit-lo", Degree: 4},
+		{PK: "edge-hi", Degree: 3},
+		{PK: "edge-lo", Degree: 2},
+		{PK: "leaf", Degree: 1},
+		{PK: "isolated", Degree: 0},
+	}
+
+	resp := groupDevices(devices)
+
+	pks := func(ds []DeviceInfo) []string {
+		var out []string
+		for _, d := range ds {
+			out = append(out, d.PK)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"core"}, pks(resp.Groups.Core))
+	assert.Equal(t, []string{"transit-hi", "transit-lo"}, pks(resp.Groups.Transit))
+	assert.Equal(t, []string{"edge-hi", "edge-lo"}, pks(resp.Groups.Edge))
+	assert.Equal(t, []string{"leaf"}, pks(resp.Groups.Leaf))
+	assert.Equal(t, []string{"isolated"}, pks(resp.Groups.Isolated))
+	assert.Equal(t, DeviceGroupsSummary{LeafCount: 1, EdgeCount: 2, TransitCount: 2, CoreCount: 1, IsolatedCount: 1}, resp.Summary)
+}
+
+func TestGroupDevices_EmptyGroups(t *testing.T) {
+	resp := groupDevices(nil)
+	assert.NotNil(t, resp.Groups.Leaf)
+	assert.NotNil(t, resp.Groups.Core)
+	assert.Nil(t, resp.Groups.Isolated)
+	assert.Zero(t, resp.Summary)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// ReachableDevice is a device reachable over ISIS from the queried device
+type ReachableDevice struct {
+	PK        string `json:"pk"`
+	Code      string `json:"code"`
+	Status    string `json:"status"`
+	MetroPK   string `json:"metroPK,omitempty"`
+	HopCount  int    `json:"hopCount"`
+	MinMetric int64  `json:"minMetric"` // lowest total ISIS metric to the device
+}
+
+// DeviceReachabilityResponse is the response for the device reachability endpoint
+type DeviceReachabilityResponse struct {
+	DevicePK   string            `json:"devicePK"`
+	DeviceCode string            `json:"deviceCode"`
+	MaxHops    int               `json:"maxHops,omitempty"`
+	Reachable  []ReachableDevice `json:"reachable"`
+	// IsPartitioned is true if some ISIS device can't be reached at any
+	// distance, regardless of max_hops
+	IsPartitioned    bool `json:"isPartitioned"`
+	TotalISISDevices int  `json:"totalIsisDevices"`
+}
+
+// GetDeviceReachability lists every ISIS device reachable from a device with
+// its hop count and lowest total metric, sorted nearest first. It is the
+// complement of GetFailureImpact. Pass max_hops=N to stop N hops out.
+func GetDeviceReachability(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
+		return
+	}
+
+	maxHops := 0
+	if s := r.URL.Query().Get("max_hops"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "max_hops must be a positive integer")
+			return
+		}
+		maxHops = n
+	}
+
+	start := time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("device_reachability", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Device reachability graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	src, ok := g.index[pk]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeDeviceNotFound, "device not found in ISIS topology")
+		return
+	}
+
+	response := DeviceReachabilityResponse{
+		DevicePK:         pk,
+		DeviceCode:       g.nodes[src].Code,
+		MaxHops:          maxHops,
+		TotalISISDevices: len(g.nodes),
+	}
+	response.Reachable, response.IsPartitioned = deviceReachability(g, src, maxHops)
+
+	writeJSON(w, response)
+}
+
+// deviceReachability returns the devices reachable from src within maxHops
+// (0 for no limit), sorted by hop count then metric, and whether any device
+// is unreachable at any distance.
+func deviceReachability(g *isisGraph, src, maxHops int) ([]ReachableDevice, bool) {
+	hops := make([]int, len(g.nodes))
+	for i := range hops {
+		hops[i] = -1
+	}
+	hops[src] = 0
+	queue := []int{src}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, e := range g.adj[v] {
+			if hops[e.to] < 0 {
+				hops[e.to] = hops[v] + 1
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	dist, _ := multiSourceShortestPaths(g.adj, []int{src})
+
+	partitioned := false
+	reachable := []ReachableDevice{}
+	for i, node := range g.nodes {
+		if hops[i] < 0 {
+			partitioned = true
+			continue
+		}
+		if i == src || (maxHops > 0 && hops[i] > maxHops) {
+			continue
+		}
+		reachable = append(reachable, ReachableDevice{
+			PK:        node.PK,
+			Code:      node.Code,
+			Status:    node.Status,
+			MetroPK:   node.MetroPK,
+			HopCount:  hops[i],
+			MinMetric: dist[i],
+		})
+	}
+
+	sort.SliceStable(reachable, func(i, j int) bool {
+		if reachable[i].HopCount != reachable[j].HopCount {
+			return reachable[i].HopCount < reachable[j].HopCount
+		}
+		return reachable[i].MinMetric < reachable[j].MinMetric
+	})
+	return reachable, partitioned
+}
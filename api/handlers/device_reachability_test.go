@@ -0,0 +1,60 @@
+package handlers
+
+import "testing"
+
+func TestDeviceReachability(t *testing.T) {
+	// a -10- b -10- c, with a -50- c as a direct but costly adjacency, d -1- e
+	// off on their own
+	g := &isisGraph{
+		nodes: []isisGraphNode{
+			{PK: "a", Code: "a"},
+			{PK: "b", Code: "b"},
+			{PK: "c", Code: "c"},
+			{PK: "d", Code: "d"},
+			{PK: "e", Code: "e"},
+		},
+		adj: [][]isisGraphEdge{
+			{{to: 1, weight: 10}, {to: 2, weight: 50}},
+			{{to: 0, weight: 10}, {to: 2, weight: 10}},
+			{{to: 1, weight: 10}, {to: 0, weight: 50}},
+			{{to: 4, weight: 1}},
+			{{to: 3, weight: 1}},
+		},
+	}
+
+	reachable, partitioned := deviceReachability(g, 0, 0)
+	if !partitioned {
+		t.Error("expected a partition with d and e unreachable")
+	}
+	if len(reachable) != 2 {
+		t.Fatalf("expected 2 reachable devices, got %+v", reachable)
+	}
+	// c is one hop away over the direct adjacency but cheapest through b
+	if reachable[0].PK != "b" || reachable[0].HopCount != 1 || reachable[0].MinMetric != 10 {
+		t.Errorf("expected b at 1 hop and metric 10, got %+v", reachable[0])
+	}
+	if reachable[1].PK != "c" || reachable[1].HopCount != 1 || reachable[1].MinMetric != 20 {
+		t.Errorf("expected c at 1 hop and metric 20, got %+v", reachable[1])
+	}
+
+	reachable, partitioned = deviceReachability(g, 3, 0)
+	if !partitioned || len(reachable) != 1 || reachable[0].PK != "e" {
+		t.Errorf("expected only e reachable from d, got %+v", reachable)
+	}
+}
+
+func TestDeviceReachability_MaxHops(t *testing.T) {
+	// 0 - 1 - 2 - 3
+	g := &isisGraph{
+		nodes: []isisGraphNode{{PK: "0"}, {PK: "1"}, {PK: "2"}, {PK: "3"}},
+		adj:   undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}}),
+	}
+
+	reachable, partitioned := deviceReachability(g, 0, 2)
+	if partitioned {
+		t.Error("expected no partition; max_hops doesn't make devices unreachable")
+	}
+	if len(reachable) != 2 || reachable[1].PK != "2" || reachable[1].HopCount != 2 {
+		t.Errorf("expected devices 1 and 2 within 2 hops, got %+v", reachable)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// DeviceUptimeResponse is the response for the device uptime endpoint
+type DeviceUptimeResponse struct {
+	DevicePK        string   `json:"device_pk"`
+	DeviceCode      string   `json:"device_code"`
+	Window          string   `json:"window"`
+	WindowStart     string   `json:"window_start"`
+	WindowEnd       string   `json:"window_end"`
+	CurrentStatus   string   `json:"current_status"`
+	ObservedHours   float64  `json:"observed_hours"`
+	ActiveHours     float64  `json:"active_hours"`
+	AvailabilityPct float64  `json:"availability_pct"`
+	OutageCount     int      `json:"outage_count"`
+	MTBFHours       *float64 `json:"mean_time_between_failures_hours"`
+}
+
+// uptimeSegment is a span of time a device spent in a single status
+type uptimeSegment struct {
+	Status  string
+	Seconds float64
+}
+
+// summarizeUptime totals the time spent activated and counts transitions from
+// activated to any other status. Segments must be in time order.
+func summarizeUptime(segments []uptimeSegment) (activeSeconds, observedSeconds float64, outages int) {
+	for i, s := range segments {
+		observedSeconds += s.Seconds
+		if s.Status == "activated" {
+			activeSeconds += s.Seconds
+		} else if i > 0 && segments[i-1].Status == "activated" {
+			outages++
+		}
+	}
+	return activeSeconds, observedSeconds, outages
+}
+
+// GetDeviceUptime reports how much of a window (1h, 24h, 7d, 30d) a device spent
+// activated, based on status snapshots in dim_dz_devices_history. Time before the
+// device's first snapshot counts as unavailable. Mean time between failures is
+// active time divided by the number of activated -> non-activated transitions,
+// and is null when there were no outages.
+func GetDeviceUptime(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
+		return
+	}
+
+	window := r.URL.Query().Get("window")
+	var windowDuration time.Duration
+	switch window {
+	case "1h":
+		windowDuration = time.Hour
+	case "", "24h":
+		window = "24h"
+		windowDuration = 24 * time.Hour
+	case "7d":
+		windowDuration = 7 * 24 * time.Hour
+	case "30d":
+		windowDuration = 30 * 24 * time.Hour
+	default:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "window must be one of 1h, 24h, 7d, 30d")
+		return
+	}
+
+	start := time.Now()
+	windowEnd := start.UTC().Truncate(time.Second)
+	windowStart := windowEnd.Add(-windowDuration)
+
+	response := DeviceUptimeResponse{
+		DevicePK:    pk,
+		Window:      window,
+		WindowStart: windowStart.Format(time.RFC3339),
+		WindowEnd:   windowEnd.Format(time.RFC3339),
+	}
+
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT code, status
+		FROM dz_devices_current
+		WHERE pk = $1
+	`, pk).Scan(&response.DeviceCode, &response.CurrentStatus)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeDeviceNotFound, "device not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Device uptime device query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	// The status in effect at the window start is carried in as the first
+	// segment, then each snapshot inside the window lasts until the next one
+	// (or the window end).
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH snapshots AS (
+			SELECT toDateTime64($2, 3) AS ts, argMax(if(is_deleted = 1, 'deleted', status), snapshot_ts) AS status
+			FROM dim_dz_devices_history
+			WHERE pk = $1 AND snapshot_ts <= $2
+			HAVING count() > 0
+			UNION ALL
+			SELECT snapshot_ts AS ts, if(is_deleted = 1, 'deleted', status) AS status
+			FROM dim_dz_devices_history
+			WHERE pk = $1 AND snapshot_ts > $2 AND snapshot_ts < $3
+		)
+		SELECT
+			status,
+			dateDiff('millisecond', ts, leadInFrame(ts, 1, toDateTime64($3, 3)) OVER (
+				ORDER BY ts ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING
+			)) / 1000.0 AS duration_s
+		FROM snapshots
+		ORDER BY ts
+	`, pk, windowStart, windowEnd)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("Device uptime history query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var segments []uptimeSegment
+	for rows.Next() {
+		var s uptimeSegment
+		if err := rows.Scan(&s.Status, &s.Seconds); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(start), err)
+			LoggerFromContext(ctx).Error("Device uptime history scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		segments = append(segments, s)
+	}
+	err = rows.Err()
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Device uptime history rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	activeSeconds, observedSeconds, outages := summarizeUptime(segments)
+	response.ActiveHours = activeSeconds / 3600
+	response.ObservedHours = observedSeconds / 3600
+	response.AvailabilityPct = activeSeconds * 100 / windowDuration.Seconds()
+	response.OutageCount = outages
+	if outages > 0 {
+		mtbf := response.ActiveHours / float64(outages)
+		response.MTBFHours = &mtbf
+	}
+
+	LoggerFromContext(ctx).Info("Device uptime completed", "pk", pk, "window", window, "availability_pct", response.AvailabilityPct, "outages", outages, "duration_ms", duration.Milliseconds())
+
+	writeJSON(w, response)
+}
@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedDeviceUptime inserts dev-up, activated since 30h ago, suspended from 12h
+// ago to 6h ago, then activated again.
+func seedDeviceUptime(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-up', now() - INTERVAL 30 HOUR, now(), generateUUIDv4(), 0, 1, 'dev-up', 'activated', 'hybrid', 'DEV-UP', '', '', '', 0),
+		('dev-up', now() - INTERVAL 12 HOUR, now(), generateUUIDv4(), 0, 2, 'dev-up', 'suspended', 'hybrid', 'DEV-UP', '', '', '', 0),
+		('dev-up', now() - INTERVAL 6 HOUR, now(), generateUUIDv4(), 0, 3, 'dev-up', 'activated', 'hybrid', 'DEV-UP', '', '', '', 0)`))
+}
+
+func getDeviceUptime(pk, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/devices/"+pk+"/uptime"+query, nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetDeviceUptime(rr, req)
+	return rr
+}
+
+func TestGetDeviceUptime(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedDeviceUptime(t)
+
+	rr := getDeviceUptime("dev-up", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.DeviceUptimeResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "DEV-UP", resp.DeviceCode)
+	assert.Equal(t, "24h", resp.Window)
+	assert.Equal(t, "activated", resp.CurrentStatus)
+	assert.InDelta(t, 24.0, resp.ObservedHours, 0.01)
+	assert.InDelta(t, 18.0, resp.ActiveHours, 0.01)
+	assert.InDelta(t, 75.0, resp.AvailabilityPct, 0.1)
+	assert.Equal(t, 1, resp.OutageCount)
+	require.NotNil(t, resp.MTBFHours)
+	assert.InDelta(t, 18.0, *resp.MTBFHours, 0.01)
+}
+
+func TestGetDeviceUptime_NoOutages(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedDeviceUptime(t)
+
+	rr := getDeviceUptime("dev-up", "?window=1h")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.DeviceUptimeResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "1h", resp.Window)
+	assert.InDelta(t, 100.0, resp.AvailabilityPct, 0.1)
+	assert.Equal(t, 0, resp.OutageCount)
+	assert.Nil(t, resp.MTBFHours)
+}
+
+func TestGetDeviceUptime_Errors(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedDeviceUptime(t)
+
+	rr := getDeviceUptime("dev-up", "?window=90d")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = getDeviceUptime("missing", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	var resp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, handlers.ErrCodeDeviceNotFound, resp.Code)
+}
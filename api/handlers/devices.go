@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -29,19 +33,116 @@ type DeviceListItem struct {
 	PeakOutBps      float64 `json:"peak_out_bps"`
 }
 
+// Serviceability device statuses and types accepted by the GetDevices filters
+var (
+	deviceStatusValues = []string{
+		"pending", "activated", "suspended", "deleted", "rejected", "drained",
+		"device-provisioning", "link-provisioning",
+	}
+	deviceTypeValues = []string{"hybrid", "transit", "edge"}
+)
+
+// deviceListFilter holds the optional GetDevices filters. Each list matches any
+// of its values; non-empty filters are combined with AND.
+type deviceListFilter struct {
+	Statuses       []string
+	DeviceTypes    []string
+	MetroPKs       []string
+	ContributorPKs []string
+	HasISIS        bool
+}
+
+// parseDeviceListFilter parses the status, device_type, metro_pk,
+// contributor_pk and has_isis query parameters. All list parameters are
+// comma-separated; status and device_type must be known enum values.
+func parseDeviceListFilter(r *http.Request) (deviceListFilter, error) {
+	q := r.URL.Query()
+	f := deviceListFilter{
+		Statuses:       splitCSV(q.Get("status")),
+		DeviceTypes:    splitCSV(q.Get("device_type")),
+		MetroPKs:       splitCSV(q.Get("metro_pk")),
+		ContributorPKs: splitCSV(q.Get("contributor_pk")),
+	}
+	for _, s := range f.Statuses {
+		if !slices.Contains(deviceStatusValues, s) {
+			return f, fmt.Errorf("invalid status %q: must be one of %s", s, strings.Join(deviceStatusValues, ", "))
+		}
+	}
+	for _, t := range f.DeviceTypes {
+		if !slices.Contains(deviceTypeValues, t) {
+			return f, fmt.Errorf("invalid device_type %q: must be one of %s", t, strings.Join(deviceTypeValues, ", "))
+		}
+	}
+	switch q.Get("has_isis") {
+	case "", "false":
+	case "true":
+		f.HasISIS = true
+	default:
+		return f, fmt.Errorf("has_isis must be true or false")
+	}
+	return f, nil
+}
+
+// whereClause returns the WHERE clause (empty if unfiltered) and its arguments
+// for a query over dz_devices_current aliased as d. ISIS system IDs only live
+// in the graph, so has_isis matches devices whose latest IS-IS adjacency event
+// is up, the same devices the graph sync stamps with ISIS properties.
+func (f deviceListFilter) whereClause() (string, []any) {
+	var conditions []string
+	var args []any
+	if len(f.Statuses) > 0 {
+		conditions = append(conditions, "d.status IN (?)")
+		args = append(args, f.Statuses)
+	}
+	if len(f.DeviceTypes) > 0 {
+		conditions = append(conditions, "d.device_type IN (?)")
+		args = append(args, f.DeviceTypes)
+	}
+	if len(f.MetroPKs) > 0 {
+		conditions = append(conditions, "d.metro_pk IN (?)")
+		args = append(args, f.MetroPKs)
+	}
+	if len(f.ContributorPKs) > 0 {
+		conditions = append(conditions, "d.contributor_pk IN (?)")
+		args = append(args, f.ContributorPKs)
+	}
+	if f.HasISIS {
+		conditions = append(conditions, `d.pk IN (
+			SELECT from_pk
+			FROM fact_isis_adjacency_events
+			GROUP BY from_pk, to_pk
+			HAVING argMax(event_type, event_ts) = 'up'
+		)`)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// GetDevices returns a page of devices. Optional query parameters filter the
+// list: status and device_type (comma-separated enum values), metro_pk and
+// contributor_pk (comma-separated), and has_isis=true.
 func GetDevices(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
+	filter, err := parseDeviceListFilter(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	where, whereArgs := filter.whereClause()
+
 	pagination := ParsePagination(r, 100)
 	start := time.Now()
 
 	// Get total count
-	countQuery := `SELECT count(*) FROM dz_devices_current`
+	countQuery := `SELECT count(*) FROM dz_devices_current d ` + where
 	var total uint64
-	if err := envDB(ctx).QueryRow(ctx, countQuery).Scan(&total); err != nil {
-		log.Printf("Devices count error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := envDB(ctx).QueryRow(ctx, countQuery, whereArgs...).Scan(&total); err != nil {
+		LoggerFromContext(ctx).Error("Devices count error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -108,17 +209,19 @@ func GetDevices(w http.ResponseWriter, r *http.Request) {
 		LEFT JOIN user_counts uc ON d.pk = uc.device_pk
 		LEFT JOIN traffic_rates tr ON d.pk = tr.device_pk
 		LEFT JOIN peak_rates pr ON d.pk = pr.device_pk
+		` + where + `
 		ORDER BY d.code
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := envDB(ctx).Query(ctx, query, pagination.Limit, pagination.Offset)
+	args := append(whereArgs, pagination.Limit, pagination.Offset)
+	rows, err := envDB(ctx).Query(ctx, query, args...)
 	duration := time.Since(start)
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Devices query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Devices query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -143,16 +246,16 @@ func GetDevices(w http.ResponseWriter, r *http.Request) {
 			&d.PeakInBps,
 			&d.PeakOutBps,
 		); err != nil {
-			log.Printf("Devices scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Devices scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		devices = append(devices, d)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Devices rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Devices rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -170,7 +273,7 @@ func GetDevices(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -203,7 +306,7 @@ func GetDevice(w http.ResponseWriter, r *http.Request) {
 
 	pk := chi.URLParam(r, "pk")
 	if pk == "" {
-		http.Error(w, "missing device pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
 		return
 	}
 
@@ -328,19 +431,227 @@ func GetDevice(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Device query error: %v", err)
-		http.Error(w, "device not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("Device query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeDeviceNotFound, "device not found")
 		return
 	}
 
 	// Parse interfaces JSON
 	if err := json.Unmarshal([]byte(interfacesJSON), &device.Interfaces); err != nil {
-		log.Printf("failed to parse interfaces JSON for device %s: %v", device.PK, err)
+		LoggerFromContext(ctx).Error("failed to parse interfaces JSON", "device_pk", device.PK, "error", err)
 		device.Interfaces = []DeviceInterface{}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(device); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
+
+// DeviceInterfaceStatus is the latest counter reading of one of a device's
+// interfaces
+type DeviceInterfaceStatus struct {
+	InterfaceName string  `json:"interfaceName"`
+	LinkPK        string  `json:"linkPK"`
+	LinkCode      string  `json:"linkCode"`
+	InBps         float64 `json:"inBps"`
+	OutBps        float64 `json:"outBps"`
+	InDiscards    int64   `json:"inDiscards"`
+	OutDiscards   int64   `json:"outDiscards"`
+	InErrors      int64   `json:"inErrors"`
+	OutErrors     int64   `json:"outErrors"`
+	OperStatus    string  `json:"operStatus"` // serviceability interface status; empty if not onchain
+	HasIssues     bool    `json:"hasIssues"`  // errors or discards in the latest reading
+}
+
+// GetDeviceInterfaces returns the interfaces that reported counters in the
+// past hour on a device, with the rates, discards and errors of each
+// interface's latest reading, sorted by interface name.
+func GetDeviceInterfaces(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
+		return
+	}
+
+	var interfacesJSON string
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT COALESCE(interfaces, '[]')
+		FROM dz_devices_current
+		WHERE pk = ?
+	`, pk).Scan(&interfacesJSON)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeDeviceNotFound, "device not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Device interfaces device query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	var onchain []DeviceInterface
+	if err := json.Unmarshal([]byte(interfacesJSON), &onchain); err != nil {
+		LoggerFromContext(ctx).Error("failed to parse interfaces JSON", "device_pk", pk, "error", err)
+	}
+	operStatus := make(map[string]string, len(onchain))
+	for _, intf := range onchain {
+		operStatus[intf.Name] = intf.Status
+	}
+
+	start = time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT
+			latest.intf,
+			latest.link_pk,
+			COALESCE(l.code, '') AS link_code,
+			latest.in_bps,
+			latest.out_bps,
+			latest.in_discards,
+			latest.out_discards,
+			latest.in_errors,
+			latest.out_errors
+		FROM (
+			SELECT
+				intf,
+				argMax(link_pk, event_ts) AS link_pk,
+				argMax(greatest(0, COALESCE(in_octets_delta * 8 / nullIf(delta_duration, 0), 0)), event_ts) AS in_bps,
+				argMax(greatest(0, COALESCE(out_octets_delta * 8 / nullIf(delta_duration, 0), 0)), event_ts) AS out_bps,
+				argMax(toInt64(greatest(0, COALESCE(in_discards_delta, 0))), event_ts) AS in_discards,
+				argMax(toInt64(greatest(0, COALESCE(out_discards_delta, 0))), event_ts) AS out_discards,
+				argMax(toInt64(greatest(0, COALESCE(in_errors_delta, 0))), event_ts) AS in_errors,
+				argMax(toInt64(greatest(0, COALESCE(out_errors_delta, 0))), event_ts) AS out_errors
+			FROM fact_dz_device_interface_counters
+			WHERE device_pk = ?
+			  AND event_ts > now() - INTERVAL 1 HOUR
+			GROUP BY intf
+		) latest
+		LEFT JOIN dz_links_current l ON latest.link_pk = l.pk
+		ORDER BY latest.intf
+	`, pk)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Device interfaces query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	interfaces := []DeviceInterfaceStatus{}
+	for rows.Next() {
+		var i DeviceInterfaceStatus
+		if err := rows.Scan(
+			&i.InterfaceName,
+			&i.LinkPK,
+			&i.LinkCode,
+			&i.InBps,
+			&i.OutBps,
+			&i.InDiscards,
+			&i.OutDiscards,
+			&i.InErrors,
+			&i.OutErrors,
+		); err != nil {
+			LoggerFromContext(ctx).Error("Device interfaces scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		i.OperStatus = operStatus[i.InterfaceName]
+		i.HasIssues = i.InDiscards > 0 || i.OutDiscards > 0 || i.InErrors > 0 || i.OutErrors > 0
+		interfaces = append(interfaces, i)
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Device interfaces rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, interfaces)
+}
+
+// DeviceConfigDriftResponse compares a device's first snapshot to its current state
+type DeviceConfigDriftResponse struct {
+	HasChanges      bool          `json:"hasChanges"`
+	InitialConfig   DeviceEntity  `json:"initialConfig"`
+	InitialSnapshot time.Time     `json:"initialSnapshotAt"`
+	CurrentConfig   DeviceEntity  `json:"currentConfig"`
+	Changes         []FieldChange `json:"changes"`
+}
+
+// GetDeviceConfigDrift compares a device's first row in dim_dz_devices_history
+// with its current row. Status is left out of the changes since devices are
+// expected to move through the activation lifecycle.
+func GetDeviceConfigDrift(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
+		return
+	}
+
+	var response DeviceConfigDriftResponse
+	cur := &response.CurrentConfig
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT d.pk, d.code, d.status, d.device_type, d.public_ip, d.contributor_pk,
+		       d.metro_pk, d.max_users, COALESCE(c.code, ''), COALESCE(m.code, '')
+		FROM dz_devices_current d
+		LEFT JOIN dz_contributors_current c ON d.contributor_pk = c.pk
+		LEFT JOIN dz_metros_current m ON d.metro_pk = m.pk
+		WHERE d.pk = ?
+	`, pk).Scan(&cur.PK, &cur.Code, &cur.Status, &cur.DeviceType, &cur.PublicIP, &cur.ContributorPK,
+		&cur.MetroPK, &cur.MaxUsers, &cur.ContributorCode, &cur.MetroCode)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeDeviceNotFound, "device not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Device config drift current query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	initial := &response.InitialConfig
+	start = time.Now()
+	err = envDB(ctx).QueryRow(ctx, `
+		SELECT h.snapshot_ts, h.pk, h.code, h.status, h.device_type, h.public_ip, h.contributor_pk,
+		       h.metro_pk, h.max_users, COALESCE(c.code, ''), COALESCE(m.code, '')
+		FROM (
+			SELECT *
+			FROM dim_dz_devices_history
+			WHERE pk = ? AND is_deleted = 0
+			ORDER BY snapshot_ts, ingested_at
+			LIMIT 1
+		) h
+		LEFT JOIN dz_contributors_current c ON h.contributor_pk = c.pk
+		LEFT JOIN dz_metros_current m ON h.metro_pk = m.pk
+	`, pk).Scan(&response.InitialSnapshot, &initial.PK, &initial.Code, &initial.Status, &initial.DeviceType,
+		&initial.PublicIP, &initial.ContributorPK, &initial.MetroPK, &initial.MaxUsers,
+		&initial.ContributorCode, &initial.MetroCode)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "no history for device")
+			return
+		}
+		LoggerFromContext(ctx).Error("Device config drift history query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	response.Changes = []FieldChange{}
+	for _, c := range deviceFieldChanges(response.InitialConfig, response.CurrentConfig) {
+		if c.Field != "status" {
+			response.Changes = append(response.Changes, c)
+		}
+	}
+	response.HasChanges = len(response.Changes) > 0
+
+	writeJSON(w, response)
+}
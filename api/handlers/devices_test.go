@@ -397,3 +397,215 @@ func TestGetDevice_HandlesNullContributor(t *testing.T) {
 	assert.Equal(t, "", device.ContributorPK)
 	assert.Equal(t, "", device.ContributorCode)
 }
+
+// seedDeviceFilters inserts four devices across statuses, types, metros and
+// contributors; only dev-isis has an IS-IS adjacency that is currently up.
+func seedDeviceFilters(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-isis', now(), now(), generateUUIDv4(), 0, 1, 'dev-isis', 'activated', 'hybrid', 'AMS-01', '', 'contrib-a', 'metro-ams', 0),
+		('dev-down', now(), now(), generateUUIDv4(), 0, 1, 'dev-down', 'activated', 'transit', 'AMS-02', '', 'contrib-b', 'metro-ams', 0),
+		('dev-drained', now(), now(), generateUUIDv4(), 0, 1, 'dev-drained', 'drained', 'edge', 'NYC-01', '', 'contrib-a', 'metro-nyc', 0),
+		('dev-pending', now(), now(), generateUUIDv4(), 0, 1, 'dev-pending', 'pending', 'hybrid', 'NYC-02', '', 'contrib-b', 'metro-nyc', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_isis_adjacency_events
+		(event_ts, ingested_at, from_pk, to_pk, event_type, metric)
+		VALUES
+		(now() - INTERVAL 2 HOUR, now(), 'dev-isis', 'dev-down', 'up', 1000),
+		(now() - INTERVAL 2 HOUR, now(), 'dev-down', 'dev-isis', 'up', 1000),
+		(now() - INTERVAL 1 HOUR, now(), 'dev-down', 'dev-isis', 'down', 1000)`))
+}
+
+func getDeviceCodes(t *testing.T, query string) []string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/devices"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetDevices(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var response handlers.PaginatedResponse[handlers.DeviceListItem]
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	codes := make([]string, 0, len(response.Items))
+	for _, d := range response.Items {
+		codes = append(codes, d.Code)
+	}
+	assert.Equal(t, len(codes), response.Total, "total should reflect the filter")
+	return codes
+}
+
+func TestGetDevices_Filters(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedDeviceFilters(t)
+
+	for name, tc := range map[string]struct {
+		query string
+		want  []string
+	}{
+		"no filters":          {"", []string{"AMS-01", "AMS-02", "NYC-01", "NYC-02"}},
+		"status":              {"?status=activated", []string{"AMS-01", "AMS-02"}},
+		"status list":         {"?status=drained,pending", []string{"NYC-01", "NYC-02"}},
+		"device_type":         {"?device_type=hybrid", []string{"AMS-01", "NYC-02"}},
+		"metro_pk":            {"?metro_pk=metro-nyc", []string{"NYC-01", "NYC-02"}},
+		"contributor_pk":      {"?contributor_pk=contrib-a", []string{"AMS-01", "NYC-01"}},
+		"has_isis":            {"?has_isis=true", []string{"AMS-01"}},
+		"combined":            {"?status=activated&metro_pk=metro-ams&contributor_pk=contrib-b", []string{"AMS-02"}},
+		"has_isis false":      {"?has_isis=false", []string{"AMS-01", "AMS-02", "NYC-01", "NYC-02"}},
+		"no match":            {"?device_type=edge&status=activated", []string{}},
+		"contributor_pk list": {"?contributor_pk=contrib-a,contrib-b&device_type=transit", []string{"AMS-02"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, getDeviceCodes(t, tc.query))
+		})
+	}
+}
+
+func TestGetDevices_InvalidFilters(t *testing.T) {
+	for name, query := range map[string]string{
+		"unknown status":      "?status=activated,up",
+		"injected status":     "?status=activated')%20OR%20('1'%3D'1",
+		"unknown device_type": "?device_type=router",
+		"invalid has_isis":    "?has_isis=yes",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/dz/devices"+query, nil)
+			rr := httptest.NewRecorder()
+			handlers.GetDevices(rr, req)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+// seedDeviceInterfaces inserts a device with three interfaces: Ethernet1 on a
+// link with a clean latest reading after an earlier one with errors,
+// Ethernet2 with discards in its latest reading, and Loopback0 which only
+// reported two hours ago.
+func seedDeviceInterfaces(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users, interfaces)
+		VALUES
+		('dev-intf', now(), now(), generateUUIDv4(), 0, 1, 'dev-intf', 'activated', 'hybrid', 'AMS-01', '', '', '', 0,
+		 '[{"name":"Ethernet1","ip":"10.0.0.1/31","status":"activated"},{"name":"Ethernet2","ip":"","status":"pending"}]')`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns, committed_jitter_ns,
+		 bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-intf', now(), now(), generateUUIDv4(), 0, 1, 'link-intf', 'activated', 'AMS-NYC-01', '', '', 'dev-intf', '', 'Ethernet1', '', 'WAN', 0, 0, 0, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, link_pk, in_octets_delta, out_octets_delta, delta_duration,
+		 in_errors_delta, out_errors_delta, in_discards_delta, out_discards_delta)
+		VALUES
+		(now() - INTERVAL 10 MINUTE, now(), 'dev-intf', 'Ethernet1', 'link-intf', 750, 0, 60.0, 3, 0, 0, 0),
+		(now() - INTERVAL 5 MINUTE, now(), 'dev-intf', 'Ethernet1', 'link-intf', 7500, 1500, 60.0, 0, 0, 0, 0),
+		(now() - INTERVAL 5 MINUTE, now(), 'dev-intf', 'Ethernet2', '', 0, 0, 60.0, 0, 0, 0, 7),
+		(now() - INTERVAL 2 HOUR, now(), 'dev-intf', 'Loopback0', '', 0, 0, 60.0, 0, 0, 0, 0),
+		(now() - INTERVAL 5 MINUTE, now(), 'other-dev', 'Ethernet1', '', 0, 0, 60.0, 0, 0, 0, 0)`))
+}
+
+func TestGetDeviceInterfaces(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedDeviceInterfaces(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/devices/dev-intf/interface-list", nil)
+	req = withChiURLParams(req, map[string]string{"pk": "dev-intf"})
+	rr := httptest.NewRecorder()
+	handlers.GetDeviceInterfaces(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var interfaces []handlers.DeviceInterfaceStatus
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&interfaces))
+	assert.Equal(t, []handlers.DeviceInterfaceStatus{
+		{
+			InterfaceName: "Ethernet1",
+			LinkPK:        "link-intf",
+			LinkCode:      "AMS-NYC-01",
+			InBps:         1000,
+			OutBps:        200,
+			OperStatus:    "activated",
+		},
+		{
+			InterfaceName: "Ethernet2",
+			OutDiscards:   7,
+			OperStatus:    "pending",
+			HasIssues:     true,
+		},
+	}, interfaces)
+}
+
+func TestGetDeviceInterfaces_NotFound(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/devices/missing/interface-list", nil)
+	req = withChiURLParams(req, map[string]string{"pk": "missing"})
+	rr := httptest.NewRecorder()
+	handlers.GetDeviceInterfaces(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func getDeviceConfigDrift(pk string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/devices/"+pk+"/config-drift", nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetDeviceConfigDrift(rr, req)
+	return rr
+}
+
+func TestGetDeviceConfigDrift(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	ctx := t.Context()
+
+	// Onboarded pending with 10 users, then activated and raised to 20
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-drift', now() - INTERVAL 1 DAY, now(), generateUUIDv4(), 0, 1, 'dev-drift', 'pending', 'hybrid', 'AMS-01', '', 'c1', 'm1', 10),
+		('dev-drift', now(), now(), generateUUIDv4(), 0, 2, 'dev-drift', 'activated', 'hybrid', 'AMS-01', '', 'c1', 'm1', 20),
+		('dev-same', now(), now(), generateUUIDv4(), 0, 3, 'dev-same', 'activated', 'hybrid', 'FRA-01', '', 'c1', 'm1', 5)`))
+
+	rr := getDeviceConfigDrift("dev-drift")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.DeviceConfigDriftResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.True(t, resp.HasChanges)
+	assert.EqualValues(t, 10, resp.InitialConfig.MaxUsers)
+	assert.EqualValues(t, 20, resp.CurrentConfig.MaxUsers)
+	require.Len(t, resp.Changes, 1, "status changes are not drift")
+	assert.Equal(t, "max_users", resp.Changes[0].Field)
+
+	rr = getDeviceConfigDrift("dev-same")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	resp = handlers.DeviceConfigDriftResponse{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.False(t, resp.HasChanges)
+	assert.Empty(t, resp.Changes)
+
+	assert.Equal(t, http.StatusNotFound, getDeviceConfigDrift("missing").Code)
+}
+
+func TestGetDeviceConfigDrift_NoHistory(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	ctx := t.Context()
+
+	// Only a deletion left, so there's no live history to compare against
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-gone', now(), now(), generateUUIDv4(), 1, 1, 'dev-gone', 'activated', 'hybrid', 'AMS-01', '', 'c1', 'm1', 10)`))
+
+	for _, pk := range []string{"dev-gone", "dev-never"} {
+		rr := getDeviceConfigDrift(pk)
+		assert.Equal(t, http.StatusNotFound, rr.Code, pk+": "+rr.Body.String())
+	}
+}
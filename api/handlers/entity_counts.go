@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// entityCountCacheTTL is how long device and link counts are reused
+const entityCountCacheTTL = 30 * time.Second
+
+// EntityCountResponse is the response for the device and link count endpoints
+type EntityCountResponse struct {
+	Total    uint64            `json:"total"`
+	ByStatus map[string]uint64 `json:"byStatus"`
+}
+
+type entityCountCacheKey struct {
+	table string
+	env   DZEnv
+}
+
+type entityCountCacheEntry struct {
+	response  EntityCountResponse
+	fetchedAt time.Time
+}
+
+var (
+	entityCountCache   = make(map[entityCountCacheKey]entityCountCacheEntry)
+	entityCountCacheMu sync.RWMutex
+)
+
+// GetDeviceCount returns the number of devices by status, for dashboard KPIs
+// that don't need the device list
+func GetDeviceCount(w http.ResponseWriter, r *http.Request) {
+	writeEntityCount(w, r, "dz_devices_current")
+}
+
+// GetLinkCount returns the number of links by status, for dashboard KPIs that
+// don't need the link list
+func GetLinkCount(w http.ResponseWriter, r *http.Request) {
+	writeEntityCount(w, r, "dz_links_current")
+}
+
+// writeEntityCount serves the status counts of a current-state table, cached
+// per environment. table must be a constant, never user input.
+func writeEntityCount(w http.ResponseWriter, r *http.Request, table string) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	key := entityCountCacheKey{table: table, env: EnvFromContext(ctx)}
+	entityCountCacheMu.RLock()
+	entry, ok := entityCountCache[key]
+	entityCountCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < entityCountCacheTTL {
+		w.Header().Set("X-Cache", "HIT")
+		writeJSON(w, entry.response)
+		return
+	}
+
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `SELECT status, count(*) FROM `+table+` GROUP BY status`)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Entity count query error", "table", table, "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	response := EntityCountResponse{ByStatus: make(map[string]uint64)}
+	for rows.Next() {
+		var status string
+		var count uint64
+		if err := rows.Scan(&status, &count); err != nil {
+			LoggerFromContext(ctx).Error("Entity count row scan error", "table", table, "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		response.ByStatus[status] = count
+		response.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Entity count rows error", "table", table, "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	entityCountCacheMu.Lock()
+	entityCountCache[key] = entityCountCacheEntry{response: response, fetchedAt: time.Now()}
+	entityCountCacheMu.Unlock()
+
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, response)
+}
@@ -0,0 +1,60 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getEntityCount(t *testing.T, handler http.HandlerFunc, path string) (handlers.EntityCountResponse, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.EntityCountResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return resp, rr.Header().Get("X-Cache")
+}
+
+func TestGetEntityCounts(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-1', now(), now(), generateUUIDv4(), 0, 1, 'dev-1', 'activated', 'hybrid', 'AMS-01', '', '', '', 0),
+		('dev-2', now(), now(), generateUUIDv4(), 0, 1, 'dev-2', 'activated', 'hybrid', 'AMS-02', '', '', '', 0),
+		('dev-3', now(), now(), generateUUIDv4(), 0, 1, 'dev-3', 'drained', 'edge', 'NYC-01', '', '', '', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now(), now(), generateUUIDv4(), 0, 1, 'link-1', 'activated', 'LINK-1', '', '', 'dev-1', 'dev-2', '', '', 'WAN', 0, 0, 0, 0),
+		('link-2', now(), now(), generateUUIDv4(), 0, 1, 'link-2', 'pending', 'LINK-2', '', '', 'dev-2', 'dev-3', '', '', 'WAN', 0, 0, 0, 0)`))
+
+	devices, cache := getEntityCount(t, handlers.GetDeviceCount, "/api/dz/devices/count")
+	assert.Equal(t, "MISS", cache)
+	assert.Equal(t, uint64(3), devices.Total)
+	assert.Equal(t, map[string]uint64{"activated": 2, "drained": 1}, devices.ByStatus)
+
+	links, _ := getEntityCount(t, handlers.GetLinkCount, "/api/dz/links/count")
+	assert.Equal(t, uint64(2), links.Total)
+	assert.Equal(t, map[string]uint64{"activated": 1, "pending": 1}, links.ByStatus)
+
+	// A second request within the TTL is served from the cache
+	_, cache = getEntityCount(t, handlers.GetDeviceCount, "/api/dz/devices/count")
+	assert.Equal(t, "HIT", cache)
+}
@@ -86,9 +86,7 @@ func isMainnet(ctx context.Context) bool {
 func RequireNeo4jMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !isMainnet(r.Context()) || config.Neo4jClient == nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte(`{"error":"This feature is only available on mainnet-beta"}`))
+			writeError(w, r, http.StatusServiceUnavailable, ErrCodeNeo4jUnavailable, "This feature is only available on mainnet-beta")
 			return
 		}
 		next.ServeHTTP(w, r)
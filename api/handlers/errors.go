@@ -1,12 +1,71 @@
 package handlers
 
 import (
+	"encoding/json"
 	"log/slog"
+	"net/http"
 	"strings"
 
 	"github.com/getsentry/sentry-go"
+	"github.com/malbeclabs/lake/api/handlers/dberror"
 )
 
+// Machine-readable error codes returned in ErrorResponse.Code.
+// Database errors use the dberror codes (ERR_DB_*).
+const (
+	ErrCodeBadRequest       = "ERR_BAD_REQUEST"
+	ErrCodeUnauthorized     = "ERR_UNAUTHORIZED"
+	ErrCodeForbidden        = "ERR_FORBIDDEN"
+	ErrCodeNotFound         = "ERR_NOT_FOUND"
+	ErrCodeConflict         = "ERR_CONFLICT"
+	ErrCodeRateLimited      = "ERR_RATE_LIMITED"
+	ErrCodeQuotaExceeded    = "ERR_QUOTA_EXCEEDED"
+	ErrCodeInternal         = "ERR_INTERNAL"
+	ErrCodeUpstream         = "ERR_UPSTREAM"
+	ErrCodeUnavailable      = "ERR_UNAVAILABLE"
+	ErrCodeNeo4jUnavailable = "ERR_NEO4J_UNAVAILABLE"
+
+	ErrCodeContributorNotFound    = "ERR_CONTRIBUTOR_NOT_FOUND"
+	ErrCodeDeviceNotFound         = "ERR_DEVICE_NOT_FOUND"
+	ErrCodeGossipNodeNotFound     = "ERR_GOSSIP_NODE_NOT_FOUND"
+	ErrCodeInstallationNotFound   = "ERR_INSTALLATION_NOT_FOUND"
+	ErrCodeLinkNotFound           = "ERR_LINK_NOT_FOUND"
+	ErrCodeMetroNotFound          = "ERR_METRO_NOT_FOUND"
+	ErrCodeMulticastGroupNotFound = "ERR_MULTICAST_GROUP_NOT_FOUND"
+	ErrCodeSessionNotFound        = "ERR_SESSION_NOT_FOUND"
+	ErrCodeUserNotFound           = "ERR_USER_NOT_FOUND"
+	ErrCodeValidatorNotFound      = "ERR_VALIDATOR_NOT_FOUND"
+	ErrCodeWorkflowNotFound       = "ERR_WORKFLOW_NOT_FOUND"
+)
+
+// ErrorResponse is the body of every non-2xx JSON error response
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// writeError writes an ErrorResponse with the given status, tagged with the
+// request ID so clients can correlate failures with server logs.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: GetRequestIDFromContext(r.Context()),
+	})
+}
+
+// writeDBError writes a 500 ErrorResponse for a database error, using the
+// dberror classification for the code and user-facing message.
+func writeDBError(w http.ResponseWriter, r *http.Request, err error) {
+	dbErr := dberror.UserError(err)
+	LoggerFromContext(r.Context()).Error("database error", "code", dbErr.Code, "error", err)
+	writeError(w, r, http.StatusInternalServerError, dbErr.Code, dbErr.Message)
+}
+
 // internalError logs the full error internally and returns a user-safe message.
 // The returned message does not contain sensitive information like credentials,
 // hostnames, or query details.
@@ -1,11 +1,15 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/malbeclabs/lake/api/handlers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSanitizeError_NilError(t *testing.T) {
@@ -115,3 +119,40 @@ func TestSanitizeError_MultipleURLs(t *testing.T) {
 	// First URL should have credentials removed
 	assert.Contains(t, result, "https://***@a.com")
 }
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var ctxID string
+	handler := handlers.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID = handlers.GetRequestIDFromContext(r.Context())
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	first := rr.Header().Get("X-Request-ID")
+	assert.Len(t, first, 32)
+	assert.Equal(t, first, ctxID)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEqual(t, first, rr.Header().Get("X-Request-ID"), "each request should get a new ID")
+}
+
+func TestErrorResponse_IncludesCodeAndRequestID(t *testing.T) {
+	handler := handlers.RequestIDMiddleware(handlers.RequireNeo4jMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(handlers.ContextWithEnv(req.Context(), handlers.EnvDevnet))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var resp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, handlers.ErrCodeNeo4jUnavailable, resp.Code)
+	assert.NotEmpty(t, resp.Message)
+	assert.Equal(t, rr.Header().Get("X-Request-ID"), resp.RequestID)
+}
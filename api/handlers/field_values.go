@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -208,7 +207,7 @@ func GetFieldValues(w http.ResponseWriter, r *http.Request) {
 	field := r.URL.Query().Get("field")
 
 	if entity == "" || field == "" {
-		http.Error(w, "entity and field parameters are required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "entity and field parameters are required")
 		return
 	}
 
@@ -245,8 +244,8 @@ func GetFieldValues(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Field values query error: %v\nQuery: %s", err, query)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Field values query error", "error", err, "query", query)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -255,16 +254,16 @@ func GetFieldValues(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var val string
 		if err := rows.Scan(&val); err != nil {
-			log.Printf("Field values scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Field values scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		values = append(values, val)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Field values rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Field values rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -274,6 +273,6 @@ func GetFieldValues(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(FieldValuesResponse{Values: values}); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
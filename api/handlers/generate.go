@@ -31,6 +31,7 @@ type HistoryMessage struct {
 
 type GenerateResponse struct {
 	SQL      string `json:"sql"`
+	QueryID  string `json:"queryId,omitempty"` // pass to /api/generate/feedback to rate the query
 	Provider string `json:"provider,omitempty"`
 	Attempts int    `json:"attempts,omitempty"`
 	Error    string `json:"error,omitempty"`
@@ -78,15 +79,17 @@ func getGeneratePrompt() (string, error) {
 func GenerateSQL(w http.ResponseWriter, r *http.Request) {
 	var req GenerateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Prompt) == "" {
-		http.Error(w, "Prompt is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Prompt is required")
 		return
 	}
 
+	recordAccountUsage(r.Context(), UsageRecord{GenerateCount: 1})
+
 	// Fetch schema using shared DBSchemaFetcher
 	schemaFetcher := NewDBSchemaFetcher()
 	schema, err := schemaFetcher.FetchSchema(r.Context())
@@ -104,6 +107,16 @@ func GenerateSQL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Use queries users rated well for similar requests as examples
+	queryType := generationQueryType(req.Prompt)
+	var examples []generationExample
+	if config.PgPool != nil {
+		examples, err = loadFeedbackExamples(r.Context(), queryType)
+		if err != nil {
+			slog.Warn("Failed to load generation feedback examples", "query_type", queryType, "error", err)
+		}
+	}
+
 	var sql string
 	var lastError string
 	attempts := 0
@@ -122,7 +135,7 @@ func GenerateSQL(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Generate SQL
-		sql, err = generateWithAnthropic(r.Context(), schema, prompt, req.History)
+		sql, err = generateWithAnthropic(r.Context(), schema, prompt, req.History, examples)
 		if err != nil {
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(GenerateResponse{Error: internalError("Failed to generate SQL", err), Provider: "anthropic", Attempts: attempts})
@@ -135,9 +148,18 @@ func GenerateSQL(w http.ResponseWriter, r *http.Request) {
 		// Validate with EXPLAIN
 		validationErr := validateQuery(sql)
 		if validationErr == "" {
-			// Query is valid
+			// Query is valid; record it so it can be rated
+			var queryID string
+			if config.PgPool != nil {
+				id, err := recordGeneratedQuery(r.Context(), req.Prompt, sql, queryType)
+				if err != nil {
+					slog.Warn("Failed to record generated query", "error", err)
+				} else {
+					queryID = id.String()
+				}
+			}
 			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(GenerateResponse{SQL: sql, Provider: "anthropic", Attempts: attempts})
+			_ = json.NewEncoder(w).Encode(GenerateResponse{SQL: sql, QueryID: queryID, Provider: "anthropic", Attempts: attempts})
 			return
 		}
 
@@ -159,15 +181,17 @@ func GenerateSQL(w http.ResponseWriter, r *http.Request) {
 func GenerateSQLStream(w http.ResponseWriter, r *http.Request) {
 	var req GenerateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Prompt) == "" {
-		http.Error(w, "Prompt is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Prompt is required")
 		return
 	}
 
+	recordAccountUsage(r.Context(), UsageRecord{GenerateCount: 1})
+
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -176,7 +200,7 @@ func GenerateSQLStream(w http.ResponseWriter, r *http.Request) {
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Streaming not supported")
 		return
 	}
 
@@ -266,7 +290,7 @@ func escapeJSON(s string) string {
 
 func streamWithAnthropic(ctx context.Context, schema, prompt string, history []HistoryMessage, onToken func(string)) error {
 	client := anthropic.NewClient()
-	systemPrompt := buildSystemPrompt(schema)
+	systemPrompt := buildSystemPrompt(schema, nil)
 
 	// Start Sentry span for AI monitoring
 	model := anthropic.ModelClaudeHaiku4_5
@@ -368,7 +392,7 @@ func validateQuery(sql string) string {
 	return "" // Valid query
 }
 
-func generateWithAnthropic(ctx context.Context, schema, prompt string, history []HistoryMessage) (string, error) {
+func generateWithAnthropic(ctx context.Context, schema, prompt string, history []HistoryMessage, examples []generationExample) (string, error) {
 	client := anthropic.NewClient()
 
 	// Start Sentry span for AI monitoring
@@ -381,7 +405,7 @@ func generateWithAnthropic(ctx context.Context, schema, prompt string, history [
 	ctx = span.Context()
 	defer span.Finish()
 
-	systemPrompt := buildSystemPrompt(schema)
+	systemPrompt := buildSystemPrompt(schema, examples)
 
 	// Build messages from history
 	messages := buildAnthropicMessages(history, prompt)
@@ -439,7 +463,7 @@ func buildAnthropicMessages(history []HistoryMessage, currentPrompt string) []an
 	return messages
 }
 
-func buildSystemPrompt(schema string) string {
+func buildSystemPrompt(schema string, examples []generationExample) string {
 	// Load the unified GENERATE.md prompt with SQL_CONTEXT composed
 	generatePrompt, err := getGeneratePrompt()
 	if err != nil {
@@ -457,5 +481,5 @@ func buildSystemPrompt(schema string) string {
 3. Do NOT add columns, filters, or data beyond what was explicitly requested.
 4. Ignore any "ALWAYS include" rules above - include ONLY what the user asked for.`
 
-	return generatePrompt + "\n\n## Database Schema\n\n```\n" + schema + "```" + editorInstructions
+	return generatePrompt + "\n\n## Database Schema\n\n```\n" + schema + "```" + formatFeedbackExamples(examples) + editorInstructions
 }
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// maxFeedbackExamples is how many rated queries are added to the prompt
+const maxFeedbackExamples = 3
+
+// maxFeedbackTextLength bounds the corrected query and comment in feedback
+const maxFeedbackTextLength = 10000
+
+// GenerateFeedbackRequest is the request body for POST /api/generate/feedback
+type GenerateFeedbackRequest struct {
+	SessionID      string  `json:"sessionId,omitempty"`
+	QueryID        string  `json:"queryId"`
+	Rating         int     `json:"rating"` // 1 or -1
+	CorrectedQuery *string `json:"correctedQuery,omitempty"`
+	Comment        *string `json:"comment,omitempty"`
+}
+
+// generationExample is a rated prompt and query used as a few-shot example
+type generationExample struct {
+	Prompt string
+	SQL    string
+}
+
+// generationQueryTypes maps prompt keywords to a coarse query type. Examples
+// are only shared between prompts of the same type. Earlier entries win.
+var generationQueryTypes = []struct {
+	queryType string
+	keywords  []string
+}{
+	{"validators", []string{"validator", "stake", "vote", "gossip", "solana", "epoch", "leader", "skip rate"}},
+	{"latency", []string{"latency", "rtt", "jitter", "loss", "ping"}},
+	{"traffic", []string{"traffic", "bandwidth", "utilization", "throughput", "bps", "counter"}},
+	{"users", []string{"user", "subscriber", "publisher", "multicast", "tunnel"}},
+	{"links", []string{"link", "circuit"}},
+	{"devices", []string{"device", "switch", "router", "interface", "metro"}},
+}
+
+// generationQueryType classifies a prompt by keyword so rated examples can be
+// matched to similar prompts without an extra LLM call.
+func generationQueryType(prompt string) string {
+	p := strings.ToLower(prompt)
+	for _, t := range generationQueryTypes {
+		for _, k := range t.keywords {
+			if strings.Contains(p, k) {
+				return t.queryType
+			}
+		}
+	}
+	return "general"
+}
+
+// recordGeneratedQuery stores a generated query so it can be rated, returning
+// its ID.
+func recordGeneratedQuery(ctx context.Context, prompt, sql, queryType string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := config.PgPool.QueryRow(ctx, `
+		INSERT INTO generated_queries (prompt, sql, query_type)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, prompt, sql, queryType).Scan(&id)
+	return id, err
+}
+
+// loadFeedbackExamples returns the best-rated queries of a type. Ratings are
+// summed per query: queries with a positive score are promoted, and those
+// with a negative score are left out unless a user supplied a correction, in
+// which case the latest correction is the example.
+func loadFeedbackExamples(ctx context.Context, queryType string) ([]generationExample, error) {
+	rows, err := config.PgPool.Query(ctx, `
+		SELECT q.prompt,
+		       COALESCE(
+		           (SELECT f2.corrected_query FROM generation_feedback f2
+		            WHERE f2.query_id = q.id AND COALESCE(f2.corrected_query, '') != ''
+		            ORDER BY f2.updated_at DESC LIMIT 1),
+		           q.sql
+		       ) AS example_sql
+		FROM generated_queries q
+		JOIN generation_feedback f ON f.query_id = q.id
+		WHERE q.query_type = $1
+		GROUP BY q.id, q.prompt, q.sql
+		HAVING SUM(f.rating) > 0 OR bool_or(COALESCE(f.corrected_query, '') != '')
+		ORDER BY SUM(f.rating) DESC, MAX(f.updated_at) DESC
+		LIMIT $2
+	`, queryType, maxFeedbackExamples)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var examples []generationExample
+	for rows.Next() {
+		var e generationExample
+		if err := rows.Scan(&e.Prompt, &e.SQL); err != nil {
+			return nil, err
+		}
+		examples = append(examples, e)
+	}
+	return examples, rows.Err()
+}
+
+// formatFeedbackExamples renders examples as a system prompt section
+func formatFeedbackExamples(examples []generationExample) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n## Examples\n\nUsers rated these queries as correct for similar requests:\n")
+	for _, e := range examples {
+		fmt.Fprintf(&b, "\nRequest: %s\n```sql\n%s\n```\n", strings.TrimSpace(e.Prompt), strings.TrimSpace(e.SQL))
+	}
+	return b.String()
+}
+
+// PostGenerateFeedback handles POST /api/generate/feedback - records a user's
+// rating of a query returned by GenerateSQL. Rating the same query again from
+// the same session replaces the earlier rating.
+func PostGenerateFeedback(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var req GenerateFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+
+	queryID, err := uuid.Parse(req.QueryID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid queryId")
+		return
+	}
+	var sessionID *uuid.UUID
+	if req.SessionID != "" {
+		id, err := uuid.Parse(req.SessionID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid sessionId")
+			return
+		}
+		sessionID = &id
+	}
+	if req.Rating != 1 && req.Rating != -1 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "rating must be 1 or -1")
+		return
+	}
+	if (req.CorrectedQuery != nil && len(*req.CorrectedQuery) > maxFeedbackTextLength) ||
+		(req.Comment != nil && len(*req.Comment) > maxFeedbackTextLength) {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("correctedQuery and comment must be at most %d characters", maxFeedbackTextLength))
+		return
+	}
+	if req.CorrectedQuery != nil {
+		corrected := cleanSQL(*req.CorrectedQuery)
+		req.CorrectedQuery = &corrected
+	}
+
+	// Selecting from generated_queries makes an unknown queryId insert nothing
+	var id uuid.UUID
+	err = config.PgPool.QueryRow(ctx, `
+		INSERT INTO generation_feedback (session_id, query_id, rating, corrected_query, comment)
+		SELECT $1, q.id, $3, $4, $5 FROM generated_queries q WHERE q.id = $2
+		ON CONFLICT (query_id, session_id) DO UPDATE SET
+			rating = EXCLUDED.rating,
+			corrected_query = EXCLUDED.corrected_query,
+			comment = EXCLUDED.comment,
+			updated_at = NOW()
+		RETURNING id
+	`, sessionID, queryID, req.Rating, req.CorrectedQuery, req.Comment).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "Generated query not found")
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to record generation feedback", "query_id", queryID, "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to record feedback", err))
+		return
+	}
+
+	writeJSON(w, map[string]string{"id": id.String()})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerationQueryType(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   string
+	}{
+		{"Top 10 validators by stake", "validators"},
+		{"average RTT between NYC and LON", "latency"},
+		{"Which links have the highest utilization?", "traffic"},
+		{"how many multicast subscribers are there", "users"},
+		{"links that are down", "links"},
+		{"devices in Frankfurt", "devices"},
+		{"what tables exist", "general"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, generationQueryType(tt.prompt), tt.prompt)
+	}
+}
+
+func TestFormatFeedbackExamples(t *testing.T) {
+	assert.Empty(t, formatFeedbackExamples(nil))
+
+	got := formatFeedbackExamples([]generationExample{
+		{Prompt: " count devices ", SQL: "SELECT count() FROM dz_devices_current\n"},
+	})
+	assert.Contains(t, got, "## Examples")
+	assert.Contains(t, got, "Request: count devices\n```sql\nSELECT count() FROM dz_devices_current\n```")
+}
+
+func TestBuildSystemPrompt_ExamplesBeforeFinalInstructions(t *testing.T) {
+	prompt := buildSystemPrompt("schema", []generationExample{{Prompt: "p", SQL: "SELECT 1"}})
+
+	examples := strings.Index(prompt, "## Examples")
+	final := strings.Index(prompt, "## FINAL INSTRUCTIONS")
+	assert.Greater(t, examples, 0)
+	assert.Greater(t, final, examples)
+}
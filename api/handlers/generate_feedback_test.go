@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertGeneratedQuery(t *testing.T) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	err := config.PgPool.QueryRow(t.Context(), `
+		INSERT INTO generated_queries (prompt, sql, query_type)
+		VALUES ('count devices', 'SELECT count() FROM dz_devices_current', 'devices')
+		RETURNING id
+	`).Scan(&id)
+	require.NoError(t, err)
+	return id
+}
+
+func postGenerateFeedback(body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/feedback", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handlers.PostGenerateFeedback(rr, req)
+	return rr
+}
+
+func TestPostGenerateFeedback(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	queryID := insertGeneratedQuery(t)
+	sessionID := uuid.New()
+
+	rr := postGenerateFeedback(`{"sessionId":"` + sessionID.String() + `","queryId":"` + queryID.String() + `","rating":-1,"correctedQuery":"SELECT count() FROM dz_devices_current WHERE status = 'activated';","comment":"only active"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var rating int
+	var corrected, comment string
+	err := config.PgPool.QueryRow(t.Context(), `
+		SELECT rating, corrected_query, comment FROM generation_feedback WHERE query_id = $1
+	`, queryID).Scan(&rating, &corrected, &comment)
+	require.NoError(t, err)
+	assert.Equal(t, -1, rating)
+	assert.Equal(t, "SELECT count() FROM dz_devices_current WHERE status = 'activated'", corrected)
+	assert.Equal(t, "only active", comment)
+
+	// Rating again from the same session replaces the earlier rating
+	rr = postGenerateFeedback(`{"sessionId":"` + sessionID.String() + `","queryId":"` + queryID.String() + `","rating":1}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var count int
+	err = config.PgPool.QueryRow(t.Context(), `
+		SELECT COUNT(*), MAX(rating) FROM generation_feedback WHERE query_id = $1
+	`, queryID).Scan(&count, &rating)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, rating)
+}
+
+func TestPostGenerateFeedback_Invalid(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	queryID := insertGeneratedQuery(t)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"bad json", `{`, http.StatusBadRequest},
+		{"bad query id", `{"queryId":"nope","rating":1}`, http.StatusBadRequest},
+		{"bad session id", `{"sessionId":"nope","queryId":"` + queryID.String() + `","rating":1}`, http.StatusBadRequest},
+		{"bad rating", `{"queryId":"` + queryID.String() + `","rating":2}`, http.StatusBadRequest},
+		{"unknown query", `{"queryId":"` + uuid.New().String() + `","rating":1}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, postGenerateFeedback(tt.body).Code)
+		})
+	}
+}
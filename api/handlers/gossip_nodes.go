@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"time"
 
@@ -119,8 +118,8 @@ func GetGossipNodes(w http.ResponseWriter, r *http.Request) {
 	countQuery := baseQuery + `SELECT count(*) FROM gossip_data WHERE 1=1` + whereFilter
 	var total uint64
 	if err := envDB(ctx).QueryRow(ctx, countQuery, filterArgs...).Scan(&total); err != nil {
-		log.Printf("GossipNodes count error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("GossipNodes count error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -128,7 +127,7 @@ func GetGossipNodes(w http.ResponseWriter, r *http.Request) {
 	onDZCountQuery := baseQuery + `SELECT count(*) FROM gossip_data WHERE on_dz = true` + whereFilter
 	var onDZCount uint64
 	if err := envDB(ctx).QueryRow(ctx, onDZCountQuery, filterArgs...).Scan(&onDZCount); err != nil {
-		log.Printf("GossipNodes on_dz count error: %v", err)
+		LoggerFromContext(ctx).Error("GossipNodes on_dz count error", "error", err)
 		onDZCount = 0
 	}
 
@@ -136,7 +135,7 @@ func GetGossipNodes(w http.ResponseWriter, r *http.Request) {
 	validatorCountQuery := baseQuery + `SELECT count(*) FROM gossip_data WHERE is_validator = true` + whereFilter
 	var validatorCount uint64
 	if err := envDB(ctx).QueryRow(ctx, validatorCountQuery, filterArgs...).Scan(&validatorCount); err != nil {
-		log.Printf("GossipNodes validator count error: %v", err)
+		LoggerFromContext(ctx).Error("GossipNodes validator count error", "error", err)
 		validatorCount = 0
 	}
 
@@ -156,8 +155,8 @@ func GetGossipNodes(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("GossipNodes query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("GossipNodes query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -178,16 +177,16 @@ func GetGossipNodes(w http.ResponseWriter, r *http.Request) {
 			&n.StakeSol,
 			&n.IsValidator,
 		); err != nil {
-			log.Printf("GossipNodes scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("GossipNodes scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		nodes = append(nodes, n)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("GossipNodes rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("GossipNodes rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -207,7 +206,7 @@ func GetGossipNodes(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -236,7 +235,7 @@ func GetGossipNode(w http.ResponseWriter, r *http.Request) {
 
 	pubkey := chi.URLParam(r, "pubkey")
 	if pubkey == "" {
-		http.Error(w, "missing pubkey", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing pubkey")
 		return
 	}
 
@@ -332,13 +331,125 @@ func GetGossipNode(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("GossipNode query error: %v", err)
-		http.Error(w, "gossip node not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("GossipNode query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeGossipNodeNotFound, "gossip node not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(node); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
+	}
+}
+
+// GossipNodeMapItem is a gossip node placed on the map by its gossip IP.
+// Latitude and longitude are nil when the IP has no GeoIP record.
+type GossipNodeMapItem struct {
+	Pubkey      string   `json:"pubkey"`
+	IP          string   `json:"ip"`
+	ASN         int64    `json:"asn"`
+	CountryCode string   `json:"countryCode"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+	IsOnDZ      bool     `json:"isOnDZ"`
+	DevicePK    string   `json:"devicePK"`
+	MetroPK     string   `json:"metroPK"`
+}
+
+type GossipNodeMapResponse struct {
+	Nodes []GossipNodeMapItem `json:"nodes"`
+}
+
+// GetGossipNodeMap returns the location of every known gossip node. GeoIP data
+// comes from the records the indexer resolves, not from a lookup per request.
+// ?on_dz_only=true limits the result to nodes connected to DZ.
+func GetGossipNodeMap(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	onDZFilter := ""
+	if r.URL.Query().Get("on_dz_only") == "true" {
+		onDZFilter = "WHERE dz.dz_ip != ''"
+	}
+
+	start := time.Now()
+	query := `
+		WITH dz_nodes AS (
+			SELECT
+				u.dz_ip,
+				u.device_pk,
+				d.metro_pk
+			FROM dz_users_current u
+			JOIN dz_devices_current d ON u.device_pk = d.pk
+			WHERE u.status = 'activated'
+				AND u.dz_ip IS NOT NULL
+				AND u.dz_ip != ''
+		)
+		SELECT
+			g.pubkey,
+			COALESCE(g.gossip_ip, '') as gossip_ip,
+			COALESCE(geo.asn, 0) as asn,
+			COALESCE(geo.country_code, '') as country_code,
+			COALESCE(geo.ip, '') != '' as has_geo,
+			COALESCE(geo.latitude, 0) as latitude,
+			COALESCE(geo.longitude, 0) as longitude,
+			dz.dz_ip != '' as on_dz,
+			COALESCE(dz.device_pk, '') as device_pk,
+			COALESCE(dz.metro_pk, '') as metro_pk
+		FROM solana_gossip_nodes_current g
+		LEFT JOIN geoip_records_current geo ON g.gossip_ip = geo.ip
+		LEFT JOIN dz_nodes dz ON g.gossip_ip = dz.dz_ip
+		` + onDZFilter + `
+		ORDER BY g.pubkey
+	`
+
+	rows, err := envDB(ctx).Query(ctx, query)
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+
+	if err != nil {
+		LoggerFromContext(ctx).Error("GossipNodeMap query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	nodes := []GossipNodeMapItem{}
+	for rows.Next() {
+		var n GossipNodeMapItem
+		var hasGeo bool
+		var lat, lng float64
+		if err := rows.Scan(
+			&n.Pubkey,
+			&n.IP,
+			&n.ASN,
+			&n.CountryCode,
+			&hasGeo,
+			&lat,
+			&lng,
+			&n.IsOnDZ,
+			&n.DevicePK,
+			&n.MetroPK,
+		); err != nil {
+			LoggerFromContext(ctx).Error("GossipNodeMap scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		if hasGeo {
+			n.Latitude = &lat
+			n.Longitude = &lng
+		}
+		nodes = append(nodes, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("GossipNodeMap rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(GossipNodeMapResponse{Nodes: nodes}); err != nil {
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedGossipNodeMapData inserts one gossip node connected to DZ and one off DZ
+// without a GeoIP record.
+func seedGossipNodeMapData(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_solana_gossip_nodes_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pubkey, epoch, gossip_ip, gossip_port, tpuquic_ip, tpuquic_port, version)
+		VALUES
+		('node-dz', now(), now(), generateUUIDv4(), 0, 1, 'node-dz', 100, '10.0.0.1', 8001, '', 0, '2.0.0'),
+		('node-off', now(), now(), generateUUIDv4(), 0, 2, 'node-off', 100, '5.6.7.8', 8001, '', 0, '2.0.0')`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-ams1', now(), now(), generateUUIDv4(), 0, 1, 'dev-ams1', 'up', 'edge', 'ams001-dz001', '', '', 'metro-ams', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_users_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, owner_pubkey, status, kind, client_ip, dz_ip, device_pk, tunnel_id)
+		VALUES
+		('user-1', now(), now(), generateUUIDv4(), 0, 1, 'user-1', '', 'activated', 'ibrl', '10.0.0.1', '10.0.0.1', 'dev-ams1', 501)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_geoip_records_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 ip, asn, asn_org, country_code, country, latitude, longitude)
+		VALUES
+		('10.0.0.1', now(), now(), generateUUIDv4(), 0, 1, '10.0.0.1', 12345, 'TestASN', 'NL', 'Netherlands', 52.37, 4.89)`))
+}
+
+func getGossipNodeMap(t *testing.T, url string) handlers.GossipNodeMapResponse {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	handlers.GetGossipNodeMap(rr, httptest.NewRequest(http.MethodGet, url, nil))
+	require.Equal(t, http.StatusOK, rr.Code, "body: %s", rr.Body.String())
+
+	var resp handlers.GossipNodeMapResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return resp
+}
+
+func TestGetGossipNodeMap(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedGossipNodeMapData(t)
+
+	resp := getGossipNodeMap(t, "/api/solana/gossip-nodes/map")
+	require.Len(t, resp.Nodes, 2)
+
+	dz := resp.Nodes[0]
+	assert.Equal(t, "node-dz", dz.Pubkey)
+	assert.Equal(t, "10.0.0.1", dz.IP)
+	assert.Equal(t, int64(12345), dz.ASN)
+	assert.Equal(t, "NL", dz.CountryCode)
+	require.NotNil(t, dz.Latitude)
+	assert.InDelta(t, 52.37, *dz.Latitude, 1e-9)
+	assert.True(t, dz.IsOnDZ)
+	assert.Equal(t, "dev-ams1", dz.DevicePK)
+	assert.Equal(t, "metro-ams", dz.MetroPK)
+
+	off := resp.Nodes[1]
+	assert.Equal(t, "node-off", off.Pubkey)
+	assert.False(t, off.IsOnDZ)
+	assert.Nil(t, off.Latitude, "no GeoIP record")
+	assert.Empty(t, off.DevicePK)
+}
+
+func TestGetGossipNodeMap_OnDZOnly(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedGossipNodeMapData(t)
+
+	resp := getGossipNodeMap(t, "/api/solana/gossip-nodes/map?on_dz_only=true")
+	require.Len(t, resp.Nodes, 1)
+	assert.Equal(t, "node-dz", resp.Nodes[0].Pubkey)
+}
+
+func TestGetGossipNodeMap_Empty(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	resp := getGossipNodeMap(t, "/api/solana/gossip-nodes/map")
+	assert.NotNil(t, resp.Nodes)
+	assert.Empty(t, resp.Nodes)
+}
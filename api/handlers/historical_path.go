@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+	neo4jdriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// historicalPathLookback is how far before ?at= a router's last LSP snapshot can
+// be for it to still count as part of the topology
+const historicalPathLookback = 24 * time.Hour
+
+// HistoricalPathResponse is a GetISISPath response for the topology at a past
+// time. ReconstructedAt is the newest LSDB snapshot the graph was built from.
+type HistoricalPathResponse struct {
+	PathResponse
+	ReconstructedAt *time.Time `json:"reconstructedAt,omitempty"`
+}
+
+// lsdbAdjacency is a neighbor in a router's LSP, from fact_isis_lsdb_history
+type lsdbAdjacency struct {
+	SystemID         string
+	NeighborSystemID string
+	Metric           uint32
+}
+
+// GetHistoricalPath finds the lowest-metric path between two devices as the
+// ISIS topology stood at ?at= (RFC3339). The graph is rebuilt from each
+// router's latest LSP snapshot at or before that time. Devices are matched by
+// their current ISIS system ID; routers no longer in the graph are reported by
+// system ID.
+func GetHistoricalPath(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	fromPK := r.URL.Query().Get("from")
+	toPK := r.URL.Query().Get("to")
+	at, err := time.Parse(time.RFC3339, r.URL.Query().Get("at"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "at must be an RFC3339 timestamp")
+		return
+	}
+
+	var response HistoricalPathResponse
+	switch {
+	case fromPK == "" || toPK == "":
+		response.Error = "from and to parameters are required"
+		writeJSON(w, response)
+		return
+	case fromPK == toPK:
+		response.Error = "from and to must be different devices"
+		writeJSON(w, response)
+		return
+	}
+
+	start := time.Now()
+	devices, err := loadISISDeviceHops(ctx)
+	metrics.RecordNeo4jQuery("historical_path", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Historical path device query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	var fromSystemID, toSystemID string
+	for systemID, hop := range devices {
+		switch hop.DevicePK {
+		case fromPK:
+			fromSystemID = systemID
+		case toPK:
+			toSystemID = systemID
+		}
+	}
+	if fromSystemID == "" || toSystemID == "" {
+		response.Error = "Device has no ISIS system ID"
+		writeJSON(w, response)
+		return
+	}
+
+	adjacencies, reconstructedAt, err := loadLSDBAdjacencies(ctx, at)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Historical path LSDB query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	if reconstructedAt.IsZero() {
+		response.Error = "No ISIS topology recorded before this time"
+		writeJSON(w, response)
+		return
+	}
+
+	response.PathResponse = historicalShortestPath(adjacencies, devices, fromSystemID, toSystemID)
+	response.ReconstructedAt = &reconstructedAt
+	writeJSON(w, response)
+}
+
+// loadISISDeviceHops returns path hop details for ISIS devices by system ID
+func loadISISDeviceHops(ctx context.Context) (map[string]PathHop, error) {
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (d:Device)
+		WHERE d.isis_system_id IS NOT NULL
+		OPTIONAL MATCH (d)-[:LOCATED_IN]->(m:Metro)
+		RETURN d.pk AS pk,
+		       d.code AS code,
+		       d.status AS status,
+		       d.device_type AS device_type,
+		       d.isis_system_id AS system_id,
+		       m.code AS metro_code
+	`, nil)
+	var records []*neo4jdriver.Record
+	if err == nil {
+		records, err = result.Collect(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make(map[string]PathHop, len(records))
+	for _, record := range records {
+		pk, _ := record.Get("pk")
+		code, _ := record.Get("code")
+		status, _ := record.Get("status")
+		deviceType, _ := record.Get("device_type")
+		systemID, _ := record.Get("system_id")
+		metroCode, _ := record.Get("metro_code")
+		devices[asString(systemID)] = PathHop{
+			DevicePK:   asString(pk),
+			DeviceCode: asString(code),
+			Status:     asString(status),
+			DeviceType: asString(deviceType),
+			MetroCode:  asString(metroCode),
+		}
+	}
+	return devices, nil
+}
+
+// loadLSDBAdjacencies returns the neighbors in each router's latest LSP snapshot
+// at or before at, and the time of the newest snapshot used (zero if none).
+// Only a router's latest snapshot is used so adjacencies it had dropped by then
+// aren't included; LIMIT BY collapses reprocessed copies of a snapshot.
+func loadLSDBAdjacencies(ctx context.Context, at time.Time) ([]lsdbAdjacency, time.Time, error) {
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH latest AS (
+			SELECT system_id, max(epoch_ts) AS epoch_ts
+			FROM fact_isis_lsdb_history
+			WHERE epoch_ts <= ? AND epoch_ts > ?
+			GROUP BY system_id
+		)
+		SELECT system_id, neighbor_system_id, metric, epoch_ts
+		FROM fact_isis_lsdb_history
+		WHERE (system_id, epoch_ts) IN (SELECT system_id, epoch_ts FROM latest)
+		ORDER BY epoch_ts DESC, sequence_number DESC
+		LIMIT 1 BY (system_id, neighbor_system_id)
+	`, at, at.Add(-historicalPathLookback))
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	var adjacencies []lsdbAdjacency
+	var reconstructedAt time.Time
+	for rows.Next() {
+		var a lsdbAdjacency
+		var epoch time.Time
+		if err := rows.Scan(&a.SystemID, &a.NeighborSystemID, &a.Metric, &epoch); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(start), err)
+			return nil, time.Time{}, err
+		}
+		if epoch.After(reconstructedAt) {
+			reconstructedAt = epoch
+		}
+		// LSPs without neighbors still count towards the snapshot time
+		if a.NeighborSystemID != "" {
+			adjacencies = append(adjacencies, a)
+		}
+	}
+	metrics.RecordClickHouseQuery(time.Since(start), rows.Err())
+	return adjacencies, reconstructedAt, rows.Err()
+}
+
+// historicalShortestPath runs Dijkstra over the LSP adjacencies, each directed
+// from the advertising router to its neighbor
+func historicalShortestPath(adjacencies []lsdbAdjacency, devices map[string]PathHop, fromSystemID, toSystemID string) PathResponse {
+	var systemIDs []string
+	index := make(map[string]int)
+	node := func(systemID string) int {
+		i, ok := index[systemID]
+		if !ok {
+			i = len(systemIDs)
+			index[systemID] = i
+			systemIDs = append(systemIDs, systemID)
+		}
+		return i
+	}
+	from, to := node(fromSystemID), node(toSystemID)
+	for _, a := range adjacencies {
+		node(a.SystemID)
+		node(a.NeighborSystemID)
+	}
+
+	adj := make([][]isisGraphEdge, len(systemIDs))
+	for _, a := range adjacencies {
+		s, n := index[a.SystemID], index[a.NeighborSystemID]
+		adj[s] = append(adj[s], isisGraphEdge{to: n, weight: int64(a.Metric)})
+	}
+
+	dist, prev := multiSourceShortestPaths(adj, []int{from})
+	if dist[to] < 0 {
+		return PathResponse{Error: "No path found between devices"}
+	}
+
+	var nodes []int
+	for v := to; v != -1; v = prev[v] {
+		nodes = append(nodes, v)
+	}
+	path := make([]PathHop, 0, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		v := nodes[i]
+		hop, ok := devices[systemIDs[v]]
+		if !ok {
+			hop = PathHop{DeviceCode: systemIDs[v]}
+		}
+		if i < len(nodes)-1 {
+			hop.EdgeMetric = uint32(edgeWeight(adj, nodes[i+1], v))
+		}
+		path = append(path, hop)
+	}
+
+	return PathResponse{
+		Path:        path,
+		TotalMetric: uint32(dist[to]),
+		HopCount:    len(path) - 1,
+	}
+}
+
+// loadHistoricalMetroPath rebuilds the ISIS topology at at and returns the
+// best path between two metros with the time of the newest snapshot used
+// (zero if none). The LSDB has no link bandwidth, so hops have none.
+func loadHistoricalMetroPath(ctx context.Context, fromCode, toCode, optimize string, at time.Time) ([]MetroPathDetailHop, time.Time, error) {
+	start := time.Now()
+	devices, err := loadISISDeviceHops(ctx)
+	metrics.RecordNeo4jQuery("metro_path_detail", time.Since(start), err)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	metroCodes, err := loadMetroCodes(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	adjacencies, reconstructedAt, err := loadLSDBAdjacencies(ctx, at)
+	if err != nil || reconstructedAt.IsZero() {
+		return nil, reconstructedAt, err
+	}
+
+	metroPKs := make(map[string]string, len(metroCodes))
+	for pk, code := range metroCodes {
+		metroPKs[code] = pk
+	}
+	return historicalMetroPath(adjacencies, devices, metroPKs, fromCode, toCode, optimize), reconstructedAt, nil
+}
+
+// historicalMetroPath finds the path from any router in one metro to any
+// router in the other over the LSP adjacencies, by metric for
+// optimize=latency and by hop count otherwise. Routers no longer in the graph
+// have no metro, so they can be transited but not start or end the path.
+func historicalMetroPath(adjacencies []lsdbAdjacency, devices map[string]PathHop, metroPKs map[string]string, fromCode, toCode, optimize string) []MetroPathDetailHop {
+	var systemIDs []string
+	index := make(map[string]int)
+	node := func(systemID string) int {
+		i, ok := index[systemID]
+		if !ok {
+			i = len(systemIDs)
+			index[systemID] = i
+			systemIDs = append(systemIDs, systemID)
+		}
+		return i
+	}
+	for _, a := range adjacencies {
+		node(a.SystemID)
+		node(a.NeighborSystemID)
+	}
+
+	metricAdj := make([][]isisGraphEdge, len(systemIDs))
+	searchAdj := make([][]isisGraphEdge, len(systemIDs))
+	for _, a := range adjacencies {
+		s, n := index[a.SystemID], index[a.NeighborSystemID]
+		metricAdj[s] = append(metricAdj[s], isisGraphEdge{to: n, weight: int64(a.Metric)})
+		weight := int64(a.Metric)
+		if optimize != "latency" {
+			weight = 1
+		}
+		searchAdj[s] = append(searchAdj[s], isisGraphEdge{to: n, weight: weight})
+	}
+
+	var sources []int
+	for i, systemID := range systemIDs {
+		if devices[systemID].MetroCode == fromCode {
+			sources = append(sources, i)
+		}
+	}
+	dist, prev := multiSourceShortestPaths(searchAdj, sources)
+	best := -1
+	for i, systemID := range systemIDs {
+		if devices[systemID].MetroCode == toCode && dist[i] >= 0 && (best < 0 || dist[i] < dist[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	var nodes []int
+	for v := best; v != -1; v = prev[v] {
+		nodes = append(nodes, v)
+	}
+	hops := make([]MetroPathDetailHop, 0, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		v := nodes[i]
+		device, ok := devices[systemIDs[v]]
+		if !ok {
+			device = PathHop{DeviceCode: systemIDs[v]}
+		}
+		hop := MetroPathDetailHop{
+			DevicePK:   device.DevicePK,
+			DeviceCode: device.DeviceCode,
+			MetroPK:    metroPKs[device.MetroCode],
+			MetroCode:  device.MetroCode,
+		}
+		if i > 0 {
+			hop.LinkMetric = edgeWeight(metricAdj, v, nodes[i-1])
+			hop.LinkLatency = float64(hop.LinkMetric) / 1000.0
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
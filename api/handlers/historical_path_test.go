@@ -0,0 +1,71 @@
+package handlers
+
+import "testing"
+
+func TestHistoricalShortestPath(t *testing.T) {
+	devices := map[string]PathHop{
+		"0000.0000.0001": {DevicePK: "dev-a", DeviceCode: "AMS-1"},
+		"0000.0000.0003": {DevicePK: "dev-c", DeviceCode: "LON-1"},
+	}
+	// A reaches C directly at 50, or via 0000.0000.0002 (since removed) at 20
+	adjacencies := []lsdbAdjacency{
+		{SystemID: "0000.0000.0001", NeighborSystemID: "0000.0000.0003", Metric: 50},
+		{SystemID: "0000.0000.0001", NeighborSystemID: "0000.0000.0002", Metric: 10},
+		{SystemID: "0000.0000.0002", NeighborSystemID: "0000.0000.0003", Metric: 10},
+	}
+
+	resp := historicalShortestPath(adjacencies, devices, "0000.0000.0001", "0000.0000.0003")
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	if resp.TotalMetric != 20 || resp.HopCount != 2 {
+		t.Errorf("expected metric 20 over 2 hops, got %d over %d", resp.TotalMetric, resp.HopCount)
+	}
+	if resp.Path[1].DeviceCode != "0000.0000.0002" || resp.Path[1].DevicePK != "" {
+		t.Errorf("expected unknown router by system ID, got %+v", resp.Path[1])
+	}
+	if resp.Path[2].DevicePK != "dev-c" || resp.Path[2].EdgeMetric != 10 {
+		t.Errorf("expected to reach dev-c over a metric 10 edge, got %+v", resp.Path[2])
+	}
+
+	// Adjacencies are directional as advertised
+	if resp := historicalShortestPath(adjacencies, devices, "0000.0000.0003", "0000.0000.0001"); resp.Error == "" {
+		t.Errorf("expected no path against the advertised direction, got %+v", resp.Path)
+	}
+}
+
+func TestHistoricalMetroPath(t *testing.T) {
+	devices := map[string]PathHop{
+		"0000.0000.0001": {DevicePK: "dev-a1", DeviceCode: "AMS-1", MetroCode: "ams"},
+		"0000.0000.0002": {DevicePK: "dev-a2", DeviceCode: "AMS-2", MetroCode: "ams"},
+		"0000.0000.0003": {DevicePK: "dev-f1", DeviceCode: "FRA-1", MetroCode: "fra"},
+		"0000.0000.0004": {DevicePK: "dev-l1", DeviceCode: "LON-1", MetroCode: "lon"},
+	}
+	metroPKs := map[string]string{"ams": "metro-ams", "fra": "metro-fra", "lon": "metro-lon"}
+	// AMS-1 reaches FRA directly at 100; AMS-2 reaches it via LON at 30
+	adjacencies := []lsdbAdjacency{
+		{SystemID: "0000.0000.0001", NeighborSystemID: "0000.0000.0003", Metric: 100},
+		{SystemID: "0000.0000.0002", NeighborSystemID: "0000.0000.0004", Metric: 10},
+		{SystemID: "0000.0000.0004", NeighborSystemID: "0000.0000.0003", Metric: 20},
+	}
+
+	hops := historicalMetroPath(adjacencies, devices, metroPKs, "ams", "fra", "latency")
+	if len(hops) != 3 {
+		t.Fatalf("expected 3 hops by metric, got %+v", hops)
+	}
+	if hops[0].DevicePK != "dev-a2" || hops[0].MetroPK != "metro-ams" || hops[0].LinkMetric != 10 {
+		t.Errorf("expected to start at dev-a2 over a metric 10 link, got %+v", hops[0])
+	}
+	if hops[2].DevicePK != "dev-f1" || hops[2].LinkMetric != 0 {
+		t.Errorf("expected to end at dev-f1 with no outgoing link, got %+v", hops[2])
+	}
+
+	hops = historicalMetroPath(adjacencies, devices, metroPKs, "ams", "fra", "hops")
+	if len(hops) != 2 || hops[0].DevicePK != "dev-a1" || hops[0].LinkMetric != 100 {
+		t.Errorf("expected the direct metric 100 link by hop count, got %+v", hops)
+	}
+
+	if hops := historicalMetroPath(adjacencies, devices, metroPKs, "fra", "ams", "latency"); hops != nil {
+		t.Errorf("expected no path against the advertised direction, got %+v", hops)
+	}
+}
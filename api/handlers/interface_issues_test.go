@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedInterfaceIssues inserts three interfaces with issues: errors on ii-1
+// Ethernet1 (2h ago), carrier flaps on ii-1 Ethernet2 (30m ago) and discards on
+// ii-2 Ethernet1 (3h ago). ii-1 is in AMS, ii-2 in FRA.
+func seedInterfaceIssues(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_metros_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name, longitude, latitude)
+		VALUES
+		('metro-ams', now(), now(), generateUUIDv4(), 0, 1, 'metro-ams', 'AMS', 'Amsterdam', 4.9, 52.4),
+		('metro-fra', now(), now(), generateUUIDv4(), 0, 2, 'metro-fra', 'FRA', 'Frankfurt', 8.7, 50.1)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('ii-1', now(), now(), generateUUIDv4(), 0, 1, 'ii-1', 'activated', 'hybrid', 'AMS-1', '', '', 'metro-ams', 0),
+		('ii-2', now(), now(), generateUUIDv4(), 0, 2, 'ii-2', 'activated', 'hybrid', 'FRA-1', '', '', 'metro-fra', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, in_errors_delta, out_errors_delta, in_discards_delta, out_discards_delta, carrier_transitions_delta)
+		VALUES
+		(now() - INTERVAL 2 HOUR, now(), 'ii-1', 'Ethernet1', 5, 0, 0, 0, 0),
+		(now() - INTERVAL 30 MINUTE, now(), 'ii-1', 'Ethernet2', 0, 0, 0, 0, 2),
+		(now() - INTERVAL 3 HOUR, now(), 'ii-2', 'Ethernet1', 0, 0, 10, 0, 0)`))
+}
+
+func getInterfaceIssues(t *testing.T, query url.Values) handlers.InterfaceIssuesResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/status/interface-issues?"+query.Encode(), nil)
+	rr := httptest.NewRecorder()
+	handlers.GetInterfaceIssues(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.InterfaceIssuesResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return resp
+}
+
+func TestGetInterfaceIssues_Filters(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedInterfaceIssues(t)
+
+	resp := getInterfaceIssues(t, url.Values{})
+	assert.Len(t, resp.Issues, 3)
+	assert.Equal(t, 3, resp.Total)
+	assert.False(t, resp.HasMore)
+
+	resp = getInterfaceIssues(t, url.Values{"device_pk": {"ii-1"}})
+	assert.Equal(t, 2, resp.Total)
+	for _, issue := range resp.Issues {
+		assert.Equal(t, "ii-1", issue.DevicePK)
+	}
+
+	resp = getInterfaceIssues(t, url.Values{"metro_pk": {"metro-fra"}})
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "ii-2", resp.Issues[0].DevicePK)
+
+	resp = getInterfaceIssues(t, url.Values{"issue_type": {"carrier"}})
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "Ethernet2", resp.Issues[0].InterfaceName)
+
+	resp = getInterfaceIssues(t, url.Values{"since": {time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}})
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "Ethernet2", resp.Issues[0].InterfaceName)
+}
+
+func TestGetInterfaceIssues_Pagination(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedInterfaceIssues(t)
+
+	resp := getInterfaceIssues(t, url.Values{"limit": {"2"}})
+	assert.Len(t, resp.Issues, 2)
+	assert.Equal(t, 3, resp.Total)
+	assert.True(t, resp.HasMore)
+
+	// Discards (10) outrank errors (5) and carrier transitions (2)
+	assert.Equal(t, "ii-2", resp.Issues[0].DevicePK)
+
+	resp = getInterfaceIssues(t, url.Values{"limit": {"2"}, "offset": {"2"}})
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "Ethernet2", resp.Issues[0].InterfaceName)
+	assert.Equal(t, 3, resp.Total)
+	assert.False(t, resp.HasMore)
+}
+
+func TestGetInterfaceIssues_BadRequest(t *testing.T) {
+	for _, q := range []string{"issue_type=fcs", "since=yesterday"} {
+		rr := httptest.NewRecorder()
+		handlers.GetInterfaceIssues(rr, httptest.NewRequest(http.MethodGet, "/api/status/interface-issues?"+q, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, q)
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/malbeclabs/lake/api/config"
 	"github.com/malbeclabs/lake/api/handlers/dberror"
 	"github.com/malbeclabs/lake/api/metrics"
@@ -30,6 +34,8 @@ type ISISNodeData struct {
 	MetroPK    string `json:"metroPK,omitempty"`
 	SystemID   string `json:"systemId,omitempty"`
 	RouterID   string `json:"routerId,omitempty"`
+	NodeType   string `json:"nodeType,omitempty"` // role in a multicast tree: root, subscriber or transit
+	IsCenter   bool   `json:"isCenter,omitempty"` // the center device of a neighbor graph
 }
 
 // ISISEdge represents an adjacency edge in the ISIS topology graph
@@ -53,26 +59,150 @@ type ISISTopologyResponse struct {
 	Error string     `json:"error,omitempty"`
 }
 
-// GetISISTopology returns the full ISIS topology graph
+// maxISISTopologyHops caps the max_hops filter on the ISIS topology endpoint.
+const maxISISTopologyHops = 10
+
+// isisTopologyFilter holds the optional filters for GetISISTopology.
+// MetroPK and SeedPK/MaxHops restrict the scope of the subgraph; Statuses and
+// DeviceTypes select which nodes in that scope match.
+type isisTopologyFilter struct {
+	MetroPK     string
+	Statuses    []string
+	DeviceTypes []string
+	SeedPK      string
+	MaxHops     int
+}
+
+func (f isisTopologyFilter) hasScope() bool {
+	return f.MetroPK != "" || f.SeedPK != ""
+}
+
+// parseISISTopologyFilter parses the filter query parameters for GetISISTopology.
+func parseISISTopologyFilter(r *http.Request) (isisTopologyFilter, error) {
+	q := r.URL.Query()
+	f := isisTopologyFilter{
+		MetroPK: q.Get("metro"),
+		SeedPK:  q.Get("seed_pk"),
+	}
+	if v := q.Get("status"); v != "" {
+		f.Statuses = splitCSV(v)
+	}
+	if v := q.Get("device_type"); v != "" {
+		f.DeviceTypes = splitCSV(v)
+	}
+
+	maxHopsStr := q.Get("max_hops")
+	if maxHopsStr != "" {
+		n, err := strconv.Atoi(maxHopsStr)
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("max_hops must be a non-negative integer")
+		}
+		if n > maxISISTopologyHops {
+			n = maxISISTopologyHops
+		}
+		f.MaxHops = n
+	}
+	if (f.SeedPK == "") != (maxHopsStr == "") {
+		return f, fmt.Errorf("seed_pk and max_hops must be provided together")
+	}
+	return f, nil
+}
+
+// splitCSV splits a comma-separated query value, dropping empty entries.
+func splitCSV(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// filterISISTopology applies the status and device type filters to a topology.
+// An edge is kept if at least one endpoint matches; endpoints of kept edges are
+// included even if they don't match, so the result is always a valid graph.
+func filterISISTopology(resp ISISTopologyResponse, statuses, deviceTypes []string) ISISTopologyResponse {
+	if len(statuses) == 0 && len(deviceTypes) == 0 {
+		return resp
+	}
+
+	matched := make(map[string]bool, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		if len(statuses) > 0 && !slices.Contains(statuses, n.Data.Status) {
+			continue
+		}
+		if len(deviceTypes) > 0 && !slices.Contains(deviceTypes, n.Data.DeviceType) {
+			continue
+		}
+		matched[n.Data.ID] = true
+	}
+
+	referenced := make(map[string]bool)
+	edges := []ISISEdge{}
+	for _, e := range resp.Edges {
+		if matched[e.Data.Source] || matched[e.Data.Target] {
+			edges = append(edges, e)
+			referenced[e.Data.Source] = true
+			referenced[e.Data.Target] = true
+		}
+	}
+
+	nodes := []ISISNode{}
+	for _, n := range resp.Nodes {
+		if matched[n.Data.ID] || referenced[n.Data.ID] {
+			nodes = append(nodes, n)
+		}
+	}
+
+	resp.Nodes = nodes
+	resp.Edges = edges
+	return resp
+}
+
+// GetISISTopology returns the ISIS topology graph.
+//
+// Optional query parameters narrow the graph:
+//   - metro: devices in the metro plus their one-hop ISIS neighbors
+//   - seed_pk + max_hops: devices within max_hops ISIS hops of the seed device
+//   - status, device_type: comma-separated node attribute filters
 func GetISISTopology(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
+	filter, err := parseISISTopologyFilter(r)
+	if err != nil {
+		writeJSON(w, ISISTopologyResponse{Nodes: []ISISNode{}, Edges: []ISISEdge{}, Error: err.Error()})
+		return
+	}
+
 	start := time.Now()
+	response, err := loadISISTopology(ctx, filter)
+	metrics.RecordNeo4jQuery("isis_topology", time.Since(start), err)
+	if err != nil {
+		response.Error = dberror.UserMessage(err)
+	}
 
+	writeJSON(w, response)
+}
+
+// loadISISTopology queries the ISIS topology graph narrowed by filter. On
+// error it returns an empty graph.
+func loadISISTopology(ctx context.Context, filter isisTopologyFilter) (ISISTopologyResponse, error) {
 	response := ISISTopologyResponse{
 		Nodes: []ISISNode{},
 		Edges: []ISISEdge{},
 	}
+	empty := response
 
 	// Helper to run Neo4j query with retry
-	runNeo4jQuery := func(cypher string) ([]*neo4jdriver.Record, error) {
+	runNeo4jQuery := func(cypher string, params map[string]any) ([]*neo4jdriver.Record, error) {
 		cfg := dberror.DefaultRetryConfig()
 		return dberror.Retry(ctx, cfg, func() ([]*neo4jdriver.Record, error) {
 			session := config.Neo4jSession(ctx)
 			defer session.Close(ctx)
 
-			result, err := session.Run(ctx, cypher, nil)
+			result, err := session.Run(ctx, cypher, params)
 			if err != nil {
 				return nil, err
 			}
@@ -80,10 +210,56 @@ func GetISISTopology(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	// Resolve the scope (metro and/or seed neighborhood) to a set of device PKs.
+	// When both are given, the scope is their intersection. The seed
+	// neighborhood is a breadth-first expansion that visits each device once,
+	// so its cost stays linear in the graph size however dense it is.
+	var scopePKs []string
+	if filter.hasScope() {
+		scopeCypher := `
+			OPTIONAL MATCH (:Metro {pk: $metro_pk})<-[:LOCATED_IN]-(md:Device)-[:ISIS_ADJACENT*0..1]-(m:Device)
+			WITH collect(DISTINCT m.pk) AS metro_pks
+			CALL {
+				MATCH (seed:Device {pk: $seed_pk})
+				CALL apoc.path.subgraphNodes(seed, {relationshipFilter: 'ISIS_ADJACENT', maxLevel: $max_hops}) YIELD node
+				RETURN collect(DISTINCT node.pk) AS seed_pks
+			}
+			WITH metro_pks, seed_pks
+			RETURN CASE
+			         WHEN $metro_pk = '' THEN seed_pks
+			         WHEN $seed_pk = '' THEN metro_pks
+			         ELSE [pk IN metro_pks WHERE pk IN seed_pks]
+			       END AS pks
+		`
+		scopeRecords, err := runNeo4jQuery(scopeCypher, map[string]any{
+			"metro_pk": filter.MetroPK,
+			"seed_pk":  filter.SeedPK,
+			"max_hops": filter.MaxHops,
+		})
+		if err != nil {
+			LoggerFromContext(ctx).Error("ISIS topology scope query error", "error", err)
+			return empty, err
+		}
+		scopePKs = []string{}
+		if len(scopeRecords) > 0 {
+			pks, _ := scopeRecords[0].Get("pks")
+			if arr, ok := pks.([]any); ok {
+				for _, pk := range arr {
+					scopePKs = append(scopePKs, asString(pk))
+				}
+			}
+		}
+	}
+	scopeParams := map[string]any{
+		"scoped": filter.hasScope(),
+		"pks":    scopePKs,
+	}
+
 	// Get devices with ISIS data
 	deviceCypher := `
 		MATCH (d:Device)
 		WHERE d.isis_system_id IS NOT NULL
+		  AND (NOT $scoped OR d.pk IN $pks)
 		OPTIONAL MATCH (d)-[:LOCATED_IN]->(m:Metro)
 		RETURN d.pk AS pk,
 		       d.code AS code,
@@ -94,12 +270,10 @@ func GetISISTopology(w http.ResponseWriter, r *http.Request) {
 		       m.pk AS metro_pk
 	`
 
-	deviceRecords, err := runNeo4jQuery(deviceCypher)
+	deviceRecords, err := runNeo4jQuery(deviceCypher, scopeParams)
 	if err != nil {
-		log.Printf("ISIS topology device query error: %v", err)
-		response.Error = dberror.UserMessage(err)
-		writeJSON(w, response)
-		return
+		LoggerFromContext(ctx).Error("ISIS topology device query error", "error", err)
+		return empty, err
 	}
 
 	for _, record := range deviceRecords {
@@ -124,9 +298,10 @@ func GetISISTopology(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// Get all ISIS adjacencies
+	// Get ISIS adjacencies within scope
 	adjCypher := `
 		MATCH (from:Device)-[r:ISIS_ADJACENT]->(to:Device)
+		WHERE NOT $scoped OR (from.pk IN $pks AND to.pk IN $pks)
 		RETURN from.pk AS from_pk,
 		       to.pk AS to_pk,
 		       r.metric AS metric,
@@ -134,12 +309,10 @@ func GetISISTopology(w http.ResponseWriter, r *http.Request) {
 		       r.adj_sids AS adj_sids
 	`
 
-	adjRecords, err := runNeo4jQuery(adjCypher)
+	adjRecords, err := runNeo4jQuery(adjCypher, scopeParams)
 	if err != nil {
-		log.Printf("ISIS topology adjacency query error: %v", err)
-		response.Error = dberror.UserMessage(err)
-		writeJSON(w, response)
-		return
+		LoggerFromContext(ctx).Error("ISIS topology adjacency query error", "error", err)
+		return empty, err
 	}
 
 	for _, record := range adjRecords {
@@ -161,10 +334,7 @@ func GetISISTopology(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil) // Reuse existing metric for now
-
-	writeJSON(w, response)
+	return filterISISTopology(response, filter.Statuses, filter.DeviceTypes), nil
 }
 
 // Helper functions
@@ -229,8 +399,121 @@ func asUint32Slice(v any) []uint32 {
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		slog.Default().Error("JSON encoding error", "error", err)
+	}
+}
+
+// DeviceNeighbor is a device directly adjacent to another via ISIS
+type DeviceNeighbor struct {
+	DevicePK     string   `json:"devicePK"`
+	DeviceCode   string   `json:"deviceCode"`
+	Status       string   `json:"status"`
+	DeviceType   string   `json:"deviceType"`
+	MetroPK      string   `json:"metroPK"`
+	Direction    string   `json:"direction"` // "outgoing" (device -> neighbor) or "incoming"
+	Metric       uint32   `json:"metric"`
+	NeighborAddr string   `json:"neighborAddr"`
+	AdjSIDs      []uint32 `json:"adjSids"`
+}
+
+// DeviceNeighborsResponse is the response for the device neighbors endpoint
+type DeviceNeighborsResponse struct {
+	Neighbors []DeviceNeighbor `json:"neighbors"`
+	Warning   string           `json:"warning,omitempty"`
+}
+
+// GetDeviceNeighbors returns the devices directly adjacent to a device via ISIS,
+// with one entry per adjacency direction. Only activated neighbors are returned
+// unless include_offline=true. If Neo4j is unavailable the list is empty and a
+// warning is set, rather than failing the request.
+func GetDeviceNeighbors(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
+		return
 	}
+	includeOffline := r.URL.Query().Get("include_offline") == "true"
+
+	response := DeviceNeighborsResponse{Neighbors: []DeviceNeighbor{}}
+
+	if !isMainnet(ctx) || config.Neo4jClient == nil {
+		response.Warning = "ISIS topology is unavailable for this environment"
+		writeJSON(w, response)
+		return
+	}
+
+	start := time.Now()
+
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	cypher := `
+		MATCH (d:Device {pk: $pk})-[r:ISIS_ADJACENT]-(n:Device)
+		WHERE $include_offline OR n.status = 'activated'
+		OPTIONAL MATCH (n)-[:LOCATED_IN]->(m:Metro)
+		RETURN n.pk AS pk,
+		       n.code AS code,
+		       n.status AS status,
+		       n.device_type AS device_type,
+		       m.pk AS metro_pk,
+		       startNode(r) = d AS outgoing,
+		       r.metric AS metric,
+		       r.neighbor_addr AS neighbor_addr,
+		       r.adj_sids AS adj_sids
+		ORDER BY code, outgoing DESC
+	`
+
+	result, err := session.Run(ctx, cypher, map[string]any{
+		"pk":              pk,
+		"include_offline": includeOffline,
+	})
+	var records []*neo4jdriver.Record
+	if err == nil {
+		records, err = result.Collect(ctx)
+	}
+	if err != nil {
+		LoggerFromContext(ctx).Error("Device neighbors query error", "error", err)
+		metrics.RecordNeo4jQuery("device_neighbors", time.Since(start), err)
+		response.Warning = "ISIS topology is unavailable: " + dberror.UserMessage(err)
+		writeJSON(w, response)
+		return
+	}
+
+	for _, record := range records {
+		neighborPK, _ := record.Get("pk")
+		code, _ := record.Get("code")
+		status, _ := record.Get("status")
+		deviceType, _ := record.Get("device_type")
+		metroPK, _ := record.Get("metro_pk")
+		outgoing, _ := record.Get("outgoing")
+		metric, _ := record.Get("metric")
+		neighborAddr, _ := record.Get("neighbor_addr")
+		adjSids, _ := record.Get("adj_sids")
+
+		direction := "incoming"
+		if asBool(outgoing) {
+			direction = "outgoing"
+		}
+
+		response.Neighbors = append(response.Neighbors, DeviceNeighbor{
+			DevicePK:     asString(neighborPK),
+			DeviceCode:   asString(code),
+			Status:       asString(status),
+			DeviceType:   asString(deviceType),
+			MetroPK:      asString(metroPK),
+			Direction:    direction,
+			Metric:       uint32(asInt64(metric)),
+			NeighborAddr: asString(neighborAddr),
+			AdjSIDs:      asUint32Slice(adjSids),
+		})
+	}
+
+	metrics.RecordNeo4jQuery("device_neighbors", time.Since(start), nil)
+
+	writeJSON(w, response)
 }
 
 // PathHop represents a hop in a path
@@ -239,17 +522,25 @@ type PathHop struct {
 	DeviceCode string `json:"deviceCode"`
 	Status     string `json:"status"`
 	DeviceType string `json:"deviceType"`
+	MetroCode  string `json:"metroCode,omitempty"`
+	EdgeMetric uint32 `json:"edgeMetric,omitempty"` // ISIS metric to reach this hop from previous
+	// EdgeMeasuredMs is the measured RTT in ms to reach this hop, only set
+	// by endpoints that report per-hop latency
+	EdgeMeasuredMs float64 `json:"edgeMeasuredMs,omitempty"`
 }
 
 // PathResponse is the response for the path endpoint
 type PathResponse struct {
-	Path        []PathHop `json:"path"`
-	TotalMetric uint32    `json:"totalMetric"`
-	HopCount    int       `json:"hopCount"`
-	Error       string    `json:"error,omitempty"`
+	Path              []PathHop `json:"path"`
+	TotalMetric       uint32    `json:"totalMetric"`
+	HopCount          int       `json:"hopCount"`
+	MeasuredLatencyMs float64   `json:"measuredLatencyMs,omitempty"` // sum of measured RTT along path, when reported
+	Error             string    `json:"error,omitempty"`
 }
 
-// GetISISPath finds the shortest path between two devices using ISIS metrics
+// GetISISPath finds the shortest path between two devices using ISIS metrics.
+// With format=traceroute the path is rendered as traceroute-style text with
+// measured latency instead of JSON.
 func GetISISPath(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
@@ -257,17 +548,108 @@ func GetISISPath(w http.ResponseWriter, r *http.Request) {
 	fromPK := r.URL.Query().Get("from")
 	toPK := r.URL.Query().Get("to")
 	mode := r.URL.Query().Get("mode") // "hops" or "latency"
+	format := r.URL.Query().Get("format")
 
-	if fromPK == "" || toPK == "" {
-		writeJSON(w, PathResponse{Error: "from and to parameters are required"})
+	if format != "" && format != "json" && format != "traceroute" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "format must be json or traceroute")
 		return
 	}
 
-	if fromPK == toPK {
-		writeJSON(w, PathResponse{Error: "from and to must be different devices"})
+	var response PathResponse
+	switch {
+	case fromPK == "" || toPK == "":
+		response = PathResponse{Error: "from and to parameters are required"}
+	case fromPK == toPK:
+		response = PathResponse{Error: "from and to must be different devices"}
+	default:
+		response = findISISPath(ctx, fromPK, toPK, mode)
+	}
+
+	if format != "traceroute" {
+		writeJSON(w, response)
 		return
 	}
 
+	var measured SinglePath
+	if response.Error == "" {
+		measured = pathToSinglePath(response)
+		multi := MultiPathResponse{Paths: []SinglePath{measured}}
+		if err := enrichPathsWithMeasuredLatency(ctx, &multi); err != nil {
+			LoggerFromContext(ctx).Error("enrichPathsWithMeasuredLatency error for traceroute", "error", err)
+		}
+		measured = multi.Paths[0]
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, formatTraceroute(response, measured))
+}
+
+// pathToSinglePath converts a path to the multi-path format so it can be
+// enriched with measured latency.
+func pathToSinglePath(path PathResponse) SinglePath {
+	hops := make([]MultiPathHop, len(path.Path))
+	for i, hop := range path.Path {
+		hops[i] = MultiPathHop{
+			DevicePK:   hop.DevicePK,
+			DeviceCode: hop.DeviceCode,
+			Status:     hop.Status,
+			DeviceType: hop.DeviceType,
+			EdgeMetric: hop.EdgeMetric,
+		}
+	}
+	return SinglePath{Path: hops, TotalMetric: path.TotalMetric, HopCount: path.HopCount}
+}
+
+// formatTraceroute renders a path like traceroute output: a header naming the
+// source, then one line per hop after it with the cumulative ISIS metric and
+// measured RTT to that hop, and the jitter of the hop's link. ISIS metrics are
+// in microseconds. Hops without measurements show "*".
+//
+//	traceroute from NYC1 to LAX1, 3 hops
+//	 1  NYC2  NYC  0.01ms  0.50ms (±0.02)
+//	 2  CHI1  CHI  0.03ms  *
+func formatTraceroute(path PathResponse, measured SinglePath) string {
+	var b strings.Builder
+	if path.Error != "" {
+		fmt.Fprintf(&b, "traceroute: %s\n", path.Error)
+		return b.String()
+	}
+	if len(path.Path) == 0 {
+		b.WriteString("traceroute: no path\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "traceroute from %s to %s, %d hops\n", path.Path[0].DeviceCode, path.Path[len(path.Path)-1].DeviceCode, len(path.Path)-1)
+
+	var metricUs uint64
+	var rttMs float64
+	measuredSoFar := true
+	for i := 1; i < len(path.Path); i++ {
+		hop := path.Path[i]
+		metricUs += uint64(hop.EdgeMetric)
+
+		metro := hop.MetroCode
+		if metro == "" {
+			metro = "-"
+		}
+
+		rtt := "*"
+		if i < len(measured.Path) && measured.Path[i].EdgeSampleCount > 0 && measuredSoFar {
+			rttMs += measured.Path[i].EdgeMeasuredMs
+			rtt = fmt.Sprintf("%.2fms (±%.2f)", rttMs, measured.Path[i].EdgeJitterMs)
+		} else {
+			// A cumulative RTT is meaningless past an unmeasured hop
+			measuredSoFar = false
+		}
+
+		fmt.Fprintf(&b, "%2d  %s  %s  %.2fms  %s\n", i, hop.DeviceCode, metro, float64(metricUs)/1000, rtt)
+	}
+	return b.String()
+}
+
+// findISISPath finds the shortest ISIS path between two devices, by hop count
+// or, in "latency" mode, by total metric. Failures are reported in the
+// response's Error field.
+func findISISPath(ctx context.Context, fromPK, toPK, mode string) PathResponse {
 	if mode == "" {
 		mode = "hops" // default to fewest hops
 	}
@@ -287,8 +669,10 @@ func GetISISPath(w http.ResponseWriter, r *http.Request) {
 				pk: n.pk,
 				code: n.code,
 				status: n.status,
-				device_type: n.device_type
+				device_type: n.device_type,
+				metro_code: head([(n)-[:LOCATED_IN]->(m:Metro) | m.code])
 			}] AS devices,
+			[r IN relationships(path) | r.metric] AS edge_metrics,
 			weight AS total_metric
 		`
 	} else {
@@ -301,8 +685,10 @@ func GetISISPath(w http.ResponseWriter, r *http.Request) {
 				pk: n.pk,
 				code: n.code,
 				status: n.status,
-				device_type: n.device_type
+				device_type: n.device_type,
+				metro_code: head([(n)-[:LOCATED_IN]->(m:Metro) | m.code])
 			}] AS devices,
+			[r IN relationships(path) | r.metric] AS edge_metrics,
 			total_metric
 		`
 	}
@@ -312,31 +698,38 @@ func GetISISPath(w http.ResponseWriter, r *http.Request) {
 		"to_pk":   toPK,
 	})
 	if err != nil {
-		log.Printf("ISIS path query error: %v", err)
-		writeJSON(w, PathResponse{Error: "Failed to find path: " + err.Error()})
-		return
+		LoggerFromContext(ctx).Error("ISIS path query error", "error", err)
+		metrics.RecordNeo4jQuery("isis_path", time.Since(start), err)
+		return PathResponse{Error: "Failed to find path: " + err.Error()}
 	}
 
 	record, err := result.Single(ctx)
 	if err != nil {
-		log.Printf("ISIS path no result: %v", err)
-		writeJSON(w, PathResponse{Error: "No path found between devices"})
-		return
+		LoggerFromContext(ctx).Error("ISIS path no result", "error", err)
+		return PathResponse{Error: "No path found between devices"}
 	}
 
 	devicesVal, _ := record.Get("devices")
+	edgeMetricsVal, _ := record.Get("edge_metrics")
 	totalMetric, _ := record.Get("total_metric")
 
 	path := parsePathHops(devicesVal)
+	if edgeMetrics, ok := edgeMetricsVal.([]any); ok {
+		for i, m := range edgeMetrics {
+			if i+1 < len(path) {
+				path[i+1].EdgeMetric = uint32(asInt64(m))
+			}
+		}
+	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("isis_path", duration, nil)
 
-	writeJSON(w, PathResponse{
+	return PathResponse{
 		Path:        path,
 		TotalMetric: uint32(asInt64(totalMetric)),
 		HopCount:    len(path) - 1,
-	})
+	}
 }
 
 func parsePathHops(v any) []PathHop {
@@ -358,6 +751,7 @@ func parsePathHops(v any) []PathHop {
 			DeviceCode: asString(m["code"]),
 			Status:     asString(m["status"]),
 			DeviceType: asString(m["device_type"]),
+			MetroCode:  asString(m["metro_code"]),
 		})
 	}
 	return hops
@@ -422,7 +816,8 @@ func GetTopologyCompare(w http.ResponseWriter, r *http.Request) {
 
 	configuredResult, err := session.Run(ctx, configuredCypher, nil)
 	if err != nil {
-		log.Printf("Topology compare configured query error: %v", err)
+		LoggerFromContext(ctx).Error("Topology compare configured query error", "error", err)
+		metrics.RecordNeo4jQuery("topology_compare", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -430,7 +825,8 @@ func GetTopologyCompare(w http.ResponseWriter, r *http.Request) {
 
 	configuredRecords, err := configuredResult.Collect(ctx)
 	if err != nil {
-		log.Printf("Topology compare configured collect error: %v", err)
+		LoggerFromContext(ctx).Error("Topology compare configured collect error", "error", err)
+		metrics.RecordNeo4jQuery("topology_compare", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -530,7 +926,8 @@ func GetTopologyCompare(w http.ResponseWriter, r *http.Request) {
 
 	extraResult, err := session.Run(ctx, extraCypher, nil)
 	if err != nil {
-		log.Printf("Topology compare extra query error: %v", err)
+		LoggerFromContext(ctx).Error("Topology compare extra query error", "error", err)
+		metrics.RecordNeo4jQuery("topology_compare", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -538,7 +935,8 @@ func GetTopologyCompare(w http.ResponseWriter, r *http.Request) {
 
 	extraRecords, err := extraResult.Collect(ctx)
 	if err != nil {
-		log.Printf("Topology compare extra collect error: %v", err)
+		LoggerFromContext(ctx).Error("Topology compare extra collect error", "error", err)
+		metrics.RecordNeo4jQuery("topology_compare", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -567,7 +965,7 @@ func GetTopologyCompare(w http.ResponseWriter, r *http.Request) {
 	countCypher := `MATCH ()-[r:ISIS_ADJACENT]->() RETURN count(r) AS count`
 	countResult, err := session.Run(ctx, countCypher, nil)
 	if err != nil {
-		log.Printf("Topology compare count query error: %v", err)
+		LoggerFromContext(ctx).Error("Topology compare count query error", "error", err)
 	} else {
 		if countRecord, err := countResult.Single(ctx); err == nil {
 			count, _ := countRecord.Get("count")
@@ -576,7 +974,7 @@ func GetTopologyCompare(w http.ResponseWriter, r *http.Request) {
 	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("topology_compare", duration, nil)
 
 	writeJSON(w, response)
 }
@@ -662,7 +1060,8 @@ func GetFailureImpact(w http.ResponseWriter, r *http.Request) {
 	deviceCypher := `MATCH (d:Device {pk: $pk}) RETURN d.code AS code`
 	deviceResult, err := session.Run(ctx, deviceCypher, map[string]any{"pk": devicePK})
 	if err != nil {
-		log.Printf("Failure impact device query error: %v", err)
+		LoggerFromContext(ctx).Error("Failure impact device query error", "error", err)
+		metrics.RecordNeo4jQuery("failure_impact", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -709,7 +1108,8 @@ func GetFailureImpact(w http.ResponseWriter, r *http.Request) {
 		"device_pk": devicePK,
 	})
 	if err != nil {
-		log.Printf("Failure impact query error: %v", err)
+		LoggerFromContext(ctx).Error("Failure impact query error", "error", err)
+		metrics.RecordNeo4jQuery("failure_impact", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -717,7 +1117,8 @@ func GetFailureImpact(w http.ResponseWriter, r *http.Request) {
 
 	impactRecords, err := impactResult.Collect(ctx)
 	if err != nil {
-		log.Printf("Failure impact collect error: %v", err)
+		LoggerFromContext(ctx).Error("Failure impact collect error", "error", err)
+		metrics.RecordNeo4jQuery("failure_impact", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -759,12 +1160,12 @@ func GetFailureImpact(w http.ResponseWriter, r *http.Request) {
 	`
 	metroResult, err := session.Run(ctx, metroCypher, map[string]any{})
 	if err != nil {
-		log.Printf("Failure impact metro query error: %v", err)
+		LoggerFromContext(ctx).Error("Failure impact metro query error", "error", err)
 		// Don't fail the whole response, just log the error
 	} else {
 		metroRecords, err := metroResult.Collect(ctx)
 		if err != nil {
-			log.Printf("Failure impact metro collect error: %v", err)
+			LoggerFromContext(ctx).Error("Failure impact metro collect error", "error", err)
 		} else {
 			for _, record := range metroRecords {
 				metroPK, _ := record.Get("metro_pk")
@@ -851,12 +1252,12 @@ func GetFailureImpact(w http.ResponseWriter, r *http.Request) {
 		"device_pk": devicePK,
 	})
 	if err != nil {
-		log.Printf("Failure impact affected paths query error: %v", err)
+		LoggerFromContext(ctx).Error("Failure impact affected paths query error", "error", err)
 		// Don't fail the whole response, just log the error
 	} else {
 		affectedRecords, err := affectedResult.Collect(ctx)
 		if err != nil {
-			log.Printf("Failure impact affected paths collect error: %v", err)
+			LoggerFromContext(ctx).Error("Failure impact affected paths collect error", "error", err)
 		} else {
 			for _, record := range affectedRecords {
 				fromPK, _ := record.Get("from_pk")
@@ -886,10 +1287,11 @@ func GetFailureImpact(w http.ResponseWriter, r *http.Request) {
 	response.AffectedPathCount = len(response.AffectedPaths)
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("failure_impact", duration, nil)
 
-	log.Printf("Failure impact: %s, unreachable=%d, affectedPaths=%d, metrosImpacted=%d in %v",
-		response.DeviceCode, response.UnreachableCount, response.AffectedPathCount, len(response.MetroImpact), duration)
+	LoggerFromContext(ctx).Info("Failure impact completed",
+		"device_code", response.DeviceCode, "unreachable", response.UnreachableCount, "affected_paths", response.AffectedPathCount,
+		"metros_impacted", len(response.MetroImpact), "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
@@ -1015,7 +1417,8 @@ func GetISISPaths(w http.ResponseWriter, r *http.Request) {
 		"to_pk":   toPK,
 	})
 	if err != nil {
-		log.Printf("ISIS multi-path query error: %v", err)
+		LoggerFromContext(ctx).Error("ISIS multi-path query error", "error", err)
+		metrics.RecordNeo4jQuery("isis_paths", time.Since(start), err)
 		response.Error = "Failed to find paths: " + err.Error()
 		writeJSON(w, response)
 		return
@@ -1023,7 +1426,8 @@ func GetISISPaths(w http.ResponseWriter, r *http.Request) {
 
 	records, err := result.Collect(ctx)
 	if err != nil {
-		log.Printf("ISIS multi-path collect error: %v", err)
+		LoggerFromContext(ctx).Error("ISIS multi-path collect error", "error", err)
+		metrics.RecordNeo4jQuery("isis_paths", time.Since(start), err)
 		response.Error = "Failed to collect paths: " + err.Error()
 		writeJSON(w, response)
 		return
@@ -1072,7 +1476,7 @@ func GetISISPaths(w http.ResponseWriter, r *http.Request) {
 
 	// Enrich paths with measured latency from ClickHouse
 	if err := enrichPathsWithMeasuredLatency(ctx, &response); err != nil {
-		log.Printf("enrichPathsWithMeasuredLatency error: %v", err)
+		LoggerFromContext(ctx).Error("enrichPathsWithMeasuredLatency error", "error", err)
 		response.Error = fmt.Sprintf("failed to enrich paths with measured latency: %v", err)
 	}
 
@@ -1115,12 +1519,206 @@ func GetISISPaths(w http.ResponseWriter, r *http.Request) {
 	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
-	log.Printf("ISIS multi-path query (%s mode) returned %d paths in %v", pathMode, len(response.Paths), duration)
+	metrics.RecordNeo4jQuery("isis_paths", duration, nil)
+	LoggerFromContext(ctx).Info("ISIS multi-path query completed", "mode", pathMode, "paths", len(response.Paths), "duration_ms", duration.Milliseconds())
+
+	writeJSON(w, response)
+}
+
+// ECMPPathsResponse is the response for the ECMP paths endpoint
+type ECMPPathsResponse struct {
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	ECMPCount   int          `json:"ecmp_count"`
+	TotalMetric uint32       `json:"totalMetric"`
+	Paths       []SinglePath `json:"paths"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// maxECMPPaths caps how many equal-cost paths GetECMPPaths returns
+const maxECMPPaths = 64
+
+// GetECMPPaths returns all equal-cost paths between two devices, i.e. every
+// path whose total ISIS metric equals the minimum total metric, regardless of
+// hop count.
+func GetECMPPaths(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	fromPK := r.URL.Query().Get("from")
+	toPK := r.URL.Query().Get("to")
+
+	if fromPK == "" || toPK == "" {
+		writeJSON(w, ECMPPathsResponse{Error: "from and to parameters are required"})
+		return
+	}
+
+	if fromPK == toPK {
+		writeJSON(w, ECMPPathsResponse{Error: "from and to must be different devices"})
+		return
+	}
+
+	start := time.Now()
+
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	response := ECMPPathsResponse{
+		From:  fromPK,
+		To:    toPK,
+		Paths: []SinglePath{},
+	}
+
+	// Load the directed adjacencies; parallel adjacencies collapse to the
+	// lowest metric, as ISIS would only use those
+	cypher := `
+		MATCH (a:Device)-[r:ISIS_ADJACENT]->(b:Device)
+		RETURN a.pk AS sourcePK,
+		       a.code AS sourceCode,
+		       a.status AS sourceStatus,
+		       a.device_type AS sourceType,
+		       b.pk AS targetPK,
+		       b.code AS targetCode,
+		       b.status AS targetStatus,
+		       b.device_type AS targetType,
+		       min(coalesce(r.metric, 1)) AS metric
+	`
+
+	result, err := session.Run(ctx, cypher, nil)
+	if err != nil {
+		LoggerFromContext(ctx).Error("ISIS ECMP query error", "error", err)
+		metrics.RecordNeo4jQuery("ecmp_paths", time.Since(start), err)
+		response.Error = "Failed to find paths: " + err.Error()
+		writeJSON(w, response)
+		return
+	}
+
+	records, err := result.Collect(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("ISIS ECMP collect error", "error", err)
+		metrics.RecordNeo4jQuery("ecmp_paths", time.Since(start), err)
+		response.Error = "Failed to collect paths: " + err.Error()
+		writeJSON(w, response)
+		return
+	}
+
+	index := make(map[string]int)
+	var hops []MultiPathHop
+	var adj [][]isisGraphEdge
+	nodeIndex := func(pk, code, status, deviceType any) int {
+		i, ok := index[asString(pk)]
+		if !ok {
+			i = len(hops)
+			index[asString(pk)] = i
+			hops = append(hops, MultiPathHop{
+				DevicePK:   asString(pk),
+				DeviceCode: asString(code),
+				Status:     asString(status),
+				DeviceType: asString(deviceType),
+			})
+			adj = append(adj, nil)
+		}
+		return i
+	}
+	for _, record := range records {
+		sourcePK, _ := record.Get("sourcePK")
+		sourceCode, _ := record.Get("sourceCode")
+		sourceStatus, _ := record.Get("sourceStatus")
+		sourceType, _ := record.Get("sourceType")
+		targetPK, _ := record.Get("targetPK")
+		targetCode, _ := record.Get("targetCode")
+		targetStatus, _ := record.Get("targetStatus")
+		targetType, _ := record.Get("targetType")
+		metric, _ := record.Get("metric")
+
+		a := nodeIndex(sourcePK, sourceCode, sourceStatus, sourceType)
+		b := nodeIndex(targetPK, targetCode, targetStatus, targetType)
+		weight := asInt64(metric)
+		if weight <= 0 {
+			weight = 1
+		}
+		adj[a] = append(adj[a], isisGraphEdge{to: b, weight: weight})
+	}
+
+	from, okFrom := index[fromPK]
+	to, okTo := index[toPK]
+	var paths [][]int
+	var totalMetric int64
+	if okFrom && okTo {
+		paths, totalMetric = equalCostPaths(adj, from, to, maxECMPPaths)
+	}
+	if len(paths) == 0 {
+		metrics.RecordNeo4jQuery("ecmp_paths", time.Since(start), nil)
+		response.Error = "No paths found between devices"
+		writeJSON(w, response)
+		return
+	}
+
+	response.TotalMetric = uint32(totalMetric)
+	for _, nodes := range paths {
+		path := make([]MultiPathHop, len(nodes))
+		for i, n := range nodes {
+			path[i] = hops[n]
+			if i > 0 {
+				path[i].EdgeMetric = uint32(edgeWeight(adj, nodes[i-1], n))
+			}
+		}
+		response.Paths = append(response.Paths, SinglePath{
+			Path:        path,
+			TotalMetric: uint32(totalMetric),
+			HopCount:    len(path) - 1,
+		})
+	}
+	response.ECMPCount = len(response.Paths)
+
+	duration := time.Since(start)
+	metrics.RecordNeo4jQuery("ecmp_paths", duration, nil)
+	LoggerFromContext(ctx).Info("ISIS ECMP query completed", "paths", response.ECMPCount, "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
 
+// equalCostPaths returns up to limit paths from s to t in a directed graph
+// whose total weight equals the minimum, and that minimum. An edge u->v lies
+// on such a path when the distance from s to u, plus its weight, plus the
+// distance from v to t adds up to the minimum.
+func equalCostPaths(adj [][]isisGraphEdge, s, t, limit int) ([][]int, int64) {
+	dist, _ := shortestPathCounts(adj, s)
+	if dist[t] < 0 {
+		return nil, 0
+	}
+
+	reverse := make([][]isisGraphEdge, len(adj))
+	for u, edges := range adj {
+		for _, e := range edges {
+			reverse[e.to] = append(reverse[e.to], isisGraphEdge{to: u, weight: e.weight})
+		}
+	}
+	distToT, _ := shortestPathCounts(reverse, t)
+
+	total := dist[t]
+	var paths [][]int
+	var walk func(path []int)
+	walk = func(path []int) {
+		if len(paths) >= limit {
+			return
+		}
+		u := path[len(path)-1]
+		if u == t {
+			paths = append(paths, slices.Clone(path))
+			return
+		}
+		for _, e := range adj[u] {
+			v := e.to
+			if dist[u]+e.weight == dist[v] && distToT[v] >= 0 && dist[v]+distToT[v] == total {
+				walk(append(path, v))
+			}
+		}
+	}
+	walk([]int{s})
+	return paths, total
+}
+
 func parseNodeListWithMetrics(nodeListVal, edgeMetricsVal any) []MultiPathHop {
 	if nodeListVal == nil {
 		return []MultiPathHop{}
@@ -1175,12 +1773,9 @@ type linkLatencyData struct {
 	SampleCount uint64
 }
 
-// enrichPathsWithMeasuredLatency queries ClickHouse for measured latency and adds it to path hops
-func enrichPathsWithMeasuredLatency(ctx context.Context, response *MultiPathResponse) error {
-	if len(response.Paths) == 0 {
-		return nil
-	}
-
+// loadLinkLatencyMap returns the past 3 hours of measured latency per link,
+// keyed "deviceA:deviceB" in both directions since links are bidirectional
+func loadLinkLatencyMap(ctx context.Context) (map[string]linkLatencyData, error) {
 	// Query ClickHouse for measured latency per link, including device endpoints
 	query := `
 		SELECT
@@ -1200,7 +1795,7 @@ func enrichPathsWithMeasuredLatency(ctx context.Context, response *MultiPathResp
 
 	rows, err := envDB(ctx).Query(ctx, query)
 	if err != nil {
-		return fmt.Errorf("enrichPathsWithMeasuredLatency query error: %w", err)
+		return nil, fmt.Errorf("loadLinkLatencyMap query error: %w", err)
 	}
 	defer rows.Close()
 
@@ -1210,14 +1805,27 @@ func enrichPathsWithMeasuredLatency(ctx context.Context, response *MultiPathResp
 	for rows.Next() {
 		var data linkLatencyData
 		if err := rows.Scan(&data.SideAPK, &data.SideZPK, &data.AvgRttMs, &data.AvgJitterMs, &data.LossPct, &data.SampleCount); err != nil {
-			return fmt.Errorf("enrichPathsWithMeasuredLatency scan error: %w", err)
+			return nil, fmt.Errorf("loadLinkLatencyMap scan error: %w", err)
 		}
 		// Store in both directions
 		latencyMap[data.SideAPK+":"+data.SideZPK] = data
 		latencyMap[data.SideZPK+":"+data.SideAPK] = data
 	}
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("enrichPathsWithMeasuredLatency rows error: %w", err)
+		return nil, fmt.Errorf("loadLinkLatencyMap rows error: %w", err)
+	}
+	return latencyMap, nil
+}
+
+// enrichPathsWithMeasuredLatency queries ClickHouse for measured latency and adds it to path hops
+func enrichPathsWithMeasuredLatency(ctx context.Context, response *MultiPathResponse) error {
+	if len(response.Paths) == 0 {
+		return nil
+	}
+
+	latencyMap, err := loadLinkLatencyMap(ctx)
+	if err != nil {
+		return err
 	}
 
 	// Update each path with measured latency
@@ -1317,7 +1925,8 @@ func GetCriticalLinks(w http.ResponseWriter, r *http.Request) {
 
 	result, err := session.Run(ctx, cypher, nil)
 	if err != nil {
-		log.Printf("Critical links query error: %v", err)
+		LoggerFromContext(ctx).Error("Critical links query error", "error", err)
+		metrics.RecordNeo4jQuery("critical_links", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1325,7 +1934,8 @@ func GetCriticalLinks(w http.ResponseWriter, r *http.Request) {
 
 	records, err := result.Collect(ctx)
 	if err != nil {
-		log.Printf("Critical links collect error: %v", err)
+		LoggerFromContext(ctx).Error("Critical links collect error", "error", err)
+		metrics.RecordNeo4jQuery("critical_links", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1368,7 +1978,7 @@ func GetCriticalLinks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("critical_links", duration, nil)
 
 	criticalCount := 0
 	importantCount := 0
@@ -1379,15 +1989,15 @@ func GetCriticalLinks(w http.ResponseWriter, r *http.Request) {
 			importantCount++
 		}
 	}
-	log.Printf("Critical links query returned %d links (%d critical, %d important) in %v",
-		len(response.Links), criticalCount, importantCount, duration)
+	LoggerFromContext(ctx).Info("Critical links query completed",
+		"links", len(response.Links), "critical", criticalCount, "important", importantCount, "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
 
 // RedundancyIssue represents a single redundancy issue in the network
 type RedundancyIssue struct {
-	Type        string `json:"type"`        // "leaf_device", "critical_link", "single_exit_metro", "no_backup_device"
+	Type        string `json:"type"`        // "leaf_device", "critical_link", "single_exit_metro", "no_backup_device", "non_redundant_hub"
 	Severity    string `json:"severity"`    // "critical", "warning", "info"
 	EntityPK    string `json:"entityPK"`    // PK of affected entity
 	EntityCode  string `json:"entityCode"`  // Code/name of affected entity
@@ -1404,9 +2014,10 @@ type RedundancyIssue struct {
 
 // RedundancyReportResponse is the response for the redundancy report endpoint
 type RedundancyReportResponse struct {
-	Issues  []RedundancyIssue `json:"issues"`
-	Summary RedundancySummary `json:"summary"`
-	Error   string            `json:"error,omitempty"`
+	Issues                []RedundancyIssue  `json:"issues"`
+	HighCentralityDevices []CentralityDevice `json:"highCentralityDevices"`
+	Summary               RedundancySummary  `json:"summary"`
+	Error                 string             `json:"error,omitempty"`
 }
 
 type RedundancySummary struct {
@@ -1417,6 +2028,7 @@ type RedundancySummary struct {
 	LeafDevices      int `json:"leafDevices"`
 	CriticalLinks    int `json:"criticalLinks"`
 	SingleExitMetros int `json:"singleExitMetros"`
+	NonRedundantHubs int `json:"nonRedundantHubs"`
 }
 
 // GetRedundancyReport returns a comprehensive redundancy analysis report
@@ -1430,7 +2042,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 	defer session.Close(ctx)
 
 	response := RedundancyReportResponse{
-		Issues: []RedundancyIssue{},
+		Issues:                []RedundancyIssue{},
+		HighCentralityDevices: []CentralityDevice{},
 	}
 
 	// 1. Find leaf devices (devices with only 1 ISIS neighbor)
@@ -1451,7 +2064,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 
 	leafResult, err := session.Run(ctx, leafCypher, nil)
 	if err != nil {
-		log.Printf("Redundancy report leaf devices query error: %v", err)
+		LoggerFromContext(ctx).Error("Redundancy report leaf devices query error", "error", err)
+		metrics.RecordNeo4jQuery("redundancy_report", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1459,7 +2073,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 
 	leafRecords, err := leafResult.Collect(ctx)
 	if err != nil {
-		log.Printf("Redundancy report leaf devices collect error: %v", err)
+		LoggerFromContext(ctx).Error("Redundancy report leaf devices collect error", "error", err)
+		metrics.RecordNeo4jQuery("redundancy_report", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1509,7 +2124,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 
 	criticalResult, err := session.Run(ctx, criticalLinksCypher, nil)
 	if err != nil {
-		log.Printf("Redundancy report critical links query error: %v", err)
+		LoggerFromContext(ctx).Error("Redundancy report critical links query error", "error", err)
+		metrics.RecordNeo4jQuery("redundancy_report", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1517,7 +2133,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 
 	criticalRecords, err := criticalResult.Collect(ctx)
 	if err != nil {
-		log.Printf("Redundancy report critical links collect error: %v", err)
+		LoggerFromContext(ctx).Error("Redundancy report critical links collect error", "error", err)
+		metrics.RecordNeo4jQuery("redundancy_report", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1561,7 +2178,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 
 	singleExitResult, err := session.Run(ctx, singleExitCypher, nil)
 	if err != nil {
-		log.Printf("Redundancy report single-exit metros query error: %v", err)
+		LoggerFromContext(ctx).Error("Redundancy report single-exit metros query error", "error", err)
+		metrics.RecordNeo4jQuery("redundancy_report", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1569,7 +2187,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 
 	singleExitRecords, err := singleExitResult.Collect(ctx)
 	if err != nil {
-		log.Printf("Redundancy report single-exit metros collect error: %v", err)
+		LoggerFromContext(ctx).Error("Redundancy report single-exit metros collect error", "error", err)
+		metrics.RecordNeo4jQuery("redundancy_report", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -1596,6 +2215,39 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	// 4. Find high-centrality hubs whose failure would split the topology.
+	// Centrality loads the graph with its own session, so release this one
+	// first rather than hold it while waiting for another.
+	_ = session.Close(ctx)
+	centrality, _, err := getBetweennessCentrality(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Redundancy report betweenness centrality error", "error", err)
+		metrics.RecordNeo4jQuery("redundancy_report", time.Since(start), err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	for _, device := range centrality {
+		if device.Score < highCentralityThreshold {
+			break // sorted by score descending
+		}
+		response.HighCentralityDevices = append(response.HighCentralityDevices, device)
+		if !device.CutVertex {
+			continue
+		}
+
+		response.Issues = append(response.Issues, RedundancyIssue{
+			Type:        "non_redundant_hub",
+			Severity:    "warning",
+			EntityPK:    device.PK,
+			EntityCode:  device.Code,
+			EntityType:  "device",
+			Description: fmt.Sprintf("Device carries %.0f%% of shortest paths and has no alternate route around it", device.Score*100),
+			Impact:      "If this device fails, the network is partitioned",
+			MetroPK:     device.MetroPK,
+		})
+	}
+
 	// Build summary
 	criticalCount := 0
 	warningCount := 0
@@ -1603,6 +2255,7 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 	leafDeviceCount := 0
 	criticalLinkCount := 0
 	singleExitMetroCount := 0
+	nonRedundantHubCount := 0
 
 	for _, issue := range response.Issues {
 		switch issue.Severity {
@@ -1621,6 +2274,8 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 			criticalLinkCount++
 		case "single_exit_metro":
 			singleExitMetroCount++
+		case "non_redundant_hub":
+			nonRedundantHubCount++
 		}
 	}
 
@@ -1632,13 +2287,14 @@ func GetRedundancyReport(w http.ResponseWriter, r *http.Request) {
 		LeafDevices:      leafDeviceCount,
 		CriticalLinks:    criticalLinkCount,
 		SingleExitMetros: singleExitMetroCount,
+		NonRedundantHubs: nonRedundantHubCount,
 	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("redundancy_report", duration, nil)
 
-	log.Printf("Redundancy report returned %d issues (%d critical, %d warning, %d info) in %v",
-		len(response.Issues), criticalCount, warningCount, infoCount, duration)
+	LoggerFromContext(ctx).Info("Redundancy report completed",
+		"issues", len(response.Issues), "critical", criticalCount, "warning", warningCount, "info", infoCount, "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
@@ -1708,7 +2364,7 @@ func GetMetroConnectivity(w http.ResponseWriter, r *http.Request) {
 	`
 	chRows, err := config.DB.Query(ctx, chQuery)
 	if err != nil {
-		log.Printf("Metro connectivity ClickHouse query error: %v", err)
+		LoggerFromContext(ctx).Error("Metro connectivity ClickHouse query error", "error", err)
 		response.Error = dberror.UserMessage(err)
 		writeJSON(w, response)
 		return
@@ -1734,7 +2390,8 @@ func GetMetroConnectivity(w http.ResponseWriter, r *http.Request) {
 
 	metroRecords, err := runNeo4jQuery(metroCypher)
 	if err != nil {
-		log.Printf("Metro connectivity metro query error: %v", err)
+		LoggerFromContext(ctx).Error("Metro connectivity metro query error", "error", err)
+		metrics.RecordNeo4jQuery("metro_connectivity", time.Since(start), err)
 		response.Error = dberror.UserMessage(err)
 		writeJSON(w, response)
 		return
@@ -1788,7 +2445,8 @@ func GetMetroConnectivity(w http.ResponseWriter, r *http.Request) {
 
 	connRecords, err := runNeo4jQuery(connectivityCypher)
 	if err != nil {
-		log.Printf("Metro connectivity query error: %v", err)
+		LoggerFromContext(ctx).Error("Metro connectivity query error", "error", err)
+		metrics.RecordNeo4jQuery("metro_connectivity", time.Since(start), err)
 		response.Error = dberror.UserMessage(err)
 		writeJSON(w, response)
 		return
@@ -1853,10 +2511,10 @@ func GetMetroConnectivity(w http.ResponseWriter, r *http.Request) {
 	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil) // Reuse existing metric for now
+	metrics.RecordNeo4jQuery("metro_connectivity", duration, nil)
 
-	log.Printf("Metro connectivity returned %d metros, %d connections in %v",
-		len(response.Metros), len(response.Connectivity), duration)
+	LoggerFromContext(ctx).Info("Metro connectivity completed",
+		"metros", len(response.Metros), "connections", len(response.Connectivity), "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
@@ -1901,8 +2559,12 @@ func GetMetroPathLatency(w http.ResponseWriter, r *http.Request) {
 
 	// Try cache first (cache only holds mainnet data)
 	if isMainnet(r.Context()) && statusCache != nil {
-		if cached := statusCache.GetMetroPathLatency(optimize); cached != nil {
+		if cached, age := statusCache.GetMetroPathLatency(optimize); cached != nil {
 			w.Header().Set("X-Cache", "HIT")
+			w.Header().Set("X-Cache-Age", strconv.Itoa(int(age.Seconds())))
+			if version := statusCache.CacheVersion(topologyCacheVersionType); version != "" {
+				w.Header().Set("X-Cache-Version", version)
+			}
 			writeJSON(w, cached)
 			return
 		}
@@ -1999,7 +2661,8 @@ func GetMetroPathLatency(w http.ResponseWriter, r *http.Request) {
 
 	result, err := session.Run(ctx, cypher, nil)
 	if err != nil {
-		log.Printf("Metro path latency query error: %v", err)
+		LoggerFromContext(ctx).Error("Metro path latency query error", "error", err)
+		metrics.RecordNeo4jQuery("metro_path_latency", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -2007,7 +2670,8 @@ func GetMetroPathLatency(w http.ResponseWriter, r *http.Request) {
 
 	records, err := result.Collect(ctx)
 	if err != nil {
-		log.Printf("Metro path latency collect error: %v", err)
+		LoggerFromContext(ctx).Error("Metro path latency collect error", "error", err)
+		metrics.RecordNeo4jQuery("metro_path_latency", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -2071,7 +2735,7 @@ func GetMetroPathLatency(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := safeQueryRows(ctx, internetQuery)
 	if err != nil {
-		log.Printf("Metro path latency internet query error: %v", err)
+		LoggerFromContext(ctx).Error("Metro path latency internet query error", "error", err)
 		response.Error = "failed to fetch internet latency data"
 		writeJSON(w, response)
 		return
@@ -2130,10 +2794,10 @@ func GetMetroPathLatency(w http.ResponseWriter, r *http.Request) {
 	response.Summary.MaxImprovementPct = maxImprovement
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("metro_path_latency", duration, nil)
 
-	log.Printf("Metro path latency (%s) returned %d paths in %v",
-		optimize, len(response.Paths), duration)
+	LoggerFromContext(ctx).Info("Metro path latency completed",
+		"optimize", optimize, "paths", len(response.Paths), "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
@@ -2345,8 +3009,8 @@ func fetchMetroPathLatencyData(ctx context.Context, optimize string) (*MetroPath
 	response.Summary.MaxImprovementPct = maxImprovement
 
 	duration := time.Since(start)
-	log.Printf("fetchMetroPathLatencyData (%s) returned %d paths in %v",
-		optimize, len(response.Paths), duration)
+	LoggerFromContext(ctx).Info("fetchMetroPathLatencyData completed",
+		"optimize", optimize, "paths", len(response.Paths), "duration_ms", duration.Milliseconds())
 
 	return response, nil
 }
@@ -2367,16 +3031,44 @@ type MetroPathDetailResponse struct {
 	FromMetroCode     string               `json:"fromMetroCode"`
 	ToMetroCode       string               `json:"toMetroCode"`
 	Optimize          string               `json:"optimize"`
+	Window            string               `json:"window"` // internet latency averaging window
 	TotalLatencyMs    float64              `json:"totalLatencyMs"`
 	TotalHops         int                  `json:"totalHops"`
 	BottleneckBwGbps  float64              `json:"bottleneckBwGbps"`
 	InternetLatencyMs float64              `json:"internetLatencyMs"`
 	ImprovementPct    *float64             `json:"improvementPct"`
 	Hops              []MetroPathDetailHop `json:"hops"`
-	Error             string               `json:"error,omitempty"`
+	// MeasuredAt is when the path was computed, or for historical requests
+	// the newest ISIS LSDB snapshot it was rebuilt from
+	MeasuredAt *time.Time `json:"measuredAt,omitempty"`
+	// DataFreshness is "fresh" for a new query, "cache" for a recent result
+	// reused, and "historical" for a path rebuilt as of ?at=
+	DataFreshness string `json:"dataFreshness,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// metroPathDetailCacheTTL is how long a current metro path detail is reused
+const metroPathDetailCacheTTL = 30 * time.Second
+
+type metroPathDetailCacheKey struct {
+	env                        DZEnv
+	from, to, optimize, window string
 }
 
-// GetMetroPathDetail returns detailed path breakdown between two metros
+type metroPathDetailCacheEntry struct {
+	response  MetroPathDetailResponse
+	fetchedAt time.Time
+}
+
+var (
+	metroPathDetailCache   = make(map[metroPathDetailCacheKey]metroPathDetailCacheEntry)
+	metroPathDetailCacheMu sync.RWMutex
+)
+
+// GetMetroPathDetail returns detailed path breakdown between two metros.
+// Internet latency is averaged over ?window= (1h, 6h or 24h, default 24h).
+// With ?historical=true&at=<RFC3339> the path is rebuilt from the ISIS LSDB
+// as it stood at that time, and the internet latency window ends there.
 func GetMetroPathDetail(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
@@ -2393,18 +3085,143 @@ func GetMetroPathDetail(w http.ResponseWriter, r *http.Request) {
 		optimize = "latency"
 	}
 
-	start := time.Now()
+	window := r.URL.Query().Get("window")
+	var windowDuration time.Duration
+	switch window {
+	case "1h":
+		windowDuration = time.Hour
+	case "6h":
+		windowDuration = 6 * time.Hour
+	case "", "24h":
+		window = "24h"
+		windowDuration = 24 * time.Hour
+	default:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "window must be one of 1h, 6h, 24h")
+		return
+	}
 
-	session := config.Neo4jSession(ctx)
-	defer session.Close(ctx)
+	var at time.Time
+	historical := r.URL.Query().Get("historical") == "true"
+	if historical {
+		var err error
+		at, err = time.Parse(time.RFC3339, r.URL.Query().Get("at"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "at must be an RFC3339 timestamp")
+			return
+		}
+	}
+
+	cacheKey := metroPathDetailCacheKey{env: EnvFromContext(ctx), from: fromCode, to: toCode, optimize: optimize, window: window}
+	if !historical {
+		metroPathDetailCacheMu.RLock()
+		entry, ok := metroPathDetailCache[cacheKey]
+		metroPathDetailCacheMu.RUnlock()
+		if ok && time.Since(entry.fetchedAt) < metroPathDetailCacheTTL {
+			response := entry.response
+			response.DataFreshness = "cache"
+			w.Header().Set("X-Cache", "HIT")
+			writeJSON(w, response)
+			return
+		}
+	}
 
 	response := MetroPathDetailResponse{
 		FromMetroCode: fromCode,
 		ToMetroCode:   toCode,
 		Optimize:      optimize,
+		Window:        window,
 		Hops:          []MetroPathDetailHop{},
 	}
 
+	windowEnd := time.Now().UTC()
+	if historical {
+		hops, reconstructedAt, err := loadHistoricalMetroPath(ctx, fromCode, toCode, optimize, at)
+		if err != nil {
+			LoggerFromContext(ctx).Error("Historical metro path detail query error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		if reconstructedAt.IsZero() {
+			response.Error = "No ISIS topology recorded before this time"
+			writeJSON(w, response)
+			return
+		}
+		response.MeasuredAt = &reconstructedAt
+		response.DataFreshness = "historical"
+		windowEnd = at
+		if len(hops) == 0 {
+			response.Error = "No path found between metros"
+			writeJSON(w, response)
+			return
+		}
+		response.Hops = hops
+	} else {
+		start := time.Now()
+		hops, err := queryMetroPathDetailHops(ctx, fromCode, toCode, optimize)
+		metrics.RecordNeo4jQuery("metro_path_detail", time.Since(start), err)
+		if err != nil {
+			LoggerFromContext(ctx).Error("Metro path detail query error", "error", err)
+			response.Error = err.Error()
+			writeJSON(w, response)
+			return
+		}
+		if len(hops) == 0 {
+			response.Error = "No path found between metros"
+			writeJSON(w, response)
+			return
+		}
+		response.Hops = hops
+		response.MeasuredAt = &windowEnd
+		response.DataFreshness = "fresh"
+	}
+
+	var totalMetric int64
+	for _, hop := range response.Hops {
+		totalMetric += hop.LinkMetric
+		if hop.LinkBwGbps > 0 && (response.BottleneckBwGbps == 0 || hop.LinkBwGbps < response.BottleneckBwGbps) {
+			response.BottleneckBwGbps = hop.LinkBwGbps
+		}
+	}
+	response.TotalLatencyMs = float64(totalMetric) / 1000.0
+	response.TotalHops = len(response.Hops) - 1
+
+	// Fetch internet latency for comparison
+	internetQuery := `
+		SELECT round(avg(f.rtt_us) / 1000.0, 2) AS avg_rtt_ms
+		FROM fact_dz_internet_metro_latency f
+		JOIN dz_metros_current ma ON f.origin_metro_pk = ma.pk
+		JOIN dz_metros_current mz ON f.target_metro_pk = mz.pk
+		WHERE f.event_ts > $3 AND f.event_ts <= $4
+		  AND ((ma.code = $1 AND mz.code = $2) OR (ma.code = $2 AND mz.code = $1))
+	`
+
+	var internetLatency float64
+	row := envDB(ctx).QueryRow(ctx, internetQuery, fromCode, toCode, windowEnd.Add(-windowDuration), windowEnd)
+	if err := row.Scan(&internetLatency); err == nil && internetLatency > 0 {
+		response.InternetLatencyMs = internetLatency
+		if response.TotalLatencyMs > 0 {
+			pct := (internetLatency - response.TotalLatencyMs) / internetLatency * 100
+			response.ImprovementPct = &pct
+		}
+	}
+
+	if !historical {
+		metroPathDetailCacheMu.Lock()
+		metroPathDetailCache[cacheKey] = metroPathDetailCacheEntry{response: response, fetchedAt: time.Now()}
+		metroPathDetailCacheMu.Unlock()
+		w.Header().Set("X-Cache", "MISS")
+	}
+
+	writeJSON(w, response)
+}
+
+// queryMetroPathDetailHops finds the best current path between two metros in
+// Neo4j, by ISIS metric for optimize=latency and by hop count otherwise. It
+// returns no hops if the metros aren't connected.
+func queryMetroPathDetailHops(ctx context.Context, fromCode, toCode, optimize string) ([]MetroPathDetailHop, error) {
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
 	// Build query based on optimization mode
 	var cypher string
 	if optimize == "latency" {
@@ -2454,29 +3271,14 @@ func GetMetroPathDetail(w http.ResponseWriter, r *http.Request) {
 		"to":   toCode,
 	})
 	if err != nil {
-		log.Printf("Metro path detail query error: %v", err)
-		response.Error = err.Error()
-		writeJSON(w, response)
-		return
+		return nil, err
 	}
-
 	records, err := result.Collect(ctx)
 	if err != nil {
-		log.Printf("Metro path detail collect error: %v", err)
-		response.Error = err.Error()
-		writeJSON(w, response)
-		return
+		return nil, err
 	}
 
-	if len(records) == 0 {
-		response.Error = "No path found between metros"
-		writeJSON(w, response)
-		return
-	}
-
-	var totalMetric int64
-	var minBandwidth float64 = 1e15
-
+	hops := make([]MetroPathDetailHop, 0, len(records))
 	for _, record := range records {
 		devicePK, _ := record.Get("devicePK")
 		deviceCode, _ := record.Get("deviceCode")
@@ -2486,55 +3288,17 @@ func GetMetroPathDetail(w http.ResponseWriter, r *http.Request) {
 		linkBw, _ := record.Get("linkBw")
 
 		metric := asInt64(linkMetric)
-		bw := asFloat64(linkBw)
-
-		hop := MetroPathDetailHop{
+		hops = append(hops, MetroPathDetailHop{
 			DevicePK:    asString(devicePK),
 			DeviceCode:  asString(deviceCode),
 			MetroPK:     asString(metroPK),
 			MetroCode:   asString(metroCode),
 			LinkMetric:  metric,
 			LinkLatency: float64(metric) / 1000.0, // Convert to ms
-			LinkBwGbps:  bw / 1e9,
-		}
-
-		response.Hops = append(response.Hops, hop)
-		totalMetric += metric
-		if bw > 0 && bw < minBandwidth {
-			minBandwidth = bw
-		}
-	}
-
-	response.TotalLatencyMs = float64(totalMetric) / 1000.0
-	response.TotalHops = len(response.Hops) - 1
-	if minBandwidth < 1e15 {
-		response.BottleneckBwGbps = minBandwidth / 1e9
-	}
-
-	// Fetch internet latency for comparison
-	internetQuery := `
-		SELECT round(avg(f.rtt_us) / 1000.0, 2) AS avg_rtt_ms
-		FROM fact_dz_internet_metro_latency f
-		JOIN dz_metros_current ma ON f.origin_metro_pk = ma.pk
-		JOIN dz_metros_current mz ON f.target_metro_pk = mz.pk
-		WHERE f.event_ts >= now() - INTERVAL 24 HOUR
-		  AND ((ma.code = $1 AND mz.code = $2) OR (ma.code = $2 AND mz.code = $1))
-	`
-
-	var internetLatency float64
-	row := envDB(ctx).QueryRow(ctx, internetQuery, fromCode, toCode)
-	if err := row.Scan(&internetLatency); err == nil && internetLatency > 0 {
-		response.InternetLatencyMs = internetLatency
-		if response.TotalLatencyMs > 0 {
-			pct := (internetLatency - response.TotalLatencyMs) / internetLatency * 100
-			response.ImprovementPct = &pct
-		}
+			LinkBwGbps:  asFloat64(linkBw) / 1e9,
+		})
 	}
-
-	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
-
-	writeJSON(w, response)
+	return hops, nil
 }
 
 // MetroPathsHop represents a device in a path
@@ -2578,7 +3342,7 @@ func GetMetroPaths(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if fromPK == "" || toPK == "" {
-		http.Error(w, "from and to metro PKs are required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "from and to metro PKs are required")
 		return
 	}
 
@@ -2764,6 +3528,20 @@ func PostMaintenanceImpact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response := analyzeMaintenanceImpact(ctx, req)
+
+	duration := time.Since(start)
+	metrics.RecordNeo4jQuery("maintenance_impact", duration, nil)
+
+	LoggerFromContext(ctx).Info("Maintenance impact completed",
+		"devices", len(req.Devices), "links", len(req.Links), "duration_ms", duration.Milliseconds())
+
+	writeJSON(w, response)
+}
+
+// analyzeMaintenanceImpact computes the impact of taking the requested devices
+// and links offline. Failures are reported in the response's Error field.
+func analyzeMaintenanceImpact(ctx context.Context, req MaintenanceImpactRequest) MaintenanceImpactResponse {
 	session := config.Neo4jSession(ctx)
 	defer session.Close(ctx)
 
@@ -2799,8 +3577,7 @@ func PostMaintenanceImpact(w http.ResponseWriter, r *http.Request) {
 		linkItems, err := analyzeLinksImpactBatch(ctx, session, req.Links)
 		if err != nil {
 			response.Error = fmt.Sprintf("failed to analyze links impact: %v", err)
-			writeJSON(w, response)
-			return
+			return response
 		}
 		for _, item := range linkItems {
 			response.Items = append(response.Items, item)
@@ -2847,13 +3624,7 @@ func PostMaintenanceImpact(w http.ResponseWriter, r *http.Request) {
 	// Compute affected metro pairs - simplified
 	response.AffectedMetros = computeAffectedMetrosFast(ctx, session, offlineDevicePKs)
 
-	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
-
-	log.Printf("Maintenance impact analyzed %d devices, %d links in %v",
-		len(req.Devices), len(req.Links), duration)
-
-	writeJSON(w, response)
+	return response
 }
 
 // getLinkEndpoints returns "sourcePK:targetPK" for a link PK
@@ -2908,7 +3679,7 @@ func analyzeDevicesImpactBatch(ctx context.Context, session neo4j.Session, devic
 		"devicePKs": devicePKs,
 	})
 	if err != nil {
-		log.Printf("Batch device impact query error: %v", err)
+		LoggerFromContext(ctx).Error("Batch device impact query error", "error", err)
 		// Fallback to individual queries
 		for _, pk := range devicePKs {
 			items = append(items, analyzeDeviceImpact(ctx, session, pk))
@@ -3212,7 +3983,7 @@ func computeAffectedPathsFast(ctx context.Context, session neo4j.Session,
 		"limit":            limit * 2, // Get more candidates, we'll filter
 	})
 	if err != nil {
-		log.Printf("Error computing affected paths: %v", err)
+		LoggerFromContext(ctx).Error("Error computing affected paths", "error", err)
 		return result
 	}
 
@@ -3354,7 +4125,7 @@ func computeAffectedMetrosFast(ctx context.Context, session neo4j.Session,
 		"offlineDevicePKs": offlineDevicePKs,
 	})
 	if err != nil {
-		log.Printf("Error computing affected metros fast: %v", err)
+		LoggerFromContext(ctx).Error("Error computing affected metros fast", "error", err)
 		return result
 	}
 
@@ -3507,6 +4278,10 @@ type MetroDevicePathsResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
+// metroDevicePathWorkers is the number of concurrent path queries (and Neo4j
+// sessions) used by GetMetroDevicePaths.
+const metroDevicePathWorkers = 10
+
 // GetMetroDevicePaths returns all paths between devices in two metros
 // Query params: from (metro PK), to (metro PK), mode (hops|latency)
 func GetMetroDevicePaths(w http.ResponseWriter, r *http.Request) {
@@ -3560,7 +4335,8 @@ func GetMetroDevicePaths(w http.ResponseWriter, r *http.Request) {
 		"toPK":   toMetroPK,
 	})
 	if err != nil {
-		log.Printf("Metro device paths metro query error: %v", err)
+		LoggerFromContext(ctx).Error("Metro device paths metro query error", "error", err)
+		metrics.RecordNeo4jQuery("metro_device_paths", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -3568,7 +4344,7 @@ func GetMetroDevicePaths(w http.ResponseWriter, r *http.Request) {
 
 	record, err := result.Single(ctx)
 	if err != nil {
-		log.Printf("Metro device paths metro query no result: %v", err)
+		LoggerFromContext(ctx).Error("Metro device paths metro query no result", "error", err)
 		response.Error = "One or both metros not found"
 		writeJSON(w, response)
 		return
@@ -3616,116 +4392,144 @@ func GetMetroDevicePaths(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build list of all device pairs and find paths
+	type pairJob struct {
+		sourceIdx int
+		targetIdx int
+	}
+	// noPath marks pairs without a path; err is set when the query itself
+	// failed, e.g. when no Neo4j session could be acquired
 	type pathResult struct {
 		sourceIdx int
 		targetIdx int
 		path      SinglePath
+		noPath    bool
 		err       error
 	}
 
-	// Use a channel to collect results from goroutines
-	resultChan := make(chan pathResult, len(sourceDevices)*len(targetDevices))
-
-	// Semaphore to limit concurrent goroutines
-	sem := make(chan struct{}, 10)
-
-	// Find shortest path for each device pair
-	for i, source := range sourceDevices {
-		for j, target := range targetDevices {
-			i, j := i, j
-			source, target := source, target
-
-			go func() {
-				sem <- struct{}{}        // Acquire
-				defer func() { <-sem }() // Release
-
-				// Use a fresh context for each query
-				queryCtx, queryCancel := context.WithTimeout(ctx, 5*time.Second)
-				defer queryCancel()
-
-				querySession := config.Neo4jSession(queryCtx)
-				defer querySession.Close(queryCtx)
-
-				var cypher string
-				if mode == "latency" {
-					cypher = `
-						MATCH (a:Device {pk: $from_pk}), (b:Device {pk: $to_pk})
-						CALL apoc.algo.dijkstra(a, b, 'ISIS_ADJACENT>', 'metric') YIELD path, weight
-						WITH path, toInteger(weight) AS totalMetric
-						RETURN [n IN nodes(path) | {
-							pk: n.pk,
-							code: n.code,
-							status: n.status,
-							device_type: n.device_type
-						}] AS devices,
-						[r IN relationships(path) | r.metric] AS edgeMetrics,
-						totalMetric
-					`
-				} else {
-					cypher = `
-						MATCH (a:Device {pk: $from_pk}), (b:Device {pk: $to_pk})
-						MATCH path = shortestPath((a)-[:ISIS_ADJACENT*]->(b))
-						WITH path, reduce(total = 0, r IN relationships(path) | total + coalesce(r.metric, 0)) AS totalMetric
-						RETURN [n IN nodes(path) | {
-							pk: n.pk,
-							code: n.code,
-							status: n.status,
-							device_type: n.device_type
-						}] AS devices,
-						[r IN relationships(path) | r.metric] AS edgeMetrics,
-						totalMetric
-					`
-				}
+	var cypher string
+	if mode == "latency" {
+		cypher = `
+			MATCH (a:Device {pk: $from_pk}), (b:Device {pk: $to_pk})
+			CALL apoc.algo.dijkstra(a, b, 'ISIS_ADJACENT>', 'metric') YIELD path, weight
+			WITH path, toInteger(weight) AS totalMetric
+			RETURN [n IN nodes(path) | {
+				pk: n.pk,
+				code: n.code,
+				status: n.status,
+				device_type: n.device_type
+			}] AS devices,
+			[r IN relationships(path) | r.metric] AS edgeMetrics,
+			totalMetric
+		`
+	} else {
+		cypher = `
+			MATCH (a:Device {pk: $from_pk}), (b:Device {pk: $to_pk})
+			MATCH path = shortestPath((a)-[:ISIS_ADJACENT*]->(b))
+			WITH path, reduce(total = 0, r IN relationships(path) | total + coalesce(r.metric, 0)) AS totalMetric
+			RETURN [n IN nodes(path) | {
+				pk: n.pk,
+				code: n.code,
+				status: n.status,
+				device_type: n.device_type
+			}] AS devices,
+			[r IN relationships(path) | r.metric] AS edgeMetrics,
+			totalMetric
+		`
+	}
 
-				pathRes, err := querySession.Run(queryCtx, cypher, map[string]any{
-					"from_pk": source.PK,
-					"to_pk":   target.PK,
-				})
-				if err != nil {
-					resultChan <- pathResult{sourceIdx: i, targetIdx: j, err: err}
-					return
-				}
+	findPath := func(querySession neo4j.Session, job pairJob) pathResult {
+		// Use a fresh context for each query
+		queryCtx, queryCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer queryCancel()
 
-				pathRecord, err := pathRes.Single(queryCtx)
-				if err != nil {
-					resultChan <- pathResult{sourceIdx: i, targetIdx: j, err: err}
-					return
-				}
+		pathRes, err := querySession.Run(queryCtx, cypher, map[string]any{
+			"from_pk": sourceDevices[job.sourceIdx].PK,
+			"to_pk":   targetDevices[job.targetIdx].PK,
+		})
+		if err != nil {
+			return pathResult{sourceIdx: job.sourceIdx, targetIdx: job.targetIdx, err: err}
+		}
 
-				devicesVal, _ := pathRecord.Get("devices")
-				edgeMetricsVal, _ := pathRecord.Get("edgeMetrics")
-				totalMetric, _ := pathRecord.Get("totalMetric")
+		pathRecord, err := pathRes.Single(queryCtx)
+		if err != nil {
+			return pathResult{sourceIdx: job.sourceIdx, targetIdx: job.targetIdx, noPath: true}
+		}
 
-				hops := parseNodeListWithMetrics(devicesVal, edgeMetricsVal)
+		devicesVal, _ := pathRecord.Get("devices")
+		edgeMetricsVal, _ := pathRecord.Get("edgeMetrics")
+		totalMetric, _ := pathRecord.Get("totalMetric")
 
-				resultChan <- pathResult{
-					sourceIdx: i,
-					targetIdx: j,
-					path: SinglePath{
-						Path:        hops,
-						TotalMetric: uint32(asInt64(totalMetric)),
-						HopCount:    len(hops) - 1,
-					},
-				}
-			}()
+		hops := parseNodeListWithMetrics(devicesVal, edgeMetricsVal)
+
+		return pathResult{
+			sourceIdx: job.sourceIdx,
+			targetIdx: job.targetIdx,
+			path: SinglePath{
+				Path:        hops,
+				TotalMetric: uint32(asInt64(totalMetric)),
+				HopCount:    len(hops) - 1,
+			},
 		}
 	}
 
-	// Collect all results
+	// Queue every device pair up front, then run a fixed number of workers
+	// that each hold one Neo4j session for all of their queries.
 	expectedResults := len(sourceDevices) * len(targetDevices)
-	results := make([]pathResult, 0, expectedResults)
-	for k := 0; k < expectedResults; k++ {
-		results = append(results, <-resultChan)
+	jobs := make(chan pairJob, expectedResults)
+	for i := range sourceDevices {
+		for j := range targetDevices {
+			jobs <- pairJob{sourceIdx: i, targetIdx: j}
+		}
 	}
+	close(jobs)
+
+	workers := min(metroDevicePathWorkers, expectedResults)
+	resultChan := make(chan pathResult, expectedResults)
+
+	// The first worker reuses the request's session, which it already holds,
+	// and the rest acquire their own: a request holds at most
+	// metroDevicePathWorkers sessions, and nothing waits for a session while
+	// holding another.
+	runWorker := func(workerSession neo4j.Session) {
+		for job := range jobs {
+			resultChan <- findPath(workerSession, job)
+		}
+	}
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i == 0 {
+				runWorker(session)
+				return
+			}
+			workerSession := config.Neo4jSession(ctx)
+			defer workerSession.Close(ctx)
+			runWorker(workerSession)
+		}()
+	}
+	wg.Wait()
 	close(resultChan)
 
+	// Collect all results
+	results := make([]pathResult, 0, expectedResults)
+	for res := range resultChan {
+		if res.err != nil {
+			LoggerFromContext(ctx).Error("Metro device paths query error", "error", res.err)
+			metrics.RecordNeo4jQuery("metro_device_paths", time.Since(start), res.err)
+			writeDBError(w, r, res.err)
+			return
+		}
+		results = append(results, res)
+	}
+
 	// Build device pair paths from results
 	var totalLatencyMs float64
 	var pathCount int
 
 	for _, res := range results {
-		if res.err != nil {
-			// No path found, skip this pair
+		if res.noPath {
 			continue
 		}
 
@@ -3782,7 +4586,7 @@ func GetMetroDevicePaths(w http.ResponseWriter, r *http.Request) {
 			multiPathResp.Paths[i] = pair.BestPath
 		}
 		if err := enrichPathsWithMeasuredLatency(ctx, multiPathResp); err != nil {
-			log.Printf("enrichPathsWithMeasuredLatency error for metro paths: %v", err)
+			LoggerFromContext(ctx).Error("enrichPathsWithMeasuredLatency error for metro paths", "error", err)
 		} else {
 			// Copy enriched paths back
 			for i := range response.DevicePairs {
@@ -3838,10 +4642,10 @@ func GetMetroDevicePaths(w http.ResponseWriter, r *http.Request) {
 	})
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("metro_device_paths", duration, nil)
 
-	log.Printf("GetMetroDevicePaths %s->%s (%s mode): %d pairs in %v",
-		response.FromMetroCode, response.ToMetroCode, mode, response.TotalPairs, duration)
+	LoggerFromContext(ctx).Info("GetMetroDevicePaths completed",
+		"from_metro", response.FromMetroCode, "to_metro", response.ToMetroCode, "mode", mode, "pairs", response.TotalPairs, "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// maxISISChangeEvents caps the number of adjacency events returned in one response
+const maxISISChangeEvents = 5000
+
+// ISISChangeEvent is an IS-IS adjacency between two devices coming up or going down
+type ISISChangeEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	FromPK    string    `json:"fromPK"`
+	FromCode  string    `json:"fromCode"`
+	ToPK      string    `json:"toPK"`
+	ToCode    string    `json:"toCode"`
+	EventType string    `json:"eventType"` // "up" or "down"
+	Metric    uint32    `json:"metric"`
+}
+
+// parseISISEventRange parses the RFC3339 start and end query parameters,
+// defaulting to the 24 hours before end (or now).
+func parseISISEventRange(r *http.Request) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if s := r.URL.Query().Get("end"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("end must be an RFC3339 timestamp")
+		}
+		end = t
+	}
+	start := end.Add(-24 * time.Hour)
+	if s := r.URL.Query().Get("start"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("start must be an RFC3339 timestamp")
+		}
+		start = t
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New("end must be after start")
+	}
+	return start, end, nil
+}
+
+// GetISISChanges returns IS-IS adjacency up/down events from
+// fact_isis_adjacency_events, newest first. Accepts RFC3339 start and end
+// (default: the last 24 hours) and an optional device_pk matching either side
+// of the adjacency.
+func GetISISChanges(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	start, end, err := parseISISEventRange(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	devicePK := r.URL.Query().Get("device_pk")
+
+	queryStart := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT
+			e.event_ts,
+			e.from_pk,
+			COALESCE(fd.code, '') AS from_code,
+			e.to_pk,
+			COALESCE(td.code, '') AS to_code,
+			e.event_type,
+			e.metric
+		FROM fact_isis_adjacency_events e FINAL
+		LEFT JOIN dz_devices_current fd ON e.from_pk = fd.pk
+		LEFT JOIN dz_devices_current td ON e.to_pk = td.pk
+		WHERE e.event_ts >= ? AND e.event_ts < ?
+		  AND (? = '' OR e.from_pk = ? OR e.to_pk = ?)
+		ORDER BY e.event_ts DESC, e.from_pk, e.to_pk
+		LIMIT ?
+	`, start, end, devicePK, devicePK, devicePK, maxISISChangeEvents)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+		LoggerFromContext(ctx).Error("ISIS changes query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	events := []ISISChangeEvent{}
+	for rows.Next() {
+		var e ISISChangeEvent
+		if err := rows.Scan(&e.Timestamp, &e.FromPK, &e.FromCode, &e.ToPK, &e.ToCode, &e.EventType, &e.Metric); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+			LoggerFromContext(ctx).Error("ISIS changes scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		e.Timestamp = e.Timestamp.UTC()
+		events = append(events, e)
+	}
+	err = rows.Err()
+	metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("ISIS changes rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, events)
+}
+
+// DeviceISISAdjacencyEvent is an adjacency of a device to a neighbor coming up
+// or going down
+type DeviceISISAdjacencyEvent struct {
+	Timestamp    time.Time `json:"timestamp"`
+	NeighborPK   string    `json:"neighborPK"`
+	NeighborCode string    `json:"neighborCode"`
+	EventType    string    `json:"eventType"` // "up" or "down"
+	Metric       uint32    `json:"metric"`
+}
+
+// DeviceISISAdjacencyHistoryResponse is the response for the device IS-IS
+// adjacency history endpoint
+type DeviceISISAdjacencyHistoryResponse struct {
+	DevicePK             string                     `json:"devicePK"`
+	Start                time.Time                  `json:"start"`
+	End                  time.Time                  `json:"end"`
+	Events               []DeviceISISAdjacencyEvent `json:"events"`
+	AdjacencyFlaps       int                        `json:"adjacencyFlaps"`       // down->up cycles across all neighbors
+	LongestOutageSeconds float64                    `json:"longestOutageSeconds"` // longest time any adjacency was down, clipped to the window
+}
+
+// GetDeviceISISAdjacencyHistory returns the IS-IS adjacency up/down events
+// between a device and its neighbors, newest first, with flap and outage
+// totals. Events reported from either side of an adjacency are included.
+// Accepts the same start and end parameters as GetISISChanges.
+func GetDeviceISISAdjacencyHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
+		return
+	}
+	start, end, err := parseISISEventRange(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	queryStart := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT
+			e.event_ts,
+			if(e.from_pk = ?, e.to_pk, e.from_pk) AS neighbor_pk,
+			COALESCE(nd.code, '') AS neighbor_code,
+			e.event_type,
+			e.metric
+		FROM fact_isis_adjacency_events e FINAL
+		LEFT JOIN dz_devices_current nd ON nd.pk = if(e.from_pk = ?, e.to_pk, e.from_pk)
+		WHERE e.event_ts >= ? AND e.event_ts < ?
+		  AND (e.from_pk = ? OR e.to_pk = ?)
+		ORDER BY e.event_ts DESC, neighbor_pk
+		LIMIT ?
+	`, pk, pk, start, end, pk, pk, maxISISChangeEvents)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+		LoggerFromContext(ctx).Error("Device ISIS adjacency history query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	events := []DeviceISISAdjacencyEvent{}
+	for rows.Next() {
+		var e DeviceISISAdjacencyEvent
+		if err := rows.Scan(&e.Timestamp, &e.NeighborPK, &e.NeighborCode, &e.EventType, &e.Metric); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+			LoggerFromContext(ctx).Error("Device ISIS adjacency history scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		e.Timestamp = e.Timestamp.UTC()
+		events = append(events, e)
+	}
+	err = rows.Err()
+	metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Device ISIS adjacency history rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	windowEnd := end
+	if now := time.Now().UTC(); now.Before(windowEnd) {
+		windowEnd = now
+	}
+	flaps, longest := adjacencyOutageStats(events, windowEnd)
+
+	writeJSON(w, DeviceISISAdjacencyHistoryResponse{
+		DevicePK:             pk,
+		Start:                start.UTC(),
+		End:                  end.UTC(),
+		Events:               events,
+		AdjacencyFlaps:       flaps,
+		LongestOutageSeconds: longest.Seconds(),
+	})
+}
+
+// adjacencyOutageStats counts down->up cycles per neighbor and finds the
+// longest outage, with an adjacency still down at the end counted as down
+// until end. Repeated events of the same type (one from each side of the
+// adjacency) are ignored. A first "up" in the window isn't an outage, since
+// it may be a new adjacency.
+func adjacencyOutageStats(events []DeviceISISAdjacencyEvent, end time.Time) (int, time.Duration) {
+	sorted := make([]DeviceISISAdjacencyEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	type neighborState struct {
+		down      bool
+		downSince time.Time
+	}
+	states := make(map[string]*neighborState)
+
+	var flaps int
+	var longest time.Duration
+	for _, e := range sorted {
+		st := states[e.NeighborPK]
+		if st == nil {
+			st = &neighborState{}
+			states[e.NeighborPK] = st
+		}
+		switch e.EventType {
+		case "down":
+			if !st.down {
+				st.down = true
+				st.downSince = e.Timestamp
+			}
+		case "up":
+			if st.down {
+				flaps++
+				longest = max(longest, e.Timestamp.Sub(st.downSince))
+				st.down = false
+			}
+		}
+	}
+	for _, st := range states {
+		if st.down {
+			longest = max(longest, end.Sub(st.downSince))
+		}
+	}
+	return flaps, longest
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjacencyOutageStats(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return t0.Add(time.Duration(minutes) * time.Minute) }
+	event := func(minutes int, neighbor, eventType string) DeviceISISAdjacencyEvent {
+		return DeviceISISAdjacencyEvent{Timestamp: at(minutes), NeighborPK: neighbor, EventType: eventType}
+	}
+
+	t.Run("flaps and longest outage", func(t *testing.T) {
+		// Newest first, as the handler returns them
+		events := []DeviceISISAdjacencyEvent{
+			event(50, "a", "up"),
+			event(40, "a", "down"),
+			event(30, "b", "up"),
+			event(10, "b", "down"),
+			event(5, "a", "up"),
+			event(0, "a", "down"),
+		}
+		flaps, longest := adjacencyOutageStats(events, at(60))
+		assert.Equal(t, 3, flaps)
+		assert.Equal(t, 20*time.Minute, longest)
+	})
+
+	t.Run("duplicate events from both sides", func(t *testing.T) {
+		events := []DeviceISISAdjacencyEvent{
+			event(10, "a", "up"),
+			event(10, "a", "up"),
+			event(0, "a", "down"),
+			event(0, "a", "down"),
+		}
+		flaps, longest := adjacencyOutageStats(events, at(60))
+		assert.Equal(t, 1, flaps)
+		assert.Equal(t, 10*time.Minute, longest)
+	})
+
+	t.Run("still down at end", func(t *testing.T) {
+		flaps, longest := adjacencyOutageStats([]DeviceISISAdjacencyEvent{event(15, "a", "down")}, at(60))
+		assert.Equal(t, 0, flaps)
+		assert.Equal(t, 45*time.Minute, longest)
+	})
+
+	t.Run("new adjacency is not an outage", func(t *testing.T) {
+		flaps, longest := adjacencyOutageStats([]DeviceISISAdjacencyEvent{event(15, "a", "up")}, at(60))
+		assert.Equal(t, 0, flaps)
+		assert.Zero(t, longest)
+	})
+}
@@ -0,0 +1,226 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedISISChanges inserts devices CHI-IC, NYC-IC and LON-IC, with the CHI-NYC
+// adjacency flapping 3h ago and NYC-LON coming up 30h ago.
+func seedISISChanges(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('chi-ic', now(), now(), generateUUIDv4(), 0, 1, 'chi-ic', 'activated', 'hybrid', 'CHI-IC', '', '', '', 0),
+		('nyc-ic', now(), now(), generateUUIDv4(), 0, 1, 'nyc-ic', 'activated', 'hybrid', 'NYC-IC', '', '', '', 0),
+		('lon-ic', now(), now(), generateUUIDv4(), 0, 1, 'lon-ic', 'activated', 'hybrid', 'LON-IC', '', '', '', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_isis_adjacency_events
+		(event_ts, ingested_at, from_pk, to_pk, event_type, metric)
+		VALUES
+		(now() - INTERVAL 30 HOUR, now(), 'nyc-ic', 'lon-ic', 'up', 7000),
+		(now() - INTERVAL 3 HOUR, now(), 'chi-ic', 'nyc-ic', 'down', 1000),
+		(now() - INTERVAL 2 HOUR, now(), 'chi-ic', 'nyc-ic', 'up', 1200)`))
+}
+
+func getISISChanges(t *testing.T, query url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/isis-changes?"+query.Encode(), nil)
+	rr := httptest.NewRecorder()
+	handlers.GetISISChanges(rr, req)
+	return rr
+}
+
+func TestGetISISChanges(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISChanges(t)
+
+	// Defaults to the last 24 hours, newest first
+	rr := getISISChanges(t, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var events []handlers.ISISChangeEvent
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&events))
+	require.Len(t, events, 2)
+	assert.Equal(t, "up", events[0].EventType)
+	assert.Equal(t, "CHI-IC", events[0].FromCode)
+	assert.Equal(t, "NYC-IC", events[0].ToCode)
+	assert.Equal(t, uint32(1200), events[0].Metric)
+	assert.Equal(t, "down", events[1].EventType)
+	assert.Equal(t, uint32(1000), events[1].Metric)
+
+	// An explicit range and device filter match either side of the adjacency
+	rr = getISISChanges(t, url.Values{
+		"start":     {time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)},
+		"device_pk": {"lon-ic"},
+	})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	events = nil
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&events))
+	require.Len(t, events, 1)
+	assert.Equal(t, "NYC-IC", events[0].FromCode)
+	assert.Equal(t, "LON-IC", events[0].ToCode)
+}
+
+func TestGetISISChanges_Empty(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	rr := getISISChanges(t, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `[]`, rr.Body.String())
+}
+
+func TestGetISISChanges_InvalidParams(t *testing.T) {
+	now := time.Now().UTC()
+	for name, query := range map[string]url.Values{
+		"invalid start":    {"start": {"yesterday"}},
+		"invalid end":      {"end": {"now"}},
+		"end before start": {"start": {now.Format(time.RFC3339)}, "end": {now.Add(-time.Hour).Format(time.RFC3339)}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rr := getISISChanges(t, query)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func getDeviceISISAdjacencyHistory(t *testing.T, pk string, query url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/devices/"+pk+"/isis-adjacency-history?"+query.Encode(), nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetDeviceISISAdjacencyHistory(rr, req)
+	return rr
+}
+
+func TestGetDeviceISISAdjacencyHistory(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISChanges(t)
+
+	// NYC-IC is the "to" side of the CHI-NYC flap and the "from" side of NYC-LON
+	rr := getDeviceISISAdjacencyHistory(t, "nyc-ic", url.Values{
+		"start": {time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)},
+	})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.DeviceISISAdjacencyHistoryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "nyc-ic", resp.DevicePK)
+	require.Len(t, resp.Events, 3)
+	assert.Equal(t, "chi-ic", resp.Events[0].NeighborPK)
+	assert.Equal(t, "CHI-IC", resp.Events[0].NeighborCode)
+	assert.Equal(t, "up", resp.Events[0].EventType)
+	assert.Equal(t, uint32(1200), resp.Events[0].Metric)
+	assert.Equal(t, "down", resp.Events[1].EventType)
+	assert.Equal(t, "LON-IC", resp.Events[2].NeighborCode)
+
+	assert.Equal(t, 1, resp.AdjacencyFlaps)
+	assert.InDelta(t, 3600, resp.LongestOutageSeconds, 5)
+}
+
+func TestGetDeviceISISAdjacencyHistory_Empty(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	rr := getDeviceISISAdjacencyHistory(t, "unknown", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.DeviceISISAdjacencyHistoryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.NotNil(t, resp.Events)
+	assert.Empty(t, resp.Events)
+	assert.Zero(t, resp.AdjacencyFlaps)
+	assert.Zero(t, resp.LongestOutageSeconds)
+}
+
+func TestGetDeviceISISAdjacencyHistory_InvalidParams(t *testing.T) {
+	rr := getDeviceISISAdjacencyHistory(t, "nyc-ic", url.Values{"start": {"yesterday"}})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// seedMetroConnectivity inserts AMS with two devices, FRA and LON with one
+// each, and adjacency events: AMS gains FRA twice over but loses it only
+// when both adjacencies go down, and AMS-LON comes up in between
+func seedMetroConnectivity(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_metros_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name, longitude, latitude)
+		VALUES
+		('metro-ams', now(), now(), generateUUIDv4(), 0, 1, 'metro-ams', 'ams', 'Amsterdam', 4.9, 52.4),
+		('metro-fra', now(), now(), generateUUIDv4(), 0, 2, 'metro-fra', 'fra', 'Frankfurt', 8.7, 50.1),
+		('metro-lon', now(), now(), generateUUIDv4(), 0, 3, 'metro-lon', 'lon', 'London', -0.1, 51.5)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('ams1', now(), now(), generateUUIDv4(), 0, 1, 'ams1', 'activated', 'hybrid', 'AMS1', '', '', 'metro-ams', 0),
+		('ams2', now(), now(), generateUUIDv4(), 0, 2, 'ams2', 'activated', 'hybrid', 'AMS2', '', '', 'metro-ams', 0),
+		('fra1', now(), now(), generateUUIDv4(), 0, 3, 'fra1', 'activated', 'hybrid', 'FRA1', '', '', 'metro-fra', 0),
+		('lon1', now(), now(), generateUUIDv4(), 0, 4, 'lon1', 'activated', 'hybrid', 'LON1', '', '', 'metro-lon', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_isis_adjacency_events
+		(event_ts, ingested_at, from_pk, to_pk, event_type, metric)
+		VALUES
+		(now() - INTERVAL 5 HOUR, now(), 'ams1', 'fra1', 'up', 1000),
+		(now() - INTERVAL 5 HOUR, now(), 'fra1', 'ams1', 'up', 1000),
+		(now() - INTERVAL 4 HOUR, now(), 'fra1', 'ams2', 'up', 1000),
+		(now() - INTERVAL 3 HOUR, now(), 'lon1', 'ams1', 'up', 2000),
+		(now() - INTERVAL 2 HOUR, now(), 'ams1', 'fra1', 'down', 1000),
+		(now() - INTERVAL 1 HOUR, now(), 'ams2', 'fra1', 'down', 1000),
+		(now() - INTERVAL 1 HOUR, now(), 'ams1', 'ams2', 'down', 10)`))
+}
+
+func getMetroConnectivityHistory(pk string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/metros/"+pk+"/connectivity-history", nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetMetroConnectivityHistory(rr, req)
+	return rr
+}
+
+func TestGetMetroConnectivityHistory(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedMetroConnectivity(t)
+
+	rr := getMetroConnectivityHistory("metro-ams")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var events []handlers.MetroConnectivityEvent
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&events))
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "gained", events[0].EventType)
+	assert.Equal(t, "metro-fra", events[0].TargetMetroPK)
+	assert.Equal(t, "fra", events[0].TargetMetroCode)
+	assert.Equal(t, "fra1", events[0].AffectedDevicePK)
+	assert.Equal(t, "FRA1", events[0].AffectedDeviceCode)
+
+	assert.Equal(t, "gained", events[1].EventType)
+	assert.Equal(t, "metro-lon", events[1].TargetMetroPK)
+	assert.Equal(t, "lon1", events[1].AffectedDevicePK)
+
+	assert.Equal(t, "lost", events[2].EventType)
+	assert.Equal(t, "metro-fra", events[2].TargetMetroPK)
+	assert.True(t, events[2].Timestamp.After(events[1].Timestamp))
+}
+
+func TestGetMetroConnectivityHistory_NotFound(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	rr := getMetroConnectivityHistory("nonexistent")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// isisGraphNode is a device in an in-memory copy of the ISIS topology
+type isisGraphNode struct {
+	PK      string
+	Code    string
+	Status  string
+	MetroPK string
+}
+
+// isisGraphEdge is one direction of an undirected ISIS adjacency
+type isisGraphEdge struct {
+	to     int
+	weight int64
+}
+
+// isisGraph is an undirected, metric-weighted copy of the ISIS topology for
+// algorithms that are easier to run in Go than in Cypher.
+type isisGraph struct {
+	nodes []isisGraphNode
+	index map[string]int
+	adj   [][]isisGraphEdge
+}
+
+// loadISISGraph loads all ISIS devices and adjacencies from Neo4j. Parallel
+// adjacencies between the same devices are collapsed to the lowest metric.
+func loadISISGraph(ctx context.Context) (*isisGraph, error) {
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	devicesCypher := `
+		MATCH (d:Device)
+		WHERE d.isis_system_id IS NOT NULL
+		OPTIONAL MATCH (d)-[:LOCATED_IN]->(m:Metro)
+		RETURN d.pk AS pk,
+		       d.code AS code,
+		       d.status AS status,
+		       m.pk AS metroPK
+		ORDER BY d.code
+	`
+	result, err := session.Run(ctx, devicesCypher, nil)
+	if err != nil {
+		return nil, err
+	}
+	deviceRecords, err := result.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	edgesCypher := `
+		MATCH (a:Device)-[r:ISIS_ADJACENT]-(b:Device)
+		WHERE a.isis_system_id IS NOT NULL
+		  AND b.isis_system_id IS NOT NULL
+		  AND a.pk < b.pk
+		RETURN a.pk AS sourcePK,
+		       b.pk AS targetPK,
+		       min(r.metric) AS metric
+	`
+	result, err = session.Run(ctx, edgesCypher, nil)
+	if err != nil {
+		return nil, err
+	}
+	edgeRecords, err := result.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &isisGraph{
+		nodes: make([]isisGraphNode, 0, len(deviceRecords)),
+		index: make(map[string]int, len(deviceRecords)),
+	}
+	for _, record := range deviceRecords {
+		pk, _ := record.Get("pk")
+		code, _ := record.Get("code")
+		status, _ := record.Get("status")
+		metroPK, _ := record.Get("metroPK")
+
+		g.index[asString(pk)] = len(g.nodes)
+		g.nodes = append(g.nodes, isisGraphNode{
+			PK:      asString(pk),
+			Code:    asString(code),
+			Status:  asString(status),
+			MetroPK: asString(metroPK),
+		})
+	}
+
+	g.adj = make([][]isisGraphEdge, len(g.nodes))
+	for _, record := range edgeRecords {
+		sourcePK, _ := record.Get("sourcePK")
+		targetPK, _ := record.Get("targetPK")
+		metric, _ := record.Get("metric")
+
+		a, okA := g.index[asString(sourcePK)]
+		b, okB := g.index[asString(targetPK)]
+		if !okA || !okB {
+			continue
+		}
+		weight := asInt64(metric)
+		if weight <= 0 {
+			weight = 1
+		}
+		g.adj[a] = append(g.adj[a], isisGraphEdge{to: b, weight: weight})
+		g.adj[b] = append(g.adj[b], isisGraphEdge{to: a, weight: weight})
+	}
+
+	return g, nil
+}
+
+// edgeWeight returns the metric of the a-z adjacency, or -1 if there is none
+func edgeWeight(adj [][]isisGraphEdge, a, z int) int64 {
+	weight := int64(-1)
+	for _, e := range adj[a] {
+		if e.to == z && (weight < 0 || e.weight < weight) {
+			weight = e.weight
+		}
+	}
+	return weight
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTraceroute(t *testing.T) {
+	path := PathResponse{
+		Path: []PathHop{
+			{DevicePK: "a", DeviceCode: "NYC1", MetroCode: "NYC"},
+			{DevicePK: "b", DeviceCode: "NYC2", MetroCode: "NYC", EdgeMetric: 500},
+			{DevicePK: "c", DeviceCode: "CHI1", EdgeMetric: 18000},
+			{DevicePK: "d", DeviceCode: "LAX1", MetroCode: "LAX", EdgeMetric: 30000},
+		},
+		TotalMetric: 48500,
+		HopCount:    3,
+	}
+
+	t.Run("measured", func(t *testing.T) {
+		measured := pathToSinglePath(path)
+		measured.Path[1].EdgeMeasuredMs, measured.Path[1].EdgeJitterMs, measured.Path[1].EdgeSampleCount = 0.48, 0.02, 10
+		measured.Path[2].EdgeMeasuredMs, measured.Path[2].EdgeJitterMs, measured.Path[2].EdgeSampleCount = 17.5, 0.3, 10
+		measured.Path[3].EdgeMeasuredMs, measured.Path[3].EdgeJitterMs, measured.Path[3].EdgeSampleCount = 29, 1.25, 10
+
+		assert.Equal(t, "traceroute from NYC1 to LAX1, 3 hops\n"+
+			" 1  NYC2  NYC  0.50ms  0.48ms (±0.02)\n"+
+			" 2  CHI1  -  18.50ms  17.98ms (±0.30)\n"+
+			" 3  LAX1  LAX  48.50ms  46.98ms (±1.25)\n",
+			formatTraceroute(path, measured))
+	})
+
+	t.Run("unmeasured hop", func(t *testing.T) {
+		measured := pathToSinglePath(path)
+		measured.Path[1].EdgeMeasuredMs, measured.Path[1].EdgeSampleCount = 0.48, 10
+		measured.Path[3].EdgeMeasuredMs, measured.Path[3].EdgeSampleCount = 29, 10
+
+		assert.Equal(t, "traceroute from NYC1 to LAX1, 3 hops\n"+
+			" 1  NYC2  NYC  0.50ms  0.48ms (±0.00)\n"+
+			" 2  CHI1  -  18.50ms  *\n"+
+			" 3  LAX1  LAX  48.50ms  *\n",
+			formatTraceroute(path, measured))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		assert.Equal(t, "traceroute: No path found between devices\n",
+			formatTraceroute(PathResponse{Error: "No path found between devices"}, SinglePath{}))
+	})
+}
+
+func TestEqualCostPaths(t *testing.T) {
+	// 0 -> 1 -> 4 costs 20 and 0 -> 2 -> 3 -> 4 costs 20 too, so both are
+	// equal-cost despite the different hop counts. 0 -> 4 directly costs 25.
+	adj := [][]isisGraphEdge{
+		{{to: 1, weight: 10}, {to: 2, weight: 5}, {to: 4, weight: 25}},
+		{{to: 4, weight: 10}},
+		{{to: 3, weight: 5}},
+		{{to: 4, weight: 10}},
+		{},
+	}
+
+	paths, total := equalCostPaths(adj, 0, 4, 10)
+	assert.Equal(t, int64(20), total)
+	assert.ElementsMatch(t, [][]int{{0, 1, 4}, {0, 2, 3, 4}}, paths)
+
+	paths, _ = equalCostPaths(adj, 0, 4, 1)
+	assert.Len(t, paths, 1)
+
+	// Edges are directed, so there's no way back to 0
+	paths, _ = equalCostPaths(adj, 4, 0, 10)
+	assert.Empty(t, paths)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+const (
+	// metricOptimizationDeviationPct is the default deviation between a
+	// link's ISIS metric and its measured RTT that flags it
+	metricOptimizationDeviationPct = 20.0
+
+	// metricOptimizationWindow is how far back measured RTT is taken from
+	metricOptimizationWindow = "24 HOUR"
+)
+
+// ISISMetricSuggestion is a link whose ISIS metric is out of line with its
+// measured RTT
+type ISISMetricSuggestion struct {
+	LinkPK          string  `json:"linkPK"`
+	LinkCode        string  `json:"linkCode"`
+	SideAPK         string  `json:"sideAPK"`
+	SideZPK         string  `json:"sideZPK"`
+	CurrentMetric   int64   `json:"currentMetric"`
+	MeasuredRttUs   float64 `json:"measuredRttUs"` // median over the past 24 hours
+	SuggestedMetric int64   `json:"suggestedMetric"`
+	DeviationPct    float64 `json:"deviationPct"`  // positive when the current metric is higher than suggested
+	ImpactedPaths   int     `json:"impactedPaths"` // device pairs whose shortest paths change with the suggested metric
+}
+
+// ISISMetricOptimizationResponse is the response for the ISIS metric
+// optimization endpoint
+type ISISMetricOptimizationResponse struct {
+	ScaleFactor     float64                `json:"scaleFactor"`
+	MinDeviationPct float64                `json:"minDeviationPct"`
+	LinksChecked    int                    `json:"linksChecked"`
+	Suggestions     []ISISMetricSuggestion `json:"suggestions"`
+}
+
+// GetISISMetricOptimization compares each link's ISIS metric with its measured
+// RTT scaled by scale_factor (default 1, as metrics are RTT in microseconds)
+// and suggests new metrics for links that deviate by more than
+// min_deviation_pct. Suggestions are ordered by how many device pairs would be
+// rerouted.
+func GetISISMetricOptimization(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	scale := 1.0
+	if s := r.URL.Query().Get("scale_factor"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v <= 0 {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "scale_factor must be a positive number")
+			return
+		}
+		scale = v
+	}
+	minDeviation := metricOptimizationDeviationPct
+	if s := r.URL.Query().Get("min_deviation_pct"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "min_deviation_pct must be a non-negative number")
+			return
+		}
+		minDeviation = v
+	}
+
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT l.pk, l.code, l.side_a_pk, l.side_z_pk, quantile(0.5)(lat.rtt_us) AS rtt_us
+		FROM dz_links_current l
+		JOIN fact_dz_device_link_latency lat ON l.pk = lat.link_pk
+		WHERE lat.event_ts > now() - INTERVAL `+metricOptimizationWindow+`
+		  AND NOT lat.loss
+		  AND lat.rtt_us > 0
+		  AND l.side_a_pk != ''
+		  AND l.side_z_pk != ''
+		GROUP BY l.pk, l.code, l.side_a_pk, l.side_z_pk
+	`)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("ISIS metric optimization latency query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var measured []ISISMetricSuggestion
+	for rows.Next() {
+		var s ISISMetricSuggestion
+		if err := rows.Scan(&s.LinkPK, &s.LinkCode, &s.SideAPK, &s.SideZPK, &s.MeasuredRttUs); err != nil {
+			LoggerFromContext(ctx).Error("ISIS metric optimization row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to read link latency", err))
+			return
+		}
+		measured = append(measured, s)
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("ISIS metric optimization rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	start = time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("isis_metric_optimization", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("ISIS metric optimization graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	response := ISISMetricOptimizationResponse{
+		ScaleFactor:     scale,
+		MinDeviationPct: minDeviation,
+		Suggestions:     []ISISMetricSuggestion{},
+	}
+	for _, s := range measured {
+		a, okA := g.index[s.SideAPK]
+		z, okZ := g.index[s.SideZPK]
+		if !okA || !okZ {
+			continue
+		}
+		current := edgeWeight(g.adj, a, z)
+		if current < 0 {
+			continue
+		}
+		response.LinksChecked++
+
+		s.CurrentMetric = current
+		s.SuggestedMetric = max(1, int64(math.Round(s.MeasuredRttUs*scale)))
+		s.DeviationPct = float64(s.CurrentMetric-s.SuggestedMetric) * 100 / float64(s.SuggestedMetric)
+		if math.Abs(s.DeviationPct) <= minDeviation {
+			continue
+		}
+		s.ImpactedPaths = metricChangeImpact(g.adj, a, z, s.SuggestedMetric)
+		response.Suggestions = append(response.Suggestions, s)
+	}
+
+	sort.SliceStable(response.Suggestions, func(i, j int) bool {
+		si, sj := response.Suggestions[i], response.Suggestions[j]
+		if si.ImpactedPaths != sj.ImpactedPaths {
+			return si.ImpactedPaths > sj.ImpactedPaths
+		}
+		return math.Abs(si.DeviationPct) > math.Abs(sj.DeviationPct)
+	})
+
+	writeJSON(w, response)
+}
+
+// metricChangeImpact counts the device pairs whose shortest paths change if
+// the a-z adjacency's metric is set to metric. Only pairs whose use of the
+// edge changes can be rerouted, so pairs are compared by their share of
+// shortest paths over the edge before and after.
+func metricChangeImpact(adj [][]isisGraphEdge, a, z int, metric int64) int {
+	before := edgePathUsage(adj, a, z)
+
+	changed := make([][]isisGraphEdge, len(adj))
+	for v, edges := range adj {
+		changed[v] = append([]isisGraphEdge(nil), edges...)
+		for i, e := range changed[v] {
+			if (v == a && e.to == z) || (v == z && e.to == a) {
+				changed[v][i].weight = metric
+			}
+		}
+	}
+	after := edgePathUsage(changed, a, z)
+
+	shares := make(map[[2]int]float64, len(before.pairs))
+	for _, p := range before.pairs {
+		shares[[2]int{p.from, p.to}] = p.share
+	}
+	impacted := 0
+	for _, p := range after.pairs {
+		key := [2]int{p.from, p.to}
+		if math.Abs(shares[key]-p.share) > 1e-9 {
+			impacted++
+		}
+		delete(shares, key)
+	}
+	return impacted + len(shares)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import "testing"
+
+func TestMetricChangeImpact_Square(t *testing.T) {
+	// Square 0-1-2-3-0 with unit metrics: 0-1 carries 0-1, half of 0-2 and
+	// half of 1-3
+	adj := undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}})
+
+	tests := []struct {
+		name   string
+		metric int64
+		want   int
+	}{
+		{"unchanged", 1, 0},
+		// 0-2 and 1-3 move off the edge, 0-1 stays on it
+		{"raised", 2, 2},
+		// 0-1 now ties with the path around the square as well
+		{"raised to tie", 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metricChangeImpact(adj, 0, 1, tt.metric); got != tt.want {
+				t.Errorf("expected %d impacted pairs, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMetricChangeImpact_LeavesGraphUnchanged(t *testing.T) {
+	adj := undirectedGraph(3, [][2]int{{0, 1}, {1, 2}, {2, 0}})
+	metricChangeImpact(adj, 0, 1, 10)
+
+	if w := edgeWeight(adj, 0, 1); w != 1 {
+		t.Errorf("expected original metric 1, got %d", w)
+	}
+}
+
+func TestEdgeWeight(t *testing.T) {
+	adj := undirectedGraph(3, [][2]int{{0, 1}})
+	adj[0] = append(adj[0], isisGraphEdge{to: 1, weight: 5})
+
+	if w := edgeWeight(adj, 0, 1); w != 1 {
+		t.Errorf("expected lowest parallel metric 1, got %d", w)
+	}
+	if w := edgeWeight(adj, 0, 2); w != -1 {
+		t.Errorf("expected -1 for missing adjacency, got %d", w)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// SPTNode is a device in the shortest path tree rooted at another device
+type SPTNode struct {
+	DevicePK   string `json:"devicePK"`
+	DeviceCode string `json:"deviceCode"`
+	ParentPK   string `json:"parentPK"` // empty for the root
+	PathMetric int64  `json:"pathMetric"`
+	HopCount   int    `json:"hopCount"`
+}
+
+// ISISSPTResponse is the response for the ISIS shortest path tree endpoint
+type ISISSPTResponse struct {
+	RootPK   string    `json:"rootPK"`
+	RootCode string    `json:"rootCode"`
+	Nodes    []SPTNode `json:"nodes"`
+	// UnreachableCount is the number of ISIS devices not in the tree
+	UnreachableCount int `json:"unreachableCount"`
+}
+
+// GetISISSPT returns the shortest path tree IS-IS computes from ?root=, each
+// reachable device with its parent on the lowest-metric path from the root.
+// Where equal-cost paths tie, one parent is chosen. Dijkstra runs in Go over
+// the topology loaded once from Neo4j.
+func GetISISSPT(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	rootPK := r.URL.Query().Get("root")
+	if rootPK == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "root is required")
+		return
+	}
+
+	start := time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("isis_spt", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("ISIS SPT graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	root, ok := g.index[rootPK]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeDeviceNotFound, "device not found in ISIS topology")
+		return
+	}
+
+	response := ISISSPTResponse{RootPK: rootPK, RootCode: g.nodes[root].Code}
+	response.Nodes, response.UnreachableCount = shortestPathTree(g, root)
+
+	writeJSON(w, response)
+}
+
+// shortestPathTree returns the devices reachable from root, ordered by path
+// metric then code, and how many devices are unreachable
+func shortestPathTree(g *isisGraph, root int) ([]SPTNode, int) {
+	dist, prev := multiSourceShortestPaths(g.adj, []int{root})
+
+	// Dijkstra settles parents before children, so hop counts can be filled
+	// in metric order
+	order := make([]int, 0, len(g.nodes))
+	unreachable := 0
+	for i := range g.nodes {
+		if dist[i] < 0 {
+			unreachable++
+			continue
+		}
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if dist[a] != dist[b] {
+			return dist[a] < dist[b]
+		}
+		return g.nodes[a].Code < g.nodes[b].Code
+	})
+
+	hops := make([]int, len(g.nodes))
+	nodes := make([]SPTNode, 0, len(order))
+	for _, v := range order {
+		node := SPTNode{
+			DevicePK:   g.nodes[v].PK,
+			DeviceCode: g.nodes[v].Code,
+			PathMetric: dist[v],
+		}
+		if p := prev[v]; p >= 0 {
+			hops[v] = hops[p] + 1
+			node.ParentPK = g.nodes[p].PK
+			node.HopCount = hops[v]
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, unreachable
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import "testing"
+
+func TestShortestPathTree(t *testing.T) {
+	// a -10- b -10- c, with a -50- c as a direct but costly adjacency and
+	// c -5- d beyond it; e is off on its own
+	g := &isisGraph{
+		nodes: []isisGraphNode{
+			{PK: "a", Code: "a"},
+			{PK: "b", Code: "b"},
+			{PK: "c", Code: "c"},
+			{PK: "d", Code: "d"},
+			{PK: "e", Code: "e"},
+		},
+		adj: [][]isisGraphEdge{
+			{{to: 1, weight: 10}, {to: 2, weight: 50}},
+			{{to: 0, weight: 10}, {to: 2, weight: 10}},
+			{{to: 1, weight: 10}, {to: 0, weight: 50}, {to: 3, weight: 5}},
+			{{to: 2, weight: 5}},
+			{},
+		},
+	}
+
+	nodes, unreachable := shortestPathTree(g, 0)
+	if unreachable != 1 {
+		t.Errorf("expected e to be unreachable, got %d unreachable", unreachable)
+	}
+	want := []SPTNode{
+		{DevicePK: "a", DeviceCode: "a"},
+		{DevicePK: "b", DeviceCode: "b", ParentPK: "a", PathMetric: 10, HopCount: 1},
+		{DevicePK: "c", DeviceCode: "c", ParentPK: "b", PathMetric: 20, HopCount: 2},
+		{DevicePK: "d", DeviceCode: "d", ParentPK: "c", PathMetric: 25, HopCount: 3},
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("expected %d nodes, got %+v", len(want), nodes)
+	}
+	for i := range want {
+		if nodes[i] != want[i] {
+			t.Errorf("node %d: expected %+v, got %+v", i, want[i], nodes[i])
+		}
+	}
+}
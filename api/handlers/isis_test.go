@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/malbeclabs/lake/api/config"
 	"github.com/malbeclabs/lake/api/handlers"
+	"github.com/malbeclabs/lake/api/metrics"
 	apitesting "github.com/malbeclabs/lake/api/testing"
 	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -155,3 +160,518 @@ func TestGetMetroConnectivity_Empty(t *testing.T) {
 	assert.Empty(t, response.Metros)
 	assert.Empty(t, response.Connectivity)
 }
+
+// seedISISLine seeds a line topology NYC1 - NYC2 - CHI1 - LAX1 with
+// bidirectional adjacencies. LAX1 is drained.
+func seedISISLine(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (nyc:Metro {pk: 'metro-nyc', code: 'NYC'})
+			CREATE (chi:Metro {pk: 'metro-chi', code: 'CHI'})
+			CREATE (lax:Metro {pk: 'metro-lax', code: 'LAX'})
+			CREATE (n1:Device {pk: 'nyc1', code: 'NYC1', status: 'activated', device_type: 'hybrid', isis_system_id: '0000.0000.0001'})
+			CREATE (n2:Device {pk: 'nyc2', code: 'NYC2', status: 'activated', device_type: 'transit', isis_system_id: '0000.0000.0002'})
+			CREATE (c1:Device {pk: 'chi1', code: 'CHI1', status: 'activated', device_type: 'hybrid', isis_system_id: '0000.0000.0003'})
+			CREATE (l1:Device {pk: 'lax1', code: 'LAX1', status: 'drained', device_type: 'hybrid', isis_system_id: '0000.0000.0004'})
+			CREATE (n1)-[:LOCATED_IN]->(nyc)
+			CREATE (n2)-[:LOCATED_IN]->(nyc)
+			CREATE (c1)-[:LOCATED_IN]->(chi)
+			CREATE (l1)-[:LOCATED_IN]->(lax)
+			CREATE (n1)-[:ISIS_ADJACENT {metric: 10}]->(n2)
+			CREATE (n2)-[:ISIS_ADJACENT {metric: 10}]->(n1)
+			CREATE (n2)-[:ISIS_ADJACENT {metric: 20}]->(c1)
+			CREATE (c1)-[:ISIS_ADJACENT {metric: 20}]->(n2)
+			CREATE (c1)-[:ISIS_ADJACENT {metric: 30}]->(l1)
+			CREATE (l1)-[:ISIS_ADJACENT {metric: 30}]->(c1)
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+}
+
+func getISISTopology(t *testing.T, query string) handlers.ISISTopologyResponse {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/isis"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetISISTopology(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.ISISTopologyResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func isisNodeIDs(resp handlers.ISISTopologyResponse) []string {
+	ids := make([]string, 0, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		ids = append(ids, n.Data.ID)
+	}
+	return ids
+}
+
+// assertValidISISGraph checks that every edge endpoint is present in the node list.
+func assertValidISISGraph(t *testing.T, resp handlers.ISISTopologyResponse) {
+	nodes := make(map[string]bool)
+	for _, n := range resp.Nodes {
+		nodes[n.Data.ID] = true
+	}
+	for _, e := range resp.Edges {
+		assert.True(t, nodes[e.Data.Source], "edge %s references missing source", e.Data.ID)
+		assert.True(t, nodes[e.Data.Target], "edge %s references missing target", e.Data.ID)
+	}
+}
+
+func TestGetISISTopology_NoFilters(t *testing.T) {
+	seedISISLine(t)
+
+	resp := getISISTopology(t, "")
+	assert.Empty(t, resp.Error)
+	assert.Len(t, resp.Nodes, 4)
+	assert.Len(t, resp.Edges, 6)
+}
+
+func TestGetISISTopology_MetroFilter(t *testing.T) {
+	seedISISLine(t)
+
+	resp := getISISTopology(t, "?metro=metro-nyc")
+	assert.Empty(t, resp.Error)
+	// NYC devices plus CHI1, the one-hop neighbor of NYC2
+	assert.ElementsMatch(t, []string{"nyc1", "nyc2", "chi1"}, isisNodeIDs(resp))
+	assert.Len(t, resp.Edges, 4)
+	assertValidISISGraph(t, resp)
+}
+
+func TestGetISISTopology_MaxHops(t *testing.T) {
+	seedISISLine(t)
+
+	resp := getISISTopology(t, "?seed_pk=nyc1&max_hops=1")
+	assert.Empty(t, resp.Error)
+	assert.ElementsMatch(t, []string{"nyc1", "nyc2"}, isisNodeIDs(resp))
+	assert.Len(t, resp.Edges, 2)
+
+	resp = getISISTopology(t, "?seed_pk=nyc1&max_hops=0")
+	assert.Empty(t, resp.Error)
+	assert.ElementsMatch(t, []string{"nyc1"}, isisNodeIDs(resp))
+	assert.Empty(t, resp.Edges)
+}
+
+func TestGetISISTopology_StatusFilterKeepsReferencedNodes(t *testing.T) {
+	seedISISLine(t)
+
+	resp := getISISTopology(t, "?status=drained")
+	assert.Empty(t, resp.Error)
+	// LAX1 matches; CHI1 is included because it is the other end of LAX1's edges
+	assert.ElementsMatch(t, []string{"lax1", "chi1"}, isisNodeIDs(resp))
+	assert.Len(t, resp.Edges, 2)
+	assertValidISISGraph(t, resp)
+}
+
+func TestGetISISTopology_DeviceTypeFilter(t *testing.T) {
+	seedISISLine(t)
+
+	resp := getISISTopology(t, "?device_type=transit&metro=metro-nyc")
+	assert.Empty(t, resp.Error)
+	assert.ElementsMatch(t, []string{"nyc1", "nyc2", "chi1"}, isisNodeIDs(resp))
+	assertValidISISGraph(t, resp)
+}
+
+func TestGetISISTopology_InvalidParams(t *testing.T) {
+	resp := getISISTopology(t, "?max_hops=2")
+	assert.NotEmpty(t, resp.Error)
+
+	resp = getISISTopology(t, "?seed_pk=nyc1&max_hops=abc")
+	assert.NotEmpty(t, resp.Error)
+}
+
+func getNeighborGraph(t *testing.T, query string) handlers.ISISTopologyResponse {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/neighbor-graph"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetNeighborGraph(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.ISISTopologyResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestGetNeighborGraph(t *testing.T) {
+	seedISISLine(t)
+
+	// Default depth of 2 from NYC1 reaches CHI1 but not LAX1
+	resp := getNeighborGraph(t, "?center=nyc1")
+	assert.Empty(t, resp.Error)
+	assert.ElementsMatch(t, []string{"nyc1", "nyc2", "chi1"}, isisNodeIDs(resp))
+	assert.Len(t, resp.Edges, 4)
+	assertValidISISGraph(t, resp)
+	for _, n := range resp.Nodes {
+		assert.Equal(t, n.Data.ID == "nyc1", n.Data.IsCenter, "node %s", n.Data.ID)
+	}
+
+	resp = getNeighborGraph(t, "?center=chi1&depth=1")
+	assert.Empty(t, resp.Error)
+	assert.ElementsMatch(t, []string{"nyc2", "chi1", "lax1"}, isisNodeIDs(resp))
+}
+
+func TestGetNeighborGraph_InvalidParams(t *testing.T) {
+	seedISISLine(t)
+
+	assert.NotEmpty(t, getNeighborGraph(t, "").Error)
+	assert.NotEmpty(t, getNeighborGraph(t, "?center=nyc1&depth=0").Error)
+	assert.NotEmpty(t, getNeighborGraph(t, "?center=nyc1&depth=5").Error)
+
+	resp := getNeighborGraph(t, "?center=missing")
+	assert.NotEmpty(t, resp.Error)
+	assert.Empty(t, resp.Nodes)
+}
+
+// seedISISDiamond creates three two-hop paths from src to dst: two with a
+// total metric of 20 (ECMP) and one with a total metric of 30.
+func getISISPath(t *testing.T, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/path"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetISISPath(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	return rr
+}
+
+// parseTraceroute returns the device codes in traceroute output: the source
+// from the header, then the code column of each hop line.
+func parseTraceroute(t *testing.T, text string) []string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	header := strings.Fields(lines[0])
+	require.GreaterOrEqual(t, len(header), 3, "header: %q", lines[0])
+	require.Equal(t, "from", header[1])
+
+	codes := []string{header[2]}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		require.GreaterOrEqual(t, len(fields), 5, "line: %q", line)
+		require.Equal(t, strconv.Itoa(len(codes)), fields[0])
+		codes = append(codes, fields[1])
+	}
+	return codes
+}
+
+func TestGetISISPath_TracerouteFormat(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISLine(t)
+
+	for _, mode := range []string{"hops", "latency"} {
+		t.Run(mode, func(t *testing.T) {
+			query := "?from=nyc1&to=lax1&mode=" + mode
+
+			var resp handlers.PathResponse
+			require.NoError(t, json.NewDecoder(getISISPath(t, query).Body).Decode(&resp))
+			require.Empty(t, resp.Error)
+			jsonCodes := make([]string, 0, len(resp.Path))
+			for _, hop := range resp.Path {
+				jsonCodes = append(jsonCodes, hop.DeviceCode)
+			}
+
+			rr := getISISPath(t, query+"&format=traceroute")
+			assert.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+			text := rr.Body.String()
+			assert.Equal(t, jsonCodes, parseTraceroute(t, text))
+			// Metros and cumulative metrics: 10, 30 and 60 microseconds
+			assert.Contains(t, text, " 1  NYC2  NYC  0.01ms  *")
+			assert.Contains(t, text, " 3  LAX1  LAX  0.06ms  *")
+		})
+	}
+}
+
+func TestGetISISPath_TracerouteFormatError(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISLine(t)
+
+	rr := getISISPath(t, "?from=nyc1&format=traceroute")
+	assert.Equal(t, "traceroute: from and to parameters are required\n", rr.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/path?from=nyc1&to=lax1&format=xml", nil)
+	bad := httptest.NewRecorder()
+	handlers.GetISISPath(bad, req)
+	assert.Equal(t, http.StatusBadRequest, bad.Code)
+}
+
+func seedISISDiamond(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (src:Device {pk: 'src', code: 'SRC', status: 'activated', device_type: 'hybrid'})
+			CREATE (b:Device {pk: 'b', code: 'B', status: 'activated', device_type: 'transit'})
+			CREATE (c:Device {pk: 'c', code: 'C', status: 'activated', device_type: 'transit'})
+			CREATE (e:Device {pk: 'e', code: 'E', status: 'activated', device_type: 'transit'})
+			CREATE (dst:Device {pk: 'dst', code: 'DST', status: 'activated', device_type: 'hybrid'})
+			CREATE (src)-[:ISIS_ADJACENT {metric: 10}]->(b)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 10}]->(dst)
+			CREATE (src)-[:ISIS_ADJACENT {metric: 5}]->(c)
+			CREATE (c)-[:ISIS_ADJACENT {metric: 15}]->(dst)
+			CREATE (src)-[:ISIS_ADJACENT {metric: 10}]->(e)
+			CREATE (e)-[:ISIS_ADJACENT {metric: 20}]->(dst)
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+}
+
+func getECMPPaths(t *testing.T, query string) handlers.ECMPPathsResponse {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/ecmp-paths"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetECMPPaths(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.ECMPPathsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestGetECMPPaths(t *testing.T) {
+	seedISISDiamond(t)
+
+	resp := getECMPPaths(t, "?from=src&to=dst")
+	require.Empty(t, resp.Error)
+	assert.Equal(t, 2, resp.ECMPCount)
+	assert.Equal(t, uint32(20), resp.TotalMetric)
+	require.Len(t, resp.Paths, 2)
+
+	var vias []string
+	for _, p := range resp.Paths {
+		assert.Equal(t, uint32(20), p.TotalMetric)
+		assert.Equal(t, 2, p.HopCount)
+		require.Len(t, p.Path, 3)
+		assert.Equal(t, "src", p.Path[0].DevicePK)
+		assert.Equal(t, "dst", p.Path[2].DevicePK)
+		assert.Equal(t, p.TotalMetric, p.Path[1].EdgeMetric+p.Path[2].EdgeMetric)
+		vias = append(vias, p.Path[1].DevicePK)
+	}
+	assert.ElementsMatch(t, []string{"b", "c"}, vias)
+}
+
+func TestGetECMPPaths_SinglePath(t *testing.T) {
+	seedISISLine(t)
+
+	resp := getECMPPaths(t, "?from=nyc1&to=lax1")
+	require.Empty(t, resp.Error)
+	assert.Equal(t, 1, resp.ECMPCount)
+	assert.Equal(t, uint32(60), resp.TotalMetric)
+}
+
+func TestGetECMPPaths_InvalidParams(t *testing.T) {
+	resp := getECMPPaths(t, "?from=src")
+	assert.NotEmpty(t, resp.Error)
+
+	resp = getECMPPaths(t, "?from=src&to=src")
+	assert.NotEmpty(t, resp.Error)
+}
+
+// seedMetroDevicePairs seeds two metros with n ISIS devices each. Every
+// source device connects to every target device through one core link.
+func seedMetroDevicePairs(tb testing.TB, n int) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (src:Metro {pk: 'metro-src', code: 'SRC'})
+			CREATE (dst:Metro {pk: 'metro-dst', code: 'DST'})
+			CREATE (a:Device {pk: 'core-a', code: 'CORE-A', status: 'activated', device_type: 'transit', isis_system_id: 'core-a'})
+			CREATE (b:Device {pk: 'core-b', code: 'CORE-B', status: 'activated', device_type: 'transit', isis_system_id: 'core-b'})
+			CREATE (a)-[:ISIS_ADJACENT {metric: 100}]->(b)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 100}]->(a)
+			WITH src, dst, a, b
+			UNWIND range(1, $n) AS i
+			CREATE (s:Device {pk: 'src-' + i, code: 'SRC' + i, status: 'activated', device_type: 'hybrid', isis_system_id: 'src-' + i})
+			CREATE (t:Device {pk: 'dst-' + i, code: 'DST' + i, status: 'activated', device_type: 'hybrid', isis_system_id: 'dst-' + i})
+			CREATE (s)-[:LOCATED_IN]->(src)
+			CREATE (t)-[:LOCATED_IN]->(dst)
+			CREATE (s)-[:ISIS_ADJACENT {metric: 10}]->(a)
+			CREATE (a)-[:ISIS_ADJACENT {metric: 10}]->(s)
+			CREATE (t)-[:ISIS_ADJACENT {metric: 10}]->(b)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 10}]->(t)
+		`, map[string]any{"n": n})
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(tb, testNeo4jDB, seedFunc)
+}
+
+// neo4jSessionsAcquired returns the number of sessions checked out from the
+// pool so far. Every successful acquire records one pool wait observation.
+func neo4jSessionsAcquired(tb testing.TB) uint64 {
+	var m dto.Metric
+	require.NoError(tb, metrics.Neo4jSessionPoolWaitDuration.Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func getMetroDevicePaths(tb testing.TB) handlers.MetroDevicePathsResponse {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/metro-device-paths?from=metro-src&to=metro-dst", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetMetroDevicePaths(rr, req)
+	require.Equal(tb, http.StatusOK, rr.Code)
+
+	var resp handlers.MetroDevicePathsResponse
+	require.NoError(tb, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Empty(tb, resp.Error)
+	return resp
+}
+
+func TestGetMetroDevicePaths_BoundedSessions(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedMetroDevicePairs(t, 10)
+
+	before := neo4jSessionsAcquired(t)
+	resp := getMetroDevicePaths(t)
+	sessions := neo4jSessionsAcquired(t) - before
+
+	assert.Equal(t, 100, resp.TotalPairs)
+	assert.Len(t, resp.DevicePairs, 100)
+	assert.Equal(t, 3, resp.MinHops)
+	assert.Equal(t, 3, resp.MaxHops)
+	assert.LessOrEqual(t, sessions, uint64(10))
+}
+
+func BenchmarkGetMetroDevicePaths(b *testing.B) {
+	apitesting.SetupTestClickHouseWithMigrations(b, testChDB)
+	seedMetroDevicePairs(b, 10)
+
+	before := neo4jSessionsAcquired(b)
+	for b.Loop() {
+		resp := getMetroDevicePaths(b)
+		require.Equal(b, 100, resp.TotalPairs)
+	}
+	sessions := float64(neo4jSessionsAcquired(b)-before) / float64(b.N)
+
+	b.ReportMetric(sessions, "sessions/op")
+	assert.LessOrEqual(b, sessions, 10.0)
+}
+
+func TestGetBetweennessCentrality(t *testing.T) {
+	seedISISLine(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/betweenness-centrality", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetBetweennessCentrality(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.BetweennessCentralityResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Empty(t, response.Error)
+	require.Len(t, response.Devices, 4)
+
+	// NYC2 and CHI1 each sit on 4 of the 6 ordered paths between other devices
+	scores := make(map[string]handlers.CentralityDevice)
+	for _, d := range response.Devices {
+		scores[d.Code] = d
+	}
+	assert.InDelta(t, 2.0/3, scores["NYC2"].Score, 1e-9)
+	assert.InDelta(t, 2.0/3, scores["CHI1"].Score, 1e-9)
+	assert.Zero(t, scores["NYC1"].Score)
+	assert.Zero(t, scores["LAX1"].Score)
+	assert.True(t, scores["NYC2"].CutVertex)
+	assert.Equal(t, "metro-chi", scores["CHI1"].MetroPK)
+	assert.Equal(t, "drained", scores["LAX1"].Status)
+	assert.Contains(t, []string{"NYC2", "CHI1"}, response.Devices[0].Code, "devices should be ranked by score")
+
+	// Hubs flow into the redundancy report
+	req = httptest.NewRequest(http.MethodGet, "/api/topology/redundancy-report", nil)
+	rr = httptest.NewRecorder()
+	handlers.GetRedundancyReport(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var report handlers.RedundancyReportResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	require.Empty(t, report.Error)
+	require.Len(t, report.HighCentralityDevices, 2)
+	assert.Equal(t, 2, report.Summary.NonRedundantHubs)
+}
+
+func getDeviceNeighbors(t *testing.T, pk, query string) handlers.DeviceNeighborsResponse {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/devices/"+pk+"/neighbors"+query, nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetDeviceNeighbors(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.DeviceNeighborsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestGetDeviceNeighbors(t *testing.T) {
+	seedISISLine(t)
+
+	// LAX1 is drained, so only NYC2 is returned, once per adjacency direction
+	resp := getDeviceNeighbors(t, "chi1", "")
+	require.Empty(t, resp.Warning)
+	require.Len(t, resp.Neighbors, 2)
+	for _, n := range resp.Neighbors {
+		assert.Equal(t, "nyc2", n.DevicePK)
+		assert.Equal(t, "NYC2", n.DeviceCode)
+		assert.Equal(t, "transit", n.DeviceType)
+		assert.Equal(t, "metro-nyc", n.MetroPK)
+		assert.Equal(t, uint32(20), n.Metric)
+	}
+	assert.ElementsMatch(t, []string{"outgoing", "incoming"},
+		[]string{resp.Neighbors[0].Direction, resp.Neighbors[1].Direction})
+
+	resp = getDeviceNeighbors(t, "chi1", "?include_offline=true")
+	require.Len(t, resp.Neighbors, 4)
+	codes := make(map[string]int)
+	for _, n := range resp.Neighbors {
+		codes[n.DeviceCode]++
+	}
+	assert.Equal(t, map[string]int{"NYC2": 2, "LAX1": 2}, codes)
+}
+
+func TestGetDeviceNeighbors_UnknownDevice(t *testing.T) {
+	seedISISLine(t)
+
+	resp := getDeviceNeighbors(t, "does-not-exist", "")
+	assert.Empty(t, resp.Warning)
+	assert.Empty(t, resp.Neighbors)
+}
+
+func TestGetDeviceNeighbors_Neo4jUnavailable(t *testing.T) {
+	origClient := config.Neo4jClient
+	config.Neo4jClient = nil
+	t.Cleanup(func() { config.Neo4jClient = origClient })
+
+	resp := getDeviceNeighbors(t, "chi1", "")
+	assert.NotEmpty(t, resp.Warning)
+	assert.NotNil(t, resp.Neighbors)
+	assert.Empty(t, resp.Neighbors)
+}
+
+func TestGetPathDiversity(t *testing.T) {
+	seedISISLine(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/path-diversity?from=metro-nyc&to=metro-lax", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetPathDiversity(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.PathDiversityResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Empty(t, response.Error)
+	assert.Equal(t, 1, response.NodeDisjointPaths)
+	assert.Equal(t, 1, response.EdgeDisjointPaths)
+
+	// NYC1 isn't a bottleneck since NYC2 is also in the source metro
+	var nodeCodes []string
+	for _, n := range response.BottleneckNodes {
+		nodeCodes = append(nodeCodes, n.Code)
+	}
+	assert.ElementsMatch(t, []string{"NYC2", "CHI1", "LAX1"}, nodeCodes)
+
+	var linkCodes []string
+	for _, l := range response.BottleneckLinks {
+		pair := []string{l.SourceCode, l.TargetCode}
+		sort.Strings(pair)
+		linkCodes = append(linkCodes, pair[0]+"-"+pair[1])
+	}
+	assert.ElementsMatch(t, []string{"CHI1-NYC2", "CHI1-LAX1"}, linkCodes)
+}
+
+func TestGetPathDiversity_MissingParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/path-diversity?from=metro-nyc", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetPathDiversity(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.PathDiversityResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "from and to parameters are required", response.Error)
+}
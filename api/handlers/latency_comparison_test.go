@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getLatencyComparison(query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/latency-comparison"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetLatencyComparison(rr, req)
+	return rr
+}
+
+func TestGetLatencyComparison_LinkDeltas(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now(), now(), generateUUIDv4(), 0, 1, 'link-1', 'activated', 'LINK-1', '', '', 'dev-a', 'dev-z',
+		 '', '', 'WAN', 0, 0, 0, 0)`))
+
+	// 1ms two days ago, 2ms in the last hour
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT if(number < 10, now() - INTERVAL 2 DAY, now() - INTERVAL 30 MINUTE) + INTERVAL 1 SECOND * number,
+		       now(), 1, number, 'dev-a', 'dev-z', 'link-1', if(number < 10, 1000, 2000), false, 0
+		FROM numbers(20)`))
+
+	rr := getLatencyComparison("?device_pk=dev-z")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.LatencyComparisonResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.Links, 1)
+	link := resp.Links[0]
+	assert.Equal(t, "LINK-1", link.LinkCode)
+	assert.InDelta(t, 2.0, link.CurrentAvgMs, 0.001)
+	require.NotNil(t, link.DeltaMs)
+	require.NotNil(t, link.DeltaPct)
+	assert.InDelta(t, 1.0, *link.DeltaMs, 0.001)
+	assert.InDelta(t, 100.0, *link.DeltaPct, 0.001)
+
+	// A 1 day baseline doesn't reach the older samples
+	rr = getLatencyComparison("?baseline_window=1d")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	resp = handlers.LatencyComparisonResponse{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.Links, 1)
+	assert.Nil(t, resp.Links[0].DeltaMs)
+
+	// Links on other devices are filtered out
+	rr = getLatencyComparison("?device_pk=dev-other")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	resp = handlers.LatencyComparisonResponse{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Links)
+}
+
+func TestGetLatencyComparison_Validation(t *testing.T) {
+	for _, query := range []string{
+		"?start=yesterday",
+		"?end=2025-01-01",
+		"?start=2025-01-02T00:00:00Z&end=2025-01-01T00:00:00Z",
+		"?baseline_window=week",
+		"?baseline_window=0d",
+	} {
+		assert.Equal(t, http.StatusBadRequest, getLatencyComparison(query).Code, query)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// latencyHeatmapCacheTTL is how long a latency heatmap is reused per optimize strategy
+const latencyHeatmapCacheTTL = 2 * time.Minute
+
+// LatencyHeatmapMetro is a row/column of the latency heatmap
+type LatencyHeatmapMetro struct {
+	PK   string `json:"pk"`
+	Code string `json:"code"`
+}
+
+// LatencyHeatmapResponse is the response for the latency heatmap endpoint.
+// Matrix[i][j] is the path latency in ms from Metros[i] to Metros[j], 0 on the
+// diagonal and -1 when there is no path.
+type LatencyHeatmapResponse struct {
+	Optimize string                `json:"optimize"`
+	Metros   []LatencyHeatmapMetro `json:"metros"`
+	Matrix   [][]float64           `json:"matrix"`
+	Error    string                `json:"error,omitempty"`
+}
+
+type latencyHeatmapCacheEntry struct {
+	response  LatencyHeatmapResponse
+	fetchedAt time.Time
+}
+
+var (
+	latencyHeatmapCache   = make(map[string]latencyHeatmapCacheEntry)
+	latencyHeatmapCacheMu sync.RWMutex
+)
+
+// buildLatencyHeatmap sorts metros by code and fills the latency matrix from paths
+func buildLatencyHeatmap(metros []LatencyHeatmapMetro, paths []MetroPathLatency) ([]LatencyHeatmapMetro, [][]float64) {
+	sort.Slice(metros, func(i, j int) bool {
+		if metros[i].Code != metros[j].Code {
+			return metros[i].Code < metros[j].Code
+		}
+		return metros[i].PK < metros[j].PK
+	})
+
+	index := make(map[string]int, len(metros))
+	matrix := make([][]float64, len(metros))
+	for i, m := range metros {
+		index[m.PK] = i
+		matrix[i] = make([]float64, len(metros))
+		for j := range matrix[i] {
+			if i != j {
+				matrix[i][j] = -1
+			}
+		}
+	}
+
+	for _, p := range paths {
+		i, ok := index[p.FromMetroPK]
+		if !ok {
+			continue
+		}
+		j, ok := index[p.ToMetroPK]
+		if !ok || i == j {
+			continue
+		}
+		matrix[i][j] = p.PathLatencyMs
+	}
+
+	return metros, matrix
+}
+
+// GetLatencyHeatmap returns metro-to-metro path latency as a matrix for direct
+// rendering as a heatmap. Paths come from the same queries as GetMetroPathLatency
+// for the optimize strategy (hops, latency or bandwidth). Cached for 2 minutes.
+func GetLatencyHeatmap(w http.ResponseWriter, r *http.Request) {
+	optimize := r.URL.Query().Get("optimize")
+	if optimize == "" {
+		optimize = "latency"
+	}
+	if optimize != "hops" && optimize != "latency" && optimize != "bandwidth" {
+		writeJSON(w, LatencyHeatmapResponse{Error: "optimize must be 'hops', 'latency', or 'bandwidth'"})
+		return
+	}
+
+	latencyHeatmapCacheMu.RLock()
+	entry, ok := latencyHeatmapCache[optimize]
+	latencyHeatmapCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < latencyHeatmapCacheTTL {
+		w.Header().Set("X-Cache", "HIT")
+		writeJSON(w, entry.response)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	response := LatencyHeatmapResponse{
+		Optimize: optimize,
+		Metros:   []LatencyHeatmapMetro{},
+		Matrix:   [][]float64{},
+	}
+
+	var paths *MetroPathLatencyResponse
+	if statusCache != nil {
+		paths, _ = statusCache.GetMetroPathLatency(optimize)
+	}
+	if paths == nil {
+		var err error
+		paths, err = fetchMetroPathLatencyData(ctx, optimize)
+		if err != nil {
+			LoggerFromContext(ctx).Error("Latency heatmap path query error", "error", err)
+			response.Error = err.Error()
+			writeJSON(w, response)
+			return
+		}
+	}
+
+	start := time.Now()
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `MATCH (m:Metro) RETURN m.pk AS pk, m.code AS code`, nil)
+	if err != nil {
+		metrics.RecordNeo4jQuery("latency_heatmap", time.Since(start), err)
+		LoggerFromContext(ctx).Error("Latency heatmap metro query error", "error", err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	records, err := result.Collect(ctx)
+	metrics.RecordNeo4jQuery("latency_heatmap", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Latency heatmap metro collect error", "error", err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+
+	metros := make([]LatencyHeatmapMetro, 0, len(records))
+	for _, record := range records {
+		pk, _ := record.Get("pk")
+		code, _ := record.Get("code")
+		metros = append(metros, LatencyHeatmapMetro{PK: asString(pk), Code: asString(code)})
+	}
+	response.Metros, response.Matrix = buildLatencyHeatmap(metros, paths.Paths)
+
+	latencyHeatmapCacheMu.Lock()
+	latencyHeatmapCache[optimize] = latencyHeatmapCacheEntry{response: response, fetchedAt: time.Now()}
+	latencyHeatmapCacheMu.Unlock()
+
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, response)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildLatencyHeatmap(t *testing.T) {
+	t.Parallel()
+
+	metros := []LatencyHeatmapMetro{
+		{PK: "m-nyc", Code: "nyc"},
+		{PK: "m-ams", Code: "ams"},
+		{PK: "m-fra", Code: "fra"},
+	}
+	paths := []MetroPathLatency{
+		{FromMetroPK: "m-ams", ToMetroPK: "m-nyc", PathLatencyMs: 70.5},
+		{FromMetroPK: "m-nyc", ToMetroPK: "m-ams", PathLatencyMs: 70.5},
+		{FromMetroPK: "m-ams", ToMetroPK: "m-fra", PathLatencyMs: 8},
+		{FromMetroPK: "m-fra", ToMetroPK: "m-ams", PathLatencyMs: 8.2},
+		{FromMetroPK: "m-gone", ToMetroPK: "m-ams", PathLatencyMs: 1},
+	}
+
+	sorted, matrix := buildLatencyHeatmap(metros, paths)
+
+	assert.Equal(t, []LatencyHeatmapMetro{
+		{PK: "m-ams", Code: "ams"},
+		{PK: "m-fra", Code: "fra"},
+		{PK: "m-nyc", Code: "nyc"},
+	}, sorted)
+	assert.Equal(t, [][]float64{
+		{0, 8, 70.5},
+		{8.2, 0, -1},
+		{70.5, -1, 0},
+	}, matrix)
+}
+
+func TestBuildLatencyHeatmap_Empty(t *testing.T) {
+	t.Parallel()
+
+	sorted, matrix := buildLatencyHeatmap([]LatencyHeatmapMetro{}, nil)
+	assert.Empty(t, sorted)
+	assert.NotNil(t, matrix)
+	assert.Empty(t, matrix)
+}
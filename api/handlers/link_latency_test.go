@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedLinkLatency inserts link-1 with a 5ms committed RTT and 100 samples in
+// the last few minutes: RTTs of 1..99ms plus one lost sample.
+func seedLinkLatency(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now(), now(), generateUUIDv4(), 0, 1, 'link-1', 'activated', 'LINK-1', '', '', 'dev-a', 'dev-z',
+		 '', '', 'WAN', 5000000, 0, 0, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT toStartOfHour(now()) + INTERVAL 1 SECOND * number, now(), 1, number, 'dev-a', 'dev-z', 'link-1',
+		       if(number = 0, 0, number * 1000), number = 0, 100
+		FROM numbers(100)`))
+}
+
+func getLinkLatencyTimeseries(pk, query string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links/"+pk+"/latency-timeseries"+query, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetLinkLatencyTimeseries(rr, req)
+	return rr
+}
+
+func TestGetLinkLatencyTimeseries(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkLatency(t)
+
+	rr := getLinkLatencyTimeseries("link-1", "?granularity=1h&range=6h", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+
+	var resp handlers.LinkLatencyTimeseriesResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "LINK-1", resp.LinkCode)
+	assert.Equal(t, "1h", resp.Granularity)
+	assert.InDelta(t, 5.0, resp.CommittedRttMs, 0.001)
+	require.Len(t, resp.Points, 1)
+
+	p := resp.Points[0]
+	assert.Equal(t, uint64(100), p.Samples)
+	assert.InDelta(t, 1.0, p.LossPct, 0.001)
+	assert.InDelta(t, 50.0, p.P50RttMs, 1.0)
+	assert.InDelta(t, 94.0, p.P95RttMs, 1.5)
+	assert.InDelta(t, 98.0, p.P99RttMs, 1.5)
+	assert.InDelta(t, 0.1, p.AvgJitterMs, 0.001)
+}
+
+func TestGetLinkLatencyTimeseries_ConditionalGet(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkLatency(t)
+
+	first := getLinkLatencyTimeseries("link-1", "?granularity=1h", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	cached := getLinkLatencyTimeseries("link-1", "?granularity=1h", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, cached.Code)
+	assert.Empty(t, cached.Body.String())
+
+	stale := getLinkLatencyTimeseries("link-1", "?granularity=1h", map[string]string{"If-None-Match": `"other"`})
+	assert.Equal(t, http.StatusOK, stale.Code)
+}
+
+func TestGetLinkLatencyTimeseries_Errors(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkLatency(t)
+
+	assert.Equal(t, http.StatusNotFound, getLinkLatencyTimeseries("missing", "", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, getLinkLatencyTimeseries("link-1", "?granularity=10s", nil).Code)
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// linkPathUsageSampleLimit is how many device pairs are returned as examples
+const linkPathUsageSampleLimit = 5
+
+// LinkPathUsagePair is a device pair whose shortest path crosses the link
+type LinkPathUsagePair struct {
+	FromPK   string  `json:"fromPK"`
+	FromCode string  `json:"fromCode"`
+	ToPK     string  `json:"toPK"`
+	ToCode   string  `json:"toCode"`
+	Metric   int64   `json:"metric"` // total ISIS metric of the pair's shortest path
+	Share    float64 `json:"share"`  // fraction of the pair's equal-cost shortest paths using the link (0-1)
+}
+
+// LinkPathUsageResponse is the response for the link path usage endpoint
+type LinkPathUsageResponse struct {
+	LinkPK            string              `json:"linkPK"`
+	LinkCode          string              `json:"linkCode"`
+	SideAPK           string              `json:"sideAPK"`
+	SideZPK           string              `json:"sideZPK"`
+	InTopology        bool                `json:"inTopology"` // both sides are ISIS adjacent
+	UsageCount        int                 `json:"usageCount"`
+	TotalPairs        int                 `json:"totalPairs"`
+	PercentOfAllPairs float64             `json:"percentOfAllPairs"`
+	EdgeBetweenness   float64             `json:"edgeBetweenness"` // normalized edge betweenness centrality (0-1)
+	SamplePaths       []LinkPathUsagePair `json:"samplePaths"`
+}
+
+// GetLinkPathUsage returns how many device pairs route over a link, i.e. the
+// edge betweenness of the link's ISIS adjacency.
+func GetLinkPathUsage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
+		return
+	}
+
+	response := LinkPathUsageResponse{
+		LinkPK:      pk,
+		SamplePaths: []LinkPathUsagePair{},
+	}
+
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT code, side_a_pk, side_z_pk
+		FROM dz_links_current
+		WHERE pk = $1
+	`, pk).Scan(&response.LinkCode, &response.SideAPK, &response.SideZPK)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "link not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Link path usage link query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	start = time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("link_path_usage", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link path usage graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	a, okA := g.index[response.SideAPK]
+	z, okZ := g.index[response.SideZPK]
+	if okA && okZ {
+		usage := edgePathUsage(g.adj, a, z)
+		response.InTopology = usage.adjacent
+		response.UsageCount = len(usage.pairs)
+		response.TotalPairs = usage.totalPairs
+		if usage.totalPairs > 0 {
+			response.PercentOfAllPairs = float64(len(usage.pairs)) * 100 / float64(usage.totalPairs)
+			response.EdgeBetweenness = usage.betweenness / float64(usage.totalPairs)
+		}
+		for i, p := range usage.pairs {
+			if i == linkPathUsageSampleLimit {
+				break
+			}
+			response.SamplePaths = append(response.SamplePaths, LinkPathUsagePair{
+				FromPK:   g.nodes[p.from].PK,
+				FromCode: g.nodes[p.from].Code,
+				ToPK:     g.nodes[p.to].PK,
+				ToCode:   g.nodes[p.to].Code,
+				Metric:   p.metric,
+				Share:    p.share,
+			})
+		}
+	}
+
+	writeJSON(w, response)
+}
+
+// edgePairUsage is a device pair with at least one shortest path over an edge
+type edgePairUsage struct {
+	from, to int
+	metric   int64
+	share    float64
+}
+
+// edgeUsage is the result of edgePathUsage
+type edgeUsage struct {
+	adjacent    bool
+	pairs       []edgePairUsage // most dependent pairs first
+	totalPairs  int             // connected unordered pairs
+	betweenness float64         // sum of pair shares (unnormalized edge betweenness)
+}
+
+// edgePathUsage finds the unordered node pairs whose shortest paths cross the
+// a-z edge in either direction. A pair's share is the fraction of its
+// equal-cost shortest paths that use the edge, so summing the shares gives
+// the edge's betweenness.
+func edgePathUsage(adj [][]isisGraphEdge, a, z int) edgeUsage {
+	var usage edgeUsage
+
+	weight := edgeWeight(adj, a, z)
+	usage.adjacent = weight >= 0
+
+	n := len(adj)
+	dist := make([][]int64, n)
+	sigma := make([][]float64, n)
+	for s := range n {
+		dist[s], sigma[s] = shortestPathCounts(adj, s)
+	}
+
+	for s := range n {
+		for t := s + 1; t < n; t++ {
+			if dist[s][t] < 0 {
+				continue
+			}
+			usage.totalPairs++
+			if !usage.adjacent {
+				continue
+			}
+
+			var through float64
+			if dist[s][a] >= 0 && dist[z][t] >= 0 && dist[s][a]+weight+dist[z][t] == dist[s][t] {
+				through += sigma[s][a] * sigma[z][t]
+			}
+			if dist[s][z] >= 0 && dist[a][t] >= 0 && dist[s][z]+weight+dist[a][t] == dist[s][t] {
+				through += sigma[s][z] * sigma[a][t]
+			}
+			if through == 0 {
+				continue
+			}
+
+			share := through / sigma[s][t]
+			usage.betweenness += share
+			usage.pairs = append(usage.pairs, edgePairUsage{from: s, to: t, metric: dist[s][t], share: share})
+		}
+	}
+
+	// Pairs with no alternative first, then the longest paths
+	sort.SliceStable(usage.pairs, func(i, j int) bool {
+		if usage.pairs[i].share != usage.pairs[j].share {
+			return usage.pairs[i].share > usage.pairs[j].share
+		}
+		return usage.pairs[i].metric > usage.pairs[j].metric
+	})
+	return usage
+}
+
+// shortestPathCounts returns the shortest distance from s to every node (-1
+// if unreachable) and the number of equal-cost shortest paths to each.
+func shortestPathCounts(adj [][]isisGraphEdge, s int) ([]int64, []float64) {
+	n := len(adj)
+	dist := make([]int64, n)
+	sigma := make([]float64, n)
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	dist[s] = 0
+	sigma[s] = 1
+	pq := &centralityQueue{{node: s}}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(centralityItem)
+		v := item.node
+		if item.dist > dist[v] {
+			continue // stale entry
+		}
+		for _, e := range adj[v] {
+			alt := dist[v] + e.weight
+			switch {
+			case dist[e.to] < 0 || alt < dist[e.to]:
+				dist[e.to] = alt
+				sigma[e.to] = sigma[v]
+				heap.Push(pq, centralityItem{node: e.to, dist: alt})
+			case alt == dist[e.to]:
+				sigma[e.to] += sigma[v]
+			}
+		}
+	}
+	return dist, sigma
+}
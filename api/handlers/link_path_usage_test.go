@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEdgePathUsage_Line(t *testing.T) {
+	// 0 - 1 - 2 - 3: the middle edge carries 0-2, 0-3, 1-2 and 1-3
+	usage := edgePathUsage(undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}}), 1, 2)
+
+	if !usage.adjacent {
+		t.Fatal("expected edge to be adjacent")
+	}
+	if usage.totalPairs != 6 {
+		t.Errorf("expected 6 pairs, got %d", usage.totalPairs)
+	}
+	if len(usage.pairs) != 4 {
+		t.Fatalf("expected 4 pairs using the edge, got %d", len(usage.pairs))
+	}
+	// Longest path first among equal shares
+	if usage.pairs[0].from != 0 || usage.pairs[0].to != 3 || usage.pairs[0].metric != 3 {
+		t.Errorf("expected 0-3 first, got %+v", usage.pairs[0])
+	}
+	if math.Abs(usage.betweenness-4) > 1e-9 {
+		t.Errorf("expected betweenness 4, got %v", usage.betweenness)
+	}
+}
+
+func TestEdgePathUsage_DirectionIndependent(t *testing.T) {
+	adj := undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}})
+	if a, b := len(edgePathUsage(adj, 1, 2).pairs), len(edgePathUsage(adj, 2, 1).pairs); a != b {
+		t.Errorf("expected same usage both ways, got %d and %d", a, b)
+	}
+}
+
+func TestEdgePathUsage_SplitsEqualCostPaths(t *testing.T) {
+	// Square: 0-2 has two equal-cost paths, one over the 0-1 edge
+	usage := edgePathUsage(undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}), 0, 1)
+
+	shares := map[[2]int]float64{}
+	for _, p := range usage.pairs {
+		shares[[2]int{p.from, p.to}] = p.share
+	}
+	if shares[[2]int{0, 1}] != 1 {
+		t.Errorf("expected 0-1 share 1, got %v", shares[[2]int{0, 1}])
+	}
+	if math.Abs(shares[[2]int{0, 2}]-0.5) > 1e-9 {
+		t.Errorf("expected 0-2 share 0.5, got %v", shares[[2]int{0, 2}])
+	}
+	if math.Abs(shares[[2]int{1, 3}]-0.5) > 1e-9 {
+		t.Errorf("expected 1-3 share 0.5, got %v", shares[[2]int{1, 3}])
+	}
+	if _, ok := shares[[2]int{2, 3}]; ok {
+		t.Error("expected 2-3 not to use the edge")
+	}
+	// Sole-path pairs sort ahead of shared ones
+	if usage.pairs[0].share != 1 {
+		t.Errorf("expected a share-1 pair first, got %+v", usage.pairs[0])
+	}
+}
+
+func TestEdgePathUsage_UsesMetric(t *testing.T) {
+	// 0 -> 2 directly costs 100, via 1 costs 20, so the direct edge is unused
+	adj := make([][]isisGraphEdge, 3)
+	link := func(a, b int, w int64) {
+		adj[a] = append(adj[a], isisGraphEdge{to: b, weight: w})
+		adj[b] = append(adj[b], isisGraphEdge{to: a, weight: w})
+	}
+	link(0, 1, 10)
+	link(1, 2, 10)
+	link(0, 2, 100)
+
+	usage := edgePathUsage(adj, 0, 2)
+	if !usage.adjacent {
+		t.Fatal("expected edge to be adjacent")
+	}
+	if len(usage.pairs) != 0 {
+		t.Errorf("expected no pairs using the expensive edge, got %+v", usage.pairs)
+	}
+}
+
+func TestEdgePathUsage_NotAdjacent(t *testing.T) {
+	// 0 - 1   2 - 3: two components, and 1-2 isn't an edge
+	usage := edgePathUsage(undirectedGraph(4, [][2]int{{0, 1}, {2, 3}}), 1, 2)
+
+	if usage.adjacent {
+		t.Error("expected edge not to be adjacent")
+	}
+	if usage.totalPairs != 2 {
+		t.Errorf("expected 2 connected pairs, got %d", usage.totalPairs)
+	}
+	if len(usage.pairs) != 0 {
+		t.Errorf("expected no pairs, got %+v", usage.pairs)
+	}
+}
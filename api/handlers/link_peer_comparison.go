@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// peerCommittedRttTolerance is how far a peer's committed RTT may be from the
+// link's own, as a fraction of it
+const peerCommittedRttTolerance = 0.2
+
+// LinkPeerComparisonResponse is the response for the link peer comparison endpoint
+type LinkPeerComparisonResponse struct {
+	LinkPK    string `json:"linkPK"`
+	PeerCount int    `json:"peerCount"` // peers with measurements in the window, excluding the link
+	// Percentile ranks are the share of peers with a lower value, so a rank
+	// above 90 means the link is worse than 90% of its peers
+	RttPercentileRank    float64 `json:"rttPercentileRank"`
+	JitterPercentileRank float64 `json:"jitterPercentileRank"`
+	LossPercentileRank   float64 `json:"lossPercentileRank"`
+	RttVsPeerMedianPct   float64 `json:"rttVsPeerMedianPct"`
+}
+
+// linkPeerStats is a link's measured latency over the comparison window.
+// RTT and jitter are NaN if every sample was lost.
+type linkPeerStats struct {
+	pk       string
+	rttNs    float64
+	jitterNs float64
+	lossPct  float64
+}
+
+// GetLinkPeerComparison ranks a link's RTT, jitter and loss over the past 24
+// hours against links of the same type whose committed RTT is within 20% of
+// its own.
+func GetLinkPeerComparison(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
+		return
+	}
+
+	var linkType string
+	var committedRttNs int64
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT link_type, COALESCE(committed_rtt_ns, 0)
+		FROM dz_links_current
+		WHERE pk = $1
+	`, pk).Scan(&linkType, &committedRttNs)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "link not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Link peer comparison link query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	// The link itself is included so its stats come back from the same query
+	start = time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH peers AS (
+			SELECT pk
+			FROM dz_links_current
+			WHERE link_type = $1
+			  AND COALESCE(committed_rtt_ns, 0) BETWEEN $2 * (1 - $3) AND $2 * (1 + $3)
+		)
+		SELECT
+			link_pk,
+			quantileIf(0.5)(rtt_us, NOT loss) * 1000 AS rtt_ns,
+			avgIf(abs(ipdv_us), NOT loss) * 1000 AS jitter_ns,
+			countIf(loss) * 100.0 / count(*) AS loss_pct
+		FROM fact_dz_device_link_latency
+		WHERE event_ts >= now() - INTERVAL 24 HOUR
+		  AND link_pk IN (SELECT pk FROM peers)
+		GROUP BY link_pk
+	`, linkType, committedRttNs, peerCommittedRttTolerance)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link peer comparison query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var stats []linkPeerStats
+	for rows.Next() {
+		var s linkPeerStats
+		if err := rows.Scan(&s.pk, &s.rttNs, &s.jitterNs, &s.lossPct); err != nil {
+			LoggerFromContext(ctx).Error("Link peer comparison row scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Link peer comparison rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, comparePeerLinks(pk, stats))
+}
+
+// comparePeerLinks ranks the link's stats against every other entry. Without
+// measurements for the link, only the peer count is filled in.
+func comparePeerLinks(pk string, stats []linkPeerStats) LinkPeerComparisonResponse {
+	response := LinkPeerComparisonResponse{LinkPK: pk}
+
+	var link *linkPeerStats
+	var rtts, jitters, losses []float64
+	for i := range stats {
+		s := &stats[i]
+		if s.pk == pk {
+			link = s
+			continue
+		}
+		response.PeerCount++
+		if !math.IsNaN(s.rttNs) {
+			rtts = append(rtts, s.rttNs)
+		}
+		if !math.IsNaN(s.jitterNs) {
+			jitters = append(jitters, s.jitterNs)
+		}
+		losses = append(losses, s.lossPct)
+	}
+	if link == nil {
+		return response
+	}
+
+	response.RttPercentileRank = percentileRank(link.rttNs, rtts)
+	response.JitterPercentileRank = percentileRank(link.jitterNs, jitters)
+	response.LossPercentileRank = percentileRank(link.lossPct, losses)
+	if median := medianOf(rtts); median > 0 && !math.IsNaN(link.rttNs) {
+		response.RttVsPeerMedianPct = (link.rttNs - median) * 100 / median
+	}
+	return response
+}
+
+// percentileRank is the percentage of values strictly below v. It is 0 when v
+// is NaN or there are no values.
+func percentileRank(v float64, values []float64) float64 {
+	if math.IsNaN(v) || len(values) == 0 {
+		return 0
+	}
+	below := 0
+	for _, x := range values {
+		if x < v {
+			below++
+		}
+	}
+	return float64(below) * 100 / float64(len(values))
+}
+
+// medianOf returns the median of values, or 0 if there are none
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedLinkPeers inserts five WAN links with a ~10ms committed RTT measured at
+// 1-5ms over the past hour, plus a WAN link outside the committed RTT range
+// and a DZX link in range that should both be ignored.
+func seedLinkPeers(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		SELECT pk, now(), now(), generateUUIDv4(), 0, 1, pk, 'activated', upper(pk), '', '', 'dev-a', 'dev-z',
+		       '', '', link_type, committed_rtt_ns, 0, 0, 0
+		FROM values('pk String, link_type String, committed_rtt_ns Int64',
+			('peer-1', 'WAN', 10000000), ('peer-2', 'WAN', 9500000), ('peer-3', 'WAN', 10500000),
+			('peer-4', 'WAN', 10200000), ('peer-5', 'WAN', 9800000),
+			('peer-far', 'WAN', 50000000), ('peer-dzx', 'DZX', 10000000))`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT now() - INTERVAL 1 HOUR + INTERVAL 1 SECOND * number, now(), 1, number, 'dev-a', 'dev-z',
+		       'peer-' || toString(number % 5 + 1), (number % 5 + 1) * 1000, false, (number % 5 + 1) * 10
+		FROM numbers(100)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT now() - INTERVAL 1 HOUR, now(), 1, number, 'dev-a', 'dev-z', link_pk, 100000, false, 10000
+		FROM numbers(10) CROSS JOIN (SELECT arrayJoin(['peer-far', 'peer-dzx']) AS link_pk)`))
+}
+
+func getLinkPeerComparison(pk string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links/"+pk+"/peer-comparison", nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetLinkPeerComparison(rr, req)
+	return rr
+}
+
+func TestGetLinkPeerComparison(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkPeers(t)
+
+	rr := getLinkPeerComparison("peer-5")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.LinkPeerComparisonResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "peer-5", resp.LinkPK)
+	assert.Equal(t, 4, resp.PeerCount)
+	// The slowest of its peers at 5ms against a 2.5ms peer median
+	assert.InDelta(t, 100.0, resp.RttPercentileRank, 0.001)
+	assert.InDelta(t, 100.0, resp.JitterPercentileRank, 0.001)
+	assert.InDelta(t, 0.0, resp.LossPercentileRank, 0.001)
+	assert.InDelta(t, 100.0, resp.RttVsPeerMedianPct, 0.001)
+
+	rr = getLinkPeerComparison("peer-1")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.InDelta(t, 0.0, resp.RttPercentileRank, 0.001)
+	assert.InDelta(t, -71.43, resp.RttVsPeerMedianPct, 0.01)
+}
+
+func TestGetLinkPeerComparison_NotFound(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	assert.Equal(t, http.StatusNotFound, getLinkPeerComparison("missing").Code)
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// LinkRedundancyCheckResponse is the response for the link redundancy check endpoint
+type LinkRedundancyCheckResponse struct {
+	LinkPK       string `json:"linkPK"`
+	LinkCode     string `json:"linkCode"`
+	SideAPK      string `json:"sideAPK"`
+	SideZPK      string `json:"sideZPK"`
+	InTopology   bool   `json:"inTopology"` // both sides are ISIS adjacent
+	HasAlternate bool   `json:"hasAlternate"`
+	// AlternatePath is the shortest path between the sides without the link
+	AlternatePath []PathHop `json:"alternatePath,omitempty"`
+	HopDelta      int       `json:"hopDelta,omitempty"`    // extra hops over the direct adjacency
+	MetricDelta   int64     `json:"metricDelta,omitempty"` // extra ISIS metric over the direct adjacency
+	// MetroConnectivityImpact is true if losing the link leaves some pair of
+	// metros without any path between them
+	MetroConnectivityImpact bool `json:"metroConnectivityImpact"`
+}
+
+// GetLinkRedundancyCheck answers "if this link fails, is there another path?"
+// for a single link: the shortest path between its sides that doesn't use the
+// adjacency, and whether metro-to-metro connectivity would change. It is a
+// quick single-link version of PostMaintenanceImpact.
+func GetLinkRedundancyCheck(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
+		return
+	}
+
+	response := LinkRedundancyCheckResponse{LinkPK: pk}
+
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT code, side_a_pk, side_z_pk
+		FROM dz_links_current
+		WHERE pk = $1
+	`, pk).Scan(&response.LinkCode, &response.SideAPK, &response.SideZPK)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "link not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Link redundancy check link query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	start = time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("link_redundancy_check", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link redundancy check graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	a, okA := g.index[response.SideAPK]
+	z, okZ := g.index[response.SideZPK]
+	if !okA || !okZ {
+		writeJSON(w, response)
+		return
+	}
+
+	direct := edgeWeight(g.adj, a, z)
+	response.InTopology = direct >= 0
+
+	path, metric := shortestPathWithout(g.adj, a, z)
+	if path != nil {
+		response.HasAlternate = true
+		for i, v := range path {
+			hop := PathHop{
+				DevicePK:   g.nodes[v].PK,
+				DeviceCode: g.nodes[v].Code,
+				Status:     g.nodes[v].Status,
+			}
+			if i > 0 {
+				hop.EdgeMetric = uint32(edgeWeight(g.adj, path[i-1], v))
+			}
+			response.AlternatePath = append(response.AlternatePath, hop)
+		}
+		if response.InTopology {
+			response.HopDelta = len(path) - 2
+			response.MetricDelta = metric - direct
+		}
+	} else if response.InTopology {
+		response.MetroConnectivityImpact = splitsMetros(g, a, z)
+	}
+
+	writeJSON(w, response)
+}
+
+// shortestPathWithout returns the lowest-metric path from s to t that doesn't
+// use the direct s-t adjacency, and its total metric. The path is nil if t
+// can't be reached that way.
+func shortestPathWithout(adj [][]isisGraphEdge, s, t int) ([]int, int64) {
+	n := len(adj)
+	dist := make([]int64, n)
+	prev := make([]int, n)
+	for i := range dist {
+		dist[i] = -1
+		prev[i] = -1
+	}
+
+	dist[s] = 0
+	pq := &centralityQueue{{node: s}}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(centralityItem)
+		v := item.node
+		if item.dist > dist[v] {
+			continue // stale entry
+		}
+		if v == t {
+			break
+		}
+		for _, e := range adj[v] {
+			if (v == s && e.to == t) || (v == t && e.to == s) {
+				continue
+			}
+			alt := dist[v] + e.weight
+			if dist[e.to] < 0 || alt < dist[e.to] {
+				dist[e.to] = alt
+				prev[e.to] = v
+				heap.Push(pq, centralityItem{node: e.to, dist: alt})
+			}
+		}
+	}
+	if dist[t] < 0 {
+		return nil, 0
+	}
+
+	var path []int
+	for v := t; v != -1; v = prev[v] {
+		path = append(path, v)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist[t]
+}
+
+// splitsMetros reports whether removing the a-z adjacency leaves two metros
+// that were connected with no path between any of their devices. It only needs
+// to be called when the adjacency is a bridge, i.e. a and z end up in
+// different components.
+func splitsMetros(g *isisGraph, a, z int) bool {
+	comp := make([]int, len(g.nodes))
+	for i := range comp {
+		comp[i] = -1
+	}
+	next := 0
+	for root := range g.nodes {
+		if comp[root] >= 0 {
+			continue
+		}
+		comp[root] = next
+		stack := []int{root}
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range g.adj[v] {
+				if (v == a && e.to == z) || (v == z && e.to == a) || comp[e.to] >= 0 {
+					continue
+				}
+				comp[e.to] = next
+				stack = append(stack, e.to)
+			}
+		}
+		next++
+	}
+
+	// Components each metro has devices in
+	metroComps := make(map[string]map[int]bool)
+	for i, node := range g.nodes {
+		if node.MetroPK == "" {
+			continue
+		}
+		if metroComps[node.MetroPK] == nil {
+			metroComps[node.MetroPK] = make(map[int]bool)
+		}
+		metroComps[node.MetroPK][comp[i]] = true
+	}
+
+	// Only metros on either side of the split can have lost connectivity
+	for m1, comps1 := range metroComps {
+		if !comps1[comp[a]] {
+			continue
+		}
+		for m2, comps2 := range metroComps {
+			if m1 == m2 || !comps2[comp[z]] {
+				continue
+			}
+			shared := false
+			for c := range comps1 {
+				if comps2[c] {
+					shared = true
+					break
+				}
+			}
+			if !shared {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShortestPathWithout_FindsDetour(t *testing.T) {
+	// Square: without 0-1 the only way round is 0-3-2-1
+	adj := undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}})
+
+	path, metric := shortestPathWithout(adj, 0, 1)
+	if !slices.Equal(path, []int{0, 3, 2, 1}) {
+		t.Errorf("expected path [0 3 2 1], got %v", path)
+	}
+	if metric != 3 {
+		t.Errorf("expected metric 3, got %d", metric)
+	}
+}
+
+func TestShortestPathWithout_Bridge(t *testing.T) {
+	// 0 - 1 - 2: the 1-2 edge is the only way to reach 2
+	adj := undirectedGraph(3, [][2]int{{0, 1}, {1, 2}})
+
+	if path, _ := shortestPathWithout(adj, 1, 2); path != nil {
+		t.Errorf("expected no alternate path, got %v", path)
+	}
+	if path, _ := shortestPathWithout(adj, 2, 1); path != nil {
+		t.Errorf("expected no alternate path in reverse, got %v", path)
+	}
+}
+
+func TestSplitsMetros(t *testing.T) {
+	nodes := []isisGraphNode{
+		{PK: "a1", MetroPK: "ams"},
+		{PK: "a2", MetroPK: "ams"},
+		{PK: "f1", MetroPK: "fra"},
+		{PK: "f2", MetroPK: "fra"},
+	}
+
+	// ams - fra over a single link cuts the metros apart
+	g := &isisGraph{nodes: nodes, adj: undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}})}
+	if !splitsMetros(g, 1, 2) {
+		t.Error("expected losing the only inter-metro link to split metros")
+	}
+
+	// A leaf device inside a metro only disconnects itself
+	if splitsMetros(g, 2, 3) {
+		t.Error("expected intra-metro leaf link not to split metros")
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// slaBreachFactor is how far a bucket's p95 RTT may exceed the committed RTT
+// before the bucket counts as an SLA breach.
+const slaBreachFactor = 1.1
+
+// LinkSLAComplianceResponse is the response for the link SLA compliance endpoint
+type LinkSLAComplianceResponse struct {
+	LinkPK            string  `json:"linkPK"`
+	LinkCode          string  `json:"linkCode"`
+	Window            string  `json:"window"`
+	WindowStart       string  `json:"windowStart"`
+	WindowEnd         string  `json:"windowEnd"`
+	CommittedRttNs    int64   `json:"committedRttNs"`
+	CommittedJitterNs int64   `json:"committedJitterNs"`
+	P50RttNs          float64 `json:"p50RttNs"`
+	P95RttNs          float64 `json:"p95RttNs"`
+	P99RttNs          float64 `json:"p99RttNs"`
+	AvgJitterNs       float64 `json:"avgJitterNs"`
+	LossPct           float64 `json:"lossPct"`
+	BucketCount       uint64  `json:"bucketCount"`
+	SLABreachCount    uint64  `json:"slaBreachCount"`
+	SLACompliancePct  float64 `json:"slaCompliancePct"`
+}
+
+// GetLinkSLACompliance reports whether a link is meeting its committed RTT
+// over a window (24h, 7d, 30d). The window is split into 5-minute buckets and
+// a bucket is a breach when its p95 RTT exceeds the committed RTT by more than
+// 10%. Links without a committed RTT never breach. Pass format=csv to download
+// the report as CSV.
+func GetLinkSLACompliance(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
+		return
+	}
+
+	window := r.URL.Query().Get("window")
+	var windowDuration time.Duration
+	switch window {
+	case "", "24h":
+		window = "24h"
+		windowDuration = 24 * time.Hour
+	case "7d":
+		windowDuration = 7 * 24 * time.Hour
+	case "30d":
+		windowDuration = 30 * 24 * time.Hour
+	default:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "window must be one of 24h, 7d, 30d")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "format must be json or csv")
+		return
+	}
+
+	start := time.Now()
+	windowEnd := start.UTC().Truncate(time.Second)
+	windowStart := windowEnd.Add(-windowDuration)
+
+	response := LinkSLAComplianceResponse{
+		LinkPK:      pk,
+		Window:      window,
+		WindowStart: windowStart.Format(time.RFC3339),
+		WindowEnd:   windowEnd.Format(time.RFC3339),
+	}
+
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT code, COALESCE(committed_rtt_ns, 0), COALESCE(committed_jitter_ns, 0)
+		FROM dz_links_current
+		WHERE pk = $1
+	`, pk).Scan(&response.LinkCode, &response.CommittedRttNs, &response.CommittedJitterNs)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "link not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Link SLA compliance link query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	// Lost samples carry no RTT, so they only count toward loss. Buckets where
+	// every sample was lost have no p95 and are excluded from the breach count.
+	var p50, p95, p99, jitter *float64
+	err = envDB(ctx).QueryRow(ctx, `
+		WITH buckets AS (
+			SELECT
+				toStartOfInterval(event_ts, INTERVAL 5 MINUTE) AS bucket,
+				quantileIf(0.95)(rtt_us, NOT loss) * 1000 AS p95_rtt_ns
+			FROM fact_dz_device_link_latency
+			WHERE link_pk = $1 AND event_ts >= $2 AND event_ts < $3
+			GROUP BY bucket
+		),
+		breaches AS (
+			SELECT
+				countIf(NOT isNaN(p95_rtt_ns)) AS bucket_count,
+				countIf(NOT isNaN(p95_rtt_ns) AND $4 > 0 AND p95_rtt_ns > $4 * $5) AS breach_count
+			FROM buckets
+		),
+		overall AS (
+			SELECT
+				quantileIf(0.5)(rtt_us, NOT loss) * 1000 AS p50_rtt_ns,
+				quantileIf(0.95)(rtt_us, NOT loss) * 1000 AS p95_rtt_ns,
+				quantileIf(0.99)(rtt_us, NOT loss) * 1000 AS p99_rtt_ns,
+				avgIf(abs(ipdv_us), NOT loss) * 1000 AS avg_jitter_ns,
+				if(count(*) > 0, countIf(loss) * 100.0 / count(*), 0) AS loss_pct
+			FROM fact_dz_device_link_latency
+			WHERE link_pk = $1 AND event_ts >= $2 AND event_ts < $3
+		)
+		SELECT
+			overall.p50_rtt_ns, overall.p95_rtt_ns, overall.p99_rtt_ns, overall.avg_jitter_ns, overall.loss_pct,
+			breaches.bucket_count, breaches.breach_count
+		FROM overall CROSS JOIN breaches
+	`, pk, windowStart, windowEnd, response.CommittedRttNs, slaBreachFactor).Scan(
+		&p50, &p95, &p99, &jitter, &response.LossPct,
+		&response.BucketCount, &response.SLABreachCount,
+	)
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link SLA compliance query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	response.P50RttNs = finiteOrZero(p50)
+	response.P95RttNs = finiteOrZero(p95)
+	response.P99RttNs = finiteOrZero(p99)
+	response.AvgJitterNs = finiteOrZero(jitter)
+	if response.BucketCount > 0 {
+		response.SLACompliancePct = float64(response.BucketCount-response.SLABreachCount) * 100 / float64(response.BucketCount)
+	}
+
+	if format == "csv" {
+		writeLinkSLAComplianceCSV(w, response)
+		return
+	}
+	writeJSON(w, response)
+}
+
+// writeLinkSLAComplianceCSV writes the report as a single-row CSV for monthly SLA reports
+func writeLinkSLAComplianceCSV(w http.ResponseWriter, resp LinkSLAComplianceResponse) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=link-sla-%s-%s.csv", resp.LinkCode, resp.Window))
+
+	_, _ = w.Write([]byte("link_pk,link_code,window_start,window_end,committed_rtt_ns,committed_jitter_ns,p50_rtt_ns,p95_rtt_ns,p99_rtt_ns,avg_jitter_ns,loss_pct,bucket_count,sla_breach_count,sla_compliance_pct\n"))
+
+	line := fmt.Sprintf("%s,%s,%s,%s,%d,%d,%s,%s,%s,%s,%s,%d,%d,%s\n",
+		resp.LinkPK, resp.LinkCode, resp.WindowStart, resp.WindowEnd,
+		resp.CommittedRttNs, resp.CommittedJitterNs,
+		formatCSVFloat(resp.P50RttNs), formatCSVFloat(resp.P95RttNs), formatCSVFloat(resp.P99RttNs),
+		formatCSVFloat(resp.AvgJitterNs), formatCSVFloat(resp.LossPct),
+		resp.BucketCount, resp.SLABreachCount, formatCSVFloat(resp.SLACompliancePct))
+	_, _ = w.Write([]byte(line))
+}
+
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedLinkSLA inserts link-sla with a 10ms committed RTT and two 5-minute
+// buckets an hour ago: one at 5ms (compliant) and one at 20ms (breach),
+// plus one lost sample.
+func seedLinkSLA(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-sla', now(), now(), generateUUIDv4(), 0, 1, 'link-sla', 'activated', 'LINK-SLA', '', '', 'dev-a', 'dev-z',
+		 '', '', 'WAN', 10000000, 2000000, 0, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT toStartOfInterval(now() - INTERVAL 1 HOUR, INTERVAL 5 MINUTE) + INTERVAL 1 SECOND * (if(number < 50, number, 600 + number)),
+		       now(), 1, number, 'dev-a', 'dev-z', 'link-sla',
+		       if(number = 99, 0, if(number < 50, 5000, 20000)), number = 99, 100
+		FROM numbers(100)`))
+}
+
+func getLinkSLACompliance(pk, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links/"+pk+"/sla-compliance"+query, nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetLinkSLACompliance(rr, req)
+	return rr
+}
+
+func TestGetLinkSLACompliance(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkSLA(t)
+
+	rr := getLinkSLACompliance("link-sla", "?window=7d")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.LinkSLAComplianceResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "LINK-SLA", resp.LinkCode)
+	assert.Equal(t, "7d", resp.Window)
+	assert.NotEmpty(t, resp.WindowStart)
+	assert.NotEmpty(t, resp.WindowEnd)
+	assert.Equal(t, int64(10000000), resp.CommittedRttNs)
+	assert.Equal(t, int64(2000000), resp.CommittedJitterNs)
+	assert.Equal(t, uint64(2), resp.BucketCount)
+	assert.Equal(t, uint64(1), resp.SLABreachCount)
+	assert.InDelta(t, 50.0, resp.SLACompliancePct, 0.001)
+	assert.InDelta(t, 1.0, resp.LossPct, 0.001)
+	assert.InDelta(t, 100000.0, resp.AvgJitterNs, 0.001)
+	assert.InDelta(t, 20000000.0, resp.P99RttNs, 1.0)
+}
+
+func TestGetLinkSLACompliance_CSV(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkSLA(t)
+
+	rr := getLinkSLACompliance("link-sla", "?format=csv")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Header().Get("Content-Disposition"), "link-sla-LINK-SLA-24h.csv")
+
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "link_pk,link_code,"))
+	assert.True(t, strings.HasPrefix(lines[1], "link-sla,LINK-SLA,"))
+	assert.True(t, strings.HasSuffix(lines[1], ",2,1,50.00"))
+}
+
+func TestGetLinkSLACompliance_Errors(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkSLA(t)
+
+	assert.Equal(t, http.StatusNotFound, getLinkSLACompliance("missing", "").Code)
+	assert.Equal(t, http.StatusBadRequest, getLinkSLACompliance("link-sla", "?window=1h").Code)
+	assert.Equal(t, http.StatusBadRequest, getLinkSLACompliance("link-sla", "?format=xml").Code)
+}
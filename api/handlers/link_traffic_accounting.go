@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// maxLinkTrafficUsers caps how many users the traffic accounting endpoint returns
+const maxLinkTrafficUsers = 20
+
+// LinkUserTraffic is one user's traffic on a link's endpoint devices
+type LinkUserTraffic struct {
+	UserPK          string  `json:"userPK"`
+	OwnerPubkey     string  `json:"ownerPubkey"`
+	InBps           float64 `json:"inBps"`
+	OutBps          float64 `json:"outBps"`
+	ShareOfTotalPct float64 `json:"shareOfTotalPct"` // of all user traffic on both endpoints
+}
+
+// GetLinkTrafficAccounting returns the users with the most traffic behind a
+// link. Counters aren't broken down by user per link, so a user's traffic is
+// its tunnel traffic on either of the link's endpoint devices, averaged over
+// ?window=1h|6h|24h (default 1h). In and out are from the user's side, as in
+// the user list. Only the top users are listed, but shares are of all of them.
+func GetLinkTrafficAccounting(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
+		return
+	}
+
+	var windowDuration time.Duration
+	switch r.URL.Query().Get("window") {
+	case "", "1h":
+		windowDuration = time.Hour
+	case "6h":
+		windowDuration = 6 * time.Hour
+	case "24h":
+		windowDuration = 24 * time.Hour
+	default:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "window must be one of 1h, 6h, 24h")
+		return
+	}
+
+	var sideAPK, sideZPK string
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT side_a_pk, side_z_pk
+		FROM dz_links_current
+		WHERE pk = ?
+	`, pk).Scan(&sideAPK, &sideZPK)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "link not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Link traffic accounting link query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	start = time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH traffic_rates AS (
+			SELECT
+				user_tunnel_id,
+				device_pk,
+				SUM(out_octets_delta) * 8 / SUM(delta_duration) AS in_bps,
+				SUM(in_octets_delta) * 8 / SUM(delta_duration) AS out_bps
+			FROM fact_dz_device_interface_counters
+			WHERE event_ts > ?
+				AND device_pk IN (?, ?)
+				AND user_tunnel_id IS NOT NULL
+				AND delta_duration > 0
+				AND (in_octets_delta >= 0 OR out_octets_delta >= 0)
+			GROUP BY user_tunnel_id, device_pk
+		)
+		SELECT
+			u.pk,
+			COALESCE(u.owner_pubkey, '') AS owner_pubkey,
+			COALESCE(tr.in_bps, 0) AS in_bps,
+			COALESCE(tr.out_bps, 0) AS out_bps
+		FROM dz_users_current u
+		JOIN traffic_rates tr ON u.tunnel_id = tr.user_tunnel_id AND u.device_pk = tr.device_pk
+	`, time.Now().Add(-windowDuration), sideAPK, sideZPK)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link traffic accounting query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var users []LinkUserTraffic
+	for rows.Next() {
+		var u LinkUserTraffic
+		if err := rows.Scan(&u.UserPK, &u.OwnerPubkey, &u.InBps, &u.OutBps); err != nil {
+			LoggerFromContext(ctx).Error("Link traffic accounting scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Link traffic accounting rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, rankLinkUserTraffic(users))
+}
+
+// rankLinkUserTraffic fills in each user's share of the total, sorts by total
+// traffic and keeps the top users
+func rankLinkUserTraffic(users []LinkUserTraffic) []LinkUserTraffic {
+	var total float64
+	for _, u := range users {
+		total += u.InBps + u.OutBps
+	}
+	for i := range users {
+		if total > 0 {
+			users[i].ShareOfTotalPct = (users[i].InBps + users[i].OutBps) * 100 / total
+		}
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		ti, tj := users[i].InBps+users[i].OutBps, users[j].InBps+users[j].OutBps
+		if ti != tj {
+			return ti > tj
+		}
+		return users[i].UserPK < users[j].UserPK
+	})
+	if len(users) > maxLinkTrafficUsers {
+		users = users[:maxLinkTrafficUsers]
+	}
+	if users == nil {
+		users = []LinkUserTraffic{}
+	}
+	return users
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedLinkTrafficAccounting inserts a link between two devices with a user on
+// each end and one on an unrelated device. Over the last hour user-a sends
+// 3 Mbps and user-z sends 1 Mbps; user-a's 2-hour-old sample only counts in
+// longer windows.
+func seedLinkTrafficAccounting(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('ta-link', now(), now(), generateUUIDv4(), 0, 1, 'ta-link', 'activated', 'AMS-FRA', '', '', 'ta-a', 'ta-z', '', '', 'WAN', 0, 0, 10000000000, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_users_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, owner_pubkey, status, kind, client_ip, dz_ip, device_pk, tunnel_id)
+		VALUES
+		('user-a', now(), now(), generateUUIDv4(), 0, 1, 'user-a', 'owner-a', 'activated', 'ibrl', '', '', 'ta-a', 501),
+		('user-z', now(), now(), generateUUIDv4(), 0, 2, 'user-z', 'owner-z', 'activated', 'ibrl', '', '', 'ta-z', 501),
+		('user-other', now(), now(), generateUUIDv4(), 0, 3, 'user-other', 'owner-other', 'activated', 'ibrl', '', '', 'ta-other', 501)`))
+
+	// The device sends what the user receives, so out_octets is the user's in
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, user_tunnel_id, in_octets_delta, out_octets_delta, delta_duration)
+		VALUES
+		(now() - INTERVAL 10 MINUTE, now(), 'ta-a', 'Tunnel501', 501, 15000000, 7500000, 60.0),
+		(now() - INTERVAL 2 HOUR, now(), 'ta-a', 'Tunnel501', 501, 0, 0, 60.0),
+		(now() - INTERVAL 10 MINUTE, now(), 'ta-z', 'Tunnel501', 501, 7500000, 0, 60.0),
+		(now() - INTERVAL 10 MINUTE, now(), 'ta-other', 'Tunnel501', 501, 75000000, 0, 60.0)`))
+}
+
+func getLinkTrafficAccounting(pk, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links/"+pk+"/traffic-accounting"+query, nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetLinkTrafficAccounting(rr, req)
+	return rr
+}
+
+func TestGetLinkTrafficAccounting(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkTrafficAccounting(t)
+
+	rr := getLinkTrafficAccounting("ta-link", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var users []handlers.LinkUserTraffic
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&users))
+	require.Len(t, users, 2)
+
+	assert.Equal(t, "user-a", users[0].UserPK)
+	assert.Equal(t, "owner-a", users[0].OwnerPubkey)
+	assert.InDelta(t, 1000000.0, users[0].InBps, 1)
+	assert.InDelta(t, 2000000.0, users[0].OutBps, 1)
+	assert.InDelta(t, 75.0, users[0].ShareOfTotalPct, 0.001)
+
+	assert.Equal(t, "user-z", users[1].UserPK)
+	assert.InDelta(t, 1000000.0, users[1].OutBps, 1)
+	assert.InDelta(t, 25.0, users[1].ShareOfTotalPct, 0.001)
+
+	// The older empty sample halves user-a's average over 6 hours
+	rr = getLinkTrafficAccounting("ta-link", "?window=6h")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&users))
+	require.Len(t, users, 2)
+	assert.InDelta(t, 1500000.0, users[0].InBps+users[0].OutBps, 1)
+	assert.InDelta(t, 60.0, users[0].ShareOfTotalPct, 0.001)
+}
+
+func TestGetLinkTrafficAccounting_Errors(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkTrafficAccounting(t)
+
+	assert.Equal(t, http.StatusBadRequest, getLinkTrafficAccounting("ta-link", "?window=7d").Code)
+	assert.Equal(t, http.StatusNotFound, getLinkTrafficAccounting("nonexistent", "").Code)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// linkUtilizationCriticalPct is the utilization above which a link is flagged critical
+const linkUtilizationCriticalPct = 90.0
+
+// LinkUtilization is a link's traffic relative to its provisioned bandwidth
+type LinkUtilization struct {
+	LinkPK         string  `json:"linkPK"`
+	LinkCode       string  `json:"linkCode"`
+	SideACode      string  `json:"sideACode"`
+	SideZCode      string  `json:"sideZCode"`
+	IngressBps     float64 `json:"ingressBps"`
+	EgressBps      float64 `json:"egressBps"`
+	BandwidthBps   int64   `json:"bandwidthBps"`
+	UtilizationPct float64 `json:"utilizationPct"`
+	DiscardsPps    float64 `json:"discardsPps"`
+	Severity       string  `json:"severity"`
+}
+
+// LinkUtilizationResponse is the response for the link utilization endpoint
+type LinkUtilizationResponse struct {
+	Threshold float64           `json:"threshold"`
+	Links     []LinkUtilization `json:"links"`
+}
+
+// linkUtilizationSeverity classifies a link's utilization percentage
+func linkUtilizationSeverity(utilizationPct float64) string {
+	if utilizationPct > linkUtilizationCriticalPct {
+		return "critical"
+	}
+	return "normal"
+}
+
+// GetLinkUtilization returns per-link utilization over the past hour, computed
+// from interface counters against each link's bandwidth_bps. Utilization is the
+// busier of the two directions. Links without a bandwidth are skipped. Pass
+// threshold to only return links at or above a utilization percentage.
+func GetLinkUtilization(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	threshold := 0.0
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(t) || t < 0 || t > 100 {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "threshold must be a number between 0 and 100")
+			return
+		}
+		threshold = t
+	}
+
+	start := time.Now()
+	query := `
+		WITH traffic_rates AS (
+			SELECT
+				link_pk,
+				SUM(in_octets_delta) * 8 / SUM(delta_duration) as in_bps,
+				SUM(out_octets_delta) * 8 / SUM(delta_duration) as out_bps,
+				(SUM(greatest(0, COALESCE(in_discards_delta, 0))) + SUM(greatest(0, COALESCE(out_discards_delta, 0))))
+					/ SUM(delta_duration) as discards_pps
+			FROM fact_dz_device_interface_counters
+			WHERE event_ts > now() - INTERVAL 1 HOUR
+				AND link_pk != ''
+				AND delta_duration > 0
+				AND in_octets_delta >= 0
+				AND out_octets_delta >= 0
+			GROUP BY link_pk
+		)
+		SELECT
+			l.pk,
+			l.code,
+			COALESCE(da.code, '') as side_a_code,
+			COALESCE(dz.code, '') as side_z_code,
+			tr.in_bps,
+			tr.out_bps,
+			l.bandwidth_bps,
+			greatest(tr.in_bps, tr.out_bps) * 100.0 / l.bandwidth_bps as utilization_pct,
+			tr.discards_pps
+		FROM dz_links_current l
+		JOIN traffic_rates tr ON l.pk = tr.link_pk
+		LEFT JOIN dz_devices_current da ON l.side_a_pk = da.pk
+		LEFT JOIN dz_devices_current dz ON l.side_z_pk = dz.pk
+		WHERE l.bandwidth_bps > 0
+			AND utilization_pct >= ?
+		ORDER BY utilization_pct DESC, l.code
+	`
+
+	rows, err := envDB(ctx).Query(ctx, query, threshold)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("Link utilization query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	links := []LinkUtilization{}
+	for rows.Next() {
+		var l LinkUtilization
+		if err := rows.Scan(
+			&l.LinkPK, &l.LinkCode, &l.SideACode, &l.SideZCode,
+			&l.IngressBps, &l.EgressBps, &l.BandwidthBps, &l.UtilizationPct, &l.DiscardsPps,
+		); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(start), err)
+			LoggerFromContext(ctx).Error("Link utilization scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		l.Severity = linkUtilizationSeverity(l.UtilizationPct)
+		links = append(links, l)
+	}
+	err = rows.Err()
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link utilization rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, LinkUtilizationResponse{Threshold: threshold, Links: links})
+}
@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedLinkUtilization inserts a 1G link running at ~93% (critical) and a 10G
+// link running at 10%, each with one minute of counters in the last hour.
+func seedLinkUtilization(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('util-a', now(), now(), generateUUIDv4(), 0, 1, 'util-a', 'activated', 'hybrid', 'AMS-1', '', '', '', 0),
+		('util-z', now(), now(), generateUUIDv4(), 0, 2, 'util-z', 'activated', 'hybrid', 'FRA-1', '', '', '', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('util-hot', now(), now(), generateUUIDv4(), 0, 1, 'util-hot', 'activated', 'AMS-FRA-1', '', '', 'util-a', 'util-z', '', '', 'WAN', 0, 0, 1000000000, 0),
+		('util-cool', now(), now(), generateUUIDv4(), 0, 2, 'util-cool', 'activated', 'AMS-FRA-2', '', '', 'util-a', 'util-z', '', '', 'WAN', 0, 0, 10000000000, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, link_pk, in_octets_delta, out_octets_delta, delta_duration, in_discards_delta, out_discards_delta)
+		VALUES
+		(now() - INTERVAL 10 MINUTE, now(), 'util-a', 'Port-Channel1000', 'util-hot', 7000000000, 750000000, 60.0, 60, 0),
+		(now() - INTERVAL 10 MINUTE, now(), 'util-a', 'Port-Channel1001', 'util-cool', 7500000000, 750000000, 60.0, 0, 0),
+		(now() - INTERVAL 2 HOUR, now(), 'util-a', 'Port-Channel1001', 'util-cool', 75000000000, 0, 60.0, 0, 0)`))
+}
+
+func getLinkUtilization(query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/link-utilization"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetLinkUtilization(rr, req)
+	return rr
+}
+
+func TestGetLinkUtilization(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkUtilization(t)
+
+	rr := getLinkUtilization("")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.LinkUtilizationResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.Links, 2)
+
+	hot := resp.Links[0]
+	assert.Equal(t, "util-hot", hot.LinkPK)
+	assert.Equal(t, "AMS-FRA-1", hot.LinkCode)
+	assert.Equal(t, "AMS-1", hot.SideACode)
+	assert.Equal(t, "FRA-1", hot.SideZCode)
+	assert.InDelta(t, 933333333.3, hot.IngressBps, 1)
+	assert.InDelta(t, 100000000.0, hot.EgressBps, 1)
+	assert.Equal(t, int64(1000000000), hot.BandwidthBps)
+	assert.InDelta(t, 93.33, hot.UtilizationPct, 0.01)
+	assert.InDelta(t, 1.0, hot.DiscardsPps, 0.001)
+	assert.Equal(t, "critical", hot.Severity)
+
+	cool := resp.Links[1]
+	assert.Equal(t, "util-cool", cool.LinkPK)
+	assert.InDelta(t, 10.0, cool.UtilizationPct, 0.01)
+	assert.Equal(t, "normal", cool.Severity)
+}
+
+func TestGetLinkUtilization_Threshold(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkUtilization(t)
+
+	rr := getLinkUtilization("?threshold=80")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.LinkUtilizationResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, 80.0, resp.Threshold)
+	require.Len(t, resp.Links, 1)
+	assert.Equal(t, "util-hot", resp.Links[0].LinkPK)
+
+	for _, q := range []string{"?threshold=abc", "?threshold=-1", "?threshold=101"} {
+		rr := getLinkUtilization(q)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, q)
+	}
+}
+
+func TestGetLinkUtilization_Empty(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	rr := getLinkUtilization("")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.LinkUtilizationResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.NotNil(t, resp.Links)
+	assert.Empty(t, resp.Links)
+}
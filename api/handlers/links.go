@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -39,19 +43,148 @@ type LinkListItem struct {
 	LossPercent     float64 `json:"loss_percent"`
 }
 
+// Serviceability link statuses and types accepted by the GetLinks filters
+var (
+	linkStatusValues = []string{
+		"pending", "activated", "suspended", "deleted", "rejected", "requested",
+		"soft-drained", "hard-drained", "provisioning",
+	}
+	linkTypeValues = []string{"WAN", "DZX"}
+)
+
+// linkListFilter holds the optional GetLinks filters. Each list matches any of
+// its values; non-empty filters are combined with AND.
+type linkListFilter struct {
+	Statuses          []string
+	LinkTypes         []string
+	SideAMetroPKs     []string
+	SideZMetroPKs     []string
+	ContributorPKs    []string
+	MinBandwidthBps   int64
+	MaxCommittedRttNs int64
+	HasLatencyData    bool
+}
+
+// parseLinkListFilter parses the status, link_type, side_a_metro_pk,
+// side_z_metro_pk, contributor_pk, min_bandwidth_bps, max_committed_rtt_ns and
+// has_latency_data query parameters. All list parameters are comma-separated;
+// status and link_type must be known enum values.
+func parseLinkListFilter(r *http.Request) (linkListFilter, error) {
+	q := r.URL.Query()
+	f := linkListFilter{
+		Statuses:       splitCSV(q.Get("status")),
+		LinkTypes:      splitCSV(q.Get("link_type")),
+		SideAMetroPKs:  splitCSV(q.Get("side_a_metro_pk")),
+		SideZMetroPKs:  splitCSV(q.Get("side_z_metro_pk")),
+		ContributorPKs: splitCSV(q.Get("contributor_pk")),
+	}
+	for _, s := range f.Statuses {
+		if !slices.Contains(linkStatusValues, s) {
+			return f, fmt.Errorf("invalid status %q: must be one of %s", s, strings.Join(linkStatusValues, ", "))
+		}
+	}
+	for _, t := range f.LinkTypes {
+		if !slices.Contains(linkTypeValues, t) {
+			return f, fmt.Errorf("invalid link_type %q: must be one of %s", t, strings.Join(linkTypeValues, ", "))
+		}
+	}
+	if s := q.Get("min_bandwidth_bps"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v < 0 {
+			return f, fmt.Errorf("min_bandwidth_bps must be a non-negative integer")
+		}
+		f.MinBandwidthBps = v
+	}
+	if s := q.Get("max_committed_rtt_ns"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v <= 0 {
+			return f, fmt.Errorf("max_committed_rtt_ns must be a positive integer")
+		}
+		f.MaxCommittedRttNs = v
+	}
+	switch q.Get("has_latency_data") {
+	case "", "false":
+	case "true":
+		f.HasLatencyData = true
+	default:
+		return f, fmt.Errorf("has_latency_data must be true or false")
+	}
+	return f, nil
+}
+
+// whereClause returns the WHERE clause (empty if unfiltered) and its arguments
+// for a query over dz_links_current aliased as l. Side metros are matched
+// through the side devices so the clause works without joins. Links without a
+// committed RTT never match max_committed_rtt_ns.
+func (f linkListFilter) whereClause() (string, []any) {
+	var conditions []string
+	var args []any
+	if len(f.Statuses) > 0 {
+		conditions = append(conditions, "l.status IN (?)")
+		args = append(args, f.Statuses)
+	}
+	if len(f.LinkTypes) > 0 {
+		conditions = append(conditions, "l.link_type IN (?)")
+		args = append(args, f.LinkTypes)
+	}
+	if len(f.SideAMetroPKs) > 0 {
+		conditions = append(conditions, "l.side_a_pk IN (SELECT pk FROM dz_devices_current WHERE metro_pk IN (?))")
+		args = append(args, f.SideAMetroPKs)
+	}
+	if len(f.SideZMetroPKs) > 0 {
+		conditions = append(conditions, "l.side_z_pk IN (SELECT pk FROM dz_devices_current WHERE metro_pk IN (?))")
+		args = append(args, f.SideZMetroPKs)
+	}
+	if len(f.ContributorPKs) > 0 {
+		conditions = append(conditions, "l.contributor_pk IN (?)")
+		args = append(args, f.ContributorPKs)
+	}
+	if f.MinBandwidthBps > 0 {
+		conditions = append(conditions, "l.bandwidth_bps >= ?")
+		args = append(args, f.MinBandwidthBps)
+	}
+	if f.MaxCommittedRttNs > 0 {
+		conditions = append(conditions, "l.committed_rtt_ns > 0 AND l.committed_rtt_ns <= ?")
+		args = append(args, f.MaxCommittedRttNs)
+	}
+	if f.HasLatencyData {
+		conditions = append(conditions, `l.pk IN (
+			SELECT link_pk
+			FROM fact_dz_device_link_latency
+			WHERE event_ts > now() - INTERVAL 1 HOUR
+		)`)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// GetLinks returns a page of links. Optional query parameters filter the list:
+// status and link_type (comma-separated enum values), side_a_metro_pk,
+// side_z_metro_pk and contributor_pk (comma-separated), min_bandwidth_bps,
+// max_committed_rtt_ns, and has_latency_data=true for links measured in the
+// past hour.
 func GetLinks(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
+	filter, err := parseLinkListFilter(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	where, whereArgs := filter.whereClause()
+
 	pagination := ParsePagination(r, 100)
 	start := time.Now()
 
 	// Get total count
-	countQuery := `SELECT count(*) FROM dz_links_current`
+	countQuery := `SELECT count(*) FROM dz_links_current l ` + where
 	var total uint64
-	if err := envDB(ctx).QueryRow(ctx, countQuery).Scan(&total); err != nil {
-		log.Printf("Links count error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := envDB(ctx).QueryRow(ctx, countQuery, whereArgs...).Scan(&total); err != nil {
+		LoggerFromContext(ctx).Error("Links count error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -114,17 +247,19 @@ func GetLinks(w http.ResponseWriter, r *http.Request) {
 		LEFT JOIN dz_contributors_current c ON l.contributor_pk = c.pk
 		LEFT JOIN traffic_rates tr ON l.pk = tr.link_pk
 		LEFT JOIN latency_stats ls ON l.pk = ls.link_pk
+		` + where + `
 		ORDER BY l.code
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := envDB(ctx).Query(ctx, query, pagination.Limit, pagination.Offset)
+	args := append(whereArgs, pagination.Limit, pagination.Offset)
+	rows, err := envDB(ctx).Query(ctx, query, args...)
 	duration := time.Since(start)
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Links query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Links query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -154,16 +289,16 @@ func GetLinks(w http.ResponseWriter, r *http.Request) {
 			&l.JitterUs,
 			&l.LossPercent,
 		); err != nil {
-			log.Printf("Links scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Links scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		links = append(links, l)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Links rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Links rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -181,7 +316,7 @@ func GetLinks(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -220,23 +355,32 @@ type LinkDetail struct {
 	ISISDelayOverrideNs int64   `json:"isis_delay_override_ns"`
 }
 
-// TopologyLinkHealth represents the SLA health status of a link for topology overlay
+// TopologyLinkHealth represents the SLA health status of a link for topology overlay.
+// Measurements cover the past hour. SlaStatus is one of:
+//   - "critical": the link is down, loss is over 10%, or avg RTT is at least 2x committed
+//   - "warning": loss is over 0.1%, or avg RTT is at least 1.5x committed
+//   - "healthy": within both thresholds
+//   - "unknown": the link is dark (no recent samples) or has no committed RTT
 type TopologyLinkHealth struct {
-	LinkPK         string  `json:"link_pk"`
-	SideAPK        string  `json:"side_a_pk"`
-	SideACode      string  `json:"side_a_code"`
-	SideZPK        string  `json:"side_z_pk"`
-	SideZCode      string  `json:"side_z_code"`
-	AvgRttUs       float64 `json:"avg_rtt_us"`
-	P95RttUs       float64 `json:"p95_rtt_us"`
-	CommittedRttNs int64   `json:"committed_rtt_ns"`
-	LossPct        float64 `json:"loss_pct"`
-	ExceedsCommit  bool    `json:"exceeds_commit"`
-	HasPacketLoss  bool    `json:"has_packet_loss"`
-	IsDark         bool    `json:"is_dark"`
-	IsDown         bool    `json:"is_down"`
-	SlaStatus      string  `json:"sla_status"` // "healthy", "warning", "critical", "unknown"
-	SlaRatio       float64 `json:"sla_ratio"`  // measured / committed (0 if no commitment)
+	LinkPK         string     `json:"link_pk"`
+	LinkCode       string     `json:"link_code"`
+	Status         string     `json:"status"`
+	SideAPK        string     `json:"side_a_pk"`
+	SideACode      string     `json:"side_a_code"`
+	SideZPK        string     `json:"side_z_pk"`
+	SideZCode      string     `json:"side_z_code"`
+	AvgRttUs       float64    `json:"avg_rtt_us"`
+	P95RttUs       float64    `json:"p95_rtt_us"`
+	JitterUs       float64    `json:"jitter_us"` // mean absolute IPDV
+	CommittedRttNs int64      `json:"committed_rtt_ns"`
+	LossPct        float64    `json:"loss_pct"`
+	LastMeasuredAt *time.Time `json:"last_measured_at"` // nil if no samples in the past hour
+	ExceedsCommit  bool       `json:"exceeds_commit"`
+	HasPacketLoss  bool       `json:"has_packet_loss"`
+	IsDark         bool       `json:"is_dark"`
+	IsDown         bool       `json:"is_down"`
+	SlaStatus      string     `json:"sla_status"` // "healthy", "warning", "critical", "unknown"
+	SlaRatio       float64    `json:"sla_ratio"`  // measured / committed (0 if no commitment)
 }
 
 type TopologyLinkHealthResponse struct {
@@ -248,22 +392,46 @@ type TopologyLinkHealthResponse struct {
 	UnknownCount  int                  `json:"unknown_count"`
 }
 
+// GetLinkHealth returns the SLA health of every link with both sides set.
+// Pass sort=rtt, sort=jitter or sort=loss to order links worst first.
 func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy != "" && sortBy != "rtt" && sortBy != "jitter" && sortBy != "loss" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "sort must be one of rtt, jitter, loss")
+		return
+	}
+
 	start := time.Now()
 	query := `
+		WITH recent_jitter AS (
+			SELECT
+				link_pk,
+				count(*) AS samples,
+				avgIf(abs(ipdv_us), NOT loss) AS jitter_us,
+				max(event_ts) AS last_ts
+			FROM fact_dz_device_link_latency
+			WHERE event_ts >= now() - INTERVAL 1 HOUR
+			  AND link_pk != ''
+			GROUP BY link_pk
+		)
 		SELECT
 			h.pk AS link_pk,
+			l.code,
+			l.status,
 			l.side_a_pk,
 			COALESCE(da.code, '') AS side_a_code,
 			l.side_z_pk,
 			COALESCE(dz.code, '') AS side_z_code,
 			h.avg_rtt_us,
 			h.p95_rtt_us,
+			toFloat64(COALESCE(j.jitter_us, 0)) AS jitter_us,
 			h.committed_rtt_ns,
 			h.loss_pct,
+			toUInt64(COALESCE(j.samples, 0)) AS samples,
+			toDateTime64(COALESCE(j.last_ts, toDateTime(0)), 3) AS last_ts,
 			toUInt8(h.exceeds_committed_rtt) AS exceeds_committed_rtt,
 			toUInt8(h.has_packet_loss) AS has_packet_loss,
 			toUInt8(h.is_dark) AS is_dark,
@@ -272,6 +440,7 @@ func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 		JOIN dz_links_current l ON h.pk = l.pk
 		LEFT JOIN dz_devices_current da ON l.side_a_pk = da.pk
 		LEFT JOIN dz_devices_current dz ON l.side_z_pk = dz.pk
+		LEFT JOIN recent_jitter j ON h.pk = j.link_pk
 		WHERE l.side_a_pk != '' AND l.side_z_pk != ''
 	`
 
@@ -280,8 +449,8 @@ func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Link health query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Link health query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -291,30 +460,41 @@ func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 
 	for rows.Next() {
 		var lh TopologyLinkHealth
+		var samples uint64
+		var lastTs time.Time
 		var exceedsCommit, hasPacketLoss, isDark, isDown uint8
 		if err := rows.Scan(
 			&lh.LinkPK,
+			&lh.LinkCode,
+			&lh.Status,
 			&lh.SideAPK,
 			&lh.SideACode,
 			&lh.SideZPK,
 			&lh.SideZCode,
 			&lh.AvgRttUs,
 			&lh.P95RttUs,
+			&lh.JitterUs,
 			&lh.CommittedRttNs,
 			&lh.LossPct,
+			&samples,
+			&lastTs,
 			&exceedsCommit,
 			&hasPacketLoss,
 			&isDark,
 			&isDown,
 		); err != nil {
-			log.Printf("Link health scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Link health scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		lh.ExceedsCommit = exceedsCommit != 0
 		lh.HasPacketLoss = hasPacketLoss != 0
 		lh.IsDark = isDark != 0
 		lh.IsDown = isDown != 0
+		if samples > 0 {
+			lastTs = lastTs.UTC()
+			lh.LastMeasuredAt = &lastTs
+		}
 
 		// Sanitize NaN/Inf values from ClickHouse
 		if math.IsNaN(lh.AvgRttUs) || math.IsInf(lh.AvgRttUs, 0) {
@@ -323,6 +503,9 @@ func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 		if math.IsNaN(lh.P95RttUs) || math.IsInf(lh.P95RttUs, 0) {
 			lh.P95RttUs = 0
 		}
+		if math.IsNaN(lh.JitterUs) || math.IsInf(lh.JitterUs, 0) {
+			lh.JitterUs = 0
+		}
 		if math.IsNaN(lh.LossPct) || math.IsInf(lh.LossPct, 0) {
 			lh.LossPct = 0
 		}
@@ -363,8 +546,8 @@ func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Link health rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Link health rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -372,6 +555,15 @@ func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 		links = []TopologyLinkHealth{}
 	}
 
+	switch sortBy {
+	case "rtt":
+		sort.SliceStable(links, func(i, j int) bool { return links[i].AvgRttUs > links[j].AvgRttUs })
+	case "jitter":
+		sort.SliceStable(links, func(i, j int) bool { return links[i].JitterUs > links[j].JitterUs })
+	case "loss":
+		sort.SliceStable(links, func(i, j int) bool { return links[i].LossPct > links[j].LossPct })
+	}
+
 	response := TopologyLinkHealthResponse{
 		Links:         links,
 		TotalLinks:    len(links),
@@ -381,10 +573,7 @@ func GetLinkHealth(w http.ResponseWriter, r *http.Request) {
 		UnknownCount:  unknownCount,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
-	}
+	writeJSON(w, response)
 }
 
 func GetLink(w http.ResponseWriter, r *http.Request) {
@@ -393,7 +582,7 @@ func GetLink(w http.ResponseWriter, r *http.Request) {
 
 	pk := chi.URLParam(r, "pk")
 	if pk == "" {
-		http.Error(w, "missing link pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
 		return
 	}
 
@@ -449,7 +638,7 @@ func GetLink(w http.ResponseWriter, r *http.Request) {
 			} else {
 				fallbackSelects = linkDetailSelectsNoIP
 			}
-			log.Printf("Link query missing columns (pk=%s). Retrying with fallback.", pk)
+			LoggerFromContext(ctx).Warn("Link query missing columns, retrying with fallback", "pk", pk)
 			start = time.Now()
 			fallbackQuery := linkDetailQuery(fallbackSelects, !missingDirection)
 			err = envDB(ctx).QueryRow(ctx, fallbackQuery, pk).Scan(
@@ -493,18 +682,18 @@ func GetLink(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Printf("Link query error: %v", err)
-			http.Error(w, "link not found", http.StatusNotFound)
+			LoggerFromContext(ctx).Error("Link query error", "error", err)
+			writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "link not found")
 			return
 		}
-		log.Printf("Link query error: %v", err)
-		http.Error(w, "failed to fetch link", http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Link query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "failed to fetch link")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(link); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -707,3 +896,180 @@ func detectMissingLinkColumns(err error) (missingIP bool, missingIface bool, mis
 	missingLatency = strings.Contains(msg, "committed_rtt_ns") || strings.Contains(msg, "isis_delay_override_ns")
 	return missingIP, missingIface, missingDirection, missingLatency
 }
+
+// LinkLatencyTimeseriesPoint is one time bucket of latency percentiles for a link
+type LinkLatencyTimeseriesPoint struct {
+	Timestamp   string  `json:"timestamp"`
+	P50RttMs    float64 `json:"p50_rtt_ms"`
+	P95RttMs    float64 `json:"p95_rtt_ms"`
+	P99RttMs    float64 `json:"p99_rtt_ms"`
+	AvgJitterMs float64 `json:"avg_jitter_ms"`
+	LossPct     float64 `json:"loss_pct"`
+	Samples     uint64  `json:"samples"`
+}
+
+// LinkLatencyTimeseriesResponse is the response for the link latency timeseries endpoint
+type LinkLatencyTimeseriesResponse struct {
+	LinkPK         string                       `json:"link_pk"`
+	LinkCode       string                       `json:"link_code"`
+	Granularity    string                       `json:"granularity"`
+	Range          string                       `json:"range"`
+	CommittedRttMs float64                      `json:"committed_rtt_ms"`
+	Points         []LinkLatencyTimeseriesPoint `json:"points"`
+}
+
+// GetLinkLatencyTimeseries returns p50/p95/p99 RTT, jitter and loss for a
+// single link, bucketed by granularity (1m, 5m, 1h) over a range (1h, 6h,
+// 24h, 7d). The link's committed RTT is included as a reference line.
+func GetLinkLatencyTimeseries(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
+		return
+	}
+
+	granularity := r.URL.Query().Get("granularity")
+	var bucketSeconds int
+	switch granularity {
+	case "1m":
+		bucketSeconds = 60
+	case "", "5m":
+		granularity = "5m"
+		bucketSeconds = 300
+	case "1h":
+		bucketSeconds = 3600
+	default:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "granularity must be one of 1m, 5m, 1h")
+		return
+	}
+
+	rangeParam := r.URL.Query().Get("range")
+	var rangeMinutes int
+	switch rangeParam {
+	case "1h":
+		rangeMinutes = 60
+	case "6h":
+		rangeMinutes = 360
+	case "7d":
+		rangeMinutes = 10080
+	default:
+		rangeParam = "24h"
+		rangeMinutes = 1440
+	}
+
+	start := time.Now()
+
+	response := LinkLatencyTimeseriesResponse{
+		LinkPK:      pk,
+		Granularity: granularity,
+		Range:       rangeParam,
+		Points:      []LinkLatencyTimeseriesPoint{},
+	}
+
+	var committedRttNs int64
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT code, COALESCE(committed_rtt_ns, 0)
+		FROM dz_links_current
+		WHERE pk = $1
+	`, pk).Scan(&response.LinkCode, &committedRttNs)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "link not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Link latency timeseries link query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "failed to fetch link")
+		return
+	}
+	response.CommittedRttMs = float64(committedRttNs) / 1e6
+
+	// Lost samples carry no RTT, so they only count toward loss
+	bucketExpr := fmt.Sprintf("toStartOfInterval(event_ts, INTERVAL %d SECOND)", bucketSeconds)
+	query := fmt.Sprintf(`
+		SELECT
+			formatDateTime(%[1]s, '%%Y-%%m-%%dT%%H:%%i:%%sZ') AS ts,
+			quantileIf(0.5)(rtt_us, NOT loss) / 1000.0 AS p50_rtt_ms,
+			quantileIf(0.95)(rtt_us, NOT loss) / 1000.0 AS p95_rtt_ms,
+			quantileIf(0.99)(rtt_us, NOT loss) / 1000.0 AS p99_rtt_ms,
+			avgIf(abs(ipdv_us), NOT loss) / 1000.0 AS avg_jitter_ms,
+			countIf(loss) * 100.0 / count(*) AS loss_pct,
+			count(*) AS samples
+		FROM fact_dz_device_link_latency
+		WHERE link_pk = $1
+		  AND event_ts > now() - INTERVAL %[2]d MINUTE
+		GROUP BY %[1]s
+		ORDER BY %[1]s
+	`, bucketExpr, rangeMinutes)
+
+	rows, err := envDB(ctx).Query(ctx, query, pk)
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link latency timeseries query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "failed to fetch link latency")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p LinkLatencyTimeseriesPoint
+		var p50, p95, p99, jitter *float64
+		if err := rows.Scan(&p.Timestamp, &p50, &p95, &p99, &jitter, &p.LossPct, &p.Samples); err != nil {
+			LoggerFromContext(ctx).Error("Link latency timeseries scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "failed to fetch link latency")
+			return
+		}
+		p.P50RttMs = finiteOrZero(p50)
+		p.P95RttMs = finiteOrZero(p95)
+		p.P99RttMs = finiteOrZero(p99)
+		p.AvgJitterMs = finiteOrZero(jitter)
+		response.Points = append(response.Points, p)
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Link latency timeseries rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "failed to fetch link latency")
+		return
+	}
+
+	writeJSONWithETag(w, r, response)
+}
+
+// finiteOrZero returns the value, or 0 for NULL and NaN (e.g. an aggregate
+// over a bucket where every sample was lost).
+func finiteOrZero(v *float64) float64 {
+	if v == nil || math.IsNaN(*v) || math.IsInf(*v, 0) {
+		return 0
+	}
+	return *v
+}
+
+// writeJSONWithETag writes v as JSON with a strong ETag derived from the body,
+// and responds 304 Not Modified when the request's If-None-Match matches.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		LoggerFromContext(r.Context()).Error("JSON encoding error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "failed to encode response")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
@@ -474,3 +474,147 @@ func TestGetLinkHealth_IsDownForcesCritical(t *testing.T) {
 	assert.Equal(t, 1, response.HealthyCount)
 	assert.Equal(t, 1, response.CriticalCount)
 }
+
+func TestGetLinkHealth_PopulatesEveryField(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+	setupLinksTables(t)
+	insertLinksTestData(t)
+	setupLinkHealthData(t)
+
+	// link-1 jitters 20us on average, link-2 40us
+	require.NoError(t, config.DB.Exec(t.Context(), `
+		INSERT INTO fact_dz_device_link_latency (event_ts, link_pk, rtt_us, ipdv_us, loss) VALUES
+		(now() - INTERVAL 10 MINUTE, 'link-1', 1500, 10, 0),
+		(now() - INTERVAL 5 MINUTE, 'link-1', 1500, -30, 0),
+		(now() - INTERVAL 5 MINUTE, 'link-2', 500, 40, 0)
+	`))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links-health?sort=jitter", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetLinkHealth(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// Decode generically so missing or null fields are caught
+	var raw struct {
+		Links []map[string]any `json:"links"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &raw))
+	require.Len(t, raw.Links, 2)
+	for _, link := range raw.Links {
+		for _, field := range []string{"link_pk", "link_code", "status", "side_a_code", "side_z_code", "last_measured_at", "sla_status"} {
+			value, ok := link[field].(string)
+			assert.True(t, ok && value != "", "%s missing from %v", field, link["link_pk"])
+		}
+		for _, field := range []string{"avg_rtt_us", "p95_rtt_us", "jitter_us", "loss_pct", "committed_rtt_ns", "sla_ratio"} {
+			_, ok := link[field].(float64)
+			assert.True(t, ok, "%s missing from %v", field, link["link_pk"])
+		}
+	}
+
+	var response handlers.TopologyLinkHealthResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "link-2", response.Links[0].LinkPK)
+	assert.InDelta(t, 40.0, response.Links[0].JitterUs, 0.001)
+	assert.Equal(t, "NYC-LAX-001", response.Links[1].LinkCode)
+	assert.InDelta(t, 20.0, response.Links[1].JitterUs, 0.001)
+}
+
+func TestGetLinkHealth_InvalidSort(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links-health?sort=bandwidth", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetLinkHealth(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// seedLinkFilters inserts four links between AMS and NYC devices across
+// statuses, types, contributors, bandwidths and committed RTTs; only
+// AMS-NYC-01 has a latency sample from the past hour.
+func seedLinkFilters(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-ams', now(), now(), generateUUIDv4(), 0, 1, 'dev-ams', 'activated', 'hybrid', 'AMS-01', '', '', 'metro-ams', 0),
+		('dev-nyc', now(), now(), generateUUIDv4(), 0, 1, 'dev-nyc', 'activated', 'hybrid', 'NYC-01', '', '', 'metro-nyc', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns, committed_jitter_ns,
+		 bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-an1', now(), now(), generateUUIDv4(), 0, 1, 'link-an1', 'activated', 'AMS-NYC-01', '', 'contrib-a', 'dev-ams', 'dev-nyc', '', '', 'WAN', 40000000, 0, 10000000000, 0),
+		('link-an2', now(), now(), generateUUIDv4(), 0, 1, 'link-an2', 'soft-drained', 'AMS-NYC-02', '', 'contrib-b', 'dev-ams', 'dev-nyc', '', '', 'WAN', 80000000, 0, 1000000000, 0),
+		('link-na1', now(), now(), generateUUIDv4(), 0, 1, 'link-na1', 'activated', 'NYC-AMS-01', '', 'contrib-a', 'dev-nyc', 'dev-ams', '', '', 'DZX', 0, 0, 100000000000, 0),
+		('link-aa1', now(), now(), generateUUIDv4(), 0, 1, 'link-aa1', 'pending', 'AMS-AMS-01', '', 'contrib-b', 'dev-ams', 'dev-ams', '', '', 'DZX', 1000000, 0, 10000000000, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		VALUES
+		(now() - INTERVAL 10 MINUTE, now(), 1, 0, 'dev-ams', 'dev-nyc', 'link-an1', 40000, 0, 100),
+		(now() - INTERVAL 2 HOUR, now(), 1, 1, 'dev-ams', 'dev-nyc', 'link-an2', 80000, 0, 100)`))
+}
+
+func getLinkCodes(t *testing.T, query string) []string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetLinks(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var response handlers.PaginatedResponse[handlers.LinkListItem]
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	codes := make([]string, 0, len(response.Items))
+	for _, l := range response.Items {
+		codes = append(codes, l.Code)
+	}
+	assert.Equal(t, len(codes), response.Total, "total should reflect the filter")
+	return codes
+}
+
+func TestGetLinks_Filters(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkFilters(t)
+
+	for name, tc := range map[string]struct {
+		query string
+		want  []string
+	}{
+		"no filters":           {"", []string{"AMS-AMS-01", "AMS-NYC-01", "AMS-NYC-02", "NYC-AMS-01"}},
+		"status":               {"?status=activated", []string{"AMS-NYC-01", "NYC-AMS-01"}},
+		"status list":          {"?status=soft-drained,pending", []string{"AMS-AMS-01", "AMS-NYC-02"}},
+		"link_type":            {"?link_type=DZX", []string{"AMS-AMS-01", "NYC-AMS-01"}},
+		"side_a_metro_pk":      {"?side_a_metro_pk=metro-nyc", []string{"NYC-AMS-01"}},
+		"side_z_metro_pk":      {"?side_z_metro_pk=metro-nyc", []string{"AMS-NYC-01", "AMS-NYC-02"}},
+		"contributor_pk":       {"?contributor_pk=contrib-b", []string{"AMS-AMS-01", "AMS-NYC-02"}},
+		"min_bandwidth_bps":    {"?min_bandwidth_bps=10000000000", []string{"AMS-AMS-01", "AMS-NYC-01", "NYC-AMS-01"}},
+		"max_committed_rtt_ns": {"?max_committed_rtt_ns=40000000", []string{"AMS-AMS-01", "AMS-NYC-01"}},
+		"has_latency_data":     {"?has_latency_data=true", []string{"AMS-NYC-01"}},
+		"combined":             {"?side_a_metro_pk=metro-ams&side_z_metro_pk=metro-nyc&status=soft-drained", []string{"AMS-NYC-02"}},
+		"no match":             {"?link_type=WAN&side_a_metro_pk=metro-nyc", []string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, getLinkCodes(t, tc.query))
+		})
+	}
+}
+
+func TestGetLinks_InvalidFilters(t *testing.T) {
+	for name, query := range map[string]string{
+		"unknown status":      "?status=activated,up",
+		"unknown link_type":   "?link_type=wan",
+		"invalid bandwidth":   "?min_bandwidth_bps=10G",
+		"negative bandwidth":  "?min_bandwidth_bps=-1",
+		"zero committed rtt":  "?max_committed_rtt_ns=0",
+		"invalid has_latency": "?has_latency_data=yes",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/dz/links"+query, nil)
+			rr := httptest.NewRecorder()
+			handlers.GetLinks(rr, req)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// Imbalance ratios above which an ECMP group is flagged
+const (
+	loadImbalanceWarningRatio  = 0.3
+	loadImbalanceCriticalRatio = 0.6
+)
+
+// ECMPGroup identifies the equal-cost paths between two metros
+type ECMPGroup struct {
+	FromMetro string `json:"fromMetro"`
+	ToMetro   string `json:"toMetro"`
+}
+
+// LoadBalancingGroup is how evenly traffic is spread over an ECMP group
+type LoadBalancingGroup struct {
+	ECMPGroup ECMPGroup `json:"ecmpGroup"`
+	PathCount int       `json:"pathCount"`
+	// AdjacencyCount is the number of adjacencies compared: those on some but
+	// not all of the paths, with traffic data
+	AdjacencyCount int     `json:"adjacencyCount"`
+	MinTrafficBps  float64 `json:"minTrafficBps"`
+	MaxTrafficBps  float64 `json:"maxTrafficBps"`
+	AvgTrafficBps  float64 `json:"avgTrafficBps"`
+	ImbalanceRatio float64 `json:"imbalanceRatio"` // (max - min) / avg
+	Severity       string  `json:"severity"`       // "ok", "warning" or "critical"
+}
+
+// LoadBalancingQualityResponse is the response for the load balancing quality endpoint
+type LoadBalancingQualityResponse struct {
+	Groups        []LoadBalancingGroup `json:"groups"`
+	WarningCount  int                  `json:"warningCount"`
+	CriticalCount int                  `json:"criticalCount"`
+}
+
+// adjacencyKey identifies an undirected adjacency by its device PKs in order
+type adjacencyKey [2]string
+
+func newAdjacencyKey(a, b string) adjacencyKey {
+	if a > b {
+		a, b = b, a
+	}
+	return adjacencyKey{a, b}
+}
+
+// loadBalancingSeverity classifies an imbalance ratio
+func loadBalancingSeverity(ratio float64) string {
+	switch {
+	case ratio > loadImbalanceCriticalRatio:
+		return "critical"
+	case ratio > loadImbalanceWarningRatio:
+		return "warning"
+	default:
+		return "ok"
+	}
+}
+
+// GetLoadBalancingQuality checks whether traffic is spread evenly across the
+// equal-cost ISIS paths between each pair of metros. Traffic over the past
+// hour is compared on the adjacencies where the paths diverge; adjacencies
+// every path shares carry the whole group's traffic and are left out. Groups
+// are ordered by imbalance, worst first.
+func GetLoadBalancingQuality(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	metroCodes, err := loadMetroCodes(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Load balancing quality metro query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	traffic, err := loadAdjacencyTraffic(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Load balancing quality traffic query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	start := time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("load_balancing_quality", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Load balancing quality graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	writeJSON(w, loadBalancingQuality(g, metroCodes, traffic))
+}
+
+// loadAdjacencyTraffic returns each device pair's average traffic over the
+// past hour, in and out, summed over the links between them
+func loadAdjacencyTraffic(ctx context.Context) (map[adjacencyKey]float64, error) {
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH traffic_rates AS (
+			SELECT
+				link_pk,
+				SUM(in_octets_delta + out_octets_delta) * 8 / SUM(delta_duration) AS bps
+			FROM fact_dz_device_interface_counters
+			WHERE event_ts > now() - INTERVAL 1 HOUR
+				AND link_pk != ''
+				AND delta_duration > 0
+				AND in_octets_delta >= 0
+				AND out_octets_delta >= 0
+			GROUP BY link_pk
+		)
+		SELECT l.side_a_pk, l.side_z_pk, tr.bps
+		FROM dz_links_current l
+		JOIN traffic_rates tr ON l.pk = tr.link_pk
+	`)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	traffic := make(map[adjacencyKey]float64)
+	for rows.Next() {
+		var sideA, sideZ string
+		var bps float64
+		if err := rows.Scan(&sideA, &sideZ, &bps); err != nil {
+			return nil, err
+		}
+		traffic[newAdjacencyKey(sideA, sideZ)] += bps
+	}
+	return traffic, rows.Err()
+}
+
+// loadBalancingQuality finds the ECMP group for every metro pair with more
+// than one equal-cost path and measures its imbalance. Groups with fewer than
+// two diverging adjacencies with traffic, or no traffic at all, can't be
+// assessed and are skipped.
+func loadBalancingQuality(g *isisGraph, metroCodes map[string]string, traffic map[adjacencyKey]float64) LoadBalancingQualityResponse {
+	response := LoadBalancingQualityResponse{Groups: []LoadBalancingGroup{}}
+
+	devicesByMetro := make(map[string][]int)
+	for i, node := range g.nodes {
+		if node.MetroPK != "" {
+			devicesByMetro[node.MetroPK] = append(devicesByMetro[node.MetroPK], i)
+		}
+	}
+	metros := make([]string, 0, len(devicesByMetro))
+	for pk := range devicesByMetro {
+		metros = append(metros, pk)
+	}
+	sort.Slice(metros, func(i, j int) bool { return metroCodes[metros[i]] < metroCodes[metros[j]] })
+
+	for i, from := range metros {
+		for _, to := range metros[i+1:] {
+			paths := metroECMPPaths(g.adj, devicesByMetro[from], devicesByMetro[to])
+			if len(paths) < 2 {
+				continue
+			}
+
+			// Adjacencies on every path carry all of the group's traffic
+			onPaths := make(map[adjacencyKey]int)
+			for _, path := range paths {
+				seen := make(map[adjacencyKey]bool)
+				for j := 1; j < len(path); j++ {
+					key := newAdjacencyKey(g.nodes[path[j-1]].PK, g.nodes[path[j]].PK)
+					if !seen[key] {
+						seen[key] = true
+						onPaths[key]++
+					}
+				}
+			}
+			var values []float64
+			for key, count := range onPaths {
+				if bps, ok := traffic[key]; ok && count < len(paths) {
+					values = append(values, bps)
+				}
+			}
+			if len(values) < 2 {
+				continue
+			}
+
+			group := LoadBalancingGroup{
+				ECMPGroup:      ECMPGroup{FromMetro: metroCodes[from], ToMetro: metroCodes[to]},
+				PathCount:      len(paths),
+				AdjacencyCount: len(values),
+				MinTrafficBps:  slices.Min(values),
+				MaxTrafficBps:  slices.Max(values),
+			}
+			var sum float64
+			for _, v := range values {
+				sum += v
+			}
+			group.AvgTrafficBps = sum / float64(len(values))
+			if group.AvgTrafficBps <= 0 {
+				continue
+			}
+			group.ImbalanceRatio = (group.MaxTrafficBps - group.MinTrafficBps) / group.AvgTrafficBps
+			group.Severity = loadBalancingSeverity(group.ImbalanceRatio)
+			switch group.Severity {
+			case "critical":
+				response.CriticalCount++
+			case "warning":
+				response.WarningCount++
+			}
+			response.Groups = append(response.Groups, group)
+		}
+	}
+
+	sort.SliceStable(response.Groups, func(i, j int) bool {
+		return response.Groups[i].ImbalanceRatio > response.Groups[j].ImbalanceRatio
+	})
+	return response
+}
+
+// metroECMPPaths returns the equal-cost paths from any of the source devices
+// to any of the target devices. A virtual node is linked to every source and
+// from every target so equalCostPaths can search between the two sets.
+func metroECMPPaths(adj [][]isisGraphEdge, sources, targets []int) [][]int {
+	n := len(adj)
+	s, t := n, n+1
+	ext := make([][]isisGraphEdge, n+2)
+	copy(ext, adj)
+	for _, v := range sources {
+		ext[s] = append(ext[s], isisGraphEdge{to: v, weight: 1})
+	}
+	for _, v := range targets {
+		ext[v] = append(slices.Clone(adj[v]), isisGraphEdge{to: t, weight: 1})
+	}
+
+	paths, _ := equalCostPaths(ext, s, t, maxECMPPaths)
+	for i, path := range paths {
+		paths[i] = path[1 : len(path)-1]
+	}
+	return paths
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLoadBalancingQuality(t *testing.T) {
+	// ams1 reaches lon1 through fra1 or par1 at equal cost; nyc1 hangs off
+	// lon1 with a single path everywhere
+	g := &isisGraph{
+		nodes: []isisGraphNode{
+			{PK: "ams1", MetroPK: "ams"},
+			{PK: "fra1", MetroPK: "fra"},
+			{PK: "par1", MetroPK: "par"},
+			{PK: "lon1", MetroPK: "lon"},
+			{PK: "nyc1", MetroPK: "nyc"},
+		},
+		adj: undirectedGraph(5, [][2]int{{0, 1}, {0, 2}, {1, 3}, {2, 3}, {3, 4}}),
+	}
+	codes := map[string]string{"ams": "AMS", "fra": "FRA", "par": "PAR", "lon": "LON", "nyc": "NYC"}
+	traffic := map[adjacencyKey]float64{
+		newAdjacencyKey("ams1", "fra1"): 100,
+		newAdjacencyKey("fra1", "lon1"): 100,
+		newAdjacencyKey("ams1", "par1"): 50,
+		newAdjacencyKey("lon1", "par1"): 50,
+		newAdjacencyKey("lon1", "nyc1"): 150,
+	}
+
+	resp := loadBalancingQuality(g, codes, traffic)
+
+	// AMS-LON and AMS-NYC have two paths; FRA-PAR also does, via ams1 or lon1
+	if len(resp.Groups) != 3 {
+		t.Fatalf("expected 3 ECMP groups, got %+v", resp.Groups)
+	}
+	for _, group := range resp.Groups {
+		if group.PathCount != 2 || group.AdjacencyCount != 4 {
+			t.Errorf("%+v: expected 2 paths over 4 diverging adjacencies", group)
+		}
+		// (100 - 50) / 75, with lon1-nyc1 shared by both AMS-NYC paths
+		if math.Abs(group.ImbalanceRatio-2.0/3) > 1e-9 || group.Severity != "critical" {
+			t.Errorf("%+v: expected a critical 0.67 imbalance", group)
+		}
+	}
+	if resp.CriticalCount != 3 || resp.WarningCount != 0 {
+		t.Errorf("expected 3 critical groups, got %d critical and %d warning", resp.CriticalCount, resp.WarningCount)
+	}
+}
+
+func TestLoadBalancingQuality_SkipsUnmeasuredGroups(t *testing.T) {
+	g := &isisGraph{
+		nodes: []isisGraphNode{
+			{PK: "ams1", MetroPK: "ams"},
+			{PK: "fra1"},
+			{PK: "par1"},
+			{PK: "lon1", MetroPK: "lon"},
+		},
+		adj: undirectedGraph(4, [][2]int{{0, 1}, {0, 2}, {1, 3}, {2, 3}}),
+	}
+
+	resp := loadBalancingQuality(g, map[string]string{"ams": "AMS", "lon": "LON"}, map[adjacencyKey]float64{
+		newAdjacencyKey("ams1", "fra1"): 100,
+	})
+	if len(resp.Groups) != 0 {
+		t.Errorf("expected no groups with traffic on one adjacency, got %+v", resp.Groups)
+	}
+}
+
+func TestLoadBalancingSeverity(t *testing.T) {
+	for ratio, want := range map[float64]string{0: "ok", 0.3: "ok", 0.31: "warning", 0.6: "warning", 0.61: "critical"} {
+		if got := loadBalancingSeverity(ratio); got != want {
+			t.Errorf("ratio %v: expected %s, got %s", ratio, want, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const loggerContextKey contextKey = "logger"
+
+// ContextWithLogger returns a new context carrying logger.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// LoggerFromContext returns the request-scoped logger set by LoggerMiddleware,
+// or slog.Default() outside a request (e.g. in background cache refreshes).
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// LoggerMiddleware attaches a logger with request_id, user_id, method, path and
+// env fields to the request context, and logs each request's outcome. It must
+// run after RequestIDMiddleware, OptionalAuth and EnvMiddleware.
+func LoggerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID := ""
+		if account := GetAccountFromContext(ctx); account != nil {
+			userID = account.ID.String()
+		}
+		logger := slog.Default().With(
+			"request_id", GetRequestIDFromContext(ctx),
+			"user_id", userID,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"env", string(EnvFromContext(ctx)),
+		)
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ContextWithLogger(ctx, logger)))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		durationMs := time.Since(start).Milliseconds()
+		if status >= http.StatusInternalServerError {
+			logger.Error("handler failed", "status", status, "duration_ms", durationMs)
+			return
+		}
+		logger.Info("handler completed", "status", status, "duration_ms", durationMs)
+	})
+}
@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs routes slog.Default() to a JSON buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+// decodeLogLines returns the captured lines logged with request fields, ignoring
+// any background logging
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		if _, ok := line["request_id"]; ok {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	buf := captureLogs(t)
+
+	handler := handlers.RequestIDMiddleware(handlers.EnvMiddleware(handlers.LoggerMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlers.LoggerFromContext(r.Context()).Warn("inside handler")
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+	req.Header.Set("X-DZ-Env", "devnet")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	lines := decodeLogLines(t, buf)
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Equal(t, rr.Header().Get("X-Request-ID"), line["request_id"])
+		assert.Equal(t, "", line["user_id"])
+		assert.Equal(t, "GET", line["method"])
+		assert.Equal(t, "/api/stats", line["path"])
+		assert.Equal(t, "devnet", line["env"])
+	}
+	assert.Equal(t, "inside handler", lines[0]["msg"])
+	assert.Equal(t, "handler completed", lines[1]["msg"])
+	assert.Equal(t, "INFO", lines[1]["level"])
+	assert.Equal(t, float64(http.StatusTeapot), lines[1]["status"])
+	assert.Contains(t, lines[1], "duration_ms")
+}
+
+func TestLoggerMiddleware_ServerError(t *testing.T) {
+	buf := captureLogs(t)
+
+	handler := handlers.LoggerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+
+	lines := decodeLogLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), lines[0]["status"])
+}
+
+func TestLoggerFromContext_Default(t *testing.T) {
+	assert.Same(t, slog.Default(), handlers.LoggerFromContext(t.Context()))
+}
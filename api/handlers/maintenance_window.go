@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/maintenance"
+	"github.com/slack-go/slack"
+)
+
+// maintenanceNotifyLeadTime is how long before a window starts the Slack notification is sent.
+const maintenanceNotifyLeadTime = time.Hour
+
+// maintenanceWindowColumns are the columns read by scanMaintenanceWindow
+const maintenanceWindowColumns = `id, device_pks, link_pks, start_at, end_at, description, impact,
+	created_at, notified_at, status, approved_by, approved_at`
+
+// MaintenanceWindowRequest is the request body for scheduling a maintenance window
+type MaintenanceWindowRequest struct {
+	Devices     []string `json:"devices"` // Device PKs to take offline
+	Links       []string `json:"links"`   // Link PKs to take offline
+	StartAt     string   `json:"startAt"` // RFC3339
+	EndAt       string   `json:"endAt"`   // RFC3339
+	Description string   `json:"description"`
+}
+
+// MaintenanceWindow is a scheduled maintenance window with its pre-computed impact
+type MaintenanceWindow struct {
+	WindowID    uuid.UUID                 `json:"windowId"`
+	Devices     []string                  `json:"devices"`
+	Links       []string                  `json:"links"`
+	StartAt     time.Time                 `json:"startAt"`
+	EndAt       time.Time                 `json:"endAt"`
+	Description string                    `json:"description"`
+	Impact      MaintenanceImpactResponse `json:"impact"`
+	CreatedAt   time.Time                 `json:"createdAt"`
+	NotifiedAt  *time.Time                `json:"notifiedAt,omitempty"`
+	Status      string                    `json:"status"`               // pending, approved or cancelled
+	ApprovedBy  *string                   `json:"approvedBy,omitempty"` // Slack user ID
+	ApprovedAt  *time.Time                `json:"approvedAt,omitempty"`
+}
+
+// MaintenanceWindowsResponse is the response for listing maintenance windows
+type MaintenanceWindowsResponse struct {
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// PostMaintenanceWindow schedules a maintenance window and returns it with the
+// impact analysis computed at scheduling time.
+func PostMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var req MaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.Devices) == 0 && len(req.Links) == 0 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "No devices or links specified")
+		return
+	}
+	startAt, err := time.Parse(time.RFC3339, req.StartAt)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "startAt must be an RFC3339 timestamp")
+		return
+	}
+	endAt, err := time.Parse(time.RFC3339, req.EndAt)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "endAt must be an RFC3339 timestamp")
+		return
+	}
+	if !endAt.After(startAt) {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "endAt must be after startAt")
+		return
+	}
+	if !startAt.After(time.Now()) {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "startAt must be in the future")
+		return
+	}
+	if req.Devices == nil {
+		req.Devices = []string{}
+	}
+	if req.Links == nil {
+		req.Links = []string{}
+	}
+
+	impact := analyzeMaintenanceImpact(ctx, MaintenanceImpactRequest{Devices: req.Devices, Links: req.Links})
+	if impact.Error != "" {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, impact.Error)
+		return
+	}
+	impactJSON, err := json.Marshal(impact)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to encode maintenance impact", err))
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if account := GetAccountFromContext(ctx); account != nil {
+		createdBy = &account.ID
+	}
+
+	window := MaintenanceWindow{
+		Devices:     req.Devices,
+		Links:       req.Links,
+		StartAt:     startAt,
+		EndAt:       endAt,
+		Description: req.Description,
+		Impact:      impact,
+	}
+	err = config.PgPool.QueryRow(ctx, `
+		INSERT INTO maintenance_windows (device_pks, link_pks, start_at, end_at, description, impact, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, status
+	`, req.Devices, req.Links, startAt, endAt, req.Description, impactJSON, createdBy).Scan(&window.WindowID, &window.CreatedAt, &window.Status)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to create maintenance window", err))
+		return
+	}
+
+	// Ask on-call to approve, defer or cancel the window. A failure here doesn't
+	// undo the scheduling; the window still gets its pre-start notification.
+	if botToken, channel := os.Getenv("SLACK_BOT_TOKEN"), os.Getenv("SLACK_MAINTENANCE_CHANNEL"); botToken != "" && channel != "" {
+		if err := postSlackBlocks(ctx, botToken, channel, maintenanceNotificationText(window), maintenanceApprovalBlocks(window)); err != nil {
+			LoggerFromContext(ctx).Error("failed to post maintenance approval request", "id", window.WindowID, "error", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(window)
+}
+
+// GetMaintenanceWindows lists maintenance windows that haven't ended or been
+// cancelled, soonest first
+func GetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	rows, err := config.PgPool.Query(ctx, `
+		SELECT `+maintenanceWindowColumns+`
+		FROM maintenance_windows
+		WHERE end_at > NOW() AND status <> $1
+		ORDER BY start_at, id
+	`, maintenance.StatusCancelled)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to query maintenance windows", err))
+		return
+	}
+	defer rows.Close()
+
+	response := MaintenanceWindowsResponse{Windows: []MaintenanceWindow{}}
+	for rows.Next() {
+		mw, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to scan maintenance window", err))
+			return
+		}
+		response.Windows = append(response.Windows, *mw)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to iterate maintenance windows", err))
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// scanMaintenanceWindow scans a row of maintenanceWindowColumns
+func scanMaintenanceWindow(row pgx.Row) (*MaintenanceWindow, error) {
+	var mw MaintenanceWindow
+	var impactJSON []byte
+	if err := row.Scan(&mw.WindowID, &mw.Devices, &mw.Links, &mw.StartAt, &mw.EndAt, &mw.Description,
+		&impactJSON, &mw.CreatedAt, &mw.NotifiedAt, &mw.Status, &mw.ApprovedBy, &mw.ApprovedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(impactJSON, &mw.Impact); err != nil {
+		slog.Warn("failed to decode maintenance window impact", "id", mw.WindowID, "error", err)
+	}
+	return &mw, nil
+}
+
+// StartMaintenanceNotifier starts a background worker that posts to Slack
+// maintenanceNotifyLeadTime before each maintenance window starts. It only
+// runs when SLACK_BOT_TOKEN and SLACK_MAINTENANCE_CHANNEL are set.
+func StartMaintenanceNotifier(ctx context.Context) {
+	botToken := os.Getenv("SLACK_BOT_TOKEN")
+	channel := os.Getenv("SLACK_MAINTENANCE_CHANNEL")
+	if botToken == "" || channel == "" || config.PgPool == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if err := notifyUpcomingMaintenance(ctx, botToken, channel); err != nil {
+					slog.Error("Failed to send maintenance notifications", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// notifyUpcomingMaintenance claims windows starting within the lead time that
+// haven't been notified yet and posts a Slack message for each. Claiming with
+// SKIP LOCKED keeps multiple API replicas from notifying the same window twice.
+// A window whose post fails is released so the next tick retries it.
+func notifyUpcomingMaintenance(ctx context.Context, botToken, channel string) error {
+	rows, err := config.PgPool.Query(ctx, `
+		UPDATE maintenance_windows SET notified_at = NOW()
+		WHERE id IN (
+			SELECT id FROM maintenance_windows
+			WHERE notified_at IS NULL
+			  AND status <> $2
+			  AND start_at > NOW()
+			  AND start_at <= NOW() + make_interval(secs => $1)
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+maintenanceWindowColumns,
+		maintenanceNotifyLeadTime.Seconds(), maintenance.StatusCancelled)
+	if err != nil {
+		return err
+	}
+
+	var windows []MaintenanceWindow
+	for rows.Next() {
+		mw, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		windows = append(windows, *mw)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, mw := range windows {
+		if err := postSlackMessage(ctx, botToken, channel, maintenanceNotificationText(mw)); err != nil {
+			slog.Error("Failed to post maintenance notification", "id", mw.WindowID, "error", err)
+			if _, err := config.PgPool.Exec(ctx, `UPDATE maintenance_windows SET notified_at = NULL WHERE id = $1 AND notified_at = $2`,
+				mw.WindowID, mw.NotifiedAt); err != nil {
+				slog.Error("Failed to release maintenance notification", "id", mw.WindowID, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// maintenanceNotificationText formats the Slack message for an upcoming window
+func maintenanceNotificationText(mw MaintenanceWindow) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":construction: Maintenance starts at %s (until %s)\n",
+		mw.StartAt.UTC().Format(time.RFC3339), mw.EndAt.UTC().Format(time.RFC3339))
+	if mw.Description != "" {
+		fmt.Fprintf(&b, "%s\n", mw.Description)
+	}
+	if len(mw.Devices) > 0 {
+		fmt.Fprintf(&b, "Devices: %s\n", strings.Join(mw.Devices, ", "))
+	}
+	if len(mw.Links) > 0 {
+		fmt.Fprintf(&b, "Links: %s\n", strings.Join(mw.Links, ", "))
+	}
+	fmt.Fprintf(&b, "Expected impact: %d affected paths, %d disconnected devices\n",
+		mw.Impact.TotalImpact, mw.Impact.TotalDisconnected)
+	fmt.Fprintf(&b, "Window ID: %s", mw.WindowID)
+	return b.String()
+}
+
+// maintenanceApprovalBlocks renders a newly scheduled window as a Block Kit
+// message with Approve, Defer and Cancel buttons for on-call.
+func maintenanceApprovalBlocks(mw MaintenanceWindow) []slack.Block {
+	text := strings.Replace(maintenanceNotificationText(mw), ":construction: Maintenance starts",
+		":construction: Maintenance scheduled to start", 1)
+	id := mw.WindowID.String()
+	approve := slack.NewButtonBlockElement(maintenance.ActionApprove, id,
+		slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false))
+	approve.Style = slack.StylePrimary
+	deferButton := slack.NewButtonBlockElement(maintenance.ActionDefer, id,
+		slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("Defer %d minutes", int(maintenance.DeferInterval.Minutes())), false, false))
+	cancel := slack.NewButtonBlockElement(maintenance.ActionCancel, id,
+		slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))
+	cancel.Style = slack.StyleDanger
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+		slack.NewActionBlock("maintenance_actions", approve, deferButton, cancel),
+	}
+}
+
+// postSlackMessage posts a plain text message with chat.postMessage
+func postSlackMessage(ctx context.Context, botToken, channel, text string) error {
+	return postSlackPayload(ctx, botToken, map[string]any{"channel": channel, "text": text})
+}
+
+// postSlackBlocks posts a Block Kit message with chat.postMessage; text is the notification fallback
+func postSlackBlocks(ctx context.Context, botToken, channel, text string, blocks []slack.Block) error {
+	return postSlackPayload(ctx, botToken, map[string]any{"channel": channel, "text": text, "blocks": blocks})
+}
+
+func postSlackPayload(ctx context.Context, botToken string, payload map[string]any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://slack.com/api/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+botToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error,omitempty"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.OK {
+		return fmt.Errorf("slack chat.postMessage failed: %s", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	"github.com/malbeclabs/lake/api/maintenance"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postMaintenanceWindow(t *testing.T, account *handlers.Account, body handlers.MaintenanceWindowRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/topology/maintenance-window", bytes.NewReader(b))
+	req = withAccount(req, account)
+	rr := httptest.NewRecorder()
+	handlers.PostMaintenanceWindow(rr, req)
+	return rr
+}
+
+func TestPostMaintenanceWindow(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISLine(t)
+	account := createTestAccount(t, t.Context())
+
+	startAt := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	rr := postMaintenanceWindow(t, account, handlers.MaintenanceWindowRequest{
+		Devices:     []string{"chi1"},
+		StartAt:     startAt.Format(time.RFC3339),
+		EndAt:       startAt.Add(time.Hour).Format(time.RFC3339),
+		Description: "CHI1 line card swap",
+	})
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var window handlers.MaintenanceWindow
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&window))
+	assert.NotEmpty(t, window.WindowID)
+	assert.True(t, startAt.Equal(window.StartAt))
+	assert.Equal(t, []string{"chi1"}, window.Devices)
+	assert.Empty(t, window.Impact.Error)
+	require.Len(t, window.Impact.Items, 1)
+	assert.Equal(t, "CHI1", window.Impact.Items[0].Code)
+
+	// The window shows up in the upcoming list with its stored impact
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/maintenance-windows", nil)
+	rr = httptest.NewRecorder()
+	handlers.GetMaintenanceWindows(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var list handlers.MaintenanceWindowsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	require.Len(t, list.Windows, 1)
+	assert.Equal(t, window.WindowID, list.Windows[0].WindowID)
+	assert.Equal(t, "CHI1 line card swap", list.Windows[0].Description)
+	require.Len(t, list.Windows[0].Impact.Items, 1)
+	assert.Nil(t, list.Windows[0].NotifiedAt)
+	assert.Equal(t, maintenance.StatusPending, list.Windows[0].Status)
+}
+
+func TestMaintenanceWindowActions(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISLine(t)
+	ctx := t.Context()
+	account := createTestAccount(t, ctx)
+
+	startAt := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	rr := postMaintenanceWindow(t, account, handlers.MaintenanceWindowRequest{
+		Devices: []string{"chi1"},
+		StartAt: startAt.Format(time.RFC3339),
+		EndAt:   startAt.Add(time.Hour).Format(time.RFC3339),
+	})
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var window handlers.MaintenanceWindow
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&window))
+	assert.Equal(t, maintenance.StatusPending, window.Status)
+
+	approved, err := maintenance.Approve(ctx, window.WindowID, "U0ONCALL")
+	require.NoError(t, err)
+	assert.Equal(t, maintenance.StatusApproved, approved.Status)
+	require.NotNil(t, approved.ApprovedBy)
+	assert.Equal(t, "U0ONCALL", *approved.ApprovedBy)
+	assert.NotNil(t, approved.ApprovedAt)
+
+	// Deferring shifts the whole window and re-arms the pre-start notification
+	_, err = config.PgPool.Exec(ctx, `UPDATE maintenance_windows SET notified_at = NOW() WHERE id = $1`, window.WindowID)
+	require.NoError(t, err)
+	deferred, err := maintenance.Defer(ctx, window.WindowID, maintenance.DeferInterval)
+	require.NoError(t, err)
+	assert.True(t, startAt.Add(30*time.Minute).Equal(deferred.StartAt), deferred.StartAt)
+	assert.True(t, startAt.Add(90*time.Minute).Equal(deferred.EndAt), deferred.EndAt)
+	var notifiedAt *time.Time
+	require.NoError(t, config.PgPool.QueryRow(ctx, `SELECT notified_at FROM maintenance_windows WHERE id = $1`, window.WindowID).Scan(&notifiedAt))
+	assert.Nil(t, notifiedAt)
+
+	// Wallet accounts have no email to match a Slack user against
+	email, err := maintenance.RequesterEmail(ctx, window.WindowID)
+	require.NoError(t, err)
+	assert.Empty(t, email)
+
+	cancelled, err := maintenance.Cancel(ctx, window.WindowID)
+	require.NoError(t, err)
+	assert.Equal(t, maintenance.StatusCancelled, cancelled.Status)
+
+	// Cancelled windows can't be acted on and drop out of the upcoming list
+	_, err = maintenance.Approve(ctx, window.WindowID, "U0ONCALL")
+	assert.ErrorIs(t, err, maintenance.ErrWindowNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/maintenance-windows", nil)
+	rr = httptest.NewRecorder()
+	handlers.GetMaintenanceWindows(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var list handlers.MaintenanceWindowsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	assert.Empty(t, list.Windows)
+
+	_, err = maintenance.Defer(ctx, uuid.New(), maintenance.DeferInterval)
+	assert.ErrorIs(t, err, maintenance.ErrWindowNotFound)
+}
+
+func TestPostMaintenanceWindow_Validation(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	account := createTestAccount(t, t.Context())
+
+	future := time.Now().Add(2 * time.Hour).UTC()
+	tests := []struct {
+		name string
+		body handlers.MaintenanceWindowRequest
+	}{
+		{"no devices or links", handlers.MaintenanceWindowRequest{
+			StartAt: future.Format(time.RFC3339), EndAt: future.Add(time.Hour).Format(time.RFC3339),
+		}},
+		{"invalid startAt", handlers.MaintenanceWindowRequest{
+			Devices: []string{"chi1"}, StartAt: "tomorrow", EndAt: future.Format(time.RFC3339),
+		}},
+		{"end before start", handlers.MaintenanceWindowRequest{
+			Devices: []string{"chi1"}, StartAt: future.Format(time.RFC3339), EndAt: future.Add(-time.Hour).Format(time.RFC3339),
+		}},
+		{"start in the past", handlers.MaintenanceWindowRequest{
+			Devices: []string{"chi1"}, StartAt: time.Now().Add(-time.Hour).Format(time.RFC3339), EndAt: future.Format(time.RFC3339),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postMaintenanceWindow(t, account, tt.body)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			var resp handlers.ErrorResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+			assert.Equal(t, handlers.ErrCodeBadRequest, resp.Code)
+		})
+	}
+}
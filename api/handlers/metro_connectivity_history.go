@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// MetroConnectivityEvent is a metro gaining its first or losing its last
+// IS-IS adjacency to another metro
+type MetroConnectivityEvent struct {
+	Timestamp       time.Time `json:"timestamp"`
+	EventType       string    `json:"eventType"` // "gained" or "lost"
+	TargetMetroPK   string    `json:"targetMetroPK"`
+	TargetMetroCode string    `json:"targetMetroCode"`
+	// AffectedDevicePK is the device in the target metro whose adjacency
+	// came up or went down
+	AffectedDevicePK   string `json:"affectedDevicePK"`
+	AffectedDeviceCode string `json:"affectedDeviceCode"`
+}
+
+// metroAdjacencyEvent is an adjacency event between a device in the metro and
+// a device in another metro
+type metroAdjacencyEvent struct {
+	ts              time.Time
+	eventType       string // "up" or "down"
+	localPK         string
+	remotePK        string
+	remoteCode      string
+	remoteMetroPK   string
+	remoteMetroCode string
+}
+
+// GetMetroConnectivityHistory returns when a metro gained or lost connectivity
+// to each other metro, oldest first, over all recorded IS-IS adjacency events.
+// A metro is connected to another while any adjacency between their devices
+// is up. Devices are placed in metros by where they are now.
+func GetMetroConnectivityHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing metro pk")
+		return
+	}
+
+	var code string
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `SELECT code FROM dz_metros_current WHERE pk = ?`, pk).Scan(&code)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeMetroNotFound, "metro not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Metro connectivity history metro query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	events, err := loadMetroAdjacencyEvents(ctx, pk)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Metro connectivity history query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, metroConnectivityEvents(events))
+}
+
+// loadMetroAdjacencyEvents returns adjacency events between the metro's
+// devices and devices in other metros, oldest first, oriented so the local
+// device is the one in the metro
+func loadMetroAdjacencyEvents(ctx context.Context, metroPK string) ([]metroAdjacencyEvent, error) {
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT
+			e.event_ts,
+			e.event_type,
+			e.from_pk,
+			fd.code,
+			fd.metro_pk,
+			COALESCE(fm.code, '') AS from_metro_code,
+			e.to_pk,
+			td.code,
+			td.metro_pk,
+			COALESCE(tm.code, '') AS to_metro_code
+		FROM fact_isis_adjacency_events e FINAL
+		JOIN dz_devices_current fd ON e.from_pk = fd.pk
+		JOIN dz_devices_current td ON e.to_pk = td.pk
+		LEFT JOIN dz_metros_current fm ON fd.metro_pk = fm.pk
+		LEFT JOIN dz_metros_current tm ON td.metro_pk = tm.pk
+		WHERE (fd.metro_pk = ? OR td.metro_pk = ?)
+		  AND fd.metro_pk != td.metro_pk
+		  AND fd.metro_pk != '' AND td.metro_pk != ''
+		ORDER BY e.event_ts, e.from_pk, e.to_pk
+	`, metroPK, metroPK)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []metroAdjacencyEvent
+	for rows.Next() {
+		var e metroAdjacencyEvent
+		var fromPK, fromCode, fromMetroPK, fromMetroCode string
+		var toPK, toCode, toMetroPK, toMetroCode string
+		if err := rows.Scan(&e.ts, &e.eventType,
+			&fromPK, &fromCode, &fromMetroPK, &fromMetroCode,
+			&toPK, &toCode, &toMetroPK, &toMetroCode); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(start), err)
+			return nil, err
+		}
+		e.ts = e.ts.UTC()
+		if fromMetroPK == metroPK {
+			e.localPK, e.remotePK, e.remoteCode = fromPK, toPK, toCode
+			e.remoteMetroPK, e.remoteMetroCode = toMetroPK, toMetroCode
+		} else {
+			e.localPK, e.remotePK, e.remoteCode = toPK, fromPK, fromCode
+			e.remoteMetroPK, e.remoteMetroCode = fromMetroPK, fromMetroCode
+		}
+		events = append(events, e)
+	}
+	err = rows.Err()
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	return events, err
+}
+
+// metroConnectivityEvents replays adjacency events in order and reports each
+// time the set of up adjacencies to a metro becomes non-empty or empty.
+// Adjacencies whose first event is "down" were up before the history starts,
+// so they count as up from the beginning. Events reported from both sides of
+// an adjacency only change its state once.
+func metroConnectivityEvents(events []metroAdjacencyEvent) []MetroConnectivityEvent {
+	type adjacency struct{ local, remote string }
+
+	up := make(map[string]map[adjacency]bool) // by remote metro
+	upTo := func(metroPK string) map[adjacency]bool {
+		if up[metroPK] == nil {
+			up[metroPK] = make(map[adjacency]bool)
+		}
+		return up[metroPK]
+	}
+
+	seen := make(map[adjacency]bool)
+	for _, e := range events {
+		adj := adjacency{e.localPK, e.remotePK}
+		if seen[adj] {
+			continue
+		}
+		seen[adj] = true
+		if e.eventType == "down" {
+			upTo(e.remoteMetroPK)[adj] = true
+		}
+	}
+
+	changes := []MetroConnectivityEvent{}
+	for _, e := range events {
+		adj := adjacency{e.localPK, e.remotePK}
+		set := upTo(e.remoteMetroPK)
+		var eventType string
+		switch e.eventType {
+		case "up":
+			if set[adj] {
+				continue
+			}
+			set[adj] = true
+			if len(set) == 1 {
+				eventType = "gained"
+			}
+		case "down":
+			if !set[adj] {
+				continue
+			}
+			delete(set, adj)
+			if len(set) == 0 {
+				eventType = "lost"
+			}
+		}
+		if eventType == "" {
+			continue
+		}
+		changes = append(changes, MetroConnectivityEvent{
+			Timestamp:          e.ts,
+			EventType:          eventType,
+			TargetMetroPK:      e.remoteMetroPK,
+			TargetMetroCode:    e.remoteMetroCode,
+			AffectedDevicePK:   e.remotePK,
+			AffectedDeviceCode: e.remoteCode,
+		})
+	}
+	return changes
+}
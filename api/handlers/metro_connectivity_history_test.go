@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetroConnectivityEvents(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return t0.Add(time.Duration(h) * time.Hour) }
+	fra := func(h int, eventType, local, remote string) metroAdjacencyEvent {
+		return metroAdjacencyEvent{ts: at(h), eventType: eventType, localPK: local, remotePK: remote,
+			remoteCode: remote, remoteMetroPK: "fra", remoteMetroCode: "FRA"}
+	}
+	lon := func(h int, eventType, local, remote string) metroAdjacencyEvent {
+		return metroAdjacencyEvent{ts: at(h), eventType: eventType, localPK: local, remotePK: remote,
+			remoteCode: remote, remoteMetroPK: "lon", remoteMetroCode: "LON"}
+	}
+
+	events := []metroAdjacencyEvent{
+		// ams1-lon1 was up before the history starts
+		lon(1, "down", "ams1", "lon1"),
+		fra(2, "up", "ams1", "fra1"),
+		fra(2, "up", "ams1", "fra1"), // reported by the other side too
+		fra(3, "up", "ams2", "fra2"),
+		fra(4, "down", "ams1", "fra1"),
+		fra(5, "down", "ams2", "fra2"),
+		lon(6, "up", "ams2", "lon2"),
+	}
+
+	got := metroConnectivityEvents(events)
+	want := []MetroConnectivityEvent{
+		{Timestamp: at(1), EventType: "lost", TargetMetroPK: "lon", TargetMetroCode: "LON", AffectedDevicePK: "lon1", AffectedDeviceCode: "lon1"},
+		{Timestamp: at(2), EventType: "gained", TargetMetroPK: "fra", TargetMetroCode: "FRA", AffectedDevicePK: "fra1", AffectedDeviceCode: "fra1"},
+		{Timestamp: at(5), EventType: "lost", TargetMetroPK: "fra", TargetMetroCode: "FRA", AffectedDevicePK: "fra2", AffectedDeviceCode: "fra2"},
+		{Timestamp: at(6), EventType: "gained", TargetMetroPK: "lon", TargetMetroCode: "LON", AffectedDevicePK: "lon2", AffectedDeviceCode: "lon2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestMetroConnectivityEvents_StillUpFromBefore(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// ams2-fra2 goes down later, so it was up when ams1-fra1 went down
+	events := []metroAdjacencyEvent{
+		{ts: t0, eventType: "down", localPK: "ams1", remotePK: "fra1", remoteMetroPK: "fra"},
+		{ts: t0.Add(time.Hour), eventType: "down", localPK: "ams2", remotePK: "fra2", remoteMetroPK: "fra"},
+	}
+	got := metroConnectivityEvents(events)
+	if len(got) != 1 || got[0].EventType != "lost" || got[0].AffectedDevicePK != "fra2" {
+		t.Errorf("expected only the last adjacency going down to lose FRA, got %+v", got)
+	}
+}
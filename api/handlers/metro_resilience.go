@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// Resilience factor weights; they sum to 1. The SLA factor's weight is spread
+// over the others for metros without measured links.
+const (
+	resilienceDeviceWeight    = 0.20
+	resilienceExitWeight      = 0.25
+	resilienceLeafWeight      = 0.15
+	resilienceDiversityWeight = 0.25
+	resilienceSLAWeight       = 0.15
+)
+
+// Counts at which a factor scores 100
+const (
+	resilienceFullDevices   = 3
+	resilienceFullExits     = 2
+	resilienceFullDiversity = 3
+)
+
+// metroResilienceCacheTTL is how long resilience scores are reused. Path
+// diversity between every pair of metros is expensive to compute.
+const metroResilienceCacheTTL = 5 * time.Minute
+
+type metroResilienceCacheEntry struct {
+	response  []MetroResilienceScore
+	fetchedAt time.Time
+}
+
+var (
+	metroResilienceCache   = make(map[DZEnv]metroResilienceCacheEntry)
+	metroResilienceCacheMu sync.RWMutex
+)
+
+// MetroResilienceBreakdown holds the normalized 0-100 factors of a metro's
+// resilience score
+type MetroResilienceBreakdown struct {
+	DeviceScore    float64  `json:"deviceScore"`    // ISIS-enabled devices
+	ExitScore      float64  `json:"exitScore"`      // devices with adjacencies to other metros
+	LeafPenalty    float64  `json:"leafPenalty"`    // 100 if any device has a single ISIS neighbor
+	DiversityScore float64  `json:"diversityScore"` // node-disjoint paths to other metros, each capped at 3
+	SLAScore       *float64 `json:"slaScore"`       // links within committed RTT; null if none are measured
+}
+
+// MetroResilienceScore is a metro's weighted resilience score
+type MetroResilienceScore struct {
+	MetroPK         string                   `json:"metroPK"`
+	MetroCode       string                   `json:"metroCode"`
+	ResilienceScore float64                  `json:"resilienceScore"`
+	Breakdown       MetroResilienceBreakdown `json:"breakdown"`
+}
+
+// metroSLACounts counts a metro's links with a committed RTT and a measured
+// RTT, and how many of them are within the commitment
+type metroSLACounts struct {
+	Measured uint64
+	Within   uint64
+}
+
+// GetMetroResilienceScore returns a 0-100 resilience score per metro with ISIS
+// devices, combining device count, exit points, leaf devices, path diversity to
+// other metros and committed RTT compliance. Metros are ordered weakest first.
+func GetMetroResilienceScore(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	env := EnvFromContext(ctx)
+	metroResilienceCacheMu.RLock()
+	entry, ok := metroResilienceCache[env]
+	metroResilienceCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < metroResilienceCacheTTL {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Cache", "HIT")
+		_ = json.NewEncoder(w).Encode(entry.response)
+		return
+	}
+
+	metroCodes, err := loadMetroCodes(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Metro resilience metro query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	sla, err := loadMetroSLACounts(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Metro resilience SLA query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	start := time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("metro_resilience_score", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Metro resilience graph query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load ISIS topology", err))
+		return
+	}
+
+	response := metroResilienceScores(g, metroCodes, sla)
+
+	metroResilienceCacheMu.Lock()
+	metroResilienceCache[env] = metroResilienceCacheEntry{response: response, fetchedAt: time.Now()}
+	metroResilienceCacheMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", "MISS")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
+	}
+}
+
+// loadMetroCodes returns metro codes by pk
+func loadMetroCodes(ctx context.Context) (map[string]string, error) {
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `SELECT pk, code FROM dz_metros_current`)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := make(map[string]string)
+	for rows.Next() {
+		var pk, code string
+		if err := rows.Scan(&pk, &code); err != nil {
+			return nil, err
+		}
+		codes[pk] = code
+	}
+	return codes, rows.Err()
+}
+
+// loadMetroSLACounts counts, per metro, the links on either side of it that
+// have a committed RTT and RTT samples in the past hour, and how many have an
+// average RTT within the commitment.
+func loadMetroSLACounts(ctx context.Context) (map[string]metroSLACounts, error) {
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT metro_pk, count() AS measured, countIf(avg_rtt_us <= committed_rtt_ns / 1000.0) AS within
+		FROM (
+			SELECT l.pk,
+			       l.committed_rtt_ns,
+			       lat.avg_rtt_us,
+			       arrayJoin(arrayDistinct([da.metro_pk, dz.metro_pk])) AS metro_pk
+			FROM dz_links_current l
+			JOIN (
+				SELECT link_pk, avgIf(rtt_us, NOT loss AND rtt_us > 0) AS avg_rtt_us
+				FROM fact_dz_device_link_latency
+				WHERE event_ts > now() - INTERVAL 1 HOUR
+				GROUP BY link_pk
+				HAVING countIf(NOT loss AND rtt_us > 0) > 0
+			) lat ON l.pk = lat.link_pk
+			JOIN dz_devices_current da ON l.side_a_pk = da.pk
+			JOIN dz_devices_current dz ON l.side_z_pk = dz.pk
+			WHERE l.committed_rtt_ns > 0
+		)
+		WHERE metro_pk != ''
+		GROUP BY metro_pk
+	`)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]metroSLACounts)
+	for rows.Next() {
+		var metroPK string
+		var c metroSLACounts
+		if err := rows.Scan(&metroPK, &c.Measured, &c.Within); err != nil {
+			return nil, err
+		}
+		counts[metroPK] = c
+	}
+	return counts, rows.Err()
+}
+
+// metroResilienceScores scores every metro with ISIS devices in g
+func metroResilienceScores(g *isisGraph, metroCodes map[string]string, sla map[string]metroSLACounts) []MetroResilienceScore {
+	devices := make(map[string][]int)
+	for i, node := range g.nodes {
+		if node.MetroPK != "" {
+			devices[node.MetroPK] = append(devices[node.MetroPK], i)
+		}
+	}
+	metroPKs := make([]string, 0, len(devices))
+	for pk := range devices {
+		metroPKs = append(metroPKs, pk)
+	}
+	sort.Strings(metroPKs)
+
+	scores := make([]MetroResilienceScore, 0, len(metroPKs))
+	for _, metroPK := range metroPKs {
+		var b MetroResilienceBreakdown
+		b.DeviceScore = scaledScore(len(devices[metroPK]), resilienceFullDevices)
+
+		exits := 0
+		for _, v := range devices[metroPK] {
+			if len(g.adj[v]) == 1 {
+				b.LeafPenalty = 100
+			}
+			for _, e := range g.adj[v] {
+				if g.nodes[e.to].MetroPK != metroPK {
+					exits++
+					break
+				}
+			}
+		}
+		b.ExitScore = scaledScore(exits, resilienceFullExits)
+
+		if len(metroPKs) > 1 {
+			total := 0
+			for _, other := range metroPKs {
+				if other != metroPK {
+					total += min(nodeDisjointPaths(g.adj, devices[metroPK], devices[other], -1), resilienceFullDiversity)
+				}
+			}
+			b.DiversityScore = float64(total) * 100 / float64(resilienceFullDiversity*(len(metroPKs)-1))
+		}
+
+		score := resilienceDeviceWeight*b.DeviceScore +
+			resilienceExitWeight*b.ExitScore +
+			resilienceLeafWeight*(100-b.LeafPenalty) +
+			resilienceDiversityWeight*b.DiversityScore
+		if c, ok := sla[metroPK]; ok && c.Measured > 0 {
+			slaScore := float64(c.Within) * 100 / float64(c.Measured)
+			b.SLAScore = &slaScore
+			score += resilienceSLAWeight * slaScore
+		} else {
+			score /= 1 - resilienceSLAWeight
+		}
+
+		scores = append(scores, MetroResilienceScore{
+			MetroPK:         metroPK,
+			MetroCode:       metroCodes[metroPK],
+			ResilienceScore: math.Round(score*10) / 10,
+			Breakdown:       b,
+		})
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].ResilienceScore != scores[j].ResilienceScore {
+			return scores[i].ResilienceScore < scores[j].ResilienceScore
+		}
+		return scores[i].MetroCode < scores[j].MetroCode
+	})
+	return scores
+}
+
+// scaledScore maps count to 0-100, reaching 100 at full
+func scaledScore(count, full int) float64 {
+	return float64(min(count, full)) * 100 / float64(full)
+}
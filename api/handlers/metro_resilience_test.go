@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMetroResilienceScores(t *testing.T) {
+	// Metro A has 0 and 1, metro B has 2, 3 and 4, and metro C has the leaf 5
+	// hanging off 4:
+	//
+	//   0 - 2 - 4 - 5
+	//   |   | /
+	//   1 - 3
+	g := &isisGraph{
+		nodes: []isisGraphNode{
+			{PK: "a0", MetroPK: "metro-a"},
+			{PK: "a1", MetroPK: "metro-a"},
+			{PK: "b2", MetroPK: "metro-b"},
+			{PK: "b3", MetroPK: "metro-b"},
+			{PK: "b4", MetroPK: "metro-b"},
+			{PK: "c5", MetroPK: "metro-c"},
+		},
+		adj: undirectedGraph(6, [][2]int{{0, 1}, {0, 2}, {1, 3}, {2, 3}, {3, 4}, {2, 4}, {4, 5}}),
+	}
+	codes := map[string]string{"metro-a": "AMS", "metro-b": "BER", "metro-c": "CDG"}
+	sla := map[string]metroSLACounts{
+		"metro-a": {Measured: 4, Within: 3},
+		"metro-b": {Measured: 2, Within: 2},
+	}
+
+	scores := metroResilienceScores(g, codes, sla)
+	if len(scores) != 3 {
+		t.Fatalf("expected 3 metros, got %d", len(scores))
+	}
+
+	// Weakest first
+	for i, want := range []string{"CDG", "AMS", "BER"} {
+		if scores[i].MetroCode != want {
+			t.Errorf("expected metro %d to be %s, got %s", i, want, scores[i].MetroCode)
+		}
+	}
+
+	cdg := scores[0]
+	if cdg.Breakdown.LeafPenalty != 100 {
+		t.Errorf("expected CDG leaf penalty 100, got %f", cdg.Breakdown.LeafPenalty)
+	}
+	if cdg.Breakdown.SLAScore != nil {
+		t.Errorf("expected no CDG SLA score, got %f", *cdg.Breakdown.SLAScore)
+	}
+	// Without SLA data the other weights are scaled up: (0.2*33.3 + 0.25*50 + 0.25*33.3) / 0.85
+	if cdg.ResilienceScore != 32.4 {
+		t.Errorf("expected CDG score 32.4, got %f", cdg.ResilienceScore)
+	}
+
+	ams := scores[1]
+	b := ams.Breakdown
+	if math.Abs(b.DeviceScore-200.0/3) > 1e-9 || b.ExitScore != 100 || b.LeafPenalty != 0 {
+		t.Errorf("unexpected AMS breakdown %+v", b)
+	}
+	// Two disjoint paths to BER and one to CDG, each out of 3
+	if b.DiversityScore != 50 {
+		t.Errorf("expected AMS diversity 50, got %f", b.DiversityScore)
+	}
+	if b.SLAScore == nil || *b.SLAScore != 75 {
+		t.Errorf("expected AMS SLA score 75, got %v", b.SLAScore)
+	}
+	if ams.ResilienceScore != 77.1 {
+		t.Errorf("expected AMS score 77.1, got %f", ams.ResilienceScore)
+	}
+
+	if scores[2].ResilienceScore != 87.5 {
+		t.Errorf("expected BER score 87.5, got %f", scores[2].ResilienceScore)
+	}
+}
+
+func TestMetroResilienceScores_SingleMetro(t *testing.T) {
+	g := &isisGraph{
+		nodes: []isisGraphNode{{PK: "a0", MetroPK: "metro-a"}, {PK: "a1", MetroPK: "metro-a"}, {PK: "x"}},
+		adj:   undirectedGraph(3, [][2]int{{0, 1}, {1, 2}}),
+	}
+
+	scores := metroResilienceScores(g, nil, nil)
+	if len(scores) != 1 {
+		t.Fatalf("expected devices without a metro to be skipped, got %d metros", len(scores))
+	}
+	b := scores[0].Breakdown
+	if b.DiversityScore != 0 {
+		t.Errorf("expected no diversity without other metros, got %f", b.DiversityScore)
+	}
+	if b.LeafPenalty != 100 || b.ExitScore != 50 {
+		t.Errorf("unexpected breakdown %+v", b)
+	}
+}
@@ -3,12 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/malbeclabs/lake/api/handlers/dberror"
 	"github.com/malbeclabs/lake/api/metrics"
 )
 
@@ -33,8 +31,8 @@ func GetMetros(w http.ResponseWriter, r *http.Request) {
 	countQuery := `SELECT count(*) FROM dz_metros_current`
 	var total uint64
 	if err := envDB(ctx).QueryRow(ctx, countQuery).Scan(&total); err != nil {
-		log.Printf("Metros count error: %v", err)
-		http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Metros count error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 
@@ -72,8 +70,8 @@ func GetMetros(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Metros query error: %v", err)
-		http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Metros query error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -90,16 +88,16 @@ func GetMetros(w http.ResponseWriter, r *http.Request) {
 			&m.DeviceCount,
 			&m.UserCount,
 		); err != nil {
-			log.Printf("Metros scan error: %v", err)
-			http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Metros scan error", "error", err)
+			writeDBError(w, r, err)
 			return
 		}
 		metros = append(metros, m)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Metros rows error: %v", err)
-		http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Metros rows error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 
@@ -117,7 +115,7 @@ func GetMetros(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -141,7 +139,7 @@ func GetMetro(w http.ResponseWriter, r *http.Request) {
 
 	pk := chi.URLParam(r, "pk")
 	if pk == "" {
-		http.Error(w, "missing metro pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing metro pk")
 		return
 	}
 
@@ -226,13 +224,13 @@ func GetMetro(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Metro query error: %v", err)
-		http.Error(w, "metro not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("Metro query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeMetroNotFound, "metro not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(metro); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"sync"
 	"time"
@@ -48,8 +47,8 @@ func GetMulticastGroups(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("MulticastGroups query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("MulticastGroups query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -66,16 +65,16 @@ func GetMulticastGroups(w http.ResponseWriter, r *http.Request) {
 			&g.PublisherCount,
 			&g.SubscriberCount,
 		); err != nil {
-			log.Printf("MulticastGroups scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("MulticastGroups scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		groups = append(groups, g)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("MulticastGroups rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("MulticastGroups rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -119,14 +118,14 @@ func GetMulticastGroups(w http.ResponseWriter, r *http.Request) {
 
 		countRows, err := envDB(ctx).Query(ctx, countsQuery, groupPKs)
 		if err != nil {
-			log.Printf("MulticastGroups counts query error (non-fatal): %v", err)
+			LoggerFromContext(ctx).Warn("MulticastGroups counts query error", "error", err)
 		} else {
 			defer countRows.Close()
 			for countRows.Next() {
 				var gpk string
 				var pubCount, subCount uint64
 				if err := countRows.Scan(&gpk, &pubCount, &subCount); err != nil {
-					log.Printf("MulticastGroups counts scan error: %v", err)
+					LoggerFromContext(ctx).Error("MulticastGroups counts scan error", "error", err)
 					continue
 				}
 				if idx, ok := groupByPK[gpk]; ok {
@@ -139,7 +138,7 @@ func GetMulticastGroups(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(groups); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -184,7 +183,7 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 
 	pkOrCode := chi.URLParam(r, "pk")
 	if pkOrCode == "" {
-		http.Error(w, "missing multicast group pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing multicast group pk")
 		return
 	}
 
@@ -215,8 +214,8 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 		&group.SubscriberCount,
 	)
 	if err != nil {
-		log.Printf("MulticastGroup query error: %v", err)
-		http.Error(w, "multicast group not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("MulticastGroup query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeMulticastGroupNotFound, "multicast group not found")
 		return
 	}
 
@@ -257,8 +256,8 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("MulticastGroup members query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("MulticastGroup members query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -280,16 +279,16 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 			&m.OwnerPubkey,
 			&m.TunnelID,
 		); err != nil {
-			log.Printf("MulticastGroup members scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("MulticastGroup members scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		members = append(members, m)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("MulticastGroup members rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("MulticastGroup members rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -333,7 +332,7 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 
 		trafficRows, err := envDB(ctx).Query(ctx, trafficQuery)
 		if err != nil {
-			log.Printf("MulticastGroup traffic query error (non-fatal): %v", err)
+			LoggerFromContext(ctx).Warn("MulticastGroup traffic query error", "error", err)
 		} else {
 			defer trafficRows.Close()
 			for trafficRows.Next() {
@@ -341,7 +340,7 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 				var tunnelID int64
 				var inBps, outBps, inPps, outPps float64
 				if err := trafficRows.Scan(&devicePK, &tunnelID, &inBps, &outBps, &inPps, &outPps); err != nil {
-					log.Printf("MulticastGroup traffic scan error: %v", err)
+					LoggerFromContext(ctx).Error("MulticastGroup traffic scan error", "error", err)
 					continue
 				}
 				key := tunnelKey{devicePK, tunnelID}
@@ -392,14 +391,14 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 			`
 			gossipRows, err := envDB(ctx).Query(ctx, gossipQuery, clientIPs)
 			if err != nil {
-				log.Printf("MulticastGroup gossip query error (non-fatal): %v", err)
+				LoggerFromContext(ctx).Warn("MulticastGroup gossip query error", "error", err)
 			} else {
 				defer gossipRows.Close()
 				for gossipRows.Next() {
 					var gossipIP, pubkey, votePubkey string
 					var stakeSol float64
 					if err := gossipRows.Scan(&gossipIP, &pubkey, &votePubkey, &stakeSol); err != nil {
-						log.Printf("MulticastGroup gossip scan error: %v", err)
+						LoggerFromContext(ctx).Error("MulticastGroup gossip scan error", "error", err)
 						continue
 					}
 					if indices, ok := clientIPToMembers[gossipIP]; ok {
@@ -446,7 +445,7 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 
 			leaderRows, err := envDB(ctx).Query(ctx, leaderQuery, clientIPs)
 			if err != nil {
-				log.Printf("MulticastGroup leader query error (non-fatal): %v", err)
+				LoggerFromContext(ctx).Warn("MulticastGroup leader query error", "error", err)
 			} else {
 				defer leaderRows.Close()
 				for leaderRows.Next() {
@@ -455,7 +454,7 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 					var isLeader uint8
 					var lastSlot, nextSlot uint64
 					if err := leaderRows.Scan(&clientIP, &nodePubkey, &currentSlot, &isLeader, &lastSlot, &nextSlot); err != nil {
-						log.Printf("MulticastGroup leader scan error: %v", err)
+						LoggerFromContext(ctx).Error("MulticastGroup leader scan error", "error", err)
 						continue
 					}
 					if indices, ok := clientIPToMembers[clientIP]; ok {
@@ -481,7 +480,7 @@ func GetMulticastGroup(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(group); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -502,7 +501,7 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 
 	pkOrCode := chi.URLParam(r, "pk")
 	if pkOrCode == "" {
-		http.Error(w, "missing multicast group pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing multicast group pk")
 		return
 	}
 
@@ -541,8 +540,8 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 	err := envDB(ctx).QueryRow(ctx,
 		`SELECT pk FROM dz_multicast_groups_current WHERE pk = ? OR code = ?`, pkOrCode, pkOrCode).Scan(&groupPK)
 	if err != nil {
-		log.Printf("MulticastGroupTraffic group query error: %v", err)
-		http.Error(w, "multicast group not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("MulticastGroupTraffic group query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeMulticastGroupNotFound, "multicast group not found")
 		return
 	}
 
@@ -567,8 +566,8 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 
 	memberRows, err := envDB(ctx).Query(ctx, membersQuery, groupPK, groupPK, groupPK, groupPK, groupPK)
 	if err != nil {
-		log.Printf("MulticastGroupTraffic members query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("MulticastGroupTraffic members query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer memberRows.Close()
@@ -584,7 +583,7 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 	for memberRows.Next() {
 		var m memberInfo
 		if err := memberRows.Scan(&m.devicePK, &m.tunnelID, &m.mode); err != nil {
-			log.Printf("MulticastGroupTraffic members scan error: %v", err)
+			LoggerFromContext(ctx).Error("MulticastGroupTraffic members scan error", "error", err)
 			continue
 		}
 		if m.tunnelID > 0 {
@@ -649,8 +648,8 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("MulticastGroupTraffic traffic query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("MulticastGroupTraffic traffic query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer trafficRows.Close()
@@ -659,8 +658,8 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 	for trafficRows.Next() {
 		var p MulticastTrafficPoint
 		if err := trafficRows.Scan(&p.Time, &p.DevicePK, &p.TunnelID, &p.InBps, &p.OutBps, &p.InPps, &p.OutPps); err != nil {
-			log.Printf("MulticastGroupTraffic traffic scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("MulticastGroupTraffic traffic scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		// Only include rows for exact (device_pk, tunnel_id) member pairs
@@ -674,8 +673,8 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := trafficRows.Err(); err != nil {
-		log.Printf("MulticastGroupTraffic rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("MulticastGroupTraffic rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -685,7 +684,7 @@ func GetMulticastGroupTraffic(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(points); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -718,35 +717,18 @@ type MulticastTreeResponse struct {
 	Error           string              `json:"error,omitempty"`
 }
 
-// GetMulticastTreePaths computes paths from all publishers to all subscribers in a multicast group
-func GetMulticastTreePaths(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
-	pkOrCode := chi.URLParam(r, "pk")
-	if pkOrCode == "" {
-		writeJSON(w, MulticastTreeResponse{Error: "missing multicast group pk"})
-		return
-	}
+// multicastMemberDevice is a device with an activated user publishing or
+// subscribing to a multicast group
+type multicastMemberDevice struct {
+	PK   string
+	Code string
+}
 
+// queryMulticastMemberDevices returns the distinct devices of a multicast
+// group's publishers and subscribers. A device with users in both roles
+// appears in both lists; users without a device assignment are skipped.
+func queryMulticastMemberDevices(ctx context.Context, groupPK string) (publishers, subscribers []multicastMemberDevice, err error) {
 	start := time.Now()
-	response := MulticastTreeResponse{
-		Paths: []MulticastTreePath{},
-	}
-
-	// First get group info and members from ClickHouse (accept pk or code)
-	groupQuery := `
-		SELECT pk, COALESCE(code, '') FROM dz_multicast_groups_current WHERE pk = ? OR code = ?
-	`
-	err := envDB(ctx).QueryRow(ctx, groupQuery, pkOrCode, pkOrCode).Scan(&response.GroupPK, &response.GroupCode)
-	if err != nil {
-		log.Printf("MulticastTreePaths group query error: %v", err)
-		response.Error = "multicast group not found"
-		writeJSON(w, response)
-		return
-	}
-
-	// Get publishers and subscribers
 	membersQuery := `
 		SELECT
 			CASE
@@ -766,30 +748,19 @@ func GetMulticastTreePaths(w http.ResponseWriter, r *http.Request) {
 			)
 	`
 
-	rows, err := envDB(ctx).Query(ctx, membersQuery, response.GroupPK, response.GroupPK, response.GroupPK, response.GroupPK, response.GroupPK)
-	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, err)
-
+	rows, err := envDB(ctx).Query(ctx, membersQuery, groupPK, groupPK, groupPK, groupPK, groupPK)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
 	if err != nil {
-		log.Printf("MulticastTreePaths members query error: %v", err)
-		response.Error = err.Error()
-		writeJSON(w, response)
-		return
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	type deviceInfo struct {
-		PK   string
-		Code string
-	}
-	var publishers, subscribers []deviceInfo
 	publisherSet := make(map[string]bool)
 	subscriberSet := make(map[string]bool)
-
 	for rows.Next() {
 		var mode, devicePK, deviceCode string
 		if err := rows.Scan(&mode, &devicePK, &deviceCode); err != nil {
-			log.Printf("MulticastTreePaths members scan error: %v", err)
+			LoggerFromContext(ctx).Error("Multicast members scan error", "error", err)
 			continue
 		}
 		if devicePK == "" {
@@ -798,15 +769,53 @@ func GetMulticastTreePaths(w http.ResponseWriter, r *http.Request) {
 
 		// Publishers: P or P+S
 		if (mode == "P" || mode == "P+S") && !publisherSet[devicePK] {
-			publishers = append(publishers, deviceInfo{PK: devicePK, Code: deviceCode})
+			publishers = append(publishers, multicastMemberDevice{PK: devicePK, Code: deviceCode})
 			publisherSet[devicePK] = true
 		}
 		// Subscribers: S or P+S
 		if (mode == "S" || mode == "P+S") && !subscriberSet[devicePK] {
-			subscribers = append(subscribers, deviceInfo{PK: devicePK, Code: deviceCode})
+			subscribers = append(subscribers, multicastMemberDevice{PK: devicePK, Code: deviceCode})
 			subscriberSet[devicePK] = true
 		}
 	}
+	return publishers, subscribers, rows.Err()
+}
+
+// GetMulticastTreePaths computes paths from all publishers to all subscribers in a multicast group
+func GetMulticastTreePaths(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pkOrCode := chi.URLParam(r, "pk")
+	if pkOrCode == "" {
+		writeJSON(w, MulticastTreeResponse{Error: "missing multicast group pk"})
+		return
+	}
+
+	start := time.Now()
+	response := MulticastTreeResponse{
+		Paths: []MulticastTreePath{},
+	}
+
+	// First get group info and members from ClickHouse (accept pk or code)
+	groupQuery := `
+		SELECT pk, COALESCE(code, '') FROM dz_multicast_groups_current WHERE pk = ? OR code = ?
+	`
+	err := envDB(ctx).QueryRow(ctx, groupQuery, pkOrCode, pkOrCode).Scan(&response.GroupPK, &response.GroupCode)
+	if err != nil {
+		LoggerFromContext(ctx).Error("MulticastTreePaths group query error", "error", err)
+		response.Error = "multicast group not found"
+		writeJSON(w, response)
+		return
+	}
+
+	publishers, subscribers, err := queryMulticastMemberDevices(ctx, response.GroupPK)
+	if err != nil {
+		LoggerFromContext(ctx).Error("MulticastTreePaths members query error", "error", err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
 
 	response.PublisherCount = len(publishers)
 	response.SubscriberCount = len(subscribers)
@@ -933,12 +942,12 @@ func GetMulticastTreePaths(w http.ResponseWriter, r *http.Request) {
 	// Collect results
 	for result := range resultChan {
 		if result.err != nil {
-			log.Printf("MulticastTreePaths path query error: %v", result.err)
+			LoggerFromContext(ctx).Error("MulticastTreePaths path query error", "error", result.err)
 			continue
 		}
 		response.Paths = append(response.Paths, result.path)
 	}
 
-	log.Printf("MulticastTreePaths: %d paths found in %v", len(response.Paths), time.Since(start))
+	LoggerFromContext(ctx).Info("MulticastTreePaths completed", "paths", len(response.Paths), "duration_ms", time.Since(start).Milliseconds())
 	writeJSON(w, response)
 }
@@ -512,3 +512,17 @@ func TestGetMulticastGroup_ValidatorEnrichment(t *testing.T) {
 	assert.Equal(t, "vote-pub-1", pub.VotePubkey, "should resolve vote_pubkey from vote accounts")
 	assert.Equal(t, float64(5000), pub.StakeSol, "should resolve stake from vote accounts")
 }
+
+func TestGetMulticastGroupTopology_NotFound(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/multicast-groups/nonexistent/topology", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("pk", "nonexistent")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	handlers.GetMulticastGroupTopology(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
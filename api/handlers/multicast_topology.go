@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers/dberror"
+	"github.com/malbeclabs/lake/api/metrics"
+	neo4jdriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Multicast tree node types set on ISISNodeData.NodeType
+const (
+	MulticastNodeRoot       = "root"
+	MulticastNodeSubscriber = "subscriber"
+	MulticastNodeTransit    = "transit"
+)
+
+// buildMulticastTopology assembles the multicast distribution tree graph from
+// the member devices and the devices and edges on the publisher-to-subscriber
+// paths. Publisher devices are marked as roots, subscriber-only devices as
+// subscribers and everything else as transit. Members missing from the graph
+// are still included as nodes, labelled with their ClickHouse device code.
+func buildMulticastTopology(publishers, subscribers []multicastMemberDevice, devices []ISISNodeData, edges []ISISEdgeData) ISISTopologyResponse {
+	nodeTypes := make(map[string]string)
+	for _, s := range subscribers {
+		nodeTypes[s.PK] = MulticastNodeSubscriber
+	}
+	for _, p := range publishers {
+		nodeTypes[p.PK] = MulticastNodeRoot
+	}
+
+	nodes := make(map[string]ISISNodeData, len(devices))
+	for _, d := range devices {
+		nodes[d.ID] = d
+	}
+	for _, members := range [][]multicastMemberDevice{publishers, subscribers} {
+		for _, m := range members {
+			if _, ok := nodes[m.PK]; !ok {
+				nodes[m.PK] = ISISNodeData{ID: m.PK, Label: m.Code}
+			}
+		}
+	}
+
+	response := ISISTopologyResponse{
+		Nodes: make([]ISISNode, 0, len(nodes)),
+		Edges: make([]ISISEdge, 0, len(edges)),
+	}
+	for pk, n := range nodes {
+		n.NodeType = nodeTypes[pk]
+		if n.NodeType == "" {
+			n.NodeType = MulticastNodeTransit
+		}
+		response.Nodes = append(response.Nodes, ISISNode{Data: n})
+	}
+	sort.Slice(response.Nodes, func(i, j int) bool {
+		return response.Nodes[i].Data.ID < response.Nodes[j].Data.ID
+	})
+	for _, e := range edges {
+		if _, ok := nodes[e.Source]; !ok {
+			continue
+		}
+		if _, ok := nodes[e.Target]; !ok {
+			continue
+		}
+		response.Edges = append(response.Edges, ISISEdge{Data: e})
+	}
+	sort.Slice(response.Edges, func(i, j int) bool {
+		return response.Edges[i].Data.ID < response.Edges[j].Data.ID
+	})
+	return response
+}
+
+// GetMulticastGroupTopology returns a multicast group's distribution tree as a
+// graph in the same {nodes, edges} format as GetISISTopology. The graph has no
+// multicast relationships, so the tree is the union of the lowest-metric IS-IS
+// paths from each publisher device (the root) to each subscriber device.
+func GetMulticastGroupTopology(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pkOrCode := chi.URLParam(r, "pk")
+	if pkOrCode == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing multicast group pk")
+		return
+	}
+
+	var groupPK string
+	err := envDB(ctx).QueryRow(ctx,
+		`SELECT pk FROM dz_multicast_groups_current WHERE pk = ? OR code = ?`, pkOrCode, pkOrCode).Scan(&groupPK)
+	if err != nil {
+		LoggerFromContext(ctx).Error("MulticastGroupTopology group query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeMulticastGroupNotFound, "multicast group not found")
+		return
+	}
+
+	publishers, subscribers, err := queryMulticastMemberDevices(ctx, groupPK)
+	if err != nil {
+		LoggerFromContext(ctx).Error("MulticastGroupTopology members query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	var pairs []map[string]any
+	for _, pub := range publishers {
+		for _, sub := range subscribers {
+			if pub.PK != sub.PK {
+				pairs = append(pairs, map[string]any{"from_pk": pub.PK, "to_pk": sub.PK})
+			}
+		}
+	}
+
+	runNeo4jQuery := func(cypher string, params map[string]any) ([]*neo4jdriver.Record, error) {
+		return dberror.Retry(ctx, dberror.DefaultRetryConfig(), func() ([]*neo4jdriver.Record, error) {
+			session := config.Neo4jSession(ctx)
+			defer session.Close(ctx)
+
+			result, err := session.Run(ctx, cypher, params)
+			if err != nil {
+				return nil, err
+			}
+			return result.Collect(ctx)
+		})
+	}
+
+	start := time.Now()
+	var edges []ISISEdgeData
+	pks := make(map[string]bool)
+	for _, members := range [][]multicastMemberDevice{publishers, subscribers} {
+		for _, m := range members {
+			pks[m.PK] = true
+		}
+	}
+	if len(pairs) > 0 {
+		edgeCypher := `
+			UNWIND $pairs AS pair
+			MATCH (a:Device {pk: pair.from_pk}), (b:Device {pk: pair.to_pk})
+			CALL apoc.algo.dijkstra(a, b, 'ISIS_ADJACENT>', 'metric') YIELD path
+			UNWIND relationships(path) AS r
+			WITH DISTINCT startNode(r).pk AS from_pk, endNode(r).pk AS to_pk, r.metric AS metric
+			RETURN from_pk, to_pk, metric
+		`
+		edgeRecords, err := runNeo4jQuery(edgeCypher, map[string]any{"pairs": pairs})
+		if err != nil {
+			metrics.RecordNeo4jQuery("multicast_topology", time.Since(start), err)
+			LoggerFromContext(ctx).Error("MulticastGroupTopology edge query error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		for _, record := range edgeRecords {
+			fromPK, _ := record.Get("from_pk")
+			toPK, _ := record.Get("to_pk")
+			metric, _ := record.Get("metric")
+			edges = append(edges, ISISEdgeData{
+				ID:     asString(fromPK) + "->" + asString(toPK),
+				Source: asString(fromPK),
+				Target: asString(toPK),
+				Metric: uint32(asInt64(metric)),
+			})
+			pks[asString(fromPK)] = true
+			pks[asString(toPK)] = true
+		}
+	}
+
+	pkList := make([]string, 0, len(pks))
+	for pk := range pks {
+		pkList = append(pkList, pk)
+	}
+	deviceCypher := `
+		MATCH (d:Device)
+		WHERE d.pk IN $pks
+		OPTIONAL MATCH (d)-[:LOCATED_IN]->(m:Metro)
+		RETURN d.pk AS pk,
+		       d.code AS code,
+		       d.status AS status,
+		       d.device_type AS device_type,
+		       d.isis_system_id AS system_id,
+		       d.isis_router_id AS router_id,
+		       m.pk AS metro_pk
+	`
+	deviceRecords, err := runNeo4jQuery(deviceCypher, map[string]any{"pks": pkList})
+	metrics.RecordNeo4jQuery("multicast_topology", time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("MulticastGroupTopology device query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	devices := make([]ISISNodeData, 0, len(deviceRecords))
+	for _, record := range deviceRecords {
+		pk, _ := record.Get("pk")
+		code, _ := record.Get("code")
+		status, _ := record.Get("status")
+		deviceType, _ := record.Get("device_type")
+		systemID, _ := record.Get("system_id")
+		routerID, _ := record.Get("router_id")
+		metroPK, _ := record.Get("metro_pk")
+		devices = append(devices, ISISNodeData{
+			ID:         asString(pk),
+			Label:      asString(code),
+			Status:     asString(status),
+			DeviceType: asString(deviceType),
+			SystemID:   asString(systemID),
+			RouterID:   asString(routerID),
+			MetroPK:    asString(metroPK),
+		})
+	}
+
+	writeJSON(w, buildMulticastTopology(publishers, subscribers, devices, edges))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMulticastTopology(t *testing.T) {
+	t.Parallel()
+
+	publishers := []multicastMemberDevice{{PK: "ams1", Code: "AMS1"}, {PK: "both", Code: "BOTH"}}
+	subscribers := []multicastMemberDevice{{PK: "nyc1", Code: "NYC1"}, {PK: "both", Code: "BOTH"}, {PK: "lax1", Code: "LAX1"}}
+	devices := []ISISNodeData{
+		{ID: "ams1", Label: "AMS1", Status: "activated"},
+		{ID: "fra1", Label: "FRA1", Status: "activated"},
+		{ID: "nyc1", Label: "NYC1", Status: "activated"},
+		{ID: "both", Label: "BOTH", Status: "activated"},
+	}
+	edges := []ISISEdgeData{
+		{ID: "fra1->nyc1", Source: "fra1", Target: "nyc1", Metric: 20},
+		{ID: "ams1->fra1", Source: "ams1", Target: "fra1", Metric: 10},
+		{ID: "fra1->gone", Source: "fra1", Target: "gone", Metric: 5},
+	}
+
+	resp := buildMulticastTopology(publishers, subscribers, devices, edges)
+
+	nodeTypes := make(map[string]string)
+	for _, n := range resp.Nodes {
+		nodeTypes[n.Data.ID] = n.Data.NodeType
+	}
+	assert.Equal(t, map[string]string{
+		"ams1": MulticastNodeRoot,
+		"both": MulticastNodeRoot, // publishing wins over subscribing
+		"fra1": MulticastNodeTransit,
+		"lax1": MulticastNodeSubscriber,
+		"nyc1": MulticastNodeSubscriber,
+	}, nodeTypes)
+
+	// Members missing from the graph keep their ClickHouse code
+	require.Len(t, resp.Nodes, 5)
+	assert.Equal(t, "lax1", resp.Nodes[3].Data.ID)
+	assert.Equal(t, "LAX1", resp.Nodes[3].Data.Label)
+
+	// Edges to unknown nodes are dropped; the rest are sorted by ID
+	require.Len(t, resp.Edges, 2)
+	assert.Equal(t, "ams1->fra1", resp.Edges[0].Data.ID)
+	assert.Equal(t, "fra1->nyc1", resp.Edges[1].Data.ID)
+}
+
+func TestBuildMulticastTopology_Empty(t *testing.T) {
+	t.Parallel()
+
+	resp := buildMulticastTopology(nil, nil, nil, nil)
+	assert.NotNil(t, resp.Nodes)
+	assert.NotNil(t, resp.Edges)
+	assert.Empty(t, resp.Nodes)
+	assert.Empty(t, resp.Edges)
+}
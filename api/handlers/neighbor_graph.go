@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/malbeclabs/lake/api/handlers/dberror"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// Bounds on the depth of a neighbor graph
+const (
+	defaultNeighborGraphDepth = 2
+	maxNeighborGraphDepth     = 4
+)
+
+// GetNeighborGraph returns the ISIS topology within depth hops (1-4, default
+// 2) of the center device, with every adjacency between those devices. The
+// center node is marked with isCenter.
+func GetNeighborGraph(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	response := ISISTopologyResponse{
+		Nodes: []ISISNode{},
+		Edges: []ISISEdge{},
+	}
+
+	center := r.URL.Query().Get("center")
+	if center == "" {
+		response.Error = "center is required"
+		writeJSON(w, response)
+		return
+	}
+	depth := defaultNeighborGraphDepth
+	if s := r.URL.Query().Get("depth"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxNeighborGraphDepth {
+			response.Error = "depth must be an integer from 1 to 4"
+			writeJSON(w, response)
+			return
+		}
+		depth = n
+	}
+
+	start := time.Now()
+	response, err := loadISISTopology(ctx, isisTopologyFilter{SeedPK: center, MaxHops: depth})
+	metrics.RecordNeo4jQuery("neighbor_graph", time.Since(start), err)
+	if err != nil {
+		response.Error = dberror.UserMessage(err)
+		writeJSON(w, response)
+		return
+	}
+
+	found := false
+	for i := range response.Nodes {
+		if response.Nodes[i].Data.ID == center {
+			response.Nodes[i].Data.IsCenter = true
+			found = true
+		}
+	}
+	if !found {
+		response.Error = "Device not found in ISIS topology"
+	}
+
+	writeJSON(w, response)
+}
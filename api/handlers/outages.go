@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"sort"
 	"strconv"
@@ -12,6 +11,7 @@ import (
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/parquet-go/parquet-go"
 )
 
 // LinkOutage represents a discrete outage event on a link
@@ -153,7 +153,7 @@ func GetLinkOutages(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.Header().Set("X-Cache", "HIT")
 			if err := json.NewEncoder(w).Encode(cached); err != nil {
-				log.Printf("Error encoding cached outages response: %v", err)
+				LoggerFromContext(r.Context()).Error("Error encoding cached outages response", "error", err)
 			}
 			return
 		}
@@ -187,7 +187,7 @@ func GetLinkOutages(w http.ResponseWriter, r *http.Request) {
 	if outageType == "all" || outageType == "status" {
 		statusOutages, err := fetchStatusOutages(ctx, envDB(ctx), duration, filters)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to fetch status outages: %v", err), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("Failed to fetch status outages: %v", err))
 			return
 		}
 		outages = append(outages, statusOutages...)
@@ -197,7 +197,7 @@ func GetLinkOutages(w http.ResponseWriter, r *http.Request) {
 	if outageType == "all" || outageType == "loss" {
 		lossOutages, err := fetchPacketLossOutages(ctx, envDB(ctx), duration, threshold, filters)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to fetch packet loss outages: %v", err), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("Failed to fetch packet loss outages: %v", err))
 			return
 		}
 		outages = append(outages, lossOutages...)
@@ -207,7 +207,7 @@ func GetLinkOutages(w http.ResponseWriter, r *http.Request) {
 	if outageType == "all" || outageType == "no_data" {
 		noDataOutages, err := fetchNoDataOutages(ctx, envDB(ctx), duration, filters)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to fetch no-data outages: %v", err), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("Failed to fetch no-data outages: %v", err))
 			return
 		}
 		outages = append(outages, noDataOutages...)
@@ -1432,9 +1432,9 @@ func findCompletedNoDataOutages(ctx context.Context, conn driver.Conn, duration
 	return outages, nil
 }
 
-// GetLinkOutagesCSV returns outages as CSV for export
-func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters (same as GetLinkOutages)
+// fetchLinkOutagesForExport runs the outage queries for the export endpoints
+// using the same query parameters as GetLinkOutages.
+func fetchLinkOutagesForExport(ctx context.Context, r *http.Request) ([]LinkOutage, time.Duration, error) {
 	timeRange := r.URL.Query().Get("range")
 	if timeRange == "" {
 		timeRange = "24h"
@@ -1453,16 +1453,12 @@ func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
 	filterStr := r.URL.Query().Get("filter")
 	filters := parseOutageFilters(filterStr)
 
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
 	var outages []LinkOutage
 
 	if outageType == "all" || outageType == "status" {
 		statusOutages, err := fetchStatusOutages(ctx, envDB(ctx), duration, filters)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to fetch status outages: %v", err), http.StatusInternalServerError)
-			return
+			return nil, duration, fmt.Errorf("failed to fetch status outages: %w", err)
 		}
 		outages = append(outages, statusOutages...)
 	}
@@ -1470,8 +1466,7 @@ func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
 	if outageType == "all" || outageType == "loss" {
 		lossOutages, err := fetchPacketLossOutages(ctx, envDB(ctx), duration, threshold, filters)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to fetch packet loss outages: %v", err), http.StatusInternalServerError)
-			return
+			return nil, duration, fmt.Errorf("failed to fetch packet loss outages: %w", err)
 		}
 		outages = append(outages, lossOutages...)
 	}
@@ -1479,8 +1474,7 @@ func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
 	if outageType == "all" || outageType == "no_data" {
 		noDataOutages, err := fetchNoDataOutages(ctx, envDB(ctx), duration, filters)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to fetch no-data outages: %v", err), http.StatusInternalServerError)
-			return
+			return nil, duration, fmt.Errorf("failed to fetch no-data outages: %w", err)
 		}
 		outages = append(outages, noDataOutages...)
 	}
@@ -1490,6 +1484,28 @@ func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
 		return outages[i].StartedAt > outages[j].StartedAt
 	})
 
+	return outages, duration, nil
+}
+
+// linkOutageDetails returns the human-readable details column for exports.
+func linkOutageDetails(o LinkOutage) string {
+	if o.OutageType == "status" {
+		return fmt.Sprintf("%s -> %s", strVal(o.PreviousStatus), strVal(o.NewStatus))
+	}
+	return fmt.Sprintf("peak %.1f%% (threshold %.0f%%)", floatVal(o.PeakLossPct), floatVal(o.ThresholdPct))
+}
+
+// GetLinkOutagesCSV returns outages as CSV for export
+func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	outages, _, err := fetchLinkOutagesForExport(ctx, r)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
 	// Generate CSV
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=link-outages.csv")
@@ -1498,12 +1514,7 @@ func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("id,link_code,link_type,side_a_metro,side_z_metro,contributor,outage_type,severity,details,started_at,ended_at,duration_seconds,is_ongoing\n"))
 
 	for _, o := range outages {
-		var details string
-		if o.OutageType == "status" {
-			details = fmt.Sprintf("%s -> %s", strVal(o.PreviousStatus), strVal(o.NewStatus))
-		} else {
-			details = fmt.Sprintf("peak %.1f%% (threshold %.0f%%)", floatVal(o.PeakLossPct), floatVal(o.ThresholdPct))
-		}
+		details := linkOutageDetails(o)
 
 		endedAt := ""
 		if o.EndedAt != nil {
@@ -1523,6 +1534,91 @@ func GetLinkOutagesCSV(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// linkOutageParquetBatchSize is the number of rows written per Parquet row group.
+const linkOutageParquetBatchSize = 10000
+
+// LinkOutageParquetRow is the Parquet schema for outage exports. Columns
+// mirror the CSV export.
+type LinkOutageParquetRow struct {
+	ID              string  `parquet:"id"`
+	LinkCode        string  `parquet:"link_code"`
+	LinkType        string  `parquet:"link_type"`
+	SideAMetro      string  `parquet:"side_a_metro"`
+	SideZMetro      string  `parquet:"side_z_metro"`
+	Contributor     string  `parquet:"contributor"`
+	OutageType      string  `parquet:"outage_type"`
+	Severity        string  `parquet:"severity"`
+	Details         string  `parquet:"details"`
+	StartedAt       string  `parquet:"started_at"`
+	EndedAt         *string `parquet:"ended_at,optional"`
+	DurationSeconds *int64  `parquet:"duration_seconds,optional"`
+	IsOngoing       bool    `parquet:"is_ongoing"`
+}
+
+// GetLinkOutagesParquet returns outages as a Parquet file for data pipelines.
+// It accepts the same query parameters as GetLinkOutagesCSV.
+func GetLinkOutagesParquet(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	outages, duration, err := fetchLinkOutagesForExport(ctx, r)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-duration)
+	filename := fmt.Sprintf("link-outages_%s_%s.parquet", start.Format("20060102T150405Z"), end.Format("20060102T150405Z"))
+
+	w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+
+	writer := parquet.NewGenericWriter[LinkOutageParquetRow](w)
+	batch := make([]LinkOutageParquetRow, 0, linkOutageParquetBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := writer.Write(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return writer.Flush()
+	}
+
+	for _, o := range outages {
+		batch = append(batch, LinkOutageParquetRow{
+			ID:              o.ID,
+			LinkCode:        o.LinkCode,
+			LinkType:        o.LinkType,
+			SideAMetro:      o.SideAMetro,
+			SideZMetro:      o.SideZMetro,
+			Contributor:     o.ContributorCode,
+			OutageType:      o.OutageType,
+			Severity:        o.Severity,
+			Details:         linkOutageDetails(o),
+			StartedAt:       o.StartedAt,
+			EndedAt:         o.EndedAt,
+			DurationSeconds: o.DurationSeconds,
+			IsOngoing:       o.IsOngoing,
+		})
+		if len(batch) == linkOutageParquetBatchSize {
+			if err := flush(); err != nil {
+				LoggerFromContext(ctx).Error("Parquet export write error", "error", err)
+				return
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		LoggerFromContext(ctx).Error("Parquet export write error", "error", err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		LoggerFromContext(ctx).Error("Parquet export close error", "error", err)
+	}
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -1550,7 +1646,7 @@ func fetchDefaultOutagesData(ctx context.Context) *LinkOutagesResponse {
 	// Fetch status-based outages (drained states)
 	statusOutages, err := fetchStatusOutages(ctx, envDB(ctx), duration, filters)
 	if err != nil {
-		log.Printf("Cache: Failed to fetch status outages: %v", err)
+		LoggerFromContext(ctx).Error("Cache: Failed to fetch status outages", "error", err)
 	} else {
 		outages = append(outages, statusOutages...)
 	}
@@ -1558,7 +1654,7 @@ func fetchDefaultOutagesData(ctx context.Context) *LinkOutagesResponse {
 	// Fetch packet loss outages
 	lossOutages, err := fetchPacketLossOutages(ctx, envDB(ctx), duration, threshold, filters)
 	if err != nil {
-		log.Printf("Cache: Failed to fetch packet loss outages: %v", err)
+		LoggerFromContext(ctx).Error("Cache: Failed to fetch packet loss outages", "error", err)
 	} else {
 		outages = append(outages, lossOutages...)
 	}
@@ -1566,7 +1662,7 @@ func fetchDefaultOutagesData(ctx context.Context) *LinkOutagesResponse {
 	// Fetch no-data outages
 	noDataOutages, err := fetchNoDataOutages(ctx, envDB(ctx), duration, filters)
 	if err != nil {
-		log.Printf("Cache: Failed to fetch no-data outages: %v", err)
+		LoggerFromContext(ctx).Error("Cache: Failed to fetch no-data outages", "error", err)
 	} else {
 		outages = append(outages, noDataOutages...)
 	}
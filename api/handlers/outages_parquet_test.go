@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedLinkStatusOutages inserts link history with drain transitions:
+// link-1 was soft-drained for an hour and recovered, link-2 is still drained.
+func seedLinkStatusOutages(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now() - INTERVAL 3 HOUR, now(), generateUUIDv4(), 0, 1, 'link-1', 'activated', 'LINK-1', '', '', '', '', '', '', 'WAN', 0, 0, 0, 0),
+		('link-1', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 2, 'link-1', 'soft-drained', 'LINK-1', '', '', '', '', '', '', 'WAN', 0, 0, 0, 0),
+		('link-1', now() - INTERVAL 1 HOUR, now(), generateUUIDv4(), 0, 3, 'link-1', 'activated', 'LINK-1', '', '', '', '', '', '', 'WAN', 0, 0, 0, 0),
+		('link-2', now() - INTERVAL 3 HOUR, now(), generateUUIDv4(), 0, 4, 'link-2', 'activated', 'LINK-2', '', '', '', '', '', '', 'PNI', 0, 0, 0, 0),
+		('link-2', now() - INTERVAL 30 MINUTE, now(), generateUUIDv4(), 0, 5, 'link-2', 'hard-drained', 'LINK-2', '', '', '', '', '', '', 'PNI', 0, 0, 0, 0)`))
+}
+
+func TestGetLinkOutagesParquet_MatchesCSV(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedLinkStatusOutages(t)
+
+	query := "?range=24h&type=status"
+
+	csvReq := httptest.NewRequest(http.MethodGet, "/api/outages/links/csv"+query, nil)
+	csvRec := httptest.NewRecorder()
+	handlers.GetLinkOutagesCSV(csvRec, csvReq)
+	require.Equal(t, http.StatusOK, csvRec.Code, csvRec.Body.String())
+
+	csvRows, err := csv.NewReader(csvRec.Body).ReadAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, csvRows)
+	header, records := csvRows[0], csvRows[1:]
+	require.NotEmpty(t, records)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/outages/links/parquet"+query, nil)
+	rec := httptest.NewRecorder()
+	handlers.GetLinkOutagesParquet(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/vnd.apache.parquet", rec.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename=link-outages_\d{8}T\d{6}Z_\d{8}T\d{6}Z\.parquet$`, rec.Header().Get("Content-Disposition"))
+
+	data := rec.Body.Bytes()
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var columns []string
+	for _, field := range file.Schema().Fields() {
+		columns = append(columns, field.Name())
+	}
+	assert.Equal(t, header, columns)
+	assert.Equal(t, int64(len(records)), file.NumRows())
+
+	rows, err := parquet.Read[handlers.LinkOutageParquetRow](bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, rows, len(records))
+	for i, row := range rows {
+		assert.Equal(t, records[i][0], row.ID)
+		assert.Equal(t, records[i][1], row.LinkCode)
+		assert.Equal(t, records[i][8], row.Details)
+		assert.Equal(t, records[i][12] == "true", row.IsOngoing)
+	}
+}
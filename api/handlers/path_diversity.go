@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// PathDiversityDevice is a device whose failure reduces path diversity
+type PathDiversityDevice struct {
+	PK      string `json:"pk"`
+	Code    string `json:"code"`
+	MetroPK string `json:"metroPK,omitempty"`
+}
+
+// PathDiversityLink is an adjacency whose failure reduces path diversity
+type PathDiversityLink struct {
+	SourcePK   string `json:"sourcePK"`
+	SourceCode string `json:"sourceCode"`
+	TargetPK   string `json:"targetPK"`
+	TargetCode string `json:"targetCode"`
+}
+
+// PathDiversityResponse is the response for the path diversity endpoint
+type PathDiversityResponse struct {
+	FromMetroPK       string                `json:"fromMetroPK"`
+	ToMetroPK         string                `json:"toMetroPK"`
+	NodeDisjointPaths int                   `json:"nodeDisjointPaths"`
+	EdgeDisjointPaths int                   `json:"edgeDisjointPaths"`
+	BottleneckNodes   []PathDiversityDevice `json:"bottleneckNodes"`
+	BottleneckLinks   []PathDiversityLink   `json:"bottleneckLinks"`
+	Error             string                `json:"error,omitempty"`
+}
+
+// GetPathDiversity returns the number of node-disjoint and edge-disjoint ISIS
+// paths between the devices of two metros, along with the devices and links
+// whose removal would reduce those counts.
+func GetPathDiversity(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	fromMetro := r.URL.Query().Get("from")
+	toMetro := r.URL.Query().Get("to")
+
+	response := PathDiversityResponse{
+		FromMetroPK:     fromMetro,
+		ToMetroPK:       toMetro,
+		BottleneckNodes: []PathDiversityDevice{},
+		BottleneckLinks: []PathDiversityLink{},
+	}
+
+	if fromMetro == "" || toMetro == "" {
+		response.Error = "from and to parameters are required"
+		writeJSON(w, response)
+		return
+	}
+	if fromMetro == toMetro {
+		response.Error = "from and to must be different metros"
+		writeJSON(w, response)
+		return
+	}
+
+	start := time.Now()
+
+	g, err := loadISISGraph(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Path diversity graph query error", "error", err)
+		metrics.RecordNeo4jQuery("path_diversity", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+
+	var src, dst []int
+	for i, node := range g.nodes {
+		switch node.MetroPK {
+		case fromMetro:
+			src = append(src, i)
+		case toMetro:
+			dst = append(dst, i)
+		}
+	}
+
+	response.NodeDisjointPaths = nodeDisjointPaths(g.adj, src, dst, -1)
+	response.EdgeDisjointPaths = edgeDisjointPaths(g.adj, src, dst, -1, -1)
+
+	// A device or link is a bottleneck if taking it out lowers the count
+	if response.NodeDisjointPaths > 0 {
+		for v, node := range g.nodes {
+			if ctx.Err() != nil {
+				response.Error = "path diversity computation timed out"
+				writeJSON(w, response)
+				return
+			}
+			if nodeDisjointPaths(g.adj, src, dst, v) < response.NodeDisjointPaths {
+				response.BottleneckNodes = append(response.BottleneckNodes, PathDiversityDevice{
+					PK:      node.PK,
+					Code:    node.Code,
+					MetroPK: node.MetroPK,
+				})
+			}
+		}
+	}
+	if response.EdgeDisjointPaths > 0 {
+		for a := range g.adj {
+			for _, e := range g.adj[a] {
+				if a > e.to {
+					continue
+				}
+				if ctx.Err() != nil {
+					response.Error = "path diversity computation timed out"
+					writeJSON(w, response)
+					return
+				}
+				if edgeDisjointPaths(g.adj, src, dst, a, e.to) < response.EdgeDisjointPaths {
+					response.BottleneckLinks = append(response.BottleneckLinks, PathDiversityLink{
+						SourcePK:   g.nodes[a].PK,
+						SourceCode: g.nodes[a].Code,
+						TargetPK:   g.nodes[e.to].PK,
+						TargetCode: g.nodes[e.to].Code,
+					})
+				}
+			}
+		}
+	}
+
+	duration := time.Since(start)
+	metrics.RecordNeo4jQuery("path_diversity", duration, nil)
+
+	LoggerFromContext(ctx).Info("Path diversity completed",
+		"from_metro", fromMetro, "to_metro", toMetro, "node_disjoint_paths", response.NodeDisjointPaths,
+		"edge_disjoint_paths", response.EdgeDisjointPaths, "duration_ms", duration.Milliseconds())
+
+	writeJSON(w, response)
+}
+
+// nodeDisjointPaths returns the maximum number of paths from any device in src
+// to any device in dst that share no intermediate device, ignoring the removed
+// node (-1 for none). Each metro is treated as a single endpoint, so devices in
+// src and dst aren't capacity limited. Every other device is split into an in
+// and out vertex joined by a unit-capacity arc.
+func nodeDisjointPaths(adj [][]isisGraphEdge, src, dst []int, removed int) int {
+	n := len(adj)
+	in := func(v int) int { return 2 * v }
+	out := func(v int) int { return 2*v + 1 }
+	s, t := 2*n, 2*n+1
+
+	endpoint := make([]bool, n)
+	for _, v := range src {
+		endpoint[v] = true
+	}
+	for _, v := range dst {
+		endpoint[v] = true
+	}
+
+	f := newFlowNetwork(2*n + 2)
+	for v := range n {
+		if v == removed {
+			continue
+		}
+		if endpoint[v] {
+			f.addEdge(in(v), out(v), n)
+		} else {
+			f.addEdge(in(v), out(v), 1)
+		}
+		for _, e := range adj[v] {
+			if e.to != removed {
+				f.addEdge(out(v), in(e.to), 1)
+			}
+		}
+	}
+	for _, v := range src {
+		if v != removed {
+			f.addEdge(s, in(v), n)
+		}
+	}
+	for _, v := range dst {
+		if v != removed {
+			f.addEdge(out(v), t, n)
+		}
+	}
+	return f.maxFlow(s, t)
+}
+
+// edgeDisjointPaths returns the maximum number of paths from any device in src
+// to any device in dst that share no adjacency, ignoring the adjacency between
+// removedA and removedB (-1 for none).
+func edgeDisjointPaths(adj [][]isisGraphEdge, src, dst []int, removedA, removedB int) int {
+	n := len(adj)
+	s, t := n, n+1
+
+	f := newFlowNetwork(n + 2)
+	for v := range n {
+		for _, e := range adj[v] {
+			if (v == removedA && e.to == removedB) || (v == removedB && e.to == removedA) {
+				continue
+			}
+			f.addEdge(v, e.to, 1)
+		}
+	}
+	for _, v := range src {
+		f.addEdge(s, v, n)
+	}
+	for _, v := range dst {
+		f.addEdge(v, t, n)
+	}
+	return f.maxFlow(s, t)
+}
+
+type flowEdge struct {
+	to  int
+	rev int // index of the reverse edge in the residual graph
+	cap int
+}
+
+// flowNetwork is a residual graph for computing max flow
+type flowNetwork [][]flowEdge
+
+func newFlowNetwork(n int) flowNetwork {
+	return make(flowNetwork, n)
+}
+
+func (f flowNetwork) addEdge(u, v, capacity int) {
+	f[u] = append(f[u], flowEdge{to: v, rev: len(f[v]), cap: capacity})
+	f[v] = append(f[v], flowEdge{to: u, rev: len(f[u]) - 1, cap: 0})
+}
+
+// maxFlow computes the max flow from s to t with Edmonds-Karp
+func (f flowNetwork) maxFlow(s, t int) int {
+	flow := 0
+	prevNode := make([]int, len(f))
+	prevEdge := make([]int, len(f))
+	for {
+		for i := range prevNode {
+			prevNode[i] = -1
+		}
+		prevNode[s] = s
+		queue := []int{s}
+		for len(queue) > 0 && prevNode[t] < 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for i, e := range f[u] {
+				if e.cap > 0 && prevNode[e.to] < 0 {
+					prevNode[e.to] = u
+					prevEdge[e.to] = i
+					queue = append(queue, e.to)
+				}
+			}
+		}
+		if prevNode[t] < 0 {
+			return flow
+		}
+
+		// Find the bottleneck capacity along the path, then push it
+		push := -1
+		for v := t; v != s; v = prevNode[v] {
+			c := f[prevNode[v]][prevEdge[v]].cap
+			if push < 0 || c < push {
+				push = c
+			}
+		}
+		for v := t; v != s; v = prevNode[v] {
+			e := &f[prevNode[v]][prevEdge[v]]
+			e.cap -= push
+			f[v][e.rev].cap += push
+		}
+		flow += push
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import "testing"
+
+func TestDisjointPaths_Line(t *testing.T) {
+	// 0 - 1 - 2 - 3
+	adj := undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}})
+
+	if got := nodeDisjointPaths(adj, []int{0}, []int{3}, -1); got != 1 {
+		t.Errorf("expected 1 node-disjoint path, got %d", got)
+	}
+	if got := edgeDisjointPaths(adj, []int{0}, []int{3}, -1, -1); got != 1 {
+		t.Errorf("expected 1 edge-disjoint path, got %d", got)
+	}
+	if got := nodeDisjointPaths(adj, []int{0}, []int{3}, 2); got != 0 {
+		t.Errorf("expected 0 paths with node 2 removed, got %d", got)
+	}
+	if got := edgeDisjointPaths(adj, []int{0}, []int{3}, 1, 2); got != 0 {
+		t.Errorf("expected 0 paths with edge 1-2 removed, got %d", got)
+	}
+}
+
+func TestDisjointPaths_Ring(t *testing.T) {
+	// 0 - 1 - 2 - 3 - 0: two routes between 0 and 2
+	adj := undirectedGraph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}})
+
+	if got := nodeDisjointPaths(adj, []int{0}, []int{2}, -1); got != 2 {
+		t.Errorf("expected 2 node-disjoint paths, got %d", got)
+	}
+	if got := nodeDisjointPaths(adj, []int{0}, []int{2}, 1); got != 1 {
+		t.Errorf("expected 1 path with node 1 removed, got %d", got)
+	}
+	if got := edgeDisjointPaths(adj, []int{0}, []int{2}, 3, 0); got != 1 {
+		t.Errorf("expected 1 path with edge 3-0 removed, got %d", got)
+	}
+}
+
+func TestDisjointPaths_EdgeButNotNodeDisjoint(t *testing.T) {
+	// Two triangles joined at node 2: 0-1-2 and 2-3-4
+	adj := undirectedGraph(5, [][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 3}, {3, 4}, {4, 2}})
+
+	if got := nodeDisjointPaths(adj, []int{0}, []int{4}, -1); got != 1 {
+		t.Errorf("expected 1 node-disjoint path, got %d", got)
+	}
+	if got := edgeDisjointPaths(adj, []int{0}, []int{4}, -1, -1); got != 2 {
+		t.Errorf("expected 2 edge-disjoint paths, got %d", got)
+	}
+}
+
+func TestDisjointPaths_MultipleSources(t *testing.T) {
+	// Sources 0 and 1 each reach sink 3 through separate nodes
+	adj := undirectedGraph(5, [][2]int{{0, 2}, {2, 3}, {1, 4}, {4, 3}})
+
+	if got := nodeDisjointPaths(adj, []int{0, 1}, []int{3}, -1); got != 2 {
+		t.Errorf("expected 2 node-disjoint paths, got %d", got)
+	}
+	if got := nodeDisjointPaths(adj, []int{0, 1}, []int{3}, 3); got != 0 {
+		t.Errorf("expected 0 paths with the sink removed, got %d", got)
+	}
+	if got := edgeDisjointPaths(adj, []int{0, 1}, []int{3}, -1, -1); got != 2 {
+		t.Errorf("expected 2 edge-disjoint paths, got %d", got)
+	}
+}
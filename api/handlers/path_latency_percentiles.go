@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// pathLatencyPercentilesCacheTTL is how long the network-wide latency
+// distribution is reused before it's recomputed.
+const pathLatencyPercentilesCacheTTL = 5 * time.Minute
+
+// pathLatencyWindow is how far back latency samples are aggregated.
+const pathLatencyWindow = time.Hour
+
+// pathLatencyPercentileLabels names the percentiles returned by
+// GetPathLatencyPercentiles, in the order the query computes them.
+var pathLatencyPercentileLabels = []string{"p5", "p25", "p50", "p75", "p95", "p99"}
+
+// PathLatencyBuckets counts links by median RTT. Each bucket holds links from
+// the previous bucket's bound up to (but excluding) its own.
+type PathLatencyBuckets struct {
+	Under1ms  uint64 `json:"under1ms"`
+	Under5ms  uint64 `json:"under5ms"`
+	Under10ms uint64 `json:"under10ms"`
+	Under50ms uint64 `json:"under50ms"`
+	Over50ms  uint64 `json:"over50ms"` // 50ms or more
+}
+
+// PathLatencyPercentilesResponse is the distribution of link RTT across the network
+type PathLatencyPercentilesResponse struct {
+	WindowStart string             `json:"windowStart"`
+	WindowEnd   string             `json:"windowEnd"`
+	LinkCount   uint64             `json:"linkCount"`
+	P5RttNs     float64            `json:"p5RttNs"`
+	P25RttNs    float64            `json:"p25RttNs"`
+	P50RttNs    float64            `json:"p50RttNs"`
+	P75RttNs    float64            `json:"p75RttNs"`
+	P95RttNs    float64            `json:"p95RttNs"`
+	P99RttNs    float64            `json:"p99RttNs"`
+	Buckets     PathLatencyBuckets `json:"buckets"`
+}
+
+type pathLatencyPercentilesCacheEntry struct {
+	response  PathLatencyPercentilesResponse
+	fetchedAt time.Time
+}
+
+var (
+	pathLatencyPercentilesCache   = make(map[DZEnv]pathLatencyPercentilesCacheEntry)
+	pathLatencyPercentilesCacheMu sync.RWMutex
+)
+
+// GetPathLatencyPercentiles returns the distribution of measured RTT across
+// all activated links over the last hour. Each link contributes its median
+// RTT, so busy links don't outweigh quiet ones; lost samples are ignored. The
+// result is cached per environment for 5 minutes and exported as the
+// doublezero_lake_api_path_latency_percentile_seconds gauge.
+func GetPathLatencyPercentiles(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	env := EnvFromContext(ctx)
+	pathLatencyPercentilesCacheMu.RLock()
+	entry, ok := pathLatencyPercentilesCache[env]
+	pathLatencyPercentilesCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < pathLatencyPercentilesCacheTTL {
+		w.Header().Set("X-Cache", "HIT")
+		writeJSON(w, entry.response)
+		return
+	}
+
+	start := time.Now()
+	windowEnd := start.UTC().Truncate(time.Second)
+	windowStart := windowEnd.Add(-pathLatencyWindow)
+	response := PathLatencyPercentilesResponse{
+		WindowStart: windowStart.Format(time.RFC3339),
+		WindowEnd:   windowEnd.Format(time.RFC3339),
+	}
+
+	var percentilesUs []float64
+	b := &response.Buckets
+	err := envDB(ctx).QueryRow(ctx, `
+		WITH link_rtt AS (
+			SELECT link_pk, quantileIf(0.5)(rtt_us, NOT loss) AS median_rtt_us
+			FROM fact_dz_device_link_latency
+			WHERE event_ts >= ? AND event_ts < ?
+			  AND link_pk IN (SELECT pk FROM dz_links_current WHERE status = 'activated')
+			GROUP BY link_pk
+			HAVING countIf(NOT loss) > 0
+		)
+		SELECT
+			count() AS link_count,
+			quantiles(0.05, 0.25, 0.5, 0.75, 0.95, 0.99)(median_rtt_us) AS percentiles_us,
+			countIf(median_rtt_us < 1000),
+			countIf(median_rtt_us >= 1000 AND median_rtt_us < 5000),
+			countIf(median_rtt_us >= 5000 AND median_rtt_us < 10000),
+			countIf(median_rtt_us >= 10000 AND median_rtt_us < 50000),
+			countIf(median_rtt_us >= 50000)
+		FROM link_rtt
+	`, windowStart, windowEnd).Scan(
+		&response.LinkCount, &percentilesUs,
+		&b.Under1ms, &b.Under5ms, &b.Under10ms, &b.Under50ms, &b.Over50ms,
+	)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Path latency percentiles query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	// quantiles over no links is all NaN, which JSON can't carry; report zeros
+	percentilesNs := make([]float64, len(pathLatencyPercentileLabels))
+	for i := range percentilesNs {
+		if i < len(percentilesUs) && !math.IsNaN(percentilesUs[i]) {
+			percentilesNs[i] = percentilesUs[i] * 1000
+		}
+	}
+	response.P5RttNs = percentilesNs[0]
+	response.P25RttNs = percentilesNs[1]
+	response.P50RttNs = percentilesNs[2]
+	response.P75RttNs = percentilesNs[3]
+	response.P95RttNs = percentilesNs[4]
+	response.P99RttNs = percentilesNs[5]
+
+	if response.LinkCount > 0 {
+		for i, label := range pathLatencyPercentileLabels {
+			metrics.PathLatencyPercentileSeconds.WithLabelValues(string(env), label).Set(percentilesNs[i] / 1e9)
+		}
+	}
+
+	pathLatencyPercentilesCacheMu.Lock()
+	pathLatencyPercentilesCache[env] = pathLatencyPercentilesCacheEntry{response: response, fetchedAt: time.Now()}
+	pathLatencyPercentilesCacheMu.Unlock()
+
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, response)
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedPathLatency inserts activated links at 0.5ms, 3ms, 7ms, 20ms and 80ms
+// median RTT, a drained link and a link with only lost samples.
+func seedPathLatency(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		SELECT pk, now(), now(), generateUUIDv4(), 0, 1, pk, status, upper(pk), '', '', 'dev-a', 'dev-z',
+		       '', '', 'WAN', 0, 0, 0, 0
+		FROM values('pk String, status String',
+			('pl-1', 'activated'), ('pl-2', 'activated'), ('pl-3', 'activated'), ('pl-4', 'activated'),
+			('pl-5', 'activated'), ('pl-drained', 'soft-drained'), ('pl-lost', 'activated'))`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT now() - INTERVAL 30 MINUTE + INTERVAL 1 SECOND * number, now(), 1, number, 'dev-a', 'dev-z',
+		       link_pk, if(link_pk = 'pl-lost', 0, rtt_us), link_pk = 'pl-lost', 0
+		FROM values('link_pk String, rtt_us Int64',
+			('pl-1', 500), ('pl-2', 3000), ('pl-3', 7000), ('pl-4', 20000), ('pl-5', 80000),
+			('pl-drained', 900000), ('pl-lost', 0))
+		CROSS JOIN numbers(10)`))
+
+	// Samples older than the hour window are ignored
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT now() - INTERVAL 2 HOUR, now(), 0, number, 'dev-a', 'dev-z', 'pl-1', 900000, false, 0
+		FROM numbers(100)`))
+}
+
+func TestGetPathLatencyPercentiles(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedPathLatency(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/path-latency-percentiles", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetPathLatencyPercentiles(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+
+	var resp handlers.PathLatencyPercentilesResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, uint64(5), resp.LinkCount)
+	assert.InDelta(t, 7_000_000, resp.P50RttNs, 1)
+	assert.Less(t, resp.P5RttNs, resp.P50RttNs)
+	assert.Greater(t, resp.P99RttNs, resp.P95RttNs)
+	assert.LessOrEqual(t, resp.P99RttNs, 80_000_000.0)
+	assert.Equal(t, handlers.PathLatencyBuckets{
+		Under1ms:  1,
+		Under5ms:  1,
+		Under10ms: 1,
+		Under50ms: 1,
+		Over50ms:  1,
+	}, resp.Buckets)
+
+	// A second request within the TTL is served from cache
+	rr = httptest.NewRecorder()
+	handlers.GetPathLatencyPercentiles(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "HIT", rr.Header().Get("X-Cache"))
+}
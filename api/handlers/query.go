@@ -3,14 +3,20 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"math"
 	"net"
 	"net/http"
+	"os"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/malbeclabs/lake/api/config"
 	"github.com/malbeclabs/lake/api/metrics"
 )
@@ -81,26 +87,71 @@ type QueryRequest struct {
 }
 
 type QueryResponse struct {
-	Columns   []string `json:"columns"`
-	Rows      [][]any  `json:"rows"`
-	RowCount  int      `json:"row_count"`
-	ElapsedMs int64    `json:"elapsed_ms"`
-	Error     string   `json:"error,omitempty"`
+	Columns   []string   `json:"columns"`
+	Rows      [][]any    `json:"rows"`
+	RowCount  int        `json:"row_count"`
+	ElapsedMs int64      `json:"elapsed_ms"`
+	Truncated bool       `json:"truncated,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Diff      *QueryDiff `json:"diff,omitempty"`
+	DiffError string     `json:"diff_error,omitempty"`
 }
 
+// defaultMaxQueryRows is the row cap for ExecuteQuery when MAX_QUERY_ROWS is unset.
+const defaultMaxQueryRows = 10000
+
+// queryFlushInterval is how many rows ExecuteQuery writes between flushes.
+const queryFlushInterval = 100
+
+// getMaxQueryRows returns the maximum rows ExecuteQuery returns, from MAX_QUERY_ROWS
+func getMaxQueryRows() int {
+	val := os.Getenv("MAX_QUERY_ROWS")
+	if val == "" {
+		return defaultMaxQueryRows
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n <= 0 {
+		slog.Warn("Invalid MAX_QUERY_ROWS, using default", "value", val)
+		return defaultMaxQueryRows
+	}
+	return n
+}
+
+// ExecuteQuery runs a SQL query and streams the result as a QueryResponse.
+// The columns are written first, then rows are encoded one at a time and
+// flushed every queryFlushInterval rows so clients can render progressively.
+// Results are capped at MAX_QUERY_ROWS rows, with truncated set when the cap is
+// hit. An error after streaming has started is reported in the error field.
+//
+// With ?diff_session_id=<id>&diff_key=<column>, the result is compared with
+// the previous one stored in that query session, matching rows on diff_key,
+// and the diff is returned in the diff field. The new result then replaces
+// the stored one. Truncated or failed results are neither diffed nor stored;
+// diff_error says why. ?show_diff_only=true leaves the rows array empty.
 func ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 	var req QueryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Query) == "" {
-		http.Error(w, "Query is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Query is required")
 		return
 	}
 
-	start := time.Now()
+	diffSessionParam := r.URL.Query().Get("diff_session_id")
+	diffKey := r.URL.Query().Get("diff_key")
+	showDiffOnly := r.URL.Query().Get("show_diff_only") == "true"
+	if (diffSessionParam == "") != (diffKey == "") {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "diff_session_id and diff_key must be given together")
+		return
+	}
+	diffing := diffKey != ""
+	if showDiffOnly && !diffing {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "show_diff_only requires diff_session_id and diff_key")
+		return
+	}
 
 	query := strings.TrimSpace(req.Query)
 	query = strings.TrimSuffix(query, ";")
@@ -108,6 +159,29 @@ func ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
 	defer cancel()
 
+	var diffSessionID uuid.UUID
+	var previous *storedQueryResult
+	if diffing {
+		var err error
+		diffSessionID, err = uuid.Parse(diffSessionParam)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid diff_session_id")
+			return
+		}
+		previous, err = loadSessionQueryResult(ctx, r, diffSessionID)
+		if errors.Is(err, errQuerySessionNotFound) {
+			writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
+			return
+		}
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to load previous query result", err))
+			return
+		}
+	}
+
+	start := time.Now()
+	recordAccountUsage(ctx, UsageRecord{QueryCount: 1})
+
 	// Agent queries always run against the mainnet database. To query other
 	// environments, use fully-qualified table names (e.g., lake_devnet.dim_devices_current).
 	rows, err := config.DB.Query(ctx, query)
@@ -129,10 +203,33 @@ func ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 	for i, ct := range columnTypes {
 		columns[i] = ct.Name()
 	}
+	if diffing && !slices.Contains(columns, diffKey) {
+		metrics.RecordClickHouseQuery(duration, nil)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("diff_key column %q is not in the result", diffKey))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	columnsJSON, _ := json.Marshal(columns)
+	_, _ = w.Write([]byte(`{"columns":`))
+	_, _ = w.Write(columnsJSON)
+	_, _ = w.Write([]byte(`,"rows":[`))
 
-	// Collect all rows
+	maxRows := getMaxQueryRows()
+	rowCount := 0
+	truncated := false
+	var streamErr error
 	var resultRows [][]any
 	for rows.Next() {
+		if rowCount >= maxRows {
+			truncated = true
+			break
+		}
+
 		// Create properly typed values based on column types
 		values := make([]any, len(columnTypes))
 		for i, ct := range columnTypes {
@@ -140,53 +237,93 @@ func ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if err := rows.Scan(values...); err != nil {
-			metrics.RecordClickHouseQuery(duration, err)
-			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(QueryResponse{
-				Error:     err.Error(),
-				ElapsedMs: duration.Milliseconds(),
-			})
-			return
+			streamErr = err
+			break
 		}
 
-		// Dereference pointers
+		// Dereference pointers and convert to JSON-safe values
 		row := make([]any, len(values))
 		for i, v := range values {
-			row[i] = reflect.ValueOf(v).Elem().Interface()
+			row[i] = toJSONSafe(reflect.ValueOf(v).Elem().Interface())
+		}
+
+		if diffing {
+			resultRows = append(resultRows, row)
+		}
+		if !showDiffOnly {
+			if rowCount > 0 {
+				_, _ = w.Write([]byte(","))
+			}
+			if err := enc.Encode(row); err != nil {
+				// Client went away; the response can't be completed
+				LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
+				metrics.RecordClickHouseQuery(duration, err)
+				return
+			}
+		}
+		rowCount++
+
+		if flusher != nil && !showDiffOnly && rowCount%queryFlushInterval == 0 {
+			flusher.Flush()
 		}
-		resultRows = append(resultRows, row)
 	}
+	if streamErr == nil && !truncated {
+		streamErr = rows.Err()
+	}
+	metrics.RecordClickHouseQuery(duration, streamErr)
 
-	if err := rows.Err(); err != nil {
-		metrics.RecordClickHouseQuery(duration, err)
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(QueryResponse{
-			Error:     err.Error(),
-			ElapsedMs: duration.Milliseconds(),
-		})
-		return
+	// Close the rows array and write the trailing fields
+	trailer := struct {
+		RowCount  int        `json:"row_count"`
+		ElapsedMs int64      `json:"elapsed_ms"`
+		Truncated bool       `json:"truncated,omitempty"`
+		Error     string     `json:"error,omitempty"`
+		Diff      *QueryDiff `json:"diff,omitempty"`
+		DiffError string     `json:"diff_error,omitempty"`
+	}{
+		RowCount:  rowCount,
+		ElapsedMs: duration.Milliseconds(),
+		Truncated: truncated,
 	}
+	if streamErr != nil {
+		trailer.Error = streamErr.Error()
+	}
+	if diffing {
+		trailer.Diff, trailer.DiffError = diffAndStoreQueryResult(ctx, diffSessionID, previous,
+			storedQueryResult{DiffKey: diffKey, Columns: columns, Rows: resultRows}, truncated, streamErr)
+	}
+	trailerJSON, _ := json.Marshal(trailer)
+	_, _ = w.Write([]byte("],"))
+	_, _ = w.Write(trailerJSON[1:]) // drop the opening brace
+	_, _ = w.Write([]byte("\n"))
+}
 
-	metrics.RecordClickHouseQuery(duration, nil)
+// diffAndStoreQueryResult diffs a complete result against the previous one
+// and stores it for the next run. There is no diff on the first run.
+func diffAndStoreQueryResult(ctx context.Context, sessionID uuid.UUID, previous *storedQueryResult, cur storedQueryResult, truncated bool, streamErr error) (*QueryDiff, string) {
+	switch {
+	case streamErr != nil:
+		return nil, "query failed; result not diffed"
+	case truncated:
+		return nil, "result truncated; only complete results are diffed"
+	}
 
-	// Convert rows to JSON-safe values
-	safeRows := make([][]any, len(resultRows))
-	for i, row := range resultRows {
-		safeRow := make([]any, len(row))
-		for j, v := range row {
-			safeRow[j] = toJSONSafe(v)
-		}
-		safeRows[i] = safeRow
+	// A result whose keys repeat can't be diffed now or next time
+	if _, _, err := keyQueryRows(cur, cur.DiffKey); err != nil {
+		return nil, err.Error()
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(QueryResponse{
-		Columns:   columns,
-		Rows:      safeRows,
-		RowCount:  len(safeRows),
-		ElapsedMs: duration.Milliseconds(),
-	}); err != nil {
-		// Log encoding error - response is already partially written
-		log.Printf("JSON encoding error: %v", err)
+	var diff *QueryDiff
+	var diffErr string
+	if previous != nil {
+		var err error
+		if diff, err = diffQueryResults(*previous, cur, cur.DiffKey); err != nil {
+			diffErr = err.Error()
+		}
+	}
+	if err := saveSessionQueryResult(ctx, sessionID, cur); err != nil {
+		LoggerFromContext(ctx).Error("Failed to store query result for diffing", "error", err)
+		return diff, "failed to store result for the next diff"
 	}
+	return diff, diffErr
 }
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// errQuerySessionNotFound is returned when the diff session doesn't exist or
+// isn't the caller's
+var errQuerySessionNotFound = errors.New("session not found")
+
+// QueryDiff is how a query result changed since the previous run in the same
+// session. Rows are objects keyed by column name, since the columns of the two
+// runs may differ.
+type QueryDiff struct {
+	Added   []map[string]any `json:"added"`
+	Removed []map[string]any `json:"removed"`
+	Changed []QueryRowChange `json:"changed"`
+}
+
+// QueryRowChange is a row whose diff key is in both results but whose values differ
+type QueryRowChange struct {
+	Key            any            `json:"key"`
+	OldRow         map[string]any `json:"oldRow"`
+	NewRow         map[string]any `json:"newRow"`
+	ChangedColumns []string       `json:"changedColumns"`
+}
+
+// storedQueryResult is the result kept for diffing against the next run
+type storedQueryResult struct {
+	DiffKey string
+	Columns []string
+	Rows    [][]any
+}
+
+// loadSessionQueryResult checks the caller owns the session and returns the
+// result stored by its previous diffed query, or nil if there is none yet.
+// Anonymous callers pass anonymous_id as a query parameter, as for GetSession.
+func loadSessionQueryResult(ctx context.Context, r *http.Request, sessionID uuid.UUID) (*storedQueryResult, error) {
+	var ownerFilter string
+	var owner any
+	if account := GetAccountFromContext(ctx); account != nil {
+		ownerFilter, owner = "s.account_id = $2", account.ID
+	} else if anonymousID := r.URL.Query().Get("anonymous_id"); anonymousID != "" {
+		ownerFilter, owner = "s.anonymous_id = $2", anonymousID
+	} else {
+		return nil, errQuerySessionNotFound
+	}
+
+	var diffKey *string
+	var columnsJSON, rowsJSON []byte
+	err := config.PgPool.QueryRow(ctx, `
+		SELECT q.diff_key, q.columns, q.rows
+		FROM sessions s
+		LEFT JOIN session_query_results q ON q.session_id = s.id
+		WHERE s.id = $1 AND `+ownerFilter, sessionID, owner).Scan(&diffKey, &columnsJSON, &rowsJSON)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errQuerySessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if diffKey == nil {
+		return nil, nil
+	}
+
+	result := &storedQueryResult{DiffKey: *diffKey}
+	if err := json.Unmarshal(columnsJSON, &result.Columns); err != nil {
+		return nil, fmt.Errorf("decode stored columns: %w", err)
+	}
+	// Keep numbers as written so large integers compare exactly
+	dec := json.NewDecoder(bytes.NewReader(rowsJSON))
+	dec.UseNumber()
+	if err := dec.Decode(&result.Rows); err != nil {
+		return nil, fmt.Errorf("decode stored rows: %w", err)
+	}
+	return result, nil
+}
+
+// saveSessionQueryResult replaces the session's stored result
+func saveSessionQueryResult(ctx context.Context, sessionID uuid.UUID, result storedQueryResult) error {
+	columnsJSON, err := json.Marshal(result.Columns)
+	if err != nil {
+		return err
+	}
+	rowsJSON, err := json.Marshal(result.Rows)
+	if err != nil {
+		return err
+	}
+	_, err = config.PgPool.Exec(ctx, `
+		INSERT INTO session_query_results (session_id, diff_key, columns, rows)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (session_id) DO UPDATE
+		SET diff_key = EXCLUDED.diff_key, columns = EXCLUDED.columns, rows = EXCLUDED.rows, created_at = NOW()
+	`, sessionID, result.DiffKey, columnsJSON, rowsJSON)
+	return err
+}
+
+// diffQueryResults compares two results row by row, matching rows on the
+// value of the key column. Values are compared by their JSON encoding, so a
+// stored result compares equal to the same values freshly scanned. Added and
+// changed rows are in new result order, removed rows in old result order.
+func diffQueryResults(old, cur storedQueryResult, key string) (*QueryDiff, error) {
+	oldRows, oldKeys, err := keyQueryRows(old, key)
+	if err != nil {
+		return nil, fmt.Errorf("previous result: %w", err)
+	}
+	curRows, curKeys, err := keyQueryRows(cur, key)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &QueryDiff{
+		Added:   []map[string]any{},
+		Removed: []map[string]any{},
+		Changed: []QueryRowChange{},
+	}
+	oldByKey := make(map[string]map[string]any, len(oldRows))
+	for i, row := range oldRows {
+		oldByKey[oldKeys[i]] = row
+	}
+	curKeySet := make(map[string]bool, len(curRows))
+	for i, row := range curRows {
+		curKeySet[curKeys[i]] = true
+		oldRow, ok := oldByKey[curKeys[i]]
+		if !ok {
+			diff.Added = append(diff.Added, row)
+			continue
+		}
+		if changed := changedQueryColumns(old.Columns, cur.Columns, oldRow, row); len(changed) > 0 {
+			diff.Changed = append(diff.Changed, QueryRowChange{
+				Key:            row[key],
+				OldRow:         oldRow,
+				NewRow:         row,
+				ChangedColumns: changed,
+			})
+		}
+	}
+	for i, row := range oldRows {
+		if !curKeySet[oldKeys[i]] {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	return diff, nil
+}
+
+// keyQueryRows converts a result's rows to objects and returns each row's
+// encoded key, failing if the key column is missing or a key repeats
+func keyQueryRows(result storedQueryResult, key string) ([]map[string]any, []string, error) {
+	keyIdx := -1
+	for i, c := range result.Columns {
+		if c == key {
+			keyIdx = i
+			break
+		}
+	}
+	if keyIdx < 0 {
+		return nil, nil, fmt.Errorf("no column %q", key)
+	}
+
+	rows := make([]map[string]any, len(result.Rows))
+	keys := make([]string, len(result.Rows))
+	seen := make(map[string]bool, len(result.Rows))
+	for i, values := range result.Rows {
+		row := make(map[string]any, len(result.Columns))
+		for j, c := range result.Columns {
+			if j < len(values) {
+				row[c] = values[j]
+			}
+		}
+		k := queryValueJSON(row[key])
+		if seen[k] {
+			return nil, nil, fmt.Errorf("column %q has duplicate value %s", key, k)
+		}
+		seen[k] = true
+		rows[i] = row
+		keys[i] = k
+	}
+	return rows, keys, nil
+}
+
+// changedQueryColumns lists the columns whose values differ between two rows,
+// in new column order followed by any columns only in the old result
+func changedQueryColumns(oldColumns, curColumns []string, oldRow, curRow map[string]any) []string {
+	var changed []string
+	seen := make(map[string]bool, len(curColumns))
+	for _, c := range curColumns {
+		seen[c] = true
+		oldValue, ok := oldRow[c]
+		if !ok || queryValueJSON(oldValue) != queryValueJSON(curRow[c]) {
+			changed = append(changed, c)
+		}
+	}
+	for _, c := range oldColumns {
+		if !seen[c] {
+			changed = append(changed, c)
+		}
+	}
+	return changed
+}
+
+func queryValueJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiffQueryResults(t *testing.T) {
+	// The previous result comes back from storage with json.Number values
+	old := storedQueryResult{
+		Columns: []string{"id", "status", "rtt"},
+		Rows: [][]any{
+			{json.Number("1"), "up", json.Number("1.5")},
+			{json.Number("2"), "up", json.Number("3")},
+			{json.Number("3"), "down", nil},
+		},
+	}
+	cur := storedQueryResult{
+		Columns: []string{"id", "status", "rtt"},
+		Rows: [][]any{
+			{uint64(4), "up", 2.0},
+			{uint64(2), "down", 3.0},
+			{uint64(1), "up", 1.5},
+		},
+	}
+
+	diff, err := diffQueryResults(old, cur, "id")
+	if err != nil {
+		t.Fatalf("diffQueryResults: %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0]["id"] != uint64(4) {
+		t.Errorf("added = %v, want row 4", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0]["id"] != json.Number("3") {
+		t.Errorf("removed = %v, want row 3", diff.Removed)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("changed = %v, want row 2 only", diff.Changed)
+	}
+	change := diff.Changed[0]
+	if change.Key != uint64(2) || change.OldRow["status"] != "up" || change.NewRow["status"] != "down" {
+		t.Errorf("change = %+v, want row 2 going up to down", change)
+	}
+	if !reflect.DeepEqual(change.ChangedColumns, []string{"status"}) {
+		t.Errorf("changed columns = %v, want [status]", change.ChangedColumns)
+	}
+}
+
+func TestDiffQueryResults_ColumnChanges(t *testing.T) {
+	old := storedQueryResult{Columns: []string{"id", "a", "b"}, Rows: [][]any{{"x", 1, 2}}}
+	cur := storedQueryResult{Columns: []string{"id", "a", "c"}, Rows: [][]any{{"x", 1, 3}}}
+
+	diff, err := diffQueryResults(old, cur, "id")
+	if err != nil {
+		t.Fatalf("diffQueryResults: %v", err)
+	}
+	if len(diff.Changed) != 1 || !reflect.DeepEqual(diff.Changed[0].ChangedColumns, []string{"c", "b"}) {
+		t.Errorf("changed = %+v, want columns [c b]", diff.Changed)
+	}
+}
+
+func TestDiffQueryResults_Errors(t *testing.T) {
+	ok := storedQueryResult{Columns: []string{"id"}, Rows: [][]any{{1}}}
+
+	if _, err := diffQueryResults(storedQueryResult{Columns: []string{"name"}}, ok, "id"); err == nil {
+		t.Error("expected an error when the previous result has no key column")
+	}
+	dup := storedQueryResult{Columns: []string{"id"}, Rows: [][]any{{1}, {1}}}
+	if _, err := diffQueryResults(ok, dup, "id"); err == nil {
+		t.Error("expected an error for duplicate keys")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/malbeclabs/lake/api/config"
 	"github.com/malbeclabs/lake/api/handlers"
 	apitesting "github.com/malbeclabs/lake/api/testing"
@@ -384,3 +385,127 @@ func TestExecuteQuery_EmptyResult(t *testing.T) {
 	assert.Equal(t, 0, response.RowCount)
 	assert.Equal(t, []string{"id"}, response.Columns)
 }
+
+func TestExecuteQuery_StreamsManyRows(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+
+	body, _ := json.Marshal(handlers.QueryRequest{Query: "SELECT number, toString(number) FROM numbers(250)"})
+	req := httptest.NewRequest(http.MethodPost, "/api/query", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handlers.ExecuteQuery(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.QueryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Empty(t, response.Error)
+	assert.False(t, response.Truncated)
+	assert.Equal(t, 250, response.RowCount)
+	require.Len(t, response.Rows, 250)
+	assert.Equal(t, "249", response.Rows[249][1])
+}
+
+func TestExecuteQuery_TruncatesAtMaxRows(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+	t.Setenv("MAX_QUERY_ROWS", "100")
+
+	body, _ := json.Marshal(handlers.QueryRequest{Query: "SELECT number FROM numbers(250)"})
+	req := httptest.NewRequest(http.MethodPost, "/api/query", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handlers.ExecuteQuery(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.QueryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Empty(t, response.Error)
+	assert.True(t, response.Truncated)
+	assert.Equal(t, 100, response.RowCount)
+	assert.Len(t, response.Rows, 100)
+}
+
+func executeDiffQuery(t *testing.T, account *handlers.Account, query, params string) handlers.QueryResponse {
+	t.Helper()
+	body, _ := json.Marshal(handlers.QueryRequest{Query: query})
+	req := httptest.NewRequest(http.MethodPost, "/api/sql/query?"+params, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withAccount(req, account)
+
+	rr := httptest.NewRecorder()
+	handlers.ExecuteQuery(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var response handlers.QueryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Empty(t, response.Error)
+	return response
+}
+
+func TestExecuteQuery_Diff(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	apitesting.SetupTestClickHouse(t, testChDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	sessionID := uuid.New()
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, content, account_id) VALUES ($1, 'query', '[]', $2)
+	`, sessionID, account.ID)
+	require.NoError(t, err)
+	params := "diff_session_id=" + sessionID.String() + "&diff_key=id"
+
+	// The first run is stored with nothing to compare against
+	first := executeDiffQuery(t, account, "SELECT number AS id, 'up' AS status FROM numbers(3)", params)
+	assert.Equal(t, 3, first.RowCount)
+	assert.Len(t, first.Rows, 3)
+	assert.Nil(t, first.Diff)
+	assert.Empty(t, first.DiffError)
+
+	second := executeDiffQuery(t, account,
+		"SELECT number + 1 AS id, if(number = 0, 'down', 'up') AS status FROM numbers(3)",
+		params+"&show_diff_only=true")
+	assert.Equal(t, 3, second.RowCount)
+	assert.Empty(t, second.Rows)
+	require.NotNil(t, second.Diff)
+	require.Len(t, second.Diff.Added, 1)
+	assert.EqualValues(t, 3, second.Diff.Added[0]["id"])
+	require.Len(t, second.Diff.Removed, 1)
+	assert.EqualValues(t, 0, second.Diff.Removed[0]["id"])
+	require.Len(t, second.Diff.Changed, 1)
+	assert.EqualValues(t, 1, second.Diff.Changed[0].Key)
+	assert.Equal(t, "up", second.Diff.Changed[0].OldRow["status"])
+	assert.Equal(t, "down", second.Diff.Changed[0].NewRow["status"])
+	assert.Equal(t, []string{"status"}, second.Diff.Changed[0].ChangedColumns)
+}
+
+func TestExecuteQuery_DiffValidation(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	apitesting.SetupTestClickHouse(t, testChDB)
+	ctx := t.Context()
+
+	owner := createTestAccount(t, ctx)
+	other := createTestAccount(t, ctx)
+	sessionID := uuid.New()
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, content, account_id) VALUES ($1, 'query', '[]', $2)
+	`, sessionID, owner.ID)
+	require.NoError(t, err)
+
+	run := func(account *handlers.Account, params string) int {
+		body, _ := json.Marshal(handlers.QueryRequest{Query: "SELECT 1 AS id"})
+		req := httptest.NewRequest(http.MethodPost, "/api/sql/query?"+params, bytes.NewReader(body))
+		req = withAccount(req, account)
+		rr := httptest.NewRecorder()
+		handlers.ExecuteQuery(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, run(owner, "diff_key=id"))
+	assert.Equal(t, http.StatusBadRequest, run(owner, "diff_session_id="+sessionID.String()))
+	assert.Equal(t, http.StatusBadRequest, run(owner, "show_diff_only=true"))
+	assert.Equal(t, http.StatusBadRequest, run(owner, "diff_session_id=nope&diff_key=id"))
+	assert.Equal(t, http.StatusBadRequest, run(owner, "diff_session_id="+sessionID.String()+"&diff_key=missing"))
+	assert.Equal(t, http.StatusNotFound, run(other, "diff_session_id="+sessionID.String()+"&diff_key=id"))
+}
@@ -3,7 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -12,12 +15,14 @@ import (
 
 // RateLimitError is returned when rate limit is exceeded.
 type RateLimitError struct {
+	Code       string `json:"code"`
 	Error      string `json:"error"`
 	Message    string `json:"message"`
 	RetryAfter int    `json:"retry_after"` // seconds
+	RequestID  string `json:"requestId,omitempty"`
 }
 
-// RateLimiter provides per-IP rate limiting for database queries.
+// RateLimiter provides keyed (per-IP or per-account) rate limiting for database queries.
 type RateLimiter struct {
 	mu       sync.RWMutex
 	limiters map[string]*rateLimiterEntry
@@ -31,6 +36,18 @@ type rateLimiterEntry struct {
 	lastSeen time.Time
 }
 
+// rateLimiterIdleTimeout is how long a key can be inactive before its limiter is evicted.
+const rateLimiterIdleTimeout = 10 * time.Minute
+
+// RateLimitResult describes the outcome of a rate limit check.
+type RateLimitResult struct {
+	Allowed    bool
+	RetryAfter time.Duration // Time until the next token, when not allowed
+	Limit      int           // Bucket size
+	Remaining  int           // Tokens left after this request
+	Reset      time.Time     // When the bucket will be full again
+}
+
 // NewRateLimiter creates a rate limiter with the specified rate (requests per second) and burst size.
 // For example, NewRateLimiter(rate.Every(time.Minute/100), 10) allows 100 requests/minute with burst of 10.
 func NewRateLimiter(r rate.Limit, burst int) *RateLimiter {
@@ -38,47 +55,74 @@ func NewRateLimiter(r rate.Limit, burst int) *RateLimiter {
 		limiters: make(map[string]*rateLimiterEntry),
 		rate:     r,
 		burst:    burst,
-		cleanup:  5 * time.Minute,
+		cleanup:  rateLimiterIdleTimeout,
 	}
 	go rl.cleanupLoop()
 	return rl
 }
 
-// Allow checks if a request from the given IP is allowed.
-func (rl *RateLimiter) Allow(ip string) bool {
-	allowed, _ := rl.AllowWithRetry(ip)
+// SetRate changes the refill rate for all existing and future keys.
+func (rl *RateLimiter) SetRate(r rate.Limit) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.rate = r
+	for _, entry := range rl.limiters {
+		entry.limiter.SetLimit(r)
+	}
+}
+
+// Allow checks if a request from the given key is allowed.
+func (rl *RateLimiter) Allow(key string) bool {
+	allowed, _ := rl.AllowWithRetry(key)
 	return allowed
 }
 
 // AllowWithRetry checks if a request is allowed and returns time until next token if not.
-func (rl *RateLimiter) AllowWithRetry(ip string) (allowed bool, retryAfter time.Duration) {
+func (rl *RateLimiter) AllowWithRetry(key string) (allowed bool, retryAfter time.Duration) {
+	res := rl.Check(key)
+	return res.Allowed, res.RetryAfter
+}
+
+// Check consumes a token for the given key if one is available and reports the
+// bucket state for rate limit headers.
+func (rl *RateLimiter) Check(key string) RateLimitResult {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	entry, exists := rl.limiters[ip]
+	now := time.Now()
+	entry, exists := rl.limiters[key]
 	if !exists {
 		entry = &rateLimiterEntry{
 			limiter:  rate.NewLimiter(rl.rate, rl.burst),
-			lastSeen: time.Now(),
+			lastSeen: now,
 		}
-		rl.limiters[ip] = entry
+		rl.limiters[key] = entry
 	}
-	entry.lastSeen = time.Now()
+	entry.lastSeen = now
+
+	res := RateLimitResult{Allowed: true, Limit: rl.burst}
 
 	// Try to reserve a token
-	reservation := entry.limiter.Reserve()
+	reservation := entry.limiter.ReserveN(now, 1)
 	if !reservation.OK() {
-		return false, time.Minute // fallback
-	}
-
-	delay := reservation.Delay()
-	if delay > 0 {
+		res.Allowed = false
+		res.RetryAfter = time.Minute // fallback
+	} else if delay := reservation.DelayFrom(now); delay > 0 {
 		// Can't get token now, cancel reservation and return delay
-		reservation.Cancel()
-		return false, delay
+		reservation.CancelAt(now)
+		res.Allowed = false
+		res.RetryAfter = delay
 	}
 
-	return true, 0
+	tokens := entry.limiter.TokensAt(now)
+	if tokens > 0 {
+		res.Remaining = int(tokens)
+	}
+	res.Reset = now
+	if missing := float64(rl.burst) - tokens; missing > 0 && rl.rate > 0 {
+		res.Reset = now.Add(time.Duration(missing / float64(rl.rate) * float64(time.Second)))
+	}
+	return res
 }
 
 // cleanupLoop removes stale entries periodically.
@@ -87,40 +131,119 @@ func (rl *RateLimiter) cleanupLoop() {
 	for range ticker.C {
 		rl.mu.Lock()
 		cutoff := time.Now().Add(-rl.cleanup)
-		for ip, entry := range rl.limiters {
+		for key, entry := range rl.limiters {
 			if entry.lastSeen.Before(cutoff) {
-				delete(rl.limiters, ip)
+				delete(rl.limiters, key)
 			}
 		}
 		rl.mu.Unlock()
 	}
 }
 
-// QueryRateLimiter is the shared rate limiter for database queries.
-// Allows 100 queries per minute per IP with a burst of 20.
-var QueryRateLimiter = NewRateLimiter(rate.Every(time.Minute/100), 20)
+// Default per-key query rates, overridable with RATE_LIMIT_ANON_RPS and RATE_LIMIT_AUTHED_RPS.
+const (
+	defaultAnonQueryRPS   = 100.0 / 60 // 100 queries per minute
+	defaultAuthedQueryRPS = 300.0 / 60 // 300 queries per minute
+	queryRateLimitBurst   = 20
+)
+
+// QueryRateLimiter is the shared rate limiter for anonymous database queries, keyed by IP.
+// Allows 100 queries per minute per IP with a burst of 20 by default.
+var QueryRateLimiter = NewRateLimiter(rate.Limit(defaultAnonQueryRPS), queryRateLimitBurst)
+
+// QueryAuthedRateLimiter is the shared rate limiter for authenticated database queries, keyed by account.
+// Allows 300 queries per minute per account with a burst of 20 by default.
+var QueryAuthedRateLimiter = NewRateLimiter(rate.Limit(defaultAuthedQueryRPS), queryRateLimitBurst)
+
+// ConfigureQueryRateLimits applies RATE_LIMIT_ANON_RPS, RATE_LIMIT_AUTHED_RPS and the
+// RATE_LIMIT_MCP_*_RPS tier rates to the shared rate limiters. Should be called after
+// environment files are loaded.
+func ConfigureQueryRateLimits() {
+	if rps, ok := rateLimitFromEnv("RATE_LIMIT_ANON_RPS"); ok {
+		QueryRateLimiter.SetRate(rps)
+	}
+	if rps, ok := rateLimitFromEnv("RATE_LIMIT_AUTHED_RPS"); ok {
+		QueryAuthedRateLimiter.SetRate(rps)
+	}
+	if rps, ok := rateLimitFromEnv("RATE_LIMIT_MCP_STANDARD_RPS"); ok {
+		MCPStandardRateLimiter.SetRate(rps)
+	}
+	if rps, ok := rateLimitFromEnv("RATE_LIMIT_MCP_PREMIUM_RPS"); ok {
+		MCPPremiumRateLimiter.SetRate(rps)
+	}
+}
+
+func rateLimitFromEnv(name string) (rate.Limit, bool) {
+	val := os.Getenv(name)
+	if val == "" {
+		return 0, false
+	}
+	rps, err := strconv.ParseFloat(val, 64)
+	if err != nil || rps <= 0 {
+		slog.Warn("Invalid rate limit, using default", "name", name, "value", val)
+		return 0, false
+	}
+	return rate.Limit(rps), true
+}
+
+// setRateLimitHeaders sets the X-RateLimit-* headers from a rate limit check.
+// X-RateLimit-Reset is in Unix epoch seconds.
+func setRateLimitHeaders(w http.ResponseWriter, res RateLimitResult) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(res.Reset.Unix(), 10))
+}
+
+// writeRateLimitExceeded writes a 429 response with a Retry-After header.
+func writeRateLimitExceeded(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	retrySeconds := int(retryAfter.Seconds())
+	if retrySeconds < 1 {
+		retrySeconds = 1
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", retrySeconds))
+	w.WriteHeader(http.StatusTooManyRequests)
 
-// RateLimitMiddleware creates HTTP middleware that rate limits requests using the given limiter.
+	_ = json.NewEncoder(w).Encode(RateLimitError{
+		Code:       ErrCodeRateLimited,
+		Error:      "rate_limit_exceeded",
+		Message:    "Too many requests. Please slow down.",
+		RetryAfter: retrySeconds,
+		RequestID:  GetRequestIDFromContext(r.Context()),
+	})
+}
+
+// RateLimitMiddleware creates HTTP middleware that rate limits requests by IP using the given limiter.
 func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := GetIPFromRequest(r)
-			allowed, retryAfter := limiter.AllowWithRetry(ip)
-			if !allowed {
-				retrySeconds := int(retryAfter.Seconds())
-				if retrySeconds < 1 {
-					retrySeconds = 1
-				}
-
-				w.Header().Set("Content-Type", "application/json")
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", retrySeconds))
-				w.WriteHeader(http.StatusTooManyRequests)
-
-				_ = json.NewEncoder(w).Encode(RateLimitError{
-					Error:      "rate_limit_exceeded",
-					Message:    "Too many requests. Please slow down.",
-					RetryAfter: retrySeconds,
-				})
+			res := limiter.Check(GetIPFromRequest(r))
+			setRateLimitHeaders(w, res)
+			if !res.Allowed {
+				writeRateLimitExceeded(w, r, res.RetryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PerUserRateLimitMiddleware creates HTTP middleware that gives each authenticated
+// account its own bucket in authed, and each anonymous IP its own bucket in anon.
+// Must run after OptionalAuth so the account is available in the request context.
+func PerUserRateLimitMiddleware(authed, anon *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var res RateLimitResult
+			if account := GetAccountFromContext(r.Context()); account != nil {
+				res = authed.Check(account.ID.String())
+			} else {
+				res = anon.Check(GetIPFromRequest(r))
+			}
+			setRateLimitHeaders(w, res)
+			if !res.Allowed {
+				writeRateLimitExceeded(w, r, res.RetryAfter)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -128,8 +251,8 @@ func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 	}
 }
 
-// QueryRateLimitMiddleware is middleware that uses the shared query rate limiter.
-var QueryRateLimitMiddleware = RateLimitMiddleware(QueryRateLimiter)
+// QueryRateLimitMiddleware is middleware that uses the shared query rate limiters.
+var QueryRateLimitMiddleware = PerUserRateLimitMiddleware(QueryAuthedRateLimiter, QueryRateLimiter)
 
 // CheckRateLimit checks the rate limit and returns an error message if exceeded.
 // Returns empty string if allowed, or error message with retry time if not.
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/malbeclabs/lake/api/handlers"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/time/rate"
@@ -102,3 +104,67 @@ func TestRateLimitMiddleware_JSONResponse(t *testing.T) {
 	assert.NotEmpty(t, errResp.Message)
 	assert.Greater(t, errResp.RetryAfter, 0)
 }
+
+func TestRateLimitMiddleware_Headers(t *testing.T) {
+	limiter := handlers.NewRateLimiter(rate.Limit(1), 3)
+
+	handler := handlers.RateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.RemoteAddr = "192.168.1.60:12345"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "2", rec.Header().Get("X-RateLimit-Remaining"))
+	reset, err := strconv.ParseInt(rec.Header().Get("X-RateLimit-Reset"), 10, 64)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, reset, time.Now().Add(-time.Second).Unix())
+}
+
+func TestPerUserRateLimitMiddleware_SeparateBuckets(t *testing.T) {
+	authed := handlers.NewRateLimiter(rate.Limit(1), 2)
+	anon := handlers.NewRateLimiter(rate.Limit(1), 1)
+
+	handler := handlers.PerUserRateLimitMiddleware(authed, anon)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(account *handlers.Account) int {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "192.168.1.70:12345"
+		if account != nil {
+			req = req.WithContext(handlers.SetAccountInContext(req.Context(), account))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	alice := &handlers.Account{ID: uuid.New()}
+	bob := &handlers.Account{ID: uuid.New()}
+
+	// Anonymous requests from the IP use the anon bucket
+	assert.Equal(t, http.StatusOK, serve(nil))
+	assert.Equal(t, http.StatusTooManyRequests, serve(nil))
+
+	// Authenticated users from the same IP are not affected by the anon bucket
+	assert.Equal(t, http.StatusOK, serve(alice))
+	assert.Equal(t, http.StatusOK, serve(alice))
+	assert.Equal(t, http.StatusTooManyRequests, serve(alice))
+
+	// Each account has its own bucket
+	assert.Equal(t, http.StatusOK, serve(bob))
+}
+
+func TestConfigureQueryRateLimits(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ANON_RPS", "invalid")
+	t.Setenv("RATE_LIMIT_AUTHED_RPS", "-1")
+
+	// Invalid values are ignored and must not panic
+	handlers.ConfigureQueryRateLimits()
+	assert.True(t, handlers.QueryAuthedRateLimiter.Allow("configure-test"))
+}
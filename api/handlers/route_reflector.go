@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// Route reflector candidate scoring weights; they sum to 1 so scores are 0-1
+const (
+	rrBetweennessWeight = 0.35
+	rrResilienceWeight  = 0.25
+	rrMetroWeight       = 0.2
+	rrDegreeWeight      = 0.2
+)
+
+// Default and maximum number of route reflector candidates returned
+const (
+	defaultRouteReflectorCandidates = 5
+	maxRouteReflectorCandidates     = 20
+)
+
+// routeReflectorCacheTTL is how long the candidate ranking is reused; like
+// betweenness centrality it runs a shortest-path search from every device.
+const routeReflectorCacheTTL = 5 * time.Minute
+
+// RouteReflectorCandidate is a device suggested as an iBGP route reflector
+type RouteReflectorCandidate struct {
+	DevicePK   string   `json:"devicePK"`
+	DeviceCode string   `json:"deviceCode"`
+	MetroCode  string   `json:"metroCode"`
+	Score      float64  `json:"score"` // 0-1, higher is a better candidate
+	Reasons    []string `json:"reasons"`
+}
+
+var (
+	routeReflectorCache          []RouteReflectorCandidate
+	routeReflectorCacheFetchedAt time.Time
+	routeReflectorCacheMu        sync.RWMutex
+)
+
+// GetRouteReflectorCandidates suggests the ?limit= (default 5, max 20) best
+// activated devices to act as route reflectors for iBGP over the ISIS
+// underlay. Each device is scored on how many shortest paths cross it, how
+// few devices its loss would cut off, how many other metros its metro has
+// adjacencies to, and its ISIS degree, each relative to the best device.
+func GetRouteReflectorCandidates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	limit := defaultRouteReflectorCandidates
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxRouteReflectorCandidates {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest,
+				fmt.Sprintf("limit must be between 1 and %d", maxRouteReflectorCandidates))
+			return
+		}
+		limit = n
+	}
+
+	candidates, cached, err := getRouteReflectorCandidates(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Route reflector candidates error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to rank route reflector candidates", err))
+		return
+	}
+
+	if cached {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	writeJSON(w, candidates)
+}
+
+// getRouteReflectorCandidates returns the cached ranking of all candidates,
+// recomputing it when older than routeReflectorCacheTTL
+func getRouteReflectorCandidates(ctx context.Context) ([]RouteReflectorCandidate, bool, error) {
+	routeReflectorCacheMu.RLock()
+	candidates, fetchedAt := routeReflectorCache, routeReflectorCacheFetchedAt
+	routeReflectorCacheMu.RUnlock()
+	if candidates != nil && time.Since(fetchedAt) < routeReflectorCacheTTL {
+		return candidates, true, nil
+	}
+
+	start := time.Now()
+	g, err := loadISISGraph(ctx)
+	metrics.RecordNeo4jQuery("route_reflector_candidates", time.Since(start), err)
+	if err != nil {
+		return nil, false, err
+	}
+	metroCodes, err := loadMetroCodes(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	candidates = rankRouteReflectorCandidates(g, metroCodes)
+
+	routeReflectorCacheMu.Lock()
+	routeReflectorCache = candidates
+	routeReflectorCacheFetchedAt = time.Now()
+	routeReflectorCacheMu.Unlock()
+
+	return candidates, false, nil
+}
+
+// rankRouteReflectorCandidates scores every activated device, best first.
+// Each factor is divided by the best device's so no one factor dominates
+// because of its scale; resilience is the share of other devices not cut
+// off when the device is lost.
+func rankRouteReflectorCandidates(g *isisGraph, metroCodes map[string]string) []RouteReflectorCandidate {
+	n := len(g.nodes)
+	betweenness := brandesBetweenness(g.adj)
+	cut := articulationPoints(g.adj)
+
+	// Other metros each metro has an adjacency to
+	metroPeers := make(map[string]map[string]bool)
+	for v, edges := range g.adj {
+		from := g.nodes[v].MetroPK
+		if from == "" {
+			continue
+		}
+		for _, e := range edges {
+			to := g.nodes[e.to].MetroPK
+			if to == "" || to == from {
+				continue
+			}
+			if metroPeers[from] == nil {
+				metroPeers[from] = make(map[string]bool)
+			}
+			metroPeers[from][to] = true
+		}
+	}
+
+	var maxBetweenness float64
+	var maxMetroPeers, maxDegree int
+	for v := range g.nodes {
+		maxBetweenness = max(maxBetweenness, betweenness[v])
+		maxMetroPeers = max(maxMetroPeers, len(metroPeers[g.nodes[v].MetroPK]))
+		maxDegree = max(maxDegree, len(g.adj[v]))
+	}
+
+	candidates := []RouteReflectorCandidate{}
+	for v, node := range g.nodes {
+		if node.Status != "activated" {
+			continue
+		}
+		stranded := 0
+		if cut[v] {
+			stranded = strandedByRemoval(g.adj, v)
+		}
+		metroPeerCount := len(metroPeers[node.MetroPK])
+		degree := len(g.adj[v])
+
+		betweennessFactor := rrFactor(betweenness[v], maxBetweenness)
+		resilienceFactor := 1.0
+		if n > 1 {
+			resilienceFactor = 1 - float64(stranded)/float64(n-1)
+		}
+		metroFactor := rrFactor(float64(metroPeerCount), float64(maxMetroPeers))
+		degreeFactor := rrFactor(float64(degree), float64(maxDegree))
+
+		c := RouteReflectorCandidate{
+			DevicePK:   node.PK,
+			DeviceCode: node.Code,
+			MetroCode:  metroCodes[node.MetroPK],
+			Score: rrBetweennessWeight*betweennessFactor +
+				rrResilienceWeight*resilienceFactor +
+				rrMetroWeight*metroFactor +
+				rrDegreeWeight*degreeFactor,
+			Reasons: []string{},
+		}
+		if betweennessFactor >= 0.5 {
+			c.Reasons = append(c.Reasons, fmt.Sprintf("hub on many shortest paths (betweenness %.2f)", betweenness[v]))
+		}
+		if stranded == 0 {
+			c.Reasons = append(c.Reasons, "losing it doesn't partition the network")
+		} else {
+			c.Reasons = append(c.Reasons, fmt.Sprintf("losing it cuts off %d devices", stranded))
+		}
+		if metroFactor >= 0.5 {
+			c.Reasons = append(c.Reasons, fmt.Sprintf("metro %s has adjacencies to %d other metros", c.MetroCode, metroPeerCount))
+		}
+		if degreeFactor >= 0.5 {
+			c.Reasons = append(c.Reasons, fmt.Sprintf("ISIS degree %d", degree))
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].DeviceCode < candidates[j].DeviceCode
+	})
+	return candidates
+}
+
+// strandedByRemoval returns how many devices lose their path to the rest of
+// v's component when v is removed: every piece it splits into except the largest
+func strandedByRemoval(adj [][]isisGraphEdge, v int) int {
+	seen := make([]bool, len(adj))
+	seen[v] = true
+	total, largest := 0, 0
+	for _, start := range adj[v] {
+		if seen[start.to] {
+			continue
+		}
+		seen[start.to] = true
+		size := 0
+		queue := []int{start.to}
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			size++
+			for _, e := range adj[u] {
+				if !seen[e.to] {
+					seen[e.to] = true
+					queue = append(queue, e.to)
+				}
+			}
+		}
+		total += size
+		largest = max(largest, size)
+	}
+	return total - largest
+}
+
+// rrFactor is v relative to the best device's value
+func rrFactor(v, best float64) float64 {
+	if best <= 0 {
+		return 0
+	}
+	return v / best
+}
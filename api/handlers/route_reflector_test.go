@@ -0,0 +1,83 @@
+package handlers
+
+import "testing"
+
+func TestRankRouteReflectorCandidates(t *testing.T) {
+	// hub in AMS links to a in FRA, b in LON and c in AMS; d hangs off c and
+	// e is in maintenance
+	g := &isisGraph{
+		nodes: []isisGraphNode{
+			{PK: "hub", Code: "hub", Status: "activated", MetroPK: "ams"},
+			{PK: "a", Code: "a", Status: "activated", MetroPK: "fra"},
+			{PK: "b", Code: "b", Status: "activated", MetroPK: "lon"},
+			{PK: "c", Code: "c", Status: "activated", MetroPK: "ams"},
+			{PK: "d", Code: "d", Status: "activated", MetroPK: "ams"},
+			{PK: "e", Code: "e", Status: "drained", MetroPK: "fra"},
+		},
+		adj: [][]isisGraphEdge{
+			{{to: 1, weight: 10}, {to: 2, weight: 10}, {to: 3, weight: 10}},
+			{{to: 0, weight: 10}, {to: 2, weight: 10}, {to: 5, weight: 10}},
+			{{to: 0, weight: 10}, {to: 1, weight: 10}},
+			{{to: 0, weight: 10}, {to: 4, weight: 10}},
+			{{to: 3, weight: 10}},
+			{{to: 1, weight: 10}},
+		},
+	}
+	metroCodes := map[string]string{"ams": "AMS", "fra": "FRA", "lon": "LON"}
+
+	candidates := rankRouteReflectorCandidates(g, metroCodes)
+	if len(candidates) != 5 {
+		t.Fatalf("expected the 5 activated devices, got %+v", candidates)
+	}
+	if candidates[0].DevicePK != "hub" || candidates[0].MetroCode != "AMS" {
+		t.Errorf("expected hub first, got %+v", candidates[0])
+	}
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].Score > candidates[i-1].Score {
+			t.Errorf("candidates not sorted by score: %+v", candidates)
+		}
+	}
+
+	byPK := make(map[string]RouteReflectorCandidate)
+	for _, c := range candidates {
+		byPK[c.DevicePK] = c
+	}
+	// Losing the hub cuts off c and d from the larger a-b-e side
+	if !hasReason(byPK["hub"], "losing it cuts off 2 devices") {
+		t.Errorf("hub reasons: %v", byPK["hub"].Reasons)
+	}
+	if !hasReason(byPK["b"], "losing it doesn't partition the network") {
+		t.Errorf("b reasons: %v", byPK["b"].Reasons)
+	}
+	if !hasReason(byPK["hub"], "metro AMS has adjacencies to 2 other metros") {
+		t.Errorf("hub reasons: %v", byPK["hub"].Reasons)
+	}
+	if byPK["d"].Score >= byPK["c"].Score {
+		t.Errorf("expected leaf d to score below c, got %v and %v", byPK["d"].Score, byPK["c"].Score)
+	}
+}
+
+func TestStrandedByRemoval(t *testing.T) {
+	// a - b - c - d with e hanging off b
+	adj := [][]isisGraphEdge{
+		{{to: 1}},
+		{{to: 0}, {to: 2}, {to: 4}},
+		{{to: 1}, {to: 3}},
+		{{to: 2}},
+		{{to: 1}},
+	}
+	for v, want := range []int{0, 2, 1, 0, 0} {
+		if got := strandedByRemoval(adj, v); got != want {
+			t.Errorf("strandedByRemoval(%d) = %d, want %d", v, got, want)
+		}
+	}
+}
+
+func hasReason(c RouteReflectorCandidate, reason string) bool {
+	for _, r := range c.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,6 +21,9 @@ type SearchSuggestion struct {
 	Label    string `json:"label"`
 	Sublabel string `json:"sublabel"`
 	URL      string `json:"url"`
+	// Latitude and Longitude are only set by geographic search
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
 }
 
 // AutocompleteResponse is the response for the autocomplete endpoint
@@ -615,7 +618,7 @@ func SearchAutocomplete(w http.ResponseWriter, r *http.Request) {
 				suggestions, _, err = searchMulticastGroups(gCtx, term, perTypeLimit)
 			}
 			if err != nil {
-				log.Printf("Search %s error: %v", et, err)
+				LoggerFromContext(ctx).Error("Search error", "entity_type", et, "error", err)
 				return nil // Don't fail the whole search
 			}
 			resultsChan <- searchResult{entityType: et, suggestions: suggestions}
@@ -728,7 +731,7 @@ func Search(w http.ResponseWriter, r *http.Request) {
 				suggestions, total, err = searchMulticastGroups(gCtx, term, limit)
 			}
 			if err != nil {
-				log.Printf("Search %s error: %v", et, err)
+				LoggerFromContext(ctx).Error("Search error", "entity_type", et, "error", err)
 				return nil // Don't fail the whole search
 			}
 			resultsChan <- searchResult{entityType: et, suggestions: suggestions, total: total}
@@ -755,3 +758,253 @@ func Search(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(SearchResponse{Query: q, Results: results})
 }
+
+// GeoBounds is a latitude/longitude bounding box in decimal degrees
+type GeoBounds struct {
+	LatMin float64 `json:"lat_min"`
+	LatMax float64 `json:"lat_max"`
+	LonMin float64 `json:"lon_min"`
+	LonMax float64 `json:"lon_max"`
+}
+
+// GeoSearchResponse is the response for the geographic search endpoint
+type GeoSearchResponse struct {
+	Bounds  GeoBounds                    `json:"bounds"`
+	Results map[string]SearchResultGroup `json:"results"`
+}
+
+// kmPerDegreeLat is the approximate length of one degree of latitude
+const kmPerDegreeLat = 111.32
+
+// radiusToBounds converts a center point and radius into the bounding box that
+// encloses the circle. Longitude spans are clamped rather than wrapped, so a
+// circle crossing the antimeridian is truncated at ±180.
+func radiusToBounds(lat, lon, radiusKm float64) GeoBounds {
+	dLat := radiusKm / kmPerDegreeLat
+	dLon := 180.0
+	if cosLat := math.Cos(lat * math.Pi / 180); cosLat > 1e-9 {
+		dLon = math.Min(radiusKm/(kmPerDegreeLat*cosLat), 180)
+	}
+	return GeoBounds{
+		LatMin: math.Max(lat-dLat, -90),
+		LatMax: math.Min(lat+dLat, 90),
+		LonMin: math.Max(lon-dLon, -180),
+		LonMax: math.Min(lon+dLon, 180),
+	}
+}
+
+// parseGeoBounds reads either lat_min/lat_max/lon_min/lon_max or
+// lat/lon/radius_km from the query string.
+func parseGeoBounds(r *http.Request) (GeoBounds, error) {
+	q := r.URL.Query()
+	parse := func(name string) (float64, error) {
+		v := q.Get(name)
+		if v == "" {
+			return 0, fmt.Errorf("%s is required", name)
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, fmt.Errorf("%s must be a number", name)
+		}
+		return f, nil
+	}
+
+	if q.Get("radius_km") != "" {
+		radius, err := parse("radius_km")
+		if err != nil {
+			return GeoBounds{}, err
+		}
+		if radius <= 0 {
+			return GeoBounds{}, fmt.Errorf("radius_km must be positive")
+		}
+		lat, err := parse("lat")
+		if err != nil {
+			return GeoBounds{}, err
+		}
+		lon, err := parse("lon")
+		if err != nil {
+			return GeoBounds{}, err
+		}
+		if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			return GeoBounds{}, fmt.Errorf("lat must be within [-90, 90] and lon within [-180, 180]")
+		}
+		return radiusToBounds(lat, lon, radius), nil
+	}
+
+	var b GeoBounds
+	var err error
+	if b.LatMin, err = parse("lat_min"); err != nil {
+		return GeoBounds{}, err
+	}
+	if b.LatMax, err = parse("lat_max"); err != nil {
+		return GeoBounds{}, err
+	}
+	if b.LonMin, err = parse("lon_min"); err != nil {
+		return GeoBounds{}, err
+	}
+	if b.LonMax, err = parse("lon_max"); err != nil {
+		return GeoBounds{}, err
+	}
+	if b.LatMin > b.LatMax || b.LonMin > b.LonMax {
+		return GeoBounds{}, fmt.Errorf("lat_min/lon_min must not exceed lat_max/lon_max")
+	}
+	if b.LatMin < -90 || b.LatMax > 90 || b.LonMin < -180 || b.LonMax > 180 {
+		return GeoBounds{}, fmt.Errorf("latitude must be within [-90, 90] and longitude within [-180, 180]")
+	}
+	return b, nil
+}
+
+// geoSearchMetros returns metros whose coordinates fall inside the bounding box
+func geoSearchMetros(ctx context.Context, b GeoBounds, limit int) ([]SearchSuggestion, int, error) {
+	condition := `latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?`
+	args := []any{b.LatMin, b.LatMax, b.LonMin, b.LonMax}
+
+	countQuery := `SELECT count(*) FROM dz_metros_current WHERE ` + condition
+	var total uint64
+	if err := envDB(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT pk, code, name, latitude, longitude
+		FROM dz_metros_current
+		WHERE ` + condition + `
+		ORDER BY code
+		LIMIT ?
+	`
+
+	rows, err := envDB(ctx).Query(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	suggestions := []SearchSuggestion{}
+	for rows.Next() {
+		var pk, code, name string
+		var lat, lon float64
+		if err := rows.Scan(&pk, &code, &name, &lat, &lon); err != nil {
+			return nil, 0, err
+		}
+		suggestions = append(suggestions, SearchSuggestion{
+			Type:      string(entityMetro),
+			ID:        pk,
+			Label:     code,
+			Sublabel:  name,
+			URL:       fmt.Sprintf("/dz/metros/%s", pk),
+			Latitude:  &lat,
+			Longitude: &lon,
+		})
+	}
+	return suggestions, int(total), rows.Err()
+}
+
+// geoSearchDevices returns devices whose metro falls inside the bounding box.
+// Devices don't carry their own coordinates, so they inherit their metro's.
+func geoSearchDevices(ctx context.Context, b GeoBounds, limit int) ([]SearchSuggestion, int, error) {
+	condition := `m.latitude BETWEEN ? AND ? AND m.longitude BETWEEN ? AND ?`
+	args := []any{b.LatMin, b.LatMax, b.LonMin, b.LonMax}
+
+	countQuery := `
+		SELECT count(*)
+		FROM dz_devices_current d
+		JOIN dz_metros_current m ON d.metro_pk = m.pk
+		WHERE ` + condition
+	var total uint64
+	if err := envDB(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT
+			d.pk,
+			d.code,
+			d.device_type,
+			m.code as metro_code,
+			m.latitude,
+			m.longitude
+		FROM dz_devices_current d
+		JOIN dz_metros_current m ON d.metro_pk = m.pk
+		WHERE ` + condition + `
+		ORDER BY d.code
+		LIMIT ?
+	`
+
+	rows, err := envDB(ctx).Query(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	suggestions := []SearchSuggestion{}
+	for rows.Next() {
+		var pk, code, deviceType, metroCode string
+		var lat, lon float64
+		if err := rows.Scan(&pk, &code, &deviceType, &metroCode, &lat, &lon); err != nil {
+			return nil, 0, err
+		}
+		suggestions = append(suggestions, SearchSuggestion{
+			Type:      string(entityDevice),
+			ID:        pk,
+			Label:     code,
+			Sublabel:  fmt.Sprintf("%s - %s", deviceType, metroCode),
+			URL:       fmt.Sprintf("/dz/devices/%s", pk),
+			Latitude:  &lat,
+			Longitude: &lon,
+		})
+	}
+	return suggestions, int(total), rows.Err()
+}
+
+// GetGeoSearch returns metros and devices within a geographic bounding box.
+// The box is given either as lat_min/lat_max/lon_min/lon_max or as a
+// lat/lon center with radius_km.
+func GetGeoSearch(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	bounds, err := parseGeoBounds(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	start := time.Now()
+
+	var metros, devices []SearchSuggestion
+	var metroTotal, deviceTotal int
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		metros, metroTotal, err = geoSearchMetros(gCtx, bounds, limit)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		devices, deviceTotal, err = geoSearchDevices(gCtx, bounds, limit)
+		return err
+	})
+	err = g.Wait()
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Geo search error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	results := make(map[string]SearchResultGroup)
+	if metroTotal > 0 {
+		results[string(entityMetro)] = SearchResultGroup{Items: metros, Total: metroTotal}
+	}
+	if deviceTotal > 0 {
+		results[string(entityDevice)] = SearchResultGroup{Items: devices, Total: deviceTotal}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(GeoSearchResponse{Bounds: bounds, Results: results})
+}
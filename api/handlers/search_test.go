@@ -533,3 +533,122 @@ func TestSearch_SuggestionURLFormat(t *testing.T) {
 		assert.Contains(t, linkGroup.Items[0].URL, "/dz/links/")
 	}
 }
+
+func setupGeoSearchTables(t *testing.T) {
+	ctx := t.Context()
+
+	err := config.DB.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS dz_metros_current (
+			pk String,
+			code String,
+			name String,
+			latitude Float64,
+			longitude Float64
+		) ENGINE = Memory
+	`)
+	require.NoError(t, err)
+
+	err = config.DB.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS dz_devices_current (
+			pk String,
+			code String,
+			device_type String,
+			metro_pk String,
+			public_ip String
+		) ENGINE = Memory
+	`)
+	require.NoError(t, err)
+
+	err = config.DB.Exec(ctx, `
+		INSERT INTO dz_metros_current (pk, code, name, latitude, longitude) VALUES
+		('metro-nyc', 'NYC', 'New York', 40.71, -74.01),
+		('metro-chi', 'CHI', 'Chicago', 41.88, -87.63),
+		('metro-lon', 'LON', 'London', 51.51, -0.13)
+	`)
+	require.NoError(t, err)
+
+	err = config.DB.Exec(ctx, `
+		INSERT INTO dz_devices_current (pk, code, device_type, metro_pk, public_ip) VALUES
+		('dev-1', 'NYC-CORE-01', 'router', 'metro-nyc', '10.0.0.1'),
+		('dev-2', 'CHI-CORE-01', 'router', 'metro-chi', '10.0.0.2'),
+		('dev-3', 'LON-CORE-01', 'router', 'metro-lon', '10.0.1.1')
+	`)
+	require.NoError(t, err)
+}
+
+func TestGetGeoSearch_BoundingBox(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+	setupGeoSearchTables(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search/geo?lat_min=35&lat_max=45&lon_min=-90&lon_max=-70", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetGeoSearch(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.GeoSearchResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+
+	metros := response.Results["metro"]
+	assert.Equal(t, 2, metros.Total)
+	require.Len(t, metros.Items, 2)
+	assert.Equal(t, "CHI", metros.Items[0].Label)
+	assert.Equal(t, "NYC", metros.Items[1].Label)
+	require.NotNil(t, metros.Items[1].Latitude)
+	assert.InDelta(t, 40.71, *metros.Items[1].Latitude, 0.001)
+
+	devices := response.Results["device"]
+	assert.Equal(t, 2, devices.Total)
+	for _, d := range devices.Items {
+		assert.NotEqual(t, "LON-CORE-01", d.Label)
+		assert.Contains(t, d.URL, "/dz/devices/")
+	}
+}
+
+func TestGetGeoSearch_Radius(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+	setupGeoSearchTables(t)
+
+	// 200km around Manhattan reaches New York but not Chicago (~1150km away)
+	req := httptest.NewRequest(http.MethodGet, "/api/search/geo?lat=40.7&lon=-74.0&radius_km=200", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetGeoSearch(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.GeoSearchResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+
+	metros := response.Results["metro"]
+	require.Len(t, metros.Items, 1)
+	assert.Equal(t, "NYC", metros.Items[0].Label)
+	assert.Less(t, response.Bounds.LatMin, 40.7)
+	assert.Greater(t, response.Bounds.LonMax, -74.0)
+}
+
+func TestGetGeoSearch_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing bounds", ""},
+		{"non-numeric", "lat_min=a&lat_max=10&lon_min=0&lon_max=10"},
+		{"inverted", "lat_min=10&lat_max=0&lon_min=0&lon_max=10"},
+		{"out of range", "lat_min=-100&lat_max=10&lon_min=0&lon_max=10"},
+		{"negative radius", "lat=0&lon=0&radius_km=-5"},
+		{"radius without center", "radius_km=50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/search/geo?"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			handlers.GetGeoSearch(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			var response handlers.ErrorResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.Equal(t, handlers.ErrCodeBadRequest, response.Code)
+		})
+	}
+}
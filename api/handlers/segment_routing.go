@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// PrefixSID is a device's SR-MPLS node SID
+type PrefixSID struct {
+	DevicePK   string   `json:"devicePK"`
+	DeviceCode string   `json:"deviceCode"`
+	PrefixSID  int64    `json:"prefixSID"` // index relative to the SRGB base
+	Flags      []string `json:"flags"`
+}
+
+// AdjacencySID is an SR-MPLS adjacency SID of an IS-IS adjacency
+type AdjacencySID struct {
+	SourcePK string   `json:"sourcePK"`
+	TargetPK string   `json:"targetPK"`
+	AdjSID   int64    `json:"adjSID"`
+	Flags    []string `json:"flags"` // always empty; adjacency SID flags aren't synced
+}
+
+// SegmentRoutingSIDsResponse is the response for the segment routing SIDs
+// endpoint
+type SegmentRoutingSIDsResponse struct {
+	PrefixSIDs    []PrefixSID    `json:"prefixSIDs"`
+	AdjacencySIDs []AdjacencySID `json:"adjacencySIDs"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// GetSegmentRoutingSIDs returns the node SIDs of IS-IS devices and the
+// adjacency SIDs of ISIS_ADJACENT edges, as synced from the LSDB by the
+// indexer. Devices without a node SID are omitted.
+func GetSegmentRoutingSIDs(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	response := SegmentRoutingSIDsResponse{
+		PrefixSIDs:    []PrefixSID{},
+		AdjacencySIDs: []AdjacencySID{},
+	}
+
+	prefixCypher := `
+		MATCH (d:Device)
+		WHERE d.isis_system_id IS NOT NULL AND d.prefix_sid IS NOT NULL
+		RETURN d.pk AS pk,
+		       d.code AS code,
+		       d.prefix_sid AS prefix_sid,
+		       d.prefix_sid_flags AS flags
+		ORDER BY prefix_sid, code
+	`
+	result, err := session.Run(ctx, prefixCypher, nil)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Segment routing prefix SID query error", "error", err)
+		metrics.RecordNeo4jQuery("segment_routing_sids", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	prefixRecords, err := result.Collect(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Segment routing prefix SID collect error", "error", err)
+		metrics.RecordNeo4jQuery("segment_routing_sids", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+
+	adjCypher := `
+		MATCH (a:Device)-[r:ISIS_ADJACENT]->(b:Device)
+		WHERE size(coalesce(r.adj_sids, [])) > 0
+		RETURN a.pk AS source_pk,
+		       b.pk AS target_pk,
+		       r.adj_sids AS adj_sids
+		ORDER BY a.code, b.code
+	`
+	result, err = session.Run(ctx, adjCypher, nil)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Segment routing adjacency SID query error", "error", err)
+		metrics.RecordNeo4jQuery("segment_routing_sids", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	adjRecords, err := result.Collect(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Segment routing adjacency SID collect error", "error", err)
+		metrics.RecordNeo4jQuery("segment_routing_sids", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	metrics.RecordNeo4jQuery("segment_routing_sids", time.Since(start), nil)
+
+	for _, record := range prefixRecords {
+		pk, _ := record.Get("pk")
+		code, _ := record.Get("code")
+		prefixSID, _ := record.Get("prefix_sid")
+		flags, _ := record.Get("flags")
+
+		sid := PrefixSID{
+			DevicePK:   asString(pk),
+			DeviceCode: asString(code),
+			PrefixSID:  asInt64(prefixSID),
+			Flags:      []string{},
+		}
+		if arr, ok := flags.([]any); ok {
+			for _, f := range arr {
+				sid.Flags = append(sid.Flags, asString(f))
+			}
+		}
+		response.PrefixSIDs = append(response.PrefixSIDs, sid)
+	}
+
+	for _, record := range adjRecords {
+		sourcePK, _ := record.Get("source_pk")
+		targetPK, _ := record.Get("target_pk")
+		adjSids, _ := record.Get("adj_sids")
+
+		for _, adjSID := range asUint32Slice(adjSids) {
+			response.AdjacencySIDs = append(response.AdjacencySIDs, AdjacencySID{
+				SourcePK: asString(sourcePK),
+				TargetPK: asString(targetPK),
+				AdjSID:   int64(adjSID),
+				Flags:    []string{},
+			})
+		}
+	}
+
+	writeJSON(w, response)
+}
+
+// PathWithSIDsResponse is the response for the path with SIDs endpoint.
+// LabelStack is ordered top of stack first, with one label per hop after the
+// source; LabelTypes gives the kind of each label.
+type PathWithSIDsResponse struct {
+	Path        []PathHop `json:"path"`
+	LabelStack  []uint32  `json:"labelStack"`
+	LabelTypes  []string  `json:"labelTypes"` // "prefix" or "adj"
+	TotalMetric uint32    `json:"totalMetric"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// GetPathWithSIDs finds the shortest ISIS path between two devices, like
+// GetISISPath, and resolves the SR-MPLS label stack that steers traffic
+// along it. Prefix SID labels are SID indexes, since the SRGB base isn't
+// synced.
+func GetPathWithSIDs(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	fromPK := r.URL.Query().Get("from")
+	toPK := r.URL.Query().Get("to")
+	mode := r.URL.Query().Get("mode") // "hops" or "latency"
+
+	response := PathWithSIDsResponse{
+		Path:       []PathHop{},
+		LabelStack: []uint32{},
+		LabelTypes: []string{},
+	}
+	switch {
+	case fromPK == "" || toPK == "":
+		response.Error = "from and to parameters are required"
+		writeJSON(w, response)
+		return
+	case fromPK == toPK:
+		response.Error = "from and to must be different devices"
+		writeJSON(w, response)
+		return
+	}
+
+	path := findISISPath(ctx, fromPK, toPK, mode)
+	if path.Error != "" {
+		response.Error = path.Error
+		writeJSON(w, response)
+		return
+	}
+	response.Path = path.Path
+	response.TotalMetric = path.TotalMetric
+
+	pks := make([]string, len(path.Path))
+	for i, hop := range path.Path {
+		pks[i] = hop.DevicePK
+	}
+
+	start := time.Now()
+
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	cypher := `
+		MATCH (d:Device)
+		WHERE d.pk IN $pks
+		OPTIONAL MATCH (d)-[r:ISIS_ADJACENT]->(n:Device)
+		WHERE n.pk IN $pks
+		RETURN d.pk AS pk,
+		       d.prefix_sid AS prefix_sid,
+		       collect({target_pk: n.pk, adj_sids: r.adj_sids}) AS adjacencies
+	`
+	result, err := session.Run(ctx, cypher, map[string]any{"pks": pks})
+	if err != nil {
+		LoggerFromContext(ctx).Error("Path SIDs query error", "error", err)
+		metrics.RecordNeo4jQuery("path_with_sids", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Path SIDs collect error", "error", err)
+		metrics.RecordNeo4jQuery("path_with_sids", time.Since(start), err)
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	metrics.RecordNeo4jQuery("path_with_sids", time.Since(start), nil)
+
+	sids := pathSIDs{
+		prefix: map[string]uint32{},
+		adj:    map[[2]string][]uint32{},
+	}
+	for _, record := range records {
+		pk, _ := record.Get("pk")
+		prefixSID, _ := record.Get("prefix_sid")
+		adjacencies, _ := record.Get("adjacencies")
+
+		if prefixSID != nil {
+			sids.prefix[asString(pk)] = uint32(asInt64(prefixSID))
+		}
+		arr, _ := adjacencies.([]any)
+		for _, item := range arr {
+			m, ok := item.(map[string]any)
+			if !ok || m["target_pk"] == nil {
+				continue
+			}
+			sids.adj[[2]string{asString(pk), asString(m["target_pk"])}] = asUint32Slice(m["adj_sids"])
+		}
+	}
+
+	labels, types, err := buildLabelStack(path.Path, sids)
+	if err != nil {
+		response.Error = err.Error()
+		writeJSON(w, response)
+		return
+	}
+	response.LabelStack = labels
+	response.LabelTypes = types
+
+	writeJSON(w, response)
+}
+
+// pathSIDs holds the SIDs of the devices and adjacencies along a path. Prefix
+// SIDs are keyed by device PK and adjacency SIDs by source and target PK.
+type pathSIDs struct {
+	prefix map[string]uint32
+	adj    map[[2]string][]uint32
+}
+
+// buildLabelStack returns one label per hop after the source. A hop is
+// reached with its device's prefix SID when known, and otherwise with the
+// first adjacency SID of the link from the previous hop. It fails when a hop
+// has neither.
+func buildLabelStack(path []PathHop, sids pathSIDs) ([]uint32, []string, error) {
+	labels := []uint32{}
+	types := []string{}
+	for i := 1; i < len(path); i++ {
+		prev, hop := path[i-1], path[i]
+		if sid, ok := sids.prefix[hop.DevicePK]; ok {
+			labels = append(labels, sid)
+			types = append(types, "prefix")
+			continue
+		}
+		if adj := sids.adj[[2]string{prev.DevicePK, hop.DevicePK}]; len(adj) > 0 {
+			labels = append(labels, adj[0])
+			types = append(types, "adj")
+			continue
+		}
+		return nil, nil, fmt.Errorf("no SID known to reach %s from %s", hop.DeviceCode, prev.DeviceCode)
+	}
+	return labels, types, nil
+}
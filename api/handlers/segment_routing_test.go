@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSegmentRoutingSIDs(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (a:Device {pk: 'dev-a', code: 'AMS-1', isis_system_id: '0000.0000.0001', prefix_sid: 2, prefix_sid_flags: ['N']})
+			CREATE (b:Device {pk: 'dev-b', code: 'FRA-1', isis_system_id: '0000.0000.0002', prefix_sid: 1, prefix_sid_flags: ['N', 'R']})
+			CREATE (c:Device {pk: 'dev-c', code: 'LON-1', isis_system_id: '0000.0000.0003'})
+			CREATE (d:Device {pk: 'dev-d', code: 'PAR-1', prefix_sid: 4})
+			CREATE (a)-[:ISIS_ADJACENT {metric: 10, adj_sids: [100001, 100002]}]->(b)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 10, adj_sids: [100003]}]->(a)
+			CREATE (a)-[:ISIS_ADJACENT {metric: 20, adj_sids: []}]->(c)
+			CREATE (c)-[:ISIS_ADJACENT {metric: 20}]->(a)
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+
+	rr := httptest.NewRecorder()
+	handlers.GetSegmentRoutingSIDs(rr, httptest.NewRequest(http.MethodGet, "/api/topology/segment-routing-sids", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp handlers.SegmentRoutingSIDsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Error)
+
+	// Devices without a SID or without IS-IS are omitted; ordered by SID
+	assert.Equal(t, []handlers.PrefixSID{
+		{DevicePK: "dev-b", DeviceCode: "FRA-1", PrefixSID: 1, Flags: []string{"N", "R"}},
+		{DevicePK: "dev-a", DeviceCode: "AMS-1", PrefixSID: 2, Flags: []string{"N"}},
+	}, resp.PrefixSIDs)
+
+	// One entry per adjacency SID; adjacencies without SIDs are omitted
+	assert.Equal(t, []handlers.AdjacencySID{
+		{SourcePK: "dev-a", TargetPK: "dev-b", AdjSID: 100001, Flags: []string{}},
+		{SourcePK: "dev-a", TargetPK: "dev-b", AdjSID: 100002, Flags: []string{}},
+		{SourcePK: "dev-b", TargetPK: "dev-a", AdjSID: 100003, Flags: []string{}},
+	}, resp.AdjacencySIDs)
+}
+
+func TestGetPathWithSIDs(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (a:Device {pk: 'dev-a', code: 'AMS-1', status: 'activated', prefix_sid: 1})
+			CREATE (b:Device {pk: 'dev-b', code: 'FRA-1', status: 'activated'})
+			CREATE (c:Device {pk: 'dev-c', code: 'LON-1', status: 'activated', prefix_sid: 3})
+			CREATE (d:Device {pk: 'dev-d', code: 'PAR-1', status: 'activated'})
+			CREATE (a)-[:ISIS_ADJACENT {metric: 10, adj_sids: [100001, 100002]}]->(b)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 20, adj_sids: [100003]}]->(c)
+			CREATE (c)-[:ISIS_ADJACENT {metric: 30}]->(d)
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+
+	get := func(query string) handlers.PathWithSIDsResponse {
+		rr := httptest.NewRecorder()
+		handlers.GetPathWithSIDs(rr, httptest.NewRequest(http.MethodGet, "/api/topology/path-with-sids"+query, nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp handlers.PathWithSIDsResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		return resp
+	}
+
+	// FRA-1 has no prefix SID, so it's reached over the adjacency
+	resp := get("?from=dev-a&to=dev-c")
+	require.Empty(t, resp.Error)
+	require.Len(t, resp.Path, 3)
+	assert.Equal(t, []uint32{100001, 3}, resp.LabelStack)
+	assert.Equal(t, []string{"adj", "prefix"}, resp.LabelTypes)
+	assert.Equal(t, uint32(30), resp.TotalMetric)
+
+	// PAR-1 has neither a prefix SID nor an adjacency SID from LON-1
+	resp = get("?from=dev-a&to=dev-d")
+	assert.NotEmpty(t, resp.Error)
+	assert.Empty(t, resp.LabelStack)
+
+	resp = get("?from=dev-a")
+	assert.Equal(t, "from and to parameters are required", resp.Error)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// Share link lifetimes, set with ?expires_in= on creation
+const (
+	defaultShareLifetime = 7 * 24 * time.Hour
+	maxShareLifetime     = 90 * 24 * time.Hour
+)
+
+// ShareSessionResponse is the response for creating a share link. Token is only
+// ever returned once.
+type ShareSessionResponse struct {
+	ShareURL  string    `json:"shareUrl"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SharedSession is the read-only view of a session behind a share link. It
+// leaves out everything identifying the owner.
+type SharedSession struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	Name      *string         `json:"name"`
+	Content   json.RawMessage `json:"content"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Env       *string         `json:"env,omitempty"`
+	ViewCount int             `json:"view_count"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// parseShareLifetime parses ?expires_in= as a number of days ("7d") or a Go
+// duration ("12h"). An empty value gives the default.
+func parseShareLifetime(val string) (time.Duration, error) {
+	if val == "" {
+		return defaultShareLifetime, nil
+	}
+	d, err := parseDayDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expires_in %q", val)
+	}
+	if d <= 0 || d > maxShareLifetime {
+		return 0, fmt.Errorf("expires_in must be between 1s and %dd", int(maxShareLifetime.Hours()/24))
+	}
+	return d, nil
+}
+
+// parseDayDuration parses a number of days ("7d") or a Go duration ("12h")
+func parseDayDuration(val string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(val, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(val)
+}
+
+// sharedSessionURL is where a share token is opened, under WEB_BASE_URL if set
+func sharedSessionURL(token string) string {
+	return strings.TrimSuffix(os.Getenv("WEB_BASE_URL"), "/") + "/api/sessions/shared/" + token
+}
+
+// PostShareSession handles POST /api/sessions/{id}/share - creates a read-only
+// share link for a session owned by the caller.
+func PostShareSession(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid session ID")
+		return
+	}
+	lifetime, err := parseShareLifetime(r.URL.Query().Get("expires_in"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+
+	// Only the owner can share a session
+	account := GetAccountFromContext(ctx)
+	anonymousID := r.URL.Query().Get("anonymous_id")
+	var createdBy *uuid.UUID
+	var owned bool
+	if account != nil {
+		createdBy = &account.ID
+		err = config.PgPool.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND account_id = $2)
+		`, id, account.ID).Scan(&owned)
+	} else if anonymousID != "" {
+		err = config.PgPool.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND anonymous_id = $2)
+		`, id, anonymousID).Scan(&owned)
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to get session", err))
+		return
+	}
+	if !owned {
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
+		return
+	}
+
+	token, tokenHash, err := generateSessionToken()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to generate share token", err))
+		return
+	}
+
+	resp := ShareSessionResponse{ShareURL: sharedSessionURL(token), Token: token}
+	err = config.PgPool.QueryRow(ctx, `
+		INSERT INTO session_shares (session_id, share_token_hash, expires_at, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING expires_at
+	`, id, tokenHash, time.Now().Add(lifetime), createdBy).Scan(&resp.ExpiresAt)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to create share link", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// GetSharedSession handles GET /api/sessions/shared/{token} - returns the
+// session behind an unexpired share link. No authentication is required.
+func GetSharedSession(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
+		return
+	}
+
+	ctx := r.Context()
+	tokenHash := hashToken(token)
+
+	var shareID uuid.UUID
+	var session SharedSession
+	err := config.PgPool.QueryRow(ctx, `
+		SELECT sh.id, sh.expires_at,
+		       s.id, s.type, s.name, s.content, s.created_at, s.updated_at
+		FROM session_shares sh
+		INNER JOIN sessions s ON s.id = sh.session_id
+		WHERE sh.share_token_hash = $1 AND sh.expires_at > NOW()
+	`, tokenHash).Scan(&shareID, &session.ExpiresAt,
+		&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to get shared session", err))
+		return
+	}
+
+	err = config.PgPool.QueryRow(ctx, `
+		UPDATE session_shares SET view_count = view_count + 1 WHERE id = $1 RETURNING view_count
+	`, shareID).Scan(&session.ViewCount)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to record share view", err))
+		return
+	}
+
+	if session.Type == "chat" {
+		if env, err := GetSessionEnv(ctx, session.ID); err == nil && env != "" {
+			session.Env = &env
+		}
+	}
+
+	writeJSON(w, session)
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postShareSession(account *handlers.Account, sessionID, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID+"/share"+query, nil)
+	req = withChiURLParams(req, map[string]string{"id": sessionID})
+	if account != nil {
+		req = withAccount(req, account)
+	}
+	rr := httptest.NewRecorder()
+	handlers.PostShareSession(rr, req)
+	return rr
+}
+
+func getSharedSession(token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/shared/"+token, nil)
+	req = withChiURLParams(req, map[string]string{"token": token})
+	rr := httptest.NewRecorder()
+	handlers.GetSharedSession(rr, req)
+	return rr
+}
+
+func TestShareSession(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	owner := createTestAccount(t, ctx)
+	sessionID := uuid.New()
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, name, content, account_id)
+		VALUES ($1, 'query', 'Link latency', '[{"sql":"SELECT 1"}]', $2)
+	`, sessionID, owner.ID)
+	require.NoError(t, err)
+
+	rr := postShareSession(owner, sessionID.String(), "?expires_in=2d")
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var share handlers.ShareSessionResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&share))
+	assert.NotEmpty(t, share.Token)
+	assert.True(t, strings.HasSuffix(share.ShareURL, "/api/sessions/shared/"+share.Token), share.ShareURL)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), share.ExpiresAt, time.Minute)
+
+	// Anyone with the token can read the session, without owner details
+	for want := 1; want <= 2; want++ {
+		rr = getSharedSession(share.Token)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var raw map[string]any
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &raw))
+		assert.Equal(t, sessionID.String(), raw["id"])
+		assert.Equal(t, "Link latency", raw["name"])
+		assert.EqualValues(t, want, raw["view_count"])
+		assert.NotContains(t, raw, "account_id")
+		assert.NotContains(t, raw, "anonymous_id")
+	}
+
+	// Expired links stop working
+	_, err = config.PgPool.Exec(ctx, `UPDATE session_shares SET expires_at = NOW() - INTERVAL '1 minute'`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, getSharedSession(share.Token).Code)
+	assert.Equal(t, http.StatusNotFound, getSharedSession("not-a-share-token").Code)
+}
+
+func TestShareSession_Validation(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	owner := createTestAccount(t, ctx)
+	other := createTestAccount(t, ctx)
+	sessionID := uuid.New()
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, account_id) VALUES ($1, 'chat', $2)
+	`, sessionID, owner.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, postShareSession(other, sessionID.String(), "").Code, "only the owner can share")
+	assert.Equal(t, http.StatusNotFound, postShareSession(nil, sessionID.String(), "").Code)
+	assert.Equal(t, http.StatusBadRequest, postShareSession(owner, "not-a-uuid", "").Code)
+	assert.Equal(t, http.StatusBadRequest, postShareSession(owner, sessionID.String(), "?expires_in=forever").Code)
+	assert.Equal(t, http.StatusBadRequest, postShareSession(owner, sessionID.String(), "?expires_in=365d").Code)
+
+	rr := postShareSession(owner, sessionID.String(), "")
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var share handlers.ShareSessionResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&share))
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), share.ExpiresAt, time.Minute)
+}
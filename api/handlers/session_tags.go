@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// Session tag limits
+const (
+	maxSessionTags        = 10
+	maxSessionTagKeyLen   = 50
+	maxSessionTagValueLen = 200
+)
+
+var sessionTagKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// PutSessionTagsRequest is the request body for replacing a session's tags
+type PutSessionTagsRequest struct {
+	Tags        map[string]any `json:"tags"`
+	AnonymousID *string        `json:"anonymous_id,omitempty"`
+}
+
+// SessionTagsResponse is a session's tags after an update
+type SessionTagsResponse struct {
+	Tags map[string]string `json:"tags"`
+}
+
+// validateSessionTags checks tag count, key format and value types and lengths
+func validateSessionTags(raw map[string]any) (map[string]string, error) {
+	if len(raw) > maxSessionTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", maxSessionTags)
+	}
+	tags := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if len(k) > maxSessionTagKeyLen || !sessionTagKeyPattern.MatchString(k) {
+			return nil, fmt.Errorf("tag key %q must be 1-%d alphanumeric characters", k, maxSessionTagKeyLen)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("tag %q must have a string value", k)
+		}
+		if len(s) > maxSessionTagValueLen {
+			return nil, fmt.Errorf("tag %q value must be at most %d characters", k, maxSessionTagValueLen)
+		}
+		tags[k] = s
+	}
+	return tags, nil
+}
+
+// PutSessionTags handles PUT /api/sessions/{id}/tags - replaces all of a
+// session's tags with the ones in the request. Tags are not merged; an empty
+// object removes them all.
+func PutSessionTags(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid session ID")
+		return
+	}
+
+	var req PutSessionTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+	if req.Tags == nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "tags is required")
+		return
+	}
+	tags, err := validateSessionTags(req.Tags)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	account := GetAccountFromContext(ctx)
+
+	resp := SessionTagsResponse{}
+	if account != nil {
+		err = config.PgPool.QueryRow(ctx, `
+			UPDATE sessions SET tags = $2, updated_at = NOW()
+			WHERE id = $1 AND account_id = $3
+			RETURNING tags
+		`, id, tags, account.ID).Scan(&resp.Tags)
+	} else if req.AnonymousID != nil && *req.AnonymousID != "" {
+		err = config.PgPool.QueryRow(ctx, `
+			UPDATE sessions SET tags = $2, updated_at = NOW()
+			WHERE id = $1 AND anonymous_id = $3
+			RETURNING tags
+		`, id, tags, *req.AnonymousID).Scan(&resp.Tags)
+	} else {
+		err = pgx.ErrNoRows
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to update session tags", err))
+		return
+	}
+
+	writeJSON(w, resp)
+}
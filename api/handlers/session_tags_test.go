@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func putSessionTags(account *handlers.Account, sessionID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/api/sessions/"+sessionID+"/tags", strings.NewReader(body))
+	req = withChiURLParams(req, map[string]string{"id": sessionID})
+	if account != nil {
+		req = withAccount(req, account)
+	}
+	rr := httptest.NewRecorder()
+	handlers.PutSessionTags(rr, req)
+	return rr
+}
+
+func listTaggedSessions(t *testing.T, account *handlers.Account, query string) handlers.SessionListResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions?type=query"+query, nil)
+	req = withAccount(req, account)
+	rr := httptest.NewRecorder()
+	handlers.ListSessions(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.SessionListResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return resp
+}
+
+func TestSessionTags(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	planning, other := uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{planning, other} {
+		_, err := config.PgPool.Exec(ctx, `
+			INSERT INTO sessions (id, type, content, account_id) VALUES ($1, 'query', '[]', $2)
+		`, id, account.ID)
+		require.NoError(t, err)
+	}
+
+	rr := putSessionTags(account, planning.String(), `{"tags":{"project":"capacity-planning","env":"mainnet"}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = putSessionTags(account, other.String(), `{"tags":{"project":"outages"}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	resp := listTaggedSessions(t, account, "&tag_key=project&tag_value=capacity-planning")
+	require.Len(t, resp.Sessions, 1)
+	assert.Equal(t, planning, resp.Sessions[0].ID)
+	assert.Equal(t, map[string]string{"project": "capacity-planning", "env": "mainnet"}, resp.Sessions[0].Tags)
+	assert.Equal(t, 2, listTaggedSessions(t, account, "&tag_key=project").Total)
+	assert.Equal(t, 1, listTaggedSessions(t, account, "&tag_key=env").Total)
+
+	// Tags are replaced, not merged
+	rr = putSessionTags(account, planning.String(), `{"tags":{"owner":"netops"}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var tags handlers.SessionTagsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&tags))
+	assert.Equal(t, map[string]string{"owner": "netops"}, tags.Tags)
+	assert.Equal(t, 0, listTaggedSessions(t, account, "&tag_key=env").Total)
+}
+
+func TestSessionTags_Validation(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	owner := createTestAccount(t, ctx)
+	other := createTestAccount(t, ctx)
+	id := uuid.New()
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, content, account_id) VALUES ($1, 'query', '[]', $2)
+	`, id, owner.ID)
+	require.NoError(t, err)
+
+	tooMany := make(map[string]string)
+	for _, k := range strings.Split("a b c d e f g h i j k", " ") {
+		tooMany[k] = "v"
+	}
+	tooManyBody, err := json.Marshal(map[string]any{"tags": tooMany})
+	require.NoError(t, err)
+
+	for name, body := range map[string]string{
+		"missing tags":      `{}`,
+		"non-alphanumeric":  `{"tags":{"my-project":"x"}}`,
+		"long key":          `{"tags":{"` + strings.Repeat("k", 51) + `":"x"}}`,
+		"long value":        `{"tags":{"project":"` + strings.Repeat("v", 201) + `"}}`,
+		"non-string value":  `{"tags":{"project":1}}`,
+		"more than 10 tags": string(tooManyBody),
+	} {
+		assert.Equal(t, http.StatusBadRequest, putSessionTags(owner, id.String(), body).Code, name)
+	}
+
+	assert.Equal(t, http.StatusNotFound, putSessionTags(other, id.String(), `{"tags":{}}`).Code)
+	assert.Equal(t, http.StatusNotFound, putSessionTags(nil, id.String(), `{"tags":{}}`).Code)
+}
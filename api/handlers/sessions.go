@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -14,27 +15,29 @@ import (
 
 // Session represents a chat or query session
 type Session struct {
-	ID          uuid.UUID       `json:"id"`
-	Type        string          `json:"type"`
-	Name        *string         `json:"name"`
-	Content     json.RawMessage `json:"content"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	AccountID   *uuid.UUID      `json:"account_id,omitempty"`
-	AnonymousID *string         `json:"anonymous_id,omitempty"`
-	Env         *string         `json:"env,omitempty"` // Environment from first workflow run (for chat sessions)
+	ID          uuid.UUID         `json:"id"`
+	Type        string            `json:"type"`
+	Name        *string           `json:"name"`
+	Content     json.RawMessage   `json:"content"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	AccountID   *uuid.UUID        `json:"account_id,omitempty"`
+	AnonymousID *string           `json:"anonymous_id,omitempty"`
+	Env         *string           `json:"env,omitempty"` // Environment from first workflow run (for chat sessions)
+	Tags        map[string]string `json:"tags"`
 }
 
 // SessionListItem represents a session in list responses (without full content)
 type SessionListItem struct {
-	ID            uuid.UUID  `json:"id"`
-	Type          string     `json:"type"`
-	Name          *string    `json:"name"`
-	ContentLength int        `json:"content_length"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	AccountID     *uuid.UUID `json:"account_id,omitempty"`
-	AnonymousID   *string    `json:"anonymous_id,omitempty"`
+	ID            uuid.UUID         `json:"id"`
+	Type          string            `json:"type"`
+	Name          *string           `json:"name"`
+	ContentLength int               `json:"content_length"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	AccountID     *uuid.UUID        `json:"account_id,omitempty"`
+	AnonymousID   *string           `json:"anonymous_id,omitempty"`
+	Tags          map[string]string `json:"tags"`
 }
 
 // SessionListResponse is the response for listing sessions
@@ -75,11 +78,19 @@ type BatchGetSessionsResponse struct {
 	Sessions []Session `json:"sessions"`
 }
 
-// ListSessions returns a paginated list of sessions for the current user
+// ListSessions returns a paginated list of sessions for the current user.
+// tag_key limits it to sessions with that tag, and tag_value to sessions where
+// the tag has that value.
 func ListSessions(w http.ResponseWriter, r *http.Request) {
 	sessionType := r.URL.Query().Get("type")
 	if sessionType != "chat" && sessionType != "query" {
-		http.Error(w, "type query parameter must be 'chat' or 'query'", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "type query parameter must be 'chat' or 'query'")
+		return
+	}
+	tagKey := r.URL.Query().Get("tag_key")
+	tagValue := r.URL.Query().Get("tag_value")
+	if tagValue != "" && tagKey == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "tag_value requires tag_key")
 		return
 	}
 
@@ -115,13 +126,24 @@ func ListSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Tag filters use the containment operators so the GIN index applies
+	args := []any{sessionType, ownerArg}
+	if tagValue != "" {
+		ownerFilter += " AND tags @> jsonb_build_object($3::text, $4::text)"
+		args = append(args, tagKey, tagValue)
+	} else if tagKey != "" {
+		ownerFilter += " AND tags ? $3"
+		args = append(args, tagKey)
+	}
+	pageArgs := fmt.Sprintf("LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+
 	// Get total count
 	var total int
 	err := config.PgPool.QueryRow(ctx, fmt.Sprintf(`
 		SELECT COUNT(*) FROM sessions WHERE type = $1 AND %s
-	`, ownerFilter), sessionType, ownerArg).Scan(&total)
+	`, ownerFilter), args...).Scan(&total)
 	if err != nil {
-		http.Error(w, internalError("Failed to count sessions", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to count sessions", err))
 		return
 	}
 
@@ -130,14 +152,14 @@ func ListSessions(w http.ResponseWriter, r *http.Request) {
 	// If include_content is true, return full sessions
 	if includeContent {
 		rows, err := config.PgPool.Query(ctx, fmt.Sprintf(`
-			SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id
+			SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id, tags
 			FROM sessions
 			WHERE type = $1 AND %s
 			ORDER BY updated_at DESC, id ASC
-			LIMIT $3 OFFSET $4
-		`, ownerFilter), sessionType, ownerArg, limit, offset)
+			%s
+		`, ownerFilter, pageArgs), append(args, limit, offset)...)
 		if err != nil {
-			http.Error(w, internalError("Failed to list sessions", err), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to list sessions", err))
 			return
 		}
 		defer rows.Close()
@@ -145,15 +167,15 @@ func ListSessions(w http.ResponseWriter, r *http.Request) {
 		sessions := []Session{}
 		for rows.Next() {
 			var s Session
-			if err := rows.Scan(&s.ID, &s.Type, &s.Name, &s.Content, &s.CreatedAt, &s.UpdatedAt, &s.AccountID, &s.AnonymousID); err != nil {
-				http.Error(w, internalError("Failed to scan session", err), http.StatusInternalServerError)
+			if err := rows.Scan(&s.ID, &s.Type, &s.Name, &s.Content, &s.CreatedAt, &s.UpdatedAt, &s.AccountID, &s.AnonymousID, &s.Tags); err != nil {
+				writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to scan session", err))
 				return
 			}
 			sessions = append(sessions, s)
 		}
 
 		if err := rows.Err(); err != nil {
-			http.Error(w, internalError("Failed to iterate sessions", err), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to iterate sessions", err))
 			return
 		}
 
@@ -169,14 +191,14 @@ func ListSessions(w http.ResponseWriter, r *http.Request) {
 	// Get sessions without content
 	rows, err := config.PgPool.Query(ctx, fmt.Sprintf(`
 		SELECT id, type, name, jsonb_array_length(content) as content_length,
-		       created_at, updated_at, account_id, anonymous_id
+		       created_at, updated_at, account_id, anonymous_id, tags
 		FROM sessions
 		WHERE type = $1 AND %s
 		ORDER BY updated_at DESC, id ASC
-		LIMIT $3 OFFSET $4
-	`, ownerFilter), sessionType, ownerArg, limit, offset)
+		%s
+	`, ownerFilter, pageArgs), append(args, limit, offset)...)
 	if err != nil {
-		http.Error(w, internalError("Failed to list sessions", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to list sessions", err))
 		return
 	}
 	defer rows.Close()
@@ -184,15 +206,15 @@ func ListSessions(w http.ResponseWriter, r *http.Request) {
 	sessions := []SessionListItem{}
 	for rows.Next() {
 		var s SessionListItem
-		if err := rows.Scan(&s.ID, &s.Type, &s.Name, &s.ContentLength, &s.CreatedAt, &s.UpdatedAt, &s.AccountID, &s.AnonymousID); err != nil {
-			http.Error(w, internalError("Failed to scan session", err), http.StatusInternalServerError)
+		if err := rows.Scan(&s.ID, &s.Type, &s.Name, &s.ContentLength, &s.CreatedAt, &s.UpdatedAt, &s.AccountID, &s.AnonymousID, &s.Tags); err != nil {
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to scan session", err))
 			return
 		}
 		sessions = append(sessions, s)
 	}
 
 	if err := rows.Err(); err != nil {
-		http.Error(w, internalError("Failed to iterate sessions", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to iterate sessions", err))
 		return
 	}
 
@@ -214,7 +236,7 @@ type BatchGetSessionsRequestWithOwner struct {
 func BatchGetSessions(w http.ResponseWriter, r *http.Request) {
 	var req BatchGetSessionsRequestWithOwner
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
@@ -244,14 +266,14 @@ func BatchGetSessions(w http.ResponseWriter, r *http.Request) {
 
 	if account != nil {
 		rows, err = config.PgPool.Query(ctx, `
-			SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id
+			SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id, tags
 			FROM sessions
 			WHERE id = ANY($1) AND account_id = $2
 			ORDER BY updated_at DESC, id ASC
 		`, req.IDs, account.ID)
 	} else if req.AnonymousID != nil && *req.AnonymousID != "" {
 		rows, err = config.PgPool.Query(ctx, `
-			SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id
+			SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id, tags
 			FROM sessions
 			WHERE id = ANY($1) AND anonymous_id = $2
 			ORDER BY updated_at DESC, id ASC
@@ -263,7 +285,7 @@ func BatchGetSessions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		http.Error(w, internalError("Failed to fetch sessions", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to fetch sessions", err))
 		return
 	}
 	defer rows.Close()
@@ -271,15 +293,15 @@ func BatchGetSessions(w http.ResponseWriter, r *http.Request) {
 	sessions := []Session{}
 	for rows.Next() {
 		var s Session
-		if err := rows.Scan(&s.ID, &s.Type, &s.Name, &s.Content, &s.CreatedAt, &s.UpdatedAt, &s.AccountID, &s.AnonymousID); err != nil {
-			http.Error(w, internalError("Failed to scan session", err), http.StatusInternalServerError)
+		if err := rows.Scan(&s.ID, &s.Type, &s.Name, &s.Content, &s.CreatedAt, &s.UpdatedAt, &s.AccountID, &s.AnonymousID, &s.Tags); err != nil {
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to scan session", err))
 			return
 		}
 		sessions = append(sessions, s)
 	}
 
 	if err := rows.Err(); err != nil {
-		http.Error(w, internalError("Failed to iterate sessions", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to iterate sessions", err))
 		return
 	}
 
@@ -292,7 +314,7 @@ func GetSession(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid session ID")
 		return
 	}
 
@@ -304,32 +326,32 @@ func GetSession(w http.ResponseWriter, r *http.Request) {
 
 	var session Session
 	err = config.PgPool.QueryRow(ctx, `
-		SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id
+		SELECT id, type, name, content, created_at, updated_at, account_id, anonymous_id, tags
 		FROM sessions WHERE id = $1
-	`, id).Scan(&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID)
+	`, id).Scan(&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID, &session.Tags)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
-			http.Error(w, "Session not found", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 			return
 		}
-		http.Error(w, internalError("Failed to get session", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to get session", err))
 		return
 	}
 
 	// Check ownership
 	if account != nil {
 		if session.AccountID == nil || *session.AccountID != account.ID {
-			http.Error(w, "Session not found", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 			return
 		}
 	} else if anonymousID != "" {
 		if session.AnonymousID == nil || *session.AnonymousID != anonymousID {
-			http.Error(w, "Session not found", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 			return
 		}
 	} else {
 		// No owner context - deny access
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 		return
 	}
 
@@ -357,17 +379,17 @@ type CreateSessionRequestWithOwner struct {
 func CreateSession(w http.ResponseWriter, r *http.Request) {
 	var req CreateSessionRequestWithOwner
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
 	if req.ID == uuid.Nil {
-		http.Error(w, "id is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "id is required")
 		return
 	}
 
 	if req.Type != "chat" && req.Type != "query" {
-		http.Error(w, "type must be 'chat' or 'query'", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "type must be 'chat' or 'query'")
 		return
 	}
 
@@ -387,7 +409,7 @@ func CreateSession(w http.ResponseWriter, r *http.Request) {
 	} else if req.AnonymousID != nil && *req.AnonymousID != "" {
 		anonymousID = req.AnonymousID
 	} else {
-		http.Error(w, "Authentication or anonymous_id required", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication or anonymous_id required")
 		return
 	}
 
@@ -395,17 +417,17 @@ func CreateSession(w http.ResponseWriter, r *http.Request) {
 	err := config.PgPool.QueryRow(ctx, `
 		INSERT INTO sessions (id, type, name, content, account_id, anonymous_id)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, type, name, content, created_at, updated_at, account_id, anonymous_id
+		RETURNING id, type, name, content, created_at, updated_at, account_id, anonymous_id, tags
 	`, req.ID, req.Type, req.Name, req.Content, accountID, anonymousID).Scan(
-		&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID,
+		&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID, &session.Tags,
 	)
 	if err != nil {
 		// Check for duplicate key error
 		if err.Error() == `ERROR: duplicate key value violates unique constraint "sessions_pkey" (SQLSTATE 23505)` {
-			http.Error(w, "Session already exists", http.StatusConflict)
+			writeError(w, r, http.StatusConflict, ErrCodeConflict, "Session already exists")
 			return
 		}
-		http.Error(w, internalError("Failed to create session", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to create session", err))
 		return
 	}
 
@@ -426,13 +448,13 @@ func UpdateSession(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid session ID")
 		return
 	}
 
 	var req UpdateSessionRequestWithOwner
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
@@ -453,9 +475,9 @@ func UpdateSession(w http.ResponseWriter, r *http.Request) {
 			UPDATE sessions
 			SET name = $2, content = $3, account_id = $4, updated_at = NOW()
 			WHERE id = $1 AND (account_id = $4 OR (account_id IS NULL AND anonymous_id IS NULL))
-			RETURNING id, type, name, content, created_at, updated_at, account_id, anonymous_id
+			RETURNING id, type, name, content, created_at, updated_at, account_id, anonymous_id, tags
 		`, id, req.Name, req.Content, account.ID).Scan(
-			&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID,
+			&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID, &session.Tags,
 		)
 	} else if req.AnonymousID != nil && *req.AnonymousID != "" {
 		// Anonymous user - update if owned by them OR if orphaned (claim it)
@@ -463,20 +485,20 @@ func UpdateSession(w http.ResponseWriter, r *http.Request) {
 			UPDATE sessions
 			SET name = $2, content = $3, anonymous_id = $4, updated_at = NOW()
 			WHERE id = $1 AND (anonymous_id = $4 OR (account_id IS NULL AND anonymous_id IS NULL))
-			RETURNING id, type, name, content, created_at, updated_at, account_id, anonymous_id
+			RETURNING id, type, name, content, created_at, updated_at, account_id, anonymous_id, tags
 		`, id, req.Name, req.Content, *req.AnonymousID).Scan(
-			&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID,
+			&session.ID, &session.Type, &session.Name, &session.Content, &session.CreatedAt, &session.UpdatedAt, &session.AccountID, &session.AnonymousID, &session.Tags,
 		)
 	} else {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 		return
 	}
 	if err != nil {
 		if err.Error() == "no rows in result set" {
-			http.Error(w, "Session not found", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 			return
 		}
-		http.Error(w, internalError("Failed to update session", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to update session", err))
 		return
 	}
 
@@ -489,7 +511,7 @@ func DeleteSession(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid session ID")
 		return
 	}
 
@@ -505,19 +527,121 @@ func DeleteSession(w http.ResponseWriter, r *http.Request) {
 	} else if anonymousID != "" {
 		result, err = config.PgPool.Exec(ctx, `DELETE FROM sessions WHERE id = $1 AND anonymous_id = $2`, id, anonymousID)
 	} else {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 		return
 	}
 
 	if err != nil {
-		http.Error(w, internalError("Failed to delete session", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to delete session", err))
 		return
 	}
 
 	if result.RowsAffected() == 0 {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeSessionNotFound, "Session not found")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// BulkDeleteSessionsRequest is the request body for bulk deleting sessions.
+// At least one filter must be set.
+type BulkDeleteSessionsRequest struct {
+	OlderThan   *time.Time `json:"olderThan,omitempty"`   // sessions last updated before this time
+	UserPK      string     `json:"userPK,omitempty"`      // owning account ID; admins only for other accounts
+	Status      string     `json:"status,omitempty"`      // status of the session's latest workflow run
+	AllAccounts bool       `json:"allAccounts,omitempty"` // admins only, in place of userPK
+}
+
+// BulkDeleteSessionsResponse is the response for bulk deleting sessions
+type BulkDeleteSessionsResponse struct {
+	DeletedCount int64 `json:"deletedCount"`
+}
+
+// BulkDeleteSessions deletes all sessions matching the given filters in a single
+// statement. Non-admins can only delete their own sessions; admins must target
+// an account via userPK or explicitly set allAccounts.
+func BulkDeleteSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	account := GetAccountFromContext(ctx)
+	if account == nil {
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var req BulkDeleteSessionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+	if req.OlderThan == nil && req.UserPK == "" && req.Status == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "At least one of olderThan, userPK or status is required")
+		return
+	}
+	if req.Status != "" && req.Status != "completed" && req.Status != "failed" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "status must be 'completed' or 'failed'")
+		return
+	}
+
+	var ownerID uuid.UUID
+	if req.UserPK != "" {
+		id, err := uuid.Parse(req.UserPK)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid userPK")
+			return
+		}
+		ownerID = id
+	}
+
+	conditions := []string{}
+	var args []any
+	if IsAdmin(account) {
+		if req.UserPK != "" && req.AllAccounts {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "userPK and allAccounts can't both be set")
+			return
+		}
+		if req.UserPK == "" && !req.AllAccounts {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "userPK or allAccounts is required")
+			return
+		}
+		if req.UserPK != "" {
+			args = append(args, ownerID)
+			conditions = append(conditions, fmt.Sprintf("account_id = $%d", len(args)))
+		}
+	} else {
+		if req.AllAccounts {
+			writeError(w, r, http.StatusForbidden, ErrCodeForbidden, "Cannot delete other users' sessions")
+			return
+		}
+		if req.UserPK != "" && ownerID != account.ID {
+			writeError(w, r, http.StatusForbidden, ErrCodeForbidden, "Cannot delete another user's sessions")
+			return
+		}
+		args = append(args, account.ID)
+		conditions = append(conditions, fmt.Sprintf("account_id = $%d", len(args)))
+	}
+	if req.OlderThan != nil {
+		args = append(args, *req.OlderThan)
+		conditions = append(conditions, fmt.Sprintf("updated_at < $%d", len(args)))
+	}
+	if req.Status != "" {
+		args = append(args, req.Status)
+		conditions = append(conditions, fmt.Sprintf(`(
+			SELECT wr.status FROM workflow_runs wr
+			WHERE wr.session_id = sessions.id
+			ORDER BY wr.started_at DESC
+			LIMIT 1
+		) = $%d`, len(args)))
+	}
+
+	result, err := config.PgPool.Exec(ctx,
+		"DELETE FROM sessions WHERE "+strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to delete sessions", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(BulkDeleteSessionsResponse{DeletedCount: result.RowsAffected()})
+}
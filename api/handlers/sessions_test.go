@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -302,6 +303,168 @@ func TestDeleteSession(t *testing.T) {
 	assert.Equal(t, 0, count)
 }
 
+// bulkDeleteSessions issues DELETE /api/sessions as account with the given body
+func bulkDeleteSessions(account *handlers.Account, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/api/sessions", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req = withAccount(req, account)
+
+	rr := httptest.NewRecorder()
+	handlers.BulkDeleteSessions(rr, req)
+	return rr
+}
+
+func TestBulkDeleteSessions(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+
+	oldID, newID, failedID := uuid.New(), uuid.New(), uuid.New()
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, name, content, account_id, updated_at)
+		VALUES
+			($1, 'chat', 'Old', '[]', $4, NOW() - INTERVAL '30 days'),
+			($2, 'chat', 'New', '[]', $4, NOW()),
+			($3, 'chat', 'Failed', '[]', $4, NOW())
+	`, oldID, newID, failedID, account.ID)
+	require.NoError(t, err)
+	_, err = config.PgPool.Exec(ctx, `
+		INSERT INTO workflow_runs (session_id, status, user_question, started_at)
+		VALUES
+			($1, 'completed', 'q1', NOW() - INTERVAL '1 hour'),
+			($1, 'failed', 'q2', NOW()),
+			($2, 'failed', 'q3', NOW() - INTERVAL '1 hour'),
+			($2, 'completed', 'q4', NOW())
+	`, failedID, newID)
+	require.NoError(t, err)
+
+	olderThan := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	rr := bulkDeleteSessions(account, `{"olderThan": "`+olderThan+`"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.BulkDeleteSessionsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, int64(1), resp.DeletedCount)
+
+	// Only failedID's latest run failed
+	rr = bulkDeleteSessions(account, `{"status": "failed"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, int64(1), resp.DeletedCount)
+
+	var remaining []uuid.UUID
+	rows, err := config.PgPool.Query(ctx, "SELECT id FROM sessions WHERE account_id = $1", account.ID)
+	require.NoError(t, err)
+	for rows.Next() {
+		var id uuid.UUID
+		require.NoError(t, rows.Scan(&id))
+		remaining = append(remaining, id)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []uuid.UUID{newID}, remaining)
+}
+
+func TestBulkDeleteSessions_OtherUser(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	owner := createTestAccount(t, ctx)
+	attacker := createTestAccount(t, ctx)
+
+	sessionID := uuid.New()
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, name, content, account_id, updated_at)
+		VALUES ($1, 'chat', 'Owned', '[]', $2, NOW() - INTERVAL '30 days')
+	`, sessionID, owner.ID)
+	require.NoError(t, err)
+
+	rr := bulkDeleteSessions(attacker, `{"userPK": "`+owner.ID.String()+`"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = bulkDeleteSessions(attacker, `{"olderThan": "`+time.Now().UTC().Format(time.RFC3339)+`", "allAccounts": true}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Without userPK the delete is scoped to the attacker's own sessions
+	olderThan := time.Now().UTC().Format(time.RFC3339)
+	rr = bulkDeleteSessions(attacker, `{"olderThan": "`+olderThan+`"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.BulkDeleteSessionsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, int64(0), resp.DeletedCount)
+
+	var count int
+	err = config.PgPool.QueryRow(ctx, "SELECT COUNT(*) FROM sessions WHERE id = $1", sessionID).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestBulkDeleteSessions_Admin(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+	t.Setenv("AUTH_ADMIN_EMAILS", "admin@example.com")
+
+	owner := createTestAccount(t, ctx)
+	admin := createTestAccount(t, ctx)
+	email := "admin@example.com"
+	admin.Email = &email
+
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, name, content, account_id)
+		VALUES ($1, 'chat', 'Owned', '[]', $2)
+	`, uuid.New(), owner.ID)
+	require.NoError(t, err)
+
+	rr := bulkDeleteSessions(admin, `{"userPK": "`+owner.ID.String()+`"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.BulkDeleteSessionsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, int64(1), resp.DeletedCount)
+}
+
+func TestBulkDeleteSessions_AdminRequiresScope(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+	t.Setenv("AUTH_ADMIN_EMAILS", "admin@example.com")
+
+	owner := createTestAccount(t, ctx)
+	admin := createTestAccount(t, ctx)
+	email := "admin@example.com"
+	admin.Email = &email
+
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO sessions (id, type, name, content, account_id, updated_at)
+		VALUES ($1, 'chat', 'Owned', '[]', $2, NOW() - INTERVAL '30 days')
+	`, uuid.New(), owner.ID)
+	require.NoError(t, err)
+
+	olderThan := time.Now().UTC().Format(time.RFC3339)
+	rr := bulkDeleteSessions(admin, `{"olderThan": "`+olderThan+`"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+
+	rr = bulkDeleteSessions(admin, `{"olderThan": "`+olderThan+`", "userPK": "`+owner.ID.String()+`", "allAccounts": true}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+
+	var count int
+	err = config.PgPool.QueryRow(ctx, "SELECT COUNT(*) FROM sessions WHERE account_id = $1", owner.ID).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	rr = bulkDeleteSessions(admin, `{"olderThan": "`+olderThan+`", "allAccounts": true}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp handlers.BulkDeleteSessionsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, int64(1), resp.DeletedCount)
+}
+
+func TestBulkDeleteSessions_BadRequest(t *testing.T) {
+	account := &handlers.Account{ID: uuid.New()}
+	for _, body := range []string{`{}`, `not json`, `{"status": "running"}`, `{"userPK": "nope"}`, `{"olderThan": "yesterday"}`} {
+		rr := bulkDeleteSessions(account, body)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
 func TestListSessions_Pagination(t *testing.T) {
 	apitesting.SetupTestDB(t, testPgDB)
 	ctx := t.Context()
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 	"github.com/malbeclabs/lake/api/config"
 )
 
@@ -99,21 +100,6 @@ func UpsertSlackInstallation(ctx context.Context, teamID, teamName, botToken, bo
 	return err
 }
 
-// DeactivateSlackInstallation deactivates a Slack installation by team ID
-func DeactivateSlackInstallation(ctx context.Context, teamID string) error {
-	cmdTag, err := config.PgPool.Exec(ctx,
-		`UPDATE slack_installations SET is_active = false, updated_at = NOW() WHERE team_id = $1`,
-		teamID,
-	)
-	if err != nil {
-		return err
-	}
-	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("no slack installation found for team_id %s", teamID)
-	}
-	return nil
-}
-
 // ListSlackInstallations returns active installations for a specific account
 func ListSlackInstallations(ctx context.Context, accountID string) ([]SlackInstallation, error) {
 	rows, err := config.PgPool.Query(ctx,
@@ -155,20 +141,20 @@ func GetSlackInstallationByTeamID(ctx context.Context, teamID string) (*SlackIns
 func GetSlackOAuthStart(w http.ResponseWriter, r *http.Request) {
 	account := GetAccountFromContext(r.Context())
 	if account == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
 		return
 	}
 
 	clientID := os.Getenv("SLACK_CLIENT_ID")
 	if clientID == "" {
-		http.Error(w, "Slack OAuth not configured", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Slack OAuth not configured")
 		return
 	}
 
 	state, err := CreateOAuthState(r.Context(), account.ID.String())
 	if err != nil {
 		slog.Error("failed to create oauth state", "error", err)
-		http.Error(w, "Internal error", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
 		return
 	}
 
@@ -345,14 +331,14 @@ func exchangeSlackCode(ctx context.Context, clientID, clientSecret, code, redire
 func GetSlackInstallations(w http.ResponseWriter, r *http.Request) {
 	account := GetAccountFromContext(r.Context())
 	if account == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
 		return
 	}
 
 	installations, err := ListSlackInstallations(r.Context(), account.ID.String())
 	if err != nil {
 		slog.Error("failed to list slack installations", "error", err)
-		http.Error(w, "Internal error", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
 		return
 	}
 
@@ -364,56 +350,72 @@ func GetSlackInstallations(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(installations)
 }
 
-// DeleteSlackInstallation uninstalls the app from the Slack workspace and deactivates the installation
+// DeleteSlackInstallation removes a Slack installation. Only the installer can remove it.
 func DeleteSlackInstallation(w http.ResponseWriter, r *http.Request) {
 	account := GetAccountFromContext(r.Context())
 	if account == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
 		return
 	}
 
 	teamID := chi.URLParam(r, "team_id")
 	if teamID == "" {
-		http.Error(w, "team_id is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "team_id is required")
 		return
 	}
 
-	// Fetch the installation to get the bot token for uninstall
 	inst, err := GetSlackInstallationByTeamID(r.Context(), teamID)
 	if err != nil {
 		slog.Error("failed to get slack installation for uninstall", "error", err, "team_id", teamID)
-		http.Error(w, "Installation not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeInstallationNotFound, "Installation not found")
 		return
 	}
 
 	// Only the installer can disconnect
 	if inst.InstalledBy == nil || *inst.InstalledBy != account.ID.String() {
-		http.Error(w, "Not authorized to disconnect this installation", http.StatusForbidden)
+		writeError(w, r, http.StatusForbidden, ErrCodeForbidden, "Not authorized to disconnect this installation")
 		return
 	}
 
-	// Uninstall the app from the Slack workspace
-	clientID := os.Getenv("SLACK_CLIENT_ID")
-	clientSecret := os.Getenv("SLACK_CLIENT_SECRET")
-	if clientID != "" && clientSecret != "" {
-		if err := uninstallSlackApp(r.Context(), inst.BotToken, clientID, clientSecret); err != nil {
-			slog.Error("failed to uninstall slack app from workspace", "error", err, "team_id", teamID)
-			http.Error(w, "Failed to uninstall Slack app from workspace. Please try again.", http.StatusBadGateway)
-			return
-		}
+	revoked, err := CleanupSlackInstallation(r.Context(), inst)
+	if err != nil {
+		slog.Error("failed to remove slack installation", "error", err, "team_id", teamID)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
+		return
 	}
 
-	if err := DeactivateSlackInstallation(r.Context(), teamID); err != nil {
-		slog.Error("failed to deactivate slack installation", "error", err)
-		http.Error(w, "Internal error", http.StatusInternalServerError)
-		return
+	slog.Info("slack installation removed", "team_id", teamID, "by_account", account.ID.String(), "revoked_token", revoked)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CleanupSlackInstallation revokes the installation's bot token and deletes the
+// installation along with any pending takeovers for the team, then drops the
+// bot's cached client. A failed revocation (e.g. the token is already invalid)
+// is logged and doesn't stop the local cleanup; it is reported as revoked=false.
+func CleanupSlackInstallation(ctx context.Context, inst *SlackInstallation) (revoked bool, err error) {
+	if err := revokeSlackToken(ctx, inst.BotToken); err != nil {
+		slog.Warn("failed to revoke slack bot token, removing installation anyway", "error", err, "team_id", inst.TeamID)
+	} else {
+		revoked = true
+	}
+
+	err = pgx.BeginFunc(ctx, config.PgPool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM slack_pending_installations WHERE team_id = $1`, inst.TeamID); err != nil {
+			return fmt.Errorf("failed to delete pending installations: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM slack_installations WHERE team_id = $1`, inst.TeamID); err != nil {
+			return fmt.Errorf("failed to delete installation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return revoked, err
 	}
 
 	if OnSlackInstallationChange != nil {
-		OnSlackInstallationChange(teamID)
+		OnSlackInstallationChange(inst.TeamID)
 	}
-	slog.Info("slack installation removed", "team_id", teamID, "by_account", account.ID.String())
-	w.WriteHeader(http.StatusNoContent)
+	return revoked, nil
 }
 
 // CreatePendingInstallation stores token data for a pending takeover confirmation
@@ -439,13 +441,13 @@ func CreatePendingInstallation(ctx context.Context, accountID string, tokenResp
 func ConfirmSlackInstallation(w http.ResponseWriter, r *http.Request) {
 	account := GetAccountFromContext(r.Context())
 	if account == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required")
 		return
 	}
 
 	pendingID := chi.URLParam(r, "pending_id")
 	if pendingID == "" {
-		http.Error(w, "pending_id is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "pending_id is required")
 		return
 	}
 
@@ -459,7 +461,7 @@ func ConfirmSlackInstallation(w http.ResponseWriter, r *http.Request) {
 	).Scan(&teamID, &teamName, &botToken, &botUserID, &scope)
 	if err != nil {
 		slog.Warn("invalid or expired pending installation", "error", err, "pending_id", pendingID)
-		http.Error(w, "Pending installation not found or expired", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeInstallationNotFound, "Pending installation not found or expired")
 		return
 	}
 
@@ -467,7 +469,7 @@ func ConfirmSlackInstallation(w http.ResponseWriter, r *http.Request) {
 	err = UpsertSlackInstallation(r.Context(), teamID, teamName, botToken, botUserID, scope, account.ID.String())
 	if err != nil {
 		slog.Error("failed to store slack installation from pending", "error", err)
-		http.Error(w, "Internal error", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
 		return
 	}
 
@@ -480,16 +482,13 @@ func ConfirmSlackInstallation(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "installed", "team_id": teamID, "team_name": teamName})
 }
 
-// uninstallSlackApp calls apps.uninstall to fully remove the app from a workspace
-func uninstallSlackApp(ctx context.Context, botToken, clientID, clientSecret string) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://slack.com/api/apps.uninstall", nil)
+// revokeSlackToken calls auth.revoke to invalidate a bot token. Revoking the
+// last token of an installation also removes the app from the workspace.
+func revokeSlackToken(ctx context.Context, botToken string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://slack.com/api/auth.revoke", nil)
 	if err != nil {
 		return err
 	}
-	q := req.URL.Query()
-	q.Set("client_id", clientID)
-	q.Set("client_secret", clientSecret)
-	req.URL.RawQuery = q.Encode()
 	req.Header.Set("Authorization", "Bearer "+botToken)
 
 	resp, err := http.DefaultClient.Do(req)
@@ -506,7 +505,7 @@ func uninstallSlackApp(ctx context.Context, botToken, clientID, clientSecret str
 		return err
 	}
 	if !result.OK {
-		return fmt.Errorf("slack apps.uninstall failed: %s", result.Error)
+		return fmt.Errorf("slack auth.revoke failed: %s", result.Error)
 	}
 	return nil
 }
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
+	"golang.org/x/time/rate"
+)
+
+// SQLValidateRateLimiter is the rate limiter for SQL validation, keyed by IP.
+// Validation only plans the query, so it allows 600 requests per minute per IP
+// with a burst of 60, well above the query execution limits.
+var SQLValidateRateLimiter = NewRateLimiter(rate.Limit(10), 60)
+
+// SQLErrorPosition is a 1-based location in a query
+type SQLErrorPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// SQLValidateResponse is the response for the SQL validate endpoint
+type SQLValidateResponse struct {
+	Valid    bool              `json:"valid"`
+	Error    string            `json:"error,omitempty"`
+	Position *SQLErrorPosition `json:"position,omitempty"`
+}
+
+var (
+	// "(line 2, col 13)" in ClickHouse syntax errors
+	sqlErrorLineColRe = regexp.MustCompile(`\(line (\d+), col (\d+)\)`)
+	// "failed at position 42" in ClickHouse syntax errors, a 1-based character offset
+	sqlErrorOffsetRe = regexp.MustCompile(`failed at position (\d+)`)
+	// Statements that only read data
+	readOnlyQueryRe = regexp.MustCompile(`(?i)^\(*\s*(SELECT|WITH)\b`)
+)
+
+// isReadOnlyQuery reports whether the query is a SELECT (optionally with a WITH clause)
+func isReadOnlyQuery(query string) bool {
+	return readOnlyQueryRe.MatchString(query)
+}
+
+// parseSQLErrorPosition extracts the error location from a ClickHouse error
+// message, falling back to converting the character offset into a line and
+// column of query. Returns nil if the message has no position.
+func parseSQLErrorPosition(query, message string) *SQLErrorPosition {
+	if m := sqlErrorLineColRe.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		col, _ := strconv.Atoi(m[2])
+		return &SQLErrorPosition{Line: line, Column: col}
+	}
+	m := sqlErrorOffsetRe.FindStringSubmatch(message)
+	if m == nil {
+		return nil
+	}
+	offset, _ := strconv.Atoi(m[1])
+	pos := &SQLErrorPosition{Line: 1, Column: 1}
+	for i, ch := range []rune(query) {
+		if i >= offset-1 {
+			break
+		}
+		if ch == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
+
+// ValidateQuery checks that a SQL query parses and plans by running EXPLAIN on
+// it, without executing it. Only SELECT queries are accepted.
+func ValidateQuery(w http.ResponseWriter, r *http.Request) {
+	var req QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
+		return
+	}
+
+	query := strings.TrimSuffix(strings.TrimSpace(req.Query), ";")
+	if query == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Query is required")
+		return
+	}
+
+	if !isReadOnlyQuery(query) {
+		writeJSON(w, SQLValidateResponse{Error: "Only SELECT queries are allowed"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	// Like ExecuteQuery, validation runs against the mainnet database.
+	start := time.Now()
+	rows, err := config.DB.Query(ctx, "EXPLAIN "+query)
+	if err == nil {
+		err = rows.Err()
+		rows.Close()
+	}
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		writeJSON(w, SQLValidateResponse{
+			Error:    err.Error(),
+			Position: parseSQLErrorPosition(query, err.Error()),
+		})
+		return
+	}
+
+	writeJSON(w, SQLValidateResponse{Valid: true})
+}
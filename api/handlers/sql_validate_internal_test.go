@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSQLErrorPosition(t *testing.T) {
+	t.Parallel()
+
+	query := "SELECT id\nFORM t"
+
+	pos := parseSQLErrorPosition(query, "code: 62, message: Syntax error: failed at position 11 ('FORM') (line 2, col 1): FORM t.")
+	assert.Equal(t, &SQLErrorPosition{Line: 2, Column: 1}, pos)
+
+	pos = parseSQLErrorPosition(query, "code: 62, message: Syntax error: failed at position 11 ('FORM'): FORM t.")
+	assert.Equal(t, &SQLErrorPosition{Line: 2, Column: 1}, pos)
+
+	pos = parseSQLErrorPosition(query, "code: 62, message: Syntax error: failed at position 8 ('id')")
+	assert.Equal(t, &SQLErrorPosition{Line: 1, Column: 8}, pos)
+
+	assert.Nil(t, parseSQLErrorPosition(query, "code: 60, message: Unknown table expression identifier 't'"))
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isReadOnlyQuery("SELECT 1"))
+	assert.True(t, isReadOnlyQuery("select 1"))
+	assert.True(t, isReadOnlyQuery("WITH x AS (SELECT 1) SELECT * FROM x"))
+	assert.True(t, isReadOnlyQuery("(SELECT 1) UNION ALL (SELECT 2)"))
+	assert.False(t, isReadOnlyQuery("SELECTED"))
+	assert.False(t, isReadOnlyQuery("DROP TABLE t"))
+	assert.False(t, isReadOnlyQuery("INSERT INTO t SELECT 1"))
+	assert.False(t, isReadOnlyQuery("TRUNCATE TABLE t"))
+}
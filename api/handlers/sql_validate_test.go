@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validateQuery(t *testing.T, query string) handlers.SQLValidateResponse {
+	t.Helper()
+	body, _ := json.Marshal(handlers.QueryRequest{Query: query})
+	req := httptest.NewRequest(http.MethodPost, "/api/sql/validate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handlers.ValidateQuery(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.SQLValidateResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return resp
+}
+
+func TestValidateQuery(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS test_query_validate (id UInt64) ENGINE = Memory
+	`))
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO test_query_validate (id) VALUES (1)`))
+
+	resp := validateQuery(t, "SELECT id FROM test_query_validate;")
+	assert.True(t, resp.Valid)
+	assert.Empty(t, resp.Error)
+	assert.Nil(t, resp.Position)
+
+	resp = validateQuery(t, "SELECT id\nFORM test_query_validate")
+	assert.False(t, resp.Valid)
+	assert.Contains(t, resp.Error, "Syntax error")
+	require.NotNil(t, resp.Position)
+	assert.Equal(t, 2, resp.Position.Line)
+
+	resp = validateQuery(t, "SELECT * FROM does_not_exist")
+	assert.False(t, resp.Valid)
+	assert.NotEmpty(t, resp.Error)
+
+	// Validation doesn't execute the query
+	var count uint64
+	require.NoError(t, config.DB.QueryRow(ctx, "SELECT count() FROM test_query_validate").Scan(&count))
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestValidateQuery_RejectsNonSelect(t *testing.T) {
+	for _, q := range []string{
+		"DROP TABLE test_query_validate",
+		"INSERT INTO test_query_validate VALUES (2)",
+		"ALTER TABLE test_query_validate DELETE WHERE 1",
+	} {
+		resp := validateQuery(t, q)
+		assert.False(t, resp.Valid, q)
+		assert.Equal(t, "Only SELECT queries are allowed", resp.Error, q)
+	}
+}
+
+func TestValidateQuery_EmptyQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/sql/validate", bytes.NewReader([]byte(`{"query": "  "}`)))
+	rr := httptest.NewRecorder()
+	handlers.ValidateQuery(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/malbeclabs/lake/api/metrics"
@@ -194,7 +194,7 @@ func GetStakeOverview(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Stake overview query error: %v", err)
+		LoggerFromContext(ctx).Error("Stake overview query error", "error", err)
 		overview.Error = err.Error()
 	}
 
@@ -209,7 +209,7 @@ func GetStakeOverview(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(overview); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -292,7 +292,7 @@ func GetStakeHistory(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Stake history query error: %v", err)
+		LoggerFromContext(ctx).Error("Stake history query error", "error", err)
 		response.Error = err.Error()
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(response)
@@ -303,28 +303,22 @@ func GetStakeHistory(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var point StakeHistoryPoint
 		if err := rows.Scan(&point.Timestamp, &point.DZStakeSol, &point.TotalStakeSol, &point.StakeSharePct); err != nil {
-			log.Printf("Stake history row scan error: %v", err)
-			response.Error = fmt.Sprintf("row scan error: %v", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			_ = json.NewEncoder(w).Encode(response)
+			LoggerFromContext(ctx).Error("Stake history row scan error", "error", err)
+			writeDBError(w, r, err)
 			return
 		}
 		response.Points = append(response.Points, point)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Stake history rows error: %v", err)
-		response.Error = err.Error()
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(response)
+		LoggerFromContext(ctx).Error("Stake history rows error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -524,7 +518,7 @@ func GetStakeChanges(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Stake changes query error: %v", err)
+		LoggerFromContext(ctx).Error("Stake changes query error", "error", err)
 		response.Error = err.Error()
 	}
 
@@ -544,7 +538,7 @@ func GetStakeChanges(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -605,7 +599,7 @@ func GetStakeValidators(w http.ResponseWriter, r *http.Request) {
 		WHERE epoch_vote_account = 'true' AND activated_stake_lamports > 0
 	`).Scan(&totalStake)
 	if err != nil {
-		log.Printf("Total stake query error: %v", err)
+		LoggerFromContext(ctx).Error("Total stake query error", "error", err)
 		response.Error = err.Error()
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(response)
@@ -709,7 +703,7 @@ func GetStakeValidators(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Stake validators query error: %v", err)
+		LoggerFromContext(ctx).Error("Stake validators query error", "error", err)
 		response.Error = err.Error()
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(response)
@@ -721,11 +715,8 @@ func GetStakeValidators(w http.ResponseWriter, r *http.Request) {
 		var v StakeValidator
 		var onDZInt uint8
 		if err := rows.Scan(&v.VotePubkey, &v.NodePubkey, &v.StakeSol, &v.Commission, &v.Version, &v.City, &v.Country, &onDZInt, &v.DeviceCode, &v.MetroCode); err != nil {
-			log.Printf("Stake validator row scan error: %v", err)
-			response.Error = fmt.Sprintf("row scan error: %v", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			_ = json.NewEncoder(w).Encode(response)
+			LoggerFromContext(ctx).Error("Stake validator row scan error", "error", err)
+			writeDBError(w, r, err)
 			return
 		}
 		v.OnDZ = onDZInt == 1
@@ -742,16 +733,386 @@ func GetStakeValidators(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Stake validators rows error: %v", err)
-		response.Error = err.Error()
+		LoggerFromContext(ctx).Error("Stake validators rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
+	}
+}
+
+// StakeConcentrationMetrics holds concentration metrics for one set of validators.
+// HHI uses percentage shares, so it ranges from 0 to 10,000.
+type StakeConcentrationMetrics struct {
+	ValidatorCount uint64  `json:"validator_count"`
+	StakeSol       float64 `json:"stake_sol"`
+	Nakamoto33     uint64  `json:"nakamoto_33"`
+	Nakamoto50     uint64  `json:"nakamoto_50"`
+	HHI            float64 `json:"hhi"`
+}
+
+type StakeConcentrationPoint struct {
+	Timestamp string                    `json:"timestamp"`
+	Total     StakeConcentrationMetrics `json:"total"`
+	DZ        StakeConcentrationMetrics `json:"dz"`
+}
+
+type StakeConcentrationResponse struct {
+	Granularity string                    `json:"granularity"`
+	Range       string                    `json:"range"`
+	Points      []StakeConcentrationPoint `json:"points"`
+	FetchedAt   string                    `json:"fetched_at"`
+	Error       string                    `json:"error,omitempty"`
+}
+
+// stakeConcentrationCacheTTL is how long concentration results are reused.
+// The per-bucket reconstruction of validator stake is expensive.
+const stakeConcentrationCacheTTL = 5 * time.Minute
+
+type stakeConcentrationCacheEntry struct {
+	response  StakeConcentrationResponse
+	fetchedAt time.Time
+}
+
+var (
+	stakeConcentrationCache   = make(map[string]stakeConcentrationCacheEntry)
+	stakeConcentrationCacheMu sync.RWMutex
+)
+
+// GetStakeConcentration returns the Nakamoto coefficient (33% and 50%) and
+// HHI of validator stake over time, for all validators and for validators
+// currently connected to DZ.
+func GetStakeConcentration(w http.ResponseWriter, r *http.Request) {
+	granularity := r.URL.Query().Get("granularity")
+	if granularity == "" {
+		granularity = "1h"
+	}
+	var bucketSeconds int
+	switch granularity {
+	case "1h":
+		bucketSeconds = 3600
+	case "6h":
+		bucketSeconds = 6 * 3600
+	case "1d":
+		bucketSeconds = 24 * 3600
+	default:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "granularity must be one of 1h, 6h, 1d")
+		return
+	}
+
+	rangeParam := r.URL.Query().Get("range")
+	var rangeSeconds int
+	switch rangeParam {
+	case "24h":
+		rangeSeconds = 24 * 3600
+	case "30d":
+		rangeSeconds = 30 * 24 * 3600
+	default:
+		rangeParam = "7d"
+		rangeSeconds = 7 * 24 * 3600
+	}
+	bucketCount := rangeSeconds / bucketSeconds
+	if bucketCount < 1 {
+		bucketCount = 1
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	cacheKey := fmt.Sprintf("%s:%s:%s", EnvFromContext(ctx), granularity, rangeParam)
+	stakeConcentrationCacheMu.RLock()
+	entry, ok := stakeConcentrationCache[cacheKey]
+	stakeConcentrationCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < stakeConcentrationCacheTTL {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(response)
+		w.Header().Set("X-Cache", "HIT")
+		_ = json.NewEncoder(w).Encode(entry.response)
 		return
 	}
 
+	start := time.Now()
+	response := StakeConcentrationResponse{
+		Granularity: granularity,
+		Range:       rangeParam,
+		Points:      []StakeConcentrationPoint{},
+		FetchedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// Reconstruct each validator's stake at the end of every bucket from the
+	// SCD2 history, then sort stakes descending and walk the cumulative sum to
+	// find the smallest set of validators above 1/3 and 1/2 of total stake.
+	// DZ membership uses the current set of connected validators.
+	//
+	// Only the history inside the window is joined against the buckets; older
+	// rows are first collapsed to each validator's state at the window start,
+	// so the join doesn't grow with the full length of the history.
+	query := fmt.Sprintf(`
+		WITH
+		dz_node_pubkeys AS (
+			SELECT DISTINCT gn.pubkey AS node_pubkey
+			FROM dz_users_current u
+			JOIN solana_gossip_nodes_current gn ON u.dz_ip = gn.gossip_ip
+			WHERE u.status = 'activated'
+		),
+		buckets AS (
+			SELECT toStartOfInterval(now(), INTERVAL %[1]d SECOND) - INTERVAL number * %[1]d SECOND AS bucket_ts
+			FROM numbers(%[2]d)
+		),
+		window_start_state AS (
+			SELECT
+				h.vote_pubkey AS vote_pubkey,
+				max(h.snapshot_ts) AS last_snapshot_ts,
+				argMax(h.activated_stake_lamports, h.snapshot_ts) AS last_stake,
+				argMax(h.node_pubkey, h.snapshot_ts) AS last_node_pubkey,
+				argMax(h.epoch_vote_account, h.snapshot_ts) AS last_epoch_vote_account,
+				argMax(h.is_deleted, h.snapshot_ts) AS last_is_deleted
+			FROM dim_solana_vote_accounts_history h
+			WHERE h.snapshot_ts < toStartOfInterval(now(), INTERVAL %[1]d SECOND) - INTERVAL %[3]d SECOND
+			GROUP BY h.vote_pubkey
+		),
+		window_history AS (
+			SELECT
+				vote_pubkey,
+				last_snapshot_ts AS snapshot_ts,
+				last_stake AS activated_stake_lamports,
+				last_node_pubkey AS node_pubkey,
+				last_epoch_vote_account AS epoch_vote_account,
+				last_is_deleted AS is_deleted
+			FROM window_start_state
+			UNION ALL
+			SELECT vote_pubkey, snapshot_ts, activated_stake_lamports, node_pubkey, epoch_vote_account, is_deleted
+			FROM dim_solana_vote_accounts_history
+			WHERE snapshot_ts >= toStartOfInterval(now(), INTERVAL %[1]d SECOND) - INTERVAL %[3]d SECOND
+		),
+		state AS (
+			SELECT
+				b.bucket_ts AS bucket_ts,
+				h.vote_pubkey AS vote_pubkey,
+				argMax(h.activated_stake_lamports, h.snapshot_ts) AS stake,
+				argMax(h.node_pubkey, h.snapshot_ts) AS node_pubkey,
+				argMax(h.epoch_vote_account, h.snapshot_ts) AS epoch_vote_account,
+				argMax(h.is_deleted, h.snapshot_ts) AS is_deleted
+			FROM buckets b
+			CROSS JOIN window_history h
+			WHERE h.snapshot_ts < b.bucket_ts + INTERVAL %[1]d SECOND
+			GROUP BY b.bucket_ts, h.vote_pubkey
+		),
+		stakes AS (
+			SELECT
+				bucket_ts,
+				arraySort(x -> -x, groupArray(toFloat64(stake))) AS all_stakes,
+				arraySort(x -> -x, groupArrayIf(toFloat64(stake), node_pubkey IN (SELECT node_pubkey FROM dz_node_pubkeys))) AS dz_stakes
+			FROM state
+			WHERE is_deleted = 0 AND stake > 0 AND epoch_vote_account = 'true'
+			GROUP BY bucket_ts
+		),
+		totals AS (
+			SELECT
+				bucket_ts,
+				all_stakes,
+				dz_stakes,
+				arraySum(all_stakes) AS all_total,
+				arraySum(dz_stakes) AS dz_total
+			FROM stakes
+		)
+		SELECT
+			formatDateTime(bucket_ts, '%%Y-%%m-%%dT%%H:%%i:%%sZ') AS timestamp,
+			toUInt64(length(all_stakes)) AS all_count,
+			all_total / 1e9 AS all_stake_sol,
+			toUInt64(arrayFirstIndex(x -> x > all_total / 3, arrayCumSum(all_stakes))) AS all_nakamoto_33,
+			toUInt64(arrayFirstIndex(x -> x > all_total / 2, arrayCumSum(all_stakes))) AS all_nakamoto_50,
+			if(all_total > 0, arraySum(arrayMap(x -> pow(x * 100 / all_total, 2), all_stakes)), 0) AS all_hhi,
+			toUInt64(length(dz_stakes)) AS dz_count,
+			dz_total / 1e9 AS dz_stake_sol,
+			toUInt64(arrayFirstIndex(x -> x > dz_total / 3, arrayCumSum(dz_stakes))) AS dz_nakamoto_33,
+			toUInt64(arrayFirstIndex(x -> x > dz_total / 2, arrayCumSum(dz_stakes))) AS dz_nakamoto_50,
+			if(dz_total > 0, arraySum(arrayMap(x -> pow(x * 100 / dz_total, 2), dz_stakes)), 0) AS dz_hhi
+		FROM totals
+		ORDER BY bucket_ts ASC
+	`, bucketSeconds, bucketCount, (bucketCount-1)*bucketSeconds)
+
+	rows, err := envDB(ctx).Query(ctx, query)
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+
+	if err != nil {
+		LoggerFromContext(ctx).Error("Stake concentration query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p StakeConcentrationPoint
+		if err := rows.Scan(
+			&p.Timestamp,
+			&p.Total.ValidatorCount, &p.Total.StakeSol, &p.Total.Nakamoto33, &p.Total.Nakamoto50, &p.Total.HHI,
+			&p.DZ.ValidatorCount, &p.DZ.StakeSol, &p.DZ.Nakamoto33, &p.DZ.Nakamoto50, &p.DZ.HHI,
+		); err != nil {
+			LoggerFromContext(ctx).Error("Stake concentration row scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		response.Points = append(response.Points, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Stake concentration rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	stakeConcentrationCacheMu.Lock()
+	stakeConcentrationCache[cacheKey] = stakeConcentrationCacheEntry{response: response, fetchedAt: time.Now()}
+	stakeConcentrationCacheMu.Unlock()
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", "MISS")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
+	}
+}
+
+// StakeByMetroValidator is one of a metro's largest validators
+type StakeByMetroValidator struct {
+	PK       string  `json:"pk"`   // vote account pubkey
+	Code     string  `json:"code"` // node identity pubkey
+	StakePct float64 `json:"stakePct"`
+}
+
+// StakeByMetro is the DZ-connected validator stake in a metro
+type StakeByMetro struct {
+	MetroPK            string                  `json:"metroPK"`
+	MetroCode          string                  `json:"metroCode"`
+	MetroName          string                  `json:"metroName"`
+	ValidatorCount     uint64                  `json:"validatorCount"`
+	TotalStakeLamports int64                   `json:"totalStakeLamports"`
+	StakePct           float64                 `json:"stakePct"` // of all DZ-connected stake
+	TopValidators      []StakeByMetroValidator `json:"topValidators"`
+}
+
+// stakeByMetroCacheTTL is how long stake by metro is reused. Vote account stake
+// only changes at epoch boundaries.
+const stakeByMetroCacheTTL = 15 * time.Minute
+
+// stakeByMetroTopValidators is how many validators are listed per metro
+const stakeByMetroTopValidators = 5
+
+type stakeByMetroCacheEntry struct {
+	response  []StakeByMetro
+	fetchedAt time.Time
+}
+
+var (
+	stakeByMetroCache   = make(map[DZEnv]stakeByMetroCacheEntry)
+	stakeByMetroCacheMu sync.RWMutex
+)
+
+// GetStakeByMetro aggregates the stake of validators connected to DZ by the
+// metro of the device they connect through, largest share first. Validators
+// are matched to DZ users by gossip IP, like GetStakeValidators.
+func GetStakeByMetro(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	env := EnvFromContext(ctx)
+	stakeByMetroCacheMu.RLock()
+	entry, ok := stakeByMetroCache[env]
+	stakeByMetroCacheMu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < stakeByMetroCacheTTL {
+		w.Header().Set("X-Cache", "HIT")
+		writeJSON(w, entry.response)
+		return
+	}
+
+	// Each validator counts once, at the metro of its first matching user
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH dz_validators AS (
+			SELECT
+				va.vote_pubkey AS vote_pubkey,
+				any(va.node_pubkey) AS node_pubkey,
+				any(va.activated_stake_lamports) AS stake,
+				any(d.metro_pk) AS metro_pk
+			FROM dz_users_current u
+			JOIN solana_gossip_nodes_current gn ON u.dz_ip = gn.gossip_ip
+			JOIN solana_vote_accounts_current va ON gn.pubkey = va.node_pubkey
+			JOIN dz_devices_current d ON u.device_pk = d.pk
+			WHERE u.status = 'activated'
+			  AND va.epoch_vote_account = 'true'
+			  AND va.activated_stake_lamports > 0
+			GROUP BY va.vote_pubkey
+		),
+		per_metro AS (
+			SELECT
+				v.metro_pk AS metro_pk,
+				count() AS validator_count,
+				sum(v.stake) AS total_stake,
+				arraySlice(arrayReverseSort(x -> (x.3, x.1), groupArray((v.vote_pubkey, v.node_pubkey, v.stake))), 1, ?) AS top
+			FROM dz_validators v
+			GROUP BY v.metro_pk
+		)
+		SELECT
+			m.pk,
+			m.code,
+			m.name,
+			p.validator_count,
+			p.total_stake,
+			p.total_stake * 100 / (SELECT sum(stake) FROM dz_validators) AS stake_pct,
+			arrayMap(x -> x.1, p.top),
+			arrayMap(x -> x.2, p.top),
+			arrayMap(x -> x.3, p.top)
+		FROM per_metro p
+		JOIN dz_metros_current m ON p.metro_pk = m.pk
+		ORDER BY stake_pct DESC, m.code
+	`, stakeByMetroTopValidators)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("Stake by metro query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	response := []StakeByMetro{}
+	for rows.Next() {
+		var m StakeByMetro
+		var votePubkeys, nodePubkeys []string
+		var stakes []int64
+		if err := rows.Scan(
+			&m.MetroPK, &m.MetroCode, &m.MetroName, &m.ValidatorCount, &m.TotalStakeLamports, &m.StakePct,
+			&votePubkeys, &nodePubkeys, &stakes,
+		); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(start), err)
+			LoggerFromContext(ctx).Error("Stake by metro scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		m.TopValidators = make([]StakeByMetroValidator, 0, len(votePubkeys))
+		for i := range votePubkeys {
+			v := StakeByMetroValidator{PK: votePubkeys[i], Code: nodePubkeys[i]}
+			// The metro's share scaled by the validator's part of it
+			if m.TotalStakeLamports > 0 {
+				v.StakePct = m.StakePct * float64(stakes[i]) / float64(m.TotalStakeLamports)
+			}
+			m.TopValidators = append(m.TopValidators, v)
+		}
+		response = append(response, m)
+	}
+	metrics.RecordClickHouseQuery(time.Since(start), rows.Err())
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Stake by metro rows error", "error", err)
+		writeDBError(w, r, err)
+		return
 	}
+
+	stakeByMetroCacheMu.Lock()
+	stakeByMetroCache[env] = stakeByMetroCacheEntry{response: response, fetchedAt: time.Now()}
+	stakeByMetroCacheMu.Unlock()
+
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, response)
 }
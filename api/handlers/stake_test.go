@@ -0,0 +1,143 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedStakeConcentration inserts four vote accounts with 40, 30, 20 and 10 SOL
+// of stake. Only the 30 SOL validator is connected to DZ.
+func seedStakeConcentration(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_solana_vote_accounts_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 vote_pubkey, epoch, node_pubkey, activated_stake_lamports, epoch_vote_account, commission_percentage)
+		VALUES
+		('vote-1', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 1, 'vote-1', 1, 'node-1', 40000000000, 'true', 0),
+		('vote-2', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 2, 'vote-2', 1, 'node-2', 30000000000, 'true', 0),
+		('vote-3', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 3, 'vote-3', 1, 'node-3', 20000000000, 'true', 0),
+		('vote-4', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 4, 'vote-4', 1, 'node-4', 10000000000, 'true', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_solana_gossip_nodes_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pubkey, epoch, gossip_ip, gossip_port, tpuquic_ip, tpuquic_port, version)
+		VALUES
+		('node-2', now(), now(), generateUUIDv4(), 0, 1, 'node-2', 1, '10.0.0.2', 8001, '', 0, '')`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_users_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, owner_pubkey, status, kind, client_ip, dz_ip, device_pk, tunnel_id)
+		VALUES
+		('user-1', now(), now(), generateUUIDv4(), 0, 1, 'user-1', '', 'activated', 'ibrl', '', '10.0.0.2', '', 0)`))
+}
+
+func TestGetStakeConcentration(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedStakeConcentration(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stake/concentration?granularity=1h&range=24h", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetStakeConcentration(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.StakeConcentrationResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Empty(t, resp.Error)
+	require.NotEmpty(t, resp.Points)
+
+	latest := resp.Points[len(resp.Points)-1]
+	assert.Equal(t, uint64(4), latest.Total.ValidatorCount)
+	assert.InDelta(t, 100.0, latest.Total.StakeSol, 0.001)
+	assert.Equal(t, uint64(1), latest.Total.Nakamoto33)
+	assert.Equal(t, uint64(2), latest.Total.Nakamoto50)
+	assert.InDelta(t, 3000.0, latest.Total.HHI, 0.001)
+
+	assert.Equal(t, uint64(1), latest.DZ.ValidatorCount)
+	assert.InDelta(t, 30.0, latest.DZ.StakeSol, 0.001)
+	assert.Equal(t, uint64(1), latest.DZ.Nakamoto33)
+	assert.Equal(t, uint64(1), latest.DZ.Nakamoto50)
+	assert.InDelta(t, 10000.0, latest.DZ.HHI, 0.001)
+}
+
+func TestGetStakeConcentration_InvalidGranularity(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/stake/concentration?granularity=5m", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetStakeConcentration(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetStakeByMetro(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	ctx := t.Context()
+
+	// vote-1 (60 SOL) and vote-2 (20 SOL) connect in AMS, vote-3 (20 SOL) in FRA;
+	// vote-4 isn't on DZ
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_solana_vote_accounts_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 vote_pubkey, epoch, node_pubkey, activated_stake_lamports, epoch_vote_account, commission_percentage)
+		VALUES
+		('vote-1', now(), now(), generateUUIDv4(), 0, 1, 'vote-1', 1, 'node-1', 60000000000, 'true', 0),
+		('vote-2', now(), now(), generateUUIDv4(), 0, 2, 'vote-2', 1, 'node-2', 20000000000, 'true', 0),
+		('vote-3', now(), now(), generateUUIDv4(), 0, 3, 'vote-3', 1, 'node-3', 20000000000, 'true', 0),
+		('vote-4', now(), now(), generateUUIDv4(), 0, 4, 'vote-4', 1, 'node-4', 50000000000, 'true', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_solana_gossip_nodes_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pubkey, epoch, gossip_ip, gossip_port, tpuquic_ip, tpuquic_port, version)
+		VALUES
+		('node-1', now(), now(), generateUUIDv4(), 0, 1, 'node-1', 1, '10.0.0.1', 8001, '', 0, ''),
+		('node-2', now(), now(), generateUUIDv4(), 0, 2, 'node-2', 1, '10.0.0.2', 8001, '', 0, ''),
+		('node-3', now(), now(), generateUUIDv4(), 0, 3, 'node-3', 1, '10.0.0.3', 8001, '', 0, '')`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_users_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, owner_pubkey, status, kind, client_ip, dz_ip, device_pk, tunnel_id)
+		VALUES
+		('user-1', now(), now(), generateUUIDv4(), 0, 1, 'user-1', '', 'activated', 'ibrl', '', '10.0.0.1', 'dev-ams', 0),
+		('user-2', now(), now(), generateUUIDv4(), 0, 2, 'user-2', '', 'activated', 'ibrl', '', '10.0.0.2', 'dev-ams', 0),
+		('user-3', now(), now(), generateUUIDv4(), 0, 3, 'user-3', '', 'activated', 'ibrl', '', '10.0.0.3', 'dev-fra', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-ams', now(), now(), generateUUIDv4(), 0, 1, 'dev-ams', 'activated', 'hybrid', 'AMS-1', '', '', 'metro-ams', 0),
+		('dev-fra', now(), now(), generateUUIDv4(), 0, 2, 'dev-fra', 'activated', 'hybrid', 'FRA-1', '', '', 'metro-fra', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_metros_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name, longitude, latitude)
+		VALUES
+		('metro-ams', now(), now(), generateUUIDv4(), 0, 1, 'metro-ams', 'AMS', 'Amsterdam', 4.9, 52.4),
+		('metro-fra', now(), now(), generateUUIDv4(), 0, 2, 'metro-fra', 'FRA', 'Frankfurt', 8.7, 50.1)`))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stake/validators/by-metro", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetStakeByMetro(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp []handlers.StakeByMetro
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp, 2)
+
+	ams := resp[0]
+	assert.Equal(t, "AMS", ams.MetroCode)
+	assert.Equal(t, uint64(2), ams.ValidatorCount)
+	assert.Equal(t, int64(80000000000), ams.TotalStakeLamports)
+	assert.InDelta(t, 80.0, ams.StakePct, 0.001)
+	require.Len(t, ams.TopValidators, 2)
+	assert.Equal(t, "vote-1", ams.TopValidators[0].PK)
+	assert.Equal(t, "node-1", ams.TopValidators[0].Code)
+	assert.InDelta(t, 60.0, ams.TopValidators[0].StakePct, 0.001)
+
+	assert.Equal(t, "FRA", resp[1].MetroCode)
+	assert.InDelta(t, 20.0, resp[1].StakePct, 0.001)
+}
@@ -3,7 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -26,9 +26,23 @@ type StatsResponse struct {
 	Error          string  `json:"error,omitempty"`
 }
 
+// GetStats returns network summary stats. Mainnet stats are served from
+// the stats cache when present, then derived from the status cache, and otherwise
+// queried directly.
 func GetStats(w http.ResponseWriter, r *http.Request) {
-	// Try to derive stats from the status cache (cache only holds mainnet data)
-	if isMainnet(r.Context()) && statusCache != nil {
+	mainnet := isMainnet(r.Context())
+	cache := statsCache.Load()
+
+	// Stats caches only hold mainnet data
+	if mainnet && cache != nil {
+		if cached := cache.Get(); cached != nil {
+			writeStats(w, cached, "HIT")
+			return
+		}
+	}
+
+	// Try to derive stats from the status cache
+	if mainnet && statusCache != nil {
 		if cached := statusCache.GetStatus(); cached != nil {
 			stats := StatsResponse{
 				ValidatorsOnDZ: cached.Network.ValidatorsOnDZ,
@@ -43,17 +57,15 @@ func GetStats(w http.ResponseWriter, r *http.Request) {
 				UserInboundBps: cached.Network.UserInboundBps,
 				FetchedAt:      cached.Timestamp,
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("X-Cache", "HIT")
-			if err := json.NewEncoder(w).Encode(stats); err != nil {
-				log.Printf("JSON encoding error: %v", err)
+			if cache != nil {
+				cache.Set(&stats)
 			}
+			writeStats(w, &stats, "HIT")
 			return
 		}
 	}
 
 	// Cache miss - fetch fresh data
-	w.Header().Set("X-Cache", "MISS")
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
@@ -176,12 +188,19 @@ func GetStats(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Stats query error: %v", err)
+		LoggerFromContext(ctx).Error("Stats query error", "error", err)
 		stats.Error = err.Error()
+	} else if mainnet && cache != nil {
+		cache.Set(&stats)
 	}
 
+	writeStats(w, &stats, "MISS")
+}
+
+func writeStats(w http.ResponseWriter, stats *StatsResponse, cacheStatus string) {
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", cacheStatus)
 	if err := json.NewEncoder(w).Encode(stats); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		slog.Default().Error("JSON encoding error", "error", err)
 	}
 }
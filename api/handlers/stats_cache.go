@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+)
+
+const (
+	// defaultStatsCacheTTL is how long stats are reused when STATS_CACHE_TTL is unset
+	defaultStatsCacheTTL = 60 * time.Second
+
+	// statsUpdatedChannel is the PostgreSQL NOTIFY channel the indexer signals
+	// on after a data refresh
+	statsUpdatedChannel = "stats_updated"
+
+	// statsListenRetryInterval is the delay before re-establishing a dropped LISTEN connection
+	statsListenRetryInterval = 5 * time.Second
+)
+
+// StatsCache holds the most recent mainnet stats response for up to its TTL.
+type StatsCache struct {
+	mu        sync.RWMutex
+	ttl       time.Duration
+	stats     *StatsResponse
+	fetchedAt time.Time
+}
+
+// NewStatsCache creates an empty stats cache with the given TTL.
+func NewStatsCache(ttl time.Duration) *StatsCache {
+	return &StatsCache{ttl: ttl}
+}
+
+// Get returns the cached stats, or nil if empty or older than the TTL.
+func (c *StatsCache) Get() *StatsResponse {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.stats == nil || time.Since(c.fetchedAt) >= c.ttl {
+		return nil
+	}
+	return c.stats
+}
+
+// Set stores stats in the cache, resetting its age.
+func (c *StatsCache) Set(stats *StatsResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = stats
+	c.fetchedAt = time.Now()
+}
+
+// Invalidate empties the cache so the next request fetches fresh stats.
+func (c *StatsCache) Invalidate() {
+	c.Set(nil)
+}
+
+// Global stats cache instance, nil until InitStatsCache is called. It is swapped
+// atomically because StopStatsCache can run while requests are being served.
+var statsCache atomic.Pointer[StatsCache]
+
+// InitStatsCache initializes the global stats cache with STATS_CACHE_TTL and,
+// when PostgreSQL is configured, invalidates it on stats_updated notifications
+// until ctx is cancelled. Should be called once during server startup.
+func InitStatsCache(ctx context.Context) {
+	cache := NewStatsCache(statsCacheTTL())
+	statsCache.Store(cache)
+	if config.PgPool != nil {
+		go listenForStatsUpdates(ctx, cache)
+	}
+}
+
+// StopStatsCache disables the global stats cache.
+func StopStatsCache() {
+	statsCache.Store(nil)
+}
+
+// statsCacheTTL returns STATS_CACHE_TTL or the default. The value is a number
+// of seconds or a Go duration string (e.g. "90s", "2m").
+func statsCacheTTL() time.Duration {
+	val := os.Getenv("STATS_CACHE_TTL")
+	if val == "" {
+		return defaultStatsCacheTTL
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		slog.Warn("Invalid STATS_CACHE_TTL, using default", "value", val, "default", defaultStatsCacheTTL)
+		return defaultStatsCacheTTL
+	}
+	return d
+}
+
+// listenForStatsUpdates holds a dedicated connection LISTENing on
+// stats_updated and invalidates cache on each notification, reconnecting if
+// the connection drops.
+func listenForStatsUpdates(ctx context.Context, cache *StatsCache) {
+	for {
+		if err := waitForStatsUpdates(ctx, cache); err != nil && ctx.Err() == nil {
+			slog.Warn("Stats cache listener error, retrying", "error", err, "retry_in", statsListenRetryInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(statsListenRetryInterval):
+		}
+	}
+}
+
+func waitForStatsUpdates(ctx context.Context, cache *StatsCache) error {
+	poolConn, err := config.PgPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Take the connection out of the pool so the LISTEN doesn't leak to other users
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+statsUpdatedChannel); err != nil {
+		return err
+	}
+	// Anything cached before LISTEN took effect may have missed a notification
+	cache.Invalidate()
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		cache.Invalidate()
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsCache(t *testing.T) {
+	t.Parallel()
+
+	c := NewStatsCache(time.Minute)
+	assert.Nil(t, c.Get())
+
+	stats := &StatsResponse{Users: 3}
+	c.Set(stats)
+	assert.Same(t, stats, c.Get())
+
+	c.Invalidate()
+	assert.Nil(t, c.Get())
+}
+
+func TestStatsCache_Expires(t *testing.T) {
+	t.Parallel()
+
+	c := NewStatsCache(10 * time.Millisecond)
+	c.Set(&StatsResponse{Users: 3})
+	assert.Eventually(t, func() bool { return c.Get() == nil }, time.Second, 5*time.Millisecond)
+}
+
+func TestStatsCacheTTL(t *testing.T) {
+	for val, want := range map[string]time.Duration{
+		"":      defaultStatsCacheTTL,
+		"30":    30 * time.Second,
+		"2m":    2 * time.Minute,
+		"0":     defaultStatsCacheTTL,
+		"later": defaultStatsCacheTTL,
+	} {
+		t.Setenv("STATS_CACHE_TTL", val)
+		assert.Equal(t, want, statsCacheTTL(), val)
+	}
+}
@@ -1,10 +1,12 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/malbeclabs/lake/api/config"
 	"github.com/malbeclabs/lake/api/handlers"
@@ -196,3 +198,42 @@ func TestGetStats_ValidatorsWithStake(t *testing.T) {
 	assert.Equal(t, float64(10000), response.TotalStakeSol) // 10000 SOL
 	assert.Equal(t, float64(50), response.StakeSharePct)    // 50% of total stake
 }
+
+func getStatsCacheHeader(t *testing.T) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	handlers.GetStats(rr, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	return rr.Header().Get("X-Cache")
+}
+
+func TestGetStats_CacheInvalidatedOnNotify(t *testing.T) {
+	apitesting.SetupTestClickHouse(t, testChDB)
+	setupStatsSchema(t)
+	apitesting.SetupTestDB(t, testPgDB)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	handlers.InitStatsCache(ctx)
+	t.Cleanup(func() {
+		cancel()
+		handlers.StopStatsCache()
+	})
+
+	// Wait for the listener's connection to have run LISTEN
+	require.Eventually(t, func() bool {
+		var n int
+		err := config.PgPool.QueryRow(t.Context(),
+			`SELECT count(*) FROM pg_stat_activity WHERE query = 'LISTEN stats_updated'`).Scan(&n)
+		require.NoError(t, err)
+		return n > 0
+	}, 10*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, "MISS", getStatsCacheHeader(t))
+	assert.Equal(t, "HIT", getStatsCacheHeader(t))
+
+	_, err := config.PgPool.Exec(t.Context(), "NOTIFY stats_updated")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return getStatsCacheHeader(t) == "MISS"
+	}, 10*time.Second, 50*time.Millisecond)
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -204,7 +204,7 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.Header().Set("X-Cache", "HIT")
 			if err := json.NewEncoder(w).Encode(cached); err != nil {
-				log.Printf("JSON encoding error: %v", err)
+				LoggerFromContext(r.Context()).Error("JSON encoding error", "error", err)
 			}
 			return
 		}
@@ -219,7 +219,7 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -1033,7 +1033,7 @@ func fetchStatusData(ctx context.Context) *StatusResponse {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Status query error: %v", err)
+		LoggerFromContext(ctx).Error("Status query error", "error", err)
 		resp.Error = err.Error()
 	}
 
@@ -1118,7 +1118,7 @@ func GetLinkHistory(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.Header().Set("X-Cache", "HIT")
 			if err := json.NewEncoder(w).Encode(cached); err != nil {
-				log.Printf("JSON encoding error: %v", err)
+				LoggerFromContext(r.Context()).Error("JSON encoding error", "error", err)
 			}
 			return
 		}
@@ -1131,14 +1131,14 @@ func GetLinkHistory(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := fetchLinkHistoryData(ctx, timeRange, requestedBuckets)
 	if err != nil {
-		log.Printf("fetchLinkHistoryData error: %v", err)
-		http.Error(w, "Failed to fetch link history", http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("fetchLinkHistoryData error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to fetch link history")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -1501,7 +1501,7 @@ func fetchLinkHistoryData(ctx context.Context, timeRange string, requestedBucket
 
 	statusRows, err := safeQueryRows(ctx, statusHistoryQuery, totalHours)
 	if err != nil {
-		log.Printf("Link status history query error: %v", err)
+		LoggerFromContext(ctx).Error("Link status history query error", "error", err)
 	}
 
 	// Build map of link status per bucket
@@ -1532,19 +1532,19 @@ func fetchLinkHistoryData(ctx context.Context, timeRange string, requestedBucket
 	downQuery := `SELECT pk FROM dz_links_health_current WHERE is_down = true`
 	downRows, downErr := envDB(ctx).Query(ctx, downQuery)
 	if downErr != nil {
-		log.Printf("is_down query error (non-fatal): %v", downErr)
+		LoggerFromContext(ctx).Warn("is_down query error", "error", downErr)
 	} else {
 		defer downRows.Close()
 		for downRows.Next() {
 			var pk string
 			if err := downRows.Scan(&pk); err != nil {
-				log.Printf("is_down scan error: %v", err)
+				LoggerFromContext(ctx).Error("is_down scan error", "error", err)
 				break
 			}
 			downLinkPKs[pk] = true
 		}
 		if err := downRows.Err(); err != nil {
-			log.Printf("is_down rows iteration error: %v", err)
+			LoggerFromContext(ctx).Error("is_down rows iteration error", "error", err)
 		}
 	}
 
@@ -1865,8 +1865,8 @@ func fetchLinkHistoryData(ctx context.Context, timeRange string, requestedBucket
 		BucketCount:   bucketCount,
 	}
 
-	log.Printf("fetchLinkHistoryData completed in %v (range=%s, buckets=%d, links=%d)",
-		time.Since(start), timeRange, bucketCount, len(links))
+	LoggerFromContext(ctx).Info("fetchLinkHistoryData completed",
+		"range", timeRange, "buckets", bucketCount, "links", len(links), "duration_ms", time.Since(start).Milliseconds())
 
 	return resp, nil
 }
@@ -1971,7 +1971,7 @@ func GetDeviceHistory(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.Header().Set("X-Cache", "HIT")
 			if err := json.NewEncoder(w).Encode(cached); err != nil {
-				log.Printf("JSON encoding error: %v", err)
+				LoggerFromContext(r.Context()).Error("JSON encoding error", "error", err)
 			}
 			return
 		}
@@ -1984,14 +1984,14 @@ func GetDeviceHistory(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := fetchDeviceHistoryData(ctx, timeRange, requestedBuckets)
 	if err != nil {
-		log.Printf("fetchDeviceHistoryData error: %v", err)
-		http.Error(w, "Failed to fetch device history", http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("fetchDeviceHistoryData error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to fetch device history")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -2147,7 +2147,7 @@ func fetchDeviceHistoryData(ctx context.Context, timeRange string, requestedBuck
 
 	statusRows, err := safeQueryRows(ctx, statusHistoryQuery, totalHours)
 	if err != nil {
-		log.Printf("Device status history query error: %v", err)
+		LoggerFromContext(ctx).Error("Device status history query error", "error", err)
 	}
 
 	// Build map of device status per bucket
@@ -2301,8 +2301,8 @@ func fetchDeviceHistoryData(ctx context.Context, timeRange string, requestedBuck
 		BucketCount:   bucketCount,
 	}
 
-	log.Printf("fetchDeviceHistoryData completed in %v (range=%s, buckets=%d, devices=%d)",
-		time.Since(start), timeRange, bucketCount, len(devices))
+	LoggerFromContext(ctx).Info("fetchDeviceHistoryData completed",
+		"range", timeRange, "buckets", bucketCount, "devices", len(devices), "duration_ms", time.Since(start).Milliseconds())
 
 	return resp, nil
 }
@@ -2326,9 +2326,32 @@ func classifyDeviceStatus(totalErrors, totalDiscards uint64, carrierTransitions
 type InterfaceIssuesResponse struct {
 	Issues    []InterfaceIssue `json:"issues"`
 	TimeRange string           `json:"time_range"`
+	Total     int              `json:"total"`
+	HasMore   bool             `json:"has_more"`
 }
 
-// GetInterfaceIssues returns interface issues for a given time range
+// interfaceIssuePredicates maps an issue_type filter to the counter condition
+// that marks a sample as an issue of that type
+var interfaceIssuePredicates = map[string]string{
+	"":         "(c.in_errors_delta > 0 OR c.out_errors_delta > 0 OR c.in_discards_delta > 0 OR c.out_discards_delta > 0 OR c.carrier_transitions_delta > 0)",
+	"errors":   "(c.in_errors_delta > 0 OR c.out_errors_delta > 0)",
+	"discards": "(c.in_discards_delta > 0 OR c.out_discards_delta > 0)",
+	"carrier":  "c.carrier_transitions_delta > 0",
+}
+
+// interfaceIssuesFilter narrows the interface issues query
+type interfaceIssuesFilter struct {
+	DevicePK  string
+	MetroPK   string
+	IssueType string
+	Since     *time.Time // only interfaces first seen with issues after this time
+	Limit     int
+	Offset    int
+}
+
+// GetInterfaceIssues returns interface issues for a given time range, optionally
+// filtered by device_pk, metro_pk, issue_type (errors, discards, carrier) and
+// since (RFC3339), paginated with limit/offset.
 func GetInterfaceIssues(w http.ResponseWriter, r *http.Request) {
 	timeRange := r.URL.Query().Get("range")
 	if timeRange == "" {
@@ -2355,30 +2378,73 @@ func GetInterfaceIssues(w http.ResponseWriter, r *http.Request) {
 		timeRange = "24h"
 	}
 
+	pagination := ParsePagination(r, 50)
+	filter := interfaceIssuesFilter{
+		DevicePK:  r.URL.Query().Get("device_pk"),
+		MetroPK:   r.URL.Query().Get("metro_pk"),
+		IssueType: r.URL.Query().Get("issue_type"),
+		Limit:     pagination.Limit,
+		Offset:    pagination.Offset,
+	}
+	if _, ok := interfaceIssuePredicates[filter.IssueType]; !ok {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "issue_type must be one of errors, discards, carrier")
+		return
+	}
+	if v := r.URL.Query().Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "since must be an RFC3339 timestamp")
+			return
+		}
+		filter.Since = &since
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	issues, err := fetchInterfaceIssuesData(ctx, duration)
+	issues, total, err := fetchInterfaceIssuesData(ctx, duration, filter)
 	if err != nil {
-		log.Printf("Error fetching interface issues: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Error fetching interface issues", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		return
 	}
 
 	resp := &InterfaceIssuesResponse{
 		Issues:    issues,
 		TimeRange: timeRange,
+		Total:     total,
+		HasMore:   filter.Offset+len(issues) < total,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-func fetchInterfaceIssuesData(ctx context.Context, duration time.Duration) ([]InterfaceIssue, error) {
+func fetchInterfaceIssuesData(ctx context.Context, duration time.Duration, filter interfaceIssuesFilter) ([]InterfaceIssue, int, error) {
 	// Convert duration to hours for the SQL interval
 	hours := int(duration.Hours())
 
-	query := fmt.Sprintf(`
+	conditions := []string{
+		fmt.Sprintf("c.event_ts > now() - INTERVAL %d HOUR", hours),
+		"d.status = 'activated'",
+		interfaceIssuePredicates[filter.IssueType],
+	}
+	var args []any
+	if filter.DevicePK != "" {
+		conditions = append(conditions, "d.pk = ?")
+		args = append(args, filter.DevicePK)
+	}
+	if filter.MetroPK != "" {
+		conditions = append(conditions, "d.metro_pk = ?")
+		args = append(args, filter.MetroPK)
+	}
+	having := ""
+	if filter.Since != nil {
+		having = "HAVING min(c.event_ts) > ?"
+		args = append(args, *filter.Since)
+	}
+
+	grouped := fmt.Sprintf(`
 		SELECT
 			d.pk as device_pk,
 			d.code as device_code,
@@ -2402,17 +2468,23 @@ func fetchInterfaceIssuesData(ctx context.Context, duration time.Duration) ([]In
 		JOIN dz_metros_current m ON d.metro_pk = m.pk
 		LEFT JOIN dz_contributors_current contrib ON d.contributor_pk = contrib.pk
 		LEFT JOIN dz_links_current l ON c.link_pk = l.pk
-		WHERE c.event_ts > now() - INTERVAL %d HOUR
-		  AND d.status = 'activated'
-		  AND (c.in_errors_delta > 0 OR c.out_errors_delta > 0 OR c.in_discards_delta > 0 OR c.out_discards_delta > 0 OR c.carrier_transitions_delta > 0)
+		WHERE %s
 		GROUP BY d.pk, d.code, d.device_type, contrib.code, m.code, c.intf, l.pk, l.code, l.link_type, c.link_side
-		ORDER BY (in_errors + out_errors + in_discards + out_discards + carrier_transitions) DESC
-		LIMIT 50
-	`, hours)
+		%s
+	`, strings.Join(conditions, " AND "), having)
 
-	rows, err := envDB(ctx).Query(ctx, query)
+	var total uint64
+	if err := envDB(ctx).QueryRow(ctx, "SELECT count() FROM ("+grouped+")", args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := grouped + `
+		ORDER BY (in_errors + out_errors + in_discards + out_discards + carrier_transitions) DESC, device_code, interface_name
+		LIMIT ? OFFSET ?
+	`
+	rows, err := envDB(ctx).Query(ctx, query, append(args, filter.Limit, filter.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -2438,12 +2510,12 @@ func fetchInterfaceIssuesData(ctx context.Context, duration time.Duration) ([]In
 			&issue.FirstSeen,
 			&issue.LastSeen,
 		); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		issues = append(issues, issue)
 	}
 
-	return issues, rows.Err()
+	return issues, int(total), rows.Err()
 }
 
 // DeviceInterfaceHistoryResponse is the response for device interface history endpoint
@@ -2478,7 +2550,7 @@ type InterfaceHourStatus struct {
 func GetDeviceInterfaceHistory(w http.ResponseWriter, r *http.Request) {
 	devicePK := chi.URLParam(r, "pk")
 	if devicePK == "" {
-		http.Error(w, "Device PK is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Device PK is required")
 		return
 	}
 
@@ -2500,8 +2572,8 @@ func GetDeviceInterfaceHistory(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := fetchDeviceInterfaceHistoryData(ctx, devicePK, timeRange, requestedBuckets)
 	if err != nil {
-		log.Printf("Error fetching device interface history: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Error fetching device interface history", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		return
 	}
 
@@ -2686,7 +2758,7 @@ type SingleLinkHistoryResponse struct {
 func GetSingleLinkHistory(w http.ResponseWriter, r *http.Request) {
 	linkPK := chi.URLParam(r, "pk")
 	if linkPK == "" {
-		http.Error(w, "missing link pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing link pk")
 		return
 	}
 
@@ -2709,19 +2781,19 @@ func GetSingleLinkHistory(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := fetchSingleLinkHistoryData(ctx, linkPK, timeRange, requestedBuckets)
 	if err != nil {
-		log.Printf("Error fetching single link history: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Error fetching single link history", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		return
 	}
 
 	if resp == nil {
-		http.Error(w, "Link not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeLinkNotFound, "Link not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -3024,7 +3096,7 @@ type SingleDeviceHistoryResponse struct {
 func GetSingleDeviceHistory(w http.ResponseWriter, r *http.Request) {
 	devicePK := chi.URLParam(r, "pk")
 	if devicePK == "" {
-		http.Error(w, "missing device pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing device pk")
 		return
 	}
 
@@ -3047,19 +3119,19 @@ func GetSingleDeviceHistory(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := fetchSingleDeviceHistoryData(ctx, devicePK, timeRange, requestedBuckets)
 	if err != nil {
-		log.Printf("Error fetching single device history: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Error fetching single device history", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		return
 	}
 
 	if resp == nil {
-		http.Error(w, "Device not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeDeviceNotFound, "Device not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -3197,7 +3269,7 @@ func fetchSingleDeviceHistoryData(ctx context.Context, devicePK string, timeRang
 
 	statusRows, err := envDB(ctx).Query(ctx, statusHistoryQuery, devicePK, totalHours)
 	if err != nil {
-		log.Printf("Device status history query error: %v", err)
+		LoggerFromContext(ctx).Error("Device status history query error", "error", err)
 		// Non-fatal - continue without historical status
 	}
 
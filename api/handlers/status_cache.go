@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/metrics"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -34,6 +36,17 @@ type StatusCache struct {
 	latencyComparison *LatencyComparisonResponse           // DZ vs Internet latency comparison
 	metroPathLatency  map[string]*MetroPathLatencyResponse // keyed by optimize strategy (hops, latency, bandwidth)
 
+	// Per-strategy refresh times, used for the X-Cache-Age header
+	metroPathLatencyRefreshed map[string]time.Time
+
+	// Topology hash the cached metro path latency was computed from, and when
+	// it was last fully recomputed
+	metroPathLatencyVersion    string
+	metroPathLatencyRecomputed time.Time
+
+	// Latest hash seen per CacheVersion type, used for the X-Cache-Version header
+	cacheVersions map[string]string
+
 	// Refresh intervals
 	statusInterval      time.Duration
 	linkHistoryInterval time.Duration
@@ -85,24 +98,29 @@ type refreshEntry struct {
 func NewStatusCache(statusInterval, linkHistoryInterval, timelineInterval, outagesInterval, performanceInterval time.Duration) *StatusCache {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &StatusCache{
-		linkHistory:         make(map[string]*LinkHistoryResponse),
-		deviceHistory:       make(map[string]*DeviceHistoryResponse),
-		metroPathLatency:    make(map[string]*MetroPathLatencyResponse),
-		statusInterval:      statusInterval,
-		linkHistoryInterval: linkHistoryInterval,
-		timelineInterval:    timelineInterval,
-		outagesInterval:     outagesInterval,
-		performanceInterval: performanceInterval,
-		ctx:                 ctx,
-		cancel:              cancel,
+		linkHistory:               make(map[string]*LinkHistoryResponse),
+		deviceHistory:             make(map[string]*DeviceHistoryResponse),
+		metroPathLatency:          make(map[string]*MetroPathLatencyResponse),
+		metroPathLatencyRefreshed: make(map[string]time.Time),
+		cacheVersions:             make(map[string]string),
+		statusInterval:            statusInterval,
+		linkHistoryInterval:       linkHistoryInterval,
+		timelineInterval:          timelineInterval,
+		outagesInterval:           outagesInterval,
+		performanceInterval:       performanceInterval,
+		ctx:                       ctx,
+		cancel:                    cancel,
 	}
 }
 
 // Start begins the background refresh loop.
 // It performs an initial refresh synchronously to ensure cache is warm before returning.
+// Metro path latency is warmed separately by WarmMetroPathLatency since it
+// depends on Neo4j, which may not be reachable yet.
 func (c *StatusCache) Start() {
-	log.Printf("Starting status cache with intervals: status=%v, linkHistory=%v, timeline=%v, outages=%v, performance=%v",
-		c.statusInterval, c.linkHistoryInterval, c.timelineInterval, c.outagesInterval, c.performanceInterval)
+	slog.Info("Starting status cache",
+		"status_interval", c.statusInterval, "link_history_interval", c.linkHistoryInterval, "timeline_interval", c.timelineInterval,
+		"outages_interval", c.outagesInterval, "performance_interval", c.performanceInterval)
 
 	// Initial refresh (synchronous to ensure cache is warm)
 	c.refreshStatus()
@@ -111,7 +129,6 @@ func (c *StatusCache) Start() {
 	c.refreshTimeline()
 	c.refreshOutages()
 	c.refreshLatencyComparison()
-	c.refreshMetroPathLatency()
 
 	// Start a single coordinated refresh loop
 	c.wg.Add(1)
@@ -186,7 +203,7 @@ func (c *StatusCache) refreshLoop() {
 
 // Stop cancels the background refresh goroutines and waits for them to exit.
 func (c *StatusCache) Stop() {
-	log.Println("Stopping status cache...")
+	slog.Info("Stopping status cache")
 	c.cancel()
 
 	// Wait for goroutines to exit with a timeout
@@ -198,9 +215,9 @@ func (c *StatusCache) Stop() {
 
 	select {
 	case <-done:
-		log.Println("Status cache stopped")
+		slog.Info("Status cache stopped")
 	case <-time.After(cacheStopTimeout):
-		log.Println("Status cache stop timed out, continuing shutdown")
+		slog.Warn("Status cache stop timed out, continuing shutdown")
 	}
 }
 
@@ -261,12 +278,75 @@ func (c *StatusCache) GetLatencyComparison() *LatencyComparisonResponse {
 	return c.latencyComparison
 }
 
-// GetMetroPathLatency returns the cached metro path latency for the given optimize strategy.
+// GetMetroPathLatency returns the cached metro path latency for the given optimize
+// strategy and how long ago it was refreshed.
 // Returns nil if the specific strategy is not cached.
-func (c *StatusCache) GetMetroPathLatency(optimize string) *MetroPathLatencyResponse {
+func (c *StatusCache) GetMetroPathLatency(optimize string) (*MetroPathLatencyResponse, time.Duration) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	resp := c.metroPathLatency[optimize]
+	if resp == nil {
+		return nil, 0
+	}
+	return resp, time.Since(c.metroPathLatencyRefreshed[optimize])
+}
+
+// HasChanged reports whether the CacheVersion hash for entityType, written by
+// the indexer, differs from lastKnownVersion. It errs on the side of a change
+// when the version can't be read, so callers fall back to a full refresh.
+func (c *StatusCache) HasChanged(entityType, lastKnownVersion string) bool {
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	version, err := fetchCacheVersion(ctx, entityType)
+	if err != nil {
+		slog.Warn("Cache version check failed", "type", entityType, "error", err)
+		return true
+	}
+
+	c.mu.Lock()
+	if c.cacheVersions == nil {
+		c.cacheVersions = make(map[string]string)
+	}
+	c.cacheVersions[entityType] = version
+	c.mu.Unlock()
+
+	return version == "" || version != lastKnownVersion
+}
+
+// CacheVersion returns the latest CacheVersion hash seen for entityType, or ""
+// if it hasn't been read yet.
+func (c *StatusCache) CacheVersion(entityType string) string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.metroPathLatency[optimize]
+	return c.cacheVersions[entityType]
+}
+
+// fetchCacheVersion reads the hash of a (:CacheVersion {type}) node, returning
+// "" if the indexer hasn't written one.
+func fetchCacheVersion(ctx context.Context, entityType string) (string, error) {
+	start := time.Now()
+	session := config.Neo4jSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (v:CacheVersion {type: $type})
+		RETURN v.hash AS hash
+	`, map[string]any{"type": entityType})
+	if err != nil {
+		metrics.RecordNeo4jQuery("cache_version", time.Since(start), err)
+		return "", err
+	}
+	records, err := result.Collect(ctx)
+	metrics.RecordNeo4jQuery("cache_version", time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", nil
+	}
+	hash, _ := records[0].Get("hash")
+	return asString(hash), nil
 }
 
 // refreshStatus fetches fresh status data and updates the cache.
@@ -278,7 +358,7 @@ func (c *StatusCache) refreshStatus() {
 	resp := fetchStatusData(ctx)
 
 	if resp.Error != "" {
-		log.Printf("Status cache refresh error: %v (keeping stale data)", resp.Error)
+		slog.Warn("Status cache refresh error, keeping stale data", "error", resp.Error)
 		return
 	}
 
@@ -287,7 +367,7 @@ func (c *StatusCache) refreshStatus() {
 	c.statusLastRefresh = time.Now()
 	c.mu.Unlock()
 
-	log.Printf("Status cache refreshed in %v", time.Since(start))
+	slog.Info("Status cache refreshed", "duration_ms", time.Since(start).Milliseconds())
 }
 
 // refreshLinkHistory fetches fresh link history data for all configured ranges.
@@ -301,7 +381,7 @@ func (c *StatusCache) refreshLinkHistory() {
 		cancel()
 
 		if err != nil {
-			log.Printf("Link history cache refresh error (range=%s, buckets=%d): %v", cfg.timeRange, cfg.buckets, err)
+			slog.Error("Link history cache refresh error", "range", cfg.timeRange, "buckets", cfg.buckets, "error", err)
 			continue
 		}
 		key := linkHistoryCacheKey(cfg.timeRange, cfg.buckets)
@@ -314,8 +394,8 @@ func (c *StatusCache) refreshLinkHistory() {
 	c.linkHistoryLastRefresh = time.Now()
 	c.mu.Unlock()
 
-	log.Printf("Link history cache refreshed in %v (%d configs)",
-		time.Since(start), len(linkHistoryConfigs))
+	slog.Info("Link history cache refreshed",
+		"configs", len(linkHistoryConfigs), "duration_ms", time.Since(start).Milliseconds())
 }
 
 // refreshDeviceHistory fetches fresh device history data for all configured ranges.
@@ -329,7 +409,7 @@ func (c *StatusCache) refreshDeviceHistory() {
 		cancel()
 
 		if err != nil {
-			log.Printf("Device history cache refresh error (range=%s, buckets=%d): %v", cfg.timeRange, cfg.buckets, err)
+			slog.Error("Device history cache refresh error", "range", cfg.timeRange, "buckets", cfg.buckets, "error", err)
 			continue
 		}
 		key := deviceHistoryCacheKey(cfg.timeRange, cfg.buckets)
@@ -342,8 +422,8 @@ func (c *StatusCache) refreshDeviceHistory() {
 	c.deviceHistoryLastRefresh = time.Now()
 	c.mu.Unlock()
 
-	log.Printf("Device history cache refreshed in %v (%d configs)",
-		time.Since(start), len(deviceHistoryConfigs))
+	slog.Info("Device history cache refreshed",
+		"configs", len(deviceHistoryConfigs), "duration_ms", time.Since(start).Milliseconds())
 }
 
 // refreshTimeline fetches fresh timeline data for the default 24h view.
@@ -355,16 +435,22 @@ func (c *StatusCache) refreshTimeline() {
 	resp := fetchDefaultTimelineData(ctx)
 
 	if ctx.Err() != nil {
-		log.Printf("Timeline cache refresh error: %v (keeping stale data)", ctx.Err())
+		slog.Warn("Timeline cache refresh error, keeping stale data", "error", ctx.Err())
 		return
 	}
 
 	c.mu.Lock()
+	prev := c.timeline
 	c.timeline = resp
 	c.timelineLastRefresh = time.Now()
 	c.mu.Unlock()
 
-	log.Printf("Timeline cache refreshed in %v (%d events)", time.Since(start), len(resp.Events))
+	// Push events that appeared since the last refresh to live subscribers
+	for _, e := range newTimelineEvents(prev, resp) {
+		timelineHub.Broadcast(e)
+	}
+
+	slog.Info("Timeline cache refreshed", "events", len(resp.Events), "duration_ms", time.Since(start).Milliseconds())
 }
 
 // refreshOutages fetches fresh outages data for the default 24h view.
@@ -376,7 +462,7 @@ func (c *StatusCache) refreshOutages() {
 	resp := fetchDefaultOutagesData(ctx)
 
 	if ctx.Err() != nil {
-		log.Printf("Outages cache refresh error: %v (keeping stale data)", ctx.Err())
+		slog.Warn("Outages cache refresh error, keeping stale data", "error", ctx.Err())
 		return
 	}
 
@@ -385,7 +471,7 @@ func (c *StatusCache) refreshOutages() {
 	c.outagesLastRefresh = time.Now()
 	c.mu.Unlock()
 
-	log.Printf("Outages cache refreshed in %v (%d outages)", time.Since(start), len(resp.Outages))
+	slog.Info("Outages cache refreshed", "outages", len(resp.Outages), "duration_ms", time.Since(start).Milliseconds())
 }
 
 // refreshLatencyComparison fetches fresh DZ vs Internet latency comparison data.
@@ -396,7 +482,7 @@ func (c *StatusCache) refreshLatencyComparison() {
 
 	resp, err := fetchLatencyComparisonData(ctx)
 	if err != nil {
-		log.Printf("Latency comparison cache refresh error: %v", err)
+		slog.Error("Latency comparison cache refresh error", "error", err)
 		return
 	}
 
@@ -405,35 +491,143 @@ func (c *StatusCache) refreshLatencyComparison() {
 	c.latencyComparisonLastRefresh = time.Now()
 	c.mu.Unlock()
 
-	log.Printf("Latency comparison cache refreshed in %v (%d comparisons)", time.Since(start), len(resp.Comparisons))
+	slog.Info("Latency comparison cache refreshed", "comparisons", len(resp.Comparisons), "duration_ms", time.Since(start).Milliseconds())
 }
 
+// metroPathLatencyStrategies are the optimization strategies cached for metro path latency.
+var metroPathLatencyStrategies = []string{"latency", "hops", "bandwidth"}
+
+// topologyCacheVersionType is the CacheVersion type the indexer updates when
+// the IS-IS topology changes.
+const topologyCacheVersionType = "isis_topology"
+
+// metroPathLatencyMaxAge bounds how long an unchanged topology can keep the
+// metro path latency cache alive; the internet latency comparison comes from
+// ClickHouse and still drifts.
+const metroPathLatencyMaxAge = 30 * time.Minute
+
 // refreshMetroPathLatency fetches fresh metro path latency data for all optimization strategies.
+// When the IS-IS topology hash hasn't changed since the last full refresh, the
+// Neo4j traversals are skipped and the cached entries are kept.
 func (c *StatusCache) refreshMetroPathLatency() {
 	start := time.Now()
 
+	c.mu.RLock()
+	knownVersion := c.metroPathLatencyVersion
+	recomputed := c.metroPathLatencyRecomputed
+	complete := len(c.metroPathLatency) == len(metroPathLatencyStrategies)
+	c.mu.RUnlock()
+
+	changed := c.HasChanged(topologyCacheVersionType, knownVersion)
+	if complete && !changed && time.Since(recomputed) < metroPathLatencyMaxAge {
+		now := time.Now()
+		c.mu.Lock()
+		for _, strategy := range metroPathLatencyStrategies {
+			c.metroPathLatencyRefreshed[strategy] = now
+		}
+		c.metroPathLatencyLastRefresh = now
+		c.mu.Unlock()
+
+		slog.Debug("Metro path latency cache unchanged, extending", "version", knownVersion)
+		return
+	}
+	version := c.CacheVersion(topologyCacheVersionType)
+
 	// Cache all three optimization strategies
-	strategies := []string{"latency", "hops", "bandwidth"}
-	for _, strategy := range strategies {
+	failed := false
+	for _, strategy := range metroPathLatencyStrategies {
 		ctx, cancel := context.WithTimeout(c.ctx, 45*time.Second)
-		resp, err := fetchMetroPathLatencyData(ctx, strategy)
+		err := c.refreshMetroPathLatencyStrategy(ctx, strategy)
 		cancel()
 
 		if err != nil {
-			log.Printf("Metro path latency cache refresh error (optimize=%s): %v", strategy, err)
-			continue
+			failed = true
+			slog.Error("Metro path latency cache refresh error", "optimize", strategy, "error", err)
 		}
-
-		c.mu.Lock()
-		c.metroPathLatency[strategy] = resp
-		c.mu.Unlock()
 	}
 
 	c.mu.Lock()
 	c.metroPathLatencyLastRefresh = time.Now()
+	// Only trust the version if every strategy was recomputed from it
+	if failed {
+		c.metroPathLatencyVersion = ""
+	} else {
+		c.metroPathLatencyVersion = version
+		c.metroPathLatencyRecomputed = start
+	}
 	c.mu.Unlock()
 
-	log.Printf("Metro path latency cache refreshed in %v (%d strategies)", time.Since(start), len(strategies))
+	slog.Info("Metro path latency cache refreshed", "strategies", len(metroPathLatencyStrategies), "duration_ms", time.Since(start).Milliseconds())
+}
+
+// refreshMetroPathLatencyStrategy fetches and caches metro path latency for one strategy.
+func (c *StatusCache) refreshMetroPathLatencyStrategy(ctx context.Context, strategy string) error {
+	resp, err := fetchMetroPathLatencyData(ctx, strategy)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.metroPathLatency[strategy] = resp
+	if c.metroPathLatencyRefreshed == nil {
+		c.metroPathLatencyRefreshed = make(map[string]time.Time)
+	}
+	c.metroPathLatencyRefreshed[strategy] = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// Metro path latency warm-up settings. The backoff doubles after each failed attempt.
+const (
+	metroPathWarmupTimeout    = 60 * time.Second
+	metroPathWarmupMaxRetries = 5
+)
+
+var metroPathWarmupBackoff = 2 * time.Second
+
+// WarmMetroPathLatency pre-warms the metro path latency cache for every
+// optimization strategy concurrently, so the first requests after startup
+// don't pay for the Neo4j queries. Each attempt uses a context derived from
+// ctx with a 60-second timeout; failures (e.g. Neo4j not reachable yet) are
+// retried with exponential backoff up to metroPathWarmupMaxRetries times.
+func (c *StatusCache) WarmMetroPathLatency(ctx context.Context) {
+	for _, strategy := range metroPathLatencyStrategies {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.warmMetroPathLatencyStrategy(ctx, strategy)
+		}()
+	}
+}
+
+func (c *StatusCache) warmMetroPathLatencyStrategy(ctx context.Context, strategy string) {
+	start := time.Now()
+	backoff := metroPathWarmupBackoff
+
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, metroPathWarmupTimeout)
+		err := c.refreshMetroPathLatencyStrategy(attemptCtx, strategy)
+		cancel()
+
+		if err == nil {
+			slog.Info("Metro path latency cache warmed", "optimize", strategy, "attempts", attempt+1, "duration_ms", time.Since(start).Milliseconds())
+			return
+		}
+		if attempt >= metroPathWarmupMaxRetries {
+			slog.Error("Metro path latency cache warm-up gave up", "optimize", strategy, "attempts", attempt+1, "error", err)
+			return
+		}
+
+		slog.Warn("Metro path latency cache warm-up failed, retrying", "optimize", strategy, "attempt", attempt+1, "retry_in", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return
+		case <-c.ctx.Done():
+			return
+		}
+	}
 }
 
 func linkHistoryCacheKey(timeRange string, buckets int) string {
@@ -447,9 +641,10 @@ func deviceHistoryCacheKey(timeRange string, buckets int) string {
 // Global cache instance
 var statusCache *StatusCache
 
-// InitStatusCache initializes the global status cache.
+// InitStatusCache initializes the global status cache and starts warming the
+// metro path latency cache in the background using ctx.
 // Should be called once during server startup.
-func InitStatusCache() {
+func InitStatusCache(ctx context.Context) {
 	statusCache = NewStatusCache(
 		30*time.Second,  // Status refresh every 30s
 		60*time.Second,  // Link history refresh every 60s
@@ -457,6 +652,7 @@ func InitStatusCache() {
 		60*time.Second,  // Outages refresh every 60s
 		120*time.Second, // Performance (latency comparison, metro path latency) refresh every 120s
 	)
+	statusCache.WarmMetroPathLatency(ctx)
 	statusCache.Start()
 }
 
@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -113,3 +115,79 @@ func TestStatusCache_RefreshOutagesKeepsStaleData(t *testing.T) {
 		t.Error("refreshOutages updated timestamp on error")
 	}
 }
+
+func TestStatusCache_WarmMetroPathLatencyRetriesThenGivesUp(t *testing.T) {
+	origClient := config.Neo4jClient
+	origBackoff := metroPathWarmupBackoff
+	t.Cleanup(func() {
+		config.Neo4jClient = origClient
+		metroPathWarmupBackoff = origBackoff
+	})
+	config.Neo4jClient = nil
+	metroPathWarmupBackoff = time.Millisecond
+
+	c := NewStatusCache(time.Minute, time.Minute, time.Minute, time.Minute, time.Minute)
+	defer c.cancel()
+
+	start := time.Now()
+	c.WarmMetroPathLatency(t.Context())
+	c.wg.Wait()
+
+	// 5 retries with 1ms doubling backoff wait at least 1+2+4+8+16ms
+	if elapsed := time.Since(start); elapsed < 31*time.Millisecond {
+		t.Errorf("warm-up returned after %v, expected it to retry with backoff", elapsed)
+	}
+	for _, strategy := range metroPathLatencyStrategies {
+		if resp, _ := c.GetMetroPathLatency(strategy); resp != nil {
+			t.Errorf("expected no cached data for %s", strategy)
+		}
+	}
+}
+
+func TestStatusCache_WarmMetroPathLatencyStopsOnCancel(t *testing.T) {
+	origClient := config.Neo4jClient
+	t.Cleanup(func() { config.Neo4jClient = origClient })
+	config.Neo4jClient = nil
+
+	c := NewStatusCache(time.Minute, time.Minute, time.Minute, time.Minute, time.Minute)
+	defer c.cancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.WarmMetroPathLatency(ctx)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("warm-up did not stop after context cancellation")
+	}
+}
+
+func TestGetMetroPathLatency_CacheAgeHeader(t *testing.T) {
+	orig := statusCache
+	t.Cleanup(func() { statusCache = orig })
+
+	statusCache = NewStatusCache(time.Minute, time.Minute, time.Minute, time.Minute, time.Minute)
+	defer statusCache.cancel()
+	statusCache.metroPathLatency["hops"] = &MetroPathLatencyResponse{Optimize: "hops"}
+	statusCache.metroPathLatencyRefreshed["hops"] = time.Now().Add(-90 * time.Second)
+	statusCache.cacheVersions[topologyCacheVersionType] = "abc123"
+
+	rr := httptest.NewRecorder()
+	GetMetroPathLatency(rr, httptest.NewRequest(http.MethodGet, "/api/topology/metro-path-latency?optimize=hops", nil))
+
+	if got := rr.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("X-Cache = %q, want HIT", got)
+	}
+	if got := rr.Header().Get("X-Cache-Age"); got != "90" {
+		t.Errorf("X-Cache-Age = %q, want 90", got)
+	}
+	if got := rr.Header().Get("X-Cache-Version"); got != "abc123" {
+		t.Errorf("X-Cache-Version = %q, want abc123", got)
+	}
+}
@@ -3,10 +3,10 @@ package handlers
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"net/http"
 	"sort"
@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/malbeclabs/lake/api/broadcast"
 	"github.com/malbeclabs/lake/api/metrics"
 	"golang.org/x/sync/errgroup"
 )
@@ -190,13 +191,16 @@ type HistogramBucket struct {
 
 // TimelineResponse is the API response for the timeline endpoint
 type TimelineResponse struct {
-	Events    []TimelineEvent   `json:"events"`
-	Total     int               `json:"total"`
-	Limit     int               `json:"limit"`
-	Offset    int               `json:"offset"`
-	TimeRange TimeRange         `json:"time_range"`
-	Histogram []HistogramBucket `json:"histogram,omitempty"`
-	Error     string            `json:"error,omitempty"`
+	Events []TimelineEvent `json:"events"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	// Deprecated: Offset is kept for clients that still page with ?offset=N.
+	// New clients should pass NextCursor back as ?cursor= instead.
+	Offset     int               `json:"offset"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	TimeRange  TimeRange         `json:"time_range"`
+	Histogram  []HistogramBucket `json:"histogram,omitempty"`
+	Error      string            `json:"error,omitempty"`
 }
 
 // TimeRange represents the time range for the query
@@ -218,7 +222,68 @@ type TimelineParams struct {
 	Search          []string // Search terms to filter by (entity codes, device codes, etc.)
 	Limit           int
 	Offset          int
-	IncludeInternal bool // Whether to include internal users (default: false)
+	Cursor          *timelineCursor // Keyset position from ?cursor=; takes precedence over Offset
+	IncludeInternal bool            // Whether to include internal users (default: false)
+}
+
+// timelineCursor is the decoded form of the opaque next_cursor token. It holds
+// the sort key of the last event on the previous page.
+type timelineCursor struct {
+	Timestamp string `json:"ts"`
+	ID        string `json:"id"`
+}
+
+// encodeTimelineCursor builds an opaque cursor token pointing at the given event.
+func encodeTimelineCursor(e TimelineEvent) string {
+	b, _ := json.Marshal(timelineCursor{Timestamp: e.Timestamp, ID: e.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeTimelineCursor parses a cursor token produced by encodeTimelineCursor.
+func decodeTimelineCursor(token string) (*timelineCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+	var c timelineCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor payload: %w", err)
+	}
+	if _, err := time.Parse(time.RFC3339, c.Timestamp); err != nil || c.ID == "" {
+		return nil, fmt.Errorf("invalid cursor position")
+	}
+	return &c, nil
+}
+
+// paginateTimelineEvents returns one page of events and the cursor for the next
+// page. Events must already be sorted by (timestamp, id) descending. When a
+// cursor is set, only events strictly after it in that ordering are considered,
+// so rows that arrive between requests cannot shift the page boundaries.
+func paginateTimelineEvents(events []TimelineEvent, cursor *timelineCursor, offset, limit int) ([]TimelineEvent, string) {
+	startIdx := offset
+	if cursor != nil {
+		startIdx = sort.Search(len(events), func(i int) bool {
+			e := events[i]
+			if e.Timestamp != cursor.Timestamp {
+				return e.Timestamp < cursor.Timestamp
+			}
+			return e.ID < cursor.ID
+		})
+	}
+	if startIdx > len(events) {
+		startIdx = len(events)
+	}
+	endIdx := startIdx + limit
+	if endIdx > len(events) {
+		endIdx = len(events)
+	}
+	page := events[startIdx:endIdx]
+
+	var nextCursor string
+	if endIdx < len(events) && len(page) > 0 {
+		nextCursor = encodeTimelineCursor(page[len(page)-1])
+	}
+	return page, nextCursor
 }
 
 // Internal user pubkeys to exclude by default
@@ -262,7 +327,7 @@ func GetTimelineBounds(w http.ResponseWriter, r *http.Request) {
 	var earliest, latest time.Time
 	err := envDB(ctx).QueryRow(ctx, query).Scan(&earliest, &latest)
 	if err != nil {
-		http.Error(w, "Failed to get timeline bounds", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to get timeline bounds")
 		return
 	}
 
@@ -275,7 +340,7 @@ func GetTimelineBounds(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-func parseTimelineParams(r *http.Request) TimelineParams {
+func parseTimelineParams(r *http.Request) (TimelineParams, error) {
 	now := time.Now().UTC()
 	endTime := now
 	startTime := now.Add(-24 * time.Hour) // Default 24h
@@ -366,6 +431,16 @@ func parseTimelineParams(r *http.Request) TimelineParams {
 		}
 	}
 
+	// Parse keyset cursor (opaque token from a previous response's next_cursor)
+	var cursor *timelineCursor
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		c, err := decodeTimelineCursor(cursorStr)
+		if err != nil {
+			return TimelineParams{}, err
+		}
+		cursor = c
+	}
+
 	// Parse search filter (comma-separated search terms)
 	var search []string
 	if searchStr := r.URL.Query().Get("search"); searchStr != "" {
@@ -389,8 +464,9 @@ func parseTimelineParams(r *http.Request) TimelineParams {
 		Search:          search,
 		Limit:           pagination.Limit,
 		Offset:          pagination.Offset,
+		Cursor:          cursor,
 		IncludeInternal: includeInternal,
-	}
+	}, nil
 }
 
 func generateEventID(entityID string, timestamp time.Time, eventType string) string {
@@ -602,10 +678,13 @@ func isDefaultTimelineRequest(r *http.Request) bool {
 		return false
 	}
 
-	// Must not have pagination offset
+	// Must not have pagination offset or cursor
 	if q.Get("offset") != "" && q.Get("offset") != "0" {
 		return false
 	}
+	if q.Get("cursor") != "" {
+		return false
+	}
 
 	// Must not have search filter
 	if q.Get("search") != "" {
@@ -623,23 +702,33 @@ func isDefaultTimelineRequest(r *http.Request) bool {
 
 // GetTimeline returns timeline events across the network
 func GetTimeline(w http.ResponseWriter, r *http.Request) {
+	requestStart := time.Now()
+
 	// Check if this is a default request that can be served from cache
 	if isMainnet(r.Context()) && isDefaultTimelineRequest(r) && statusCache != nil {
 		if cached := statusCache.GetTimeline(); cached != nil {
 			w.Header().Set("Content-Type", "application/json")
 			w.Header().Set("X-Cache", "HIT")
 			if err := json.NewEncoder(w).Encode(cached); err != nil {
-				log.Printf("Error encoding cached timeline response: %v", err)
+				LoggerFromContext(r.Context()).Error("Error encoding cached timeline response", "error", err)
 			}
+			metrics.TimelineQueryDuration.WithLabelValues("true").Observe(time.Since(requestStart).Seconds())
 			return
 		}
 	}
+	defer func() {
+		metrics.TimelineQueryDuration.WithLabelValues("false").Observe(time.Since(requestStart).Seconds())
+	}()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
 	defer cancel()
 
 	start := time.Now()
-	params := parseTimelineParams(r)
+	params, err := parseTimelineParams(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
 
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(10)
@@ -673,7 +762,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryDeviceChanges(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying device changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying device changes", "error", err)
 				return nil // Don't fail the whole request
 			}
 			mu.Lock()
@@ -688,7 +777,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryLinkChanges(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying link changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying link changes", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -703,7 +792,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryMetroChanges(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying metro changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying metro changes", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -718,7 +807,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryContributorChanges(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying contributor changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying contributor changes", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -733,7 +822,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryUserChanges(ctx, params.StartTime, params.EndTime, params.IncludeInternal)
 			if err != nil {
-				log.Printf("Error querying user changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying user changes", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -748,7 +837,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryPacketLossEvents(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying packet loss events: %v", err)
+				LoggerFromContext(ctx).Error("Error querying packet loss events", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -763,7 +852,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryInterfaceEvents(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying interface events: %v", err)
+				LoggerFromContext(ctx).Error("Error querying interface events", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -778,7 +867,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryValidatorEvents(ctx, params.StartTime, params.EndTime, params.IncludeInternal)
 			if err != nil {
-				log.Printf("Error querying validator events: %v", err)
+				LoggerFromContext(ctx).Error("Error querying validator events", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -794,7 +883,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryGossipNetworkChanges(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying gossip network changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying gossip network changes", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -810,7 +899,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryVoteAccountChanges(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying vote account changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying vote account changes", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -826,7 +915,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryStakeChanges(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying stake changes: %v", err)
+				LoggerFromContext(ctx).Error("Error querying stake changes", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -842,7 +931,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			events, err := queryDZStakeAttribution(ctx, params.StartTime, params.EndTime)
 			if err != nil {
-				log.Printf("Error querying DZ stake attribution: %v", err)
+				LoggerFromContext(ctx).Error("Error querying DZ stake attribution", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -858,7 +947,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		g.Go(func() error {
 			info, err := queryCurrentDZTotalStakeShare(ctx)
 			if err != nil {
-				log.Printf("Error querying DZ total stake share: %v", err)
+				LoggerFromContext(ctx).Error("Error querying DZ total stake share", "error", err)
 				return nil
 			}
 			mu.Lock()
@@ -869,7 +958,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := g.Wait(); err != nil {
-		log.Printf("Error in timeline queries: %v", err)
+		LoggerFromContext(ctx).Error("Error in timeline queries", "error", err)
 	}
 
 	// Merge all events
@@ -979,6 +1068,10 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for _, e := range allEvents {
+		metrics.TimelineEventsTotal.WithLabelValues(e.Category, e.EntityType).Inc()
+	}
+
 	// Filter by entity type if specified
 	if len(params.EntityTypes) > 0 {
 		filtered := make([]TimelineEvent, 0)
@@ -1114,15 +1207,7 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 	total := len(allEvents)
 
 	// Apply pagination
-	startIdx := params.Offset
-	endIdx := params.Offset + params.Limit
-	if startIdx > len(allEvents) {
-		startIdx = len(allEvents)
-	}
-	if endIdx > len(allEvents) {
-		endIdx = len(allEvents)
-	}
-	paginatedEvents := allEvents[startIdx:endIdx]
+	paginatedEvents, nextCursor := paginateTimelineEvents(allEvents, params.Cursor, params.Offset, params.Limit)
 
 	// Compute histogram from all events (before pagination)
 	histogram := computeHistogram(allEvents, params.StartTime, params.EndTime)
@@ -1131,10 +1216,11 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, nil)
 
 	resp := TimelineResponse{
-		Events: paginatedEvents,
-		Total:  total,
-		Limit:  params.Limit,
-		Offset: params.Offset,
+		Events:     paginatedEvents,
+		Total:      total,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+		NextCursor: nextCursor,
 		TimeRange: TimeRange{
 			Start: params.StartTime.Format(time.RFC3339),
 			End:   params.EndTime.Format(time.RFC3339),
@@ -1144,10 +1230,116 @@ func GetTimeline(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("Error encoding timeline response: %v", err)
+		LoggerFromContext(ctx).Error("Error encoding timeline response", "error", err)
 	}
 }
 
+// timelineHub fans out newly detected timeline events to /api/timeline/stream
+// subscribers. Events are published by the status cache when a timeline
+// refresh surfaces events that were not in the previous snapshot.
+var timelineHub = broadcast.NewHub[TimelineEvent]("timeline", 100)
+
+// CloseTimelineHub closes all open timeline SSE connections.
+// Should be called during server shutdown.
+func CloseTimelineHub() {
+	timelineHub.Close()
+}
+
+// StreamTimeline streams new timeline events as Server-Sent Events.
+// Each message carries the event ID (for Last-Event-ID reconnects), the event
+// type, and the JSON-encoded TimelineEvent.
+func StreamTimeline(w http.ResponseWriter, r *http.Request) {
+	// Set SSE headers
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Streaming not supported")
+		return
+	}
+
+	sub := timelineHub.Subscribe()
+	if sub == nil {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, "Server is shutting down")
+		return
+	}
+	defer timelineHub.Unsubscribe(sub)
+
+	sendEvent := func(event TimelineEvent) {
+		jsonData, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, string(jsonData))
+		flusher.Flush()
+	}
+
+	// On reconnect, replay cached events newer than the last one the client saw
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" && isMainnet(r.Context()) && statusCache != nil {
+		if cached := statusCache.GetTimeline(); cached != nil {
+			for i, e := range cached.Events {
+				if e.ID != lastID {
+					continue
+				}
+				// Cached events are newest first; send oldest first
+				for j := i - 1; j >= 0; j-- {
+					sendEvent(cached.Events[j])
+				}
+				break
+			}
+		}
+	}
+
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ctx := r.Context()
+	heartbeatTicker := time.NewTicker(15 * time.Second)
+	defer heartbeatTicker.Stop()
+
+	for {
+		select {
+		case event := <-sub.Events:
+			sendEvent(event)
+		case <-heartbeatTicker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-sub.Done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newTimelineEvents returns events in next that were not present in prev and
+// are at least as recent as the newest event in prev, ordered oldest first.
+// Returns nil when prev is nil (nothing to diff against on the first refresh).
+func newTimelineEvents(prev, next *TimelineResponse) []TimelineEvent {
+	if prev == nil || next == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(prev.Events))
+	var newest string
+	for _, e := range prev.Events {
+		seen[e.ID] = struct{}{}
+		if e.Timestamp > newest {
+			newest = e.Timestamp
+		}
+	}
+	var events []TimelineEvent
+	for i := len(next.Events) - 1; i >= 0; i-- {
+		e := next.Events[i]
+		if _, ok := seen[e.ID]; ok || e.Timestamp < newest {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
 // computeHistogram creates time buckets from events for visualization
 func computeHistogram(events []TimelineEvent, startTime, endTime time.Time) []HistogramBucket {
 	if len(events) == 0 {
@@ -3679,7 +3871,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryDeviceChanges(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying device changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying device changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3692,7 +3884,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryLinkChanges(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying link changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying link changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3705,7 +3897,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryMetroChanges(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying metro changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying metro changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3718,7 +3910,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryContributorChanges(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying contributor changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying contributor changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3731,7 +3923,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryUserChanges(ctx, startTime, endTime, false)
 		if err != nil {
-			log.Printf("Cache: Error querying user changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying user changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3744,7 +3936,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryPacketLossEvents(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying packet loss events: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying packet loss events", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3757,7 +3949,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryInterfaceEvents(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying interface events: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying interface events", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3770,7 +3962,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryValidatorEvents(ctx, startTime, endTime, false)
 		if err != nil {
-			log.Printf("Cache: Error querying validator events: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying validator events", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3783,7 +3975,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryGossipNetworkChanges(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying gossip network changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying gossip network changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3796,7 +3988,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryVoteAccountChanges(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying vote account changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying vote account changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3809,7 +4001,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	g.Go(func() error {
 		events, err := queryStakeChanges(ctx, startTime, endTime)
 		if err != nil {
-			log.Printf("Cache: Error querying stake changes: %v", err)
+			LoggerFromContext(ctx).Error("Cache: Error querying stake changes", "error", err)
 			return nil
 		}
 		mu.Lock()
@@ -3819,7 +4011,7 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	})
 
 	if err := g.Wait(); err != nil {
-		log.Printf("Cache: Error in timeline queries: %v", err)
+		LoggerFromContext(ctx).Error("Cache: Error in timeline queries", "error", err)
 	}
 
 	// Merge all events
@@ -3886,24 +4078,17 @@ func fetchDefaultTimelineData(ctx context.Context) *TimelineResponse {
 	total := len(allEvents)
 
 	// Apply pagination
-	startIdx := offset
-	endIdx := offset + limit
-	if startIdx > len(allEvents) {
-		startIdx = len(allEvents)
-	}
-	if endIdx > len(allEvents) {
-		endIdx = len(allEvents)
-	}
-	paginatedEvents := allEvents[startIdx:endIdx]
+	paginatedEvents, nextCursor := paginateTimelineEvents(allEvents, nil, offset, limit)
 
 	// Compute histogram
 	histogram := computeHistogram(allEvents, startTime, endTime)
 
 	return &TimelineResponse{
-		Events: paginatedEvents,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+		Events:     paginatedEvents,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: nextCursor,
 		TimeRange: TimeRange{
 			Start: startTime.Format(time.RFC3339),
 			End:   endTime.Format(time.RFC3339),
@@ -982,6 +982,86 @@ func TestTimeline_FullResponse_Pagination(t *testing.T) {
 	}
 }
 
+// TestTimeline_FullResponse_CursorPagination tests that cursor-based paging is
+// stable when new events arrive between page requests.
+func TestTimeline_FullResponse_CursorPagination(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	t1 := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	dzIP := "10.0.0.1"
+	insertDZUserCurrent(t, "dz-user-1", dzIP, "activated", "", "")
+
+	for i := 0; i < 5; i++ {
+		ts := t1.Add(time.Duration(i) * time.Hour)
+		vote := fmt.Sprintf("vote-%d", i)
+		node := fmt.Sprintf("node-%d", i)
+		insertVoteAccountHistory(t, vote, node, int64((i+1)*10_000_000_000_000), ts)
+		insertGossipNodeHistory(t, node, dzIP, ts)
+		insertCurrentVoteAccount(t, vote, node, int64((i+1)*10_000_000_000_000))
+		insertCurrentGossipNode(t, node, dzIP)
+	}
+	insertCurrentVoteAccount(t, "vote-rest", "node-rest", 900_000_000_000_000)
+	insertCurrentGossipNode(t, "node-rest", "8.8.8.8")
+	insertVoteAccountHistory(t, "vote-rest", "node-rest", 900_000_000_000_000, t1)
+	insertGossipNodeHistory(t, "node-rest", "8.8.8.8", t1)
+
+	endTime := t1.Add(6 * time.Hour)
+	baseURL := fmt.Sprintf("/api/timeline?start=%s&end=%s&category=state_change&entity_type=validator",
+		t1.Add(-time.Minute).Format(time.RFC3339),
+		endTime.Format(time.RFC3339))
+
+	getTimeline := func(query string) handlers.TimelineResponse {
+		req := httptest.NewRequest(http.MethodGet, baseURL+query, nil)
+		rr := httptest.NewRecorder()
+		handlers.GetTimeline(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp handlers.TimelineResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		return resp
+	}
+
+	respAll := getTimeline("&limit=500")
+	if respAll.Total < 4 {
+		t.Skipf("Not enough events to test pagination (got %d)", respAll.Total)
+	}
+	assert.Empty(t, respAll.NextCursor, "single page should not return a next cursor")
+
+	// Page 1
+	resp1 := getTimeline("&limit=2")
+	require.Len(t, resp1.Events, 2)
+	require.NotEmpty(t, resp1.NextCursor, "first page should return a next cursor")
+
+	// A new validator joins DZ after page 1 was served, newer than every
+	// existing event. Offset paging would shift by one; cursor paging must not.
+	newTS := t1.Add(5 * time.Hour)
+	insertVoteAccountHistory(t, "vote-new", "node-new", 50_000_000_000_000, newTS)
+	insertGossipNodeHistory(t, "node-new", dzIP, newTS)
+	insertCurrentVoteAccount(t, "vote-new", "node-new", 50_000_000_000_000)
+	insertCurrentGossipNode(t, "node-new", dzIP)
+
+	// Page 2 via cursor
+	resp2 := getTimeline("&limit=2&cursor=" + resp1.NextCursor)
+	require.Len(t, resp2.Events, 2)
+	assert.Greater(t, resp2.Total, respAll.Total, "new event should be counted in total")
+
+	assert.Equal(t, respAll.Events[2].ID, resp2.Events[0].ID, "page 2 should continue where page 1 ended")
+	assert.Equal(t, respAll.Events[3].ID, resp2.Events[1].ID, "page 2 should continue where page 1 ended")
+	originalIDs := make(map[string]bool)
+	for _, e := range respAll.Events {
+		originalIDs[e.ID] = true
+	}
+	for _, e := range resp2.Events {
+		assert.True(t, originalIDs[e.ID], "event %s inserted after page 1 should not appear on later pages", e.ID)
+	}
+}
+
+func TestTimeline_InvalidCursor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/timeline?cursor=not-a-cursor", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetTimeline(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
 // TestTimeline_FullResponse_EventFields tests that all required fields are
 // present and correctly typed on validator events.
 func TestTimeline_FullResponse_EventFields(t *testing.T) {
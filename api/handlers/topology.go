@@ -4,8 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -190,7 +191,7 @@ func GetTopology(w http.ResponseWriter, r *http.Request) {
 				return err
 			}
 			if err := json.Unmarshal([]byte(interfacesJSON), &d.Interfaces); err != nil {
-				log.Printf("failed to parse interfaces JSON for device %s: %v", d.PK, err)
+				LoggerFromContext(ctx).Error("failed to parse interfaces JSON", "device_pk", d.PK, "error", err)
 				d.Interfaces = []DeviceInterface{}
 			}
 			devices = append(devices, d)
@@ -365,7 +366,7 @@ func GetTopology(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		log.Printf("Topology query error: %v", err)
+		LoggerFromContext(ctx).Error("Topology query error", "error", err)
 		response.Error = dberror.UserMessage(err)
 	}
 
@@ -385,7 +386,7 @@ func GetTopology(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -479,7 +480,7 @@ func GetTopologyTraffic(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := envDB(ctx).Query(ctx, query, pk)
 	if err != nil {
-		log.Printf("Traffic query error: %v", err)
+		LoggerFromContext(ctx).Error("Traffic query error", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(TrafficResponse{Error: dberror.UserMessage(err)})
 		return
@@ -490,7 +491,7 @@ func GetTopologyTraffic(w http.ResponseWriter, r *http.Request) {
 		var p TrafficDataPoint
 		var avgIn, avgOut, peakIn, peakOut *float64
 		if err := rows.Scan(&p.Time, &avgIn, &avgOut, &peakIn, &peakOut); err != nil {
-			log.Printf("Traffic scan error: %v", err)
+			LoggerFromContext(ctx).Error("Traffic scan error", "error", err)
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(TrafficResponse{Error: dberror.UserMessage(err)})
 			return
@@ -697,7 +698,7 @@ func GetLinkLatencyHistory(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := envDB(ctx).Query(ctx, query, pk)
 	if err != nil {
-		log.Printf("Latency query error: %v", err)
+		LoggerFromContext(ctx).Error("Latency query error", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(LinkLatencyResponse{Error: dberror.UserMessage(err)})
 		return
@@ -709,7 +710,7 @@ func GetLinkLatencyHistory(w http.ResponseWriter, r *http.Request) {
 		var p LinkLatencyDataPoint
 		var avgRtt, p95Rtt, avgJitter, lossPct, avgRttAtoZ, p95RttAtoZ, avgRttZtoA, p95RttZtoA, jitterAtoZ, jitterZtoA *float64
 		if err := rows.Scan(&p.Time, &avgRtt, &p95Rtt, &avgJitter, &lossPct, &avgRttAtoZ, &p95RttAtoZ, &avgRttZtoA, &p95RttZtoA, &jitterAtoZ, &jitterZtoA); err != nil {
-			log.Printf("Latency scan error: %v", err)
+			LoggerFromContext(ctx).Error("Latency scan error", "error", err)
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(LinkLatencyResponse{Error: dberror.UserMessage(err)})
 			return
@@ -787,130 +788,170 @@ type LatencyComparisonResponse struct {
 		MaxImprovementPct float64 `json:"max_improvement_pct"`
 		PairsWithData     int     `json:"pairs_with_data"`
 	} `json:"summary"`
+	// Links compares each link's latency over [start, end) to the baseline
+	// window before start. Not cached.
+	Links []LinkLatencyDelta `json:"links,omitempty"`
 }
 
-func GetLatencyComparison(w http.ResponseWriter, r *http.Request) {
-	// Try cache first (cache only holds mainnet data)
-	if isMainnet(r.Context()) && statusCache != nil {
-		if cached := statusCache.GetLatencyComparison(); cached != nil {
-			w.Header().Set("X-Cache", "HIT")
-			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(cached)
-			return
-		}
-	}
+// LinkLatencyDelta is a link's average RTT in the requested range against its
+// baseline
+type LinkLatencyDelta struct {
+	LinkPK        string   `json:"linkPK"`
+	LinkCode      string   `json:"linkCode"`
+	SideAPK       string   `json:"sideAPK"`
+	SideZPK       string   `json:"sideZPK"`
+	CurrentAvgMs  float64  `json:"currentAvgMs"`
+	BaselineAvgMs *float64 `json:"baselineAvgMs"`
+	DeltaMs       *float64 `json:"deltaMs"`  // current - baseline
+	DeltaPct      *float64 `json:"deltaPct"` // relative to baseline
+}
+
+// Defaults for the per-link comparison in GetLatencyComparison
+const (
+	defaultLatencyComparisonRange    = 24 * time.Hour
+	defaultLatencyComparisonBaseline = 7 * 24 * time.Hour
+)
 
-	// Cache miss - fetch fresh data
+// GetLatencyComparison returns DZ vs internet latency per metro pair, and each
+// link's latency over ?start= to ?end= (default the last 24 hours) compared to
+// the ?baseline_window= (default 7d) before it. ?device_pk= (comma-separated)
+// limits the links to those with a side on one of the devices.
+func GetLatencyComparison(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	start := time.Now()
-
-	// Query the pre-built comparison view
-	query := `
-		SELECT
-			m1.pk AS origin_metro_pk,
-			c.origin_metro AS origin_metro_code,
-			c.origin_metro_name,
-			m2.pk AS target_metro_pk,
-			c.target_metro AS target_metro_code,
-			c.target_metro_name,
-			c.dz_avg_rtt_ms,
-			c.dz_p95_rtt_ms,
-			c.dz_avg_jitter_ms,
-			c.dz_loss_pct,
-			c.dz_sample_count,
-			c.internet_avg_rtt_ms,
-			c.internet_p95_rtt_ms,
-			c.internet_avg_jitter_ms,
-			c.internet_sample_count,
-			c.rtt_improvement_pct,
-			c.jitter_improvement_pct
-		FROM dz_vs_internet_latency_comparison c
-		JOIN dz_metros_current m1 ON c.origin_metro = m1.code
-		JOIN dz_metros_current m2 ON c.target_metro = m2.code
-		WHERE c.dz_sample_count > 0
-		ORDER BY c.origin_metro, c.target_metro
-	`
-
-	rows, err := envDB(ctx).Query(ctx, query)
-	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, err)
-
-	if err != nil {
-		log.Printf("Latency comparison query error: %v", err)
-		http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+	query := r.URL.Query()
+	end := time.Now().UTC()
+	if s := query.Get("end"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "end must be an RFC3339 timestamp")
+			return
+		}
+		end = t
+	}
+	rangeStart := end.Add(-defaultLatencyComparisonRange)
+	if s := query.Get("start"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "start must be an RFC3339 timestamp")
+			return
+		}
+		rangeStart = t
+	}
+	if !rangeStart.Before(end) {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "start must be before end")
 		return
 	}
-	defer rows.Close()
-
-	var comparisons []LatencyComparison
-	var totalImprovement float64
-	var maxImprovement float64
-	var pairsWithData int
-
-	for rows.Next() {
-		var lc LatencyComparison
-		if err := rows.Scan(
-			&lc.OriginMetroPK,
-			&lc.OriginMetroCode,
-			&lc.OriginMetroName,
-			&lc.TargetMetroPK,
-			&lc.TargetMetroCode,
-			&lc.TargetMetroName,
-			&lc.DzAvgRttMs,
-			&lc.DzP95RttMs,
-			&lc.DzAvgJitterMs,
-			&lc.DzLossPct,
-			&lc.DzSampleCount,
-			&lc.InternetAvgRttMs,
-			&lc.InternetP95RttMs,
-			&lc.InternetAvgJitterMs,
-			&lc.InternetSampleCount,
-			&lc.RttImprovementPct,
-			&lc.JitterImprovementPct,
-		); err != nil {
-			log.Printf("Latency comparison scan error: %v", err)
-			http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+	baseline := defaultLatencyComparisonBaseline
+	if s := query.Get("baseline_window"); s != "" {
+		d, err := parseDayDuration(s)
+		if err != nil || d <= 0 {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "baseline_window must be a positive duration such as 7d or 12h")
 			return
 		}
-
-		if lc.RttImprovementPct != nil {
-			pairsWithData++
-			totalImprovement += *lc.RttImprovementPct
-			if *lc.RttImprovementPct > maxImprovement {
-				maxImprovement = *lc.RttImprovementPct
-			}
+		baseline = d
+	}
+	var devicePKs []string
+	for _, pk := range strings.Split(query.Get("device_pk"), ",") {
+		if pk = strings.TrimSpace(pk); pk != "" {
+			devicePKs = append(devicePKs, pk)
 		}
+	}
 
-		comparisons = append(comparisons, lc)
+	// Metro pair comparisons come from the cache when possible (mainnet only)
+	var response LatencyComparisonResponse
+	if cached := cachedLatencyComparison(ctx); cached != nil {
+		w.Header().Set("X-Cache", "HIT")
+		response = *cached
+	} else {
+		fresh, err := fetchLatencyComparisonData(ctx)
+		if err != nil {
+			LoggerFromContext(ctx).Error("Latency comparison query error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		response = *fresh
 	}
 
-	if err := rows.Err(); err != nil {
-		log.Printf("Latency comparison rows error: %v", err)
-		http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+	links, err := fetchLinkLatencyDeltas(ctx, rangeStart, end, baseline, devicePKs)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Link latency delta query error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
+	response.Links = links
 
-	if comparisons == nil {
-		comparisons = []LatencyComparison{}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// cachedLatencyComparison returns the cached metro pair comparison, which only
+// holds mainnet data
+func cachedLatencyComparison(ctx context.Context) *LatencyComparisonResponse {
+	if !isMainnet(ctx) || statusCache == nil {
+		return nil
 	}
+	return statusCache.GetLatencyComparison()
+}
 
-	avgImprovement := 0.0
-	if pairsWithData > 0 {
-		avgImprovement = totalImprovement / float64(pairsWithData)
+// fetchLinkLatencyDeltas compares each link's average RTT over [start, end) to
+// its average over the baseline window before start. Lost samples are excluded.
+func fetchLinkLatencyDeltas(ctx context.Context, start, end time.Time, baseline time.Duration, devicePKs []string) ([]LinkLatencyDelta, error) {
+	conditions := []string{"event_ts >= ?", "event_ts < ?"}
+	args := []any{start, start, start.Add(-baseline), end}
+	if len(devicePKs) > 0 {
+		conditions = append(conditions, "link_pk IN (SELECT pk FROM dz_links_current WHERE side_a_pk IN (?) OR side_z_pk IN (?))")
+		args = append(args, devicePKs, devicePKs)
 	}
 
-	response := LatencyComparisonResponse{
-		Comparisons: comparisons,
+	queryStart := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH per_link AS (
+			SELECT
+				link_pk,
+				avgIf(rtt_us, NOT loss AND event_ts >= ?) AS current_rtt_us,
+				avgIf(rtt_us, NOT loss AND event_ts < ?) AS baseline_rtt_us
+			FROM fact_dz_device_link_latency
+			WHERE `+strings.Join(conditions, " AND ")+`
+			GROUP BY link_pk
+		)
+		SELECT l.pk, l.code, l.side_a_pk, l.side_z_pk, p.current_rtt_us, p.baseline_rtt_us
+		FROM per_link p
+		JOIN dz_links_current l ON l.pk = p.link_pk
+		WHERE NOT isNaN(p.current_rtt_us)
+		ORDER BY l.code
+	`, args...)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+		return nil, err
 	}
-	response.Summary.TotalPairs = len(comparisons)
-	response.Summary.AvgImprovementPct = avgImprovement
-	response.Summary.MaxImprovementPct = maxImprovement
-	response.Summary.PairsWithData = pairsWithData
+	defer rows.Close()
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(response)
+	links := []LinkLatencyDelta{}
+	for rows.Next() {
+		var d LinkLatencyDelta
+		var currentUs, baselineUs float64
+		if err := rows.Scan(&d.LinkPK, &d.LinkCode, &d.SideAPK, &d.SideZPK, &currentUs, &baselineUs); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+			return nil, err
+		}
+		d.CurrentAvgMs = currentUs / 1000
+		// Links without baseline samples have no delta
+		if !math.IsNaN(baselineUs) {
+			baselineMs := baselineUs / 1000
+			deltaMs := d.CurrentAvgMs - baselineMs
+			d.BaselineAvgMs = &baselineMs
+			d.DeltaMs = &deltaMs
+			if baselineMs > 0 {
+				deltaPct := deltaMs * 100 / baselineMs
+				d.DeltaPct = &deltaPct
+			}
+		}
+		links = append(links, d)
+	}
+	metrics.RecordClickHouseQuery(time.Since(queryStart), rows.Err())
+	return links, rows.Err()
 }
 
 // fetchLatencyComparisonData fetches DZ vs Internet latency comparison data.
@@ -1040,7 +1081,7 @@ func GetLatencyHistory(w http.ResponseWriter, r *http.Request) {
 	targetCode := chi.URLParam(r, "target")
 
 	if originCode == "" || targetCode == "" {
-		http.Error(w, "origin and target metro codes required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "origin and target metro codes required")
 		return
 	}
 
@@ -1156,8 +1197,8 @@ func GetLatencyHistory(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Latency history query error: %v", err)
-		http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Latency history query error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -1174,16 +1215,16 @@ func GetLatencyHistory(w http.ResponseWriter, r *http.Request) {
 			&p.InetAvgJitterMs,
 			&p.InetSampleCount,
 		); err != nil {
-			log.Printf("Latency history scan error: %v", err)
-			http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Latency history scan error", "error", err)
+			writeDBError(w, r, err)
 			return
 		}
 		points = append(points, p)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Latency history rows error: %v", err)
-		http.Error(w, dberror.UserMessage(err), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Latency history rows error", "error", err)
+		writeDBError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// TopologyEntityChanges lists the fields of an entity that changed
+type TopologyEntityChanges struct {
+	PK      string        `json:"pk"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// TopologyEntityDelta is the net change to a set of entities since a point in time
+type TopologyEntityDelta[T any] struct {
+	Added   []T                     `json:"added"`
+	Removed []T                     `json:"removed"`
+	Updated []TopologyEntityChanges `json:"updated"`
+}
+
+// TopologyDeltaResponse is the response for the topology delta endpoint
+type TopologyDeltaResponse struct {
+	Since   time.Time                         `json:"since"`
+	Links   TopologyEntityDelta[LinkEntity]   `json:"links"`
+	Devices TopologyEntityDelta[DeviceEntity] `json:"devices"`
+}
+
+// appendIfChanged appends a FieldChange when old and new differ
+func appendIfChanged[T comparable](changes []FieldChange, field string, old, new T) []FieldChange {
+	if old != new {
+		changes = append(changes, FieldChange{Field: field, OldValue: old, NewValue: new})
+	}
+	return changes
+}
+
+func linkFieldChanges(before, after LinkEntity) []FieldChange {
+	var c []FieldChange
+	c = appendIfChanged(c, "code", before.Code, after.Code)
+	c = appendIfChanged(c, "status", before.Status, after.Status)
+	c = appendIfChanged(c, "link_type", before.LinkType, after.LinkType)
+	c = appendIfChanged(c, "tunnel_net", before.TunnelNet, after.TunnelNet)
+	c = appendIfChanged(c, "contributor_pk", before.ContributorPK, after.ContributorPK)
+	c = appendIfChanged(c, "side_a_pk", before.SideAPK, after.SideAPK)
+	c = appendIfChanged(c, "side_z_pk", before.SideZPK, after.SideZPK)
+	c = appendIfChanged(c, "side_a_iface_name", before.SideAIfaceName, after.SideAIfaceName)
+	c = appendIfChanged(c, "side_z_iface_name", before.SideZIfaceName, after.SideZIfaceName)
+	c = appendIfChanged(c, "committed_rtt_ns", before.CommittedRttNs, after.CommittedRttNs)
+	c = appendIfChanged(c, "committed_jitter_ns", before.CommittedJitterNs, after.CommittedJitterNs)
+	c = appendIfChanged(c, "bandwidth_bps", before.BandwidthBps, after.BandwidthBps)
+	c = appendIfChanged(c, "isis_delay_override_ns", before.ISISDelayOverride, after.ISISDelayOverride)
+	return c
+}
+
+func deviceFieldChanges(before, after DeviceEntity) []FieldChange {
+	var c []FieldChange
+	c = appendIfChanged(c, "code", before.Code, after.Code)
+	c = appendIfChanged(c, "status", before.Status, after.Status)
+	c = appendIfChanged(c, "device_type", before.DeviceType, after.DeviceType)
+	c = appendIfChanged(c, "public_ip", before.PublicIP, after.PublicIP)
+	c = appendIfChanged(c, "contributor_pk", before.ContributorPK, after.ContributorPK)
+	c = appendIfChanged(c, "metro_pk", before.MetroPK, after.MetroPK)
+	c = appendIfChanged(c, "max_users", before.MaxUsers, after.MaxUsers)
+	return c
+}
+
+// entityState is an entity's latest snapshot as of some time
+type entityState[T any] struct {
+	entity  T
+	deleted bool
+}
+
+// buildTopologyEntityDelta compares entity states before and after a point in
+// time. An entity is added if it didn't exist (or was deleted) before and
+// exists after, removed if the reverse, and updated if any field changed.
+func buildTopologyEntityDelta[T any](
+	before, after map[string]entityState[T],
+	pkOf func(T) string,
+	diff func(before, after T) []FieldChange,
+) TopologyEntityDelta[T] {
+	delta := TopologyEntityDelta[T]{
+		Added:   []T{},
+		Removed: []T{},
+		Updated: []TopologyEntityChanges{},
+	}
+
+	ids := make([]string, 0, len(after))
+	for id := range after {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		a := after[id]
+		b, existed := before[id]
+		existed = existed && !b.deleted
+		switch {
+		case !existed && !a.deleted:
+			delta.Added = append(delta.Added, a.entity)
+		case existed && a.deleted:
+			delta.Removed = append(delta.Removed, b.entity)
+		case existed:
+			if changes := diff(b.entity, a.entity); len(changes) > 0 {
+				delta.Updated = append(delta.Updated, TopologyEntityChanges{PK: pkOf(a.entity), Changes: changes})
+			}
+		}
+	}
+	return delta
+}
+
+// queryLinkStates returns the latest state of each link that changed after
+// since, as of asOf (or now when asOf is nil)
+func queryLinkStates(ctx context.Context, since time.Time, asOf *time.Time) (map[string]entityState[LinkEntity], error) {
+	query := `
+		SELECT
+			entity_id,
+			argMax(pk, (snapshot_ts, ingested_at)),
+			argMax(code, (snapshot_ts, ingested_at)),
+			argMax(status, (snapshot_ts, ingested_at)),
+			argMax(link_type, (snapshot_ts, ingested_at)),
+			argMax(tunnel_net, (snapshot_ts, ingested_at)),
+			argMax(contributor_pk, (snapshot_ts, ingested_at)),
+			argMax(side_a_pk, (snapshot_ts, ingested_at)),
+			argMax(side_z_pk, (snapshot_ts, ingested_at)),
+			argMax(side_a_iface_name, (snapshot_ts, ingested_at)),
+			argMax(side_z_iface_name, (snapshot_ts, ingested_at)),
+			argMax(committed_rtt_ns, (snapshot_ts, ingested_at)),
+			argMax(committed_jitter_ns, (snapshot_ts, ingested_at)),
+			argMax(bandwidth_bps, (snapshot_ts, ingested_at)),
+			argMax(isis_delay_override_ns, (snapshot_ts, ingested_at)),
+			argMax(is_deleted, (snapshot_ts, ingested_at))
+		FROM dim_dz_links_history
+		WHERE entity_id IN (SELECT entity_id FROM dim_dz_links_history WHERE snapshot_ts > ?)`
+	args := []any{since}
+	if asOf != nil {
+		query += ` AND snapshot_ts <= ?`
+		args = append(args, *asOf)
+	}
+	query += ` GROUP BY entity_id`
+
+	rows, err := envDB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[string]entityState[LinkEntity])
+	for rows.Next() {
+		var id string
+		var l LinkEntity
+		var deleted uint8
+		if err := rows.Scan(
+			&id, &l.PK, &l.Code, &l.Status, &l.LinkType, &l.TunnelNet, &l.ContributorPK,
+			&l.SideAPK, &l.SideZPK, &l.SideAIfaceName, &l.SideZIfaceName,
+			&l.CommittedRttNs, &l.CommittedJitterNs, &l.BandwidthBps, &l.ISISDelayOverride, &deleted,
+		); err != nil {
+			return nil, err
+		}
+		states[id] = entityState[LinkEntity]{entity: l, deleted: deleted == 1}
+	}
+	return states, rows.Err()
+}
+
+// queryDeviceStates returns the latest state of each device that changed after
+// since, as of asOf (or now when asOf is nil)
+func queryDeviceStates(ctx context.Context, since time.Time, asOf *time.Time) (map[string]entityState[DeviceEntity], error) {
+	query := `
+		SELECT
+			entity_id,
+			argMax(pk, (snapshot_ts, ingested_at)),
+			argMax(code, (snapshot_ts, ingested_at)),
+			argMax(status, (snapshot_ts, ingested_at)),
+			argMax(device_type, (snapshot_ts, ingested_at)),
+			argMax(public_ip, (snapshot_ts, ingested_at)),
+			argMax(contributor_pk, (snapshot_ts, ingested_at)),
+			argMax(metro_pk, (snapshot_ts, ingested_at)),
+			argMax(max_users, (snapshot_ts, ingested_at)),
+			argMax(is_deleted, (snapshot_ts, ingested_at))
+		FROM dim_dz_devices_history
+		WHERE entity_id IN (SELECT entity_id FROM dim_dz_devices_history WHERE snapshot_ts > ?)`
+	args := []any{since}
+	if asOf != nil {
+		query += ` AND snapshot_ts <= ?`
+		args = append(args, *asOf)
+	}
+	query += ` GROUP BY entity_id`
+
+	rows, err := envDB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[string]entityState[DeviceEntity])
+	for rows.Next() {
+		var id string
+		var d DeviceEntity
+		var deleted uint8
+		if err := rows.Scan(
+			&id, &d.PK, &d.Code, &d.Status, &d.DeviceType, &d.PublicIP,
+			&d.ContributorPK, &d.MetroPK, &d.MaxUsers, &deleted,
+		); err != nil {
+			return nil, err
+		}
+		states[id] = entityState[DeviceEntity]{entity: d, deleted: deleted == 1}
+	}
+	return states, rows.Err()
+}
+
+// topologyDeltaETag returns an ETag for the delta since since, based on the
+// latest link or device snapshot. The delta can only change when a new
+// snapshot is written.
+func topologyDeltaETag(ctx context.Context, since time.Time) (string, error) {
+	var linksTS, devicesTS time.Time
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT
+			(SELECT max(snapshot_ts) FROM dim_dz_links_history),
+			(SELECT max(snapshot_ts) FROM dim_dz_devices_history)
+	`).Scan(&linksTS, &devicesTS)
+	if err != nil {
+		return "", err
+	}
+	latest := linksTS
+	if devicesTS.After(latest) {
+		latest = devicesTS
+	}
+	return `"` + strconv.FormatInt(latest.UnixMilli(), 36) + "-" + strconv.FormatInt(since.UnixMilli(), 36) + `"`, nil
+}
+
+// GetTopologyDelta returns links and devices added, removed or updated since
+// the since timestamp (RFC 3339), for incremental topology updates. The ETag
+// changes only when new snapshots are written, so polling clients can send
+// If-None-Match to get 304 Not Modified.
+func GetTopologyDelta(w http.ResponseWriter, r *http.Request) {
+	sinceParam := r.URL.Query().Get("since")
+	if sinceParam == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "since is required")
+		return
+	}
+	since, err := time.Parse(time.RFC3339, sinceParam)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "since must be an RFC 3339 timestamp")
+		return
+	}
+	since = since.UTC()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	etag, err := topologyDeltaETag(ctx, since)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("Topology delta etag query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			metrics.RecordClickHouseQuery(time.Since(start), nil)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	linksBefore, err := queryLinkStates(ctx, since, &since)
+	var linksAfter map[string]entityState[LinkEntity]
+	if err == nil {
+		linksAfter, err = queryLinkStates(ctx, since, nil)
+	}
+	var devicesBefore, devicesAfter map[string]entityState[DeviceEntity]
+	if err == nil {
+		devicesBefore, err = queryDeviceStates(ctx, since, &since)
+	}
+	if err == nil {
+		devicesAfter, err = queryDeviceStates(ctx, since, nil)
+	}
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Topology delta query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, TopologyDeltaResponse{
+		Since:   since,
+		Links:   buildTopologyEntityDelta(linksBefore, linksAfter, func(l LinkEntity) string { return l.PK }, linkFieldChanges),
+		Devices: buildTopologyEntityDelta(devicesBefore, devicesAfter, func(d DeviceEntity) string { return d.PK }, deviceFieldChanges),
+	})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTopologyEntityDelta(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]entityState[DeviceEntity]{
+		"updated":   {entity: DeviceEntity{PK: "updated", Status: "activated", MaxUsers: 10}},
+		"unchanged": {entity: DeviceEntity{PK: "unchanged", Status: "activated"}},
+		"removed":   {entity: DeviceEntity{PK: "removed", Code: "OLD"}},
+		"revived":   {entity: DeviceEntity{PK: "revived"}, deleted: true},
+	}
+	after := map[string]entityState[DeviceEntity]{
+		"updated":   {entity: DeviceEntity{PK: "updated", Status: "drained", MaxUsers: 20}},
+		"unchanged": {entity: DeviceEntity{PK: "unchanged", Status: "activated"}},
+		"removed":   {entity: DeviceEntity{PK: "removed", Code: "OLD"}, deleted: true},
+		"revived":   {entity: DeviceEntity{PK: "revived"}},
+		"new":       {entity: DeviceEntity{PK: "new"}},
+		"ephemeral": {entity: DeviceEntity{PK: "ephemeral"}, deleted: true},
+	}
+
+	delta := buildTopologyEntityDelta(before, after, func(d DeviceEntity) string { return d.PK }, deviceFieldChanges)
+
+	require.Len(t, delta.Added, 2)
+	assert.Equal(t, "new", delta.Added[0].PK)
+	assert.Equal(t, "revived", delta.Added[1].PK)
+
+	require.Len(t, delta.Removed, 1)
+	assert.Equal(t, "OLD", delta.Removed[0].Code, "removed entities carry their last live state")
+
+	require.Len(t, delta.Updated, 1)
+	assert.Equal(t, "updated", delta.Updated[0].PK)
+	assert.Equal(t, []FieldChange{
+		{Field: "status", OldValue: "activated", NewValue: "drained"},
+		{Field: "max_users", OldValue: int32(10), NewValue: int32(20)},
+	}, delta.Updated[0].Changes)
+}
@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedTopologyDelta inserts snapshots 2h ago for links l-keep, l-update and
+// l-remove and device d-update, then 30m ago adds l-new, updates l-update's
+// bandwidth and d-update's status, and deletes l-remove.
+func seedTopologyDelta(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, status, code, tunnel_net,
+		 contributor_pk, side_a_pk, side_z_pk, side_a_iface_name, side_z_iface_name, link_type,
+		 committed_rtt_ns, committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('l-keep', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 1, 'l-keep', 'activated', 'KEEP', '', '', 'd-a', 'd-z', '', '', 'WAN', 0, 0, 100, 0),
+		('l-update', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 2, 'l-update', 'activated', 'UPD', '', '', 'd-a', 'd-z', '', '', 'WAN', 0, 0, 100, 0),
+		('l-remove', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 3, 'l-remove', 'activated', 'REM', '', '', 'd-a', 'd-z', '', '', 'WAN', 0, 0, 100, 0),
+		('l-update', now() - INTERVAL 30 MINUTE, now(), generateUUIDv4(), 0, 4, 'l-update', 'activated', 'UPD', '', '', 'd-a', 'd-z', '', '', 'WAN', 0, 0, 200, 0),
+		('l-remove', now() - INTERVAL 30 MINUTE, now(), generateUUIDv4(), 1, 3, 'l-remove', 'activated', 'REM', '', '', 'd-a', 'd-z', '', '', 'WAN', 0, 0, 100, 0),
+		('l-new', now() - INTERVAL 30 MINUTE, now(), generateUUIDv4(), 0, 5, 'l-new', 'activated', 'NEW', '', '', 'd-a', 'd-z', '', '', 'WAN', 0, 0, 100, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('d-update', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 1, 'd-update', 'activated', 'hybrid', 'AMS-1', '', '', '', 0),
+		('d-update', now() - INTERVAL 30 MINUTE, now(), generateUUIDv4(), 0, 2, 'd-update', 'drained', 'hybrid', 'AMS-1', '', '', '', 0)`))
+}
+
+func getTopologyDelta(since time.Time, headers map[string]string) *httptest.ResponseRecorder {
+	q := url.Values{"since": {since.UTC().Format(time.RFC3339)}}
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/links/topology-delta?"+q.Encode(), nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	handlers.GetTopologyDelta(rr, req)
+	return rr
+}
+
+func TestGetTopologyDelta(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedTopologyDelta(t)
+
+	rr := getTopologyDelta(time.Now().Add(-time.Hour), nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+
+	var resp handlers.TopologyDeltaResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+
+	require.Len(t, resp.Links.Added, 1)
+	assert.Equal(t, "l-new", resp.Links.Added[0].PK)
+	require.Len(t, resp.Links.Removed, 1)
+	assert.Equal(t, "l-remove", resp.Links.Removed[0].PK)
+	require.Len(t, resp.Links.Updated, 1)
+	assert.Equal(t, "l-update", resp.Links.Updated[0].PK)
+	require.Len(t, resp.Links.Updated[0].Changes, 1)
+	assert.Equal(t, "bandwidth_bps", resp.Links.Updated[0].Changes[0].Field)
+
+	assert.Empty(t, resp.Devices.Added)
+	require.Len(t, resp.Devices.Updated, 1)
+	assert.Equal(t, "d-update", resp.Devices.Updated[0].PK)
+	assert.Equal(t, "status", resp.Devices.Updated[0].Changes[0].Field)
+
+	// Nothing changed in the last 10 minutes
+	rr = getTopologyDelta(time.Now().Add(-10*time.Minute), nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Links.Added)
+	assert.Empty(t, resp.Links.Updated)
+	assert.Empty(t, resp.Devices.Updated)
+}
+
+func TestGetTopologyDelta_ConditionalGET(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedTopologyDelta(t)
+
+	since := time.Now().Add(-time.Hour)
+	first := getTopologyDelta(since, nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+
+	cached := getTopologyDelta(since, map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, cached.Code)
+	assert.Empty(t, cached.Body.String())
+
+	// A new snapshot changes the ETag
+	require.NoError(t, config.DB.Exec(t.Context(), `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, status, code, tunnel_net,
+		 contributor_pk, side_a_pk, side_z_pk, side_a_iface_name, side_z_iface_name, link_type,
+		 committed_rtt_ns, committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES ('l-keep', now(), now(), generateUUIDv4(), 0, 9, 'l-keep', 'soft-drained', 'KEEP', '', '', 'd-a', 'd-z', '', '', 'WAN', 0, 0, 100, 0)`))
+	stale := getTopologyDelta(since, map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, stale.Code)
+	assert.NotEqual(t, etag, stale.Header().Get("ETag"))
+}
+
+func TestGetTopologyDelta_BadRequest(t *testing.T) {
+	for _, q := range []string{"", "?since=yesterday"} {
+		rr := httptest.NewRecorder()
+		handlers.GetTopologyDelta(rr, httptest.NewRequest(http.MethodGet, "/api/dz/links/topology-delta"+q, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, q)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -179,8 +178,8 @@ func GetTrafficData(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Traffic query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Traffic query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -215,8 +214,8 @@ func GetTrafficData(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Traffic mean query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Traffic mean query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer meanRows.Close()
@@ -227,8 +226,8 @@ func GetTrafficData(w http.ResponseWriter, r *http.Request) {
 		var device, intf string
 		var meanIn, meanOut float64
 		if err := meanRows.Scan(&device, &intf, &meanIn, &meanOut); err != nil {
-			log.Printf("Traffic mean row scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Traffic mean row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		key := fmt.Sprintf("%s-%s", device, intf)
@@ -261,7 +260,7 @@ func GetTrafficData(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var point TrafficPoint
 		if err := rows.Scan(&point.Time, &point.DevicePk, &point.Device, &point.Intf, &point.InBps, &point.OutBps); err != nil {
-			log.Printf("Traffic row scan error: %v", err)
+			LoggerFromContext(ctx).Error("Traffic row scan error", "error", err)
 			// Already started writing — can't send HTTP error. Log and break.
 			scanErr = err
 			break
@@ -271,7 +270,7 @@ func GetTrafficData(w http.ResponseWriter, r *http.Request) {
 		}
 		pointJSON, err := json.Marshal(point)
 		if err != nil {
-			log.Printf("Traffic point encode error: %v", err)
+			LoggerFromContext(ctx).Error("Traffic point encode error", "error", err)
 			scanErr = err
 			break
 		}
@@ -281,7 +280,7 @@ func GetTrafficData(w http.ResponseWriter, r *http.Request) {
 
 	if scanErr == nil {
 		if err := rows.Err(); err != nil {
-			log.Printf("Rows iteration error: %v", err)
+			LoggerFromContext(ctx).Error("Rows iteration error", "error", err)
 		}
 	}
 
@@ -388,8 +387,8 @@ func GetDiscardsData(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Discards query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Discards query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -401,8 +400,8 @@ func GetDiscardsData(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var point DiscardsPoint
 		if err := rows.Scan(&point.Time, &point.DevicePk, &point.Device, &point.Intf, &point.InDiscards, &point.OutDiscards); err != nil {
-			log.Printf("Discards row scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Discards row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 
@@ -433,8 +432,8 @@ func GetDiscardsData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -456,7 +455,7 @@ func GetDiscardsData(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
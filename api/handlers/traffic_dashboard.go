@@ -4,8 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -744,6 +745,59 @@ func BuildHealthQuery(timeFilter, sortMetric, sortDir, filterSQL, intfFilterSQL,
 		orderCol, dir, limit)
 }
 
+// BuildAnomalyQuery builds the ClickHouse query for the anomaly endpoint.
+// Each link's side A interface is bucketed over the past 7 days to build a
+// per-direction baseline; the latest bucket inside timeFilter is the current reading.
+// Buckets inside timeFilter are left out of the baseline, so an anomaly in the
+// current window doesn't skew the mean and stddev it is measured against.
+func BuildAnomalyQuery(timeFilter, bucketInterval, filterSQL, intfFilterSQL string, minZScore float64, limit int) string {
+	return fmt.Sprintf(`
+		WITH bucketed AS (
+			SELECT
+				toStartOfInterval(f.event_ts, INTERVAL %s) AS bucket_ts,
+				f.link_pk AS link_pk,
+				avg(f.in_octets_delta * 8 / f.delta_duration) AS in_bps,
+				avg(f.out_octets_delta * 8 / f.delta_duration) AS out_bps,
+				countIf(%s) > 0 AS in_window
+			FROM fact_dz_device_interface_counters f
+			INNER JOIN dz_links_current l ON f.link_pk = l.pk AND f.device_pk = l.side_a_pk
+			INNER JOIN dz_devices_current d ON f.device_pk = d.pk
+			LEFT JOIN dz_metros_current m ON d.metro_pk = m.pk
+			LEFT JOIN dz_contributors_current co ON d.contributor_pk = co.pk
+			WHERE (f.event_ts >= now() - INTERVAL 7 DAY OR %s)
+				AND f.link_pk != ''
+				AND f.delta_duration > 0
+				AND f.in_octets_delta >= 0
+				AND f.out_octets_delta >= 0
+				%s
+				%s
+			GROUP BY bucket_ts, f.link_pk
+		),
+		series AS (
+			SELECT link_pk, bucket_ts, in_window, 'in' AS direction, in_bps AS bps FROM bucketed
+			UNION ALL
+			SELECT link_pk, bucket_ts, in_window, 'out' AS direction, out_bps AS bps FROM bucketed
+		)
+		SELECT
+			s.link_pk,
+			l.code AS link_code,
+			s.direction,
+			argMaxIf(s.bps, s.bucket_ts, s.in_window) AS current_bps,
+			avgIf(s.bps, NOT s.in_window) AS mean_bps,
+			stddevPopIf(s.bps, NOT s.in_window) AS stddev_bps,
+			(current_bps - mean_bps) / stddev_bps AS z_score
+		FROM series s
+		INNER JOIN dz_links_current l ON s.link_pk = l.pk
+		GROUP BY s.link_pk, l.code, s.direction
+		HAVING countIf(s.in_window) > 0
+			AND countIf(NOT s.in_window) > 0
+			AND stddev_bps > 0
+			AND abs(z_score) >= %f
+		ORDER BY abs(z_score) DESC
+		LIMIT %d`,
+		bucketInterval, timeFilter, timeFilter, filterSQL, intfFilterSQL, minZScore, limit)
+}
+
 // --- Health endpoint ---
 
 type HealthEntity struct {
@@ -795,8 +849,8 @@ func GetTrafficDashboardHealth(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Traffic dashboard health query error: %v\nQuery: %s", err, query)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Traffic dashboard health query error", "error", err, "query", query)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -807,8 +861,8 @@ func GetTrafficDashboardHealth(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(&e.DevicePk, &e.DeviceCode, &e.Intf, &e.MetroCode,
 			&e.TotalErrors, &e.TotalDiscards, &e.TotalFcsErrors,
 			&e.TotalCarrierTransitions, &e.TotalEvents, &e.ContributorCode); err != nil {
-			log.Printf("Traffic dashboard health row scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Traffic dashboard health row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		entities = append(entities, e)
@@ -880,8 +934,8 @@ func GetTrafficDashboardStress(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Traffic dashboard stress query error: %v\nQuery: %s", err, query)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Traffic dashboard stress query error", "error", err, "query", query)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -908,8 +962,8 @@ func GetTrafficDashboardStress(w http.ResponseWriter, r *http.Request) {
 			var p50In, p95In, maxIn, p50Out, p95Out, maxOut float64
 			var sc, tc uint64
 			if err := rows.Scan(&ts, &gk, &gl, &p50In, &p95In, &maxIn, &p50Out, &p95Out, &maxOut, &sc, &tc); err != nil {
-				log.Printf("Traffic dashboard stress row scan error: %v", err)
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				LoggerFromContext(ctx).Error("Traffic dashboard stress row scan error", "error", err)
+				writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 				return
 			}
 			if !tsSet[ts] {
@@ -973,8 +1027,8 @@ func GetTrafficDashboardStress(w http.ResponseWriter, r *http.Request) {
 			var p50In, p95In, maxIn, p50Out, p95Out, maxOut float64
 			var sc, tc uint64
 			if err := rows.Scan(&ts, &p50In, &p95In, &maxIn, &p50Out, &p95Out, &maxOut, &sc, &tc); err != nil {
-				log.Printf("Traffic dashboard stress row scan error: %v", err)
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				LoggerFromContext(ctx).Error("Traffic dashboard stress row scan error", "error", err)
+				writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 				return
 			}
 			timestamps = append(timestamps, ts)
@@ -1076,8 +1130,8 @@ func GetTrafficDashboardTop(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Traffic dashboard top query error: %v\nQuery: %s", err, query)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Traffic dashboard top query error", "error", err, "query", query)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -1089,8 +1143,8 @@ func GetTrafficDashboardTop(w http.ResponseWriter, r *http.Request) {
 			&e.LinkType, &e.ContributorCode, &e.BandwidthBps,
 			&e.MaxUtil, &e.AvgUtil, &e.P95Util,
 			&e.MaxInBps, &e.MaxOutBps); err != nil {
-			log.Printf("Traffic dashboard top row scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Traffic dashboard top row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		entities = append(entities, e)
@@ -1134,7 +1188,7 @@ func GetTrafficDashboardDrilldown(w http.ResponseWriter, r *http.Request) {
 
 	devicePk := r.URL.Query().Get("device_pk")
 	if devicePk == "" {
-		http.Error(w, "device_pk is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "device_pk is required")
 		return
 	}
 
@@ -1159,8 +1213,8 @@ func GetTrafficDashboardDrilldown(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Traffic dashboard drilldown query error: %v\nQuery: %s", err, query)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Traffic dashboard drilldown query error", "error", err, "query", query)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -1171,8 +1225,8 @@ func GetTrafficDashboardDrilldown(w http.ResponseWriter, r *http.Request) {
 		var p DrilldownPoint
 		var inDisc, outDisc int64
 		if err := rows.Scan(&p.Time, &p.Intf, &p.InBps, &p.OutBps, &inDisc, &outDisc, &p.InPps, &p.OutPps); err != nil {
-			log.Printf("Traffic dashboard drilldown row scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Traffic dashboard drilldown row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		p.InDiscards = inDisc
@@ -1300,8 +1354,8 @@ func GetTrafficDashboardBurstiness(w http.ResponseWriter, r *http.Request) {
 		if ctx.Err() != nil {
 			return
 		}
-		log.Printf("Traffic dashboard burstiness query error: %v\nQuery: %s", err, query)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Traffic dashboard burstiness query error", "error", err, "query", query)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -1312,8 +1366,8 @@ func GetTrafficDashboardBurstiness(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(&e.DevicePk, &e.DeviceCode, &e.Intf, &e.MetroCode,
 			&e.BandwidthBps, &e.P50Util, &e.P99Util, &e.Burstiness,
 			&e.PctTimeStressed, &e.P50Bps, &e.P99Bps, &e.PeakDirection, &e.ContributorCode); err != nil {
-			log.Printf("Traffic dashboard burstiness row scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Traffic dashboard burstiness row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		entities = append(entities, e)
@@ -1323,3 +1377,281 @@ func GetTrafficDashboardBurstiness(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
+
+// --- Anomaly endpoint ---
+
+// anomalyCriticalZScore is the absolute z-score at which an anomaly is critical.
+const anomalyCriticalZScore = 5.0
+
+type TrafficAnomaly struct {
+	LinkPK     string  `json:"linkPK"`
+	LinkCode   string  `json:"linkCode"`
+	Direction  string  `json:"direction"`
+	CurrentBps float64 `json:"currentBps"`
+	MeanBps    float64 `json:"meanBps"`
+	StddevBps  float64 `json:"stddevBps"`
+	ZScore     float64 `json:"zScore"`
+	Severity   string  `json:"severity"`
+}
+
+type AnomalyResponse struct {
+	Anomalies []TrafficAnomaly `json:"anomalies"`
+}
+
+func GetTrafficDashboardAnomaly(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	timeFilter, _ := dashboardTimeFilter(r)
+
+	// The baseline spans 7 days, so use a coarser default bucket than the other endpoints
+	bucketInterval := "5 MINUTE"
+	if bp := parseBucket(r.URL.Query().Get("bucket")); bp != "" {
+		bucketInterval = bp
+	}
+
+	minZScore := 3.0
+	if z := r.URL.Query().Get("min_z_score"); z != "" {
+		if v, err := strconv.ParseFloat(z, 64); err == nil && v > 0 {
+			minZScore = v
+		}
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 500 {
+			limit = v
+		}
+	}
+
+	filterSQL, intfFilterSQL, _, _, _, _, _, _, _ := buildDimensionFilters(r)
+
+	query := BuildAnomalyQuery(timeFilter, bucketInterval, filterSQL, intfFilterSQL, minZScore, limit)
+
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, query)
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		LoggerFromContext(ctx).Error("Traffic dashboard anomaly query error", "error", err, "query", query)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	anomalies := []TrafficAnomaly{}
+	for rows.Next() {
+		var a TrafficAnomaly
+		if err := rows.Scan(&a.LinkPK, &a.LinkCode, &a.Direction,
+			&a.CurrentBps, &a.MeanBps, &a.StddevBps, &a.ZScore); err != nil {
+			LoggerFromContext(ctx).Error("Traffic dashboard anomaly row scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		a.Severity = "warning"
+		if math.Abs(a.ZScore) >= anomalyCriticalZScore {
+			a.Severity = "critical"
+		}
+		anomalies = append(anomalies, a)
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Traffic dashboard anomaly rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	resp := AnomalyResponse{Anomalies: anomalies}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// --- Capacity planning endpoint ---
+
+const (
+	capacityPlanningWindowDays   = 30
+	capacityCriticalHorizonDays  = 30
+	capacityWarningHorizonDays   = 90
+	capacityPlanningWarningRatio = 0.8
+
+	// capacityProjectionHorizon bounds projections so a near-zero fitted
+	// slope reads as flat rather than centuries away
+	capacityProjectionHorizon = 10 * 365 * 24 * time.Hour
+)
+
+// BuildCapacityPlanningQuery builds the ClickHouse query for the capacity
+// planning endpoint. Each link's side A interface is reduced to a daily p95 of
+// the busier direction, and a linear trend is fitted to those points. Time is
+// measured in seconds relative to now, so the intercept is the trend's
+// current value.
+func BuildCapacityPlanningQuery(filterSQL, intfFilterSQL string) string {
+	return fmt.Sprintf(`
+		WITH daily AS (
+			SELECT
+				toStartOfDay(f.event_ts) AS day,
+				f.link_pk AS link_pk,
+				quantile(0.95)(greatest(f.in_octets_delta, f.out_octets_delta) * 8 / f.delta_duration) AS traffic_bps
+			FROM fact_dz_device_interface_counters f
+			INNER JOIN dz_links_current l ON f.link_pk = l.pk AND f.device_pk = l.side_a_pk
+			INNER JOIN dz_devices_current d ON f.device_pk = d.pk
+			LEFT JOIN dz_metros_current m ON d.metro_pk = m.pk
+			LEFT JOIN dz_contributors_current co ON d.contributor_pk = co.pk
+			WHERE f.event_ts >= now() - INTERVAL %d DAY
+				AND f.link_pk != ''
+				AND f.delta_duration > 0
+				AND f.in_octets_delta >= 0
+				AND f.out_octets_delta >= 0
+				%s
+				%s
+			GROUP BY day, f.link_pk
+		)
+		SELECT
+			s.link_pk,
+			l.code AS link_code,
+			l.bandwidth_bps,
+			argMax(s.traffic_bps, s.day) AS current_bps,
+			tupleElement(simpleLinearRegression(toFloat64(toUnixTimestamp(s.day) - toUnixTimestamp(now())), s.traffic_bps), 1) AS slope,
+			tupleElement(simpleLinearRegression(toFloat64(toUnixTimestamp(s.day) - toUnixTimestamp(now())), s.traffic_bps), 2) AS intercept
+		FROM daily s
+		INNER JOIN dz_links_current l ON s.link_pk = l.pk
+		WHERE l.bandwidth_bps > 0
+		GROUP BY s.link_pk, l.code, l.bandwidth_bps
+		HAVING count() >= 2`,
+		capacityPlanningWindowDays, filterSQL, intfFilterSQL)
+}
+
+// projectExhaustion returns when a linear trend (bps = slope*secondsFromNow +
+// intercept) reaches thresholdBps. It returns now if the latest reading is
+// already at the threshold, and nil if traffic is flat or shrinking or the
+// threshold is beyond capacityProjectionHorizon.
+func projectExhaustion(slope, intercept, currentBps, thresholdBps float64, now time.Time) *time.Time {
+	if currentBps >= thresholdBps {
+		return &now
+	}
+	if slope <= 0 {
+		return nil
+	}
+	seconds := math.Max(0, (thresholdBps-intercept)/slope)
+	if seconds > capacityProjectionHorizon.Seconds() {
+		return nil
+	}
+	at := now.Add(time.Duration(seconds * float64(time.Second)))
+	return &at
+}
+
+// capacitySeverity grades a link by how soon it is projected to be exhausted
+func capacitySeverity(exhaustion *time.Time, now time.Time) string {
+	if exhaustion == nil {
+		return "ok"
+	}
+	switch remaining := exhaustion.Sub(now); {
+	case remaining <= capacityCriticalHorizonDays*24*time.Hour:
+		return "critical"
+	case remaining <= capacityWarningHorizonDays*24*time.Hour:
+		return "warning"
+	default:
+		return "ok"
+	}
+}
+
+type CapacityPlanningLink struct {
+	LinkPK                    string     `json:"linkPK"`
+	LinkCode                  string     `json:"linkCode"`
+	BandwidthBps              int64      `json:"bandwidthBps"`
+	CurrentBps                float64    `json:"currentBps"`
+	CurrentUtilizationPct     float64    `json:"currentUtilizationPct"`
+	GrowthRateBpsPerDay       float64    `json:"growthRateBpsPerDay"`
+	ProjectedExhaustion80Pct  *time.Time `json:"projectedExhaustion80Pct"`
+	ProjectedExhaustion100Pct *time.Time `json:"projectedExhaustion100Pct"`
+	Severity                  string     `json:"severity"` // by 100% exhaustion: critical within 30 days, warning within 90
+}
+
+type CapacityPlanningResponse struct {
+	Links []CapacityPlanningLink `json:"links"`
+}
+
+// GetTrafficCapacityPlanning projects when each link's p95 traffic will reach
+// 80% and 100% of its bandwidth, from a linear trend over the past 30 days.
+// Links are ordered by the soonest full exhaustion.
+func GetTrafficCapacityPlanning(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 500 {
+			limit = v
+		}
+	}
+
+	filterSQL, intfFilterSQL, _, _, _, _, _, _, _ := buildDimensionFilters(r)
+
+	query := BuildCapacityPlanningQuery(filterSQL, intfFilterSQL)
+
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, query)
+	duration := time.Since(start)
+	metrics.RecordClickHouseQuery(duration, err)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		LoggerFromContext(ctx).Error("Traffic dashboard capacity planning query error", "error", err, "query", query)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	now := time.Now().UTC()
+	links := []CapacityPlanningLink{}
+	for rows.Next() {
+		var (
+			l                CapacityPlanningLink
+			slope, intercept float64
+		)
+		if err := rows.Scan(&l.LinkPK, &l.LinkCode, &l.BandwidthBps, &l.CurrentBps, &slope, &intercept); err != nil {
+			LoggerFromContext(ctx).Error("Traffic dashboard capacity planning row scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		if math.IsNaN(slope) || math.IsNaN(intercept) {
+			slope, intercept = 0, l.CurrentBps
+		}
+		bandwidth := float64(l.BandwidthBps)
+		l.CurrentUtilizationPct = l.CurrentBps * 100 / bandwidth
+		l.GrowthRateBpsPerDay = slope * 86400
+		l.ProjectedExhaustion80Pct = projectExhaustion(slope, intercept, l.CurrentBps, bandwidth*capacityPlanningWarningRatio, now)
+		l.ProjectedExhaustion100Pct = projectExhaustion(slope, intercept, l.CurrentBps, bandwidth, now)
+		l.Severity = capacitySeverity(l.ProjectedExhaustion100Pct, now)
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		LoggerFromContext(ctx).Error("Traffic dashboard capacity planning rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	// Soonest exhaustion first; links that never exhaust go last, busiest first
+	sort.SliceStable(links, func(i, j int) bool {
+		a, b := links[i].ProjectedExhaustion100Pct, links[j].ProjectedExhaustion100Pct
+		switch {
+		case a != nil && b != nil && !a.Equal(*b):
+			return a.Before(*b)
+		case (a == nil) != (b == nil):
+			return a != nil
+		default:
+			return links[i].CurrentUtilizationPct > links[j].CurrentUtilizationPct
+		}
+	})
+	if len(links) > limit {
+		links = links[:limit]
+	}
+
+	resp := CapacityPlanningResponse{Links: links}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
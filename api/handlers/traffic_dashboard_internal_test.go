@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectExhaustion(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	perDay := 1e9 / 86400.0 // 1 Gbps growth per day
+	intercept := 40e9
+
+	t.Run("growing", func(t *testing.T) {
+		at := projectExhaustion(perDay, intercept, 40e9, 100e9, now)
+		require.NotNil(t, at)
+		assert.WithinDuration(t, now.AddDate(0, 0, 60), *at, time.Second)
+	})
+
+	t.Run("already exhausted", func(t *testing.T) {
+		at := projectExhaustion(perDay, intercept, 100e9, 100e9, now)
+		require.NotNil(t, at)
+		assert.Equal(t, now, *at)
+	})
+
+	t.Run("trend past threshold", func(t *testing.T) {
+		at := projectExhaustion(perDay, intercept+200e9, 40e9, 100e9, now)
+		require.NotNil(t, at)
+		assert.Equal(t, now, *at)
+	})
+
+	t.Run("flat or shrinking", func(t *testing.T) {
+		assert.Nil(t, projectExhaustion(0, 40e9, 40e9, 100e9, now))
+		assert.Nil(t, projectExhaustion(-perDay, intercept, 40e9, 100e9, now))
+		assert.Nil(t, projectExhaustion(1e-6, intercept, 40e9, 100e9, now))
+	})
+}
+
+func TestCapacitySeverity(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	in := func(days int) *time.Time {
+		at := now.AddDate(0, 0, days)
+		return &at
+	}
+
+	assert.Equal(t, "critical", capacitySeverity(in(0), now))
+	assert.Equal(t, "critical", capacitySeverity(in(30), now))
+	assert.Equal(t, "warning", capacitySeverity(in(31), now))
+	assert.Equal(t, "warning", capacitySeverity(in(90), now))
+	assert.Equal(t, "ok", capacitySeverity(in(91), now))
+	assert.Equal(t, "ok", capacitySeverity(nil, now))
+}
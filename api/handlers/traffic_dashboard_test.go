@@ -1056,3 +1056,193 @@ func TestTrafficDashboardHealth_Empty(t *testing.T) {
 	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
 	assert.Empty(t, resp.Entities)
 }
+
+// --- Anomaly endpoint tests ---
+
+// seedAnomalyData inserts a week of hourly samples on link-1 (side A dev-1) that
+// alternate between 1 and 1.1 Gbps, plus a recent 10 Gbps inbound spike.
+func seedAnomalyData(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-1', now(), now(), generateUUIDv4(), 0, 1, 'dev-1', 'active', 'router', 'ROUTER-FRA-1', '', '', '', 0),
+		('dev-2', now(), now(), generateUUIDv4(), 0, 2, 'dev-2', 'active', 'router', 'ROUTER-AMS-1', '', '', '', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now(), now(), generateUUIDv4(), 0, 1, 'link-1', 'active', 'fra-ams-1', '', '', 'dev-1', 'dev-2', 'Ethernet1', 'Ethernet1', 'WAN', 0, 0, 100000000000, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, link_pk, in_octets_delta, out_octets_delta, delta_duration)
+		SELECT
+			now() - toIntervalHour(number + 2), now(), 'dev-1', 'Ethernet1', 'link-1',
+			3750000000 + (number % 2) * 375000000,
+			3750000000 + (number % 2) * 375000000,
+			30.0
+		FROM numbers(166)`))
+
+	// Side Z sees the same traffic mirrored; it must not count as a second series
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, link_pk, in_octets_delta, out_octets_delta, delta_duration)
+		VALUES
+		(now() - INTERVAL 5 MINUTE, now(), 'dev-1', 'Ethernet1', 'link-1', 37500000000, 3750000000, 30.0),
+		(now() - INTERVAL 5 MINUTE, now(), 'dev-2', 'Ethernet1', 'link-1', 3750000000, 37500000000, 30.0)`))
+}
+
+func TestTrafficDashboardAnomaly(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedAnomalyData(t)
+
+	getAnomalies := func(t *testing.T, query string) handlers.AnomalyResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/traffic/dashboard/anomaly"+query, nil)
+		rr := httptest.NewRecorder()
+		handlers.GetTrafficDashboardAnomaly(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "body: %s", rr.Body.String())
+
+		var resp handlers.AnomalyResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		return resp
+	}
+
+	t.Run("default", func(t *testing.T) {
+		resp := getAnomalies(t, "?time_range=1h")
+
+		require.Len(t, resp.Anomalies, 1)
+		a := resp.Anomalies[0]
+		assert.Equal(t, "link-1", a.LinkPK)
+		assert.Equal(t, "fra-ams-1", a.LinkCode)
+		assert.Equal(t, "in", a.Direction)
+		assert.InDelta(t, 10e9, a.CurrentBps, 1)
+		// The spike is in the current window, so it stays out of the baseline
+		assert.InDelta(t, 1.05e9, a.MeanBps, 1e6)
+		assert.InDelta(t, 0.05e9, a.StddevBps, 1e6)
+		assert.Greater(t, a.ZScore, 3.0)
+		assert.Equal(t, "critical", a.Severity)
+	})
+
+	t.Run("min_z_score", func(t *testing.T) {
+		// Outbound is ~1 stddev below its mean, so a low threshold picks it up too
+		resp := getAnomalies(t, "?time_range=1h&min_z_score=0.5")
+
+		directions := make([]string, 0, len(resp.Anomalies))
+		for _, a := range resp.Anomalies {
+			directions = append(directions, a.Direction)
+		}
+		assert.ElementsMatch(t, []string{"in", "out"}, directions)
+	})
+
+	t.Run("dimension_filter", func(t *testing.T) {
+		resp := getAnomalies(t, "?time_range=1h&link_type=PNI")
+		assert.Empty(t, resp.Anomalies)
+	})
+}
+
+func TestTrafficDashboardAnomaly_Empty(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traffic/dashboard/anomaly?time_range=1h", nil)
+	rr := httptest.NewRecorder()
+
+	handlers.GetTrafficDashboardAnomaly(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp handlers.AnomalyResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Anomalies)
+}
+
+// --- Capacity planning endpoint tests ---
+
+// seedCapacityPlanningData inserts four weeks of samples on two 100 Gbps
+// links: link-1 grows by 1 Gbps per day to 60 Gbps today, link-2 holds at
+// 10 Gbps.
+func seedCapacityPlanningData(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-1', now(), now(), generateUUIDv4(), 0, 1, 'dev-1', 'active', 'router', 'ROUTER-FRA-1', '', '', '', 0),
+		('dev-2', now(), now(), generateUUIDv4(), 0, 2, 'dev-2', 'active', 'router', 'ROUTER-AMS-1', '', '', '', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now(), now(), generateUUIDv4(), 0, 1, 'link-1', 'active', 'fra-ams-1', '', '', 'dev-1', 'dev-2', 'Ethernet1', 'Ethernet1', 'WAN', 0, 0, 100000000000, 0),
+		('link-2', now(), now(), generateUUIDv4(), 0, 2, 'link-2', 'active', 'ams-fra-1', '', '', 'dev-2', 'dev-1', 'Ethernet2', 'Ethernet2', 'WAN', 0, 0, 100000000000, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, link_pk, in_octets_delta, out_octets_delta, delta_duration)
+		SELECT
+			now() - toIntervalDay(number), now(), 'dev-1', 'Ethernet1', 'link-1',
+			225000000000 - number * 3750000000,
+			1000,
+			30.0
+		FROM numbers(28)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, link_pk, in_octets_delta, out_octets_delta, delta_duration)
+		SELECT
+			now() - toIntervalDay(number), now(), 'dev-2', 'Ethernet2', 'link-2',
+			1000,
+			37500000000,
+			30.0
+		FROM numbers(28)`))
+}
+
+func TestTrafficCapacityPlanning(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedCapacityPlanningData(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traffic/dashboard/capacity-planning", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetTrafficCapacityPlanning(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, "body: %s", rr.Body.String())
+
+	var resp handlers.CapacityPlanningResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.Links, 2)
+
+	growing := resp.Links[0]
+	assert.Equal(t, "link-1", growing.LinkPK)
+	assert.Equal(t, "fra-ams-1", growing.LinkCode)
+	assert.InDelta(t, 60.0, growing.CurrentUtilizationPct, 0.01)
+	assert.InDelta(t, 1e9, growing.GrowthRateBpsPerDay, 1e3)
+	require.NotNil(t, growing.ProjectedExhaustion80Pct)
+	require.NotNil(t, growing.ProjectedExhaustion100Pct)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 20), *growing.ProjectedExhaustion80Pct, 24*time.Hour)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 40), *growing.ProjectedExhaustion100Pct, 24*time.Hour)
+	assert.Equal(t, "warning", growing.Severity)
+
+	flat := resp.Links[1]
+	assert.Equal(t, "link-2", flat.LinkPK)
+	assert.InDelta(t, 10.0, flat.CurrentUtilizationPct, 0.01)
+	assert.Nil(t, flat.ProjectedExhaustion80Pct)
+	assert.Nil(t, flat.ProjectedExhaustion100Pct)
+	assert.Equal(t, "ok", flat.Severity)
+}
+
+func TestTrafficCapacityPlanning_Empty(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traffic/dashboard/capacity-planning", nil)
+	rr := httptest.NewRecorder()
+	handlers.GetTrafficCapacityPlanning(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp handlers.CapacityPlanningResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Links)
+}
@@ -78,6 +78,8 @@ var ErrGlobalLimitExceeded = fmt.Errorf("service daily limit reached, please try
 // UsageRecord represents a single usage record for tracking
 type UsageRecord struct {
 	QuestionCount int
+	QueryCount    int
+	GenerateCount int
 	InputTokens   int64
 	OutputTokens  int64
 }
@@ -261,28 +263,32 @@ func RecordUsage(ctx context.Context, account *Account, ip string, usage UsageRe
 	if account != nil {
 		// Authenticated user - use account_id
 		_, err := config.PgPool.Exec(ctx, `
-			INSERT INTO usage_daily (account_id, date, question_count, input_tokens, output_tokens)
-			VALUES ($1, CURRENT_DATE, $2, $3, $4)
+			INSERT INTO usage_daily (account_id, date, question_count, query_count, generate_count, input_tokens, output_tokens)
+			VALUES ($1, CURRENT_DATE, $2, $3, $4, $5, $6)
 			ON CONFLICT (account_id, date) DO UPDATE SET
 				question_count = usage_daily.question_count + EXCLUDED.question_count,
+				query_count = usage_daily.query_count + EXCLUDED.query_count,
+				generate_count = usage_daily.generate_count + EXCLUDED.generate_count,
 				input_tokens = usage_daily.input_tokens + EXCLUDED.input_tokens,
 				output_tokens = usage_daily.output_tokens + EXCLUDED.output_tokens,
 				updated_at = NOW()
-		`, account.ID, usage.QuestionCount, usage.InputTokens, usage.OutputTokens)
+		`, account.ID, usage.QuestionCount, usage.QueryCount, usage.GenerateCount, usage.InputTokens, usage.OutputTokens)
 		if err != nil {
 			return fmt.Errorf("failed to record usage for account: %w", err)
 		}
 	} else {
 		// Anonymous user - use IP address
 		_, err := config.PgPool.Exec(ctx, `
-			INSERT INTO usage_daily (ip_address, date, question_count, input_tokens, output_tokens)
-			VALUES ($1::inet, CURRENT_DATE, $2, $3, $4)
+			INSERT INTO usage_daily (ip_address, date, question_count, query_count, generate_count, input_tokens, output_tokens)
+			VALUES ($1::inet, CURRENT_DATE, $2, $3, $4, $5, $6)
 			ON CONFLICT (ip_address, date) WHERE account_id IS NULL DO UPDATE SET
 				question_count = usage_daily.question_count + EXCLUDED.question_count,
+				query_count = usage_daily.query_count + EXCLUDED.query_count,
+				generate_count = usage_daily.generate_count + EXCLUDED.generate_count,
 				input_tokens = usage_daily.input_tokens + EXCLUDED.input_tokens,
 				output_tokens = usage_daily.output_tokens + EXCLUDED.output_tokens,
 				updated_at = NOW()
-		`, ip, usage.QuestionCount, usage.InputTokens, usage.OutputTokens)
+		`, ip, usage.QuestionCount, usage.QueryCount, usage.GenerateCount, usage.InputTokens, usage.OutputTokens)
 		if err != nil {
 			return fmt.Errorf("failed to record usage for IP: %w", err)
 		}
@@ -298,8 +304,10 @@ func RecordUsage(ctx context.Context, account *Account, ip string, usage UsageRe
 		metrics.RecordUsageTokens(accountType, usage.InputTokens, usage.OutputTokens)
 	}
 
-	// Check global usage thresholds
-	checkAndAlertGlobalUsage(ctx)
+	// Check global usage thresholds; the global limit only covers questions
+	if usage.QuestionCount > 0 {
+		checkAndAlertGlobalUsage(ctx)
+	}
 
 	return nil
 }
@@ -309,6 +317,115 @@ func IncrementQuestionCount(ctx context.Context, account *Account, ip string) er
 	return RecordUsage(ctx, account, ip, UsageRecord{QuestionCount: 1})
 }
 
+// recordAccountUsage records query and generate usage for the authenticated
+// account, if any. Anonymous queries and generations aren't tracked. Errors
+// are logged rather than returned so they never fail the request.
+func recordAccountUsage(ctx context.Context, usage UsageRecord) {
+	account := GetAccountFromContext(ctx)
+	if account == nil {
+		return
+	}
+	if err := RecordUsage(ctx, account, "", usage); err != nil {
+		slog.Error("Failed to record usage", "error", err)
+	}
+}
+
+// UsageHistoryDay is an account's usage on one day
+type UsageHistoryDay struct {
+	Date          string `json:"date"` // YYYY-MM-DD
+	QueryCount    int    `json:"query_count"`
+	GenerateCount int    `json:"generate_count"`
+	ChatCount     int    `json:"chat_count"`
+}
+
+// UsageHistoryTotals is an account's usage summed over a period
+type UsageHistoryTotals struct {
+	QueryCount    int64 `json:"query_count"`
+	GenerateCount int64 `json:"generate_count"`
+	ChatCount     int64 `json:"chat_count"`
+}
+
+// UsageHistoryResponse is the response for GET /api/usage/history
+type UsageHistoryResponse struct {
+	Days         []UsageHistoryDay  `json:"days"`
+	TotalAllTime UsageHistoryTotals `json:"total_all_time"`
+}
+
+// usageHistoryDays is how many days of history, including today, are returned
+const usageHistoryDays = 30
+
+// GetUsageHistoryForAccount returns the account's daily usage over the past
+// usageHistoryDays days, oldest first, and its all-time totals. Past days come
+// from usage_history and today from usage_daily. Days without usage are
+// omitted.
+func GetUsageHistoryForAccount(ctx context.Context, accountID uuid.UUID) (*UsageHistoryResponse, error) {
+	rows, err := config.PgPool.Query(ctx, `
+		SELECT date, query_count, generate_count, chat_count
+		FROM usage_history
+		WHERE account_id = $1 AND date > CURRENT_DATE - $2::int AND date < CURRENT_DATE
+		UNION ALL
+		SELECT date, query_count, generate_count, question_count
+		FROM usage_daily
+		WHERE account_id = $1 AND date = CURRENT_DATE
+		ORDER BY date
+	`, accountID, usageHistoryDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage history: %w", err)
+	}
+	defer rows.Close()
+
+	response := &UsageHistoryResponse{Days: []UsageHistoryDay{}}
+	for rows.Next() {
+		var date time.Time
+		var day UsageHistoryDay
+		if err := rows.Scan(&date, &day.QueryCount, &day.GenerateCount, &day.ChatCount); err != nil {
+			return nil, fmt.Errorf("failed to scan usage history: %w", err)
+		}
+		day.Date = date.Format("2006-01-02")
+		response.Days = append(response.Days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get usage history: %w", err)
+	}
+
+	err = config.PgPool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(query_count), 0), COALESCE(SUM(generate_count), 0), COALESCE(SUM(chat_count), 0)
+		FROM (
+			SELECT query_count, generate_count, chat_count
+			FROM usage_history
+			WHERE account_id = $1 AND date < CURRENT_DATE
+			UNION ALL
+			SELECT query_count, generate_count, question_count
+			FROM usage_daily
+			WHERE account_id = $1 AND date = CURRENT_DATE
+		) usage
+	`, accountID).Scan(&response.TotalAllTime.QueryCount, &response.TotalAllTime.GenerateCount, &response.TotalAllTime.ChatCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all-time usage: %w", err)
+	}
+
+	return response, nil
+}
+
+// RollupUsageHistory copies each account's usage_daily counts for date into
+// usage_history. It's idempotent, so a day can be rolled up again.
+func RollupUsageHistory(ctx context.Context, date time.Time) error {
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO usage_history (account_id, date, query_count, generate_count, chat_count)
+		SELECT account_id, date, query_count, generate_count, question_count
+		FROM usage_daily
+		WHERE account_id IS NOT NULL AND date = $1::date
+		ON CONFLICT (account_id, date) DO UPDATE SET
+			query_count = EXCLUDED.query_count,
+			generate_count = EXCLUDED.generate_count,
+			chat_count = EXCLUDED.chat_count
+	`, date.Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("failed to roll up usage history: %w", err)
+	}
+	return nil
+}
+
 // GetUsageToday returns today's usage for an account or IP
 func GetUsageToday(ctx context.Context, account *Account, ip string) (*UsageRecord, error) {
 	var questionCount int
@@ -424,9 +541,15 @@ func InitUsageMetrics(ctx context.Context) {
 	}
 }
 
-// StartDailyResetWorker starts a worker that resets daily metrics at midnight UTC
+// StartDailyResetWorker starts a worker that resets daily metrics at midnight
+// UTC, after rolling up the day that ended into usage_history. Yesterday is
+// rolled up on start in case the server was down at midnight.
 func StartDailyResetWorker(ctx context.Context) {
 	go func() {
+		if err := RollupUsageHistory(ctx, time.Now().UTC().AddDate(0, 0, -1)); err != nil {
+			slog.Error("Failed to roll up usage history", "error", err)
+		}
+
 		for {
 			// Calculate time until next midnight UTC
 			now := time.Now().UTC()
@@ -437,6 +560,10 @@ func StartDailyResetWorker(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			case <-time.After(sleepDuration):
+				if err := RollupUsageHistory(ctx, nextMidnight.AddDate(0, 0, -1)); err != nil {
+					slog.Error("Failed to roll up usage history", "error", err)
+				}
+
 				slog.Info("Resetting daily usage metrics")
 				metrics.ResetDailyGauge()
 
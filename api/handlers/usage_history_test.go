@@ -0,0 +1,130 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordUsage_QueryAndGenerateCounts(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	require.NoError(t, handlers.RecordUsage(ctx, account, "", handlers.UsageRecord{QueryCount: 1}))
+	require.NoError(t, handlers.RecordUsage(ctx, account, "", handlers.UsageRecord{QueryCount: 1}))
+	require.NoError(t, handlers.RecordUsage(ctx, account, "", handlers.UsageRecord{GenerateCount: 1}))
+	require.NoError(t, handlers.IncrementQuestionCount(ctx, account, ""))
+
+	var queries, generates, questions int
+	require.NoError(t, config.PgPool.QueryRow(ctx, `
+		SELECT query_count, generate_count, question_count
+		FROM usage_daily
+		WHERE account_id = $1 AND date = CURRENT_DATE
+	`, account.ID).Scan(&queries, &generates, &questions))
+	assert.Equal(t, 2, queries)
+	assert.Equal(t, 1, generates)
+	assert.Equal(t, 1, questions)
+}
+
+func TestRollupUsageHistory(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO usage_daily (account_id, date, question_count, query_count, generate_count)
+		VALUES ($1, CURRENT_DATE - 1, 4, 10, 2)
+	`, account.ID)
+	require.NoError(t, err)
+	_, err = config.PgPool.Exec(ctx, `
+		INSERT INTO usage_daily (ip_address, date, question_count)
+		VALUES ('192.168.1.100', CURRENT_DATE - 1, 3)
+	`)
+	require.NoError(t, err)
+
+	var yesterday time.Time
+	require.NoError(t, config.PgPool.QueryRow(ctx, `SELECT CURRENT_DATE - 1`).Scan(&yesterday))
+
+	// Rolling up twice is idempotent
+	require.NoError(t, handlers.RollupUsageHistory(ctx, yesterday))
+	require.NoError(t, handlers.RollupUsageHistory(ctx, yesterday))
+
+	var rows, queries, generates, chats int
+	require.NoError(t, config.PgPool.QueryRow(ctx, `
+		SELECT count(*), SUM(query_count), SUM(generate_count), SUM(chat_count)
+		FROM usage_history
+	`).Scan(&rows, &queries, &generates, &chats))
+	assert.Equal(t, 1, rows, "anonymous usage isn't rolled up")
+	assert.Equal(t, 10, queries)
+	assert.Equal(t, 2, generates)
+	assert.Equal(t, 4, chats)
+}
+
+func TestGetUsageHistory(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	other := createTestAccount(t, ctx)
+	_, err := config.PgPool.Exec(ctx, `
+		INSERT INTO usage_history (account_id, date, query_count, generate_count, chat_count) VALUES
+		($1, CURRENT_DATE - 45, 100, 10, 1),
+		($1, CURRENT_DATE - 2, 5, 1, 2),
+		($1, CURRENT_DATE - 1, 3, 0, 1),
+		($2, CURRENT_DATE - 1, 50, 50, 50)
+	`, account.ID, other.ID)
+	require.NoError(t, err)
+	_, err = config.PgPool.Exec(ctx, `
+		INSERT INTO usage_daily (account_id, date, question_count, query_count, generate_count)
+		VALUES ($1, CURRENT_DATE, 1, 2, 3)
+	`, account.ID)
+	require.NoError(t, err)
+
+	req := withAccount(httptest.NewRequest(http.MethodGet, "/api/usage/history", nil), account)
+	rr := httptest.NewRecorder()
+	handlers.GetUsageHistory(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.UsageHistoryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+
+	// The day 45 days ago is outside the window; today comes from usage_daily
+	require.Len(t, resp.Days, 3)
+	assert.Equal(t, handlers.UsageHistoryDay{Date: resp.Days[0].Date, QueryCount: 5, GenerateCount: 1, ChatCount: 2}, resp.Days[0])
+	assert.Equal(t, 3, resp.Days[1].QueryCount)
+	assert.Equal(t, handlers.UsageHistoryDay{Date: resp.Days[2].Date, QueryCount: 2, GenerateCount: 3, ChatCount: 1}, resp.Days[2])
+	assert.Less(t, resp.Days[0].Date, resp.Days[2].Date)
+
+	assert.Equal(t, handlers.UsageHistoryTotals{QueryCount: 110, GenerateCount: 14, ChatCount: 5}, resp.TotalAllTime)
+}
+
+func TestGetUsageHistory_Empty(t *testing.T) {
+	apitesting.SetupTestDB(t, testPgDB)
+	ctx := t.Context()
+
+	account := createTestAccount(t, ctx)
+	req := withAccount(httptest.NewRequest(http.MethodGet, "/api/usage/history", nil), account)
+	rr := httptest.NewRecorder()
+	handlers.GetUsageHistory(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.UsageHistoryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Days)
+	assert.Equal(t, handlers.UsageHistoryTotals{}, resp.TotalAllTime)
+}
+
+func TestGetUsageHistory_Unauthenticated(t *testing.T) {
+	rr := httptest.NewRecorder()
+	handlers.GetUsageHistory(rr, httptest.NewRequest(http.MethodGet, "/api/usage/history", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// GetUserPathToDevice returns the ISIS path from the device a user is
+// connected to to a target device, with the measured RTT of each hop so the
+// hop contributing most to the user's latency stands out. It accepts the same
+// mode parameter as GetISISPath.
+func GetUserPathToDevice(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing user pk")
+		return
+	}
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "target parameter is required")
+		return
+	}
+
+	var devicePK string
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT COALESCE(device_pk, '')
+		FROM dz_users_current
+		WHERE pk = ?
+	`, pk).Scan(&devicePK)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeUserNotFound, "user not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("User path device query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	switch devicePK {
+	case "":
+		writeJSON(w, PathResponse{Error: "User is not connected to a device"})
+		return
+	case target:
+		writeJSON(w, PathResponse{Error: "Target is the user's own device"})
+		return
+	}
+
+	response := findISISPath(ctx, devicePK, target, r.URL.Query().Get("mode"))
+	if response.Error == "" {
+		latencyMap, err := loadLinkLatencyMap(ctx)
+		if err != nil {
+			LoggerFromContext(ctx).Error("User path latency query error", "error", err)
+			response.Error = "failed to load measured latency: " + err.Error()
+		} else {
+			addPathHopLatency(&response, latencyMap)
+		}
+	}
+
+	writeJSON(w, response)
+}
+
+// addPathHopLatency sets the measured RTT of each hop that has samples and
+// the path total.
+func addPathHopLatency(path *PathResponse, latencyMap map[string]linkLatencyData) {
+	path.MeasuredLatencyMs = 0
+	for i := 1; i < len(path.Path); i++ {
+		data, ok := latencyMap[path.Path[i-1].DevicePK+":"+path.Path[i].DevicePK]
+		if !ok {
+			continue
+		}
+		path.Path[i].EdgeMeasuredMs = data.AvgRttMs
+		path.MeasuredLatencyMs += data.AvgRttMs
+	}
+}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedUserPathData puts user-1 on nyc1 and measures the nyc1-nyc2 and
+// nyc2-chi1 links of the seedISISLine topology.
+func seedUserPathData(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_users_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, owner_pubkey, status, kind, client_ip, dz_ip, device_pk, tunnel_id)
+		VALUES
+		('user-1', now(), now(), generateUUIDv4(), 0, 1, 'user-1', '', 'activated', 'ibrl', '10.0.0.1', '10.0.0.1', 'nyc1', 501),
+		('user-2', now(), now(), generateUUIDv4(), 0, 2, 'user-2', '', 'pending', 'ibrl', '10.0.0.2', '', '', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-1', now(), now(), generateUUIDv4(), 0, 1, 'link-1', 'active', 'nyc1-nyc2', '', '', 'nyc1', 'nyc2', 'Ethernet1', 'Ethernet1', 'DZX', 0, 0, 0, 0),
+		('link-2', now(), now(), generateUUIDv4(), 0, 2, 'link-2', 'active', 'chi1-nyc2', '', '', 'chi1', 'nyc2', 'Ethernet2', 'Ethernet2', 'WAN', 0, 0, 0, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		SELECT now() - INTERVAL 1 HOUR + INTERVAL number SECOND, now(), 1, number,
+		       if(number < 10, 'nyc1', 'chi1'), 'nyc2', if(number < 10, 'link-1', 'link-2'),
+		       if(number < 10, 500, 18000), false, 0
+		FROM numbers(20)`))
+}
+
+func getUserPathToDevice(t *testing.T, pk, query string) (int, handlers.PathResponse) {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/users/"+pk+"/path-to-device"+query, nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetUserPathToDevice(rr, req)
+
+	var response handlers.PathResponse
+	if rr.Code == http.StatusOK {
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	}
+	return rr.Code, response
+}
+
+func TestGetUserPathToDevice(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISLine(t)
+	seedUserPathData(t)
+
+	code, resp := getUserPathToDevice(t, "user-1", "?target=lax1")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, resp.Error)
+
+	codes := make([]string, 0, len(resp.Path))
+	for _, hop := range resp.Path {
+		codes = append(codes, hop.DeviceCode)
+	}
+	assert.Equal(t, []string{"NYC1", "NYC2", "CHI1", "LAX1"}, codes)
+	assert.Equal(t, 3, resp.HopCount)
+	assert.Equal(t, uint32(60), resp.TotalMetric)
+
+	// The chi1-lax1 hop has no samples
+	assert.Zero(t, resp.Path[0].EdgeMeasuredMs)
+	assert.InDelta(t, 0.5, resp.Path[1].EdgeMeasuredMs, 0.001)
+	assert.InDelta(t, 18.0, resp.Path[2].EdgeMeasuredMs, 0.001)
+	assert.Zero(t, resp.Path[3].EdgeMeasuredMs)
+	assert.InDelta(t, 18.5, resp.MeasuredLatencyMs, 0.001)
+}
+
+func TestGetUserPathToDevice_Errors(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedISISLine(t)
+	seedUserPathData(t)
+
+	t.Run("missing target", func(t *testing.T) {
+		code, _ := getUserPathToDevice(t, "user-1", "")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		code, _ := getUserPathToDevice(t, "nope", "?target=lax1")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("user without device", func(t *testing.T) {
+		code, resp := getUserPathToDevice(t, "user-2", "?target=lax1")
+		require.Equal(t, http.StatusOK, code)
+		assert.NotEmpty(t, resp.Error)
+	})
+
+	t.Run("target is user's device", func(t *testing.T) {
+		code, resp := getUserPathToDevice(t, "user-1", "?target=nyc1")
+		require.Equal(t, http.StatusOK, code)
+		assert.NotEmpty(t, resp.Error)
+	})
+}
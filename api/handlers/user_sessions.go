@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// maxUserSessionsPageSize caps session timeline pages, since each session on the
+// page adds a pair of aggregates to the traffic query
+const maxUserSessionsPageSize = 100
+
+// UserSession is a period during which a user was activated on one device and tunnel
+type UserSession struct {
+	SessionStart    time.Time  `json:"sessionStart"`
+	SessionEnd      *time.Time `json:"sessionEnd"`
+	DurationSeconds float64    `json:"durationSeconds"`
+	DevicePK        string     `json:"devicePK"`
+	DeviceCode      string     `json:"deviceCode"`
+	MetroCode       string     `json:"metroCode"`
+	BytesIn         int64      `json:"bytesIn"`
+	BytesOut        int64      `json:"bytesOut"`
+
+	tunnelID int32
+}
+
+// userSnapshot is a user's state at a point in dim_dz_users_history
+type userSnapshot struct {
+	TS       time.Time
+	Status   string
+	DevicePK string
+	TunnelID int32
+	Deleted  bool
+}
+
+// buildUserSessions turns time-ordered snapshots into sessions, newest first. A
+// session starts when the user becomes activated and ends at the first snapshot
+// where it isn't, or where it moved to another device or tunnel. A session
+// still open at the last snapshot has no end.
+func buildUserSessions(snapshots []userSnapshot) []UserSession {
+	var sessions []UserSession
+	var open *UserSession
+	closeOpen := func(ts time.Time) {
+		end := ts
+		open.SessionEnd = &end
+		open.DurationSeconds = ts.Sub(open.SessionStart).Seconds()
+		sessions = append(sessions, *open)
+		open = nil
+	}
+
+	for _, s := range snapshots {
+		active := s.Status == "activated" && !s.Deleted
+		if open != nil && (!active || s.DevicePK != open.DevicePK || s.TunnelID != open.tunnelID) {
+			closeOpen(s.TS)
+		}
+		if active && open == nil {
+			open = &UserSession{SessionStart: s.TS, DevicePK: s.DevicePK, tunnelID: s.TunnelID}
+		}
+	}
+	if open != nil {
+		sessions = append(sessions, *open)
+	}
+
+	for i, j := 0, len(sessions)-1; i < j; i, j = i+1, j-1 {
+		sessions[i], sessions[j] = sessions[j], sessions[i]
+	}
+	return sessions
+}
+
+// GetUserSessionTimeline returns a user's connection history derived from status
+// transitions in dim_dz_users_history, newest first, with bytes transferred on
+// the user's tunnel during each session. Paginated with limit (max 100) and offset.
+func GetUserSessionTimeline(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pk := chi.URLParam(r, "pk")
+	if pk == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing user pk")
+		return
+	}
+
+	pagination := ParsePagination(r, 50)
+	if pagination.Limit > maxUserSessionsPageSize {
+		pagination.Limit = maxUserSessionsPageSize
+	}
+
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT
+			snapshot_ts,
+			argMax(status, ingested_at) AS status,
+			argMax(device_pk, ingested_at) AS device_pk,
+			argMax(tunnel_id, ingested_at) AS tunnel_id,
+			argMax(is_deleted, ingested_at) AS is_deleted
+		FROM dim_dz_users_history
+		WHERE pk = ?
+		GROUP BY snapshot_ts
+		ORDER BY snapshot_ts
+	`, pk)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("User session timeline history query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var snapshots []userSnapshot
+	for rows.Next() {
+		var s userSnapshot
+		var deleted uint8
+		if err := rows.Scan(&s.TS, &s.Status, &s.DevicePK, &s.TunnelID, &deleted); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(start), err)
+			LoggerFromContext(ctx).Error("User session timeline history scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		s.Deleted = deleted == 1
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		metrics.RecordClickHouseQuery(time.Since(start), err)
+		LoggerFromContext(ctx).Error("User session timeline history rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	if len(snapshots) == 0 {
+		metrics.RecordClickHouseQuery(time.Since(start), nil)
+		writeError(w, r, http.StatusNotFound, ErrCodeUserNotFound, "user not found")
+		return
+	}
+
+	sessions := buildUserSessions(snapshots)
+	total := len(sessions)
+	page := []UserSession{}
+	if pagination.Offset < total {
+		page = sessions[pagination.Offset:min(pagination.Offset+pagination.Limit, total)]
+	}
+
+	if len(page) > 0 {
+		now := time.Now().UTC()
+		if err := fillUserSessionDetails(ctx, page, now); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(start), err)
+			LoggerFromContext(ctx).Error("User session timeline traffic query error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		for i := range page {
+			if page[i].SessionEnd == nil {
+				page[i].DurationSeconds = now.Sub(page[i].SessionStart).Seconds()
+			}
+		}
+	}
+	metrics.RecordClickHouseQuery(time.Since(start), nil)
+
+	writeJSON(w, PaginatedResponse[UserSession]{
+		Items:  page,
+		Total:  total,
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+	})
+}
+
+// fillUserSessionDetails sets device/metro codes and per-session byte counts.
+// Bytes are summed from the tunnel's interface counters between the session's
+// start and end (or now), using one conditional aggregate per session. As in
+// GetUserTraffic, the user's inbound traffic is the device's outbound.
+func fillUserSessionDetails(ctx context.Context, sessions []UserSession, now time.Time) error {
+	devicePKs := make([]string, 0, len(sessions))
+	tunnelIDs := make([]int32, 0, len(sessions))
+	earliest := now
+	for _, s := range sessions {
+		devicePKs = append(devicePKs, s.DevicePK)
+		tunnelIDs = append(tunnelIDs, s.tunnelID)
+		if s.SessionStart.Before(earliest) {
+			earliest = s.SessionStart
+		}
+	}
+
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT d.pk, d.code, COALESCE(m.code, '') AS metro_code
+		FROM dz_devices_current d
+		LEFT JOIN dz_metros_current m ON d.metro_pk = m.pk
+		WHERE d.pk IN (?)
+	`, devicePKs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	type deviceInfo struct{ code, metro string }
+	devices := make(map[string]deviceInfo)
+	for rows.Next() {
+		var pk string
+		var d deviceInfo
+		if err := rows.Scan(&pk, &d.code, &d.metro); err != nil {
+			return err
+		}
+		devices[pk] = d
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, 2*len(sessions))
+	var args []any
+	for _, s := range sessions {
+		end := now
+		if s.SessionEnd != nil {
+			end = *s.SessionEnd
+		}
+		cond := "device_pk = ? AND user_tunnel_id = ? AND event_ts >= ? AND event_ts < ?"
+		columns = append(columns,
+			fmt.Sprintf("toInt64(sumIf(greatest(0, COALESCE(out_octets_delta, 0)), %s))", cond),
+			fmt.Sprintf("toInt64(sumIf(greatest(0, COALESCE(in_octets_delta, 0)), %s))", cond),
+		)
+		args = append(args,
+			s.DevicePK, s.tunnelID, s.SessionStart, end,
+			s.DevicePK, s.tunnelID, s.SessionStart, end,
+		)
+	}
+	args = append(args, devicePKs, tunnelIDs, earliest)
+
+	query := "SELECT " + strings.Join(columns, ",\n") + `
+		FROM fact_dz_device_interface_counters
+		WHERE device_pk IN (?)
+			AND user_tunnel_id IN (?)
+			AND event_ts >= ?`
+
+	bytes := make([]int64, 2*len(sessions))
+	dest := make([]any, len(bytes))
+	for i := range bytes {
+		dest[i] = &bytes[i]
+	}
+	if err := envDB(ctx).QueryRow(ctx, query, args...).Scan(dest...); err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		d := devices[sessions[i].DevicePK]
+		sessions[i].DeviceCode = d.code
+		sessions[i].MetroCode = d.metro
+		sessions[i].BytesIn = bytes[2*i]
+		sessions[i].BytesOut = bytes[2*i+1]
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUserSessions(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	sessions := buildUserSessions([]userSnapshot{
+		{TS: at(0), Status: "pending", DevicePK: "dev-1", TunnelID: 500},
+		{TS: at(1), Status: "activated", DevicePK: "dev-1", TunnelID: 500},
+		{TS: at(2), Status: "activated", DevicePK: "dev-1", TunnelID: 500},
+		{TS: at(4), Status: "suspended", DevicePK: "dev-1", TunnelID: 500},
+		{TS: at(5), Status: "activated", DevicePK: "dev-1", TunnelID: 500},
+		{TS: at(6), Status: "activated", DevicePK: "dev-2", TunnelID: 501},
+	})
+	require.Len(t, sessions, 3)
+
+	// Newest first; the session on dev-2 is still open
+	assert.Equal(t, at(6), sessions[0].SessionStart)
+	assert.Nil(t, sessions[0].SessionEnd)
+	assert.Equal(t, "dev-2", sessions[0].DevicePK)
+
+	// Moving to dev-2 closed the dev-1 session
+	assert.Equal(t, at(5), sessions[1].SessionStart)
+	require.NotNil(t, sessions[1].SessionEnd)
+	assert.Equal(t, at(6), *sessions[1].SessionEnd)
+	assert.Equal(t, 3600.0, sessions[1].DurationSeconds)
+
+	assert.Equal(t, at(1), sessions[2].SessionStart)
+	require.NotNil(t, sessions[2].SessionEnd)
+	assert.Equal(t, at(4), *sessions[2].SessionEnd)
+	assert.Equal(t, 3*3600.0, sessions[2].DurationSeconds)
+}
+
+func TestBuildUserSessions_Deleted(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sessions := buildUserSessions([]userSnapshot{
+		{TS: base, Status: "activated", DevicePK: "dev-1"},
+		{TS: base.Add(time.Hour), Status: "activated", DevicePK: "dev-1", Deleted: true},
+	})
+	require.Len(t, sessions, 1)
+	require.NotNil(t, sessions[0].SessionEnd)
+	assert.Equal(t, base.Add(time.Hour), *sessions[0].SessionEnd)
+
+	assert.Empty(t, buildUserSessions([]userSnapshot{{TS: base, Status: "pending"}}))
+}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedUserSessions inserts user-tl, activated on dev-tl tunnel 501 from 10h ago
+// to 6h ago and again since 2h ago, with counters in each session.
+func seedUserSessions(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_metros_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name, longitude, latitude)
+		VALUES ('metro-tl', now(), now(), generateUUIDv4(), 0, 1, 'metro-tl', 'AMS', 'Amsterdam', 4.9, 52.4)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES ('dev-tl', now(), now(), generateUUIDv4(), 0, 1, 'dev-tl', 'activated', 'hybrid', 'AMS-TL', '', '', 'metro-tl', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_users_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, owner_pubkey, status, kind, client_ip, dz_ip, device_pk, tunnel_id)
+		VALUES
+		('user-tl', now() - INTERVAL 11 HOUR, now(), generateUUIDv4(), 0, 1, 'user-tl', 'owner', 'pending', 'ibrl', '', '', 'dev-tl', 501),
+		('user-tl', now() - INTERVAL 10 HOUR, now(), generateUUIDv4(), 0, 2, 'user-tl', 'owner', 'activated', 'ibrl', '', '', 'dev-tl', 501),
+		('user-tl', now() - INTERVAL 6 HOUR, now(), generateUUIDv4(), 0, 3, 'user-tl', 'owner', 'suspended', 'ibrl', '', '', 'dev-tl', 501),
+		('user-tl', now() - INTERVAL 2 HOUR, now(), generateUUIDv4(), 0, 4, 'user-tl', 'owner', 'activated', 'ibrl', '', '', 'dev-tl', 501)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_interface_counters
+		(event_ts, ingested_at, device_pk, intf, user_tunnel_id, in_octets_delta, out_octets_delta, delta_duration)
+		VALUES
+		(now() - INTERVAL 8 HOUR, now(), 'dev-tl', 'Tunnel501', 501, 100, 1000, 60.0),
+		(now() - INTERVAL 7 HOUR, now(), 'dev-tl', 'Tunnel501', 501, 200, 2000, 60.0),
+		(now() - INTERVAL 4 HOUR, now(), 'dev-tl', 'Tunnel501', 501, 9999, 9999, 60.0),
+		(now() - INTERVAL 1 HOUR, now(), 'dev-tl', 'Tunnel501', 501, 50, 500, 60.0),
+		(now() - INTERVAL 1 HOUR, now(), 'dev-tl', 'Tunnel502', 502, 7777, 7777, 60.0)`))
+}
+
+func getUserSessionTimeline(pk, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/dz/users/"+pk+"/session-timeline"+query, nil)
+	req = withChiURLParams(req, map[string]string{"pk": pk})
+	rr := httptest.NewRecorder()
+	handlers.GetUserSessionTimeline(rr, req)
+	return rr
+}
+
+func TestGetUserSessionTimeline(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedUserSessions(t)
+
+	rr := getUserSessionTimeline("user-tl", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.PaginatedResponse[handlers.UserSession]
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, 2, resp.Total)
+	require.Len(t, resp.Items, 2)
+
+	current := resp.Items[0]
+	assert.Nil(t, current.SessionEnd)
+	assert.InDelta(t, 2*3600, current.DurationSeconds, 60)
+	assert.Equal(t, "dev-tl", current.DevicePK)
+	assert.Equal(t, "AMS-TL", current.DeviceCode)
+	assert.Equal(t, "AMS", current.MetroCode)
+	assert.Equal(t, int64(500), current.BytesIn)
+	assert.Equal(t, int64(50), current.BytesOut)
+
+	previous := resp.Items[1]
+	require.NotNil(t, previous.SessionEnd)
+	assert.InDelta(t, 4*3600, previous.DurationSeconds, 1)
+	assert.Equal(t, int64(3000), previous.BytesIn)
+	assert.Equal(t, int64(300), previous.BytesOut)
+}
+
+func TestGetUserSessionTimeline_Pagination(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedUserSessions(t)
+
+	rr := getUserSessionTimeline("user-tl", "?limit=1&offset=1")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.PaginatedResponse[handlers.UserSession]
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, 2, resp.Total)
+	require.Len(t, resp.Items, 1)
+	assert.NotNil(t, resp.Items[0].SessionEnd)
+
+	rr = getUserSessionTimeline("user-tl", "?offset=5")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Empty(t, resp.Items)
+}
+
+func TestGetUserSessionTimeline_NotFound(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	rr := getUserSessionTimeline("missing", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
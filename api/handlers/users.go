@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"time"
 
@@ -37,8 +36,8 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 	countQuery := `SELECT count(*) FROM dz_users_current`
 	var total uint64
 	if err := envDB(ctx).QueryRow(ctx, countQuery).Scan(&total); err != nil {
-		log.Printf("Users count error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Users count error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -88,8 +87,8 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Users query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Users query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -111,16 +110,16 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 			&u.InBps,
 			&u.OutBps,
 		); err != nil {
-			log.Printf("Users scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Users scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		users = append(users, u)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Users rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Users rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -138,7 +137,7 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -172,7 +171,7 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 
 	pk := chi.URLParam(r, "pk")
 	if pk == "" {
-		http.Error(w, "missing user pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing user pk")
 		return
 	}
 
@@ -272,14 +271,14 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("User query error: %v", err)
-		http.Error(w, "user not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("User query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeUserNotFound, "user not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(user); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -298,7 +297,7 @@ func GetUserTraffic(w http.ResponseWriter, r *http.Request) {
 
 	pk := chi.URLParam(r, "pk")
 	if pk == "" {
-		http.Error(w, "missing user pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing user pk")
 		return
 	}
 
@@ -371,8 +370,8 @@ func GetUserTraffic(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("UserTraffic query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("UserTraffic query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -381,16 +380,16 @@ func GetUserTraffic(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var p UserTrafficPoint
 		if err := rows.Scan(&p.Time, &p.TunnelID, &p.InBps, &p.OutBps, &p.InPps, &p.OutPps); err != nil {
-			log.Printf("UserTraffic scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("UserTraffic scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		points = append(points, p)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("UserTraffic rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("UserTraffic rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -400,7 +399,7 @@ func GetUserTraffic(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(points); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -420,7 +419,7 @@ func GetUserMulticastGroups(w http.ResponseWriter, r *http.Request) {
 
 	pk := chi.URLParam(r, "pk")
 	if pk == "" {
-		http.Error(w, "missing user pk", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing user pk")
 		return
 	}
 
@@ -485,8 +484,8 @@ func GetUserMulticastGroups(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("UserMulticastGroups query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("UserMulticastGroups query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -495,16 +494,16 @@ func GetUserMulticastGroups(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var g UserMulticastGroup
 		if err := rows.Scan(&g.GroupPK, &g.GroupCode, &g.MulticastIP, &g.Mode, &g.Status, &g.PublisherCount, &g.SubscriberCount); err != nil {
-			log.Printf("UserMulticastGroups scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("UserMulticastGroups scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		groups = append(groups, g)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("UserMulticastGroups rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("UserMulticastGroups rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -514,6 +513,6 @@ func GetUserMulticastGroups(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(groups); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// maxValidatorTopPeers caps how many peers are listed in the DZ performance response
+const maxValidatorTopPeers = 10
+
+// ValidatorPeerLatency compares DZ and internet RTT to one peer validator
+type ValidatorPeerLatency struct {
+	VotePubkey        string  `json:"votePubkey"`
+	MetroCode         string  `json:"metroCode"`
+	DZLatencyMs       float64 `json:"dzLatencyMs"`
+	InternetLatencyMs float64 `json:"internetLatencyMs"`
+	ImprovementPct    float64 `json:"improvementPct"` // positive when DZ is faster
+}
+
+// ValidatorDZPerformanceResponse is the response for the validator DZ performance endpoint
+type ValidatorDZPerformanceResponse struct {
+	VotePubkey           string                 `json:"votePubkey"`
+	OnDZ                 bool                   `json:"onDZ"`
+	MetroCode            string                 `json:"metroCode,omitempty"`
+	PeerCount            int                    `json:"peerCount"` // peers with both DZ and internet measurements
+	AvgDZLatencyMs       float64                `json:"avgDZLatencyMs"`
+	AvgInternetLatencyMs float64                `json:"avgInternetLatencyMs"`
+	ImprovementPct       float64                `json:"improvementPct"`
+	TopPeers             []ValidatorPeerLatency `json:"topPeers"`
+}
+
+// GetValidatorDZPerformance compares a DZ-connected validator's RTT to the
+// other DZ validators over DZ and over the public internet, using the past
+// 24 hours of measurements. Latency is measured between metros rather than
+// between validators: DZ RTT is the average over links between the two
+// metros, and internet RTT the average internet probe between them. Peers
+// without both are left out. Top peers are the ones DZ helps most.
+func GetValidatorDZPerformance(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	votePubkey := chi.URLParam(r, "vote_pubkey")
+	if votePubkey == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing vote_pubkey")
+		return
+	}
+
+	response := ValidatorDZPerformanceResponse{VotePubkey: votePubkey, TopPeers: []ValidatorPeerLatency{}}
+
+	// Users have no validator kind, so as elsewhere a validator is on DZ when
+	// its gossip IP is an activated user's DZ IP
+	var metroPK string
+	start := time.Now()
+	err := envDB(ctx).QueryRow(ctx, `
+		SELECT COALESCE(d.metro_pk, ''), COALESCE(m.code, '')
+		FROM solana_vote_accounts_current v
+		LEFT JOIN solana_gossip_nodes_current g ON v.node_pubkey = g.pubkey
+		LEFT JOIN (
+			SELECT dz_ip, device_pk FROM dz_users_current
+			WHERE status = 'activated' AND dz_ip != ''
+		) u ON g.gossip_ip = u.dz_ip
+		LEFT JOIN dz_devices_current d ON u.device_pk = d.pk
+		LEFT JOIN dz_metros_current m ON d.metro_pk = m.pk
+		WHERE v.vote_pubkey = ?
+		LIMIT 1
+	`, votePubkey).Scan(&metroPK, &response.MetroCode)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, ErrCodeValidatorNotFound, "validator not found")
+			return
+		}
+		LoggerFromContext(ctx).Error("Validator DZ performance validator query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	response.OnDZ = metroPK != ""
+	if !response.OnDZ {
+		writeJSON(w, response)
+		return
+	}
+
+	peers, err := loadValidatorPeerLatencies(ctx, votePubkey, metroPK)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Validator DZ performance peer query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	summarizeValidatorDZPerformance(&response, peers)
+
+	writeJSON(w, response)
+}
+
+// loadValidatorPeerLatencies returns every other DZ validator with DZ and
+// internet RTT between its metro and the given one
+func loadValidatorPeerLatencies(ctx context.Context, votePubkey, metroPK string) ([]ValidatorPeerLatency, error) {
+	start := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		WITH
+		peers AS (
+			SELECT
+				v.vote_pubkey AS vote_pubkey,
+				any(d.metro_pk) AS metro_pk
+			FROM solana_vote_accounts_current v
+			JOIN solana_gossip_nodes_current g ON v.node_pubkey = g.pubkey
+			JOIN dz_users_current u ON g.gossip_ip = u.dz_ip
+			JOIN dz_devices_current d ON u.device_pk = d.pk
+			WHERE u.status = 'activated'
+				AND u.dz_ip != ''
+				AND d.metro_pk != ''
+				AND v.vote_pubkey != ?
+			GROUP BY v.vote_pubkey
+		),
+		dz AS (
+			SELECT
+				least(da.metro_pk, dz.metro_pk) AS m1,
+				greatest(da.metro_pk, dz.metro_pk) AS m2,
+				avgIf(f.rtt_us, NOT f.loss) / 1000.0 AS rtt_ms
+			FROM fact_dz_device_link_latency f
+			JOIN dz_links_current l ON f.link_pk = l.pk
+			JOIN dz_devices_current da ON l.side_a_pk = da.pk
+			JOIN dz_devices_current dz ON l.side_z_pk = dz.pk
+			WHERE f.event_ts >= now() - INTERVAL 24 HOUR
+				AND (da.metro_pk = ? OR dz.metro_pk = ?)
+			GROUP BY m1, m2
+			HAVING countIf(NOT f.loss) > 0
+		),
+		inet AS (
+			SELECT
+				least(origin_metro_pk, target_metro_pk) AS m1,
+				greatest(origin_metro_pk, target_metro_pk) AS m2,
+				avg(rtt_us) / 1000.0 AS rtt_ms
+			FROM fact_dz_internet_metro_latency
+			WHERE event_ts >= now() - INTERVAL 24 HOUR
+				AND (origin_metro_pk = ? OR target_metro_pk = ?)
+			GROUP BY m1, m2
+		)
+		SELECT p.vote_pubkey, COALESCE(m.code, ''), dz.rtt_ms, inet.rtt_ms
+		FROM peers p
+		JOIN dz ON dz.m1 = least(p.metro_pk, ?) AND dz.m2 = greatest(p.metro_pk, ?)
+		JOIN inet ON inet.m1 = dz.m1 AND inet.m2 = dz.m2
+		LEFT JOIN dz_metros_current m ON p.metro_pk = m.pk
+	`, votePubkey, metroPK, metroPK, metroPK, metroPK, metroPK, metroPK)
+	metrics.RecordClickHouseQuery(time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var peers []ValidatorPeerLatency
+	for rows.Next() {
+		var p ValidatorPeerLatency
+		if err := rows.Scan(&p.VotePubkey, &p.MetroCode, &p.DZLatencyMs, &p.InternetLatencyMs); err != nil {
+			return nil, err
+		}
+		peers = append(peers, p)
+	}
+	return peers, rows.Err()
+}
+
+// summarizeValidatorDZPerformance fills in each peer's improvement, the
+// averages over all peers, and the peers DZ helps most
+func summarizeValidatorDZPerformance(response *ValidatorDZPerformanceResponse, peers []ValidatorPeerLatency) {
+	response.PeerCount = len(peers)
+	if len(peers) == 0 {
+		return
+	}
+
+	var dzSum, inetSum float64
+	for i := range peers {
+		dzSum += peers[i].DZLatencyMs
+		inetSum += peers[i].InternetLatencyMs
+		peers[i].ImprovementPct = latencyImprovementPct(peers[i].DZLatencyMs, peers[i].InternetLatencyMs)
+	}
+	response.AvgDZLatencyMs = dzSum / float64(len(peers))
+	response.AvgInternetLatencyMs = inetSum / float64(len(peers))
+	response.ImprovementPct = latencyImprovementPct(response.AvgDZLatencyMs, response.AvgInternetLatencyMs)
+
+	sort.SliceStable(peers, func(i, j int) bool {
+		if peers[i].ImprovementPct != peers[j].ImprovementPct {
+			return peers[i].ImprovementPct > peers[j].ImprovementPct
+		}
+		return peers[i].VotePubkey < peers[j].VotePubkey
+	})
+	if len(peers) > maxValidatorTopPeers {
+		peers = peers[:maxValidatorTopPeers]
+	}
+	response.TopPeers = peers
+}
+
+// latencyImprovementPct is how much lower DZ RTT is than internet RTT, as a
+// percentage of the internet RTT
+func latencyImprovementPct(dzMs, internetMs float64) float64 {
+	if internetMs <= 0 {
+		return 0
+	}
+	return (internetMs - dzMs) * 100 / internetMs
+}
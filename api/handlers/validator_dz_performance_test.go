@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedValidatorDZPerformance inserts DZ validators in AMS, FRA and NYC and one
+// validator off DZ. AMS-FRA is 5ms over DZ and 10ms over the internet;
+// NYC-AMS is 70ms over DZ and 80ms over the internet.
+func seedValidatorDZPerformance(t *testing.T) {
+	ctx := t.Context()
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_metros_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash, pk, code, name, longitude, latitude)
+		VALUES
+		('metro-ams', now(), now(), generateUUIDv4(), 0, 1, 'metro-ams', 'ams', 'Amsterdam', 4.9, 52.4),
+		('metro-fra', now(), now(), generateUUIDv4(), 0, 2, 'metro-fra', 'fra', 'Frankfurt', 8.7, 50.1),
+		('metro-nyc', now(), now(), generateUUIDv4(), 0, 3, 'metro-nyc', 'nyc', 'New York', -74.0, 40.7)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_devices_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, device_type, code, public_ip, contributor_pk, metro_pk, max_users)
+		VALUES
+		('dev-ams', now(), now(), generateUUIDv4(), 0, 1, 'dev-ams', 'activated', 'hybrid', 'ams001-dz001', '', '', 'metro-ams', 0),
+		('dev-fra', now(), now(), generateUUIDv4(), 0, 2, 'dev-fra', 'activated', 'hybrid', 'fra001-dz001', '', '', 'metro-fra', 0),
+		('dev-nyc', now(), now(), generateUUIDv4(), 0, 3, 'dev-nyc', 'activated', 'hybrid', 'nyc001-dz001', '', '', 'metro-nyc', 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_users_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, owner_pubkey, status, kind, client_ip, dz_ip, device_pk, tunnel_id)
+		VALUES
+		('user-ams', now(), now(), generateUUIDv4(), 0, 1, 'user-ams', '', 'activated', 'ibrl', '10.0.0.1', '10.0.0.1', 'dev-ams', 501),
+		('user-fra', now(), now(), generateUUIDv4(), 0, 2, 'user-fra', '', 'activated', 'ibrl', '10.0.0.2', '10.0.0.2', 'dev-fra', 502),
+		('user-nyc', now(), now(), generateUUIDv4(), 0, 3, 'user-nyc', '', 'activated', 'ibrl', '10.0.0.3', '10.0.0.3', 'dev-nyc', 503)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_solana_gossip_nodes_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pubkey, epoch, gossip_ip, gossip_port, tpuquic_ip, tpuquic_port, version)
+		VALUES
+		('node-ams', now(), now(), generateUUIDv4(), 0, 1, 'node-ams', 100, '10.0.0.1', 8001, '', 0, '2.0.0'),
+		('node-fra', now(), now(), generateUUIDv4(), 0, 2, 'node-fra', 100, '10.0.0.2', 8001, '', 0, '2.0.0'),
+		('node-nyc', now(), now(), generateUUIDv4(), 0, 3, 'node-nyc', 100, '10.0.0.3', 8001, '', 0, '2.0.0'),
+		('node-off', now(), now(), generateUUIDv4(), 0, 4, 'node-off', 100, '5.6.7.8', 8001, '', 0, '2.0.0')`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_solana_vote_accounts_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 vote_pubkey, epoch, node_pubkey, activated_stake_lamports, epoch_vote_account, commission_percentage)
+		VALUES
+		('vote-ams', now(), now(), generateUUIDv4(), 0, 1, 'vote-ams', 100, 'node-ams', 1000000000000, 'true', 5),
+		('vote-fra', now(), now(), generateUUIDv4(), 0, 2, 'vote-fra', 100, 'node-fra', 1000000000000, 'true', 5),
+		('vote-nyc', now(), now(), generateUUIDv4(), 0, 3, 'vote-nyc', 100, 'node-nyc', 1000000000000, 'true', 5),
+		('vote-off', now(), now(), generateUUIDv4(), 0, 4, 'vote-off', 100, 'node-off', 1000000000000, 'true', 5)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO dim_dz_links_history
+		(entity_id, snapshot_ts, ingested_at, op_id, is_deleted, attrs_hash,
+		 pk, status, code, tunnel_net, contributor_pk, side_a_pk, side_z_pk,
+		 side_a_iface_name, side_z_iface_name, link_type, committed_rtt_ns,
+		 committed_jitter_ns, bandwidth_bps, isis_delay_override_ns)
+		VALUES
+		('link-ams-fra', now(), now(), generateUUIDv4(), 0, 1, 'link-ams-fra', 'activated', 'AMS-FRA', '', '', 'dev-ams', 'dev-fra', '', '', 'WAN', 0, 0, 10000000000, 0),
+		('link-nyc-ams', now(), now(), generateUUIDv4(), 0, 2, 'link-nyc-ams', 'activated', 'NYC-AMS', '', '', 'dev-nyc', 'dev-ams', '', '', 'WAN', 0, 0, 10000000000, 0)`))
+
+	// A lost sample with no RTT must not pull the DZ average down
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_device_link_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_device_pk, target_device_pk, link_pk, rtt_us, loss, ipdv_us)
+		VALUES
+		(now() - INTERVAL 1 HOUR, now(), 1, 0, 'dev-ams', 'dev-fra', 'link-ams-fra', 5000, false, 0),
+		(now() - INTERVAL 1 HOUR, now(), 1, 1, 'dev-ams', 'dev-fra', 'link-ams-fra', 0, true, 0),
+		(now() - INTERVAL 1 HOUR, now(), 1, 0, 'dev-nyc', 'dev-ams', 'link-nyc-ams', 70000, false, 0)`))
+
+	require.NoError(t, config.DB.Exec(ctx, `INSERT INTO fact_dz_internet_metro_latency
+		(event_ts, ingested_at, epoch, sample_index, origin_metro_pk, target_metro_pk, data_provider, rtt_us, ipdv_us)
+		VALUES
+		(now() - INTERVAL 1 HOUR, now(), 1, 0, 'metro-ams', 'metro-fra', 'ripeatlas', 10000, 0),
+		(now() - INTERVAL 1 HOUR, now(), 1, 0, 'metro-nyc', 'metro-ams', 'ripeatlas', 80000, 0)`))
+}
+
+func getValidatorDZPerformance(votePubkey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/solana/validators/"+votePubkey+"/dz-performance", nil)
+	req = withChiURLParams(req, map[string]string{"vote_pubkey": votePubkey})
+	rr := httptest.NewRecorder()
+	handlers.GetValidatorDZPerformance(rr, req)
+	return rr
+}
+
+func TestGetValidatorDZPerformance(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedValidatorDZPerformance(t)
+
+	rr := getValidatorDZPerformance("vote-ams")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.ValidatorDZPerformanceResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.True(t, resp.OnDZ)
+	assert.Equal(t, "ams", resp.MetroCode)
+	assert.Equal(t, 2, resp.PeerCount)
+	assert.InDelta(t, 37.5, resp.AvgDZLatencyMs, 0.001)
+	assert.InDelta(t, 45.0, resp.AvgInternetLatencyMs, 0.001)
+	assert.InDelta(t, 16.667, resp.ImprovementPct, 0.001)
+
+	require.Len(t, resp.TopPeers, 2)
+	assert.Equal(t, "vote-fra", resp.TopPeers[0].VotePubkey)
+	assert.InDelta(t, 5.0, resp.TopPeers[0].DZLatencyMs, 0.001)
+	assert.InDelta(t, 10.0, resp.TopPeers[0].InternetLatencyMs, 0.001)
+	assert.InDelta(t, 50.0, resp.TopPeers[0].ImprovementPct, 0.001)
+	assert.Equal(t, "vote-nyc", resp.TopPeers[1].VotePubkey)
+	assert.InDelta(t, 12.5, resp.TopPeers[1].ImprovementPct, 0.001)
+}
+
+func TestGetValidatorDZPerformance_NotOnDZ(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedValidatorDZPerformance(t)
+
+	rr := getValidatorDZPerformance("vote-off")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.ValidatorDZPerformanceResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.False(t, resp.OnDZ)
+	assert.Equal(t, 0, resp.PeerCount)
+	assert.Empty(t, resp.TopPeers)
+}
+
+func TestGetValidatorDZPerformance_NotFound(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	rr := getValidatorDZPerformance("nonexistent")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
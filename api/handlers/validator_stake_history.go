@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/malbeclabs/lake/api/metrics"
+)
+
+// stakeSparklinePoints is the number of points the stake sparkline is downsampled to
+const stakeSparklinePoints = 30
+
+// ValidatorStakeEpoch is a validator's stake during one epoch
+type ValidatorStakeEpoch struct {
+	Epoch             int64     `json:"epoch"`
+	EpochTs           time.Time `json:"epochTs"` // estimated epoch start
+	StakeLamports     int64     `json:"stakeLamports"`
+	StakeSol          float64   `json:"stakeSol"`
+	StakeSharePct     float64   `json:"stakeSharePct"`
+	Rank              uint32    `json:"rank"`
+	ActivatedStake    int64     `json:"activatedStake"`    // lamports
+	DeactivatingStake *int64    `json:"deactivatingStake"` // lamports, null when not recorded
+}
+
+// ValidatorStakeHistoryResponse is a validator's per-epoch stake, oldest first
+type ValidatorStakeHistoryResponse struct {
+	VotePubkey string                `json:"votePubkey"`
+	History    []ValidatorStakeEpoch `json:"history"`
+	Sparkline  [][2]float64          `json:"sparkline"` // [unix millis, stake SOL]
+}
+
+// stakeSparkline downsamples history to at most maxPoints evenly spaced
+// [unix millis, stake SOL] points, always keeping the first and last epoch.
+func stakeSparkline(history []ValidatorStakeEpoch, maxPoints int) [][2]float64 {
+	n := len(history)
+	points := min(n, maxPoints)
+	sparkline := make([][2]float64, 0, points)
+	for i := range points {
+		idx := 0
+		if points > 1 {
+			idx = int(math.Round(float64(i) * float64(n-1) / float64(points-1)))
+		}
+		h := history[idx]
+		sparkline = append(sparkline, [2]float64{float64(h.EpochTs.UnixMilli()), h.StakeSol})
+	}
+	return sparkline
+}
+
+// GetValidatorStakeHistory returns a validator's stake per epoch from
+// fact_sol_validator_stake_history, optionally limited to epochs starting in
+// [start, end) (RFC3339), with a sparkline of stake over the range.
+func GetValidatorStakeHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	votePubkey := chi.URLParam(r, "vote_pubkey")
+	if votePubkey == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing vote_pubkey")
+		return
+	}
+
+	conditions := []string{"vote_pubkey = ?"}
+	args := []any{votePubkey}
+	if s := r.URL.Query().Get("start"); s != "" {
+		start, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "start must be an RFC3339 timestamp")
+			return
+		}
+		conditions = append(conditions, "epoch_ts >= ?")
+		args = append(args, start)
+	}
+	if s := r.URL.Query().Get("end"); s != "" {
+		end, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "end must be an RFC3339 timestamp")
+			return
+		}
+		conditions = append(conditions, "epoch_ts < ?")
+		args = append(args, end)
+	}
+
+	queryStart := time.Now()
+	rows, err := envDB(ctx).Query(ctx, `
+		SELECT
+			epoch,
+			epoch_ts,
+			activated_stake_lamports,
+			deactivating_stake_lamports,
+			stake_share_pct,
+			stake_rank
+		FROM fact_sol_validator_stake_history FINAL
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY epoch
+	`, args...)
+	if err != nil {
+		metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+		LoggerFromContext(ctx).Error("Validator stake history query error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	response := ValidatorStakeHistoryResponse{VotePubkey: votePubkey, History: []ValidatorStakeEpoch{}}
+	for rows.Next() {
+		var e ValidatorStakeEpoch
+		if err := rows.Scan(&e.Epoch, &e.EpochTs, &e.ActivatedStake, &e.DeactivatingStake, &e.StakeSharePct, &e.Rank); err != nil {
+			metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+			LoggerFromContext(ctx).Error("Validator stake history scan error", "error", err)
+			writeDBError(w, r, err)
+			return
+		}
+		e.EpochTs = e.EpochTs.UTC()
+		e.StakeLamports = e.ActivatedStake
+		e.StakeSol = float64(e.ActivatedStake) / 1e9
+		response.History = append(response.History, e)
+	}
+	err = rows.Err()
+	metrics.RecordClickHouseQuery(time.Since(queryStart), err)
+	if err != nil {
+		LoggerFromContext(ctx).Error("Validator stake history rows error", "error", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	response.Sparkline = stakeSparkline(response.History, stakeSparklinePoints)
+	writeJSON(w, response)
+}
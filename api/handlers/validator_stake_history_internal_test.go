@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStakeSparkline(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]ValidatorStakeEpoch, 100)
+	for i := range history {
+		history[i] = ValidatorStakeEpoch{
+			Epoch:    int64(700 + i),
+			EpochTs:  t0.Add(time.Duration(i) * 48 * time.Hour),
+			StakeSol: float64(i),
+		}
+	}
+
+	sparkline := stakeSparkline(history, 30)
+	require.Len(t, sparkline, 30)
+	assert.Equal(t, [2]float64{float64(t0.UnixMilli()), 0}, sparkline[0])
+	assert.Equal(t, float64(99), sparkline[29][1])
+	for i := 1; i < len(sparkline); i++ {
+		assert.Greater(t, sparkline[i][0], sparkline[i-1][0])
+	}
+
+	// Short histories are returned whole
+	assert.Len(t, stakeSparkline(history[:5], 30), 5)
+	assert.Equal(t, [][2]float64{{float64(t0.UnixMilli()), 0}}, stakeSparkline(history[:1], 30))
+	assert.Empty(t, stakeSparkline(nil, 30))
+}
@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/config"
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedValidatorStakeHistory inserts three epochs for vote1 (with epoch 702
+// written twice, the later row winning) and one epoch for vote2.
+func seedValidatorStakeHistory(t *testing.T) {
+	require.NoError(t, config.DB.Exec(t.Context(), `INSERT INTO fact_sol_validator_stake_history
+		(epoch, epoch_ts, ingested_at, vote_pubkey, node_pubkey, activated_stake_lamports,
+		 deactivating_stake_lamports, stake_share_pct, stake_rank)
+		VALUES
+		(700, '2025-02-01 00:00:00', '2025-02-01 01:00:00', 'vote1', 'node1', 1000000000000, NULL, 1.5, 12),
+		(701, '2025-02-03 00:00:00', '2025-02-03 01:00:00', 'vote1', 'node1', 2000000000000, 5000000000, 2.5, 8),
+		(702, '2025-02-05 00:00:00', '2025-02-05 01:00:00', 'vote1', 'node1', 2500000000000, NULL, 2.9, 7),
+		(702, '2025-02-05 00:00:00', '2025-02-05 02:00:00', 'vote1', 'node1', 3000000000000, NULL, 3.1, 6),
+		(702, '2025-02-05 00:00:00', '2025-02-05 01:00:00', 'vote2', 'node2', 4000000000000, NULL, 4.2, 3)`))
+}
+
+func getValidatorStakeHistory(votePubkey string, query url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/solana/validators/"+votePubkey+"/stake-history?"+query.Encode(), nil)
+	req = withChiURLParams(req, map[string]string{"vote_pubkey": votePubkey})
+	rr := httptest.NewRecorder()
+	handlers.GetValidatorStakeHistory(rr, req)
+	return rr
+}
+
+func TestGetValidatorStakeHistory(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+	seedValidatorStakeHistory(t)
+
+	rr := getValidatorStakeHistory("vote1", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp handlers.ValidatorStakeHistoryResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "vote1", resp.VotePubkey)
+	require.Len(t, resp.History, 3)
+	assert.Equal(t, int64(700), resp.History[0].Epoch)
+	assert.Nil(t, resp.History[0].DeactivatingStake)
+	require.NotNil(t, resp.History[1].DeactivatingStake)
+	assert.Equal(t, int64(5000000000), *resp.History[1].DeactivatingStake)
+	assert.Equal(t, int64(702), resp.History[2].Epoch)
+	assert.Equal(t, int64(3000000000000), resp.History[2].StakeLamports)
+	assert.InDelta(t, 3000.0, resp.History[2].StakeSol, 0.001)
+	assert.Equal(t, uint32(6), resp.History[2].Rank)
+	require.Len(t, resp.Sparkline, 3)
+	assert.InDelta(t, 1000.0, resp.Sparkline[0][1], 0.001)
+
+	// start and end bound the epoch start time
+	rr = getValidatorStakeHistory("vote1", url.Values{
+		"start": {"2025-02-02T00:00:00Z"},
+		"end":   {"2025-02-05T00:00:00Z"},
+	})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	resp = handlers.ValidatorStakeHistoryResponse{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.History, 1)
+	assert.Equal(t, int64(701), resp.History[0].Epoch)
+}
+
+func TestGetValidatorStakeHistory_Empty(t *testing.T) {
+	apitesting.SetupTestClickHouseWithMigrations(t, testChDB)
+
+	rr := getValidatorStakeHistory("unknown", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"votePubkey":"unknown","history":[],"sparkline":[]}`, rr.Body.String())
+}
+
+func TestGetValidatorStakeHistory_InvalidParams(t *testing.T) {
+	for name, query := range map[string]url.Values{
+		"invalid start": {"start": {"last week"}},
+		"invalid end":   {"end": {"now"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rr := getValidatorStakeHistory("vote1", query)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"time"
 
@@ -190,8 +189,8 @@ func GetValidators(w http.ResponseWriter, r *http.Request) {
 	countQuery := baseQuery + `SELECT count(*) FROM validators_data WHERE 1=1` + whereFilter
 	var total uint64
 	if err := envDB(ctx).QueryRow(ctx, countQuery, filterArgs...).Scan(&total); err != nil {
-		log.Printf("Validators count error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Validators count error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -199,7 +198,7 @@ func GetValidators(w http.ResponseWriter, r *http.Request) {
 	onDZCountQuery := baseQuery + `SELECT count(*) FROM validators_data WHERE on_dz = true` + whereFilter
 	var onDZCount uint64
 	if err := envDB(ctx).QueryRow(ctx, onDZCountQuery, filterArgs...).Scan(&onDZCount); err != nil {
-		log.Printf("Validators on_dz count error: %v", err)
+		LoggerFromContext(ctx).Error("Validators on_dz count error", "error", err)
 		onDZCount = 0
 	}
 
@@ -237,8 +236,8 @@ func GetValidators(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Validators query error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Validators query error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	defer rows.Close()
@@ -262,16 +261,16 @@ func GetValidators(w http.ResponseWriter, r *http.Request) {
 			&v.SkipRate,
 			&v.Version,
 		); err != nil {
-			log.Printf("Validators scan error: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			LoggerFromContext(ctx).Error("Validators scan error", "error", err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 			return
 		}
 		validators = append(validators, v)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Validators rows error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LoggerFromContext(ctx).Error("Validators rows error", "error", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -290,7 +289,7 @@ func GetValidators(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
 
@@ -321,7 +320,7 @@ func GetValidator(w http.ResponseWriter, r *http.Request) {
 
 	votePubkey := chi.URLParam(r, "vote_pubkey")
 	if votePubkey == "" {
-		http.Error(w, "missing vote_pubkey", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "missing vote_pubkey")
 		return
 	}
 
@@ -434,13 +433,13 @@ func GetValidator(w http.ResponseWriter, r *http.Request) {
 	metrics.RecordClickHouseQuery(duration, err)
 
 	if err != nil {
-		log.Printf("Validator query error: %v", err)
-		http.Error(w, "validator not found", http.StatusNotFound)
+		LoggerFromContext(ctx).Error("Validator query error", "error", err)
+		writeError(w, r, http.StatusNotFound, ErrCodeValidatorNotFound, "validator not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(validator); err != nil {
-		log.Printf("JSON encoding error: %v", err)
+		LoggerFromContext(ctx).Error("JSON encoding error", "error", err)
 	}
 }
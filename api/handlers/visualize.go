@@ -34,7 +34,7 @@ type VisualizeResponse struct {
 func RecommendVisualization(w http.ResponseWriter, r *http.Request) {
 	var req VisualizeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid request body")
 		return
 	}
 
@@ -3,9 +3,9 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/malbeclabs/lake/api/config"
@@ -76,7 +76,8 @@ func GetSimulateLinkRemoval(w http.ResponseWriter, r *http.Request) {
 		"target_pk": targetPK,
 	})
 	if err != nil {
-		log.Printf("Simulate link removal codes query error: %v", err)
+		LoggerFromContext(ctx).Error("Simulate link removal codes query error", "error", err)
+		metrics.RecordNeo4jQuery("simulate_link_removal", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -123,15 +124,17 @@ func GetSimulateLinkRemoval(w http.ResponseWriter, r *http.Request) {
 		"target_pk": targetPK,
 	})
 	if err != nil {
-		log.Printf("Simulate link removal disconnect query error: %v", err)
+		LoggerFromContext(ctx).Error("Simulate link removal disconnect query error", "error", err)
+		metrics.RecordNeo4jQuery("simulate_link_removal", time.Since(start), err)
 		response.Error = "failed to query disconnect impact"
 	} else {
 		disconnectRecords, err := disconnectResult.Collect(ctx)
 		if err != nil {
-			log.Printf("Simulate link removal disconnect collect error: %v", err)
+			LoggerFromContext(ctx).Error("Simulate link removal disconnect collect error", "error", err)
+			metrics.RecordNeo4jQuery("simulate_link_removal", time.Since(start), err)
 			response.Error = "failed to query disconnect impact"
 		} else {
-			log.Printf("Simulate link removal disconnect query returned %d records", len(disconnectRecords))
+			LoggerFromContext(ctx).Debug("Simulate link removal disconnect query completed", "records", len(disconnectRecords))
 			for _, record := range disconnectRecords {
 				pk, _ := record.Get("pk")
 				code, _ := record.Get("code")
@@ -211,12 +214,14 @@ func GetSimulateLinkRemoval(w http.ResponseWriter, r *http.Request) {
 		"target_pk": targetPK,
 	})
 	if err != nil {
-		log.Printf("Simulate link removal affected paths query error: %v", err)
+		LoggerFromContext(ctx).Error("Simulate link removal affected paths query error", "error", err)
+		metrics.RecordNeo4jQuery("simulate_link_removal", time.Since(start), err)
 		response.Error = "failed to query affected paths"
 	} else {
 		affectedRecords, err := affectedResult.Collect(ctx)
 		if err != nil {
-			log.Printf("Simulate link removal affected paths collect error: %v", err)
+			LoggerFromContext(ctx).Error("Simulate link removal affected paths collect error", "error", err)
+			metrics.RecordNeo4jQuery("simulate_link_removal", time.Since(start), err)
 			response.Error = "failed to query affected paths"
 		} else {
 			for _, record := range affectedRecords {
@@ -248,10 +253,11 @@ func GetSimulateLinkRemoval(w http.ResponseWriter, r *http.Request) {
 	response.AffectedPathCount = len(response.AffectedPaths)
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("simulate_link_removal", duration, nil)
 
-	log.Printf("Simulate link removal: %s -> %s, disconnected=%d, affectedPaths=%d, partition=%v in %v",
-		response.SourceCode, response.TargetCode, response.DisconnectedCount, response.AffectedPathCount, response.CausesPartition, duration)
+	LoggerFromContext(ctx).Info("Simulate link removal completed",
+		"source", response.SourceCode, "target", response.TargetCode, "disconnected", response.DisconnectedCount,
+		"affected_paths", response.AffectedPathCount, "partition", response.CausesPartition, "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
@@ -267,9 +273,24 @@ type SimulateLinkAdditionResponse struct {
 	ImprovedPathCount int              `json:"improvedPathCount"`
 	RedundancyGains   []RedundancyGain `json:"redundancyGains"`
 	RedundancyCount   int              `json:"redundancyCount"`
+	NewShortestPath   *SimulatedPath   `json:"newShortestPath,omitempty"`
+	ECMPPaths         []ImprovedPath   `json:"ecmpPaths"` // paths that would gain an equal-cost alternative
+	CreatesECMP       bool             `json:"createsEcmp"`
 	Error             string           `json:"error,omitempty"`
 }
 
+// SimulatedPath is the lowest-metric path between the endpoints of a
+// simulated link once the link is added
+type SimulatedPath struct {
+	Path         []string `json:"path"` // device codes, source to target
+	Hops         int      `json:"hops"`
+	Metric       uint32   `json:"metric"`
+	UsesNewLink  bool     `json:"usesNewLink"`
+	BeforeHops   int      `json:"beforeHops"`   // 0 if the endpoints weren't connected
+	BeforeMetric uint32   `json:"beforeMetric"` // 0 if the endpoints weren't connected
+	ECMP         bool     `json:"ecmp"`         // the new link ties the existing path
+}
+
 // ImprovedPath represents a path that would be improved by adding a link
 type ImprovedPath struct {
 	FromPK          string `json:"fromPK"`
@@ -293,13 +314,21 @@ type RedundancyGain struct {
 	WasLeaf    bool   `json:"wasLeaf"` // Was a single point of failure
 }
 
-// GetSimulateLinkAddition simulates adding a link and shows the benefits
+// GetSimulateLinkAddition simulates adding a link and shows the benefits.
+// The link's endpoints are given as sourcePK/targetPK (or sideA/sideZ). Nothing
+// is written to Neo4j; the link only exists as a virtual edge in the queries.
 func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	sourcePK := r.URL.Query().Get("sourcePK")
+	if sourcePK == "" {
+		sourcePK = r.URL.Query().Get("sideA")
+	}
 	targetPK := r.URL.Query().Get("targetPK")
+	if targetPK == "" {
+		targetPK = r.URL.Query().Get("sideZ")
+	}
 	metricStr := r.URL.Query().Get("metric")
 
 	if sourcePK == "" || targetPK == "" {
@@ -330,6 +359,7 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 		Metric:          metric,
 		ImprovedPaths:   []ImprovedPath{},
 		RedundancyGains: []RedundancyGain{},
+		ECMPPaths:       []ImprovedPath{},
 	}
 
 	// Get device codes and current degrees
@@ -349,7 +379,8 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 		"target_pk": targetPK,
 	})
 	if err != nil {
-		log.Printf("Simulate link addition codes query error: %v", err)
+		LoggerFromContext(ctx).Error("Simulate link addition codes query error", "error", err)
+		metrics.RecordNeo4jQuery("simulate_link_addition", time.Since(start), err)
 		response.Error = err.Error()
 		writeJSON(w, response)
 		return
@@ -409,6 +440,39 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 	}
 	response.RedundancyCount = len(response.RedundancyGains)
 
+	// Compare the virtual link against the current lowest-metric path between
+	// its endpoints. The aggregate in the subquery keeps the row when the
+	// endpoints aren't connected today.
+	endpointCypher := `
+		UNWIND [{from: $source_pk, to: $target_pk, metric: $metric}] AS virtualEdge
+		MATCH (a:Device {pk: virtualEdge.from}), (b:Device {pk: virtualEdge.to})
+		CALL {
+			WITH a, b
+			CALL apoc.algo.dijkstra(a, b, 'ISIS_ADJACENT>', 'metric') YIELD path, weight
+			RETURN collect({codes: [n IN nodes(path) | n.code], hops: length(path), metric: weight}) AS current
+		}
+		RETURN a.code AS source_code, b.code AS target_code, virtualEdge.metric AS new_metric,
+		       CASE WHEN size(current) > 0 THEN current[0] ELSE null END AS current
+	`
+	endpointResult, err := session.Run(ctx, endpointCypher, map[string]any{
+		"source_pk": sourcePK,
+		"target_pk": targetPK,
+		"metric":    int64(metric),
+	})
+	if err != nil {
+		LoggerFromContext(ctx).Error("Simulate link addition endpoint path query error", "error", err)
+		metrics.RecordNeo4jQuery("simulate_link_addition", time.Since(start), err)
+		response.Error = "failed to query endpoint path: " + err.Error()
+	} else if endpointRecord, err := endpointResult.Single(ctx); err == nil {
+		sourceCode, _ := endpointRecord.Get("source_code")
+		targetCode, _ := endpointRecord.Get("target_code")
+		current, _ := endpointRecord.Get("current")
+		response.NewShortestPath = simulatedEndpointPath(asString(sourceCode), asString(targetCode), metric, current)
+		if response.NewShortestPath.ECMP {
+			response.CreatesECMP = true
+		}
+	}
+
 	// Find paths that would be improved by the new link
 	// We use a simpler approach: check current path between source and target,
 	// and also check paths from their immediate neighbors
@@ -472,8 +536,9 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 		                    reduce(t = 0, r IN relationships(p2) | t + coalesce(r.metric, 0))
 		          ELSE 999999 END AS viaNewLinkMetric
 
-		// Only return if the new link provides improvement
-		WHERE viaNewLinkHops < currentHops
+		// Only return if the new link provides improvement, or an equal-cost
+		// alternative that would form an ECMP group with the current path
+		WHERE viaNewLinkHops < currentHops OR viaNewLinkMetric = currentMetric
 		RETURN from.pk AS from_pk,
 		       from.code AS from_code,
 		       to.pk AS to_pk,
@@ -481,7 +546,8 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 		       currentHops AS before_hops,
 		       currentMetric AS before_metric,
 		       viaNewLinkHops AS after_hops,
-		       viaNewLinkMetric AS after_metric
+		       viaNewLinkMetric AS after_metric,
+		       viaNewLinkMetric = currentMetric AS ecmp
 		ORDER BY (currentHops - viaNewLinkHops) DESC
 		LIMIT 15
 	`
@@ -492,12 +558,14 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 		"metric":    int64(metric),
 	})
 	if err != nil {
-		log.Printf("Simulate link addition improved paths query error: %v", err)
+		LoggerFromContext(ctx).Error("Simulate link addition improved paths query error", "error", err)
+		metrics.RecordNeo4jQuery("simulate_link_addition", time.Since(start), err)
 		response.Error = "failed to query improved paths: " + err.Error()
 	} else {
 		improvedRecords, err := improvedResult.Collect(ctx)
 		if err != nil {
-			log.Printf("Simulate link addition improved paths collect error: %v", err)
+			LoggerFromContext(ctx).Error("Simulate link addition improved paths collect error", "error", err)
+			metrics.RecordNeo4jQuery("simulate_link_addition", time.Since(start), err)
 			response.Error = "failed to query improved paths: " + err.Error()
 		} else {
 			for _, record := range improvedRecords {
@@ -509,12 +577,28 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 				beforeMetric, _ := record.Get("before_metric")
 				afterHops, _ := record.Get("after_hops")
 				afterMetric, _ := record.Get("after_metric")
+				ecmp, _ := record.Get("ecmp")
 
 				bHops := int(asInt64(beforeHops))
 				aHops := int(asInt64(afterHops))
 				bMetric := uint32(asInt64(beforeMetric))
 				aMetric := uint32(asInt64(afterMetric))
 
+				if asBool(ecmp) {
+					response.ECMPPaths = append(response.ECMPPaths, ImprovedPath{
+						FromPK:       asString(fromPK),
+						FromCode:     asString(fromCode),
+						ToPK:         asString(toPK),
+						ToCode:       asString(toCode),
+						BeforeHops:   bHops,
+						BeforeMetric: bMetric,
+						AfterHops:    aHops,
+						AfterMetric:  aMetric,
+						HopReduction: bHops - aHops,
+					})
+					continue
+				}
+
 				response.ImprovedPaths = append(response.ImprovedPaths, ImprovedPath{
 					FromPK:          asString(fromPK),
 					FromCode:        asString(fromCode),
@@ -531,16 +615,62 @@ func GetSimulateLinkAddition(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	response.ImprovedPathCount = len(response.ImprovedPaths)
+	if len(response.ECMPPaths) > 0 {
+		response.CreatesECMP = true
+	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("simulate_link_addition", duration, nil)
 
-	log.Printf("Simulate link addition: %s -> %s (metric=%d), improvedPaths=%d, redundancyGains=%d in %v",
-		response.SourceCode, response.TargetCode, metric, response.ImprovedPathCount, response.RedundancyCount, duration)
+	LoggerFromContext(ctx).Info("Simulate link addition completed",
+		"source", response.SourceCode, "target", response.TargetCode, "metric", metric,
+		"improved_paths", response.ImprovedPathCount, "redundancy_gains", response.RedundancyCount,
+		"creates_ecmp", response.CreatesECMP, "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
 
+// simulatedEndpointPath picks the lowest-metric path between the endpoints of
+// a simulated link of the given metric. current is the {codes, hops, metric}
+// map of today's lowest-metric path, or nil if the endpoints aren't connected.
+// IS-IS installs both paths when the metrics tie.
+func simulatedEndpointPath(sourceCode, targetCode string, metric uint32, current any) *SimulatedPath {
+	direct := &SimulatedPath{
+		Path:        []string{sourceCode, targetCode},
+		Hops:        1,
+		Metric:      metric,
+		UsesNewLink: true,
+	}
+	m, ok := current.(map[string]any)
+	if !ok {
+		return direct
+	}
+
+	var codes []string
+	if list, ok := m["codes"].([]any); ok {
+		for _, c := range list {
+			codes = append(codes, asString(c))
+		}
+	}
+	beforeHops := int(asInt64(m["hops"]))
+	beforeMetric := uint32(asFloat64(m["metric"]))
+
+	if metric < beforeMetric {
+		direct.BeforeHops = beforeHops
+		direct.BeforeMetric = beforeMetric
+		return direct
+	}
+	return &SimulatedPath{
+		Path:         codes,
+		Hops:         beforeHops,
+		Metric:       beforeMetric,
+		UsesNewLink:  metric == beforeMetric,
+		BeforeHops:   beforeHops,
+		BeforeMetric: beforeMetric,
+		ECMP:         metric == beforeMetric,
+	}
+}
+
 // WhatIfRemovalRequest is the request body for unified what-if removal analysis
 type WhatIfRemovalRequest struct {
 	Devices []string `json:"devices"` // Device PKs
@@ -554,7 +684,25 @@ type WhatIfRemovalResponse struct {
 	TotalDisconnected  int                  `json:"totalDisconnected"`
 	AffectedPaths      []WhatIfAffectedPath `json:"affectedPaths,omitempty"`
 	DisconnectedList   []string             `json:"disconnectedList,omitempty"`
-	Error              string               `json:"error,omitempty"`
+
+	// Combined reachability across all removed devices and links
+	DisconnectedPairs  []WhatIfDevicePair   `json:"disconnectedPairs"`
+	ReroutedPaths      []WhatIfAffectedPath `json:"reroutedPaths"`
+	ConnectivityBefore float64              `json:"connectivityBefore"` // Fraction of device pairs connected before removal
+	ConnectivityAfter  float64              `json:"connectivityAfter"`  // Fraction of remaining device pairs connected after removal
+	ConnectivityDelta  float64              `json:"connectivityDelta"`  // ConnectivityAfter - ConnectivityBefore
+
+	Error string `json:"error,omitempty"`
+}
+
+// WhatIfDevicePair is a pair of devices that lose connectivity after removal
+type WhatIfDevicePair struct {
+	SourcePK    string `json:"sourcePK"`
+	Source      string `json:"source"`
+	SourceMetro string `json:"sourceMetro,omitempty"`
+	TargetPK    string `json:"targetPK"`
+	Target      string `json:"target"`
+	TargetMetro string `json:"targetMetro,omitempty"`
 }
 
 // WhatIfRemovalItem represents impact of a single device or link removal
@@ -605,10 +753,28 @@ func PostWhatIfRemoval(w http.ResponseWriter, r *http.Request) {
 	defer session.Close(ctx)
 
 	response := WhatIfRemovalResponse{
-		Items:            []WhatIfRemovalItem{},
-		AffectedPaths:    []WhatIfAffectedPath{},
-		DisconnectedList: []string{},
+		Items:             []WhatIfRemovalItem{},
+		AffectedPaths:     []WhatIfAffectedPath{},
+		DisconnectedList:  []string{},
+		DisconnectedPairs: []WhatIfDevicePair{},
+		ReroutedPaths:     []WhatIfAffectedPath{},
+	}
+
+	// Full reachability check with everything removed at once
+	reachCtx, reachCancel := context.WithTimeout(ctx, whatIfReachabilityTimeout)
+	reach, err := analyzeCombinedRemoval(reachCtx, session, req.Devices, req.Links)
+	reachCancel()
+	if err != nil {
+		LoggerFromContext(ctx).Error("What-if reachability check error", "error", err)
+		metrics.RecordNeo4jQuery("whatif_removal", time.Since(start), err)
+		writeJSON(w, WhatIfRemovalResponse{Error: "Failed to compute reachability: " + err.Error()})
+		return
 	}
+	response.DisconnectedPairs = reach.disconnectedPairs
+	response.ReroutedPaths = reach.reroutedPaths
+	response.ConnectivityBefore = reach.connectivityBefore
+	response.ConnectivityAfter = reach.connectivityAfter
+	response.ConnectivityDelta = reach.connectivityAfter - reach.connectivityBefore
 
 	// Analyze each device
 	for _, devicePK := range req.Devices {
@@ -636,14 +802,227 @@ func PostWhatIfRemoval(w http.ResponseWriter, r *http.Request) {
 	}
 
 	duration := time.Since(start)
-	metrics.RecordClickHouseQuery(duration, nil)
+	metrics.RecordNeo4jQuery("whatif_removal", duration, nil)
 
-	log.Printf("What-if removal: %d devices, %d links, totalPaths=%d, totalDisconnected=%d in %v",
-		len(req.Devices), len(req.Links), response.TotalAffectedPaths, response.TotalDisconnected, duration)
+	LoggerFromContext(ctx).Info("What-if removal completed",
+		"devices", len(req.Devices), "links", len(req.Links), "total_paths", response.TotalAffectedPaths, "total_disconnected", response.TotalDisconnected,
+		"disconnected_pairs", len(response.DisconnectedPairs), "rerouted", len(response.ReroutedPaths), "duration_ms", duration.Milliseconds())
 
 	writeJSON(w, response)
 }
 
+// whatIfReachabilityTimeout bounds the all-pairs reachability check.
+const whatIfReachabilityTimeout = 15 * time.Second
+
+// combinedRemovalResult holds the all-pairs reachability outcome of a what-if removal.
+type combinedRemovalResult struct {
+	disconnectedPairs  []WhatIfDevicePair
+	reroutedPaths      []WhatIfAffectedPath
+	connectivityBefore float64
+	connectivityAfter  float64
+}
+
+// whatIfPair is a device pair whose current shortest path crosses a removed element.
+type whatIfPair struct {
+	sourcePK, sourceCode, sourceMetro string
+	targetPK, targetCode, targetMetro string
+	hopsBefore, metricBefore          int
+}
+
+// analyzeCombinedRemoval removes all given devices and links together and
+// checks reachability between every remaining pair of ISIS devices.
+//
+// Pairs whose current shortest path avoids every removed element keep that
+// path, so only pairs whose path crosses a removed element are re-checked
+// with an exclusion-constrained shortestPath.
+func analyzeCombinedRemoval(ctx context.Context, session neo4j.Session, devicePKs, linkPKs []string) (combinedRemovalResult, error) {
+	result := combinedRemovalResult{
+		disconnectedPairs: []WhatIfDevicePair{},
+		reroutedPaths:     []WhatIfAffectedPath{},
+	}
+
+	// Links are modeled as the ISIS adjacencies between their endpoints, in both directions
+	excludedEdges := []string{}
+	for _, linkPK := range linkPKs {
+		endpoints := getLinkEndpoints(ctx, linkPK)
+		if endpoints == "" {
+			continue
+		}
+		sides := strings.SplitN(endpoints, ":", 2)
+		excludedEdges = append(excludedEdges, sides[0]+":"+sides[1], sides[1]+":"+sides[0])
+	}
+	excludedDevices := devicePKs
+	if excludedDevices == nil {
+		excludedDevices = []string{}
+	}
+
+	params := map[string]any{
+		"excluded":       excludedDevices,
+		"excluded_edges": excludedEdges,
+	}
+
+	// Current shortest path for every pair, flagging those that cross a removed element
+	beforeCypher := `
+		MATCH (a:Device), (b:Device)
+		WHERE a.isis_system_id IS NOT NULL AND b.isis_system_id IS NOT NULL
+		  AND a.pk < b.pk
+		OPTIONAL MATCH p = shortestPath((a)-[:ISIS_ADJACENT*]-(b))
+		WITH a, b, p,
+		     a.pk IN $excluded OR b.pk IN $excluded AS removed,
+		     p IS NOT NULL AND (
+		       ANY(n IN nodes(p) WHERE n.pk IN $excluded) OR
+		       ANY(r IN relationships(p) WHERE startNode(r).pk + ':' + endNode(r).pk IN $excluded_edges)
+		     ) AS crossesRemoved
+		OPTIONAL MATCH (a)-[:LOCATED_IN]->(ma:Metro)
+		OPTIONAL MATCH (b)-[:LOCATED_IN]->(mb:Metro)
+		RETURN a.pk AS sourcePK, a.code AS sourceCode, COALESCE(ma.code, '') AS sourceMetro,
+		       b.pk AS targetPK, b.code AS targetCode, COALESCE(mb.code, '') AS targetMetro,
+		       p IS NOT NULL AS connected,
+		       removed, crossesRemoved,
+		       CASE WHEN p IS NOT NULL THEN length(p) ELSE -1 END AS hops,
+		       CASE WHEN p IS NOT NULL
+		            THEN reduce(m = 0, r IN relationships(p) | m + coalesce(r.metric, 10))
+		            ELSE -1 END AS metric
+	`
+
+	beforeResult, err := session.Run(ctx, beforeCypher, params)
+	if err != nil {
+		return result, err
+	}
+	beforeRecords, err := beforeResult.Collect(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	var totalPairs, connectedBefore, remainingPairs, connectedAfter int
+	affected := []whatIfPair{}
+	for _, record := range beforeRecords {
+		connected, _ := record.Get("connected")
+		removed, _ := record.Get("removed")
+		crossesRemoved, _ := record.Get("crossesRemoved")
+
+		totalPairs++
+		if asBool(connected) {
+			connectedBefore++
+		}
+		if asBool(removed) {
+			continue
+		}
+		remainingPairs++
+		if !asBool(connected) {
+			continue
+		}
+		if !asBool(crossesRemoved) {
+			connectedAfter++
+			continue
+		}
+
+		sourcePK, _ := record.Get("sourcePK")
+		sourceCode, _ := record.Get("sourceCode")
+		sourceMetro, _ := record.Get("sourceMetro")
+		targetPK, _ := record.Get("targetPK")
+		targetCode, _ := record.Get("targetCode")
+		targetMetro, _ := record.Get("targetMetro")
+		hops, _ := record.Get("hops")
+		metric, _ := record.Get("metric")
+		affected = append(affected, whatIfPair{
+			sourcePK:     asString(sourcePK),
+			sourceCode:   asString(sourceCode),
+			sourceMetro:  asString(sourceMetro),
+			targetPK:     asString(targetPK),
+			targetCode:   asString(targetCode),
+			targetMetro:  asString(targetMetro),
+			hopsBefore:   int(asInt64(hops)),
+			metricBefore: int(asInt64(metric)),
+		})
+	}
+
+	if totalPairs > 0 {
+		result.connectivityBefore = float64(connectedBefore) / float64(totalPairs)
+	}
+
+	if len(affected) > 0 {
+		pairs := make([]map[string]any, 0, len(affected))
+		for _, p := range affected {
+			pairs = append(pairs, map[string]any{"a": p.sourcePK, "b": p.targetPK})
+		}
+		params["pairs"] = pairs
+
+		afterCypher := `
+			UNWIND $pairs AS pair
+			MATCH (a:Device {pk: pair.a}), (b:Device {pk: pair.b})
+			OPTIONAL MATCH p = shortestPath((a)-[:ISIS_ADJACENT*]-(b))
+			WHERE NONE(n IN nodes(p) WHERE n.pk IN $excluded)
+			  AND NONE(r IN relationships(p) WHERE startNode(r).pk + ':' + endNode(r).pk IN $excluded_edges)
+			RETURN pair.a AS sourcePK, pair.b AS targetPK,
+			       CASE WHEN p IS NOT NULL THEN length(p) ELSE -1 END AS hops,
+			       CASE WHEN p IS NOT NULL
+			            THEN reduce(m = 0, r IN relationships(p) | m + coalesce(r.metric, 10))
+			            ELSE -1 END AS metric
+		`
+
+		afterResult, err := session.Run(ctx, afterCypher, params)
+		if err != nil {
+			return result, err
+		}
+		afterRecords, err := afterResult.Collect(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		type afterPath struct{ hops, metric int }
+		after := make(map[string]afterPath, len(afterRecords))
+		for _, record := range afterRecords {
+			sourcePK, _ := record.Get("sourcePK")
+			targetPK, _ := record.Get("targetPK")
+			hops, _ := record.Get("hops")
+			metric, _ := record.Get("metric")
+			after[asString(sourcePK)+":"+asString(targetPK)] = afterPath{
+				hops:   int(asInt64(hops)),
+				metric: int(asInt64(metric)),
+			}
+		}
+
+		for _, p := range affected {
+			ap, ok := after[p.sourcePK+":"+p.targetPK]
+			if !ok || ap.hops < 0 {
+				result.disconnectedPairs = append(result.disconnectedPairs, WhatIfDevicePair{
+					SourcePK:    p.sourcePK,
+					Source:      p.sourceCode,
+					SourceMetro: p.sourceMetro,
+					TargetPK:    p.targetPK,
+					Target:      p.targetCode,
+					TargetMetro: p.targetMetro,
+				})
+				continue
+			}
+
+			connectedAfter++
+			status := "rerouted"
+			if ap.hops-p.hopsBefore > 2 || ap.metric-p.metricBefore > 50 {
+				status = "degraded"
+			}
+			result.reroutedPaths = append(result.reroutedPaths, WhatIfAffectedPath{
+				Source:       p.sourceCode,
+				Target:       p.targetCode,
+				SourceMetro:  p.sourceMetro,
+				TargetMetro:  p.targetMetro,
+				HopsBefore:   p.hopsBefore,
+				MetricBefore: p.metricBefore,
+				HopsAfter:    ap.hops,
+				MetricAfter:  ap.metric,
+				Status:       status,
+			})
+		}
+	}
+
+	if remainingPairs > 0 {
+		result.connectivityAfter = float64(connectedAfter) / float64(remainingPairs)
+	}
+
+	return result, nil
+}
+
 // analyzeDeviceRemoval computes the impact of removing a single device
 func analyzeDeviceRemoval(ctx context.Context, session neo4j.Session, devicePK string, pathLimit int) WhatIfRemovalItem {
 	item := WhatIfRemovalItem{
@@ -680,7 +1059,7 @@ func analyzeDeviceRemoval(ctx context.Context, session neo4j.Session, devicePK s
 
 	result, err := session.Run(ctx, infoCypher, map[string]any{"devicePK": devicePK})
 	if err != nil {
-		log.Printf("Device removal info query error: %v", err)
+		LoggerFromContext(ctx).Error("Device removal info query error", "error", err)
 		item.Code = devicePK
 		return item
 	}
@@ -746,7 +1125,7 @@ func analyzeDeviceRemoval(ctx context.Context, session neo4j.Session, devicePK s
 		"limit":    pathLimit * 2, // Get more for filtering
 	})
 	if err != nil {
-		log.Printf("Device removal paths query error: %v", err)
+		LoggerFromContext(ctx).Error("Device removal paths query error", "error", err)
 		return item
 	}
 
@@ -866,7 +1245,7 @@ func analyzeLinkRemoval(ctx context.Context, session neo4j.Session, linkPK strin
 
 	var sideAPK, sideZPK, sideACode, sideZCode string
 	if err := envDB(ctx).QueryRow(ctx, linkQuery, linkPK).Scan(&sideAPK, &sideZPK, &sideACode, &sideZCode); err != nil {
-		log.Printf("Link lookup error for %s: %v", linkPK, err)
+		LoggerFromContext(ctx).Error("Link lookup error", "link_pk", linkPK, "error", err)
 		item.Code = "Link not found"
 		return item
 	}
@@ -899,7 +1278,7 @@ func analyzeLinkRemoval(ctx context.Context, session neo4j.Session, linkPK strin
 		"targetPK": sideZPK,
 	})
 	if err != nil {
-		log.Printf("Link disconnect check error: %v", err)
+		LoggerFromContext(ctx).Error("Link disconnect check error", "error", err)
 	} else if degResult.Next(ctx) {
 		record := degResult.Record()
 		sourceDegree, _ := record.Get("sourceDegree")
@@ -967,7 +1346,7 @@ func analyzeLinkRemoval(ctx context.Context, session neo4j.Session, linkPK strin
 		"limit":    pathLimit * 2,
 	})
 	if err != nil {
-		log.Printf("Link affected paths query error: %v", err)
+		LoggerFromContext(ctx).Error("Link affected paths query error", "error", err)
 		return item
 	}
 
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatedEndpointPath(t *testing.T) {
+	t.Parallel()
+
+	current := map[string]any{"codes": []any{"A", "B", "C"}, "hops": int64(2), "metric": float64(20)}
+
+	t.Run("not connected", func(t *testing.T) {
+		t.Parallel()
+		p := simulatedEndpointPath("A", "C", 50, nil)
+		assert.Equal(t, []string{"A", "C"}, p.Path)
+		assert.True(t, p.UsesNewLink)
+		assert.Zero(t, p.BeforeHops)
+	})
+
+	t.Run("cheaper link", func(t *testing.T) {
+		t.Parallel()
+		p := simulatedEndpointPath("A", "C", 10, current)
+		assert.Equal(t, []string{"A", "C"}, p.Path)
+		assert.Equal(t, uint32(10), p.Metric)
+		assert.True(t, p.UsesNewLink)
+		assert.False(t, p.ECMP)
+		assert.Equal(t, 2, p.BeforeHops)
+		assert.Equal(t, uint32(20), p.BeforeMetric)
+	})
+
+	t.Run("equal cost", func(t *testing.T) {
+		t.Parallel()
+		p := simulatedEndpointPath("A", "C", 20, current)
+		assert.True(t, p.ECMP)
+		assert.True(t, p.UsesNewLink)
+		assert.Equal(t, []string{"A", "B", "C"}, p.Path)
+	})
+
+	t.Run("dearer link", func(t *testing.T) {
+		t.Parallel()
+		p := simulatedEndpointPath("A", "C", 30, current)
+		assert.False(t, p.UsesNewLink)
+		assert.False(t, p.ECMP)
+		assert.Equal(t, uint32(20), p.Metric)
+		assert.Equal(t, []string{"A", "B", "C"}, p.Path)
+	})
+}
@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/malbeclabs/lake/api/handlers"
+	apitesting "github.com/malbeclabs/lake/api/testing"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedWhatIfTopology seeds a ring A-B-C-D-F-A with a leaf E hanging off C.
+func seedWhatIfTopology(t *testing.T) {
+	seedFunc := func(ctx context.Context, session neo4j.Session) error {
+		_, err := session.Run(ctx, `
+			CREATE (a:Device {pk: 'a', code: 'A', isis_system_id: '0000.0000.0001'})
+			CREATE (b:Device {pk: 'b', code: 'B', isis_system_id: '0000.0000.0002'})
+			CREATE (c:Device {pk: 'c', code: 'C', isis_system_id: '0000.0000.0003'})
+			CREATE (d:Device {pk: 'd', code: 'D', isis_system_id: '0000.0000.0004'})
+			CREATE (e:Device {pk: 'e', code: 'E', isis_system_id: '0000.0000.0005'})
+			CREATE (a)-[:ISIS_ADJACENT {metric: 10}]->(b)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 10}]->(a)
+			CREATE (b)-[:ISIS_ADJACENT {metric: 10}]->(c)
+			CREATE (c)-[:ISIS_ADJACENT {metric: 10}]->(b)
+			CREATE (c)-[:ISIS_ADJACENT {metric: 10}]->(d)
+			CREATE (d)-[:ISIS_ADJACENT {metric: 10}]->(c)
+			CREATE (f:Device {pk: 'f', code: 'F', isis_system_id: '0000.0000.0006'})
+			CREATE (d)-[:ISIS_ADJACENT {metric: 10}]->(f)
+			CREATE (f)-[:ISIS_ADJACENT {metric: 10}]->(d)
+			CREATE (f)-[:ISIS_ADJACENT {metric: 10}]->(a)
+			CREATE (a)-[:ISIS_ADJACENT {metric: 10}]->(f)
+			CREATE (c)-[:ISIS_ADJACENT {metric: 10}]->(e)
+			CREATE (e)-[:ISIS_ADJACENT {metric: 10}]->(c)
+		`, nil)
+		return err
+	}
+	apitesting.SetupTestNeo4jWithData(t, testNeo4jDB, seedFunc)
+}
+
+func postWhatIfRemoval(t *testing.T, body string) handlers.WhatIfRemovalResponse {
+	req := httptest.NewRequest(http.MethodPost, "/api/topology/whatif-removal", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handlers.PostWhatIfRemoval(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.WhatIfRemovalResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestPostWhatIfRemoval_ReroutesAroundRing(t *testing.T) {
+	seedWhatIfTopology(t)
+
+	resp := postWhatIfRemoval(t, `{"devices":["b"]}`)
+	require.Empty(t, resp.Error)
+
+	// Removing B leaves the rest of the ring and the leaf connected
+	assert.Empty(t, resp.DisconnectedPairs)
+	assert.InDelta(t, 1.0, resp.ConnectivityBefore, 0.001)
+	assert.InDelta(t, 1.0, resp.ConnectivityAfter, 0.001)
+	assert.InDelta(t, 0.0, resp.ConnectivityDelta, 0.001)
+
+	// A->C went A-B-C; it now reroutes the long way A-F-D-C
+	var found bool
+	for _, p := range resp.ReroutedPaths {
+		if p.Source == "A" && p.Target == "C" {
+			found = true
+			assert.Equal(t, 2, p.HopsBefore)
+			assert.Equal(t, 3, p.HopsAfter)
+			assert.Equal(t, 30, p.MetricAfter)
+		}
+	}
+	assert.True(t, found, "A->C should be rerouted")
+}
+
+func TestPostWhatIfRemoval_DisconnectsLeaf(t *testing.T) {
+	seedWhatIfTopology(t)
+
+	resp := postWhatIfRemoval(t, `{"devices":["c"]}`)
+	require.Empty(t, resp.Error)
+
+	// E only connects through C, so E is cut off from A, B, D and F
+	require.Len(t, resp.DisconnectedPairs, 4)
+	for _, p := range resp.DisconnectedPairs {
+		assert.True(t, p.Source == "E" || p.Target == "E", "unexpected disconnected pair %s-%s", p.Source, p.Target)
+	}
+	// 5 remaining devices = 10 pairs, 6 still connected
+	assert.InDelta(t, 0.6, resp.ConnectivityAfter, 0.001)
+	assert.Less(t, resp.ConnectivityDelta, 0.0)
+}
+
+func TestPostWhatIfRemoval_EmptyRequest(t *testing.T) {
+	resp := postWhatIfRemoval(t, `{}`)
+	assert.NotEmpty(t, resp.Error)
+}
+
+func getSimulateLinkAddition(t *testing.T, query string) handlers.SimulateLinkAdditionResponse {
+	req := httptest.NewRequest(http.MethodGet, "/api/topology/simulate-link-addition?"+query, nil)
+	rr := httptest.NewRecorder()
+	handlers.GetSimulateLinkAddition(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response handlers.SimulateLinkAdditionResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestGetSimulateLinkAddition_ShortensEndpointPath(t *testing.T) {
+	seedWhatIfTopology(t)
+
+	resp := getSimulateLinkAddition(t, "sideA=e&sideZ=a&metric=5")
+	require.Empty(t, resp.Error)
+
+	// E-A currently goes E-C-B-A at metric 30; the new link replaces it
+	require.NotNil(t, resp.NewShortestPath)
+	assert.Equal(t, []string{"E", "A"}, resp.NewShortestPath.Path)
+	assert.True(t, resp.NewShortestPath.UsesNewLink)
+	assert.False(t, resp.NewShortestPath.ECMP)
+	assert.Equal(t, 3, resp.NewShortestPath.BeforeHops)
+	assert.Equal(t, uint32(30), resp.NewShortestPath.BeforeMetric)
+
+	// E was a leaf off C
+	require.Len(t, resp.RedundancyGains, 1)
+	assert.Equal(t, "e", resp.RedundancyGains[0].DevicePK)
+	assert.True(t, resp.RedundancyGains[0].WasLeaf)
+}
+
+func TestGetSimulateLinkAddition_EqualCostCreatesECMP(t *testing.T) {
+	seedWhatIfTopology(t)
+
+	// A-F-D costs 20, the same as the new A-D link
+	resp := getSimulateLinkAddition(t, "sourcePK=a&targetPK=d&metric=20")
+	require.Empty(t, resp.Error)
+
+	require.NotNil(t, resp.NewShortestPath)
+	assert.True(t, resp.NewShortestPath.ECMP)
+	assert.Equal(t, uint32(20), resp.NewShortestPath.Metric)
+	assert.True(t, resp.CreatesECMP)
+}
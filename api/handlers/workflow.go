@@ -398,7 +398,7 @@ func GetWorkflowForSession(w http.ResponseWriter, r *http.Request) {
 	sessionIDStr := chi.URLParam(r, "id")
 	sessionID, err := uuid.Parse(sessionIDStr)
 	if err != nil {
-		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid session ID")
 		return
 	}
 
@@ -412,7 +412,7 @@ func GetWorkflowForSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		http.Error(w, internalError("Failed to get workflow", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to get workflow", err))
 		return
 	}
 	if run == nil {
@@ -430,17 +430,17 @@ func GetWorkflow(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid workflow ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid workflow ID")
 		return
 	}
 
 	run, err := GetWorkflowRun(r.Context(), id)
 	if err != nil {
-		http.Error(w, internalError("Failed to get workflow", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to get workflow", err))
 		return
 	}
 	if run == nil {
-		http.Error(w, "Workflow not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeWorkflowNotFound, "Workflow not found")
 		return
 	}
 
@@ -466,17 +466,17 @@ func StreamWorkflow(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid workflow ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "Invalid workflow ID")
 		return
 	}
 
 	run, err := GetWorkflowRun(r.Context(), id)
 	if err != nil {
-		http.Error(w, internalError("Failed to get workflow", err), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, internalError("Failed to get workflow", err))
 		return
 	}
 	if run == nil {
-		http.Error(w, "Workflow not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeWorkflowNotFound, "Workflow not found")
 		return
 	}
 
@@ -488,7 +488,7 @@ func StreamWorkflow(w http.ResponseWriter, r *http.Request) {
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Streaming not supported")
 		return
 	}
 
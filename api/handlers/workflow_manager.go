@@ -484,8 +484,8 @@ func (m *WorkflowManager) runWorkflow(
 		cfg.GraphSchemaFetcher = NewNeo4jSchemaFetcher()
 	}
 
-	// Add env context to agent
-	cfg.EnvContext = BuildEnvContext(rw.Env)
+	// Add env context to agent, with answers the session owner rated as examples
+	cfg.EnvContext = BuildEnvContext(rw.Env) + sessionChatFeedbackContext(ctx, rw.SessionID)
 
 	// Create workflow
 	wf, err := v3.New(cfg)
@@ -913,8 +913,8 @@ func (m *WorkflowManager) resumeWorkflow(
 		cfg.GraphSchemaFetcher = NewNeo4jSchemaFetcher()
 	}
 
-	// Add env context to agent
-	cfg.EnvContext = BuildEnvContext(rw.Env)
+	// Add env context to agent, with answers the session owner rated as examples
+	cfg.EnvContext = BuildEnvContext(rw.Env) + sessionChatFeedbackContext(ctx, rw.SessionID)
 
 	// Create workflow
 	wf, err := v3.New(cfg)
@@ -27,6 +27,7 @@ import (
 	"github.com/malbeclabs/lake/api/config"
 	"github.com/malbeclabs/lake/api/handlers"
 	"github.com/malbeclabs/lake/api/metrics"
+	"github.com/malbeclabs/lake/api/tracing"
 	slackbot "github.com/malbeclabs/lake/slack/bot"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/slack-go/slack/socketmode"
@@ -210,6 +211,7 @@ func spaHandler(staticDir, assetBucketURL string) http.HandlerFunc {
 
 func main() {
 	metricsAddrFlag := flag.String("metrics-addr", defaultMetricsAddr, "Address to listen on for prometheus metrics")
+	pgConnectTimeoutFlag := flag.Duration("pg-connect-timeout", config.DefaultPgConnectTimeout, "Timeout for each PostgreSQL connection attempt at startup")
 	flag.Parse()
 
 	log.Printf("Starting lake-api version=%s commit=%s date=%s", version, commit, date)
@@ -220,6 +222,9 @@ func main() {
 	_ = godotenv.Load()           // .env in current working directory
 	_ = godotenv.Load("api/.env") // api/.env when running from repo root
 
+	// Apply per-user query rate limits from env
+	handlers.ConfigureQueryRateLimits()
+
 	// Initialize Sentry for error tracking (optional - gracefully no-op if DSN not set)
 	sentryDSN := os.Getenv("SENTRY_DSN")
 	if sentryDSN != "" {
@@ -251,13 +256,33 @@ func main() {
 		}
 	}
 
+	// Initialize OpenTelemetry tracing (optional - no-op tracer if OTEL_EXPORTER_OTLP_ENDPOINT not set)
+	shutdownTracing, err := tracing.Init(context.Background(), "lake-api", version)
+	if err != nil {
+		log.Printf("Warning: OpenTelemetry initialization failed: %v", err)
+	} else {
+		if tracing.Enabled() {
+			log.Printf("OpenTelemetry tracing enabled (endpoint=%s)", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				log.Printf("OpenTelemetry shutdown error: %v", err)
+			}
+		}()
+	}
+
 	// Load configuration
 	if err := config.Load(); err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Load PostgreSQL
-	if err := config.LoadPostgres(); err != nil {
+	// Load PostgreSQL, retrying while it comes up; a SIGINT/SIGTERM stops the retries
+	pgCtx, pgStop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err = config.LoadPostgres(pgCtx, *pgConnectTimeoutFlag)
+	pgStop()
+	if err != nil {
 		log.Fatalf("Failed to load PostgreSQL: %v", err)
 	}
 	defer config.ClosePostgres()
@@ -270,10 +295,18 @@ func main() {
 		defer func() { _ = config.CloseNeo4j() }()
 	}
 
+	// Create a cancellable context for all requests - this allows us to signal
+	// SSE connections to close during shutdown (http.Server.Shutdown does NOT
+	// cancel request contexts by default)
+	serverCtx, serverCancel := context.WithCancel(context.Background())
+
 	// Initialize status cache for fast page loads
-	handlers.InitStatusCache()
+	handlers.InitStatusCache(serverCtx)
 	// Note: StopStatusCache() is called explicitly before server shutdown, not deferred
 
+	// Cache /api/stats responses, invalidated when the indexer notifies stats_updated
+	handlers.InitStatsCache(serverCtx)
+
 	// Start metrics server
 	var metricsServer *http.Server
 	if *metricsAddrFlag != "" {
@@ -296,7 +329,10 @@ func main() {
 
 	r := chi.NewRouter()
 
+	// Tag every request with an ID, returned in X-Request-ID and in error responses
+	r.Use(handlers.RequestIDMiddleware)
 	r.Use(middleware.Logger)
+	r.Use(handlers.CompressionMiddleware)
 
 	// Sentry middleware for error and performance monitoring (before Recoverer to capture panics)
 	if sentryDSN != "" {
@@ -326,6 +362,7 @@ func main() {
 
 	r.Use(middleware.Recoverer)
 	r.Use(metrics.Middleware)
+	r.Use(tracing.Middleware)
 
 	// CORS configuration - origins from env or allow all
 	corsOrigins := []string{"*"}
@@ -336,7 +373,7 @@ func main() {
 		AllowedOrigins:   corsOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-DZ-Env"},
-		ExposedHeaders:   []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+		ExposedHeaders:   []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-Request-ID"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -372,6 +409,12 @@ func main() {
 	// Apply env middleware to extract X-DZ-Env header
 	r.Use(handlers.EnvMiddleware)
 
+	// Attach a request-scoped structured logger and log each request's outcome
+	r.Use(handlers.LoggerMiddleware)
+
+	// Record mutating requests (POST/PUT/PATCH/DELETE) in the audit log
+	r.Use(handlers.AuditMiddleware)
+
 	// Health check endpoints
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -402,6 +445,14 @@ func main() {
 	// Lightweight endpoints (no rate limiting)
 	r.Get("/api/config", handlers.GetConfig)
 	r.Get("/api/version", handlers.GetVersion)
+	r.Get("/api/dz/devices/count", handlers.GetDeviceCount)
+	r.Get("/api/dz/links/count", handlers.GetLinkCount)
+
+	// SQL validation only plans queries, so it gets a lighter limit than execution
+	r.Group(func(r chi.Router) {
+		r.Use(handlers.RateLimitMiddleware(handlers.SQLValidateRateLimiter))
+		r.Post("/api/sql/validate", handlers.ValidateQuery)
+	})
 
 	// Database query endpoints (rate limited)
 	r.Group(func(r chi.Router) {
@@ -418,39 +469,65 @@ func main() {
 		r.Get("/api/status/links/{pk}/history", handlers.GetSingleLinkHistory)
 		r.Get("/api/timeline", handlers.GetTimeline)
 		r.Get("/api/timeline/bounds", handlers.GetTimelineBounds)
+		r.Get("/api/timeline/stream", handlers.StreamTimeline)
 
 		// Outage routes
 		r.Get("/api/outages/links", handlers.GetLinkOutages)
 		r.Get("/api/outages/links/csv", handlers.GetLinkOutagesCSV)
+		r.Get("/api/outages/links/parquet", handlers.GetLinkOutagesParquet)
 
 		// Search routes
 		r.Get("/api/search", handlers.Search)
 		r.Get("/api/search/autocomplete", handlers.SearchAutocomplete)
+		r.Get("/api/search/geo", handlers.GetGeoSearch)
 
 		// DZ entity routes
 		r.Get("/api/dz/devices", handlers.GetDevices)
 		r.Get("/api/dz/devices/{pk}", handlers.GetDevice)
+		r.Get("/api/dz/devices/{pk}/neighbors", handlers.GetDeviceNeighbors)
+		r.Get("/api/dz/devices/{pk}/reachability", handlers.GetDeviceReachability)
+		r.Get("/api/dz/devices/{pk}/uptime", handlers.GetDeviceUptime)
+		r.Get("/api/dz/devices/{pk}/interface-list", handlers.GetDeviceInterfaces)
+		r.Get("/api/dz/devices/{pk}/config-drift", handlers.GetDeviceConfigDrift)
+		r.Get("/api/dz/devices/{pk}/isis-adjacency-history", handlers.GetDeviceISISAdjacencyHistory)
 		r.Get("/api/dz/links", handlers.GetLinks)
+		r.Get("/api/dz/links/topology-delta", handlers.GetTopologyDelta)
 		r.Get("/api/dz/links/{pk}", handlers.GetLink)
+		r.Get("/api/dz/links/{pk}/latency-timeseries", handlers.GetLinkLatencyTimeseries)
+		r.Get("/api/dz/links/{pk}/sla-compliance", handlers.GetLinkSLACompliance)
+		r.Get("/api/dz/links/{pk}/path-in-topology", handlers.GetLinkPathUsage)
+		r.Get("/api/dz/links/{pk}/redundancy-check", handlers.GetLinkRedundancyCheck)
+		r.Get("/api/dz/links/{pk}/peer-comparison", handlers.GetLinkPeerComparison)
+		r.Group(func(r chi.Router) {
+			r.Use(handlers.RequireAuth)
+			r.Get("/api/dz/links/{pk}/traffic-accounting", handlers.GetLinkTrafficAccounting)
+		})
 		r.Get("/api/dz/links-health", handlers.GetLinkHealth)
 		r.Get("/api/dz/metros", handlers.GetMetros)
 		r.Get("/api/dz/metros/{pk}", handlers.GetMetro)
+		r.Get("/api/dz/metros/{pk}/connectivity-history", handlers.GetMetroConnectivityHistory)
 		r.Get("/api/dz/contributors", handlers.GetContributors)
 		r.Get("/api/dz/contributors/{pk}", handlers.GetContributor)
+		r.Get("/api/dz/contributors/{pk}/network-summary", handlers.GetContributorNetworkSummary)
 		r.Get("/api/dz/users", handlers.GetUsers)
 		r.Get("/api/dz/users/{pk}", handlers.GetUser)
 		r.Get("/api/dz/users/{pk}/traffic", handlers.GetUserTraffic)
+		r.Get("/api/dz/users/{pk}/session-timeline", handlers.GetUserSessionTimeline)
 		r.Get("/api/dz/users/{pk}/multicast-groups", handlers.GetUserMulticastGroups)
 		r.Get("/api/dz/multicast-groups", handlers.GetMulticastGroups)
 		r.Get("/api/dz/multicast-groups/{pk}", handlers.GetMulticastGroup)
 		r.Get("/api/dz/multicast-groups/{pk}/tree-paths", handlers.GetMulticastTreePaths)
+		r.Get("/api/dz/multicast-groups/{pk}/topology", handlers.GetMulticastGroupTopology)
 		r.Get("/api/dz/multicast-groups/{pk}/traffic", handlers.GetMulticastGroupTraffic)
 		r.Get("/api/dz/field-values", handlers.GetFieldValues)
 
 		// Solana entity routes
 		r.Get("/api/solana/validators", handlers.GetValidators)
 		r.Get("/api/solana/validators/{vote_pubkey}", handlers.GetValidator)
+		r.Get("/api/solana/validators/{vote_pubkey}/stake-history", handlers.GetValidatorStakeHistory)
+		r.Get("/api/solana/validators/{vote_pubkey}/dz-performance", handlers.GetValidatorDZPerformance)
 		r.Get("/api/solana/gossip-nodes", handlers.GetGossipNodes)
+		r.Get("/api/solana/gossip-nodes/map", handlers.GetGossipNodeMap)
 		r.Get("/api/solana/gossip-nodes/{pubkey}", handlers.GetGossipNode)
 
 		// Stake analytics routes
@@ -458,6 +535,8 @@ func main() {
 		r.Get("/api/stake/history", handlers.GetStakeHistory)
 		r.Get("/api/stake/changes", handlers.GetStakeChanges)
 		r.Get("/api/stake/validators", handlers.GetStakeValidators)
+		r.Get("/api/stake/validators/by-metro", handlers.GetStakeByMetro)
+		r.Get("/api/stake/concentration", handlers.GetStakeConcentration)
 
 		// Traffic analytics routes
 		r.Get("/api/traffic/data", handlers.GetTrafficData)
@@ -469,6 +548,8 @@ func main() {
 		r.Get("/api/traffic/dashboard/drilldown", handlers.GetTrafficDashboardDrilldown)
 		r.Get("/api/traffic/dashboard/burstiness", handlers.GetTrafficDashboardBurstiness)
 		r.Get("/api/traffic/dashboard/health", handlers.GetTrafficDashboardHealth)
+		r.Get("/api/traffic/dashboard/anomaly", handlers.GetTrafficDashboardAnomaly)
+		r.Get("/api/traffic/dashboard/capacity-planning", handlers.GetTrafficCapacityPlanning)
 
 		// Topology endpoints (ClickHouse only)
 		r.Get("/api/topology", handlers.GetTopology)
@@ -476,26 +557,53 @@ func main() {
 		r.Get("/api/topology/link-latency", handlers.GetLinkLatencyHistory)
 		r.Get("/api/topology/latency-comparison", handlers.GetLatencyComparison)
 		r.Get("/api/topology/latency-history/{origin}/{target}", handlers.GetLatencyHistory)
+		r.Get("/api/topology/asn-paths", handlers.GetASNPaths)
+		r.Get("/api/topology/link-utilization", handlers.GetLinkUtilization)
+		r.Get("/api/topology/isis-changes", handlers.GetISISChanges)
+		r.Get("/api/topology/path-latency-percentiles", handlers.GetPathLatencyPercentiles)
 
 		// Topology endpoints (require Neo4j — mainnet only)
 		r.Group(func(r chi.Router) {
 			r.Use(handlers.RequireNeo4jMiddleware)
 			r.Get("/api/topology/isis", handlers.GetISISTopology)
+			r.Get("/api/topology/neighbor-graph", handlers.GetNeighborGraph)
+			r.Get("/api/topology/isis-spt", handlers.GetISISSPT)
+			r.Get("/api/topology/route-reflector-candidates", handlers.GetRouteReflectorCandidates)
 			r.Get("/api/topology/path", handlers.GetISISPath)
+			r.Get("/api/topology/historical-path", handlers.GetHistoricalPath)
+			r.Get("/api/dz/users/{pk}/path-to-device", handlers.GetUserPathToDevice)
 			r.Get("/api/topology/paths", handlers.GetISISPaths)
+			r.Get("/api/topology/ecmp-paths", handlers.GetECMPPaths)
 			r.Get("/api/topology/compare", handlers.GetTopologyCompare)
 			r.Get("/api/topology/impact/{pk}", handlers.GetFailureImpact)
 			r.Get("/api/topology/critical-links", handlers.GetCriticalLinks)
+			r.Get("/api/topology/isis-metric-optimization", handlers.GetISISMetricOptimization)
+			r.Get("/api/topology/bfd-sessions", handlers.GetBFDSessions)
+			r.Get("/api/topology/segment-routing-sids", handlers.GetSegmentRoutingSIDs)
+			r.Get("/api/topology/path-with-sids", handlers.GetPathWithSIDs)
 			r.Get("/api/topology/redundancy-report", handlers.GetRedundancyReport)
+			r.Get("/api/topology/betweenness-centrality", handlers.GetBetweennessCentrality)
+			r.Get("/api/topology/device-groups", handlers.GetDeviceGroups)
 			r.Get("/api/topology/simulate-link-removal", handlers.GetSimulateLinkRemoval)
 			r.Get("/api/topology/simulate-link-addition", handlers.GetSimulateLinkAddition)
 			r.Get("/api/topology/metro-connectivity", handlers.GetMetroConnectivity)
+			r.Get("/api/topology/metro-resilience-score", handlers.GetMetroResilienceScore)
+			r.Get("/api/topology/critical-path", handlers.GetCriticalPathSLA)
+			r.Get("/api/topology/load-balancing-quality", handlers.GetLoadBalancingQuality)
+			r.Get("/api/topology/path-diversity", handlers.GetPathDiversity)
 			r.Get("/api/topology/metro-path-latency", handlers.GetMetroPathLatency)
+			r.Get("/api/topology/latency-heatmap", handlers.GetLatencyHeatmap)
 			r.Get("/api/topology/metro-path-detail", handlers.GetMetroPathDetail)
 			r.Get("/api/topology/metro-paths", handlers.GetMetroPaths)
 			r.Get("/api/topology/metro-device-paths", handlers.GetMetroDevicePaths)
 			r.Post("/api/topology/maintenance-impact", handlers.PostMaintenanceImpact)
 			r.Post("/api/topology/whatif-removal", handlers.PostWhatIfRemoval)
+			r.Get("/api/topology/maintenance-windows", handlers.GetMaintenanceWindows)
+
+			r.Group(func(r chi.Router) {
+				r.Use(handlers.RequireAuth)
+				r.Post("/api/topology/maintenance-window", handlers.PostMaintenanceWindow)
+			})
 		})
 
 		// SQL endpoints
@@ -507,6 +615,7 @@ func main() {
 		r.Group(func(r chi.Router) {
 			r.Use(handlers.RequireNeo4jMiddleware)
 			r.Post("/api/cypher/query", handlers.ExecuteCypher)
+			r.Post("/api/cypher/explain", handlers.ExplainCypher)
 			r.Post("/api/cypher/generate", handlers.GenerateCypher)
 			r.Post("/api/cypher/generate/stream", handlers.GenerateCypherStream)
 		})
@@ -518,8 +627,10 @@ func main() {
 		r.Post("/api/query", handlers.ExecuteQuery)
 		r.Post("/api/generate", handlers.GenerateSQL)
 		r.Post("/api/generate/stream", handlers.GenerateSQLStream)
+		r.Post("/api/generate/feedback", handlers.PostGenerateFeedback)
 		r.Post("/api/chat", handlers.Chat)
 		r.Post("/api/chat/stream", handlers.ChatStream)
+		r.Post("/api/chat/feedback", handlers.PostChatFeedback)
 		r.Post("/api/complete", handlers.Complete)
 		r.Post("/api/visualize/recommend", handlers.RecommendVisualization)
 	})
@@ -531,6 +642,13 @@ func main() {
 	r.Get("/api/sessions/{id}", handlers.GetSession)
 	r.Put("/api/sessions/{id}", handlers.UpdateSession)
 	r.Delete("/api/sessions/{id}", handlers.DeleteSession)
+	r.Put("/api/sessions/{id}/tags", handlers.PutSessionTags)
+	r.Post("/api/sessions/{id}/share", handlers.PostShareSession)
+	r.Get("/api/sessions/shared/{token}", handlers.GetSharedSession)
+	r.Group(func(r chi.Router) {
+		r.Use(handlers.RequireAuth)
+		r.Delete("/api/sessions", handlers.BulkDeleteSessions)
+	})
 
 	// Session workflow route (get running workflow for a session)
 	r.Get("/api/sessions/{id}/workflow", handlers.GetWorkflowForSession)
@@ -545,10 +663,23 @@ func main() {
 	r.Get("/api/auth/nonce", handlers.GetAuthNonce)
 	r.Post("/api/auth/wallet", handlers.PostAuthWallet)
 	r.Post("/api/auth/google", handlers.PostAuthGoogle)
+	r.Post("/api/auth/refresh", handlers.PostAuthRefresh)
 	r.Get("/api/usage/quota", handlers.GetUsageQuota)
+	r.Group(func(r chi.Router) {
+		r.Use(handlers.RequireAuth)
+		r.Post("/api/auth/api-keys", handlers.PostAuthAPIKey)
+		r.Get("/api/usage/history", handlers.GetUsageHistory)
+	})
+
+	// Admin routes
+	r.Group(func(r chi.Router) {
+		r.Use(handlers.RequireAuth)
+		r.Use(handlers.RequireAdmin)
+		r.Get("/api/admin/audit-log", handlers.GetAuditLog)
+	})
 
 	// MCP (Model Context Protocol) server endpoint
-	mcpHandler := handlers.InitMCP()
+	mcpHandler := handlers.MCPRateLimitMiddleware(handlers.InitMCP())
 	r.Handle("/api/mcp", mcpHandler)
 	r.Handle("/api/mcp/*", mcpHandler)
 
@@ -585,10 +716,6 @@ func main() {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
-	// Create a cancellable context for all requests - this allows us to signal
-	// SSE connections to close during shutdown (http.Server.Shutdown does NOT
-	// cancel request contexts by default)
-	serverCtx, serverCancel := context.WithCancel(context.Background())
 	server.BaseContext = func(_ net.Listener) context.Context {
 		return serverCtx
 	}
@@ -611,6 +738,9 @@ func main() {
 	handlers.InitUsageMetrics(serverCtx)
 	handlers.StartDailyResetWorker(serverCtx)
 
+	// Post Slack notifications ahead of scheduled maintenance windows
+	handlers.StartMaintenanceNotifier(serverCtx)
+
 	// Slack OAuth routes (available when SLACK_CLIENT_ID is set, regardless of bot mode)
 	if os.Getenv("SLACK_CLIENT_ID") != "" {
 		r.Group(func(r chi.Router) {
@@ -664,6 +794,9 @@ func main() {
 	// Stop background cache goroutines (they may be blocking on DB queries)
 	handlers.StopStatusCache()
 
+	// Close live timeline streams so their handlers return before Shutdown
+	handlers.CloseTimelineHub()
+
 	// Give existing connections a short time to complete after context cancellation
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -730,6 +863,10 @@ func startSlackBot(ctx context.Context, r *chi.Mux) *slackbot.EventHandler {
 		cfg.BotUserID,
 		ctx,
 	)
+	eventHandler.SetSlashCommand(cfg.SlashCommand, slackbot.NewStatusLookup())
+	if config.PgPool != nil {
+		eventHandler.SetMaintenanceStore(slackbot.NewMaintenanceStore())
+	}
 	eventHandler.StartCleanup(ctx)
 
 	// Start bot based on mode
@@ -756,8 +893,12 @@ func startSlackBot(ctx context.Context, r *chi.Mux) *slackbot.EventHandler {
 		r.Post("/slack/events", func(w http.ResponseWriter, r *http.Request) {
 			eventHandler.HandleHTTP(w, r, cfg.SigningSecret)
 		})
+		// Interactivity requests (maintenance approval buttons)
+		r.Post("/slack/interactive", func(w http.ResponseWriter, r *http.Request) {
+			eventHandler.HandleMaintenanceActionCallback(w, r, cfg.SigningSecret)
+		})
 
-		log.Println("Slack bot started in HTTP mode (route: /slack/events)")
+		log.Println("Slack bot started in HTTP mode (routes: /slack/events, /slack/interactive)")
 	}
 
 	return eventHandler
@@ -828,6 +969,11 @@ func startSlackBotMultiTenant(ctx context.Context, r *chi.Mux) *slackbot.EventHa
 	)
 	eventHandler.SetClientManager(clientManager)
 	eventHandler.SetSigningSecret(signingSecret)
+	slashCommand := os.Getenv("SLACK_SLASH_COMMAND")
+	if slashCommand == "" {
+		slashCommand = slackbot.DefaultSlashCommand
+	}
+	eventHandler.SetSlashCommand(slashCommand, slackbot.NewStatusLookup())
 	eventHandler.StartCleanup(ctx)
 
 	// HTTP mode: add /slack/events route
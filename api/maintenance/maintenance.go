@@ -0,0 +1,101 @@
+// Package maintenance holds the scheduling state of maintenance windows that
+// is shared by the API handlers and the Slack bot's approval buttons.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// DeferInterval is how far the Defer button pushes a window back.
+const DeferInterval = 30 * time.Minute
+
+// Maintenance window statuses
+const (
+	StatusPending   = "pending"
+	StatusApproved  = "approved"
+	StatusCancelled = "cancelled"
+)
+
+// Action IDs of the buttons on the Slack approval request. The button value is the window ID.
+const (
+	ActionApprove = "maintenance_approve"
+	ActionDefer   = "maintenance_defer"
+	ActionCancel  = "maintenance_cancel"
+)
+
+// ErrWindowNotFound is returned when an action targets a window that doesn't
+// exist, has already ended, or has been cancelled.
+var ErrWindowNotFound = errors.New("maintenance window not found")
+
+// Window is the schedule and approval state of a maintenance window
+type Window struct {
+	ID         uuid.UUID
+	StartAt    time.Time
+	EndAt      time.Time
+	Status     string
+	ApprovedBy *string // Slack user ID
+	ApprovedAt *time.Time
+}
+
+// update applies set to an upcoming, non-cancelled window and returns the
+// updated row. Extra args are numbered from $3.
+func update(ctx context.Context, id uuid.UUID, set string, args ...any) (*Window, error) {
+	var w Window
+	err := config.PgPool.QueryRow(ctx, `
+		UPDATE maintenance_windows SET `+set+`
+		WHERE id = $1 AND status <> $2 AND end_at > NOW()
+		RETURNING id, start_at, end_at, status, approved_by, approved_at`,
+		append([]any{id, StatusCancelled}, args...)...,
+	).Scan(&w.ID, &w.StartAt, &w.EndAt, &w.Status, &w.ApprovedBy, &w.ApprovedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWindowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Approve marks a window approved by the given Slack user
+func Approve(ctx context.Context, id uuid.UUID, slackUserID string) (*Window, error) {
+	return update(ctx, id, "status = $3, approved_by = $4, approved_at = NOW()", StatusApproved, slackUserID)
+}
+
+// Defer pushes a window back by d, keeping its length. The pre-start
+// notification is reset so it fires again ahead of the new start.
+func Defer(ctx context.Context, id uuid.UUID, d time.Duration) (*Window, error) {
+	return update(ctx, id, `
+		start_at = start_at + make_interval(secs => $3),
+		end_at = end_at + make_interval(secs => $3),
+		notified_at = NULL`, d.Seconds())
+}
+
+// Cancel cancels a window so it is no longer listed or notified
+func Cancel(ctx context.Context, id uuid.UUID) (*Window, error) {
+	return update(ctx, id, "status = $3", StatusCancelled)
+}
+
+// RequesterEmail returns the email of the account that scheduled the window,
+// or "" if it was scheduled anonymously or by a wallet user.
+func RequesterEmail(ctx context.Context, id uuid.UUID) (string, error) {
+	var email *string
+	err := config.PgPool.QueryRow(ctx, `
+		SELECT a.email
+		FROM maintenance_windows mw
+		LEFT JOIN accounts a ON a.id = mw.created_by
+		WHERE mw.id = $1
+	`, id).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrWindowNotFound
+	}
+	if err != nil || email == nil {
+		return "", err
+	}
+	return *email, nil
+}
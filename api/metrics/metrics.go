@@ -37,6 +37,15 @@ var (
 		[]string{"method", "path"},
 	)
 
+	HTTPResponseSize = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "doublezero_lake_api_http_response_size_bytes",
+			Help:    "Size of HTTP response bodies in bytes, before compression",
+			Buckets: prometheus.ExponentialBuckets(1024, 4, 10), // 1KiB to 256MiB
+		},
+		[]string{"method", "path"},
+	)
+
 	HTTPRequestsInFlight = promauto.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "doublezero_lake_api_http_requests_in_flight",
@@ -44,6 +53,14 @@ var (
 		},
 	)
 
+	HTTPCompressionBytesSavedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "doublezero_lake_api_http_compression_bytes_saved_total",
+			Help: "Total bytes saved by compressing HTTP responses",
+		},
+		[]string{"encoding"}, // "gzip", "br"
+	)
+
 	// ClickHouse metrics
 	ClickHouseQueriesTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -61,6 +78,47 @@ var (
 		},
 	)
 
+	// Neo4j query metrics
+	Neo4jQueryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "doublezero_lake_api_neo4j_query_duration_seconds",
+			Help:    "Duration of Neo4j queries in seconds",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12), // 10ms to ~41s
+		},
+		[]string{"query_type", "status"},
+	)
+
+	Neo4jQueryErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "doublezero_lake_api_neo4j_query_errors_total",
+			Help: "Total number of failed Neo4j queries",
+		},
+		[]string{"query_type"},
+	)
+
+	// Neo4j session pool metrics
+	Neo4jSessionsActive = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "doublezero_lake_api_neo4j_sessions_active",
+			Help: "Number of Neo4j sessions currently checked out of the pool",
+		},
+	)
+
+	Neo4jSessionPoolWaitDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "doublezero_lake_api_neo4j_session_pool_wait_seconds",
+			Help:    "Time spent waiting to acquire a Neo4j session from the pool",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10), // 100us to ~26s
+		},
+	)
+
+	Neo4jSessionAcquireTimeoutsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "doublezero_lake_api_neo4j_session_acquire_timeouts_total",
+			Help: "Total number of Neo4j session acquisitions that timed out",
+		},
+	)
+
 	// Anthropic API metrics
 	AnthropicRequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -164,6 +222,42 @@ var (
 			Help: "Current utilization of global daily limit (0-1, or 0 if unlimited)",
 		},
 	)
+
+	// Chat feedback metrics
+	ChatFeedbackTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "doublezero_lake_api_chat_feedback_total",
+			Help: "Total number of chat response ratings",
+		},
+		[]string{"rating"}, // "positive", "negative"
+	)
+
+	// Network latency metrics
+	PathLatencyPercentileSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "doublezero_lake_api_path_latency_percentile_seconds",
+			Help: "Percentile of per-link median RTT across active links over the last hour",
+		},
+		[]string{"env", "percentile"}, // percentile: "p5", "p25", "p50", "p75", "p95", "p99"
+	)
+
+	// Timeline metrics
+	TimelineEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "doublezero_lake_api_timeline_events_total",
+			Help: "Total number of timeline events returned by timeline queries, before filtering and pagination",
+		},
+		[]string{"category", "entity_type"},
+	)
+
+	TimelineQueryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "doublezero_lake_api_timeline_query_duration_seconds",
+			Help:    "Duration of timeline requests in seconds",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 15), // 1ms to ~16s
+		},
+		[]string{"cache_hit"}, // "true", "false"
+	)
 )
 
 // Middleware returns a chi middleware that records HTTP metrics.
@@ -187,6 +281,7 @@ func Middleware(next http.Handler) http.Handler {
 
 		HTTPRequestsTotal.WithLabelValues(r.Method, path, status).Inc()
 		HTTPRequestDuration.WithLabelValues(r.Method, path).Observe(duration)
+		HTTPResponseSize.WithLabelValues(r.Method, path).Observe(float64(ww.BytesWritten()))
 	})
 }
 
@@ -200,6 +295,17 @@ func RecordClickHouseQuery(duration time.Duration, err error) {
 	ClickHouseQueryDuration.Observe(duration.Seconds())
 }
 
+// RecordNeo4jQuery records metrics for a Neo4j query (or a graph computation
+// backed by one), labelled by the kind of query that was run.
+func RecordNeo4jQuery(queryType string, duration time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+		Neo4jQueryErrorsTotal.WithLabelValues(queryType).Inc()
+	}
+	Neo4jQueryDuration.WithLabelValues(queryType, status).Observe(duration.Seconds())
+}
+
 // RecordAnthropicRequest records metrics for an Anthropic API request.
 func RecordAnthropicRequest(endpoint string, duration time.Duration, err error) {
 	status := "success"
@@ -239,6 +345,15 @@ func RecordWorkflowRun(classification string, llmCalls, sqlQueries, sqlErrors in
 	}
 }
 
+// RecordChatFeedback records a rating of a chat response (1 or -1).
+func RecordChatFeedback(rating int) {
+	if rating > 0 {
+		ChatFeedbackTotal.WithLabelValues("positive").Inc()
+	} else {
+		ChatFeedbackTotal.WithLabelValues("negative").Inc()
+	}
+}
+
 // RecordUsageQuestion records a question for usage metrics.
 func RecordUsageQuestion(accountType string) {
 	UsageQuestionsTotal.WithLabelValues(accountType).Inc()
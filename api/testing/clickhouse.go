@@ -231,7 +231,7 @@ func createClickHouseConn(ctx context.Context, addr, database, username, passwor
 
 // SetupTestClickHouseWithMigrations sets up a test database with full schema migrations.
 // Use this when your test needs the actual table schemas from the indexer migrations.
-func SetupTestClickHouseWithMigrations(t *testing.T, db *ClickHouseDB) {
+func SetupTestClickHouseWithMigrations(t testing.TB, db *ClickHouseDB) {
 	ctx := t.Context()
 
 	// Create a unique database for this test
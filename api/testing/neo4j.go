@@ -140,7 +140,7 @@ func SetupTestNeo4j(t *testing.T, db *Neo4jDB) {
 
 // SetupTestNeo4jWithData sets up a test Neo4j client with optional data seeding.
 // Uses a read-write client for setup, then swaps to read-only for the test.
-func SetupTestNeo4jWithData(t *testing.T, db *Neo4jDB, seedFunc func(ctx context.Context, session neo4j.Session) error) {
+func SetupTestNeo4jWithData(t testing.TB, db *Neo4jDB, seedFunc func(ctx context.Context, session neo4j.Session) error) {
 	ctx := t.Context()
 
 	// Create a read-write client for seeding
@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClickHouse wraps conn so Query, QueryRow, Select and Exec each run in a
+// child span of the request. Query spans stay open until the rows are closed.
+func ClickHouse(conn driver.Conn) driver.Conn {
+	if conn == nil {
+		return nil
+	}
+	return &tracedConn{Conn: conn}
+}
+
+type tracedConn struct {
+	driver.Conn
+}
+
+func (c *tracedConn) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	ctx, span := StartDBSpan(ctx, "clickhouse", query)
+	rows, err := c.Conn.Query(ctx, query, args...)
+	if err != nil {
+		EndDBSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (c *tracedConn) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	ctx, span := StartDBSpan(ctx, "clickhouse", query)
+	row := c.Conn.QueryRow(ctx, query, args...)
+	EndDBSpan(span, row.Err())
+	return row
+}
+
+func (c *tracedConn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	ctx, span := StartDBSpan(ctx, "clickhouse", query)
+	err := c.Conn.Select(ctx, dest, query, args...)
+	EndDBSpan(span, err)
+	return err
+}
+
+func (c *tracedConn) Exec(ctx context.Context, query string, args ...any) error {
+	ctx, span := StartDBSpan(ctx, "clickhouse", query)
+	err := c.Conn.Exec(ctx, query, args...)
+	EndDBSpan(span, err)
+	return err
+}
+
+type tracedRows struct {
+	driver.Rows
+	span trace.Span
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	if rowsErr := r.Rows.Err(); rowsErr != nil {
+		EndDBSpan(r.span, rowsErr)
+	} else {
+		EndDBSpan(r.span, err)
+	}
+	return err
+}
+
+// Neo4jSession wraps session so each Run executes in a child span of the
+// request.
+func Neo4jSession(session neo4j.Session) neo4j.Session {
+	return &tracedSession{Session: session}
+}
+
+type tracedSession struct {
+	neo4j.Session
+}
+
+func (s *tracedSession) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.Result, error) {
+	ctx, span := StartDBSpan(ctx, "neo4j", cypher)
+	result, err := s.Session.Run(ctx, cypher, params)
+	EndDBSpan(span, err)
+	return result, err
+}
@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBOperation(t *testing.T) {
+	t.Parallel()
+
+	for statement, want := range map[string]string{
+		"SELECT pk FROM dz_devices_current":           "SELECT",
+		"\n\t\tselect count() FROM dz_links_current":  "SELECT",
+		"WITH link_rtt AS (SELECT 1) SELECT * FROM x": "WITH",
+		"MATCH (d:Device) RETURN d.pk":                "MATCH",
+		"OPTIONAL MATCH (d)-[:LOCATED_IN]->(m:Metro)": "OPTIONAL",
+		"INSERT INTO t VALUES (?)":                    "INSERT",
+		"(SELECT 1)":                                  "SELECT",
+		"":                                            "",
+		"   ":                                         "",
+	} {
+		assert.Equal(t, want, dbOperation(statement), "statement %q", statement)
+	}
+}
@@ -3,11 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -23,17 +23,16 @@ import (
 	"github.com/malbeclabs/doublezero/smartcontract/sdk/go/serviceability"
 	"github.com/malbeclabs/doublezero/smartcontract/sdk/go/telemetry"
 	"github.com/malbeclabs/doublezero/tools/maxmind/pkg/geoip"
-	"github.com/malbeclabs/doublezero/tools/maxmind/pkg/metrodb"
 	"github.com/malbeclabs/doublezero/tools/solana/pkg/rpc"
 	"github.com/malbeclabs/lake/indexer/pkg/clickhouse"
 	dztelemusage "github.com/malbeclabs/lake/indexer/pkg/dz/telemetry/usage"
+	mcpgeoip "github.com/malbeclabs/lake/indexer/pkg/geoip"
 	"github.com/malbeclabs/lake/indexer/pkg/indexer"
 	"github.com/malbeclabs/lake/indexer/pkg/metrics"
 	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
 	"github.com/malbeclabs/lake/indexer/pkg/server"
 	"github.com/malbeclabs/lake/indexer/pkg/sol"
 	"github.com/malbeclabs/lake/utils/pkg/logger"
-	"github.com/oschwald/geoip2-golang"
 )
 
 var (
@@ -78,6 +77,9 @@ func run() error {
 	clickhouseUsernameFlag := flag.String("clickhouse-username", "default", "ClickHouse username (or set CLICKHOUSE_USERNAME env var)")
 	clickhousePasswordFlag := flag.String("clickhouse-password", "", "ClickHouse password (or set CLICKHOUSE_PASSWORD env var)")
 	clickhouseSecureFlag := flag.Bool("clickhouse-secure", false, "Enable TLS for ClickHouse Cloud (or set CLICKHOUSE_SECURE=true env var)")
+	chCircuitBreakerMaxRequestsFlag := flag.Uint("ch-circuit-breaker-max-requests", 5, "ClickHouse inserts let through to probe recovery after the circuit breaker opens")
+	chCircuitBreakerIntervalFlag := flag.Duration("ch-circuit-breaker-interval", 60*time.Second, "How often the ClickHouse circuit breaker resets failure counts while closed")
+	chCircuitBreakerTimeoutFlag := flag.Duration("ch-circuit-breaker-timeout", 30*time.Second, "How long the ClickHouse circuit breaker stays open, dropping inserts")
 
 	// Neo4j configuration (optional)
 	neo4jURIFlag := flag.String("neo4j-uri", "", "Neo4j server URI (e.g., bolt://localhost:7687, or set NEO4J_URI env var)")
@@ -104,6 +106,7 @@ func run() error {
 	isisS3BucketFlag := flag.String("isis-s3-bucket", "doublezero-mn-beta-isis-db", "S3 bucket for IS-IS dumps (or set ISIS_S3_BUCKET env var)")
 	isisS3RegionFlag := flag.String("isis-s3-region", "us-east-1", "AWS region for IS-IS S3 bucket (or set ISIS_S3_REGION env var)")
 	isisRefreshIntervalFlag := flag.Duration("isis-refresh-interval", 30*time.Second, "Refresh interval for IS-IS sync (or set ISIS_REFRESH_INTERVAL env var)")
+	isisMaxConcurrencyFlag := flag.Int("isis-max-concurrency", 4, "Maximum IS-IS dump files synced in parallel (or set ISIS_MAX_CONCURRENCY env var)")
 
 	// Readiness configuration
 	skipReadyWaitFlag := flag.Bool("skip-ready-wait", false, "Skip waiting for views to be ready (for preview/dev environments)")
@@ -163,6 +166,11 @@ func run() error {
 			*isisRefreshIntervalFlag = d
 		}
 	}
+	if envISISMaxConcurrency := os.Getenv("ISIS_MAX_CONCURRENCY"); envISISMaxConcurrency != "" {
+		if n, err := strconv.Atoi(envISISMaxConcurrency); err == nil {
+			*isisMaxConcurrencyFlag = n
+		}
+	}
 
 	// Override mock device usage flag with environment variable if set
 	if os.Getenv("MOCK_DEVICE_USAGE") == "true" {
@@ -295,6 +303,11 @@ func run() error {
 		}
 	}()
 	log.Info("clickhouse client initialized", "addr", *clickhouseAddrFlag, "database", *clickhouseDatabaseFlag)
+	clickhouseDB = clickhouse.NewCircuitBreakerClient(clickhouseDB, clickhouse.CircuitBreakerConfig{
+		MaxRequests: uint32(*chCircuitBreakerMaxRequestsFlag),
+		Interval:    *chCircuitBreakerIntervalFlag,
+		Timeout:     *chCircuitBreakerTimeoutFlag,
+	})
 
 	// Determine GeoIP database paths: flag takes precedence, then env var, then default
 	geoipCityDBPath := *geoipCityDBPathFlag
@@ -315,7 +328,7 @@ func run() error {
 	var geoIPResolver geoip.Resolver
 	if geoipEnabled {
 		var geoIPCloseFn func() error
-		geoIPResolver, geoIPCloseFn, err = initializeGeoIP(geoipCityDBPath, geoipASNDBPath, log)
+		geoIPResolver, geoIPCloseFn, err = mcpgeoip.OpenResolver(log, geoipCityDBPath, geoipASNDBPath)
 		if err != nil {
 			return fmt.Errorf("failed to initialize GeoIP: %w", err)
 		}
@@ -452,6 +465,7 @@ func run() error {
 			ISISS3Bucket:        *isisS3BucketFlag,
 			ISISS3Region:        *isisS3RegionFlag,
 			ISISRefreshInterval: *isisRefreshIntervalFlag,
+			ISISMaxConcurrency:  *isisMaxConcurrencyFlag,
 
 			// Readiness configuration
 			SkipReadyWait: *skipReadyWaitFlag,
@@ -481,40 +495,3 @@ func run() error {
 		return err
 	}
 }
-
-func initializeGeoIP(cityDBPath, asnDBPath string, log *slog.Logger) (geoip.Resolver, func() error, error) {
-	cityDB, err := geoip2.Open(cityDBPath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open GeoIP city database: %w", err)
-	}
-
-	asnDB, err := geoip2.Open(asnDBPath)
-	if err != nil {
-		cityDB.Close()
-		return nil, nil, fmt.Errorf("failed to open GeoIP ASN database: %w", err)
-	}
-
-	metroDB, err := metrodb.New()
-	if err != nil {
-		cityDB.Close()
-		asnDB.Close()
-		return nil, nil, fmt.Errorf("failed to create metro database: %w", err)
-	}
-
-	resolver, err := geoip.NewResolver(log, cityDB, asnDB, metroDB)
-	if err != nil {
-		cityDB.Close()
-		asnDB.Close()
-		return nil, nil, fmt.Errorf("failed to create GeoIP resolver: %w", err)
-	}
-
-	return resolver, func() error {
-		if err := cityDB.Close(); err != nil {
-			return fmt.Errorf("failed to close city database: %w", err)
-		}
-		if err := asnDB.Close(); err != nil {
-			return fmt.Errorf("failed to close ASN database: %w", err)
-		}
-		return nil
-	}, nil
-}
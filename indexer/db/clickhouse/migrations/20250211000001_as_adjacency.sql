@@ -0,0 +1,33 @@
+-- +goose Up
+
+-- +goose StatementBegin
+-- AS adjacency derived from DZ links and the GeoIP ASN of each side's device public IP.
+-- One row per link whose endpoints both resolve to an ASN.
+CREATE OR REPLACE VIEW as_adjacency
+AS
+WITH device_asn AS (
+    SELECT
+        d.pk AS device_pk,
+        g.asn AS asn,
+        g.asn_org AS asn_org
+    FROM dz_devices_current d
+    JOIN geoip_records_current g ON g.ip = d.public_ip
+    WHERE d.public_ip != ''
+      AND g.asn != 0
+)
+SELECT
+    l.pk AS link_pk,
+    l.code AS link_code,
+    l.side_a_pk AS side_a_device_pk,
+    a.asn AS side_a_asn,
+    a.asn_org AS side_a_asn_org,
+    l.side_z_pk AS side_z_device_pk,
+    z.asn AS side_z_asn,
+    z.asn_org AS side_z_asn_org
+FROM dz_links_current l
+JOIN device_asn a ON l.side_a_pk = a.device_pk
+JOIN device_asn z ON l.side_z_pk = z.device_pk;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP VIEW IF EXISTS as_adjacency;
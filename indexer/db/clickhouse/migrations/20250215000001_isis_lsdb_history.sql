@@ -0,0 +1,25 @@
+-- +goose Up
+
+-- +goose StatementBegin
+-- IS-IS link-state database snapshots, one row per LSP neighbor (or one row with an
+-- empty neighbor_system_id for LSPs without neighbors) for each processed dump.
+-- epoch_ts is the dump timestamp, so reprocessing the same dump collapses to the
+-- highest sequence number on merge.
+CREATE TABLE IF NOT EXISTS fact_isis_lsdb_history
+(
+    epoch_ts DateTime64(3),
+    ingested_at DateTime64(3),
+    system_id String,
+    sequence_number UInt64,
+    checksum UInt32,
+    lifetime UInt32,
+    metric UInt32,
+    neighbor_system_id String
+)
+ENGINE = ReplacingMergeTree(sequence_number)
+PARTITION BY toYYYYMM(epoch_ts)
+ORDER BY (epoch_ts, system_id, neighbor_system_id);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS fact_isis_lsdb_history;
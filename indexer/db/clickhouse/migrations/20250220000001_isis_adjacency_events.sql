@@ -0,0 +1,22 @@
+-- +goose Up
+
+-- +goose StatementBegin
+-- IS-IS adjacency up/down events between devices, written by the indexer when an
+-- ISIS_ADJACENT relationship appears in or disappears from the graph between syncs.
+-- metric is the adjacency's IS-IS metric when it came up or was last seen before going down.
+CREATE TABLE IF NOT EXISTS fact_isis_adjacency_events
+(
+    event_ts DateTime64(3),
+    ingested_at DateTime64(3),
+    from_pk String,
+    to_pk String,
+    event_type LowCardinality(String),
+    metric UInt32
+)
+ENGINE = ReplacingMergeTree(ingested_at)
+PARTITION BY toYYYYMM(event_ts)
+ORDER BY (event_ts, from_pk, to_pk, event_type);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS fact_isis_adjacency_events;
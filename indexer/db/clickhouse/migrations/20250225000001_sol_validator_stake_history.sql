@@ -0,0 +1,25 @@
+-- +goose Up
+
+-- +goose StatementBegin
+-- Per-epoch stake of each Solana validator, written once per epoch by the indexer
+-- from getVoteAccounts (current and delinquent). epoch_ts is the estimated epoch start.
+-- stake_share_pct and stake_rank are relative to all vote accounts with stake that epoch.
+-- deactivating_stake_lamports is NULL when the source doesn't report it.
+CREATE TABLE IF NOT EXISTS fact_sol_validator_stake_history
+(
+    epoch Int64,
+    epoch_ts DateTime64(3),
+    ingested_at DateTime64(3),
+    vote_pubkey String,
+    node_pubkey String,
+    activated_stake_lamports Int64,
+    deactivating_stake_lamports Nullable(Int64),
+    stake_share_pct Float64,
+    stake_rank UInt32
+)
+ENGINE = ReplacingMergeTree(ingested_at)
+ORDER BY (vote_pubkey, epoch);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS fact_sol_validator_stake_history;
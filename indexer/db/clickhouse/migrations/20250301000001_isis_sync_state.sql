@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- +goose StatementBegin
+-- Last-modified time of each IS-IS dump file synced into the graph, so the
+-- indexer only reprocesses files that changed. One row per sync; the latest
+-- synced_at per file_key is the current state.
+CREATE TABLE IF NOT EXISTS isis_sync_state
+(
+    file_key String,
+    last_modified DateTime64(3),
+    synced_at DateTime64(3),
+    duration_ms UInt64
+)
+ENGINE = ReplacingMergeTree(synced_at)
+ORDER BY file_key;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS isis_sync_state;
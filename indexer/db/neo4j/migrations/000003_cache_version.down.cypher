@@ -0,0 +1 @@
+DROP CONSTRAINT cache_version_type_unique IF EXISTS;
@@ -0,0 +1 @@
+CREATE CONSTRAINT cache_version_type_unique IF NOT EXISTS FOR (n:CacheVersion) REQUIRE n.type IS UNIQUE;
@@ -0,0 +1,125 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/malbeclabs/lake/indexer/pkg/metrics"
+	"github.com/sony/gobreaker"
+)
+
+// ErrCircuitOpen is returned instead of inserting while the circuit breaker is
+// open, or half-open with its probes already in flight; the batch is dropped
+var ErrCircuitOpen = errors.New("clickhouse circuit breaker is open")
+
+// circuitBreakerTripFailures is how many consecutive failed inserts open the circuit
+const circuitBreakerTripFailures = 5
+
+// CircuitBreakerConfig configures the circuit breaker around batch inserts.
+// The circuit opens after 5 consecutive failed calls, rejects inserts for
+// Timeout, then lets MaxRequests calls through to probe ClickHouse: if they
+// all succeed it closes again, and if any fails it reopens.
+type CircuitBreakerConfig struct {
+	MaxRequests uint32        // calls let through while half-open
+	Interval    time.Duration // how often failure counts reset while closed (0 never)
+	Timeout     time.Duration // how long the circuit stays open
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "clickhouse-insert",
+		MaxRequests: cfg.MaxRequests,
+		Interval:    cfg.Interval,
+		Timeout:     cfg.Timeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= circuitBreakerTripFailures
+		},
+		// Cancellation by the caller says nothing about ClickHouse
+		IsSuccessful: func(err error) bool {
+			return err == nil || errors.Is(err, context.Canceled)
+		},
+	})
+}
+
+// execute runs fn through the breaker, counting rejected calls
+func execute(cb *gobreaker.CircuitBreaker, fn func() error) error {
+	_, err := cb.Execute(func() (any, error) {
+		return nil, fn()
+	})
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		metrics.ClickHouseCircuitBreakerOpenTotal.Inc()
+		return ErrCircuitOpen
+	}
+	return err
+}
+
+// NewCircuitBreakerClient wraps a client so batch inserts go through a
+// circuit breaker. While ClickHouse is failing inserts, new batches fail fast
+// with ErrCircuitOpen rather than piling up goroutines and buffered rows.
+// Preparing and sending a batch are each a round trip to ClickHouse, so
+// both go through the breaker.
+func NewCircuitBreakerClient(c Client, cfg CircuitBreakerConfig) Client {
+	return &circuitBreakerClient{Client: c, breaker: newCircuitBreaker(cfg)}
+}
+
+type circuitBreakerClient struct {
+	Client
+	breaker *gobreaker.CircuitBreaker
+}
+
+func (c *circuitBreakerClient) Conn(ctx context.Context) (Connection, error) {
+	conn, err := c.Client.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &circuitBreakerConnection{Connection: conn, breaker: c.breaker}, nil
+}
+
+type circuitBreakerConnection struct {
+	Connection
+	breaker *gobreaker.CircuitBreaker
+}
+
+// PrepareBatch counts the batch towards the backlog from here until it is
+// sent, aborted or closed
+func (c *circuitBreakerConnection) PrepareBatch(ctx context.Context, query string) (driver.Batch, error) {
+	var batch driver.Batch
+	err := execute(c.breaker, func() error {
+		var err error
+		batch, err = c.Connection.PrepareBatch(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.ClickHouseInsertBacklogSize.Inc()
+	return &circuitBreakerBatch{Batch: batch, breaker: c.breaker}, nil
+}
+
+type circuitBreakerBatch struct {
+	driver.Batch
+	breaker *gobreaker.CircuitBreaker
+	once    sync.Once
+}
+
+func (b *circuitBreakerBatch) finish() {
+	b.once.Do(metrics.ClickHouseInsertBacklogSize.Dec)
+}
+
+func (b *circuitBreakerBatch) Send() error {
+	defer b.finish()
+	return execute(b.breaker, b.Batch.Send)
+}
+
+func (b *circuitBreakerBatch) Abort() error {
+	b.finish()
+	return b.Batch.Abort()
+}
+
+func (b *circuitBreakerBatch) Close() error {
+	b.finish()
+	return b.Batch.Close()
+}
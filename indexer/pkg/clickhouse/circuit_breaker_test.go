@@ -0,0 +1,89 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_TripsAtFiveFailures(t *testing.T) {
+	t.Parallel()
+
+	b := newCircuitBreaker(CircuitBreakerConfig{MaxRequests: 1, Interval: time.Minute, Timeout: time.Minute})
+	errInsert := errors.New("insert failed")
+	fail := func() error { return errInsert }
+
+	for range circuitBreakerTripFailures - 1 {
+		require.ErrorIs(t, execute(b, fail), errInsert)
+	}
+	require.Equal(t, gobreaker.StateClosed, b.State(), "should still be closed after %d failures", circuitBreakerTripFailures-1)
+
+	require.ErrorIs(t, execute(b, fail), errInsert)
+	require.Equal(t, gobreaker.StateOpen, b.State())
+
+	err := execute(b, func() error {
+		t.Fatal("insert ran while the circuit was open")
+		return nil
+	})
+	require.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_IgnoresCancellation(t *testing.T) {
+	t.Parallel()
+
+	b := newCircuitBreaker(CircuitBreakerConfig{MaxRequests: 1, Interval: time.Minute, Timeout: time.Minute})
+	for range 10 {
+		require.ErrorIs(t, execute(b, func() error { return context.Canceled }), context.Canceled)
+	}
+	require.Equal(t, gobreaker.StateClosed, b.State(), "cancelled inserts must not trip the breaker")
+}
+
+// fakeConnection fails every PrepareBatch, counting the calls that reach it
+type fakeConnection struct {
+	Connection
+	prepares int
+}
+
+func (c *fakeConnection) PrepareBatch(context.Context, string) (driver.Batch, error) {
+	c.prepares++
+	return nil, errors.New("clickhouse unavailable")
+}
+
+type fakeClient struct {
+	Client
+	conn *fakeConnection
+}
+
+func (c *fakeClient) Conn(context.Context) (Connection, error) { return c.conn, nil }
+
+func TestCircuitBreakerClient_DropsBatchesWhileOpen(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	fake := &fakeClient{conn: &fakeConnection{}}
+	client := NewCircuitBreakerClient(fake, CircuitBreakerConfig{MaxRequests: 1, Interval: time.Minute, Timeout: 50 * time.Millisecond})
+	conn, err := client.Conn(ctx)
+	require.NoError(t, err)
+
+	for range circuitBreakerTripFailures {
+		_, err := conn.PrepareBatch(ctx, "INSERT INTO t")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	_, err = conn.PrepareBatch(ctx, "INSERT INTO t")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, circuitBreakerTripFailures, fake.conn.prepares, "dropped batches must not reach ClickHouse")
+
+	// After the timeout one probe goes through, and its failure reopens the circuit
+	time.Sleep(60 * time.Millisecond)
+	_, err = conn.PrepareBatch(ctx, "INSERT INTO t")
+	require.NotErrorIs(t, err, ErrCircuitOpen)
+	_, err = conn.PrepareBatch(ctx, "INSERT INTO t")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, circuitBreakerTripFailures+1, fake.conn.prepares)
+}
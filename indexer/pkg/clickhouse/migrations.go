@@ -105,7 +105,16 @@ func UpTo(ctx context.Context, log *slog.Logger, cfg MigrationConfig, version in
 
 // Down rolls back the most recent migration
 func Down(ctx context.Context, log *slog.Logger, cfg MigrationConfig) error {
-	log.Info("rolling back ClickHouse migration (down)")
+	return RollbackMigrations(ctx, log, cfg, 1)
+}
+
+// RollbackMigrations rolls back the given number of most recently applied migrations
+func RollbackMigrations(ctx context.Context, log *slog.Logger, cfg MigrationConfig, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+
+	log.Info("rolling back ClickHouse migrations (down)", "steps", steps)
 
 	db, err := newSQLDB(cfg)
 	if err != nil {
@@ -118,14 +127,94 @@ func Down(ctx context.Context, log *slog.Logger, cfg MigrationConfig) error {
 		return err
 	}
 
-	if _, err := provider.Down(ctx); err != nil {
-		return fmt.Errorf("failed to roll back migration: %w", err)
+	applied, err := appliedMigrations(ctx, provider)
+	if err != nil {
+		return err
+	}
+	if steps > len(applied) {
+		return fmt.Errorf("cannot roll back %d migrations: only %d applied", steps, len(applied))
+	}
+
+	for i := 0; i < steps; i++ {
+		r, err := provider.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration: %w", err)
+		}
+		log.Info("migration rolled back", "version", r.Source.Version, "path", r.Source.Path, "duration", r.Duration)
 	}
 
-	log.Info("ClickHouse migration rolled back successfully")
+	log.Info("ClickHouse migrations rolled back successfully", "steps", steps)
 	return nil
 }
 
+// AppliedMigration is an applied migration that a rollback would reverse
+type AppliedMigration struct {
+	Version int64
+	Path    string
+}
+
+// PlanRollback returns the migrations, newest first, that rolling back the
+// given number of steps would reverse. It does not modify the database.
+func PlanRollback(ctx context.Context, cfg MigrationConfig, steps int) ([]AppliedMigration, error) {
+	applied, err := loadAppliedMigrations(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if steps > len(applied) {
+		return nil, fmt.Errorf("cannot roll back %d migrations: only %d applied", steps, len(applied))
+	}
+	return applied[:steps], nil
+}
+
+// PlanRollbackTo returns the migrations, newest first, that rolling back to
+// the given version would reverse. It does not modify the database.
+func PlanRollbackTo(ctx context.Context, cfg MigrationConfig, version int64) ([]AppliedMigration, error) {
+	applied, err := loadAppliedMigrations(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	var plan []AppliedMigration
+	for _, m := range applied {
+		if m.Version > version {
+			plan = append(plan, m)
+		}
+	}
+	return plan, nil
+}
+
+func loadAppliedMigrations(ctx context.Context, cfg MigrationConfig) ([]AppliedMigration, error) {
+	db, err := newSQLDB(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database connection for migrations: %w", err)
+	}
+	defer db.Close()
+
+	provider, err := newProvider(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return appliedMigrations(ctx, provider)
+}
+
+// appliedMigrations returns all applied migrations, newest first
+func appliedMigrations(ctx context.Context, provider *goose.Provider) ([]AppliedMigration, error) {
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	var applied []AppliedMigration
+	for i := len(statuses) - 1; i >= 0; i-- {
+		s := statuses[i]
+		if s.State != goose.StateApplied {
+			continue
+		}
+		applied = append(applied, AppliedMigration{Version: s.Source.Version, Path: s.Source.Path})
+	}
+	return applied, nil
+}
+
 // DownTo rolls back migrations to a specific version
 func DownTo(ctx context.Context, log *slog.Logger, cfg MigrationConfig, version int64) error {
 	log.Info("rolling back ClickHouse migrations to version", "version", version)
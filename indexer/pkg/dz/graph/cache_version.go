@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+)
+
+// TopologyCacheVersionType is the CacheVersion node type tracking the IS-IS
+// topology. API caches derived from the topology compare its hash to skip
+// recomputing when nothing has changed.
+const TopologyCacheVersionType = "isis_topology"
+
+// cypherRunner is satisfied by both sessions and transactions.
+type cypherRunner interface {
+	Run(ctx context.Context, cypher string, params map[string]any) (neo4j.Result, error)
+}
+
+// updateTopologyVersion hashes the current IS-IS topology and stores it on the
+// (:CacheVersion {type: "isis_topology"}) node. updatedAt only moves when the
+// hash changes.
+func updateTopologyVersion(ctx context.Context, run cypherRunner) (string, error) {
+	res, err := run.Run(ctx, `
+		MATCH (a:Device)-[r:ISIS_ADJACENT]->(b:Device)
+		OPTIONAL MATCH (a)-[:LOCATED_IN]->(ma:Metro)
+		OPTIONAL MATCH (b)-[:LOCATED_IN]->(mb:Metro)
+		RETURN a.pk AS fromPK, b.pk AS toPK,
+		       coalesce(ma.pk, '') AS fromMetroPK, coalesce(mb.pk, '') AS toMetroPK,
+		       r.metric AS metric, r.bandwidth_bps AS bandwidth
+	`, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to query topology: %w", err)
+	}
+	records, err := res.Collect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to collect topology: %w", err)
+	}
+
+	edges := make([]string, 0, len(records))
+	for _, record := range records {
+		values := make([]string, 0, 6)
+		for _, key := range []string{"fromPK", "fromMetroPK", "toPK", "toMetroPK", "metric", "bandwidth"} {
+			v, _ := record.Get(key)
+			values = append(values, fmt.Sprint(v))
+		}
+		edges = append(edges, strings.Join(values, "|"))
+	}
+	hash := topologyHash(edges)
+
+	res, err = run.Run(ctx, `
+		MERGE (v:CacheVersion {type: $type})
+		ON CREATE SET v.hash = $hash, v.updatedAt = datetime()
+		ON MATCH SET v.updatedAt = CASE WHEN v.hash = $hash THEN v.updatedAt ELSE datetime() END,
+		             v.hash = $hash
+	`, map[string]any{"type": TopologyCacheVersionType, "hash": hash})
+	if err != nil {
+		return "", fmt.Errorf("failed to update cache version: %w", err)
+	}
+	if _, err := res.Consume(ctx); err != nil {
+		return "", fmt.Errorf("failed to consume cache version result: %w", err)
+	}
+	return hash, nil
+}
+
+// topologyHash returns a stable hash of a set of edge descriptions, independent
+// of their order.
+func topologyHash(edges []string) string {
+	sorted := append([]string(nil), edges...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, e := range sorted {
+		h.Write([]byte(e))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/malbeclabs/lake/indexer/pkg/dz/isis"
+	dzsvc "github.com/malbeclabs/lake/indexer/pkg/dz/serviceability"
+	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
+	laketesting "github.com/malbeclabs/lake/utils/pkg/testing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologyHash_OrderIndependent(t *testing.T) {
+	a := topologyHash([]string{"d1|m1|d2|m2|1000|10", "d2|m2|d1|m1|1000|10"})
+	b := topologyHash([]string{"d2|m2|d1|m1|1000|10", "d1|m1|d2|m2|1000|10"})
+	require.Equal(t, a, b)
+
+	c := topologyHash([]string{"d1|m1|d2|m2|2000|10", "d2|m2|d1|m1|1000|10"})
+	require.NotEqual(t, a, c)
+}
+
+func readTopologyVersion(t *testing.T, neo4jClient neo4j.Client) (string, any) {
+	t.Helper()
+	ctx := t.Context()
+	session, err := neo4jClient.Session(ctx)
+	require.NoError(t, err)
+	defer session.Close(ctx)
+
+	res, err := session.Run(ctx, "MATCH (v:CacheVersion {type: $type}) RETURN v.hash AS hash, v.updatedAt AS updatedAt",
+		map[string]any{"type": TopologyCacheVersionType})
+	require.NoError(t, err)
+	record, err := res.Single(ctx)
+	require.NoError(t, err)
+	hash, _ := record.Get("hash")
+	updatedAt, _ := record.Get("updatedAt")
+	return hash.(string), updatedAt
+}
+
+func TestStore_SyncWithISIS_CacheVersion(t *testing.T) {
+	chClient := testClickHouseClient(t)
+	neo4jClient := testNeo4jClient(t)
+	log := laketesting.NewLogger()
+	ctx := t.Context()
+
+	clearTestData(t, chClient)
+
+	store, err := dzsvc.NewStore(dzsvc.StoreConfig{
+		Logger:     log,
+		ClickHouse: chClient,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.ReplaceContributors(ctx, []dzsvc.Contributor{
+		{PK: "contrib1", Code: "test1", Name: "Test Contributor 1"},
+	}))
+	require.NoError(t, store.ReplaceMetros(ctx, []dzsvc.Metro{
+		{PK: "metro1", Code: "NYC", Name: "New York", Longitude: -74.006, Latitude: 40.7128},
+		{PK: "metro2", Code: "DC", Name: "Washington DC", Longitude: -77.0369, Latitude: 38.9072},
+	}))
+	require.NoError(t, store.ReplaceDevices(ctx, []dzsvc.Device{
+		{PK: "device1", Status: "active", DeviceType: "router", Code: "DZ-NY7-SW01", PublicIP: "1.2.3.4", ContributorPK: "contrib1", MetroPK: "metro1", MaxUsers: 100},
+		{PK: "device2", Status: "active", DeviceType: "router", Code: "DZ-DC1-SW01", PublicIP: "1.2.3.5", ContributorPK: "contrib1", MetroPK: "metro2", MaxUsers: 100},
+	}))
+	require.NoError(t, store.ReplaceLinks(ctx, []dzsvc.Link{
+		{PK: "link1", Status: "active", Code: "link1", TunnelNet: "172.16.0.116/31", ContributorPK: "contrib1", SideAPK: "device1", SideZPK: "device2", SideAIfaceName: "eth0", SideZIfaceName: "eth0", LinkType: "direct", CommittedRTTNs: 1000000, CommittedJitterNs: 100000, Bandwidth: 10000000000},
+	}))
+
+	graphStore, err := NewStore(StoreConfig{
+		Logger:     log,
+		Neo4j:      neo4jClient,
+		ClickHouse: chClient,
+	})
+	require.NoError(t, err)
+
+	lspsWithMetric := func(metric uint32) []isis.LSP {
+		return []isis.LSP{{
+			SystemID: "ac10.0001.0000.00-00",
+			Hostname: "DZ-NY7-SW01",
+			RouterID: "172.16.0.1",
+			Neighbors: []isis.Neighbor{{
+				SystemID:     "ac10.0002.0000",
+				Metric:       metric,
+				NeighborAddr: "172.16.0.117",
+			}},
+		}}
+	}
+
+	require.NoError(t, graphStore.SyncWithISIS(ctx, lspsWithMetric(1000)))
+	hash1, updatedAt1 := readTopologyVersion(t, neo4jClient)
+	require.NotEmpty(t, hash1)
+
+	// Re-syncing an unchanged topology keeps the hash and updatedAt
+	require.NoError(t, graphStore.SyncWithISIS(ctx, lspsWithMetric(1000)))
+	hash2, updatedAt2 := readTopologyVersion(t, neo4jClient)
+	require.Equal(t, hash1, hash2)
+	require.Equal(t, updatedAt1, updatedAt2)
+
+	// A metric change produces a new hash
+	require.NoError(t, graphStore.SyncWithISIS(ctx, lspsWithMetric(2000)))
+	hash3, _ := readTopologyVersion(t, neo4jClient)
+	require.NotEqual(t, hash1, hash3)
+}
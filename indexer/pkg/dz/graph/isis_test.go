@@ -72,12 +72,16 @@ func TestStore_SyncISIS(t *testing.T) {
 			SystemID: "ac10.0001.0000.00-00",
 			Hostname: "DZ-NY7-SW01",
 			RouterID: "172.16.0.1",
+			PrefixSIDs: []isis.PrefixSID{
+				{Prefix: "172.16.0.1/32", SID: 1, Flags: []string{"N"}},
+			},
 			Neighbors: []isis.Neighbor{
 				{
 					SystemID:     "ac10.0002.0000",
 					Metric:       1000, // 1000 microseconds = 1ms
 					NeighborAddr: "172.16.0.117",
 					AdjSIDs:      []uint32{100001, 100002},
+					BFD:          &isis.BFDSession{State: "UP", MinTxUs: 300000, MinRxUs: 300000, Multiplier: 3},
 				},
 			},
 		},
@@ -111,6 +115,16 @@ func TestStore_SyncISIS(t *testing.T) {
 	require.Equal(t, "ac10.0001.0000.00-00", systemID, "expected ISIS system_id")
 	require.Equal(t, "172.16.0.1", routerID, "expected ISIS router_id")
 
+	// Check that the node SID was stored on the Device
+	res, err = session.Run(ctx, "MATCH (d:Device {pk: 'device1'}) RETURN d.prefix_sid AS sid, d.prefix_sid_flags AS flags", nil)
+	require.NoError(t, err)
+	record, err = res.Single(ctx)
+	require.NoError(t, err)
+	prefixSID, _ := record.Get("sid")
+	prefixSIDFlags, _ := record.Get("flags")
+	require.Equal(t, int64(1), prefixSID, "expected prefix_sid")
+	require.Equal(t, []any{"N"}, prefixSIDFlags, "expected prefix_sid_flags")
+
 	// Check that ISIS_ADJACENT relationship was created
 	res, err = session.Run(ctx, "MATCH (d1:Device {pk: 'device1'})-[r:ISIS_ADJACENT]->(d2:Device {pk: 'device2'}) RETURN r.metric AS metric, r.neighbor_addr AS neighbor_addr", nil)
 	require.NoError(t, err)
@@ -120,6 +134,20 @@ func TestStore_SyncISIS(t *testing.T) {
 	neighborAddr, _ := record.Get("neighbor_addr")
 	require.Equal(t, int64(1000), metric, "expected ISIS_ADJACENT metric to be 1000")
 	require.Equal(t, "172.16.0.117", neighborAddr, "expected neighbor_addr to be 172.16.0.117")
+
+	// Check that BFD session state was stored on the adjacency
+	res, err = session.Run(ctx, "MATCH (:Device {pk: 'device1'})-[r:ISIS_ADJACENT]->(:Device {pk: 'device2'}) RETURN r.bfd_state AS state, r.bfd_min_tx_us AS min_tx, r.bfd_min_rx_us AS min_rx, r.bfd_multiplier AS multiplier", nil)
+	require.NoError(t, err)
+	record, err = res.Single(ctx)
+	require.NoError(t, err)
+	bfdState, _ := record.Get("state")
+	minTx, _ := record.Get("min_tx")
+	minRx, _ := record.Get("min_rx")
+	multiplier, _ := record.Get("multiplier")
+	require.Equal(t, "UP", bfdState)
+	require.Equal(t, int64(300000), minTx)
+	require.Equal(t, int64(300000), minRx)
+	require.Equal(t, int64(3), multiplier)
 }
 
 func TestStore_SyncISIS_NoMatchingLink(t *testing.T) {
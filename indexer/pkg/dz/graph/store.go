@@ -99,8 +99,9 @@ func (s *Store) Sync(ctx context.Context) error {
 
 	// Perform atomic sync within a single write transaction
 	_, err = session.ExecuteWrite(ctx, func(tx neo4j.Transaction) (any, error) {
-		// Delete all existing nodes and relationships
-		res, err := tx.Run(ctx, "MATCH (n) DETACH DELETE n", nil)
+		// Delete all existing nodes and relationships, keeping the cache
+		// version so an unchanged topology keeps its hash and updatedAt
+		res, err := tx.Run(ctx, "MATCH (n) WHERE NOT n:CacheVersion DETACH DELETE n", nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to clear graph: %w", err)
 		}
@@ -129,6 +130,10 @@ func (s *Store) Sync(ctx context.Context) error {
 			return nil, fmt.Errorf("failed to create users: %w", err)
 		}
 
+		if _, err := updateTopologyVersion(ctx, tx); err != nil {
+			return nil, err
+		}
+
 		return nil, nil
 	})
 	if err != nil {
@@ -191,8 +196,9 @@ func (s *Store) SyncWithISIS(ctx context.Context, lsps []isis.LSP) error {
 
 	// Perform atomic sync within a single write transaction
 	_, err = session.ExecuteWrite(ctx, func(tx neo4j.Transaction) (any, error) {
-		// Delete all existing nodes and relationships
-		res, err := tx.Run(ctx, "MATCH (n) DETACH DELETE n", nil)
+		// Delete all existing nodes and relationships, keeping the cache
+		// version so an unchanged topology keeps its hash and updatedAt
+		res, err := tx.Run(ctx, "MATCH (n) WHERE NOT n:CacheVersion DETACH DELETE n", nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to clear graph: %w", err)
 		}
@@ -228,6 +234,10 @@ func (s *Store) SyncWithISIS(ctx context.Context, lsps []isis.LSP) error {
 			}
 		}
 
+		if _, err := updateTopologyVersion(ctx, tx); err != nil {
+			return nil, err
+		}
+
 		return nil, nil
 	})
 	if err != nil {
@@ -397,19 +407,38 @@ func updateLinkISISInTx(ctx context.Context, tx neo4j.Transaction, linkPK string
 	return err
 }
 
+// nodeSIDParams returns the Cypher parameters for a device's node SID. Both are
+// nil when the router advertises no prefix SIDs, which removes the properties.
+func nodeSIDParams(lsp isis.LSP) (any, any) {
+	sid := lsp.NodeSID()
+	if sid == nil {
+		return nil, nil
+	}
+	flags := sid.Flags
+	if flags == nil {
+		flags = []string{}
+	}
+	return int64(sid.SID), flags
+}
+
 // updateDeviceISISInTx updates a Device node with IS-IS properties within a transaction.
 func updateDeviceISISInTx(ctx context.Context, tx neo4j.Transaction, devicePK string, lsp isis.LSP, timestamp time.Time) error {
 	cypher := `
 		MATCH (d:Device {pk: $pk})
 		SET d.isis_system_id = $system_id,
 		    d.isis_router_id = $router_id,
+		    d.prefix_sid = $prefix_sid,
+		    d.prefix_sid_flags = $prefix_sid_flags,
 		    d.isis_last_sync = $last_sync
 	`
+	prefixSID, prefixSIDFlags := nodeSIDParams(lsp)
 	res, err := tx.Run(ctx, cypher, map[string]any{
-		"pk":        devicePK,
-		"system_id": lsp.SystemID,
-		"router_id": lsp.RouterID,
-		"last_sync": timestamp.Unix(),
+		"pk":               devicePK,
+		"system_id":        lsp.SystemID,
+		"router_id":        lsp.RouterID,
+		"prefix_sid":       prefixSID,
+		"prefix_sid_flags": prefixSIDFlags,
+		"last_sync":        timestamp.Unix(),
 	})
 	if err != nil {
 		return err
@@ -428,17 +457,13 @@ func createISISAdjacentInTx(ctx context.Context, tx neo4j.Transaction, fromPK, t
 		    r.neighbor_addr = $neighbor_addr,
 		    r.adj_sids = $adj_sids,
 		    r.last_seen = $last_seen,
-		    r.bandwidth_bps = $bandwidth_bps
+		    r.bandwidth_bps = $bandwidth_bps,
+		    r.bfd_state = $bfd_state,
+		    r.bfd_min_tx_us = $bfd_min_tx_us,
+		    r.bfd_min_rx_us = $bfd_min_rx_us,
+		    r.bfd_multiplier = $bfd_multiplier
 	`
-	res, err := tx.Run(ctx, cypher, map[string]any{
-		"from_pk":       fromPK,
-		"to_pk":         toPK,
-		"metric":        neighbor.Metric,
-		"neighbor_addr": neighbor.NeighborAddr,
-		"adj_sids":      neighbor.AdjSIDs,
-		"last_seen":     timestamp.Unix(),
-		"bandwidth_bps": bandwidth,
-	})
+	res, err := tx.Run(ctx, cypher, isisAdjacentParams(fromPK, toPK, neighbor, bandwidth, timestamp))
 	if err != nil {
 		return err
 	}
@@ -809,6 +834,10 @@ func (s *Store) SyncISIS(ctx context.Context, lsps []isis.LSP) error {
 		}
 	}
 
+	if _, err := updateTopologyVersion(ctx, session); err != nil {
+		return err
+	}
+
 	s.log.Info("graph: ISIS sync completed",
 		"lsps", len(lsps),
 		"adjacencies_created", adjacenciesCreated,
@@ -918,13 +947,18 @@ func (s *Store) updateDeviceISIS(ctx context.Context, session neo4j.Session, dev
 		MATCH (d:Device {pk: $pk})
 		SET d.isis_system_id = $system_id,
 		    d.isis_router_id = $router_id,
+		    d.prefix_sid = $prefix_sid,
+		    d.prefix_sid_flags = $prefix_sid_flags,
 		    d.isis_last_sync = $last_sync
 	`
+	prefixSID, prefixSIDFlags := nodeSIDParams(lsp)
 	res, err := session.Run(ctx, cypher, map[string]any{
-		"pk":        devicePK,
-		"system_id": lsp.SystemID,
-		"router_id": lsp.RouterID,
-		"last_sync": timestamp.Unix(),
+		"pk":               devicePK,
+		"system_id":        lsp.SystemID,
+		"router_id":        lsp.RouterID,
+		"prefix_sid":       prefixSID,
+		"prefix_sid_flags": prefixSIDFlags,
+		"last_sync":        timestamp.Unix(),
 	})
 	if err != nil {
 		return err
@@ -943,17 +977,13 @@ func (s *Store) createISISAdjacent(ctx context.Context, session neo4j.Session, f
 		    r.neighbor_addr = $neighbor_addr,
 		    r.adj_sids = $adj_sids,
 		    r.last_seen = $last_seen,
-		    r.bandwidth_bps = $bandwidth_bps
+		    r.bandwidth_bps = $bandwidth_bps,
+		    r.bfd_state = $bfd_state,
+		    r.bfd_min_tx_us = $bfd_min_tx_us,
+		    r.bfd_min_rx_us = $bfd_min_rx_us,
+		    r.bfd_multiplier = $bfd_multiplier
 	`
-	res, err := session.Run(ctx, cypher, map[string]any{
-		"from_pk":       fromPK,
-		"to_pk":         toPK,
-		"metric":        neighbor.Metric,
-		"neighbor_addr": neighbor.NeighborAddr,
-		"adj_sids":      neighbor.AdjSIDs,
-		"last_seen":     timestamp.Unix(),
-		"bandwidth_bps": bandwidth,
-	})
+	res, err := session.Run(ctx, cypher, isisAdjacentParams(fromPK, toPK, neighbor, bandwidth, timestamp))
 	if err != nil {
 		return err
 	}
@@ -961,6 +991,32 @@ func (s *Store) createISISAdjacent(ctx context.Context, session neo4j.Session, f
 	return err
 }
 
+// isisAdjacentParams returns the parameters for creating an ISIS_ADJACENT
+// relationship. BFD properties are null, removing them, when the adjacency has
+// no BFD session.
+func isisAdjacentParams(fromPK, toPK string, neighbor isis.Neighbor, bandwidth int64, timestamp time.Time) map[string]any {
+	params := map[string]any{
+		"from_pk":        fromPK,
+		"to_pk":          toPK,
+		"metric":         neighbor.Metric,
+		"neighbor_addr":  neighbor.NeighborAddr,
+		"adj_sids":       neighbor.AdjSIDs,
+		"last_seen":      timestamp.Unix(),
+		"bandwidth_bps":  bandwidth,
+		"bfd_state":      nil,
+		"bfd_min_tx_us":  nil,
+		"bfd_min_rx_us":  nil,
+		"bfd_multiplier": nil,
+	}
+	if bfd := neighbor.BFD; bfd != nil {
+		params["bfd_state"] = bfd.State
+		params["bfd_min_tx_us"] = bfd.MinTxUs
+		params["bfd_min_rx_us"] = bfd.MinRxUs
+		params["bfd_multiplier"] = bfd.Multiplier
+	}
+	return params
+}
+
 // parseTunnelNet31 parses a /31 CIDR and returns both IP addresses.
 func parseTunnelNet31(cidr string) (string, string, error) {
 	_, ipnet, err := net.ParseCIDR(cidr)
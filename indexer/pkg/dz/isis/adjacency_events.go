@@ -0,0 +1,189 @@
+package isis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/indexer/pkg/clickhouse"
+)
+
+// Adjacency event types written to fact_isis_adjacency_events.
+const (
+	AdjacencyEventUp   = "up"
+	AdjacencyEventDown = "down"
+)
+
+// Adjacency is a directed IS-IS adjacency between two devices.
+type Adjacency struct {
+	FromPK string
+	ToPK   string
+	Metric uint32
+}
+
+// AdjacencyEvent is an adjacency coming up or going down.
+type AdjacencyEvent struct {
+	FromPK    string
+	ToPK      string
+	EventType string // AdjacencyEventUp or AdjacencyEventDown
+	Metric    uint32
+}
+
+type adjacencyKey struct {
+	fromPK string
+	toPK   string
+}
+
+// DiffAdjacencies returns an up event for each adjacency in curr but not prev,
+// and a down event (carrying the last known metric) for each in prev but not
+// curr, ordered by from and to device PK.
+func DiffAdjacencies(prev, curr []Adjacency) []AdjacencyEvent {
+	prevByKey := adjacenciesByKey(prev)
+	currByKey := adjacenciesByKey(curr)
+
+	var events []AdjacencyEvent
+	for key, adj := range currByKey {
+		if _, ok := prevByKey[key]; !ok {
+			events = append(events, AdjacencyEvent{FromPK: key.fromPK, ToPK: key.toPK, EventType: AdjacencyEventUp, Metric: adj.Metric})
+		}
+	}
+	for key, adj := range prevByKey {
+		if _, ok := currByKey[key]; !ok {
+			events = append(events, AdjacencyEvent{FromPK: key.fromPK, ToPK: key.toPK, EventType: AdjacencyEventDown, Metric: adj.Metric})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].FromPK != events[j].FromPK {
+			return events[i].FromPK < events[j].FromPK
+		}
+		return events[i].ToPK < events[j].ToPK
+	})
+	return events
+}
+
+func adjacenciesByKey(adjs []Adjacency) map[adjacencyKey]Adjacency {
+	m := make(map[adjacencyKey]Adjacency, len(adjs))
+	for _, adj := range adjs {
+		m[adjacencyKey{fromPK: adj.FromPK, toPK: adj.ToPK}] = adj
+	}
+	return m
+}
+
+// AdjacencyEventWriterConfig configures the adjacency event writer.
+type AdjacencyEventWriterConfig struct {
+	Logger     *slog.Logger
+	ClickHouse clickhouse.Client
+}
+
+func (cfg *AdjacencyEventWriterConfig) Validate() error {
+	if cfg.Logger == nil {
+		return errors.New("logger is required")
+	}
+	if cfg.ClickHouse == nil {
+		return errors.New("clickhouse connection is required")
+	}
+	return nil
+}
+
+// AdjacencyEventWriter records IS-IS adjacency up/down events in
+// fact_isis_adjacency_events by diffing each synced adjacency set against the
+// previous one.
+type AdjacencyEventWriter struct {
+	log *slog.Logger
+	cfg AdjacencyEventWriterConfig
+
+	mu     sync.Mutex
+	loaded bool
+	prev   []Adjacency
+}
+
+// NewAdjacencyEventWriter creates a new adjacency event writer.
+func NewAdjacencyEventWriter(cfg AdjacencyEventWriterConfig) (*AdjacencyEventWriter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &AdjacencyEventWriter{
+		log: cfg.Logger,
+		cfg: cfg,
+	}, nil
+}
+
+// Write records the changes between the previous adjacency set and adjs at
+// eventTS. On the first call the previous set is rebuilt from the latest event
+// per adjacency, so a restart doesn't report every adjacency as coming up.
+func (w *AdjacencyEventWriter) Write(ctx context.Context, eventTS time.Time, adjs []Adjacency) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	conn, err := w.cfg.ClickHouse.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get ClickHouse connection: %w", err)
+	}
+
+	if !w.loaded {
+		prev, err := loadUpAdjacencies(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("failed to load adjacency state: %w", err)
+		}
+		w.prev = prev
+		w.loaded = true
+	}
+
+	events := DiffAdjacencies(w.prev, adjs)
+	if len(events) == 0 {
+		return nil
+	}
+
+	batch, err := conn.PrepareBatch(ctx, `INSERT INTO fact_isis_adjacency_events
+		(event_ts, ingested_at, from_pk, to_pk, event_type, metric)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	ingestedAt := time.Now().UTC()
+	for _, e := range events {
+		if err := batch.Append(eventTS.UTC(), ingestedAt, e.FromPK, e.ToPK, e.EventType, e.Metric); err != nil {
+			batch.Close()
+			return fmt.Errorf("failed to append adjacency event %s -> %s: %w", e.FromPK, e.ToPK, err)
+		}
+	}
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("failed to send batch: %w", err)
+	}
+
+	w.prev = adjs
+	w.log.Debug("isis_adjacency: wrote events", "events", len(events), "adjacencies", len(adjs))
+	return nil
+}
+
+// loadUpAdjacencies returns the adjacencies whose most recent event is up.
+func loadUpAdjacencies(ctx context.Context, conn clickhouse.Connection) ([]Adjacency, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT from_pk, to_pk, metric
+		FROM (
+			SELECT from_pk, to_pk,
+			       argMax(event_type, event_ts) AS last_event,
+			       argMax(metric, event_ts) AS metric
+			FROM fact_isis_adjacency_events
+			GROUP BY from_pk, to_pk
+		)
+		WHERE last_event = ?
+	`, AdjacencyEventUp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjs []Adjacency
+	for rows.Next() {
+		var adj Adjacency
+		if err := rows.Scan(&adj.FromPK, &adj.ToPK, &adj.Metric); err != nil {
+			return nil, err
+		}
+		adjs = append(adjs, adj)
+	}
+	return adjs, rows.Err()
+}
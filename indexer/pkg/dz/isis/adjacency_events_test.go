@@ -0,0 +1,101 @@
+package isis
+
+import (
+	"testing"
+	"time"
+
+	laketesting "github.com/malbeclabs/lake/utils/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAdjacencies(t *testing.T) {
+	t.Parallel()
+
+	prev := []Adjacency{
+		{FromPK: "chi1", ToPK: "nyc1", Metric: 1000},
+		{FromPK: "nyc1", ToPK: "chi1", Metric: 1000},
+		{FromPK: "nyc1", ToPK: "lon1", Metric: 7000},
+	}
+	curr := []Adjacency{
+		{FromPK: "chi1", ToPK: "nyc1", Metric: 1200}, // metric change alone isn't an event
+		{FromPK: "nyc1", ToPK: "chi1", Metric: 1200},
+		{FromPK: "lon1", ToPK: "fra1", Metric: 900},
+	}
+
+	assert.Equal(t, []AdjacencyEvent{
+		{FromPK: "lon1", ToPK: "fra1", EventType: AdjacencyEventUp, Metric: 900},
+		{FromPK: "nyc1", ToPK: "lon1", EventType: AdjacencyEventDown, Metric: 7000},
+	}, DiffAdjacencies(prev, curr))
+
+	assert.Empty(t, DiffAdjacencies(curr, curr))
+	assert.Len(t, DiffAdjacencies(nil, curr), 3)
+}
+
+func TestNewAdjacencyEventWriter(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewAdjacencyEventWriter(AdjacencyEventWriterConfig{})
+	require.ErrorContains(t, err, "logger is required")
+
+	_, err = NewAdjacencyEventWriter(AdjacencyEventWriterConfig{Logger: laketesting.NewLogger()})
+	require.ErrorContains(t, err, "clickhouse connection is required")
+}
+
+func TestAdjacencyEventWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t)
+	ctx := t.Context()
+	t0 := time.Date(2025, 2, 20, 12, 0, 0, 0, time.UTC)
+
+	writer, err := NewAdjacencyEventWriter(AdjacencyEventWriterConfig{Logger: laketesting.NewLogger(), ClickHouse: client})
+	require.NoError(t, err)
+
+	both := []Adjacency{
+		{FromPK: "chi1", ToPK: "nyc1", Metric: 1000},
+		{FromPK: "nyc1", ToPK: "lon1", Metric: 7000},
+	}
+	require.NoError(t, writer.Write(ctx, t0, both))
+	// Unchanged adjacencies write nothing
+	require.NoError(t, writer.Write(ctx, t0.Add(time.Minute), both))
+	require.NoError(t, writer.Write(ctx, t0.Add(2*time.Minute), both[:1]))
+
+	// A restarted writer picks up where the last one left off
+	restarted, err := NewAdjacencyEventWriter(AdjacencyEventWriterConfig{Logger: laketesting.NewLogger(), ClickHouse: client})
+	require.NoError(t, err)
+	require.NoError(t, restarted.Write(ctx, t0.Add(3*time.Minute), both))
+
+	conn, err := client.Conn(ctx)
+	require.NoError(t, err)
+	rows, err := conn.Query(ctx, `
+		SELECT event_ts, from_pk, to_pk, event_type, metric
+		FROM fact_isis_adjacency_events FINAL
+		ORDER BY event_ts, from_pk, to_pk
+	`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	type row struct {
+		EventTS   time.Time
+		FromPK    string
+		ToPK      string
+		EventType string
+		Metric    uint32
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		require.NoError(t, rows.Scan(&r.EventTS, &r.FromPK, &r.ToPK, &r.EventType, &r.Metric))
+		r.EventTS = r.EventTS.UTC()
+		got = append(got, r)
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, []row{
+		{t0, "chi1", "nyc1", AdjacencyEventUp, 1000},
+		{t0, "nyc1", "lon1", AdjacencyEventUp, 7000},
+		{t0.Add(2 * time.Minute), "nyc1", "lon1", AdjacencyEventDown, 7000},
+		{t0.Add(3 * time.Minute), "nyc1", "lon1", AdjacencyEventUp, 7000},
+	}, got)
+}
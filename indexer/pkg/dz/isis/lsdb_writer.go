@@ -0,0 +1,128 @@
+package isis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/malbeclabs/lake/indexer/pkg/clickhouse"
+)
+
+// dumpTimestampLayout is the timestamp prefix of IS-IS dump file names,
+// e.g. "2025-01-17T12-30-00Z_upload_data.json".
+const dumpTimestampLayout = "2006-01-02T15-04-05Z"
+
+// LSDBWriterConfig configures the LSDB writer.
+type LSDBWriterConfig struct {
+	Logger     *slog.Logger
+	ClickHouse clickhouse.Client
+}
+
+func (cfg *LSDBWriterConfig) Validate() error {
+	if cfg.Logger == nil {
+		return errors.New("logger is required")
+	}
+	if cfg.ClickHouse == nil {
+		return errors.New("clickhouse connection is required")
+	}
+	return nil
+}
+
+// LSDBWriter writes IS-IS link-state database snapshots to fact_isis_lsdb_history,
+// so historical topology can be queried without replaying the S3 archive.
+type LSDBWriter struct {
+	log *slog.Logger
+	cfg LSDBWriterConfig
+
+	mu        sync.Mutex
+	lastEpoch map[string]time.Time // by file key prefix
+}
+
+// NewLSDBWriter creates a new LSDB writer.
+func NewLSDBWriter(cfg LSDBWriterConfig) (*LSDBWriter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &LSDBWriter{
+		log:       cfg.Logger,
+		cfg:       cfg,
+		lastEpoch: make(map[string]time.Time),
+	}, nil
+}
+
+// DumpEpoch returns the time a dump was taken, parsed from its file name
+// timestamp prefix. Falls back to FetchedAt if the name has no timestamp.
+func DumpEpoch(dump *Dump) time.Time {
+	name := path.Base(dump.FileName)
+	if prefix, _, ok := strings.Cut(name, "_"); ok {
+		if ts, err := time.Parse(dumpTimestampLayout, prefix); err == nil {
+			return ts.UTC()
+		}
+	}
+	return dump.FetchedAt.UTC()
+}
+
+// Write inserts one row per LSP neighbor for the dump, plus a row with an empty
+// neighbor_system_id for LSPs without neighbors. A dump whose epoch was already
+// written by this writer for the same key prefix is skipped; rewrites of the
+// same epoch (e.g. after a restart) are deduplicated by the table's
+// ReplacingMergeTree engine.
+func (w *LSDBWriter) Write(ctx context.Context, dump *Dump, lsps []LSP) error {
+	epoch := DumpEpoch(dump)
+	prefix := ObjectPrefix(dump.FileName)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if epoch.Equal(w.lastEpoch[prefix]) {
+		w.log.Debug("isis_lsdb: dump already written, skipping", "file", dump.FileName, "epoch", epoch)
+		return nil
+	}
+
+	conn, err := w.cfg.ClickHouse.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get ClickHouse connection: %w", err)
+	}
+
+	batch, err := conn.PrepareBatch(ctx, `INSERT INTO fact_isis_lsdb_history
+		(epoch_ts, ingested_at, system_id, sequence_number, checksum, lifetime, metric, neighbor_system_id)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+
+	ingestedAt := time.Now().UTC()
+	rows := 0
+	for _, lsp := range lsps {
+		if len(lsp.Neighbors) == 0 {
+			if err := batch.Append(epoch, ingestedAt, lsp.SystemID, lsp.SequenceNumber, lsp.Checksum, lsp.RemainingLifetime, uint32(0), ""); err != nil {
+				batch.Close()
+				return fmt.Errorf("failed to append LSP %s: %w", lsp.SystemID, err)
+			}
+			rows++
+			continue
+		}
+		for _, n := range lsp.Neighbors {
+			if err := batch.Append(epoch, ingestedAt, lsp.SystemID, lsp.SequenceNumber, lsp.Checksum, lsp.RemainingLifetime, n.Metric, n.SystemID); err != nil {
+				batch.Close()
+				return fmt.Errorf("failed to append LSP %s neighbor %s: %w", lsp.SystemID, n.SystemID, err)
+			}
+			rows++
+		}
+	}
+
+	if rows == 0 {
+		batch.Close()
+		return nil
+	}
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("failed to send batch: %w", err)
+	}
+
+	w.lastEpoch[prefix] = epoch
+	w.log.Debug("isis_lsdb: wrote snapshot", "file", dump.FileName, "epoch", epoch, "lsps", len(lsps), "rows", rows)
+	return nil
+}
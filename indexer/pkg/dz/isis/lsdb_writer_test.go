@@ -0,0 +1,139 @@
+package isis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	laketesting "github.com/malbeclabs/lake/utils/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLSDBWriter(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewLSDBWriter(LSDBWriterConfig{})
+	require.ErrorContains(t, err, "logger is required")
+
+	_, err = NewLSDBWriter(LSDBWriterConfig{Logger: laketesting.NewLogger()})
+	require.ErrorContains(t, err, "clickhouse connection is required")
+}
+
+func TestDumpEpoch(t *testing.T) {
+	t.Parallel()
+
+	fetchedAt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	epoch := DumpEpoch(&Dump{FileName: "2025-01-17T12-30-00Z_upload_data.json", FetchedAt: fetchedAt})
+	assert.Equal(t, time.Date(2025, 1, 17, 12, 30, 0, 0, time.UTC), epoch)
+
+	epoch = DumpEpoch(&Dump{FileName: "dumps/2025-01-17T12-30-00Z_upload_data.json", FetchedAt: fetchedAt})
+	assert.Equal(t, time.Date(2025, 1, 17, 12, 30, 0, 0, time.UTC), epoch)
+
+	epoch = DumpEpoch(&Dump{FileName: "latest.json", FetchedAt: fetchedAt})
+	assert.Equal(t, fetchedAt, epoch)
+}
+
+func TestLSDBWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t)
+	writer, err := NewLSDBWriter(LSDBWriterConfig{Logger: laketesting.NewLogger(), ClickHouse: client})
+	require.NoError(t, err)
+
+	lsps := []LSP{
+		{
+			SystemID:          "ac10.0001.0000.00-00",
+			SequenceNumber:    42,
+			Checksum:          0xbeef,
+			RemainingLifetime: 1100,
+			Neighbors: []Neighbor{
+				{SystemID: "ac10.0002.0000", Metric: 1000},
+				{SystemID: "ac10.0003.0000", Metric: 2000},
+			},
+		},
+		{SystemID: "ac10.0004.0000.00-00", SequenceNumber: 7, RemainingLifetime: 900},
+	}
+	dump := &Dump{FileName: "2025-01-17T12-30-00Z_upload_data.json", FetchedAt: time.Now()}
+
+	ctx := t.Context()
+	require.NoError(t, writer.Write(ctx, dump, lsps))
+	// Same dump again is skipped
+	require.NoError(t, writer.Write(ctx, dump, lsps))
+
+	// A newer sequence number for the same epoch wins after dedup
+	lsps[0].SequenceNumber = 43
+	restarted, err := NewLSDBWriter(LSDBWriterConfig{Logger: laketesting.NewLogger(), ClickHouse: client})
+	require.NoError(t, err)
+	require.NoError(t, restarted.Write(ctx, dump, lsps))
+
+	conn, err := client.Conn(ctx)
+	require.NoError(t, err)
+	rows, err := conn.Query(ctx, `
+		SELECT system_id, sequence_number, checksum, lifetime, metric, neighbor_system_id
+		FROM fact_isis_lsdb_history FINAL
+		WHERE epoch_ts = toDateTime64('2025-01-17 12:30:00', 3, 'UTC')
+		ORDER BY system_id, neighbor_system_id
+	`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	type row struct {
+		SystemID         string
+		SequenceNumber   uint64
+		Checksum         uint32
+		Lifetime         uint32
+		Metric           uint32
+		NeighborSystemID string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		require.NoError(t, rows.Scan(&r.SystemID, &r.SequenceNumber, &r.Checksum, &r.Lifetime, &r.Metric, &r.NeighborSystemID))
+		got = append(got, r)
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, []row{
+		{"ac10.0001.0000.00-00", 43, 0xbeef, 1100, 1000, "ac10.0002.0000"},
+		{"ac10.0001.0000.00-00", 43, 0xbeef, 1100, 2000, "ac10.0003.0000"},
+		{"ac10.0004.0000.00-00", 7, 0, 900, 0, ""},
+	}, got)
+}
+
+func TestLSDBWriter_WriteSameEpochPerPrefix(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t)
+	writer, err := NewLSDBWriter(LSDBWriterConfig{Logger: laketesting.NewLogger(), ClickHouse: client})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	// Two regions dumped at the same time are both written
+	for _, key := range []string{"eu/2025-01-18T08-00-00Z_upload_data.json", "us/2025-01-18T08-00-00Z_upload_data.json"} {
+		region, _, _ := strings.Cut(key, "/")
+		lsps := []LSP{{SystemID: "ac10." + region + ".0000.00-00", SequenceNumber: 1, RemainingLifetime: 900}}
+		require.NoError(t, writer.Write(ctx, &Dump{FileName: key, FetchedAt: time.Now()}, lsps))
+	}
+
+	conn, err := client.Conn(ctx)
+	require.NoError(t, err)
+	rows, err := conn.Query(ctx, `
+		SELECT system_id
+		FROM fact_isis_lsdb_history FINAL
+		WHERE epoch_ts = toDateTime64('2025-01-18 08:00:00', 3, 'UTC')
+		ORDER BY system_id
+	`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var systemID string
+		require.NoError(t, rows.Scan(&systemID))
+		got = append(got, systemID)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"ac10.eu.0000.00-00", "ac10.us.0000.00-00"}, got)
+}
@@ -3,6 +3,8 @@ package isis
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // jsonDump represents the top-level JSON structure of an IS-IS dump.
@@ -27,9 +29,27 @@ type jsonLevel struct {
 
 // jsonLSP represents a Link State PDU from a router.
 type jsonLSP struct {
+	Sequence           uint64                   `json:"sequence"`
+	Checksum           uint32                   `json:"checksum"`
+	RemainingLifetime  uint32                   `json:"remainingLifetime"`
 	Hostname           jsonHostname             `json:"hostname"`
 	Neighbors          []jsonNeighbor           `json:"neighbors"`
 	RouterCapabilities []jsonRouterCapabilities `json:"routerCapabilities"`
+	Reachabilities     []jsonReachability       `json:"reachabilities"`
+}
+
+// jsonReachability represents an IPv4 prefix reachability.
+type jsonReachability struct {
+	ReachabilityV4         string                     `json:"reachabilityV4"`
+	MaskLength             uint8                      `json:"maskLength"`
+	SRPrefixReachabilities []jsonSRPrefixReachability `json:"srPrefixReachabilities"`
+}
+
+// jsonSRPrefixReachability represents a prefix SID sub-TLV; flags maps flag
+// letters (R, N, P, E, V, L) to whether they are set.
+type jsonSRPrefixReachability struct {
+	SID   uint32          `json:"sid"`
+	Flags map[string]bool `json:"flags"`
 }
 
 // jsonHostname contains the router hostname.
@@ -43,6 +63,15 @@ type jsonNeighbor struct {
 	Metric       uint32       `json:"metric"`
 	NeighborAddr string       `json:"neighborAddr"`
 	AdjSIDs      []jsonAdjSID `json:"adjSids"`
+	BFD          *jsonBFD     `json:"bfd"`
+}
+
+// jsonBFD represents the BFD session state of an adjacency.
+type jsonBFD struct {
+	State         string `json:"state"`
+	MinTxInterval uint32 `json:"minTxInterval"`
+	MinRxInterval uint32 `json:"minRxInterval"`
+	Multiplier    uint32 `json:"multiplier"`
 }
 
 // jsonAdjSID represents an adjacency SID entry.
@@ -85,8 +114,11 @@ func Parse(data []byte) ([]LSP, error) {
 	// Process each LSP
 	for systemID, jsonLSP := range level2.LSPs {
 		lsp := LSP{
-			SystemID: systemID,
-			Hostname: jsonLSP.Hostname.Name,
+			SystemID:          systemID,
+			Hostname:          jsonLSP.Hostname.Name,
+			SequenceNumber:    jsonLSP.Sequence,
+			Checksum:          jsonLSP.Checksum,
+			RemainingLifetime: jsonLSP.RemainingLifetime,
 		}
 		// RouterCapabilities is an array; use the first entry if present
 		if len(jsonLSP.RouterCapabilities) > 0 {
@@ -106,9 +138,40 @@ func Parse(data []byte) ([]LSP, error) {
 				NeighborAddr: jn.NeighborAddr,
 				AdjSIDs:      adjSIDs,
 			}
+			if jn.BFD != nil && jn.BFD.State != "" {
+				// Normalize e.g. "adminDown" and "admin-down" to "ADMIN_DOWN"
+				state := strings.ToUpper(strings.ReplaceAll(jn.BFD.State, "-", "_"))
+				if state == "ADMINDOWN" {
+					state = "ADMIN_DOWN"
+				}
+				neighbor.BFD = &BFDSession{
+					State:      state,
+					MinTxUs:    jn.BFD.MinTxInterval,
+					MinRxUs:    jn.BFD.MinRxInterval,
+					Multiplier: jn.BFD.Multiplier,
+				}
+			}
 			lsp.Neighbors = append(lsp.Neighbors, neighbor)
 		}
 
+		// Collect prefix SIDs from the reachabilities that carry them
+		for _, reach := range jsonLSP.Reachabilities {
+			for _, sr := range reach.SRPrefixReachabilities {
+				var flags []string
+				for flag, set := range sr.Flags {
+					if set {
+						flags = append(flags, flag)
+					}
+				}
+				sort.Strings(flags)
+				lsp.PrefixSIDs = append(lsp.PrefixSIDs, PrefixSID{
+					Prefix: fmt.Sprintf("%s/%d", reach.ReachabilityV4, reach.MaskLength),
+					SID:    sr.SID,
+					Flags:  flags,
+				})
+			}
+		}
+
 		lsps = append(lsps, lsp)
 	}
 
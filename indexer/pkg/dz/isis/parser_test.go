@@ -18,6 +18,9 @@ func TestParse(t *testing.T) {
 								"2": {
 									"lsps": {
 										"ac10.0001.0000.00-00": {
+											"sequence": 1234,
+											"checksum": 48879,
+											"remainingLifetime": 1100,
 											"hostname": {"name": "DZ-NY7-SW01"},
 											"routerCapabilities": [{
 												"routerId": "172.16.0.1",
@@ -29,7 +32,8 @@ func TestParse(t *testing.T) {
 													"systemId": "ac10.0002.0000",
 													"metric": 1000,
 													"neighborAddr": "172.16.0.117",
-													"adjSids": [{"adjSid": 100001}, {"adjSid": 100002}]
+													"adjSids": [{"adjSid": 100001}, {"adjSid": 100002}],
+													"bfd": {"state": "adminDown", "minTxInterval": 300000, "minRxInterval": 250000, "multiplier": 3}
 												},
 												{
 													"systemId": "ac10.0003.0000",
@@ -81,6 +85,9 @@ func TestParse(t *testing.T) {
 		assert.Equal(t, "ac10.0001.0000.00-00", ny7LSP.SystemID)
 		assert.Equal(t, "DZ-NY7-SW01", ny7LSP.Hostname)
 		assert.Equal(t, "172.16.0.1", ny7LSP.RouterID)
+		assert.Equal(t, uint64(1234), ny7LSP.SequenceNumber)
+		assert.Equal(t, uint32(48879), ny7LSP.Checksum)
+		assert.Equal(t, uint32(1100), ny7LSP.RemainingLifetime)
 		assert.Len(t, ny7LSP.Neighbors, 2)
 
 		// Check first neighbor
@@ -88,6 +95,9 @@ func TestParse(t *testing.T) {
 		assert.Equal(t, uint32(1000), ny7LSP.Neighbors[0].Metric)
 		assert.Equal(t, "172.16.0.117", ny7LSP.Neighbors[0].NeighborAddr)
 		assert.Equal(t, []uint32{100001, 100002}, ny7LSP.Neighbors[0].AdjSIDs)
+		require.NotNil(t, ny7LSP.Neighbors[0].BFD)
+		assert.Equal(t, BFDSession{State: "ADMIN_DOWN", MinTxUs: 300000, MinRxUs: 250000, Multiplier: 3}, *ny7LSP.Neighbors[0].BFD)
+		assert.Nil(t, ny7LSP.Neighbors[1].BFD)
 	})
 
 	t.Run("empty neighbors", func(t *testing.T) {
@@ -206,4 +216,66 @@ func TestParse(t *testing.T) {
 		assert.Len(t, lsps, 1)
 		assert.Nil(t, lsps[0].Neighbors[0].AdjSIDs)
 	})
+
+	t.Run("prefix SIDs", func(t *testing.T) {
+		data := []byte(`{
+			"vrfs": {
+				"default": {
+					"isisInstances": {
+						"1": {
+							"level": {
+								"2": {
+									"lsps": {
+										"ac10.0001.0000.00-00": {
+											"hostname": {"name": "DZ-NY7-SW01"},
+											"reachabilities": [
+												{
+													"reachabilityV4": "172.16.0.117",
+													"maskLength": 31,
+													"metric": 1000
+												},
+												{
+													"reachabilityV4": "10.0.0.0",
+													"maskLength": 24,
+													"srPrefixReachabilities": [{"sid": 200, "flags": {"R": false, "N": false, "P": true}}]
+												},
+												{
+													"reachabilityV4": "172.16.0.1",
+													"maskLength": 32,
+													"srPrefixReachabilities": [{"sid": 1, "flags": {"R": false, "N": true, "P": false}}]
+												}
+											]
+										},
+										"ac10.0002.0000.00-00": {
+											"hostname": {"name": "DZ-DC1-SW01"}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}`)
+
+		lsps, err := Parse(data)
+		require.NoError(t, err)
+		require.Len(t, lsps, 2)
+
+		lspMap := make(map[string]LSP)
+		for _, lsp := range lsps {
+			lspMap[lsp.SystemID] = lsp
+		}
+
+		lsp1 := lspMap["ac10.0001.0000.00-00"]
+		assert.Equal(t, []PrefixSID{
+			{Prefix: "10.0.0.0/24", SID: 200, Flags: []string{"P"}},
+			{Prefix: "172.16.0.1/32", SID: 1, Flags: []string{"N"}},
+		}, lsp1.PrefixSIDs)
+		assert.Equal(t, &PrefixSID{Prefix: "172.16.0.1/32", SID: 1, Flags: []string{"N"}}, lsp1.NodeSID())
+
+		lsp2 := lspMap["ac10.0002.0000.00-00"]
+		assert.Empty(t, lsp2.PrefixSIDs)
+		assert.Nil(t, lsp2.NodeSID())
+	})
 }
@@ -2,6 +2,8 @@ package isis
 
 import (
 	"context"
+	"path"
+	"sort"
 	"time"
 )
 
@@ -21,3 +23,50 @@ type Source interface {
 	// Close releases any resources held by the source.
 	Close() error
 }
+
+// Object is a dump file held by a MultiSource.
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// MultiSource is a Source whose topology is split across several dump files,
+// e.g. one per region. Each key prefix holds the dumps of one file's series.
+type MultiSource interface {
+	Source
+
+	// LatestObjects lists the latest object under each key prefix.
+	LatestObjects(ctx context.Context) ([]Object, error)
+
+	// Fetch retrieves a single object.
+	Fetch(ctx context.Context, key string) (*Dump, error)
+}
+
+// ObjectPrefix returns the prefix (directory) of an object key, or "" for keys
+// at the bucket root.
+func ObjectPrefix(key string) string {
+	if dir := path.Dir(key); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// LatestPerPrefix returns the object with the alphabetically last key under
+// each prefix, ordered by key. Dump keys start with a timestamp, so that is
+// the latest dump of each series.
+func LatestPerPrefix(objects []Object) []Object {
+	latest := make(map[string]Object)
+	for _, obj := range objects {
+		prefix := ObjectPrefix(obj.Key)
+		if cur, ok := latest[prefix]; !ok || obj.Key > cur.Key {
+			latest[prefix] = obj
+		}
+	}
+
+	out := make([]Object, 0, len(latest))
+	for _, obj := range latest {
+		out = append(out, obj)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
@@ -2,6 +2,8 @@ package isis
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 )
 
@@ -36,3 +38,36 @@ func (m *MockSource) Close() error {
 	m.Closed = true
 	return nil
 }
+
+// MockMultiSource is a MultiSource implementation for testing. Objects are
+// served from Dumps by key.
+type MockMultiSource struct {
+	MockSource
+
+	mu      sync.Mutex
+	Objects []Object
+	Dumps   map[string]*Dump
+	Fetched []string
+}
+
+// LatestObjects returns the latest of the configured objects per prefix.
+func (m *MockMultiSource) LatestObjects(ctx context.Context) ([]Object, error) {
+	if m.FetchErr != nil {
+		return nil, m.FetchErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return LatestPerPrefix(m.Objects), nil
+}
+
+// Fetch returns the dump for key and records the fetch.
+func (m *MockMultiSource) Fetch(ctx context.Context, key string) (*Dump, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dump, ok := m.Dumps[key]
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", key)
+	}
+	m.Fetched = append(m.Fetched, key)
+	return dump, nil
+}
@@ -76,36 +76,38 @@ func NewS3Source(ctx context.Context, cfg S3SourceConfig) (*S3Source, error) {
 // Files are named with timestamp prefixes (YYYY-MM-DDTHH-MM-SSZ_upload_data.json),
 // so the latest file is the alphabetically last one.
 func (s *S3Source) FetchLatest(ctx context.Context) (*Dump, error) {
-	// List objects in the bucket
-	listOutput, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket: aws.String(s.bucket),
-	})
+	objects, err := s.listObjects(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list objects: %w", err)
-	}
-
-	if len(listOutput.Contents) == 0 {
-		return nil, fmt.Errorf("no objects found in bucket %s", s.bucket)
+		return nil, err
 	}
 
 	// Sort keys descending to get the latest (alphabetically last)
-	keys := make([]string, 0, len(listOutput.Contents))
-	for _, obj := range listOutput.Contents {
-		if obj.Key != nil {
-			keys = append(keys, *obj.Key)
-		}
+	keys := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		keys = append(keys, obj.Key)
 	}
 	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
 
-	latestKey := keys[0]
+	return s.Fetch(ctx, keys[0])
+}
+
+// LatestObjects lists the latest dump under each key prefix in the bucket.
+func (s *S3Source) LatestObjects(ctx context.Context) ([]Object, error) {
+	objects, err := s.listObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return LatestPerPrefix(objects), nil
+}
 
-	// Fetch the latest object
+// Fetch retrieves a single object from the bucket.
+func (s *S3Source) Fetch(ctx context.Context, key string) (*Dump, error) {
 	getOutput, err := s.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(latestKey),
+		Key:    aws.String(key),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object %s: %w", latestKey, err)
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
 	}
 	defer getOutput.Body.Close()
 
@@ -117,10 +119,35 @@ func (s *S3Source) FetchLatest(ctx context.Context) (*Dump, error) {
 	return &Dump{
 		FetchedAt: time.Now(),
 		RawJSON:   data,
-		FileName:  latestKey,
+		FileName:  key,
 	}, nil
 }
 
+// listObjects lists every object in the bucket, following pagination.
+func (s *S3Source) listObjects(ctx context.Context) ([]Object, error) {
+	var objects []Object
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			objects = append(objects, Object{Key: *obj.Key, LastModified: aws.ToTime(obj.LastModified)})
+		}
+	}
+
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no objects found in bucket %s", s.bucket)
+	}
+	return objects, nil
+}
+
 // Close releases resources. For S3Source, this is a no-op.
 func (s *S3Source) Close() error {
 	return nil
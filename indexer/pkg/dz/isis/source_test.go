@@ -0,0 +1,54 @@
+package isis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectPrefix(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", ObjectPrefix("2025-01-17T12-30-00Z_upload_data.json"))
+	assert.Equal(t, "us-east", ObjectPrefix("us-east/2025-01-17T12-30-00Z_upload_data.json"))
+	assert.Equal(t, "dumps/eu", ObjectPrefix("dumps/eu/2025-01-17T12-30-00Z_upload_data.json"))
+}
+
+func TestLatestPerPrefix(t *testing.T) {
+	t.Parallel()
+
+	objects := []Object{
+		{Key: "us-east/2025-01-17T12-30-00Z_upload_data.json"},
+		{Key: "eu/2025-01-17T12-00-00Z_upload_data.json"},
+		{Key: "us-east/2025-01-17T12-31-00Z_upload_data.json"},
+		{Key: "2025-01-17T12-00-00Z_upload_data.json"},
+		{Key: "eu/2025-01-17T11-59-00Z_upload_data.json"},
+	}
+
+	assert.Equal(t, []Object{
+		{Key: "2025-01-17T12-00-00Z_upload_data.json"},
+		{Key: "eu/2025-01-17T12-00-00Z_upload_data.json"},
+		{Key: "us-east/2025-01-17T12-31-00Z_upload_data.json"},
+	}, LatestPerPrefix(objects))
+
+	assert.Empty(t, LatestPerPrefix(nil))
+}
+
+func TestChanged(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2025, 1, 17, 12, 0, 0, 0, time.UTC)
+	objects := []Object{
+		{Key: "eu/a.json", LastModified: t0},
+		{Key: "us/a.json", LastModified: t0.Add(time.Minute)},
+		{Key: "ap/a.json", LastModified: t0},
+	}
+	state := map[string]time.Time{
+		"eu/a.json": t0,
+		"us/a.json": t0,
+	}
+
+	assert.Equal(t, []Object{objects[1], objects[2]}, Changed(objects, state))
+	assert.Equal(t, objects, Changed(objects, nil))
+}
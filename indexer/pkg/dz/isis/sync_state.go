@@ -0,0 +1,103 @@
+package isis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/malbeclabs/lake/indexer/pkg/clickhouse"
+)
+
+// SyncStateStoreConfig configures the sync state store.
+type SyncStateStoreConfig struct {
+	Logger     *slog.Logger
+	ClickHouse clickhouse.Client
+}
+
+func (cfg *SyncStateStoreConfig) Validate() error {
+	if cfg.Logger == nil {
+		return errors.New("logger is required")
+	}
+	if cfg.ClickHouse == nil {
+		return errors.New("clickhouse connection is required")
+	}
+	return nil
+}
+
+// SyncStateStore tracks the last-modified time of each dump file synced into
+// the graph in isis_sync_state, so unchanged files are not reprocessed.
+type SyncStateStore struct {
+	log *slog.Logger
+	cfg SyncStateStoreConfig
+}
+
+// NewSyncStateStore creates a new sync state store.
+func NewSyncStateStore(cfg SyncStateStoreConfig) (*SyncStateStore, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &SyncStateStore{
+		log: cfg.Logger,
+		cfg: cfg,
+	}, nil
+}
+
+// Load returns the last-modified time of each synced file by key.
+func (s *SyncStateStore) Load(ctx context.Context) (map[string]time.Time, error) {
+	conn, err := s.cfg.ClickHouse.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClickHouse connection: %w", err)
+	}
+
+	rows, err := conn.Query(ctx, `
+		SELECT file_key, argMax(last_modified, synced_at)
+		FROM isis_sync_state
+		GROUP BY file_key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync state: %w", err)
+	}
+	defer rows.Close()
+
+	state := make(map[string]time.Time)
+	for rows.Next() {
+		var key string
+		var lastModified time.Time
+		if err := rows.Scan(&key, &lastModified); err != nil {
+			return nil, fmt.Errorf("failed to scan sync state: %w", err)
+		}
+		state[key] = lastModified.UTC()
+	}
+	return state, rows.Err()
+}
+
+// Changed returns the objects whose last-modified time differs from the
+// synced state, including objects that were never synced.
+func Changed(objects []Object, state map[string]time.Time) []Object {
+	var changed []Object
+	for _, obj := range objects {
+		if synced, ok := state[obj.Key]; !ok || !synced.Equal(obj.LastModified.UTC()) {
+			changed = append(changed, obj)
+		}
+	}
+	return changed
+}
+
+// Record stores that an object was synced.
+func (s *SyncStateStore) Record(ctx context.Context, obj Object, syncedAt time.Time, duration time.Duration) error {
+	conn, err := s.cfg.ClickHouse.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get ClickHouse connection: %w", err)
+	}
+
+	if err := conn.Exec(ctx, `
+		INSERT INTO isis_sync_state (file_key, last_modified, synced_at, duration_ms)
+		VALUES (?, ?, ?, ?)
+	`, obj.Key, obj.LastModified.UTC(), syncedAt.UTC(), uint64(duration.Milliseconds())); err != nil {
+		return fmt.Errorf("failed to record sync state for %s: %w", obj.Key, err)
+	}
+	s.log.Debug("isis_sync: recorded sync state", "file", obj.Key, "last_modified", obj.LastModified)
+	return nil
+}
@@ -0,0 +1,47 @@
+package isis
+
+import (
+	"testing"
+	"time"
+
+	laketesting "github.com/malbeclabs/lake/utils/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyncStateStore(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewSyncStateStore(SyncStateStoreConfig{})
+	require.ErrorContains(t, err, "logger is required")
+
+	_, err = NewSyncStateStore(SyncStateStoreConfig{Logger: laketesting.NewLogger()})
+	require.ErrorContains(t, err, "clickhouse connection is required")
+}
+
+func TestSyncStateStore_RecordAndLoad(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t)
+	store, err := NewSyncStateStore(SyncStateStoreConfig{Logger: laketesting.NewLogger(), ClickHouse: client})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	state, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, state)
+
+	t0 := time.Date(2025, 1, 17, 12, 0, 0, 0, time.UTC)
+	syncedAt := time.Date(2025, 1, 17, 12, 1, 0, 0, time.UTC)
+	require.NoError(t, store.Record(ctx, Object{Key: "eu/a.json", LastModified: t0}, syncedAt, time.Second))
+	require.NoError(t, store.Record(ctx, Object{Key: "us/a.json", LastModified: t0}, syncedAt, time.Second))
+	// A later sync of a modified file replaces its state
+	require.NoError(t, store.Record(ctx, Object{Key: "us/a.json", LastModified: t0.Add(time.Minute)}, syncedAt.Add(time.Minute), time.Second))
+
+	state, err = store.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{
+		"eu/a.json": t0,
+		"us/a.json": t0.Add(time.Minute),
+	}, state)
+}
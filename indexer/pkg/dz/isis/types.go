@@ -1,17 +1,54 @@
 package isis
 
+import "slices"
+
 // LSP represents an IS-IS Link State PDU from a router.
 type LSP struct {
-	SystemID  string     // IS-IS system ID, e.g., "ac10.0001.0000.00-00"
-	Hostname  string     // Router hostname, e.g., "DZ-NY7-SW01"
-	RouterID  string     // Router ID from capabilities, e.g., "172.16.0.1"
-	Neighbors []Neighbor // Adjacent neighbors
+	SystemID          string      // IS-IS system ID, e.g., "ac10.0001.0000.00-00"
+	Hostname          string      // Router hostname, e.g., "DZ-NY7-SW01"
+	RouterID          string      // Router ID from capabilities, e.g., "172.16.0.1"
+	SequenceNumber    uint64      // LSP sequence number, incremented on each change
+	Checksum          uint32      // LSP checksum
+	RemainingLifetime uint32      // Seconds until the LSP expires
+	Neighbors         []Neighbor  // Adjacent neighbors
+	PrefixSIDs        []PrefixSID // Segment routing prefix SIDs advertised by the router
+}
+
+// PrefixSID is a segment routing prefix SID advertised with an IP reachability.
+type PrefixSID struct {
+	Prefix string   // Advertised prefix, e.g., "172.16.0.1/32"
+	SID    uint32   // SID index relative to the SRGB base
+	Flags  []string // Set prefix SID flags, sorted, e.g., ["N", "R"]
+}
+
+// NodeSID returns the router's node SID: the first prefix SID with the N flag
+// set, falling back to the first prefix SID. It returns nil if the router
+// advertises no prefix SIDs.
+func (l LSP) NodeSID() *PrefixSID {
+	for i := range l.PrefixSIDs {
+		if slices.Contains(l.PrefixSIDs[i].Flags, "N") {
+			return &l.PrefixSIDs[i]
+		}
+	}
+	if len(l.PrefixSIDs) > 0 {
+		return &l.PrefixSIDs[0]
+	}
+	return nil
 }
 
 // Neighbor represents an IS-IS adjacency to a neighboring router.
 type Neighbor struct {
-	SystemID     string   // Neighbor's IS-IS system ID
-	Metric       uint32   // IS-IS metric (latency in microseconds)
-	NeighborAddr string   // IP address of neighbor interface
-	AdjSIDs      []uint32 // Segment routing adjacency SIDs
+	SystemID     string      // Neighbor's IS-IS system ID
+	Metric       uint32      // IS-IS metric (latency in microseconds)
+	NeighborAddr string      // IP address of neighbor interface
+	AdjSIDs      []uint32    // Segment routing adjacency SIDs
+	BFD          *BFDSession // BFD session protecting the adjacency, nil if none
+}
+
+// BFDSession is the state of a BFD session on an IS-IS adjacency.
+type BFDSession struct {
+	State      string // Session state, e.g., "UP", "DOWN", "ADMIN_DOWN", "INIT"
+	MinTxUs    uint32 // Negotiated minimum transmit interval in microseconds
+	MinRxUs    uint32 // Negotiated minimum receive interval in microseconds
+	Multiplier uint32 // Detection multiplier
 }
@@ -149,6 +149,9 @@ type View struct {
 	fetchedAt time.Time
 	readyOnce sync.Once
 	readyCh   chan struct{}
+
+	refreshedAtMu sync.RWMutex
+	refreshedAt   time.Time
 }
 
 func NewView(cfg ViewConfig) (*View, error) {
@@ -189,6 +192,14 @@ func (v *View) Ready() bool {
 	}
 }
 
+// LastRefreshedAt returns when the view last completed a successful refresh,
+// or the zero time if it hasn't yet.
+func (v *View) LastRefreshedAt() time.Time {
+	v.refreshedAtMu.RLock()
+	defer v.refreshedAtMu.RUnlock()
+	return v.refreshedAt
+}
+
 // WaitReady waits for the view to be ready (has completed at least one successful refresh)
 // It returns immediately if already ready, or blocks until ready or context is cancelled.
 func (v *View) WaitReady(ctx context.Context) error {
@@ -317,6 +328,9 @@ func (v *View) Refresh(ctx context.Context) error {
 		close(v.readyCh)
 		v.log.Info("serviceability: view is now ready")
 	})
+	v.refreshedAtMu.Lock()
+	v.refreshedAt = v.cfg.Clock.Now()
+	v.refreshedAtMu.Unlock()
 
 	v.log.Debug("serviceability: refresh completed", "fetched_at", fetchedAt)
 	metrics.ViewRefreshTotal.WithLabelValues("serviceability", "success").Inc()
@@ -85,6 +85,9 @@ type View struct {
 	readyOnce sync.Once
 	readyCh   chan struct{}
 	refreshMu sync.Mutex // prevents concurrent refreshes
+
+	refreshedAtMu sync.RWMutex
+	refreshedAt   time.Time
 }
 
 func NewView(cfg ViewConfig) (*View, error) {
@@ -205,6 +208,9 @@ func (v *View) Refresh(ctx context.Context) error {
 		close(v.readyCh)
 		v.log.Info("telemetry/latency: view is now ready")
 	})
+	v.refreshedAtMu.Lock()
+	v.refreshedAt = v.cfg.Clock.Now()
+	v.refreshedAtMu.Unlock()
 
 	metrics.ViewRefreshTotal.WithLabelValues("telemetry", "success").Inc()
 	return nil
@@ -220,6 +226,14 @@ func (v *View) Ready() bool {
 	}
 }
 
+// LastRefreshedAt returns when the view last completed a successful refresh,
+// or the zero time if it hasn't yet.
+func (v *View) LastRefreshedAt() time.Time {
+	v.refreshedAtMu.RLock()
+	defer v.refreshedAtMu.RUnlock()
+	return v.refreshedAt
+}
+
 // WaitReady waits for the view to be ready (has completed at least one successful refresh)
 // It returns immediately if already ready, or blocks until ready or context is cancelled.
 func (v *View) WaitReady(ctx context.Context) error {
@@ -128,6 +128,9 @@ type View struct {
 	readyOnce sync.Once
 	readyCh   chan struct{}
 	refreshMu sync.Mutex // prevents concurrent refreshes
+
+	refreshedAtMu sync.RWMutex
+	refreshedAt   time.Time
 }
 
 func NewView(cfg ViewConfig) (*View, error) {
@@ -340,6 +343,9 @@ func (v *View) Refresh(ctx context.Context) error {
 			close(v.readyCh)
 			v.log.Info("telemetry/usage: view is now ready (no data)")
 		})
+		v.refreshedAtMu.Lock()
+		v.refreshedAt = v.cfg.Clock.Now()
+		v.refreshedAtMu.Unlock()
 		metrics.ViewRefreshTotal.WithLabelValues("telemetry-usage", "success").Inc()
 		return nil
 	}
@@ -356,6 +362,9 @@ func (v *View) Refresh(ctx context.Context) error {
 		close(v.readyCh)
 		v.log.Info("telemetry/usage: view is now ready")
 	})
+	v.refreshedAtMu.Lock()
+	v.refreshedAt = v.cfg.Clock.Now()
+	v.refreshedAtMu.Unlock()
 
 	metrics.ViewRefreshTotal.WithLabelValues("telemetry-usage", "success").Inc()
 	return nil
@@ -1040,6 +1049,14 @@ func (v *View) Ready() bool {
 	}
 }
 
+// LastRefreshedAt returns when the view last completed a successful refresh,
+// or the zero time if it hasn't yet.
+func (v *View) LastRefreshedAt() time.Time {
+	v.refreshedAtMu.RLock()
+	defer v.refreshedAtMu.RUnlock()
+	return v.refreshedAt
+}
+
 // WaitReady waits for the view to be ready (has completed at least one successful refresh)
 // It returns immediately if already ready, or blocks until ready or context is cancelled.
 func (v *View) WaitReady(ctx context.Context) error {
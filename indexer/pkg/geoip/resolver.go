@@ -0,0 +1,49 @@
+package geoip
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/malbeclabs/doublezero/tools/maxmind/pkg/geoip"
+	"github.com/malbeclabs/doublezero/tools/maxmind/pkg/metrodb"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// OpenResolver opens the MaxMind city and ASN databases and returns a resolver
+// along with a function that closes them.
+func OpenResolver(log *slog.Logger, cityDBPath, asnDBPath string) (geoip.Resolver, func() error, error) {
+	cityDB, err := geoip2.Open(cityDBPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open GeoIP city database: %w", err)
+	}
+
+	asnDB, err := geoip2.Open(asnDBPath)
+	if err != nil {
+		cityDB.Close()
+		return nil, nil, fmt.Errorf("failed to open GeoIP ASN database: %w", err)
+	}
+
+	metroDB, err := metrodb.New()
+	if err != nil {
+		cityDB.Close()
+		asnDB.Close()
+		return nil, nil, fmt.Errorf("failed to create metro database: %w", err)
+	}
+
+	resolver, err := geoip.NewResolver(log, cityDB, asnDB, metroDB)
+	if err != nil {
+		cityDB.Close()
+		asnDB.Close()
+		return nil, nil, fmt.Errorf("failed to create GeoIP resolver: %w", err)
+	}
+
+	return resolver, func() error {
+		if err := cityDB.Close(); err != nil {
+			return fmt.Errorf("failed to close city database: %w", err)
+		}
+		if err := asnDB.Close(); err != nil {
+			return fmt.Errorf("failed to close ASN database: %w", err)
+		}
+		return nil
+	}, nil
+}
@@ -146,7 +146,7 @@ func (v *View) Refresh(ctx context.Context) error {
 
 	v.log.Debug("geoip: querying IPs from serviceability and solana stores")
 
-	// Collect unique IPs from both sources
+	// Collect unique IPs from all sources
 	ipSet := make(map[string]net.IP)
 
 	// Get IPs from serviceability users
@@ -161,6 +161,18 @@ func (v *View) Refresh(ctx context.Context) error {
 		}
 	}
 
+	// Get public IPs from serviceability devices
+	devices, err := dzsvc.QueryCurrentDevices(ctx, v.log, v.cfg.ServiceabilityStore.GetClickHouse())
+	if err != nil {
+		metrics.ViewRefreshTotal.WithLabelValues("geoip", "error").Inc()
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	for _, device := range devices {
+		if ip := net.ParseIP(device.PublicIP); ip != nil {
+			ipSet[ip.String()] = ip
+		}
+	}
+
 	// Get IPs from solana gossip nodes
 	gossipIPs, err := v.cfg.SolanaStore.GetGossipIPs(ctx)
 	if err != nil {
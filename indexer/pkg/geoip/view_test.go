@@ -359,6 +359,83 @@ func TestLake_GeoIP_View_Refresh(t *testing.T) {
 		}
 	})
 
+	t.Run("resolves device public IPs", func(t *testing.T) {
+		t.Parallel()
+
+		db := testClient(t)
+		log := laketesting.NewLogger()
+		geoipStore, err := NewStore(StoreConfig{
+			Logger:     log,
+			ClickHouse: db,
+		})
+		require.NoError(t, err)
+
+		svcStore, err := dzsvc.NewStore(dzsvc.StoreConfig{
+			Logger:     log,
+			ClickHouse: db,
+		})
+		require.NoError(t, err)
+
+		solStore, err := sol.NewStore(sol.StoreConfig{
+			Logger:     log,
+			ClickHouse: db,
+		})
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		err = svcStore.ReplaceDevices(ctx, []dzsvc.Device{
+			{
+				PK:            testPK(1),
+				Status:        "activated",
+				DeviceType:    "hybrid",
+				Code:          "DEV001",
+				PublicIP:      "9.9.9.9",
+				ContributorPK: testPK(2),
+				MetroPK:       testPK(3),
+			},
+		})
+		require.NoError(t, err)
+
+		resolver := &mockGeoIPResolver{
+			resolveFunc: func(ip net.IP) *geoip.Record {
+				return &geoip.Record{IP: ip, ASN: 19281, ASNOrg: "Quad9"}
+			},
+		}
+
+		view, err := NewView(ViewConfig{
+			Logger:              log,
+			Clock:               clockwork.NewFakeClock(),
+			GeoIPStore:          geoipStore,
+			GeoIPResolver:       resolver,
+			ServiceabilityStore: svcStore,
+			SolanaStore:         solStore,
+			RefreshInterval:     time.Second,
+		})
+		require.NoError(t, err)
+
+		err = view.Refresh(ctx)
+		require.NoError(t, err)
+
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		d, err := NewGeoIPRecordDataset(laketesting.NewLogger())
+		require.NoError(t, err)
+
+		var current map[string]any
+		for i := 0; i < 20; i++ {
+			current, err = d.GetCurrentRow(ctx, conn, dataset.NewNaturalKey("9.9.9.9").ToSurrogate())
+			require.NoError(t, err)
+			if current != nil {
+				break
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+		require.NotNil(t, current, "should have found record for device public IP")
+		require.Equal(t, "Quad9", current["asn_org"])
+	})
+
 	t.Run("handles empty stores gracefully", func(t *testing.T) {
 		t.Parallel()
 
@@ -62,6 +62,7 @@ type Config struct {
 	ISISS3Region        string        // AWS region (default: us-east-1)
 	ISISS3EndpointURL   string        // Custom S3 endpoint URL (for testing)
 	ISISRefreshInterval time.Duration // Refresh interval for IS-IS sync (default: 30s)
+	ISISMaxConcurrency  int           // Dump files processed in parallel (default: 4)
 
 	// SkipReadyWait makes the Ready() method return true immediately without waiting
 	// for views to be populated. Useful for preview/dev environments where fast startup
@@ -131,10 +132,16 @@ func (c *Config) Validate() error {
 	if c.ISISEnabled && c.Neo4j == nil {
 		return errors.New("neo4j is required when isis is enabled")
 	}
+	if c.ISISMaxConcurrency < 0 {
+		return errors.New("isis max concurrency must not be negative")
+	}
 
 	// Optional with defaults
 	if c.Clock == nil {
 		c.Clock = clockwork.NewRealClock()
 	}
+	if c.ISISMaxConcurrency == 0 {
+		c.ISISMaxConcurrency = defaultISISMaxConcurrency
+	}
 	return nil
 }
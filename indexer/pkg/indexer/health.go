@@ -0,0 +1,177 @@
+package indexer
+
+import (
+	"time"
+)
+
+// Health statuses, from best to worst. Disabled components don't count
+// towards the overall status.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+	HealthDisabled = "disabled"
+)
+
+// A component is degraded once its data is older than degradedIntervals of
+// its refresh interval, and down once it's older than downIntervals.
+const (
+	degradedIntervals = 2
+	downIntervals     = 5
+)
+
+// defaultISISRefreshInterval is used when ISISRefreshInterval isn't set.
+const defaultISISRefreshInterval = 30 * time.Second
+
+// Health is the freshness of each data source the indexer writes.
+type Health struct {
+	Status     string           `json:"status"`
+	Components HealthComponents `json:"components"`
+}
+
+type HealthComponents struct {
+	ClickHouse ClickHouseHealth `json:"clickhouse"`
+	Neo4j      Neo4jHealth      `json:"neo4j"`
+	Solana     SolanaHealth     `json:"solana"`
+	Influx     InfluxHealth     `json:"influx"`
+}
+
+// ClickHouseHealth tracks the serviceability and latency telemetry views,
+// which write to ClickHouse every refresh. LastWriteAt is the older of the two.
+type ClickHouseHealth struct {
+	Status      string     `json:"status"`
+	LastWriteAt *time.Time `json:"lastWriteAt"`
+	LagSeconds  float64    `json:"lagSeconds"`
+}
+
+// Neo4jHealth tracks the graph and IS-IS syncs into Neo4j.
+type Neo4jHealth struct {
+	Status         string     `json:"status"`
+	LastISISSyncAt *time.Time `json:"lastISISSyncAt"`
+	LagSeconds     float64    `json:"lagSeconds"`
+}
+
+// SolanaHealth tracks the Solana view, which fetches the current epoch on
+// every refresh.
+type SolanaHealth struct {
+	Status      string     `json:"status"`
+	LastEpochAt *time.Time `json:"lastEpochAt"`
+	LagSeconds  float64    `json:"lagSeconds"`
+}
+
+// InfluxHealth tracks the device usage view, which queries InfluxDB.
+type InfluxHealth struct {
+	Status      string     `json:"status"`
+	LastQueryAt *time.Time `json:"lastQueryAt"`
+	LagSeconds  float64    `json:"lagSeconds"`
+}
+
+// Health reports how fresh each data source is. Before a component's first
+// successful refresh, its lag is measured from when the indexer started.
+func (i *Indexer) Health() Health {
+	now := i.cfg.Clock.Now()
+	var h Health
+
+	lastWriteAt := i.svc.LastRefreshedAt()
+	if latencyAt := i.telemLatency.LastRefreshedAt(); latencyAt.Before(lastWriteAt) {
+		lastWriteAt = latencyAt
+	}
+	h.Components.ClickHouse.Status, h.Components.ClickHouse.LagSeconds = i.componentHealth(now, lastWriteAt, i.cfg.RefreshInterval)
+	h.Components.ClickHouse.LastWriteAt = timePtr(lastWriteAt)
+
+	h.Components.Neo4j.Status = HealthDisabled
+	if i.graphStore != nil {
+		interval := i.cfg.RefreshInterval
+		if i.isisSource != nil {
+			interval = i.cfg.ISISRefreshInterval
+			if interval <= 0 {
+				interval = defaultISISRefreshInterval
+			}
+		}
+		syncedAt := i.graphSyncedAt()
+		h.Components.Neo4j.Status, h.Components.Neo4j.LagSeconds = i.componentHealth(now, syncedAt, interval)
+		h.Components.Neo4j.LastISISSyncAt = timePtr(syncedAt)
+	}
+
+	h.Components.Solana.Status = HealthDisabled
+	if i.sol != nil {
+		refreshedAt := i.sol.LastRefreshedAt()
+		h.Components.Solana.Status, h.Components.Solana.LagSeconds = i.componentHealth(now, refreshedAt, i.cfg.RefreshInterval)
+		h.Components.Solana.LastEpochAt = timePtr(refreshedAt)
+	}
+
+	h.Components.Influx.Status = HealthDisabled
+	if i.telemUsage != nil {
+		refreshedAt := i.telemUsage.LastRefreshedAt()
+		h.Components.Influx.Status, h.Components.Influx.LagSeconds = i.componentHealth(now, refreshedAt, i.cfg.DeviceUsageRefreshInterval)
+		h.Components.Influx.LastQueryAt = timePtr(refreshedAt)
+	}
+
+	h.Status = worstHealth(
+		h.Components.ClickHouse.Status,
+		h.Components.Neo4j.Status,
+		h.Components.Solana.Status,
+		h.Components.Influx.Status,
+	)
+	return h
+}
+
+// componentHealth returns the status and lag of a component last refreshed
+// at lastAt (zero if never).
+func (i *Indexer) componentHealth(now, lastAt time.Time, interval time.Duration) (string, float64) {
+	since := lastAt
+	if since.IsZero() {
+		since = i.startedAt
+	}
+	var lag time.Duration
+	if !since.IsZero() {
+		lag = now.Sub(since)
+	}
+	return healthForLag(lag, interval), lag.Seconds()
+}
+
+// healthForLag maps a component's lag to a status given its refresh interval.
+func healthForLag(lag, interval time.Duration) string {
+	switch {
+	case interval <= 0:
+		return HealthOK
+	case lag > downIntervals*interval:
+		return HealthDown
+	case lag > degradedIntervals*interval:
+		return HealthDegraded
+	default:
+		return HealthOK
+	}
+}
+
+// worstHealth returns the worst of the given statuses, ignoring disabled ones.
+func worstHealth(statuses ...string) string {
+	rank := map[string]int{HealthOK: 0, HealthDegraded: 1, HealthDown: 2}
+	worst := HealthOK
+	for _, s := range statuses {
+		if r, ok := rank[s]; ok && r > rank[worst] {
+			worst = s
+		}
+	}
+	return worst
+}
+
+func (i *Indexer) graphSyncedAt() time.Time {
+	i.graphSyncedAtMu.RLock()
+	defer i.graphSyncedAtMu.RUnlock()
+	return i.lastGraphSyncAt
+}
+
+func (i *Indexer) setGraphSyncedAt(t time.Time) {
+	i.graphSyncedAtMu.Lock()
+	defer i.graphSyncedAtMu.Unlock()
+	i.lastGraphSyncAt = t
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
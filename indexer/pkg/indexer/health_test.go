@@ -0,0 +1,29 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthForLag(t *testing.T) {
+	t.Parallel()
+
+	interval := 30 * time.Second
+	assert.Equal(t, HealthOK, healthForLag(0, interval))
+	assert.Equal(t, HealthOK, healthForLag(60*time.Second, interval))
+	assert.Equal(t, HealthDegraded, healthForLag(61*time.Second, interval))
+	assert.Equal(t, HealthDegraded, healthForLag(150*time.Second, interval))
+	assert.Equal(t, HealthDown, healthForLag(151*time.Second, interval))
+	assert.Equal(t, HealthOK, healthForLag(time.Hour, 0))
+}
+
+func TestWorstHealth(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, HealthOK, worstHealth())
+	assert.Equal(t, HealthOK, worstHealth(HealthOK, HealthDisabled))
+	assert.Equal(t, HealthDegraded, worstHealth(HealthOK, HealthDegraded, HealthDisabled))
+	assert.Equal(t, HealthDown, worstHealth(HealthDown, HealthDegraded, HealthOK))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/malbeclabs/lake/indexer/pkg/clickhouse"
@@ -14,23 +15,42 @@ import (
 	dztelemlatency "github.com/malbeclabs/lake/indexer/pkg/dz/telemetry/latency"
 	dztelemusage "github.com/malbeclabs/lake/indexer/pkg/dz/telemetry/usage"
 	mcpgeoip "github.com/malbeclabs/lake/indexer/pkg/geoip"
+	"github.com/malbeclabs/lake/indexer/pkg/metrics"
 	"github.com/malbeclabs/lake/indexer/pkg/neo4j"
 	"github.com/malbeclabs/lake/indexer/pkg/sol"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	// defaultISISMaxConcurrency is used when ISISMaxConcurrency isn't set.
+	defaultISISMaxConcurrency = 4
+
+	// isisMaxGraphWriters caps concurrent IS-IS writes to Neo4j, which
+	// contend on the same Device and Link nodes.
+	isisMaxGraphWriters = 2
 )
 
 type Indexer struct {
 	log *slog.Logger
 	cfg Config
 
-	svc          *dzsvc.View
-	graphStore   *dzgraph.Store
-	telemLatency *dztelemlatency.View
-	telemUsage   *dztelemusage.View
-	sol          *sol.View
-	geoip        *mcpgeoip.View
-	isisSource   isis.Source
+	svc              *dzsvc.View
+	graphStore       *dzgraph.Store
+	telemLatency     *dztelemlatency.View
+	telemUsage       *dztelemusage.View
+	sol              *sol.View
+	geoip            *mcpgeoip.View
+	isisSource       isis.Source
+	isisLSDB         *isis.LSDBWriter
+	isisAdjEvents    *isis.AdjacencyEventWriter
+	isisSyncState    *isis.SyncStateStore
+	isisGraphWriters *semaphore.Weighted
 
 	startedAt time.Time
+
+	graphSyncedAtMu sync.RWMutex
+	lastGraphSyncAt time.Time // last successful graph or IS-IS sync into Neo4j
 }
 
 func New(ctx context.Context, cfg Config) (*Indexer, error) {
@@ -167,8 +187,11 @@ func New(ctx context.Context, cfg Config) (*Indexer, error) {
 		}
 	}
 
-	// Initialize ISIS source if enabled
+	// Initialize ISIS source, LSDB history writer and adjacency event writer if enabled
 	var isisSource isis.Source
+	var isisLSDB *isis.LSDBWriter
+	var isisAdjEvents *isis.AdjacencyEventWriter
+	var isisSyncState *isis.SyncStateStore
 	if cfg.ISISEnabled {
 		isisSource, err = isis.NewS3Source(ctx, isis.S3SourceConfig{
 			Bucket:      cfg.ISISS3Bucket,
@@ -181,19 +204,47 @@ func New(ctx context.Context, cfg Config) (*Indexer, error) {
 		cfg.Logger.Info("ISIS S3 source initialized",
 			"bucket", cfg.ISISS3Bucket,
 			"region", cfg.ISISS3Region)
+
+		isisLSDB, err = isis.NewLSDBWriter(isis.LSDBWriterConfig{
+			Logger:     cfg.Logger,
+			ClickHouse: cfg.ClickHouse,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ISIS LSDB writer: %w", err)
+		}
+
+		isisAdjEvents, err = isis.NewAdjacencyEventWriter(isis.AdjacencyEventWriterConfig{
+			Logger:     cfg.Logger,
+			ClickHouse: cfg.ClickHouse,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ISIS adjacency event writer: %w", err)
+		}
+
+		isisSyncState, err = isis.NewSyncStateStore(isis.SyncStateStoreConfig{
+			Logger:     cfg.Logger,
+			ClickHouse: cfg.ClickHouse,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ISIS sync state store: %w", err)
+		}
 	}
 
 	i := &Indexer{
 		log: cfg.Logger,
 		cfg: cfg,
 
-		svc:          svcView,
-		graphStore:   graphStore,
-		telemLatency: telemView,
-		telemUsage:   telemetryUsageView,
-		sol:          solanaView,
-		geoip:        geoipView,
-		isisSource:   isisSource,
+		svc:              svcView,
+		graphStore:       graphStore,
+		telemLatency:     telemView,
+		telemUsage:       telemetryUsageView,
+		sol:              solanaView,
+		geoip:            geoipView,
+		isisSource:       isisSource,
+		isisLSDB:         isisLSDB,
+		isisAdjEvents:    isisAdjEvents,
+		isisSyncState:    isisSyncState,
+		isisGraphWriters: semaphore.NewWeighted(isisMaxGraphWriters),
 	}
 
 	return i, nil
@@ -255,6 +306,7 @@ func (i *Indexer) startGraphSync(ctx context.Context) {
 	if err := i.doGraphSync(ctx); err != nil {
 		i.log.Error("graph_sync: initial sync failed", "error", err)
 	} else {
+		i.setGraphSyncedAt(i.cfg.Clock.Now())
 		i.log.Info("graph_sync: initial sync completed")
 	}
 
@@ -268,6 +320,8 @@ func (i *Indexer) startGraphSync(ctx context.Context) {
 		case <-ticker.Chan():
 			if err := i.doGraphSync(ctx); err != nil {
 				i.log.Error("graph_sync: sync failed", "error", err)
+			} else {
+				i.setGraphSyncedAt(i.cfg.Clock.Now())
 			}
 		}
 	}
@@ -285,27 +339,106 @@ func (i *Indexer) doGraphSync(ctx context.Context) error {
 			// Fall back to sync without ISIS data
 			return i.graphStore.Sync(ctx)
 		}
-		return i.graphStore.SyncWithISIS(ctx, lsps)
+		if err := i.graphStore.SyncWithISIS(ctx, lsps); err != nil {
+			return err
+		}
+		i.recordISISAdjacencyEvents(ctx)
+		return nil
 	}
 	// No ISIS source configured, just sync the base graph
 	return i.graphStore.Sync(ctx)
 }
 
-// fetchISISData fetches and parses ISIS data from the source.
+// fetchISISData fetches and parses ISIS data from the source, and records the
+// LSDB snapshot in ClickHouse. A failed LSDB write is logged but doesn't fail the fetch.
+// For a multi-file source, the latest dump of every file series is fetched in
+// parallel and their LSPs combined.
 func (i *Indexer) fetchISISData(ctx context.Context) ([]isis.LSP, error) {
+	if src, ok := i.isisSource.(isis.MultiSource); ok {
+		return i.fetchISISObjects(ctx, src)
+	}
+
 	dump, err := i.isisSource.FetchLatest(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch ISIS dump: %w", err)
 	}
+	return i.parseISISDump(ctx, dump)
+}
+
+// fetchISISObjects fetches and parses the latest object under each prefix of a
+// multi-file source.
+func (i *Indexer) fetchISISObjects(ctx context.Context, src isis.MultiSource) ([]isis.LSP, error) {
+	objects, err := src.LatestObjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ISIS dumps: %w", err)
+	}
+
+	results := make([][]isis.LSP, len(objects))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(i.cfg.ISISMaxConcurrency)
+	for idx, obj := range objects {
+		g.Go(func() error {
+			dump, err := src.Fetch(gctx, obj.Key)
+			if err != nil {
+				return fmt.Errorf("failed to fetch ISIS dump: %w", err)
+			}
+			lsps, err := i.parseISISDump(gctx, dump)
+			if err != nil {
+				return err
+			}
+			results[idx] = lsps
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var lsps []isis.LSP
+	for _, r := range results {
+		lsps = append(lsps, r...)
+	}
+	return lsps, nil
+}
+
+// parseISISDump parses a dump and records its LSDB snapshot.
+func (i *Indexer) parseISISDump(ctx context.Context, dump *isis.Dump) ([]isis.LSP, error) {
+	i.log.Debug("isis_sync: parsing dump", "file", dump.FileName, "size", len(dump.RawJSON))
 
 	lsps, err := isis.Parse(dump.RawJSON)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ISIS dump: %w", err)
+		return nil, fmt.Errorf("failed to parse ISIS dump %s: %w", dump.FileName, err)
+	}
+
+	if i.isisLSDB != nil {
+		if err := i.isisLSDB.Write(ctx, dump, lsps); err != nil {
+			i.log.Warn("isis_sync: failed to write LSDB history", "file", dump.FileName, "error", err)
+		}
 	}
 
 	return lsps, nil
 }
 
+// recordISISAdjacencyEvents writes adjacency up/down events for the ISIS_ADJACENT
+// relationships that changed in the last sync. Failures are logged but don't fail the sync.
+func (i *Indexer) recordISISAdjacencyEvents(ctx context.Context) {
+	if i.isisAdjEvents == nil {
+		return
+	}
+	_, graphAdjs, err := i.graphStore.ISISTopology(ctx)
+	if err != nil {
+		i.log.Warn("isis_sync: failed to read ISIS adjacencies", "error", err)
+		return
+	}
+	adjs := make([]isis.Adjacency, 0, len(graphAdjs))
+	for _, a := range graphAdjs {
+		adjs = append(adjs, isis.Adjacency{FromPK: a.FromDevicePK, ToPK: a.ToDevicePK, Metric: a.Metric})
+	}
+	if err := i.isisAdjEvents.Write(ctx, i.cfg.Clock.Now(), adjs); err != nil {
+		i.log.Warn("isis_sync: failed to write adjacency events", "error", err)
+	}
+}
+
 func (i *Indexer) Close() error {
 	var errs []error
 	if i.isisSource != nil {
@@ -335,7 +468,7 @@ func (i *Indexer) startISISSync(ctx context.Context) {
 	// Determine refresh interval
 	refreshInterval := i.cfg.ISISRefreshInterval
 	if refreshInterval <= 0 {
-		refreshInterval = 30 * time.Second
+		refreshInterval = defaultISISRefreshInterval
 	}
 
 	// Periodic sync only - initial sync is handled atomically by graph sync
@@ -348,6 +481,8 @@ func (i *Indexer) startISISSync(ctx context.Context) {
 		case <-ticker.Chan():
 			if err := i.doISISSync(ctx); err != nil {
 				i.log.Error("isis_sync: sync failed", "error", err)
+			} else {
+				i.setGraphSyncedAt(i.cfg.Clock.Now())
 			}
 		}
 	}
@@ -355,20 +490,16 @@ func (i *Indexer) startISISSync(ctx context.Context) {
 
 // doISISSync performs a single IS-IS sync operation.
 func (i *Indexer) doISISSync(ctx context.Context) error {
-	i.log.Debug("isis_sync: fetching latest dump")
-
-	// Fetch the latest IS-IS dump from S3
-	dump, err := i.isisSource.FetchLatest(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to fetch ISIS dump: %w", err)
+	if src, ok := i.isisSource.(isis.MultiSource); ok {
+		return i.syncISISObjects(ctx, src)
 	}
 
-	i.log.Debug("isis_sync: parsing dump", "file", dump.FileName, "size", len(dump.RawJSON))
+	i.log.Debug("isis_sync: fetching latest dump")
 
-	// Parse the dump
-	lsps, err := isis.Parse(dump.RawJSON)
+	// Fetch and parse the latest IS-IS dump from S3
+	lsps, err := i.fetchISISData(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to parse ISIS dump: %w", err)
+		return err
 	}
 
 	i.log.Debug("isis_sync: syncing to Neo4j", "lsps", len(lsps))
@@ -381,6 +512,80 @@ func (i *Indexer) doISISSync(ctx context.Context) error {
 	return nil
 }
 
+// syncISISObjects syncs the latest object of each file series that changed
+// since it was last synced, up to ISISMaxConcurrency at a time. One file
+// failing doesn't stop the others; it is retried on the next sync.
+func (i *Indexer) syncISISObjects(ctx context.Context, src isis.MultiSource) error {
+	objects, err := src.LatestObjects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list ISIS dumps: %w", err)
+	}
+	state, err := i.isisSyncState.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load ISIS sync state: %w", err)
+	}
+
+	changed := isis.Changed(objects, state)
+	if len(changed) == 0 {
+		i.log.Debug("isis_sync: no changed dumps", "files", len(objects))
+		return nil
+	}
+	i.log.Debug("isis_sync: syncing changed dumps", "changed", len(changed), "files", len(objects))
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		g    errgroup.Group
+	)
+	g.SetLimit(i.cfg.ISISMaxConcurrency)
+	for _, obj := range changed {
+		g.Go(func() error {
+			if err := i.syncISISObject(ctx, src, obj); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", obj.Key, err))
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return errors.Join(errs...)
+}
+
+// syncISISObject fetches, parses and syncs one dump file into the graph, then
+// records it as synced.
+func (i *Indexer) syncISISObject(ctx context.Context, src isis.MultiSource, obj isis.Object) error {
+	start := i.cfg.Clock.Now()
+	err := func() error {
+		dump, err := src.Fetch(ctx, obj.Key)
+		if err != nil {
+			return err
+		}
+		lsps, err := i.parseISISDump(ctx, dump)
+		if err != nil {
+			return err
+		}
+
+		if err := i.isisGraphWriters.Acquire(ctx, 1); err != nil {
+			return err
+		}
+		err = i.graphStore.SyncISIS(ctx, lsps)
+		i.isisGraphWriters.Release(1)
+		if err != nil {
+			return fmt.Errorf("failed to sync ISIS to graph: %w", err)
+		}
+
+		return i.isisSyncState.Record(ctx, obj, i.cfg.Clock.Now(), i.cfg.Clock.Since(start))
+	}()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	metrics.ISISFileSyncDuration.WithLabelValues(isis.ObjectPrefix(obj.Key), status).Observe(i.cfg.Clock.Since(start).Seconds())
+	return err
+}
+
 // GraphStore returns the Neo4j graph store, or nil if Neo4j is not configured.
 func (i *Indexer) GraphStore() *dzgraph.Store {
 	return i.graphStore
@@ -47,6 +47,15 @@ var (
 		},
 	)
 
+	ISISFileSyncDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "doublezero_data_indexer_isis_file_sync_duration_seconds",
+			Help:    "Duration of fetching, parsing and syncing one IS-IS dump file",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12), // 50ms to ~102s
+		},
+		[]string{"prefix", "status"},
+	)
+
 	MaintenanceOperationTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "doublezero_data_indexer_maintenance_operation_total",
@@ -71,4 +80,18 @@ var (
 		},
 		[]string{"operation_type", "status"},
 	)
+
+	ClickHouseCircuitBreakerOpenTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "doublezero_data_indexer_ch_circuit_breaker_open_total",
+			Help: "Total number of ClickHouse batch inserts dropped because the circuit breaker was open",
+		},
+	)
+
+	ClickHouseInsertBacklogSize = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "doublezero_data_indexer_ch_insert_backlog_size",
+			Help: "Number of ClickHouse batch inserts prepared and waiting to be sent",
+		},
+	)
 )
@@ -43,6 +43,7 @@ func New(ctx context.Context, cfg Config) (*Server, error) {
 		}
 	}))
 	mux.Handle("/readyz", http.HandlerFunc(s.readyzHandler))
+	mux.Handle("/health", http.HandlerFunc(s.healthHandler))
 	mux.Handle("/version", http.HandlerFunc(s.versionHandler))
 
 	s.httpSrv = &http.Server{
@@ -107,6 +108,22 @@ func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// healthHandler reports the freshness of each data source. It responds 503
+// when any component is down so it can back a readiness probe.
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	health := s.indexer.Health()
+	status := http.StatusOK
+	if health.Status == indexer.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		s.log.Error("failed to write health response", "error", err)
+	}
+}
+
 func (s *Server) versionHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
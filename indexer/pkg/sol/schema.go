@@ -298,14 +298,81 @@ func (s *BlockProductionSchema) ToRow(entry BlockProductionEntry, ingestedAt tim
 	}
 }
 
+// ValidatorStakeHistorySchema defines the schema for the per-epoch validator stake fact table
+type ValidatorStakeHistorySchema struct{}
+
+func (s *ValidatorStakeHistorySchema) Name() string {
+	return "sol_validator_stake_history"
+}
+
+func (s *ValidatorStakeHistorySchema) UniqueKeyColumns() []string {
+	return []string{"vote_pubkey", "epoch"}
+}
+
+func (s *ValidatorStakeHistorySchema) Columns() []string {
+	return []string{
+		"epoch:BIGINT",
+		"ingested_at:TIMESTAMP",
+		"vote_pubkey:VARCHAR",
+		"node_pubkey:VARCHAR",
+		"activated_stake_lamports:BIGINT",
+		"deactivating_stake_lamports:BIGINT",
+		"stake_share_pct:DOUBLE",
+		"stake_rank:INTEGER",
+	}
+}
+
+func (s *ValidatorStakeHistorySchema) TimeColumn() string {
+	return "epoch_ts"
+}
+
+func (s *ValidatorStakeHistorySchema) PartitionByTime() bool {
+	return false
+}
+
+func (s *ValidatorStakeHistorySchema) Grain() string {
+	return "1 epoch"
+}
+
+func (s *ValidatorStakeHistorySchema) DedupMode() dataset.DedupMode {
+	return dataset.DedupReplacing
+}
+
+func (s *ValidatorStakeHistorySchema) DedupVersionColumn() string {
+	return "ingested_at"
+}
+
+func (s *ValidatorStakeHistorySchema) ToRow(entry ValidatorStakeEntry, ingestedAt time.Time) []any {
+	var deactivatingStake any
+	if entry.DeactivatingStakeLamports != nil {
+		deactivatingStake = int64(*entry.DeactivatingStakeLamports)
+	}
+	return []any{
+		int64(entry.Epoch),                  // epoch
+		entry.EpochTime.UTC(),               // epoch_ts
+		ingestedAt,                          // ingested_at
+		entry.VotePubkey,                    // vote_pubkey
+		entry.NodePubkey,                    // node_pubkey
+		int64(entry.ActivatedStakeLamports), // activated_stake_lamports
+		deactivatingStake,                   // deactivating_stake_lamports (nullable)
+		entry.StakeSharePct,                 // stake_share_pct
+		entry.Rank,                          // stake_rank
+	}
+}
+
 var (
 	leaderScheduleSchema      = &LeaderScheduleSchema{}
 	voteAccountSchema         = &VoteAccountSchema{}
 	gossipNodeSchema          = &GossipNodeSchema{}
 	voteAccountActivitySchema = &VoteAccountActivitySchema{}
 	blockProductionSchema     = &BlockProductionSchema{}
+	validatorStakeSchema      = &ValidatorStakeHistorySchema{}
 )
 
+func NewValidatorStakeHistoryDataset(log *slog.Logger) (*dataset.FactDataset, error) {
+	return dataset.NewFactDataset(log, validatorStakeSchema)
+}
+
 func NewBlockProductionDataset(log *slog.Logger) (*dataset.FactDataset, error) {
 	return dataset.NewFactDataset(log, blockProductionSchema)
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"sort"
 	"strings"
 	"time"
 
@@ -388,3 +389,71 @@ func (s *Store) InsertBlockProduction(ctx context.Context, entries []BlockProduc
 
 	return nil
 }
+
+// ValidatorStakeEntry is a validator's stake for one epoch
+type ValidatorStakeEntry struct {
+	Epoch                     uint64
+	EpochTime                 time.Time // estimated epoch start
+	VotePubkey                string
+	NodePubkey                string
+	ActivatedStakeLamports    uint64
+	DeactivatingStakeLamports *uint64 // nil when not reported by the source
+	StakeSharePct             float64
+	Rank                      uint32 // 1 = most stake
+}
+
+// BuildValidatorStakeEntries ranks epoch vote accounts with activated stake by stake
+// (ties broken by vote pubkey) and computes each one's share of the total.
+func BuildValidatorStakeEntries(accounts []solanarpc.VoteAccountsResult, epoch uint64, epochTime time.Time) []ValidatorStakeEntry {
+	entries := make([]ValidatorStakeEntry, 0, len(accounts))
+	var total uint64
+	for _, a := range accounts {
+		if !a.EpochVoteAccount || a.ActivatedStake == 0 {
+			continue
+		}
+		total += a.ActivatedStake
+		entries = append(entries, ValidatorStakeEntry{
+			Epoch:                  epoch,
+			EpochTime:              epochTime,
+			VotePubkey:             a.VotePubkey.String(),
+			NodePubkey:             a.NodePubkey.String(),
+			ActivatedStakeLamports: a.ActivatedStake,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ActivatedStakeLamports != entries[j].ActivatedStakeLamports {
+			return entries[i].ActivatedStakeLamports > entries[j].ActivatedStakeLamports
+		}
+		return entries[i].VotePubkey < entries[j].VotePubkey
+	})
+	for i := range entries {
+		entries[i].Rank = uint32(i + 1)
+		entries[i].StakeSharePct = float64(entries[i].ActivatedStakeLamports) * 100 / float64(total)
+	}
+	return entries
+}
+
+func (s *Store) InsertValidatorStakeHistory(ctx context.Context, entries []ValidatorStakeEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	s.log.Debug("solana/store: inserting validator stake history", "count", len(entries))
+
+	ingestedAt := time.Now().UTC()
+	conn, err := s.cfg.ClickHouse.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get ClickHouse connection: %w", err)
+	}
+	ds, err := NewValidatorStakeHistoryDataset(s.log)
+	if err != nil {
+		return fmt.Errorf("failed to create fact dataset: %w", err)
+	}
+	if err := ds.WriteBatch(ctx, conn, len(entries), func(i int) ([]any, error) {
+		return validatorStakeSchema.ToRow(entries[i], ingestedAt), nil
+	}); err != nil {
+		return fmt.Errorf("failed to write validator stake history to ClickHouse: %w", err)
+	}
+
+	return nil
+}
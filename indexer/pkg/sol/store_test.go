@@ -543,3 +543,83 @@ func TestLake_Solana_Store_GetGossipIPs(t *testing.T) {
 		conn.Close()
 	})
 }
+
+func TestLake_Solana_Store_BuildValidatorStakeEntries(t *testing.T) {
+	t.Parallel()
+
+	voteA := solana.MustPublicKeyFromBase58("Vote111111111111111111111111111111111111111")
+	voteB := solana.MustPublicKeyFromBase58("Vote222222222222222222222222222222222222222")
+	voteC := solana.MustPublicKeyFromBase58("11111111111111111111111111111112")
+	epochTime := time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC)
+
+	entries := BuildValidatorStakeEntries([]solanarpc.VoteAccountsResult{
+		{VotePubkey: voteA, ActivatedStake: 1_000, EpochVoteAccount: true},
+		{VotePubkey: voteB, ActivatedStake: 3_000, EpochVoteAccount: true},
+		{VotePubkey: voteC, ActivatedStake: 0, EpochVoteAccount: true},      // no stake
+		{VotePubkey: voteC, ActivatedStake: 5_000, EpochVoteAccount: false}, // not voting this epoch
+	}, 700, epochTime)
+
+	require.Len(t, entries, 2)
+	require.Equal(t, voteB.String(), entries[0].VotePubkey)
+	require.Equal(t, uint32(1), entries[0].Rank)
+	require.InDelta(t, 75.0, entries[0].StakeSharePct, 1e-9)
+	require.Equal(t, voteA.String(), entries[1].VotePubkey)
+	require.Equal(t, uint32(2), entries[1].Rank)
+	require.InDelta(t, 25.0, entries[1].StakeSharePct, 1e-9)
+	require.Equal(t, uint64(700), entries[1].Epoch)
+	require.Equal(t, epochTime, entries[1].EpochTime)
+	require.Nil(t, entries[1].DeactivatingStakeLamports)
+}
+
+func TestLake_Solana_Store_InsertValidatorStakeHistory(t *testing.T) {
+	t.Parallel()
+
+	db := testClient(t)
+	store, err := NewStore(StoreConfig{
+		Logger:     laketesting.NewLogger(),
+		ClickHouse: db,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	votePK := "Vote111111111111111111111111111111111111111"
+	epochTime := time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC)
+	entry := ValidatorStakeEntry{
+		Epoch:                  700,
+		EpochTime:              epochTime,
+		VotePubkey:             votePK,
+		NodePubkey:             "So11111111111111111111111111111111111111112",
+		ActivatedStakeLamports: 1_000_000_000,
+		StakeSharePct:          12.5,
+		Rank:                   3,
+	}
+	require.NoError(t, store.InsertValidatorStakeHistory(ctx, []ValidatorStakeEntry{entry}))
+	// Rewriting the same epoch (e.g. after a restart) replaces the row
+	entry.Rank = 4
+	require.NoError(t, store.InsertValidatorStakeHistory(ctx, []ValidatorStakeEntry{entry}))
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	rows, err := conn.Query(ctx, `
+		SELECT epoch, activated_stake_lamports, deactivating_stake_lamports, stake_share_pct, stake_rank
+		FROM fact_sol_validator_stake_history FINAL
+		WHERE vote_pubkey = ?
+	`, votePK)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	var epoch, stake int64
+	var deactivating *int64
+	var share float64
+	var rank uint32
+	require.NoError(t, rows.Scan(&epoch, &stake, &deactivating, &share, &rank))
+	require.Equal(t, int64(700), epoch)
+	require.Equal(t, int64(1_000_000_000), stake)
+	require.Nil(t, deactivating)
+	require.Equal(t, 12.5, share)
+	require.Equal(t, uint32(4), rank)
+	require.False(t, rows.Next())
+}
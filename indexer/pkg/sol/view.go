@@ -15,6 +15,9 @@ import (
 	"github.com/malbeclabs/lake/indexer/pkg/metrics"
 )
 
+// slotDuration is the target Solana slot time, used to estimate when an epoch started
+const slotDuration = 400 * time.Millisecond
+
 type SolanaRPC interface {
 	GetEpochInfo(ctx context.Context, commitment solanarpc.CommitmentType) (*solanarpc.GetEpochInfoResult, error)
 	GetLeaderSchedule(ctx context.Context) (solanarpc.GetLeaderScheduleResult, error)
@@ -60,8 +63,15 @@ type View struct {
 
 	fetchedAt time.Time
 
+	// Last epoch written to the validator stake history, valid once stakeHistoryWritten is set
+	stakeHistoryEpoch   uint64
+	stakeHistoryWritten bool
+
 	readyOnce sync.Once
 	readyCh   chan struct{}
+
+	refreshedAtMu sync.RWMutex
+	refreshedAt   time.Time
 }
 
 func NewView(
@@ -102,6 +112,14 @@ func (v *View) Ready() bool {
 	}
 }
 
+// LastRefreshedAt returns when the view last completed a successful refresh,
+// or the zero time if it hasn't yet.
+func (v *View) LastRefreshedAt() time.Time {
+	v.refreshedAtMu.RLock()
+	defer v.refreshedAtMu.RUnlock()
+	return v.refreshedAt
+}
+
 func (v *View) WaitReady(ctx context.Context) error {
 	select {
 	case <-v.readyCh:
@@ -238,6 +256,21 @@ func (v *View) Refresh(ctx context.Context) error {
 		return fmt.Errorf("failed to refresh vote accounts: %w", err)
 	}
 
+	// Record each validator's stake once per epoch
+	if !v.stakeHistoryWritten || v.stakeHistoryEpoch != currentEpoch {
+		epochStart := fetchedAt.Add(-time.Duration(epochInfo.SlotIndex) * slotDuration)
+		accounts := append(append([]solanarpc.VoteAccountsResult{}, voteAccounts.Current...), voteAccounts.Delinquent...)
+		entries := BuildValidatorStakeEntries(accounts, currentEpoch, epochStart)
+		v.log.Debug("solana: recording validator stake history", "epoch", currentEpoch, "count", len(entries))
+		if err := v.store.InsertValidatorStakeHistory(ctx, entries); err != nil {
+			// Retried on the next refresh; don't fail the entire refresh
+			v.log.Error("solana: failed to record validator stake history", "epoch", currentEpoch, "error", err)
+		} else {
+			v.stakeHistoryEpoch = currentEpoch
+			v.stakeHistoryWritten = true
+		}
+	}
+
 	v.log.Debug("solana: refreshing cluster nodes", "count", len(clusterNodes))
 	if err := v.store.ReplaceGossipNodes(ctx, clusterNodes, fetchedAt, currentEpoch); err != nil {
 		return fmt.Errorf("failed to refresh cluster nodes: %w", err)
@@ -254,6 +287,9 @@ func (v *View) Refresh(ctx context.Context) error {
 		close(v.readyCh)
 		v.log.Info("solana: view is now ready")
 	})
+	v.refreshedAtMu.Lock()
+	v.refreshedAt = v.cfg.Clock.Now()
+	v.refreshedAtMu.Unlock()
 
 	v.log.Debug("solana: refresh completed", "fetched_at", fetchedAt)
 	metrics.ViewRefreshTotal.WithLabelValues("solana", "success").Inc()
@@ -29,6 +29,9 @@ type Config struct {
 	// Web UI configuration
 	WebBaseURL string // Base URL for web UI (for session links)
 
+	// Slash command name (e.g. "/lake")
+	SlashCommand string
+
 	// Server configuration
 	HTTPAddr    string
 	MetricsAddr string
@@ -84,5 +87,11 @@ func LoadFromEnv(modeFlag, httpAddrFlag, metricsAddrFlag string, verbose, enable
 	// Load web UI configuration (optional)
 	cfg.WebBaseURL = os.Getenv("WEB_BASE_URL")
 
+	// Load slash command name (optional)
+	cfg.SlashCommand = os.Getenv("SLACK_SLASH_COMMAND")
+	if cfg.SlashCommand == "" {
+		cfg.SlashCommand = DefaultSlashCommand
+	}
+
 	return cfg, nil
 }
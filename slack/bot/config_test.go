@@ -14,6 +14,7 @@ func TestAI_Slack_LoadFromEnv(t *testing.T) {
 		"SLACK_BOT_TOKEN",
 		"SLACK_APP_TOKEN",
 		"SLACK_SIGNING_SECRET",
+		"SLACK_SLASH_COMMAND",
 	}
 
 	for _, key := range envVars {
@@ -67,6 +68,19 @@ func TestAI_Slack_LoadFromEnv(t *testing.T) {
 			checkConfig: func(t *testing.T, cfg *Config) {
 				require.Equal(t, ModeHTTP, cfg.Mode)
 				require.Equal(t, "secret", cfg.SigningSecret)
+				require.Equal(t, DefaultSlashCommand, cfg.SlashCommand)
+			},
+		},
+		{
+			name: "custom slash command",
+			setupEnv: func() {
+				os.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+				os.Setenv("SLACK_SIGNING_SECRET", "secret")
+				os.Setenv("SLACK_SLASH_COMMAND", "/lake-dev")
+			},
+			modeFlag: "http",
+			checkConfig: func(t *testing.T, cfg *Config) {
+				require.Equal(t, "/lake-dev", cfg.SlashCommand)
 			},
 		},
 		{
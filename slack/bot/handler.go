@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/json"
@@ -13,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
 )
@@ -45,6 +47,13 @@ type EventHandler struct {
 	signingSecret string          // used in multi-tenant HTTP mode
 	shutdownCtx   context.Context // Main shutdown context for graceful cancellation
 
+	// Slash command handling (disabled unless SetSlashCommand is called)
+	slashCommand string
+	statusLookup StatusLookup
+
+	// Maintenance approval buttons (disabled unless SetMaintenanceStore is called)
+	maintenanceStore MaintenanceStore
+
 	// Track processed events by envelope ID to avoid reprocessing duplicates
 	processedEvents   map[string]time.Time
 	processedEventsMu sync.RWMutex
@@ -449,6 +458,29 @@ func (h *EventHandler) HandleSocketMode(ctx context.Context, client *socketmode.
 				// The WaitGroup handles graceful shutdown coordination.
 				// Note: Socket mode is single-tenant only, so TeamID from event is used for routing.
 				h.HandleEvent(context.Background(), e, envelopeID)
+			case socketmode.EventTypeSlashCommand:
+				cmd, ok := evt.Data.(slack.SlashCommand)
+				if !ok {
+					h.log.Warn("socketmode: slash command data is not SlashCommand", "data_type", fmt.Sprintf("%T", evt.Data))
+					client.Ack(*evt.Request)
+					continue
+				}
+				// Slash command responses are sent as the ack payload
+				client.Ack(*evt.Request, h.handleSlashCommand(ctx, cmd))
+			case socketmode.EventTypeInteractive:
+				callback, ok := evt.Data.(slack.InteractionCallback)
+				client.Ack(*evt.Request)
+				if !ok {
+					h.log.Warn("socketmode: interactive data is not InteractionCallback", "data_type", fmt.Sprintf("%T", evt.Data))
+					continue
+				}
+				// Slack expects the ack within 3 seconds; the outcome is reported
+				// by replacing the original message
+				h.inFlightOps.Add(1)
+				go func() {
+					defer h.inFlightOps.Done()
+					h.handleMaintenanceActions(context.Background(), &callback)
+				}()
 			}
 		}
 	}
@@ -482,6 +514,22 @@ func (h *EventHandler) HandleHTTP(w http.ResponseWriter, r *http.Request, signin
 		return
 	}
 
+	// Slash commands are form-encoded rather than JSON events, and must be answered inline
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		cmd, err := slack.SlashCommandParse(r)
+		if err != nil {
+			h.log.Error("failed to parse slash command", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(h.handleSlashCommand(r.Context(), cmd)); err != nil {
+			h.log.Error("failed to write slash command response", "error", err)
+		}
+		return
+	}
+
 	// Handle URL verification challenge
 	var challengeResp struct {
 		Type      string `json:"type"`
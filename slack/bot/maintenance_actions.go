@@ -0,0 +1,214 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/maintenance"
+	"github.com/slack-go/slack"
+)
+
+// maintenanceActionTimeout bounds the database and Slack calls made for one button press
+const maintenanceActionTimeout = 10 * time.Second
+
+// MaintenanceStore applies on-call decisions to scheduled maintenance windows
+type MaintenanceStore interface {
+	Approve(ctx context.Context, windowID uuid.UUID, slackUserID string) (*maintenance.Window, error)
+	Defer(ctx context.Context, windowID uuid.UUID, d time.Duration) (*maintenance.Window, error)
+	Cancel(ctx context.Context, windowID uuid.UUID) (*maintenance.Window, error)
+	RequesterEmail(ctx context.Context, windowID uuid.UUID) (string, error)
+}
+
+// PostgresMaintenanceStore stores maintenance window decisions in PostgreSQL
+type PostgresMaintenanceStore struct{}
+
+// NewMaintenanceStore creates a MaintenanceStore backed by the API's PostgreSQL pool
+func NewMaintenanceStore() *PostgresMaintenanceStore {
+	return &PostgresMaintenanceStore{}
+}
+
+func (PostgresMaintenanceStore) Approve(ctx context.Context, windowID uuid.UUID, slackUserID string) (*maintenance.Window, error) {
+	return maintenance.Approve(ctx, windowID, slackUserID)
+}
+
+func (PostgresMaintenanceStore) Defer(ctx context.Context, windowID uuid.UUID, d time.Duration) (*maintenance.Window, error) {
+	return maintenance.Defer(ctx, windowID, d)
+}
+
+func (PostgresMaintenanceStore) Cancel(ctx context.Context, windowID uuid.UUID) (*maintenance.Window, error) {
+	return maintenance.Cancel(ctx, windowID)
+}
+
+func (PostgresMaintenanceStore) RequesterEmail(ctx context.Context, windowID uuid.UUID) (string, error) {
+	return maintenance.RequesterEmail(ctx, windowID)
+}
+
+// SetMaintenanceStore enables the maintenance approval buttons
+func (h *EventHandler) SetMaintenanceStore(store MaintenanceStore) {
+	h.maintenanceStore = store
+}
+
+// HandleMaintenanceActionCallback handles block_actions payloads from the
+// Approve, Defer and Cancel buttons on maintenance approval requests. Requests
+// are verified with the same signature check as HandleHTTP.
+func (h *EventHandler) HandleMaintenanceActionCallback(w http.ResponseWriter, r *http.Request, signingSecret string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log.Error("failed to read interaction body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !VerifySlackSignature(r, body, signingSecret) {
+		h.log.Warn("invalid Slack signature on interaction")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := r.ParseForm(); err != nil {
+		h.log.Error("failed to parse interaction form", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var callback slack.InteractionCallback
+	if err := callback.UnmarshalJSON([]byte(r.PostForm.Get("payload"))); err != nil {
+		h.log.Error("failed to parse interaction payload", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Slack only needs a 200; the outcome is reported by replacing the original message
+	w.WriteHeader(http.StatusOK)
+	h.handleMaintenanceActions(r.Context(), &callback)
+}
+
+// handleMaintenanceActions applies the maintenance button presses in an
+// interaction, which arrives either over HTTP or Socket Mode
+func (h *EventHandler) handleMaintenanceActions(ctx context.Context, callback *slack.InteractionCallback) {
+	if callback.Type != slack.InteractionTypeBlockActions || h.maintenanceStore == nil {
+		return
+	}
+	if !isTeamAllowed(callback.Team.ID) {
+		h.log.Warn("ignoring interaction from disallowed team", "team_id", callback.Team.ID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, maintenanceActionTimeout)
+	defer cancel()
+	for _, action := range callback.ActionCallback.BlockActions {
+		h.handleMaintenanceAction(ctx, callback, action)
+	}
+}
+
+// handleMaintenanceAction applies a single button press and reports the result
+func (h *EventHandler) handleMaintenanceAction(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) {
+	switch action.ActionID {
+	case maintenance.ActionApprove, maintenance.ActionDefer, maintenance.ActionCancel:
+	default:
+		return
+	}
+	log := h.log.With("action", action.ActionID, "window_id", action.Value, "user", callback.User.ID)
+
+	windowID, err := uuid.Parse(action.Value)
+	if err != nil {
+		log.Warn("maintenance action has invalid window ID")
+		return
+	}
+
+	var mw *maintenance.Window
+	switch action.ActionID {
+	case maintenance.ActionApprove:
+		mw, err = h.maintenanceStore.Approve(ctx, windowID, callback.User.ID)
+	case maintenance.ActionDefer:
+		mw, err = h.maintenanceStore.Defer(ctx, windowID, maintenance.DeferInterval)
+	case maintenance.ActionCancel:
+		mw, err = h.maintenanceStore.Cancel(ctx, windowID)
+	}
+	MaintenanceActionsTotal.WithLabelValues(action.ActionID, maintenanceActionResult(err)).Inc()
+
+	var text string
+	switch {
+	case errors.Is(err, maintenance.ErrWindowNotFound):
+		text = fmt.Sprintf(":warning: Maintenance window %s has ended or was cancelled.", windowID)
+	case err != nil:
+		log.Error("failed to apply maintenance action", "error", err)
+		text = fmt.Sprintf(":x: Failed to update maintenance window %s, please try again.", windowID)
+	default:
+		log.Info("maintenance action applied")
+		text = maintenanceActionSummary(action.ActionID, callback.User.ID, mw)
+		h.notifyMaintenanceRequester(ctx, callback.Team.ID, windowID, text)
+	}
+
+	if callback.ResponseURL == "" {
+		return
+	}
+	// Keep the buttons on transient failures so the action can be retried
+	msg := &slack.WebhookMessage{
+		Text:            text,
+		ReplaceOriginal: err == nil || errors.Is(err, maintenance.ErrWindowNotFound),
+	}
+	if err := slack.PostWebhookContext(ctx, callback.ResponseURL, msg); err != nil {
+		log.Warn("failed to update maintenance approval message", "error", err)
+	}
+}
+
+// notifyMaintenanceRequester DMs the person who scheduled the window, matched
+// to a Slack user by their account email
+func (h *EventHandler) notifyMaintenanceRequester(ctx context.Context, teamID string, windowID uuid.UUID, text string) {
+	client := h.resolveClient(ctx, teamID)
+	if client == nil {
+		return
+	}
+	email, err := h.maintenanceStore.RequesterEmail(ctx, windowID)
+	if err != nil || email == "" {
+		if err != nil {
+			h.log.Warn("failed to look up maintenance requester", "window_id", windowID, "error", err)
+		}
+		return
+	}
+	user, err := client.API().GetUserByEmailContext(ctx, email)
+	if err != nil {
+		h.log.Warn("maintenance requester not found in Slack", "window_id", windowID, "error", err)
+		return
+	}
+	if _, _, err := client.API().PostMessageContext(ctx, user.ID, slack.MsgOptionText(text, false)); err != nil {
+		h.log.Warn("failed to notify maintenance requester", "window_id", windowID, "user", user.ID, "error", err)
+	}
+}
+
+// maintenanceActionSummary describes an applied action for the channel and the requester
+func maintenanceActionSummary(actionID, userID string, mw *maintenance.Window) string {
+	switch actionID {
+	case maintenance.ActionApprove:
+		return fmt.Sprintf(":white_check_mark: <@%s> approved maintenance window %s (starts %s).",
+			userID, mw.ID, mw.StartAt.UTC().Format(time.RFC3339))
+	case maintenance.ActionDefer:
+		return fmt.Sprintf(":hourglass_flowing_sand: <@%s> deferred maintenance window %s by %d minutes; it now starts %s.",
+			userID, mw.ID, int(maintenance.DeferInterval.Minutes()), mw.StartAt.UTC().Format(time.RFC3339))
+	default:
+		return fmt.Sprintf(":no_entry_sign: <@%s> cancelled maintenance window %s.", userID, mw.ID)
+	}
+}
+
+// maintenanceActionResult is the metrics label for the outcome of an action
+func maintenanceActionResult(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, maintenance.ErrWindowNotFound):
+		return "not_found"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,209 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malbeclabs/lake/api/maintenance"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMaintenanceStore struct {
+	mu       sync.Mutex
+	calls    []string
+	deferBy  time.Duration
+	approver string
+	err      error
+}
+
+func (f *fakeMaintenanceStore) record(call string, id uuid.UUID) (*maintenance.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+	if f.err != nil {
+		return nil, f.err
+	}
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	return &maintenance.Window{ID: id, StartAt: start, EndAt: start.Add(time.Hour)}, nil
+}
+
+func (f *fakeMaintenanceStore) Approve(_ context.Context, id uuid.UUID, slackUserID string) (*maintenance.Window, error) {
+	f.approver = slackUserID
+	return f.record("approve", id)
+}
+
+func (f *fakeMaintenanceStore) Defer(_ context.Context, id uuid.UUID, d time.Duration) (*maintenance.Window, error) {
+	f.deferBy = d
+	return f.record("defer", id)
+}
+
+func (f *fakeMaintenanceStore) Cancel(_ context.Context, id uuid.UUID) (*maintenance.Window, error) {
+	return f.record("cancel", id)
+}
+
+func (f *fakeMaintenanceStore) RequesterEmail(context.Context, uuid.UUID) (string, error) {
+	return "", nil
+}
+
+func blockActionsForm(t *testing.T, responseURL, actionID, value string) url.Values {
+	t.Helper()
+	payload, err := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"team":         map[string]string{"id": "T123"},
+		"user":         map[string]string{"id": "U0ONCALL"},
+		"response_url": responseURL,
+		"actions": []map[string]string{
+			{"type": "button", "action_id": actionID, "block_id": "maintenance_actions", "value": value},
+		},
+	})
+	require.NoError(t, err)
+	return url.Values{"payload": {string(payload)}}
+}
+
+// webhookRecorder captures messages posted to an interaction's response_url
+func webhookRecorder(t *testing.T) (*httptest.Server, func() []slack.WebhookMessage) {
+	t.Helper()
+	var mu sync.Mutex
+	var msgs []slack.WebhookMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.WebhookMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		mu.Lock()
+		msgs = append(msgs, msg)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []slack.WebhookMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return msgs
+	}
+}
+
+func TestAI_Slack_MaintenanceActions(t *testing.T) {
+	t.Parallel()
+
+	windowID := uuid.New()
+	tests := []struct {
+		name     string
+		actionID string
+		wantCall string
+		wantText string
+	}{
+		{"approve", maintenance.ActionApprove, "approve", "approved maintenance window"},
+		{"defer", maintenance.ActionDefer, "defer", "deferred maintenance window " + windowID.String() + " by 30 minutes"},
+		{"cancel", maintenance.ActionCancel, "cancel", "cancelled maintenance window"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &fakeMaintenanceStore{}
+			h := NewEventHandler(nil, nil, nil, slog.Default(), "U123", context.Background())
+			h.SetMaintenanceStore(store)
+			srv, messages := webhookRecorder(t)
+
+			req := signedSlashCommandRequest(t, "secret", blockActionsForm(t, srv.URL, tt.actionID, windowID.String()))
+			rr := httptest.NewRecorder()
+			h.HandleMaintenanceActionCallback(rr, req, "secret")
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, []string{tt.wantCall}, store.calls)
+			require.Len(t, messages(), 1)
+			require.True(t, messages()[0].ReplaceOriginal)
+			require.Contains(t, messages()[0].Text, "<@U0ONCALL>")
+			require.Contains(t, messages()[0].Text, tt.wantText)
+		})
+	}
+}
+
+func TestAI_Slack_MaintenanceActions_DeferInterval(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeMaintenanceStore{}
+	h := NewEventHandler(nil, nil, nil, slog.Default(), "U123", context.Background())
+	h.SetMaintenanceStore(store)
+
+	req := signedSlashCommandRequest(t, "secret", blockActionsForm(t, "", maintenance.ActionDefer, uuid.NewString()))
+	h.HandleMaintenanceActionCallback(httptest.NewRecorder(), req, "secret")
+	require.Equal(t, 30*time.Minute, store.deferBy)
+
+	req = signedSlashCommandRequest(t, "secret", blockActionsForm(t, "", maintenance.ActionApprove, uuid.NewString()))
+	h.HandleMaintenanceActionCallback(httptest.NewRecorder(), req, "secret")
+	require.Equal(t, "U0ONCALL", store.approver)
+}
+
+func TestAI_Slack_MaintenanceActions_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("store failure keeps the buttons", func(t *testing.T) {
+		t.Parallel()
+		store := &fakeMaintenanceStore{err: errors.New("connection refused")}
+		h := NewEventHandler(nil, nil, nil, slog.Default(), "U123", context.Background())
+		h.SetMaintenanceStore(store)
+		srv, messages := webhookRecorder(t)
+
+		req := signedSlashCommandRequest(t, "secret", blockActionsForm(t, srv.URL, maintenance.ActionApprove, uuid.NewString()))
+		h.HandleMaintenanceActionCallback(httptest.NewRecorder(), req, "secret")
+
+		require.Len(t, messages(), 1)
+		require.False(t, messages()[0].ReplaceOriginal)
+		require.Contains(t, messages()[0].Text, "Failed to update")
+	})
+
+	t.Run("window gone", func(t *testing.T) {
+		t.Parallel()
+		store := &fakeMaintenanceStore{err: maintenance.ErrWindowNotFound}
+		h := NewEventHandler(nil, nil, nil, slog.Default(), "U123", context.Background())
+		h.SetMaintenanceStore(store)
+		srv, messages := webhookRecorder(t)
+
+		req := signedSlashCommandRequest(t, "secret", blockActionsForm(t, srv.URL, maintenance.ActionCancel, uuid.NewString()))
+		h.HandleMaintenanceActionCallback(httptest.NewRecorder(), req, "secret")
+
+		require.Len(t, messages(), 1)
+		require.True(t, messages()[0].ReplaceOriginal)
+		require.Contains(t, messages()[0].Text, "has ended or was cancelled")
+	})
+
+	t.Run("unknown action and invalid window ID are ignored", func(t *testing.T) {
+		t.Parallel()
+		store := &fakeMaintenanceStore{}
+		h := NewEventHandler(nil, nil, nil, slog.Default(), "U123", context.Background())
+		h.SetMaintenanceStore(store)
+
+		for _, form := range []url.Values{
+			blockActionsForm(t, "", "some_other_button", uuid.NewString()),
+			blockActionsForm(t, "", maintenance.ActionApprove, "not-a-uuid"),
+		} {
+			rr := httptest.NewRecorder()
+			h.HandleMaintenanceActionCallback(rr, signedSlashCommandRequest(t, "secret", form), "secret")
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		require.Empty(t, store.calls)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+		store := &fakeMaintenanceStore{}
+		h := NewEventHandler(nil, nil, nil, slog.Default(), "U123", context.Background())
+		h.SetMaintenanceStore(store)
+
+		req := signedSlashCommandRequest(t, "wrong-secret", blockActionsForm(t, "", maintenance.ActionApprove, uuid.NewString()))
+		rr := httptest.NewRecorder()
+		h.HandleMaintenanceActionCallback(rr, req, "secret")
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		require.Empty(t, store.calls)
+	})
+}
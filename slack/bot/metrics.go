@@ -94,6 +94,22 @@ var (
 		},
 	)
 
+	SlashCommandsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "doublezero_ai_slack_slash_commands_total",
+			Help: "Total number of slash commands received",
+		},
+		[]string{"subcommand"},
+	)
+
+	MaintenanceActionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "doublezero_ai_slack_maintenance_actions_total",
+			Help: "Total number of maintenance approval button presses",
+		},
+		[]string{"action", "result"},
+	)
+
 	ActiveConversations = promauto.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "doublezero_ai_slack_active_conversations",
@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// DefaultSlashCommand is the slash command name used when SLACK_SLASH_COMMAND is unset
+	DefaultSlashCommand = "/lake"
+
+	// Slack drops slash command responses that take longer than 3 seconds
+	slashCommandTimeout = 2500 * time.Millisecond
+)
+
+// SetSlashCommand enables the slash command with the given name (e.g. "/lake"),
+// using lookup to resolve entity status.
+func (h *EventHandler) SetSlashCommand(command string, lookup StatusLookup) {
+	h.slashCommand = command
+	h.statusLookup = lookup
+}
+
+// parseSlashCommandText splits slash command text into a lowercased subcommand and its arguments
+func parseSlashCommandText(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return strings.ToLower(fields[0]), fields[1:]
+}
+
+// slashCommandUsage returns the help text for the slash command
+func slashCommandUsage(command string) string {
+	return fmt.Sprintf("Usage: `%s status <device-or-link-code>`", command)
+}
+
+// ephemeralMessage builds a slash command reply visible only to the invoking user
+func ephemeralMessage(text string) *slack.Msg {
+	return &slack.Msg{
+		ResponseType: slack.ResponseTypeEphemeral,
+		Text:         text,
+	}
+}
+
+// handleSlashCommand runs a slash command and returns the message to reply with
+func (h *EventHandler) handleSlashCommand(ctx context.Context, cmd slack.SlashCommand) *slack.Msg {
+	h.log.Info("slash command received", "command", cmd.Command, "text", cmd.Text, "user", cmd.UserID, "channel", cmd.ChannelID, "team_id", cmd.TeamID)
+
+	if !isTeamAllowed(cmd.TeamID) {
+		h.log.Warn("ignoring slash command from disallowed team", "team_id", cmd.TeamID)
+		SlashCommandsTotal.WithLabelValues("disallowed").Inc()
+		return ephemeralMessage("This command is not available in this workspace.")
+	}
+	if h.slashCommand == "" || cmd.Command != h.slashCommand {
+		SlashCommandsTotal.WithLabelValues("unknown").Inc()
+		return ephemeralMessage(fmt.Sprintf("Unknown command `%s`.", cmd.Command))
+	}
+
+	subcommand, args := parseSlashCommandText(cmd.Text)
+	switch subcommand {
+	case "status":
+		SlashCommandsTotal.WithLabelValues("status").Inc()
+		if len(args) != 1 {
+			return ephemeralMessage(slashCommandUsage(h.slashCommand))
+		}
+		return h.handleStatusCommand(ctx, args[0])
+	default:
+		SlashCommandsTotal.WithLabelValues("help").Inc()
+		return ephemeralMessage(slashCommandUsage(h.slashCommand))
+	}
+}
+
+// handleStatusCommand looks up a device or link and renders its status
+func (h *EventHandler) handleStatusCommand(ctx context.Context, code string) *slack.Msg {
+	if h.statusLookup == nil {
+		return ephemeralMessage("Status lookups are not configured.")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, slashCommandTimeout)
+	defer cancel()
+
+	status, err := h.statusLookup.LookupStatus(ctx, code)
+	if errors.Is(err, ErrEntityNotFound) {
+		return ephemeralMessage(fmt.Sprintf("No device or link found with code `%s`.", code))
+	}
+	if err != nil {
+		h.log.Error("slash command status lookup failed", "code", code, "error", err)
+		return ephemeralMessage("Sorry, I couldn't look up that status right now. Please try again.")
+	}
+
+	return &slack.Msg{
+		ResponseType: slack.ResponseTypeInChannel,
+		Text:         fmt.Sprintf("%s %s is %s", status.Kind, status.Code, status.Status),
+		Blocks:       slack.Blocks{BlockSet: buildStatusBlocks(status)},
+	}
+}
+
+// statusEmoji returns the badge emoji for a device or link status
+func statusEmoji(status string) string {
+	switch status {
+	case "activated":
+		return ":large_green_circle:"
+	case "soft-drained", "drained", "pending":
+		return ":large_yellow_circle:"
+	case "hard-drained", "suspended", "deleted", "rejected":
+		return ":red_circle:"
+	default:
+		return ":white_circle:"
+	}
+}
+
+// buildStatusBlocks renders an entity status as Block Kit blocks
+func buildStatusBlocks(s *EntityStatus) []slack.Block {
+	summary := slack.NewTextBlockObject(slack.MarkdownType,
+		fmt.Sprintf("%s *%s* is *%s*", statusEmoji(s.Status), s.Code, s.Status), false, false)
+
+	uptime := "no history"
+	if s.HasHistory {
+		uptime = fmt.Sprintf("%.2f%%", s.UptimePct)
+	}
+	lastChange := "none in last 30 days"
+	if !s.LastChange.IsZero() {
+		lastChange = fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", s.LastChange.Unix(), s.LastChange.UTC().Format(time.RFC3339))
+	}
+	location := s.Location
+	if location == "" {
+		location = "unknown"
+	}
+	locationLabel := "Metro"
+	if s.Kind == "link" {
+		locationLabel = "Endpoints"
+	}
+
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject(slack.MarkdownType, "*Type*\n"+s.Kind, false, false),
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*\n%s", locationLabel, location), false, false),
+		slack.NewTextBlockObject(slack.MarkdownType, "*Uptime (30d)*\n"+uptime, false, false),
+		slack.NewTextBlockObject(slack.MarkdownType, "*Last change*\n"+lastChange, false, false),
+	}
+
+	return []slack.Block{
+		slack.NewSectionBlock(summary, nil, nil, slack.SectionBlockOptionBlockID("status_summary")),
+		slack.NewSectionBlock(nil, fields, nil, slack.SectionBlockOptionBlockID("status_details")),
+		slack.NewContextBlock("status_context",
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("PK `%s`", s.PK), false, false)),
+	}
+}
@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStatusLookup struct {
+	statuses map[string]*EntityStatus
+	err      error
+}
+
+func (f *fakeStatusLookup) LookupStatus(_ context.Context, code string) (*EntityStatus, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	s, ok := f.statuses[code]
+	if !ok {
+		return nil, ErrEntityNotFound
+	}
+	return s, nil
+}
+
+func newSlashCommandTestHandler(lookup StatusLookup) *EventHandler {
+	h := NewEventHandler(nil, nil, nil, slog.Default(), "U123", context.Background())
+	h.SetSlashCommand("/lake", lookup)
+	return h
+}
+
+func signedSlashCommandRequest(t *testing.T, secret string, form url.Values) *http.Request {
+	t.Helper()
+
+	body := form.Encode()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", ts, body)))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestAI_Slack_SlashCommand_ParseText(t *testing.T) {
+	t.Parallel()
+
+	sub, args := parseSlashCommandText("  STATUS  nyc-core-01 ")
+	require.Equal(t, "status", sub)
+	require.Equal(t, []string{"nyc-core-01"}, args)
+
+	sub, args = parseSlashCommandText("")
+	require.Equal(t, "", sub)
+	require.Empty(t, args)
+}
+
+func TestAI_Slack_SlashCommand_SummarizeStatusHistory(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	buckets := []statusBucket{
+		{Start: base, Status: "activated"},
+		{Start: base.Add(time.Hour), Status: "activated"},
+		{Start: base.Add(2 * time.Hour), Status: "soft-drained"},
+		{Start: base.Add(3 * time.Hour), Status: "activated"},
+	}
+	uptime, lastChange := summarizeStatusHistory(buckets)
+	require.InDelta(t, 75.0, uptime, 0.001)
+	require.Equal(t, base.Add(3*time.Hour), lastChange)
+
+	uptime, lastChange = summarizeStatusHistory(buckets[:2])
+	require.InDelta(t, 100.0, uptime, 0.001)
+	require.True(t, lastChange.IsZero())
+
+	uptime, lastChange = summarizeStatusHistory(nil)
+	require.Zero(t, uptime)
+	require.True(t, lastChange.IsZero())
+}
+
+func TestAI_Slack_SlashCommand_StatusBlocks(t *testing.T) {
+	t.Parallel()
+
+	lastChange := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	h := newSlashCommandTestHandler(&fakeStatusLookup{statuses: map[string]*EntityStatus{
+		"NYC-CORE-01": {
+			Kind:       "device",
+			PK:         "dev-1",
+			Code:       "NYC-CORE-01",
+			Status:     "activated",
+			Location:   "NYC",
+			UptimePct:  99.5,
+			HasHistory: true,
+			LastChange: lastChange,
+		},
+	}})
+
+	msg := h.handleSlashCommand(context.Background(), slack.SlashCommand{Command: "/lake", Text: "status NYC-CORE-01"})
+	require.Equal(t, slack.ResponseTypeInChannel, msg.ResponseType)
+	require.Len(t, msg.Blocks.BlockSet, 3)
+
+	summary, ok := msg.Blocks.BlockSet[0].(*slack.SectionBlock)
+	require.True(t, ok)
+	require.Equal(t, "status_summary", summary.BlockID)
+	require.Contains(t, summary.Text.Text, ":large_green_circle:")
+	require.Contains(t, summary.Text.Text, "*NYC-CORE-01*")
+
+	details, ok := msg.Blocks.BlockSet[1].(*slack.SectionBlock)
+	require.True(t, ok)
+	require.Equal(t, "status_details", details.BlockID)
+	require.Len(t, details.Fields, 4)
+	require.Equal(t, "*Metro*\nNYC", details.Fields[1].Text)
+	require.Equal(t, "*Uptime (30d)*\n99.50%", details.Fields[2].Text)
+	require.Contains(t, details.Fields[3].Text, strconv.FormatInt(lastChange.Unix(), 10))
+
+	ctxBlock, ok := msg.Blocks.BlockSet[2].(*slack.ContextBlock)
+	require.True(t, ok)
+	require.Equal(t, "status_context", ctxBlock.BlockID)
+}
+
+func TestAI_Slack_SlashCommand_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		lookup   StatusLookup
+		cmd      slack.SlashCommand
+		contains string
+	}{
+		{
+			name:     "missing code",
+			lookup:   &fakeStatusLookup{},
+			cmd:      slack.SlashCommand{Command: "/lake", Text: "status"},
+			contains: "Usage:",
+		},
+		{
+			name:     "unknown subcommand",
+			lookup:   &fakeStatusLookup{},
+			cmd:      slack.SlashCommand{Command: "/lake", Text: "reboot everything"},
+			contains: "Usage:",
+		},
+		{
+			name:     "not found",
+			lookup:   &fakeStatusLookup{},
+			cmd:      slack.SlashCommand{Command: "/lake", Text: "status NOPE"},
+			contains: "No device or link found with code `NOPE`",
+		},
+		{
+			name:     "lookup failure",
+			lookup:   &fakeStatusLookup{err: errors.New("connection refused")},
+			cmd:      slack.SlashCommand{Command: "/lake", Text: "status NYC-CORE-01"},
+			contains: "couldn't look up",
+		},
+		{
+			name:     "unknown command",
+			lookup:   &fakeStatusLookup{},
+			cmd:      slack.SlashCommand{Command: "/other", Text: "status NYC-CORE-01"},
+			contains: "Unknown command",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newSlashCommandTestHandler(tt.lookup)
+			msg := h.handleSlashCommand(context.Background(), tt.cmd)
+			require.Equal(t, slack.ResponseTypeEphemeral, msg.ResponseType)
+			require.Contains(t, msg.Text, tt.contains)
+			require.Empty(t, msg.Blocks.BlockSet)
+		})
+	}
+}
+
+func TestAI_Slack_SlashCommand_HandleHTTP(t *testing.T) {
+	t.Parallel()
+
+	h := newSlashCommandTestHandler(&fakeStatusLookup{statuses: map[string]*EntityStatus{
+		"NYC-LAX-001": {
+			Kind:     "link",
+			PK:       "link-1",
+			Code:     "NYC-LAX-001",
+			Status:   "hard-drained",
+			Location: "NYC-CORE-01 ⇔ LAX-CORE-01",
+		},
+	}})
+
+	req := signedSlashCommandRequest(t, "secret", url.Values{
+		"command": {"/lake"},
+		"text":    {"status NYC-LAX-001"},
+		"team_id": {"T123"},
+		"user_id": {"U456"},
+	})
+	rr := httptest.NewRecorder()
+	h.HandleHTTP(rr, req, "secret")
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var resp struct {
+		ResponseType string `json:"response_type"`
+		Blocks       []struct {
+			Type    string `json:"type"`
+			BlockID string `json:"block_id"`
+			Text    *struct {
+				Text string `json:"text"`
+			} `json:"text"`
+			Fields []struct {
+				Text string `json:"text"`
+			} `json:"fields"`
+		} `json:"blocks"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Equal(t, "in_channel", resp.ResponseType)
+	require.Len(t, resp.Blocks, 3)
+	require.Equal(t, "section", resp.Blocks[0].Type)
+	require.Contains(t, resp.Blocks[0].Text.Text, ":red_circle:")
+	require.Equal(t, "*Endpoints*\nNYC-CORE-01 ⇔ LAX-CORE-01", resp.Blocks[1].Fields[1].Text)
+	require.Equal(t, "*Uptime (30d)*\nno history", resp.Blocks[1].Fields[2].Text)
+	require.Equal(t, "context", resp.Blocks[2].Type)
+}
+
+func TestAI_Slack_SlashCommand_HandleHTTPInvalidSignature(t *testing.T) {
+	t.Parallel()
+
+	h := newSlashCommandTestHandler(&fakeStatusLookup{})
+	req := signedSlashCommandRequest(t, "wrong-secret", url.Values{
+		"command": {"/lake"},
+		"text":    {"status NYC-CORE-01"},
+	})
+	rr := httptest.NewRecorder()
+	h.HandleHTTP(rr, req, "secret")
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
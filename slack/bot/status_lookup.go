@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/malbeclabs/lake/api/config"
+)
+
+// statusUptimeWindow is how far back /lake status looks when computing uptime
+const statusUptimeWindow = 30 * 24 * time.Hour
+
+// ErrEntityNotFound is returned when no device or link matches the requested code
+var ErrEntityNotFound = errors.New("entity not found")
+
+// EntityStatus is the current state of a device or link
+type EntityStatus struct {
+	Kind       string // "device" or "link"
+	PK         string
+	Code       string
+	Status     string
+	Location   string    // metro code for devices, "A ⇔ Z" device codes for links
+	UptimePct  float64   // share of the uptime window spent activated, 0-100
+	HasHistory bool      // false if there were no history snapshots in the window
+	LastChange time.Time // zero if the status did not change within the window
+}
+
+// StatusLookup resolves a device or link code to its current status
+type StatusLookup interface {
+	LookupStatus(ctx context.Context, code string) (*EntityStatus, error)
+}
+
+// statusBucket is the status of an entity during one hour of history
+type statusBucket struct {
+	Start  time.Time
+	Status string
+}
+
+// summarizeStatusHistory computes uptime and the most recent status change from
+// hourly buckets ordered oldest first.
+func summarizeStatusHistory(buckets []statusBucket) (uptimePct float64, lastChange time.Time) {
+	if len(buckets) == 0 {
+		return 0, time.Time{}
+	}
+	activated := 0
+	for i, b := range buckets {
+		if b.Status == "activated" {
+			activated++
+		}
+		if i > 0 && b.Status != buckets[i-1].Status {
+			lastChange = b.Start
+		}
+	}
+	return float64(activated) * 100 / float64(len(buckets)), lastChange
+}
+
+// ClickHouseStatusLookup looks up device and link status from the ClickHouse
+// current-state and history tables.
+type ClickHouseStatusLookup struct{}
+
+// NewStatusLookup creates a status lookup backed by ClickHouse
+func NewStatusLookup() *ClickHouseStatusLookup {
+	return &ClickHouseStatusLookup{}
+}
+
+// LookupStatus finds a device or link by code, checking devices first
+func (l *ClickHouseStatusLookup) LookupStatus(ctx context.Context, code string) (*EntityStatus, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("clickhouse is not configured")
+	}
+
+	entity := &EntityStatus{Kind: "device"}
+	historyTable := "dim_dz_devices_history"
+	err := config.DB.QueryRow(ctx, `
+		SELECT d.pk, d.code, d.status, COALESCE(m.code, '')
+		FROM dz_devices_current d
+		LEFT JOIN dz_metros_current m ON d.metro_pk = m.pk
+		WHERE d.code = ?
+		LIMIT 1
+	`, code).Scan(&entity.PK, &entity.Code, &entity.Status, &entity.Location)
+	if errors.Is(err, sql.ErrNoRows) {
+		entity = &EntityStatus{Kind: "link"}
+		historyTable = "dim_dz_links_history"
+		var sideA, sideZ string
+		err = config.DB.QueryRow(ctx, `
+			SELECT l.pk, l.code, l.status, COALESCE(da.code, ''), COALESCE(dz.code, '')
+			FROM dz_links_current l
+			LEFT JOIN dz_devices_current da ON l.side_a_pk = da.pk
+			LEFT JOIN dz_devices_current dz ON l.side_z_pk = dz.pk
+			WHERE l.code = ?
+			LIMIT 1
+		`, code).Scan(&entity.PK, &entity.Code, &entity.Status, &sideA, &sideZ)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEntityNotFound
+		}
+		if sideA != "" && sideZ != "" {
+			entity.Location = sideA + " ⇔ " + sideZ
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", entity.Kind, err)
+	}
+
+	rows, err := config.DB.Query(ctx, `
+		SELECT
+			toStartOfHour(snapshot_ts) as bucket,
+			argMax(status, snapshot_ts) as status
+		FROM `+historyTable+`
+		WHERE pk = ? AND snapshot_ts > now() - INTERVAL ? HOUR
+		GROUP BY bucket
+		ORDER BY bucket
+	`, entity.PK, int(statusUptimeWindow.Hours()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s history: %w", entity.Kind, err)
+	}
+	defer rows.Close()
+
+	var buckets []statusBucket
+	for rows.Next() {
+		var b statusBucket
+		if err := rows.Scan(&b.Start, &b.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan %s history: %w", entity.Kind, err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s history: %w", entity.Kind, err)
+	}
+
+	entity.HasHistory = len(buckets) > 0
+	entity.UptimePct, entity.LastChange = summarizeStatusHistory(buckets)
+	return entity, nil
+}
@@ -160,7 +160,7 @@ export function DzVsInternetPage() {
   // Fetch latency comparison data
   const { data: latencyData, isLoading: latencyLoading, error: latencyError, isFetching: latencyFetching } = useQuery({
     queryKey: ['latency-comparison'],
-    queryFn: fetchLatencyComparison,
+    queryFn: () => fetchLatencyComparison(),
     staleTime: 0,
     retry: 2,
   })
@@ -33,6 +33,7 @@ const ISSUE_TYPE_LABELS: Record<string, string> = {
   critical_link: 'Critical Link',
   single_exit_metro: 'Single-Exit Metro',
   no_backup_device: 'No Backup Device',
+  non_redundant_hub: 'Non-Redundant Hub',
 }
 
 function SummaryCard({
@@ -325,7 +326,7 @@ function DegradedLinkRow({
   )
 }
 
-type FilterType = 'all' | 'leaf_device' | 'critical_link' | 'single_exit_metro'
+type FilterType = 'all' | 'leaf_device' | 'critical_link' | 'single_exit_metro' | 'non_redundant_hub'
 type FilterSeverity = 'all' | 'critical' | 'warning' | 'info'
 
 export function RedundancyReportPage() {
@@ -469,6 +470,7 @@ export function RedundancyReportPage() {
             <option value="leaf_device">Leaf Devices</option>
             <option value="critical_link">Critical Links</option>
             <option value="single_exit_metro">Single-Exit Metros</option>
+            <option value="non_redundant_hub">Non-Redundant Hubs</option>
           </select>
         </div>
         <div>
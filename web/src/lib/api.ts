@@ -15,7 +15,28 @@ export interface QueryResponse {
   rows: unknown[][]
   row_count: number
   elapsed_ms: number
+  truncated?: boolean
   error?: string
+  diff?: QueryDiff
+  diff_error?: string
+}
+
+// Rows are keyed by column name since the columns of the two runs may differ
+export interface QueryDiff {
+  added: Record<string, unknown>[]
+  removed: Record<string, unknown>[]
+  changed: {
+    key: unknown
+    oldRow: Record<string, unknown>
+    newRow: Record<string, unknown>
+    changedColumns: string[]
+  }[]
+}
+
+export interface QueryDiffOptions {
+  sessionId: string
+  key: string // column identifying a row across runs
+  diffOnly?: boolean // leave out the raw rows
 }
 
 // Cypher query response uses map rows instead of array rows
@@ -31,6 +52,7 @@ export type QueryMode = 'sql' | 'cypher' | 'auto'
 
 // Auth token storage key
 const AUTH_TOKEN_KEY = 'lake_auth_token'
+const REFRESH_TOKEN_KEY = 'lake_refresh_token'
 const ANONYMOUS_ID_KEY = 'lake_anonymous_id'
 
 // Get the auth token from localStorage
@@ -43,9 +65,18 @@ export function setAuthToken(token: string): void {
   localStorage.setItem(AUTH_TOKEN_KEY, token)
 }
 
-// Clear the auth token from localStorage
+// Clear the auth and refresh tokens from localStorage
 export function clearAuthToken(): void {
   localStorage.removeItem(AUTH_TOKEN_KEY)
+  localStorage.removeItem(REFRESH_TOKEN_KEY)
+}
+
+// Store the tokens returned by a login or refresh
+function setAuthTokens(token: string, refreshToken?: string): void {
+  setAuthToken(token)
+  if (refreshToken) {
+    localStorage.setItem(REFRESH_TOKEN_KEY, refreshToken)
+  }
 }
 
 // Get or generate an anonymous ID for unauthenticated users
@@ -205,6 +236,27 @@ async function fetchWithRetry(
   throw new Error(`Request failed with status ${lastStatus}`)
 }
 
+// API error responses are JSON: { code, message, requestId }
+export interface ApiErrorResponse {
+  code: string
+  message: string
+  requestId?: string
+}
+
+// Returns the message from an API error response, or the raw body if it isn't JSON
+async function errorText(res: Response): Promise<string> {
+  const text = await res.text()
+  try {
+    const body = JSON.parse(text) as Partial<ApiErrorResponse>
+    if (typeof body.message === 'string') {
+      return body.message
+    }
+  } catch {
+    // Not JSON
+  }
+  return text
+}
+
 export async function fetchCatalog(): Promise<CatalogResponse> {
   const res = await fetchWithRetry('/api/catalog')
   if (!res.ok) {
@@ -214,23 +266,58 @@ export async function fetchCatalog(): Promise<CatalogResponse> {
 }
 
 // SQL query execution (uses new /api/sql/query endpoint)
-export async function executeSqlQuery(query: string, env?: string): Promise<QueryResponse> {
+// With diff options, the result is compared with the session's previous diffed run
+export async function executeSqlQuery(
+  query: string,
+  env?: string,
+  diff?: QueryDiffOptions
+): Promise<QueryResponse> {
   const headers: Record<string, string> = { 'Content-Type': 'application/json' }
   if (env) {
     headers['X-DZ-Env'] = env
   }
-  const res = await fetchWithRetry('/api/sql/query', {
+  let url = '/api/sql/query'
+  if (diff) {
+    const params = [
+      `diff_session_id=${encodeURIComponent(diff.sessionId)}`,
+      `diff_key=${encodeURIComponent(diff.key)}`,
+    ]
+    if (diff.diffOnly) params.push('show_diff_only=true')
+    const anonParam = getAnonymousIdParam()
+    if (anonParam) params.push(anonParam)
+    url += `?${params.join('&')}`
+  }
+  const res = await fetchWithRetry(url, {
     method: 'POST',
     headers,
     body: JSON.stringify({ query }),
   })
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     throw new Error(text || 'Failed to execute query')
   }
   return res.json()
 }
 
+export interface SqlValidateResponse {
+  valid: boolean
+  error?: string
+  position?: { line: number; column: number }
+}
+
+// Syntax-checks and plans a query without executing it
+export async function validateSqlQuery(query: string): Promise<SqlValidateResponse> {
+  const res = await fetchWithRetry('/api/sql/validate', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ query }),
+  })
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
 // Cypher query execution
 export async function executeCypherQuery(query: string, env?: string): Promise<CypherQueryResponse> {
   const headers: Record<string, string> = { 'Content-Type': 'application/json' }
@@ -243,7 +330,7 @@ export async function executeCypherQuery(query: string, env?: string): Promise<C
     body: JSON.stringify({ query }),
   })
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     throw new Error(text || 'Failed to execute Cypher query')
   }
   return res.json()
@@ -254,6 +341,7 @@ export const executeQuery = executeSqlQuery
 
 export interface GenerateResponse {
   sql: string
+  queryId?: string
   error?: string
 }
 
@@ -269,12 +357,56 @@ export async function generateSQL(prompt: string, currentQuery?: string, history
     body: JSON.stringify({ prompt, currentQuery, history }),
   })
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     throw new Error(text || 'Failed to generate SQL')
   }
   return res.json()
 }
 
+export interface GenerateFeedback {
+  sessionId?: string
+  queryId: string
+  rating: 1 | -1
+  correctedQuery?: string
+  comment?: string
+}
+
+export async function submitGenerateFeedback(feedback: GenerateFeedback): Promise<void> {
+  const res = await fetchWithRetry('/api/generate/feedback', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(feedback),
+  })
+  if (!res.ok) {
+    const text = await errorText(res)
+    throw new Error(text || 'Failed to submit feedback')
+  }
+}
+
+export interface ChatFeedback {
+  sessionId: string
+  messageIndex: number // index of the assistant message in the session
+  rating: 1 | -1
+  correction?: string
+}
+
+export async function submitChatFeedback(feedback: ChatFeedback): Promise<void> {
+  const body: ChatFeedback & { anonymousId?: string } = { ...feedback }
+  if (!getAuthToken()) {
+    body.anonymousId = getAnonymousId()
+  }
+
+  const res = await fetchWithRetry('/api/chat/feedback', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  })
+  if (!res.ok) {
+    const text = await errorText(res)
+    throw new Error(text || 'Failed to submit feedback')
+  }
+}
+
 export interface StreamCallbacks {
   onToken: (token: string) => void
   onStatus: (status: { provider?: string; status?: string; attempt?: number; error?: string }) => void
@@ -352,7 +484,7 @@ export async function generateAutoStream(
   }
 
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     callbacks.onError(text || 'Failed to generate query')
     return
   }
@@ -470,7 +602,7 @@ async function generateQueryStream(
   }
 
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     callbacks.onError(text || 'Failed to generate query')
     return
   }
@@ -751,7 +883,7 @@ export async function sendChatMessage(
     signal,
   })
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     throw new Error(text || 'Failed to send message')
   }
   return res.json()
@@ -861,7 +993,7 @@ export async function sendChatMessageStream(
     }
 
     if (!res.ok) {
-      const text = await res.text()
+      const text = await errorText(res)
       callbacks.onError(text || 'Failed to send message')
       return
     }
@@ -1038,7 +1170,7 @@ Respond with ONLY the title. No quotes, no explanation.`
   })
 
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     return { title: '', error: text || 'Failed to generate title' }
   }
 
@@ -1086,7 +1218,7 @@ Respond with ONLY the title. No quotes, no explanation.`
   })
 
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     return { title: '', error: text || 'Failed to generate title' }
   }
 
@@ -1134,7 +1266,7 @@ export async function recommendVisualization(
   })
 
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     return { recommended: false, error: text || 'Failed to get recommendation' }
   }
 
@@ -1264,6 +1396,17 @@ export interface InterfaceHealth {
 export interface InterfaceIssuesResponse {
   issues: InterfaceIssue[]
   time_range: string
+  total: number
+  has_more: boolean
+}
+
+export interface InterfaceIssuesFilter {
+  device_pk?: string
+  metro_pk?: string
+  issue_type?: 'errors' | 'discards' | 'carrier'
+  since?: string // RFC3339
+  limit?: number
+  offset?: number
 }
 
 export interface NonActivatedDevice {
@@ -1481,9 +1624,12 @@ export async function fetchSingleDeviceHistory(devicePk: string, timeRange?: str
   return res.json()
 }
 
-export async function fetchInterfaceIssues(timeRange?: string): Promise<InterfaceIssuesResponse> {
+export async function fetchInterfaceIssues(timeRange?: string, filter: InterfaceIssuesFilter = {}): Promise<InterfaceIssuesResponse> {
   const params = new URLSearchParams()
   if (timeRange) params.set('range', timeRange)
+  for (const [key, value] of Object.entries(filter)) {
+    if (value !== undefined && value !== '') params.set(key, String(value))
+  }
   const url = `/api/status/interface-issues${params.toString() ? '?' + params.toString() : ''}`
   const res = await fetchWithRetry(url)
   if (!res.ok) {
@@ -1636,6 +1782,8 @@ export interface ISISNodeData {
   metroPK?: string
   systemId?: string
   routerId?: string
+  nodeType?: 'root' | 'subscriber' | 'transit'
+  isCenter?: boolean
 }
 
 export interface ISISNode {
@@ -1669,18 +1817,72 @@ export async function fetchISISTopology(): Promise<ISISTopologyResponse> {
   return res.json()
 }
 
+export interface SPTNode {
+  devicePK: string
+  deviceCode: string
+  parentPK: string // empty for the root
+  pathMetric: number
+  hopCount: number
+}
+
+export interface ISISSPTResponse {
+  rootPK: string
+  rootCode: string
+  nodes: SPTNode[]
+  unreachableCount: number
+}
+
+export async function fetchISISSPT(root: string): Promise<ISISSPTResponse> {
+  const res = await fetchWithRetry(`/api/topology/isis-spt?root=${encodeURIComponent(root)}`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
+export interface RouteReflectorCandidate {
+  devicePK: string
+  deviceCode: string
+  metroCode: string
+  score: number // 0-1, higher is a better candidate
+  reasons: string[]
+}
+
+export async function fetchRouteReflectorCandidates(limit?: number): Promise<RouteReflectorCandidate[]> {
+  const query = limit !== undefined ? `?limit=${limit}` : ''
+  const res = await fetchWithRetry(`/api/topology/route-reflector-candidates${query}`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
+export async function fetchNeighborGraph(center: string, depth?: number): Promise<ISISTopologyResponse> {
+  const params = new URLSearchParams({ center })
+  if (depth !== undefined) params.set('depth', String(depth))
+  const res = await fetchWithRetry(`/api/topology/neighbor-graph?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch neighbor graph')
+  }
+  return res.json()
+}
+
 // Path finding types
 export interface PathHop {
   devicePK: string
   deviceCode: string
   status: string
   deviceType: string
+  metroCode?: string
+  edgeMetric?: number
+  edgeMeasuredMs?: number
 }
 
 export interface PathResponse {
   path: PathHop[]
   totalMetric: number
   hopCount: number
+  measuredLatencyMs?: number
   error?: string
 }
 
@@ -1694,6 +1896,35 @@ export async function fetchISISPath(fromPK: string, toPK: string, mode: PathMode
   return res.json()
 }
 
+export interface HistoricalPathResponse extends PathResponse {
+  reconstructedAt?: string
+}
+
+export async function fetchHistoricalPath(fromPK: string, toPK: string, at: string): Promise<HistoricalPathResponse> {
+  const params = new URLSearchParams({ from: fromPK, to: toPK, at })
+  const res = await apiFetch(`/api/topology/historical-path?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch historical path')
+  }
+  return res.json()
+}
+
+export async function fetchISISPathTraceroute(fromPK: string, toPK: string, mode: PathMode = 'hops'): Promise<string> {
+  const res = await apiFetch(`/api/topology/path?from=${encodeURIComponent(fromPK)}&to=${encodeURIComponent(toPK)}&mode=${mode}&format=traceroute`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch path')
+  }
+  return res.text()
+}
+
+export async function fetchUserPathToDevice(userPK: string, targetPK: string, mode: PathMode = 'hops'): Promise<PathResponse> {
+  const res = await apiFetch(`/api/dz/users/${encodeURIComponent(userPK)}/path-to-device?target=${encodeURIComponent(targetPK)}&mode=${mode}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch user path')
+  }
+  return res.json()
+}
+
 // Multi-path types
 export interface MultiPathHop {
   devicePK: string
@@ -1730,6 +1961,71 @@ export async function fetchISISPaths(fromPK: string, toPK: string, k: number = 5
   return res.json()
 }
 
+export interface ECMPPathsResponse {
+  from: string
+  to: string
+  ecmp_count: number
+  totalMetric: number
+  paths: SinglePath[]
+  error?: string
+}
+
+export async function fetchECMPPaths(fromPK: string, toPK: string): Promise<ECMPPathsResponse> {
+  const res = await apiFetch(`/api/topology/ecmp-paths?from=${encodeURIComponent(fromPK)}&to=${encodeURIComponent(toPK)}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch ECMP paths')
+  }
+  return res.json()
+}
+
+export interface ASNPathHop {
+  asn: number
+  org: string
+  deviceCount: number
+}
+
+export interface ASNPathResponse {
+  from: string
+  to: string
+  path: ASNPathHop[]
+}
+
+export async function fetchASNPaths(fromPK: string, toPK: string): Promise<ASNPathResponse> {
+  const res = await apiFetch(`/api/topology/asn-paths?from=${encodeURIComponent(fromPK)}&to=${encodeURIComponent(toPK)}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch ASN path')
+  }
+  return res.json()
+}
+
+// Link utilization types
+export interface LinkUtilization {
+  linkPK: string
+  linkCode: string
+  sideACode: string
+  sideZCode: string
+  ingressBps: number
+  egressBps: number
+  bandwidthBps: number
+  utilizationPct: number
+  discardsPps: number
+  severity: 'critical' | 'normal'
+}
+
+export interface LinkUtilizationResponse {
+  threshold: number
+  links: LinkUtilization[]
+}
+
+export async function fetchLinkUtilization(threshold?: number): Promise<LinkUtilizationResponse> {
+  const params = threshold !== undefined ? `?threshold=${threshold}` : ''
+  const res = await fetchWithRetry(`/api/topology/link-utilization${params}`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
 // Metro device paths types
 export interface MetroDevicePairPath {
   sourceDevicePK: string
@@ -1865,6 +2161,14 @@ export async function fetchMulticastTreePaths(pkOrCode: string): Promise<Multica
   return res.json()
 }
 
+export async function fetchMulticastGroupTopology(pkOrCode: string): Promise<ISISTopologyResponse> {
+  const res = await apiFetch(`/api/dz/multicast-groups/${encodeURIComponent(pkOrCode)}/topology`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch multicast group topology')
+  }
+  return res.json()
+}
+
 // Multicast group traffic types
 export interface MulticastTrafficPoint {
   time: string
@@ -1912,119 +2216,478 @@ export async function fetchCriticalLinks(): Promise<CriticalLinksResponse> {
   return res.json()
 }
 
-// Redundancy report types
-export interface RedundancyIssue {
-  type: 'leaf_device' | 'critical_link' | 'single_exit_metro' | 'no_backup_device'
-  severity: 'critical' | 'warning' | 'info'
-  entityPK: string
-  entityCode: string
-  entityType: 'device' | 'link' | 'metro'
-  description: string
-  impact: string
-  targetPK?: string
-  targetCode?: string
-  metroPK?: string
-  metroCode?: string
+// ISIS metric optimization types
+export interface ISISMetricSuggestion {
+  linkPK: string
+  linkCode: string
+  sideAPK: string
+  sideZPK: string
+  currentMetric: number
+  measuredRttUs: number
+  suggestedMetric: number
+  deviationPct: number
+  impactedPaths: number
 }
 
-export interface RedundancySummary {
-  totalIssues: number
-  criticalCount: number
-  warningCount: number
-  infoCount: number
-  leafDevices: number
-  criticalLinks: number
-  singleExitMetros: number
+export interface ISISMetricOptimizationResponse {
+  scaleFactor: number
+  minDeviationPct: number
+  linksChecked: number
+  suggestions: ISISMetricSuggestion[]
 }
 
-export interface RedundancyReportResponse {
-  issues: RedundancyIssue[]
-  summary: RedundancySummary
+export async function fetchISISMetricOptimization(
+  scaleFactor?: number,
+  minDeviationPct?: number
+): Promise<ISISMetricOptimizationResponse> {
+  const params = new URLSearchParams()
+  if (scaleFactor !== undefined) params.set('scale_factor', String(scaleFactor))
+  if (minDeviationPct !== undefined) params.set('min_deviation_pct', String(minDeviationPct))
+  const res = await apiFetch(`/api/topology/isis-metric-optimization?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch ISIS metric optimization')
+  }
+  return res.json()
+}
+
+// BFD session types
+export interface BFDSession {
+  sourcePK: string
+  sourceCode: string
+  targetPK: string
+  targetCode: string
+  bfdState: string
+  minTxUs: number
+  minRxUs: number
+  multiplier: number
+  severity: 'critical' | 'normal'
+}
+
+export interface BFDSessionsResponse {
+  sessions: BFDSession[]
   error?: string
 }
 
-export async function fetchRedundancyReport(): Promise<RedundancyReportResponse> {
-  const res = await apiFetch('/api/topology/redundancy-report')
+export async function fetchBFDSessions(): Promise<BFDSessionsResponse> {
+  const res = await apiFetch('/api/topology/bfd-sessions')
   if (!res.ok) {
-    throw new Error('Failed to fetch redundancy report')
+    throw new Error('Failed to fetch BFD sessions')
   }
   return res.json()
 }
 
-// Topology comparison types
-export interface TopologyDiscrepancy {
-  type: 'missing_isis' | 'extra_isis' | 'metric_mismatch'
-  linkPK?: string
-  linkCode?: string
-  deviceAPK: string
-  deviceACode: string
-  deviceBPK: string
-  deviceBCode: string
-  configuredRttUs?: number
-  isisMetric?: number
-  details: string
+export interface PrefixSID {
+  devicePK: string
+  deviceCode: string
+  prefixSID: number
+  flags: string[]
 }
 
-export interface TopologyCompareResponse {
-  configuredLinks: number
-  isisAdjacencies: number
-  matchedLinks: number
-  discrepancies: TopologyDiscrepancy[]
+export interface AdjacencySID {
+  sourcePK: string
+  targetPK: string
+  adjSID: number
+  flags: string[]
+}
+
+export interface SegmentRoutingSIDsResponse {
+  prefixSIDs: PrefixSID[]
+  adjacencySIDs: AdjacencySID[]
   error?: string
 }
 
-export async function fetchTopologyCompare(): Promise<TopologyCompareResponse> {
-  const res = await apiFetch('/api/topology/compare')
+export async function fetchSegmentRoutingSIDs(): Promise<SegmentRoutingSIDsResponse> {
+  const res = await apiFetch('/api/topology/segment-routing-sids')
   if (!res.ok) {
-    throw new Error('Failed to fetch topology comparison')
+    throw new Error('Failed to fetch segment routing SIDs')
   }
   return res.json()
 }
 
-// Failure impact types
-export interface ImpactDevice {
-  pk: string
-  code: string
-  status: string
-  deviceType: string
+export interface PathWithSIDsResponse {
+  path: PathHop[]
+  labelStack: number[]
+  labelTypes: ('prefix' | 'adj')[]
+  totalMetric: number
+  error?: string
 }
 
-export interface FailureImpactPath {
-  fromPK: string
-  fromCode: string
-  toPK: string
-  toCode: string
-  beforeHops: number
-  beforeMetric: number
-  afterHops: number
-  afterMetric: number
-  hasAlternate: boolean
+export async function fetchPathWithSIDs(fromPK: string, toPK: string, mode: PathMode = 'hops'): Promise<PathWithSIDsResponse> {
+  const res = await apiFetch(`/api/topology/path-with-sids?from=${encodeURIComponent(fromPK)}&to=${encodeURIComponent(toPK)}&mode=${mode}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch path with SIDs')
+  }
+  return res.json()
 }
 
-export interface MetroImpact {
-  pk: string
-  code: string
-  name: string
-  totalDevices: number
-  remainingDevices: number
-  isolatedDevices: number
+export interface MetroResilienceBreakdown {
+  deviceScore: number
+  exitScore: number
+  leafPenalty: number
+  diversityScore: number
+  slaScore: number | null
 }
 
-export interface FailureImpactResponse {
-  devicePK: string
-  deviceCode: string
-  unreachableDevices: ImpactDevice[]
-  unreachableCount: number
-  affectedPaths: FailureImpactPath[]
-  affectedPathCount: number
-  metroImpact: MetroImpact[]
-  error?: string
+export interface MetroResilienceScore {
+  metroPK: string
+  metroCode: string
+  resilienceScore: number
+  breakdown: MetroResilienceBreakdown
 }
 
-export async function fetchFailureImpact(devicePK: string): Promise<FailureImpactResponse> {
-  const res = await apiFetch(`/api/topology/impact/${encodeURIComponent(devicePK)}`)
+export async function fetchMetroResilienceScore(): Promise<MetroResilienceScore[]> {
+  const res = await apiFetch('/api/topology/metro-resilience-score')
   if (!res.ok) {
-    throw new Error('Failed to fetch failure impact')
+    throw new Error('Failed to fetch metro resilience score')
+  }
+  return res.json()
+}
+
+export interface CriticalPathViolation {
+  fromMetroCode: string
+  toMetroCode: string
+  currentMetricMs: number
+  slaMs: number
+  violationMs: number
+  bestPath: PathHop[]
+}
+
+export interface CriticalPathSLAResponse {
+  slaMs: number
+  violations: CriticalPathViolation[]
+  totalPairs: number
+  totalViolations: number
+  compliancePct: number
+  unreachablePairs: number
+}
+
+export async function fetchCriticalPathSLA(slaMs: number): Promise<CriticalPathSLAResponse> {
+  const res = await apiFetch(`/api/topology/critical-path?sla_ms=${slaMs}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch critical path SLA')
+  }
+  return res.json()
+}
+
+export interface LoadBalancingGroup {
+  ecmpGroup: { fromMetro: string; toMetro: string }
+  pathCount: number
+  adjacencyCount: number // adjacencies on some but not all paths, with traffic
+  minTrafficBps: number
+  maxTrafficBps: number
+  avgTrafficBps: number
+  imbalanceRatio: number // (max - min) / avg
+  severity: 'ok' | 'warning' | 'critical'
+}
+
+export interface LoadBalancingQualityResponse {
+  groups: LoadBalancingGroup[]
+  warningCount: number
+  criticalCount: number
+}
+
+export async function fetchLoadBalancingQuality(): Promise<LoadBalancingQualityResponse> {
+  const res = await apiFetch('/api/topology/load-balancing-quality')
+  if (!res.ok) {
+    throw new Error('Failed to fetch load balancing quality')
+  }
+  return res.json()
+}
+
+// IS-IS adjacency change types
+export interface ISISChangeEvent {
+  timestamp: string
+  fromPK: string
+  fromCode: string
+  toPK: string
+  toCode: string
+  eventType: 'up' | 'down'
+  metric: number
+}
+
+export async function fetchISISChanges(params: {
+  start?: string
+  end?: string
+  devicePK?: string
+} = {}): Promise<ISISChangeEvent[]> {
+  const searchParams = new URLSearchParams()
+  if (params.start) searchParams.set('start', params.start)
+  if (params.end) searchParams.set('end', params.end)
+  if (params.devicePK) searchParams.set('device_pk', params.devicePK)
+  const query = searchParams.toString()
+  const res = await apiFetch(`/api/topology/isis-changes${query ? `?${query}` : ''}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch IS-IS changes')
+  }
+  return res.json()
+}
+
+export interface DeviceISISAdjacencyEvent {
+  timestamp: string
+  neighborPK: string
+  neighborCode: string
+  eventType: 'up' | 'down'
+  metric: number
+}
+
+export interface DeviceISISAdjacencyHistory {
+  devicePK: string
+  start: string
+  end: string
+  events: DeviceISISAdjacencyEvent[]
+  adjacencyFlaps: number
+  longestOutageSeconds: number
+}
+
+export async function fetchDeviceISISAdjacencyHistory(
+  pk: string,
+  params: { start?: string; end?: string } = {}
+): Promise<DeviceISISAdjacencyHistory> {
+  const searchParams = new URLSearchParams()
+  if (params.start) searchParams.set('start', params.start)
+  if (params.end) searchParams.set('end', params.end)
+  const query = searchParams.toString()
+  const res = await apiFetch(`/api/dz/devices/${encodeURIComponent(pk)}/isis-adjacency-history${query ? `?${query}` : ''}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch IS-IS adjacency history')
+  }
+  return res.json()
+}
+
+export interface PathLatencyBuckets {
+  under1ms: number
+  under5ms: number
+  under10ms: number
+  under50ms: number
+  over50ms: number
+}
+
+export interface PathLatencyPercentiles {
+  windowStart: string
+  windowEnd: string
+  linkCount: number
+  p5RttNs: number
+  p25RttNs: number
+  p50RttNs: number
+  p75RttNs: number
+  p95RttNs: number
+  p99RttNs: number
+  buckets: PathLatencyBuckets
+}
+
+export async function fetchPathLatencyPercentiles(): Promise<PathLatencyPercentiles> {
+  const res = await apiFetch('/api/topology/path-latency-percentiles')
+  if (!res.ok) {
+    throw new Error('Failed to fetch path latency percentiles')
+  }
+  return res.json()
+}
+
+// Redundancy report types
+export interface RedundancyIssue {
+  type: 'leaf_device' | 'critical_link' | 'single_exit_metro' | 'no_backup_device' | 'non_redundant_hub'
+  severity: 'critical' | 'warning' | 'info'
+  entityPK: string
+  entityCode: string
+  entityType: 'device' | 'link' | 'metro'
+  description: string
+  impact: string
+  targetPK?: string
+  targetCode?: string
+  metroPK?: string
+  metroCode?: string
+}
+
+export interface RedundancySummary {
+  totalIssues: number
+  criticalCount: number
+  warningCount: number
+  infoCount: number
+  leafDevices: number
+  criticalLinks: number
+  singleExitMetros: number
+  nonRedundantHubs: number
+}
+
+export interface CentralityDevice {
+  pk: string
+  code: string
+  metroPK?: string
+  status: string
+  score: number
+  cutVertex: boolean
+}
+
+export interface RedundancyReportResponse {
+  issues: RedundancyIssue[]
+  highCentralityDevices: CentralityDevice[]
+  summary: RedundancySummary
+  error?: string
+}
+
+export async function fetchRedundancyReport(): Promise<RedundancyReportResponse> {
+  const res = await apiFetch('/api/topology/redundancy-report')
+  if (!res.ok) {
+    throw new Error('Failed to fetch redundancy report')
+  }
+  return res.json()
+}
+
+export interface BetweennessCentralityResponse {
+  devices: CentralityDevice[]
+  error?: string
+}
+
+export async function fetchBetweennessCentrality(): Promise<BetweennessCentralityResponse> {
+  const res = await apiFetch('/api/topology/betweenness-centrality')
+  if (!res.ok) {
+    throw new Error('Failed to fetch betweenness centrality')
+  }
+  return res.json()
+}
+
+export interface DeviceInfo {
+  pk: string
+  code: string
+  status: string
+  deviceType: string
+  metroPK?: string
+  metroCode?: string
+  degree: number
+}
+
+export interface DeviceGroupsResponse {
+  groups: {
+    leaf: DeviceInfo[]
+    edge: DeviceInfo[]
+    transit: DeviceInfo[]
+    core: DeviceInfo[]
+    isolated?: DeviceInfo[]
+  }
+  summary: {
+    leafCount: number
+    edgeCount: number
+    transitCount: number
+    coreCount: number
+    isolatedCount?: number
+  }
+}
+
+export async function fetchDeviceGroups(includeOffline = false): Promise<DeviceGroupsResponse> {
+  const params = includeOffline ? '?include_offline=true' : ''
+  const res = await apiFetch(`/api/topology/device-groups${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch device groups')
+  }
+  return res.json()
+}
+
+export interface PathDiversityDevice {
+  pk: string
+  code: string
+  metroPK?: string
+}
+
+export interface PathDiversityLink {
+  sourcePK: string
+  sourceCode: string
+  targetPK: string
+  targetCode: string
+}
+
+export interface PathDiversityResponse {
+  fromMetroPK: string
+  toMetroPK: string
+  nodeDisjointPaths: number
+  edgeDisjointPaths: number
+  bottleneckNodes: PathDiversityDevice[]
+  bottleneckLinks: PathDiversityLink[]
+  error?: string
+}
+
+export async function fetchPathDiversity(fromMetroPK: string, toMetroPK: string): Promise<PathDiversityResponse> {
+  const params = new URLSearchParams({ from: fromMetroPK, to: toMetroPK })
+  const res = await apiFetch(`/api/topology/path-diversity?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch path diversity')
+  }
+  return res.json()
+}
+
+// Topology comparison types
+export interface TopologyDiscrepancy {
+  type: 'missing_isis' | 'extra_isis' | 'metric_mismatch'
+  linkPK?: string
+  linkCode?: string
+  deviceAPK: string
+  deviceACode: string
+  deviceBPK: string
+  deviceBCode: string
+  configuredRttUs?: number
+  isisMetric?: number
+  details: string
+}
+
+export interface TopologyCompareResponse {
+  configuredLinks: number
+  isisAdjacencies: number
+  matchedLinks: number
+  discrepancies: TopologyDiscrepancy[]
+  error?: string
+}
+
+export async function fetchTopologyCompare(): Promise<TopologyCompareResponse> {
+  const res = await apiFetch('/api/topology/compare')
+  if (!res.ok) {
+    throw new Error('Failed to fetch topology comparison')
+  }
+  return res.json()
+}
+
+// Failure impact types
+export interface ImpactDevice {
+  pk: string
+  code: string
+  status: string
+  deviceType: string
+}
+
+export interface FailureImpactPath {
+  fromPK: string
+  fromCode: string
+  toPK: string
+  toCode: string
+  beforeHops: number
+  beforeMetric: number
+  afterHops: number
+  afterMetric: number
+  hasAlternate: boolean
+}
+
+export interface MetroImpact {
+  pk: string
+  code: string
+  name: string
+  totalDevices: number
+  remainingDevices: number
+  isolatedDevices: number
+}
+
+export interface FailureImpactResponse {
+  devicePK: string
+  deviceCode: string
+  unreachableDevices: ImpactDevice[]
+  unreachableCount: number
+  affectedPaths: FailureImpactPath[]
+  affectedPathCount: number
+  metroImpact: MetroImpact[]
+  error?: string
+}
+
+export async function fetchFailureImpact(devicePK: string): Promise<FailureImpactResponse> {
+  const res = await apiFetch(`/api/topology/impact/${encodeURIComponent(devicePK)}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch failure impact')
   }
   return res.json()
 }
@@ -2099,9 +2762,22 @@ export interface SimulateLinkAdditionResponse {
   improvedPathCount: number
   redundancyGains: RedundancyGain[]
   redundancyCount: number
+  newShortestPath?: SimulatedPath
+  ecmpPaths: ImprovedPath[]
+  createsEcmp: boolean
   error?: string
 }
 
+export interface SimulatedPath {
+  path: string[]
+  hops: number
+  metric: number
+  usesNewLink: boolean
+  beforeHops: number
+  beforeMetric: number
+  ecmp: boolean
+}
+
 export async function fetchSimulateLinkAddition(
   sourcePK: string,
   targetPK: string,
@@ -2119,14 +2795,18 @@ export async function fetchSimulateLinkAddition(
 // Link Health (SLA compliance) for topology overlay
 export interface TopologyLinkHealth {
   link_pk: string
+  link_code: string
+  status: string
   side_a_pk: string
   side_a_code: string
   side_z_pk: string
   side_z_code: string
   avg_rtt_us: number
   p95_rtt_us: number
+  jitter_us: number
   committed_rtt_ns: number
   loss_pct: number
+  last_measured_at: string | null
   exceeds_commit: boolean
   has_packet_loss: boolean
   is_dark: boolean
@@ -2144,8 +2824,8 @@ export interface LinkHealthResponse {
   unknown_count: number
 }
 
-export async function fetchLinkHealth(): Promise<LinkHealthResponse> {
-  const res = await apiFetch('/api/dz/links-health')
+export async function fetchLinkHealth(sort?: 'rtt' | 'jitter' | 'loss'): Promise<LinkHealthResponse> {
+  const res = await apiFetch(`/api/dz/links-health${sort ? `?sort=${sort}` : ''}`)
   if (!res.ok) {
     throw new Error('Failed to fetch link health')
   }
@@ -2177,6 +2857,7 @@ export interface SessionMetadata {
   content_length: number
   created_at: string
   updated_at: string
+  tags: Record<string, string>
 }
 
 export interface ServerSession<T> {
@@ -2186,6 +2867,7 @@ export interface ServerSession<T> {
   content: T
   created_at: string
   updated_at: string
+  tags: Record<string, string>
 }
 
 export interface SessionListResponse {
@@ -2209,13 +2891,23 @@ function getAnonymousIdParam(): string {
 }
 
 // Session API functions
+export interface SessionTagFilter {
+  key: string
+  value?: string // only sessions with the key set to this value
+}
+
 export async function listSessions(
   type: 'chat' | 'query',
   limit = 50,
-  offset = 0
+  offset = 0,
+  tag?: SessionTagFilter
 ): Promise<SessionListResponse> {
   const anonParam = getAnonymousIdParam()
   const params = [`type=${type}`, `limit=${limit}`, `offset=${offset}`]
+  if (tag) {
+    params.push(`tag_key=${encodeURIComponent(tag.key)}`)
+    if (tag.value !== undefined) params.push(`tag_value=${encodeURIComponent(tag.value)}`)
+  }
   if (anonParam) params.push(anonParam)
 
   const res = await fetchWithRetry(`/api/sessions?${params.join('&')}`)
@@ -2335,6 +3027,30 @@ export async function updateSession<T>(
   return res.json()
 }
 
+// Replaces all of a session's tags; pass {} to clear them
+export async function updateSessionTags(
+  id: string,
+  tags: Record<string, string>
+): Promise<{ tags: Record<string, string> }> {
+  const body: { tags: Record<string, string>; anonymous_id?: string } = { tags }
+  if (!getAuthToken()) {
+    body.anonymous_id = getAnonymousId()
+  }
+
+  const res = await fetchWithRetry(`/api/sessions/${id}/tags`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  })
+  if (!res.ok) {
+    if (res.status === 404) {
+      throw new Error('Session not found')
+    }
+    throw new Error('Failed to update session tags')
+  }
+  return res.json()
+}
+
 export async function deleteSession(id: string): Promise<void> {
   const anonParam = getAnonymousIdParam()
   const url = anonParam ? `/api/sessions/${id}?${anonParam}` : `/api/sessions/${id}`
@@ -2347,6 +3063,75 @@ export async function deleteSession(id: string): Promise<void> {
   }
 }
 
+export interface ShareSessionResponse {
+  shareUrl: string
+  token: string
+  expiresAt: string
+}
+
+// expiresIn is a number of days ("7d") or a duration ("12h"); the server defaults to 7 days
+export async function shareSession(id: string, expiresIn?: string): Promise<ShareSessionResponse> {
+  const params = new URLSearchParams()
+  if (expiresIn) params.set('expires_in', expiresIn)
+  if (!getAuthToken()) params.set('anonymous_id', getAnonymousId())
+  const query = params.toString()
+
+  const res = await fetchWithRetry(`/api/sessions/${id}/share${query ? `?${query}` : ''}`, {
+    method: 'POST',
+  })
+  if (!res.ok) {
+    if (res.status === 404) {
+      throw new Error('Session not found')
+    }
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
+export interface SharedSession<T> {
+  id: string
+  type: 'chat' | 'query'
+  name: string | null
+  content: T
+  created_at: string
+  updated_at: string
+  env?: string
+  view_count: number
+  expires_at: string
+}
+
+export async function getSharedSession<T>(token: string): Promise<SharedSession<T>> {
+  const res = await fetchWithRetry(`/api/sessions/shared/${encodeURIComponent(token)}`)
+  if (!res.ok) {
+    if (res.status === 404) {
+      throw new Error('Shared session not found or expired')
+    }
+    throw new Error('Failed to get shared session')
+  }
+  return res.json()
+}
+
+export interface BulkDeleteSessionsFilter {
+  olderThan?: string // RFC3339
+  userPK?: string
+  status?: 'completed' | 'failed'
+  allAccounts?: boolean // admins only, in place of userPK
+}
+
+// Requires a signed-in account; deletes only the caller's sessions unless they're an admin
+export async function bulkDeleteSessions(filter: BulkDeleteSessionsFilter): Promise<number> {
+  const res = await fetchWithRetry('/api/sessions', {
+    method: 'DELETE',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(filter),
+  })
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  const data: { deletedCount: number } = await res.json()
+  return data.deletedCount
+}
+
 // Upsert helper - creates if not exists, updates otherwise
 export async function upsertSession<T>(
   id: string,
@@ -2602,14 +3387,53 @@ export interface Device {
   peak_out_bps: number
 }
 
-export async function fetchDevices(limit = 100, offset = 0): Promise<PaginatedResponse<Device>> {
-  const res = await fetchWithRetry(`/api/dz/devices?limit=${limit}&offset=${offset}`)
+export interface DeviceFilters {
+  status?: string[]
+  deviceType?: string[]
+  metroPK?: string[]
+  contributorPK?: string[]
+  hasISIS?: boolean
+}
+
+export async function fetchDevices(
+  limit = 100,
+  offset = 0,
+  filters: DeviceFilters = {}
+): Promise<PaginatedResponse<Device>> {
+  const params = new URLSearchParams({ limit: String(limit), offset: String(offset) })
+  if (filters.status?.length) params.set('status', filters.status.join(','))
+  if (filters.deviceType?.length) params.set('device_type', filters.deviceType.join(','))
+  if (filters.metroPK?.length) params.set('metro_pk', filters.metroPK.join(','))
+  if (filters.contributorPK?.length) params.set('contributor_pk', filters.contributorPK.join(','))
+  if (filters.hasISIS) params.set('has_isis', 'true')
+  const res = await fetchWithRetry(`/api/dz/devices?${params}`)
   if (!res.ok) {
     throw new Error('Failed to fetch devices')
   }
   return res.json()
 }
 
+export interface EntityCountResponse {
+  total: number
+  byStatus: Record<string, number>
+}
+
+export async function fetchDeviceCount(): Promise<EntityCountResponse> {
+  const res = await fetchWithRetry('/api/dz/devices/count')
+  if (!res.ok) {
+    throw new Error('Failed to fetch device count')
+  }
+  return res.json()
+}
+
+export async function fetchLinkCount(): Promise<EntityCountResponse> {
+  const res = await fetchWithRetry('/api/dz/links/count')
+  if (!res.ok) {
+    throw new Error('Failed to fetch link count')
+  }
+  return res.json()
+}
+
 export interface DeviceDetail extends Device {
   metro_name: string
   validator_count: number
@@ -2626,6 +3450,125 @@ export async function fetchDevice(pk: string): Promise<DeviceDetail> {
   return res.json()
 }
 
+export interface DeviceUptimeResponse {
+  device_pk: string
+  device_code: string
+  window: '1h' | '24h' | '7d' | '30d'
+  window_start: string
+  window_end: string
+  current_status: string
+  observed_hours: number
+  active_hours: number
+  availability_pct: number
+  outage_count: number
+  mean_time_between_failures_hours: number | null
+}
+
+export async function fetchDeviceUptime(
+  pk: string,
+  window: '1h' | '24h' | '7d' | '30d' = '24h'
+): Promise<DeviceUptimeResponse> {
+  const params = new URLSearchParams({ window })
+  const res = await fetchWithRetry(`/api/dz/devices/${encodeURIComponent(pk)}/uptime?${params}`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
+export interface DeviceInterfaceStatus {
+  interfaceName: string
+  linkPK: string
+  linkCode: string
+  inBps: number
+  outBps: number
+  inDiscards: number
+  outDiscards: number
+  inErrors: number
+  outErrors: number
+  operStatus: string
+  hasIssues: boolean
+}
+
+export async function fetchDeviceInterfaces(pk: string): Promise<DeviceInterfaceStatus[]> {
+  const res = await fetchWithRetry(`/api/dz/devices/${encodeURIComponent(pk)}/interface-list`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
+export interface DeviceConfigDriftResponse {
+  hasChanges: boolean
+  initialConfig: DeviceEntity
+  initialSnapshotAt: string
+  currentConfig: DeviceEntity
+  changes: FieldChange[]
+}
+
+export async function fetchDeviceConfigDrift(pk: string): Promise<DeviceConfigDriftResponse> {
+  const res = await fetchWithRetry(`/api/dz/devices/${encodeURIComponent(pk)}/config-drift`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
+export interface ReachableDevice {
+  pk: string
+  code: string
+  status: string
+  metroPK?: string
+  hopCount: number
+  minMetric: number
+}
+
+export interface DeviceReachabilityResponse {
+  devicePK: string
+  deviceCode: string
+  maxHops?: number
+  reachable: ReachableDevice[]
+  isPartitioned: boolean // some ISIS device is unreachable at any distance
+  totalIsisDevices: number
+}
+
+export async function fetchDeviceReachability(pk: string, maxHops?: number): Promise<DeviceReachabilityResponse> {
+  const params = new URLSearchParams()
+  if (maxHops) params.set('max_hops', String(maxHops))
+  const query = params.toString()
+  const res = await fetchWithRetry(`/api/dz/devices/${encodeURIComponent(pk)}/reachability${query ? `?${query}` : ''}`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
+export interface DeviceNeighbor {
+  devicePK: string
+  deviceCode: string
+  status: string
+  deviceType: string
+  metroPK: string
+  direction: 'outgoing' | 'incoming'
+  metric: number
+  neighborAddr: string
+  adjSids: number[] | null
+}
+
+export interface DeviceNeighborsResponse {
+  neighbors: DeviceNeighbor[]
+  warning?: string
+}
+
+export async function fetchDeviceNeighbors(pk: string, includeOffline = false): Promise<DeviceNeighborsResponse> {
+  const params = includeOffline ? '?include_offline=true' : ''
+  const res = await fetchWithRetry(`/api/dz/devices/${encodeURIComponent(pk)}/neighbors${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch device neighbors')
+  }
+  return res.json()
+}
+
 export interface Link {
   pk: string
   code: string
@@ -2657,25 +3600,232 @@ export interface Link {
   loss_percent: number
 }
 
-export async function fetchLinks(limit = 100, offset = 0): Promise<PaginatedResponse<Link>> {
-  const res = await fetchWithRetry(`/api/dz/links?limit=${limit}&offset=${offset}`)
+export interface LinkFilters {
+  status?: string[]
+  linkType?: string[]
+  sideAMetroPK?: string[]
+  sideZMetroPK?: string[]
+  contributorPK?: string[]
+  minBandwidthBps?: number
+  maxCommittedRttNs?: number
+  hasLatencyData?: boolean
+}
+
+export async function fetchLinks(
+  limit = 100,
+  offset = 0,
+  filters: LinkFilters = {}
+): Promise<PaginatedResponse<Link>> {
+  const params = new URLSearchParams({ limit: String(limit), offset: String(offset) })
+  if (filters.status?.length) params.set('status', filters.status.join(','))
+  if (filters.linkType?.length) params.set('link_type', filters.linkType.join(','))
+  if (filters.sideAMetroPK?.length) params.set('side_a_metro_pk', filters.sideAMetroPK.join(','))
+  if (filters.sideZMetroPK?.length) params.set('side_z_metro_pk', filters.sideZMetroPK.join(','))
+  if (filters.contributorPK?.length) params.set('contributor_pk', filters.contributorPK.join(','))
+  if (filters.minBandwidthBps !== undefined) params.set('min_bandwidth_bps', String(filters.minBandwidthBps))
+  if (filters.maxCommittedRttNs !== undefined) params.set('max_committed_rtt_ns', String(filters.maxCommittedRttNs))
+  if (filters.hasLatencyData) params.set('has_latency_data', 'true')
+  const res = await fetchWithRetry(`/api/dz/links?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch links')
+  }
+  return res.json()
+}
+
+export interface TopologyEntityDelta<T> {
+  added: T[]
+  removed: T[]
+  updated: { pk: string; changes: FieldChange[] }[]
+}
+
+export interface TopologyDeltaResponse {
+  since: string
+  links: TopologyEntityDelta<LinkEntity>
+  devices: TopologyEntityDelta<DeviceEntity>
+}
+
+// Fetch links and devices changed since a timestamp. Pass the ETag from the
+// previous response to get null back when nothing has changed.
+export async function fetchTopologyDelta(
+  since: string,
+  etag?: string
+): Promise<{ delta: TopologyDeltaResponse | null; etag: string | null }> {
+  const res = await apiFetch(`/api/dz/links/topology-delta?since=${encodeURIComponent(since)}`, {
+    headers: etag ? { 'If-None-Match': etag } : undefined,
+  })
+  if (res.status === 304) {
+    return { delta: null, etag: etag ?? null }
+  }
+  if (!res.ok) {
+    throw new Error('Failed to fetch topology delta')
+  }
+  return { delta: await res.json(), etag: res.headers.get('ETag') }
+}
+
+export interface LinkDetail extends Link {
+  peak_in_bps: number
+  peak_out_bps: number
+  committed_rtt_ns: number
+  isis_delay_override_ns: number
+}
+
+export async function fetchLink(pk: string): Promise<LinkDetail> {
+  const res = await fetchWithRetry(`/api/dz/links/${encodeURIComponent(pk)}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch link')
+  }
+  return res.json()
+}
+
+export interface LinkLatencyTimeseriesPoint {
+  timestamp: string
+  p50_rtt_ms: number
+  p95_rtt_ms: number
+  p99_rtt_ms: number
+  avg_jitter_ms: number
+  loss_pct: number
+  samples: number
+}
+
+export interface LinkLatencyTimeseriesResponse {
+  link_pk: string
+  link_code: string
+  granularity: string
+  range: string
+  committed_rtt_ms: number
+  points: LinkLatencyTimeseriesPoint[]
+}
+
+export async function fetchLinkLatencyTimeseries(
+  pk: string,
+  granularity: '1m' | '5m' | '1h' = '5m',
+  range: '1h' | '6h' | '24h' | '7d' = '24h'
+): Promise<LinkLatencyTimeseriesResponse> {
+  const params = new URLSearchParams({ granularity, range })
+  const res = await fetchWithRetry(`/api/dz/links/${encodeURIComponent(pk)}/latency-timeseries?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch link latency timeseries')
+  }
+  return res.json()
+}
+
+export interface LinkSLAComplianceResponse {
+  linkPK: string
+  linkCode: string
+  window: '24h' | '7d' | '30d'
+  windowStart: string
+  windowEnd: string
+  committedRttNs: number
+  committedJitterNs: number
+  p50RttNs: number
+  p95RttNs: number
+  p99RttNs: number
+  avgJitterNs: number
+  lossPct: number
+  bucketCount: number
+  slaBreachCount: number
+  slaCompliancePct: number
+}
+
+export async function fetchLinkSLACompliance(
+  pk: string,
+  window: '24h' | '7d' | '30d' = '24h'
+): Promise<LinkSLAComplianceResponse> {
+  const params = new URLSearchParams({ window })
+  const res = await fetchWithRetry(`/api/dz/links/${encodeURIComponent(pk)}/sla-compliance?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch link SLA compliance')
+  }
+  return res.json()
+}
+
+export interface LinkPathUsagePair {
+  fromPK: string
+  fromCode: string
+  toPK: string
+  toCode: string
+  metric: number
+  share: number
+}
+
+export interface LinkPathUsageResponse {
+  linkPK: string
+  linkCode: string
+  sideAPK: string
+  sideZPK: string
+  inTopology: boolean
+  usageCount: number
+  totalPairs: number
+  percentOfAllPairs: number
+  edgeBetweenness: number
+  samplePaths: LinkPathUsagePair[]
+}
+
+export async function fetchLinkPathUsage(pk: string): Promise<LinkPathUsageResponse> {
+  const res = await fetchWithRetry(`/api/dz/links/${encodeURIComponent(pk)}/path-in-topology`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch link path usage')
+  }
+  return res.json()
+}
+
+export interface LinkRedundancyCheckResponse {
+  linkPK: string
+  linkCode: string
+  sideAPK: string
+  sideZPK: string
+  inTopology: boolean
+  hasAlternate: boolean
+  alternatePath?: PathHop[]
+  hopDelta?: number
+  metricDelta?: number
+  metroConnectivityImpact: boolean
+}
+
+export async function fetchLinkRedundancyCheck(pk: string): Promise<LinkRedundancyCheckResponse> {
+  const res = await fetchWithRetry(`/api/dz/links/${encodeURIComponent(pk)}/redundancy-check`)
   if (!res.ok) {
-    throw new Error('Failed to fetch links')
+    throw new Error('Failed to fetch link redundancy check')
   }
   return res.json()
 }
 
-export interface LinkDetail extends Link {
-  peak_in_bps: number
-  peak_out_bps: number
-  committed_rtt_ns: number
-  isis_delay_override_ns: number
+export interface LinkPeerComparisonResponse {
+  linkPK: string
+  peerCount: number
+  rttPercentileRank: number // share of peers with lower RTT; above 90 warrants a look
+  jitterPercentileRank: number
+  lossPercentileRank: number
+  rttVsPeerMedianPct: number
 }
 
-export async function fetchLink(pk: string): Promise<LinkDetail> {
-  const res = await fetchWithRetry(`/api/dz/links/${encodeURIComponent(pk)}`)
+export async function fetchLinkPeerComparison(pk: string): Promise<LinkPeerComparisonResponse> {
+  const res = await fetchWithRetry(`/api/dz/links/${encodeURIComponent(pk)}/peer-comparison`)
   if (!res.ok) {
-    throw new Error('Failed to fetch link')
+    throw new Error('Failed to fetch link peer comparison')
+  }
+  return res.json()
+}
+
+// Traffic of users on either end of a link; shares are of all those users
+export interface LinkUserTraffic {
+  userPK: string
+  ownerPubkey: string
+  inBps: number
+  outBps: number
+  shareOfTotalPct: number
+}
+
+// Requires sign-in since it exposes per-user traffic
+export async function fetchLinkTrafficAccounting(
+  pk: string,
+  window: '1h' | '6h' | '24h' = '1h'
+): Promise<LinkUserTraffic[]> {
+  const res = await apiFetch(
+    `/api/dz/links/${encodeURIComponent(pk)}/traffic-accounting?window=${window}`
+  )
+  if (!res.ok) {
+    throw new Error('Failed to fetch link traffic accounting')
   }
   return res.json()
 }
@@ -2713,6 +3863,24 @@ export async function fetchMetro(pk: string): Promise<MetroDetail> {
   return res.json()
 }
 
+// A metro gaining its first or losing its last ISIS adjacency to another metro
+export interface MetroConnectivityEvent {
+  timestamp: string
+  eventType: 'gained' | 'lost'
+  targetMetroPK: string
+  targetMetroCode: string
+  affectedDevicePK: string // device in the target metro
+  affectedDeviceCode: string
+}
+
+export async function fetchMetroConnectivityHistory(pk: string): Promise<MetroConnectivityEvent[]> {
+  const res = await fetchWithRetry(`/api/dz/metros/${encodeURIComponent(pk)}/connectivity-history`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch metro connectivity history')
+  }
+  return res.json()
+}
+
 export interface Contributor {
   pk: string
   code: string
@@ -2723,8 +3891,34 @@ export interface Contributor {
   link_count: number
 }
 
-export async function fetchContributors(limit = 100, offset = 0): Promise<PaginatedResponse<Contributor>> {
-  const res = await fetchWithRetry(`/api/dz/contributors?limit=${limit}&offset=${offset}`)
+export interface ContributorListSummary {
+  total_contributors: number
+  total_devices: number
+  total_bandwidth_bps: number
+}
+
+export interface ContributorListResponse extends PaginatedResponse<Contributor> {
+  summary: ContributorListSummary
+}
+
+export interface ContributorFilters {
+  sortBy?: 'device_count' | 'bandwidth' | 'link_count'
+  sortDir?: 'asc' | 'desc'
+  minDevices?: number
+  hasActiveLinks?: boolean
+}
+
+export async function fetchContributors(
+  limit = 100,
+  offset = 0,
+  filters: ContributorFilters = {}
+): Promise<ContributorListResponse> {
+  const params = new URLSearchParams({ limit: String(limit), offset: String(offset) })
+  if (filters.sortBy) params.set('sort_by', filters.sortBy)
+  if (filters.sortDir) params.set('sort_dir', filters.sortDir)
+  if (filters.minDevices !== undefined) params.set('min_devices', String(filters.minDevices))
+  if (filters.hasActiveLinks) params.set('has_active_links', 'true')
+  const res = await fetchWithRetry(`/api/dz/contributors?${params}`)
   if (!res.ok) {
     throw new Error('Failed to fetch contributors')
   }
@@ -2745,6 +3939,34 @@ export async function fetchContributor(pk: string): Promise<ContributorDetail> {
   return res.json()
 }
 
+export interface ContributorMetroCoverage {
+  pk: string
+  code: string
+  deviceCount: number
+}
+
+export interface ContributorNetworkSummary {
+  contributorPK: string
+  contributorCode: string
+  deviceCount: number
+  linkCount: number
+  activeLinkCount: number
+  totalBandwidthBps: number
+  metrosCovered: ContributorMetroCoverage[]
+  avgLinkRttNs: number
+  p95LossPct: number
+  isisAdjacencyCount: number
+  isisNeighborCount: number
+}
+
+export async function fetchContributorNetworkSummary(pk: string): Promise<ContributorNetworkSummary> {
+  const res = await fetchWithRetry(`/api/dz/contributors/${encodeURIComponent(pk)}/network-summary`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
 export interface User {
   pk: string
   owner_pubkey: string
@@ -2867,6 +4089,67 @@ export async function fetchValidator(votePubkey: string): Promise<ValidatorDetai
   return res.json()
 }
 
+export interface ValidatorStakeEpoch {
+  epoch: number
+  epochTs: string
+  stakeLamports: number
+  stakeSol: number
+  stakeSharePct: number
+  rank: number
+  activatedStake: number
+  deactivatingStake: number | null
+}
+
+export interface ValidatorStakeHistory {
+  votePubkey: string
+  history: ValidatorStakeEpoch[]
+  sparkline: [number, number][]
+}
+
+export async function fetchValidatorStakeHistory(
+  votePubkey: string,
+  options?: { start?: string; end?: string }
+): Promise<ValidatorStakeHistory> {
+  const params = new URLSearchParams()
+  if (options?.start) params.set('start', options.start)
+  if (options?.end) params.set('end', options.end)
+  const qs = params.toString()
+  const res = await fetchWithRetry(
+    `/api/solana/validators/${encodeURIComponent(votePubkey)}/stake-history${qs ? `?${qs}` : ''}`
+  )
+  if (!res.ok) {
+    throw new Error('Failed to fetch validator stake history')
+  }
+  return res.json()
+}
+
+export interface ValidatorPeerLatency {
+  votePubkey: string
+  metroCode: string
+  dzLatencyMs: number
+  internetLatencyMs: number
+  improvementPct: number
+}
+
+export interface ValidatorDZPerformance {
+  votePubkey: string
+  onDZ: boolean
+  metroCode?: string
+  peerCount: number
+  avgDZLatencyMs: number
+  avgInternetLatencyMs: number
+  improvementPct: number
+  topPeers: ValidatorPeerLatency[]
+}
+
+export async function fetchValidatorDZPerformance(votePubkey: string): Promise<ValidatorDZPerformance> {
+  const res = await fetchWithRetry(`/api/solana/validators/${encodeURIComponent(votePubkey)}/dz-performance`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch validator DZ performance')
+  }
+  return res.json()
+}
+
 // User traffic types
 export interface UserTrafficPoint {
   time: string
@@ -2889,6 +4172,25 @@ export async function fetchUserTraffic(pk: string, timeRange?: string, bucket?:
   return res.json()
 }
 
+export interface UserSession {
+  sessionStart: string
+  sessionEnd: string | null
+  durationSeconds: number
+  devicePK: string
+  deviceCode: string
+  metroCode: string
+  bytesIn: number
+  bytesOut: number
+}
+
+export async function fetchUserSessionTimeline(pk: string, limit = 50, offset = 0): Promise<PaginatedResponse<UserSession>> {
+  const res = await fetchWithRetry(`/api/dz/users/${encodeURIComponent(pk)}/session-timeline?limit=${limit}&offset=${offset}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch user session timeline')
+  }
+  return res.json()
+}
+
 export interface GossipNode {
   pubkey: string
   gossip_ip: string
@@ -2931,6 +4233,32 @@ export async function fetchGossipNodes(
   return res.json()
 }
 
+export interface GossipNodeMapItem {
+  pubkey: string
+  ip: string
+  asn: number
+  countryCode: string
+  latitude: number | null
+  longitude: number | null
+  isOnDZ: boolean
+  devicePK: string
+  metroPK: string
+}
+
+export interface GossipNodeMapResponse {
+  nodes: GossipNodeMapItem[]
+}
+
+export async function fetchGossipNodeMap(onDZOnly = false): Promise<GossipNodeMapResponse> {
+  const params = new URLSearchParams()
+  if (onDZOnly) params.set('on_dz_only', 'true')
+  const res = await fetchWithRetry(`/api/solana/gossip-nodes/map?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch gossip node map')
+  }
+  return res.json()
+}
+
 export interface GossipNodeDetail extends GossipNode {
   device_pk: string
   metro_pk: string
@@ -3099,7 +4427,9 @@ export interface TimelineResponse {
   events: TimelineEvent[]
   total: number
   limit: number
+  /** @deprecated Use next_cursor for pagination */
   offset: number
+  next_cursor?: string
   time_range: {
     start: string
     end: string
@@ -3123,7 +4453,8 @@ export interface TimelineParams {
   min_stake_pct?: number // Minimum stake share percentage to include
   search?: string // Comma-separated search terms to filter by entity codes, device codes, etc.
   limit?: number
-  offset?: number
+  offset?: number // Deprecated: prefer cursor
+  cursor?: string // Opaque next_cursor from a previous response
   include_internal?: boolean
 }
 
@@ -3154,6 +4485,7 @@ export async function fetchTimeline(params: TimelineParams = {}): Promise<Timeli
   if (params.search) searchParams.set('search', params.search)
   if (params.limit) searchParams.set('limit', params.limit.toString())
   if (params.offset) searchParams.set('offset', params.offset.toString())
+  if (params.cursor) searchParams.set('cursor', params.cursor)
   if (params.include_internal) searchParams.set('include_internal', 'true')
 
   const url = `/api/timeline${searchParams.toString() ? '?' + searchParams.toString() : ''}`
@@ -3219,6 +4551,17 @@ export interface LatencyComparison {
   jitter_improvement_pct: number | null
 }
 
+export interface LinkLatencyDelta {
+  linkPK: string
+  linkCode: string
+  sideAPK: string
+  sideZPK: string
+  currentAvgMs: number
+  baselineAvgMs: number | null
+  deltaMs: number | null
+  deltaPct: number | null
+}
+
 export interface LatencyComparisonResponse {
   comparisons: LatencyComparison[]
   summary: {
@@ -3227,10 +4570,24 @@ export interface LatencyComparisonResponse {
     max_improvement_pct: number
     pairs_with_data: number
   }
+  links?: LinkLatencyDelta[]
+}
+
+export interface LatencyComparisonParams {
+  start?: string
+  end?: string
+  devicePKs?: string[]
+  baselineWindow?: string
 }
 
-export async function fetchLatencyComparison(): Promise<LatencyComparisonResponse> {
-  const res = await apiFetch('/api/topology/latency-comparison')
+export async function fetchLatencyComparison(params: LatencyComparisonParams = {}): Promise<LatencyComparisonResponse> {
+  const searchParams = new URLSearchParams()
+  if (params.start) searchParams.set('start', params.start)
+  if (params.end) searchParams.set('end', params.end)
+  if (params.devicePKs?.length) searchParams.set('device_pk', params.devicePKs.join(','))
+  if (params.baselineWindow) searchParams.set('baseline_window', params.baselineWindow)
+  const qs = searchParams.toString()
+  const res = await apiFetch(`/api/topology/latency-comparison${qs ? `?${qs}` : ''}`)
   if (!res.ok) {
     throw new Error('Failed to fetch latency comparison')
   }
@@ -3302,6 +4659,22 @@ export async function fetchMetroPathLatency(optimize: PathOptimizeMode = 'latenc
   return res.json()
 }
 
+// Latency heatmap types (matrix[i][j] is ms from metros[i] to metros[j], -1 when unreachable)
+export interface LatencyHeatmapResponse {
+  optimize: PathOptimizeMode
+  metros: { pk: string; code: string }[]
+  matrix: number[][]
+  error?: string
+}
+
+export async function fetchLatencyHeatmap(optimize: PathOptimizeMode = 'latency'): Promise<LatencyHeatmapResponse> {
+  const res = await apiFetch(`/api/topology/latency-heatmap?optimize=${optimize}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch latency heatmap')
+  }
+  return res.json()
+}
+
 // Metro path detail types (single path breakdown)
 export interface MetroPathDetailHop {
   devicePK: string
@@ -3317,21 +4690,33 @@ export interface MetroPathDetailResponse {
   fromMetroCode: string
   toMetroCode: string
   optimize: PathOptimizeMode
+  window: MetroPathDetailWindow
   totalLatencyMs: number
   totalHops: number
   bottleneckBwGbps: number
   internetLatencyMs: number
   improvementPct: number | null
   hops: MetroPathDetailHop[]
+  measuredAt?: string
+  dataFreshness?: 'fresh' | 'cache' | 'historical'
   error?: string
 }
 
+export type MetroPathDetailWindow = '1h' | '6h' | '24h'
+
 export async function fetchMetroPathDetail(
   from: string,
   to: string,
-  optimize: PathOptimizeMode = 'latency'
+  optimize: PathOptimizeMode = 'latency',
+  options?: { window?: MetroPathDetailWindow; at?: string }
 ): Promise<MetroPathDetailResponse> {
-  const res = await apiFetch(`/api/topology/metro-path-detail?from=${from}&to=${to}&optimize=${optimize}`)
+  const params = new URLSearchParams({ from, to, optimize })
+  if (options?.window) params.set('window', options.window)
+  if (options?.at) {
+    params.set('historical', 'true')
+    params.set('at', options.at)
+  }
+  const res = await apiFetch(`/api/topology/metro-path-detail?${params}`)
   if (!res.ok) {
     throw new Error('Failed to fetch metro path detail')
   }
@@ -3440,6 +4825,48 @@ export async function fetchMaintenanceImpact(
   return res.json()
 }
 
+export interface MaintenanceWindow {
+  windowId: string
+  devices: string[]
+  links: string[]
+  startAt: string
+  endAt: string
+  description: string
+  impact: MaintenanceImpactResponse
+  createdAt: string
+  notifiedAt?: string
+  status: 'pending' | 'approved' | 'cancelled'
+  approvedBy?: string // Slack user ID
+  approvedAt?: string
+}
+
+export async function createMaintenanceWindow(window: {
+  devices: string[]
+  links: string[]
+  startAt: string
+  endAt: string
+  description: string
+}): Promise<MaintenanceWindow> {
+  const res = await apiFetch('/api/topology/maintenance-window', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(window),
+  })
+  if (!res.ok) {
+    throw new Error(await errorText(res) || 'Failed to schedule maintenance window')
+  }
+  return res.json()
+}
+
+export async function fetchMaintenanceWindows(): Promise<MaintenanceWindow[]> {
+  const res = await apiFetch('/api/topology/maintenance-windows')
+  if (!res.ok) {
+    throw new Error('Failed to fetch maintenance windows')
+  }
+  const data: { windows: MaintenanceWindow[] } = await res.json()
+  return data.windows
+}
+
 // What-if removal types (unified API for devices and links)
 export interface WhatIfAffectedPath {
   source: string
@@ -3470,9 +4897,23 @@ export interface WhatIfRemovalResponse {
   totalDisconnected: number
   affectedPaths?: WhatIfAffectedPath[]
   disconnectedList?: string[]
+  disconnectedPairs: WhatIfDevicePair[]
+  reroutedPaths: WhatIfAffectedPath[]
+  connectivityBefore: number
+  connectivityAfter: number
+  connectivityDelta: number
   error?: string
 }
 
+export interface WhatIfDevicePair {
+  sourcePK: string
+  source: string
+  sourceMetro?: string
+  targetPK: string
+  target: string
+  targetMetro?: string
+}
+
 export async function fetchWhatIfRemoval(
   devices: string[],
   links: string[]
@@ -3617,6 +5058,65 @@ export async function fetchStakeValidators(
   return res.json()
 }
 
+// Stake concentration
+export interface StakeConcentrationMetrics {
+  validator_count: number
+  stake_sol: number
+  nakamoto_33: number
+  nakamoto_50: number
+  hhi: number
+}
+
+export interface StakeConcentrationPoint {
+  timestamp: string
+  total: StakeConcentrationMetrics
+  dz: StakeConcentrationMetrics
+}
+
+export interface StakeConcentrationResponse {
+  granularity: string
+  range: string
+  points: StakeConcentrationPoint[]
+  fetched_at: string
+  error?: string
+}
+
+export async function fetchStakeConcentration(
+  granularity: '1h' | '6h' | '1d' = '1h',
+  range: '24h' | '7d' | '30d' = '7d'
+): Promise<StakeConcentrationResponse> {
+  const params = new URLSearchParams({ granularity, range })
+  const res = await fetchWithRetry(`/api/stake/concentration?${params}`)
+  if (!res.ok) {
+    throw new Error('Failed to fetch stake concentration')
+  }
+  return res.json()
+}
+
+export interface StakeByMetroValidator {
+  pk: string
+  code: string
+  stakePct: number
+}
+
+export interface StakeByMetro {
+  metroPK: string
+  metroCode: string
+  metroName: string
+  validatorCount: number
+  totalStakeLamports: number
+  stakePct: number
+  topValidators: StakeByMetroValidator[]
+}
+
+export async function fetchStakeByMetro(): Promise<StakeByMetro[]> {
+  const res = await fetchWithRetry('/api/stake/validators/by-metro')
+  if (!res.ok) {
+    throw new Error('Failed to fetch stake by metro')
+  }
+  return res.json()
+}
+
 // Traffic analytics types and functions
 export interface TrafficPoint {
   time: string
@@ -3962,6 +5462,88 @@ export async function fetchDashboardHealth(
   return res.json()
 }
 
+export interface DashboardAnomaly {
+  linkPK: string
+  linkCode: string
+  direction: 'in' | 'out'
+  currentBps: number
+  meanBps: number
+  stddevBps: number
+  zScore: number
+  severity: 'warning' | 'critical'
+}
+
+export interface DashboardAnomalyResponse {
+  anomalies: DashboardAnomaly[]
+}
+
+export interface DashboardAnomalyParams {
+  time_range?: string
+  bucket?: string
+  min_z_score?: number
+  limit?: number
+  metro?: string
+  device?: string
+  link_type?: string
+  contributor?: string
+  intf?: string
+  start_time?: string
+  end_time?: string
+}
+
+export async function fetchDashboardAnomaly(
+  params: DashboardAnomalyParams = {}
+): Promise<DashboardAnomalyResponse> {
+  const searchParams = new URLSearchParams()
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined && value !== '') {
+      searchParams.set(key, String(value))
+    }
+  }
+  const res = await fetchWithRetry(`/api/traffic/dashboard/anomaly?${searchParams}`)
+  if (!res.ok) throw new Error('Failed to fetch dashboard anomaly data')
+  return res.json()
+}
+
+export interface CapacityPlanningLink {
+  linkPK: string
+  linkCode: string
+  bandwidthBps: number
+  currentBps: number
+  currentUtilizationPct: number
+  growthRateBpsPerDay: number
+  projectedExhaustion80Pct: string | null
+  projectedExhaustion100Pct: string | null
+  severity: 'ok' | 'warning' | 'critical'
+}
+
+export interface CapacityPlanningResponse {
+  links: CapacityPlanningLink[]
+}
+
+export interface CapacityPlanningParams {
+  limit?: number
+  metro?: string
+  device?: string
+  link_type?: string
+  contributor?: string
+  intf?: string
+}
+
+export async function fetchCapacityPlanning(
+  params: CapacityPlanningParams = {}
+): Promise<CapacityPlanningResponse> {
+  const searchParams = new URLSearchParams()
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined && value !== '') {
+      searchParams.set(key, String(value))
+    }
+  }
+  const res = await fetchWithRetry(`/api/traffic/dashboard/capacity-planning?${searchParams}`)
+  if (!res.ok) throw new Error('Failed to fetch capacity planning data')
+  return res.json()
+}
+
 // Search types and functions
 export type SearchEntityType = 'device' | 'link' | 'metro' | 'contributor' | 'user' | 'validator' | 'gossip' | 'multicast'
 
@@ -3971,6 +5553,8 @@ export interface SearchSuggestion {
   label: string
   sublabel: string
   url: string
+  latitude?: number
+  longitude?: number
 }
 
 export interface AutocompleteResponse {
@@ -4012,6 +5596,32 @@ export async function fetchSearch(
   return res.json()
 }
 
+export interface GeoBounds {
+  lat_min: number
+  lat_max: number
+  lon_min: number
+  lon_max: number
+}
+
+export interface GeoSearchResponse {
+  bounds: GeoBounds
+  results: Partial<Record<'metro' | 'device', SearchResultGroup>>
+}
+
+export type GeoSearchArea = GeoBounds | { lat: number; lon: number; radius_km: number }
+
+export async function fetchGeoSearch(area: GeoSearchArea, limit = 100): Promise<GeoSearchResponse> {
+  const params = new URLSearchParams({ limit: limit.toString() })
+  for (const [key, value] of Object.entries(area)) {
+    params.set(key, value.toString())
+  }
+  const res = await fetchWithRetry(`/api/search/geo?${params}`)
+  if (!res.ok) {
+    throw new Error(await errorText(res))
+  }
+  return res.json()
+}
+
 // Link outages types
 export type OutageTimeRange = '3h' | '6h' | '12h' | '24h' | '3d' | '7d' | '30d'
 export type OutageType = 'status' | 'packet_loss' | 'no_data'
@@ -4114,14 +5724,22 @@ export interface WalletNonceResponse {
 
 export interface WalletAuthResponse {
   token: string
+  refresh_token?: string
   account: Account
 }
 
 export interface GoogleAuthResponse {
   token: string
+  refresh_token?: string
   account: Account
 }
 
+export interface RefreshAuthResponse {
+  token: string
+  expires_at: string
+  refresh_token: string
+}
+
 // Get current user and quota
 export class AuthError extends Error {
   status: number
@@ -4143,7 +5761,12 @@ export async function fetchAuthMe(): Promise<AuthMeResponse> {
 // Logout
 export async function logout(): Promise<void> {
   try {
-    await fetchWithRetry('/api/auth/logout', { method: 'POST' })
+    const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY)
+    await fetchWithRetry('/api/auth/logout', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken ?? '' }),
+    })
   } finally {
     clearAuthToken()
   }
@@ -4179,11 +5802,11 @@ export async function authenticateWallet(
     }),
   })
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     throw new Error(text || 'Wallet authentication failed')
   }
   const data: WalletAuthResponse = await res.json()
-  setAuthToken(data.token)
+  setAuthTokens(data.token, data.refresh_token)
   return data
 }
 
@@ -4201,14 +5824,36 @@ export async function authenticateGoogle(idToken: string): Promise<GoogleAuthRes
     }),
   })
   if (!res.ok) {
-    const text = await res.text()
+    const text = await errorText(res)
     throw new Error(text || 'Google authentication failed')
   }
   const data: GoogleAuthResponse = await res.json()
-  setAuthToken(data.token)
+  setAuthTokens(data.token, data.refresh_token)
   return data
 }
 
+// Exchange the stored refresh token for a new auth token. Returns false if
+// there's no refresh token or it was rejected, in which case the user has to
+// log in again.
+export async function refreshAuthToken(): Promise<boolean> {
+  const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY)
+  if (!refreshToken) {
+    return false
+  }
+  const res = await apiFetch('/api/auth/refresh', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ refresh_token: refreshToken }),
+  })
+  if (!res.ok) {
+    clearAuthToken()
+    return false
+  }
+  const data: RefreshAuthResponse = await res.json()
+  setAuthTokens(data.token, data.refresh_token)
+  return true
+}
+
 // Get current quota
 export async function fetchQuota(): Promise<QuotaInfo> {
   const res = await fetchWithRetry('/api/usage/quota')
@@ -4218,6 +5863,31 @@ export async function fetchQuota(): Promise<QuotaInfo> {
   return res.json()
 }
 
+export interface UsageHistoryDay {
+  date: string
+  query_count: number
+  generate_count: number
+  chat_count: number
+}
+
+export interface UsageHistory {
+  days: UsageHistoryDay[]
+  total_all_time: {
+    query_count: number
+    generate_count: number
+    chat_count: number
+  }
+}
+
+// Get the signed-in user's daily usage over the past 30 days
+export async function fetchUsageHistory(): Promise<UsageHistory> {
+  const res = await apiFetch('/api/usage/history')
+  if (!res.ok) {
+    throw new Error('Failed to get usage history')
+  }
+  return res.json()
+}
+
 // Build SIWS message for signing
 export function buildSIWSMessage(nonce: string): string {
   return `Sign this message to authenticate with DoubleZero Data.\n\nNonce: ${nonce}`